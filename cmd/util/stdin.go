@@ -39,6 +39,53 @@ func YesNo(reader *bufio.Reader) bool {
 	}
 }
 
+// YesNoDefault prints the question and reads from stdin like YesNo, an empty line selecting defaultValue
+func YesNoDefault(reader *bufio.Reader, question string, defaultValue bool) bool {
+	choices := "[y/N]"
+	if defaultValue {
+		choices = "[Y/n]"
+	}
+	for {
+		fmt.Printf("%s %s: ", question, choices)
+		text := strings.ToLower(readstdin(reader))
+		switch text {
+		case "":
+			return defaultValue
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		default:
+			fmt.Printf("invalid input %q, should be [y/n]\n", text)
+		}
+	}
+}
+
+// Prompt prints the question and reads values from stdin until one is valid, an empty line selecting
+// defaultValue if it is set
+func Prompt(reader *bufio.Reader, question, defaultValue string, validate func(string) error) string {
+	for {
+		if defaultValue != "" {
+			fmt.Printf("%s [%s]: ", question, defaultValue)
+		} else {
+			fmt.Printf("%s: ", question)
+		}
+		text := readstdin(reader)
+		if text == "" {
+			text = defaultValue
+		}
+		if text == "" {
+			fmt.Println("a value is required")
+			continue
+		}
+		if err := validate(text); err != nil {
+			fmt.Printf("invalid input %q: %v\n", text, err)
+			continue
+		}
+		return text
+	}
+}
+
 // Readstdin reads a line from stdin trimming spaces, and returns the value.
 // log.Fatal's if there is an error.
 func readstdin(reader *bufio.Reader) string {
@@ -53,7 +53,7 @@ scaffold_test_project() {
         $kb create api --group creatures --version v2alpha1 --kind Kraken --namespaced=false --example=false --controller=true --resource=true --make=false
         $kb alpha webhook --group creatures --version v2alpha1 --kind Kraken --type=validating --operations=create --make=false
         $kb create api --group core --version v1 --kind Namespace --example=false --controller=true --resource=false --namespaced=false --make=false
-        $kb alpha webhook --group core --version v1 --kind Namespace --type=mutating --operations=update --make=false
+        $kb alpha webhook --group core --version v1 --kind Namespace --type=mutating --operations=update --allow-reserved-group --make=false
         $kb create api --group policy --version v1beta1 --kind HealthCheckPolicy --example=false --controller=true --resource=true --namespaced=false --make=false
     elif [ $version == "2" ]; then
         header_text 'Starting to generate projects with version 2'
@@ -99,6 +99,7 @@ scaffold_test_project() {
     make all test # v2 doesn't test by default
     rm -f Gopkg.lock
     rm -f go.sum
+    rm -f SCAFFOLD_INFO.yaml # records the build of kubebuilder and the times of the operations
     rm -rf ./vendor
     rm -rf ./bin
     export GOPATH=$oldgopath
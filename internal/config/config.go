@@ -19,9 +19,10 @@ package config
 import (
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"os"
+	"path/filepath"
 
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
 	"sigs.k8s.io/kubebuilder/pkg/model/config"
 	"sigs.k8s.io/yaml"
 )
@@ -29,9 +30,14 @@ import (
 // Default path for the configuration file
 const DefaultPath = "PROJECT"
 
+// PathIn returns the path of the configuration file of a project rooted at the provided directory
+func PathIn(dir string) string {
+	return filepath.Join(dir, DefaultPath)
+}
+
 func exists(path string) (bool, error) {
 	// Look up the file
-	_, err := os.Stat(path)
+	_, err := filesystem.Stat(path)
 
 	// If we could find it the file exists
 	if err == nil || os.IsExist(err) {
@@ -51,9 +57,14 @@ func Exists() (bool, error) {
 	return exists(DefaultPath)
 }
 
+// ExistsIn verifies that the configuration file exists in the project rooted at the provided directory
+func ExistsIn(dir string) (bool, error) {
+	return exists(PathIn(dir))
+}
+
 func readFrom(path string) (c config.Config, err error) {
 	// Read the file
-	in, err := ioutil.ReadFile(path) // nolint: gosec
+	in, err := filesystem.ReadFile(path)
 	if err != nil {
 		return
 	}
@@ -146,7 +157,7 @@ func (c Config) Save() error {
 	}
 
 	// Write the marshalled configuration
-	err = ioutil.WriteFile(c.path, content, os.ModePerm)
+	err = filesystem.WriteFile(c.path, content, os.ModePerm)
 	if err != nil {
 		return saveError{fmt.Errorf("failed to save configuration to %s: %v", c.path, err)}
 	}
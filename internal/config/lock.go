@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// errLocked is returned by openLocked when the lock is held by another process
+var errLocked = errors.New("locked")
+
+// LockPath returns the path of the file locking the project rooted at the provided directory
+func LockPath(dir string) string {
+	return filepath.Join(dir, ".kubebuilder", "lock")
+}
+
+// Lock is held by a command while it modifies the configuration and the files of a project
+type Lock struct {
+	file *os.File
+}
+
+// AcquireLock locks the project rooted at the provided directory, failing if another command holds its lock
+func AcquireLock(dir string) (*Lock, error) {
+	path := LockPath(dir)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, lockError{fmt.Errorf("unable to create %s: %v", filepath.Dir(path), err)}
+	}
+
+	f, err := openLocked(path)
+	if err == errLocked {
+		return nil, lockedError{path: path, owner: readOwner(path)}
+	}
+	if err != nil {
+		return nil, lockError{err}
+	}
+
+	// Record the holder of the lock to report it to the commands waiting for it
+	owner := fmt.Sprintf("pid %d: %s", os.Getpid(), strings.Join(os.Args, " "))
+	if err := f.Truncate(0); err != nil {
+		_ = f.Close()
+		return nil, lockError{err}
+	}
+	if _, err := f.WriteString(owner); err != nil {
+		_ = f.Close()
+		return nil, lockError{err}
+	}
+
+	return &Lock{file: f}, nil
+}
+
+// Release releases the lock, allowing other commands to modify the project
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	err := closeLocked(l.file)
+	l.file = nil
+	if err != nil {
+		return lockError{err}
+	}
+	return nil
+}
+
+// readOwner returns the description of the holder of the lock recorded in the file at path
+func readOwner(path string) string {
+	content, err := ioutil.ReadFile(path) // nolint: gosec
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+type lockError struct {
+	err error
+}
+
+func (e lockError) Error() string {
+	return fmt.Sprintf("unable to lock the project: %v", e.err)
+}
+
+type lockedError struct {
+	path  string
+	owner string
+}
+
+func (e lockedError) Error() string {
+	owner := "another kubebuilder command"
+	if e.owner != "" {
+		owner = fmt.Sprintf("another kubebuilder command (%s)", e.owner)
+	}
+	return fmt.Sprintf("%s is modifying the project, retry once it has finished. "+
+		"If no other command is running, remove %s", owner, e.path)
+}
+
+// IsLocked returns true if err was returned because the project is locked by another command
+func IsLocked(err error) bool {
+	_, ok := err.(lockedError)
+	return ok
+}
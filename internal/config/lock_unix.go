@@ -0,0 +1,66 @@
+//go:build !windows
+// +build !windows
+
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"syscall"
+)
+
+// openLocked opens the file at path holding an exclusive flock on it,
+// the lock is released by the system if the process exits without releasing it
+func openLocked(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		_ = f.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, errLocked
+		}
+		return nil, err
+	}
+
+	// The holder may have removed the file between the open and the flock calls
+	opened, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	current, err := os.Stat(path)
+	if err != nil || !os.SameFile(opened, current) {
+		_ = f.Close()
+		return nil, errLocked
+	}
+
+	return f, nil
+}
+
+// closeLocked removes the file while still holding its flock, so that no lock can be acquired
+// on the removed file, and closes it
+func closeLocked(f *os.File) error {
+	removeErr := os.Remove(f.Name())
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return removeErr
+}
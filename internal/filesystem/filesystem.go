@@ -0,0 +1,303 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package filesystem is the filesystem the scaffolds read and write the files of the project through: the disk,
+// unless a dry run keeps the writes in memory. The files written by a dry run, or by a tracked run, are reported
+// by Changes.
+package filesystem
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// FS is the filesystem of the project, the disk by default
+var FS afero.Fs = afero.NewOsFs()
+
+var (
+	mu sync.Mutex
+
+	// tracking records the writes of the dry run or of the tracked run, nil unless one started
+	tracking *trackingFs
+)
+
+// Action is what a dry run, or a tracked run, did to a file
+type Action string
+
+const (
+	// Create is the action of writing a file which didn't exist
+	Create Action = "create"
+
+	// Modify is the action of changing the content of an existing file
+	Modify Action = "modify"
+)
+
+// Change is a file a dry run, or a tracked run, created or modified
+type Change struct {
+	// Path is the path of the file, relative to the current working directory if it is under it
+	Path string
+
+	Action Action
+}
+
+// DryRun keeps the writes to FS in memory from now on: the files are read from the disk, with the writes of the
+// dry run applied, and the disk is left untouched
+func DryRun() {
+	mu.Lock()
+	defer mu.Unlock()
+	if tracking != nil && tracking.dryRun {
+		return
+	}
+	tracking = &trackingFs{
+		Fs:      afero.NewCopyOnWriteFs(afero.NewReadOnlyFs(afero.NewOsFs()), afero.NewMemMapFs()),
+		dryRun:  true,
+		written: map[string]*snapshot{},
+	}
+	FS = tracking
+}
+
+// Track records the files written to the disk from now on, for Changes to report them. It does nothing during a
+// dry run, which records them already.
+func Track() {
+	mu.Lock()
+	defer mu.Unlock()
+	if tracking != nil {
+		return
+	}
+	tracking = &trackingFs{
+		Fs:      afero.NewOsFs(),
+		written: map[string]*snapshot{},
+	}
+	FS = tracking
+}
+
+// Reset ends the dry run, discarding its writes, or the tracked run: FS is the disk again
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	tracking = nil
+	FS = afero.NewOsFs()
+}
+
+// IsDryRun returns whether the writes are kept in memory
+func IsDryRun() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return tracking != nil && tracking.dryRun
+}
+
+// Changes returns the files the dry run, or the tracked run, created or modified, sorted by path. The files
+// written with the content they had are not changed.
+func Changes() ([]Change, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if tracking == nil {
+		return nil, nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	for path, previous := range tracking.written {
+		current, err := afero.ReadFile(tracking.Fs, path)
+		if err != nil {
+			// the directories and the files removed since they were written are not changes of content
+			continue
+		}
+		action := Create
+		if previous.existed {
+			if bytes.Equal(previous.content, current) {
+				continue
+			}
+			action = Modify
+		}
+		if rel, err := filepath.Rel(wd, path); err == nil && rel != ".." &&
+			!strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			path = rel
+		}
+		changes = append(changes, Change{Path: path, Action: action})
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// Contents returns the content of the file of the change before it was modified, nil if it was created, and its
+// content now
+func Contents(change Change) (previous, current []byte, err error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if tracking == nil {
+		return nil, nil, fmt.Errorf("no dry run nor tracked run recorded %s", change.Path)
+	}
+	path := abs(change.Path)
+	snapshot, found := tracking.written[path]
+	if !found {
+		return nil, nil, fmt.Errorf("%s was not written", change.Path)
+	}
+	if current, err = afero.ReadFile(tracking.Fs, path); err != nil {
+		return nil, nil, err
+	}
+	if snapshot.existed {
+		previous = snapshot.content
+	}
+	return previous, current, nil
+}
+
+// ReadFile reads the file of the path from FS
+func ReadFile(path string) ([]byte, error) {
+	return afero.ReadFile(FS, path)
+}
+
+// WriteFile writes the file of the path to FS
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	return afero.WriteFile(FS, path, data, perm)
+}
+
+// MkdirAll creates the directory of the path and its parents in FS
+func MkdirAll(path string, perm os.FileMode) error {
+	return FS.MkdirAll(path, perm)
+}
+
+// Stat returns the description of the file of the path in FS
+func Stat(path string) (os.FileInfo, error) {
+	return FS.Stat(path)
+}
+
+// Open opens the file of the path in FS for reading
+func Open(path string) (afero.File, error) {
+	return FS.Open(path)
+}
+
+// trackingFs is the filesystem of a dry run, copy-on-write over the disk, or of a tracked run, the disk,
+// recording the files it writes. The paths are made absolute, for the relative and the absolute paths of a file
+// to name the same file, e.g. in memory.
+type trackingFs struct {
+	afero.Fs
+
+	// dryRun is true if the writes are kept in memory
+	dryRun bool
+
+	// written maps the absolute paths of the files opened for writing to their content before they were first
+	written map[string]*snapshot
+}
+
+// snapshot is the content of a file before it was written
+type snapshot struct {
+	existed bool
+	content []byte
+}
+
+var _ afero.Fs = &trackingFs{}
+
+// abs returns the absolute path of the path, the path itself if it can't be made absolute
+func abs(path string) string {
+	if p, err := filepath.Abs(path); err == nil {
+		return p
+	}
+	return path
+}
+
+// record records that the file of the path is written, along with its content unless it was written already
+func (d *trackingFs) record(path string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, recorded := d.written[path]; recorded {
+		return
+	}
+	content, err := afero.ReadFile(d.Fs, path)
+	d.written[path] = &snapshot{existed: err == nil, content: content}
+}
+
+// Create implements afero.Fs
+func (d *trackingFs) Create(name string) (afero.File, error) {
+	name = abs(name)
+	d.record(name)
+	return d.Fs.Create(name)
+}
+
+// Mkdir implements afero.Fs
+func (d *trackingFs) Mkdir(name string, perm os.FileMode) error {
+	return d.Fs.Mkdir(abs(name), perm)
+}
+
+// MkdirAll implements afero.Fs
+func (d *trackingFs) MkdirAll(path string, perm os.FileMode) error {
+	return d.Fs.MkdirAll(abs(path), perm)
+}
+
+// Open implements afero.Fs
+func (d *trackingFs) Open(name string) (afero.File, error) {
+	return d.Fs.Open(abs(name))
+}
+
+// OpenFile implements afero.Fs
+func (d *trackingFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	name = abs(name)
+	if flag&(os.O_WRONLY|os.O_RDWR|os.O_APPEND|os.O_CREATE|os.O_TRUNC) != 0 {
+		d.record(name)
+	}
+	return d.Fs.OpenFile(name, flag, perm)
+}
+
+// Remove implements afero.Fs
+func (d *trackingFs) Remove(name string) error {
+	return d.Fs.Remove(abs(name))
+}
+
+// RemoveAll implements afero.Fs
+func (d *trackingFs) RemoveAll(path string) error {
+	return d.Fs.RemoveAll(abs(path))
+}
+
+// Rename implements afero.Fs
+func (d *trackingFs) Rename(oldname, newname string) error {
+	newname = abs(newname)
+	d.record(newname)
+	return d.Fs.Rename(abs(oldname), newname)
+}
+
+// Stat implements afero.Fs
+func (d *trackingFs) Stat(name string) (os.FileInfo, error) {
+	return d.Fs.Stat(abs(name))
+}
+
+// Name implements afero.Fs
+func (d *trackingFs) Name() string {
+	if d.dryRun {
+		return "DryRunFs"
+	}
+	return "TrackingFs"
+}
+
+// Chmod implements afero.Fs
+func (d *trackingFs) Chmod(name string, mode os.FileMode) error {
+	return d.Fs.Chmod(abs(name), mode)
+}
+
+// Chtimes implements afero.Fs
+func (d *trackingFs) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return d.Fs.Chtimes(abs(name), atime, mtime)
+}
@@ -14,7 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package cli
 
 import (
 	"github.com/spf13/cobra"
@@ -22,19 +22,33 @@ import (
 
 // newAlphaCommand returns alpha subcommand which will be mounted
 // at the root command by the caller.
-func newAlphaCommand() *cobra.Command {
+func (c *CLI) newAlphaCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "alpha",
 		Short: "Expose commands which are in experimental or early stages of development",
 		Long:  `Command group for commands which are either experimental or in early stages of development`,
 		Example: `
-# scaffolds webhook server
+# scaffolds webhook server (v1 projects)
 kubebuilder alpha webhook <params>
+
+# upgrades the dependencies pinned in the PROJECT file
+kubebuilder alpha upgrade-deps
+
+# generates the SBOM of the dependencies of the project
+kubebuilder alpha sbom
+
+# compares the scaffolded files of the project with the current templates
+kubebuilder alpha diff-templates
 `,
 	}
 
 	cmd.AddCommand(
-		newWebhookCmd(),
+		c.newUpgradeDepsCmd(),
+		c.newSBOMCmd(),
+		c.newDiffTemplatesCmd(),
 	)
+	cmd.AddCommand(onlyV1(
+		c.newWebhookCmd(),
+	)...)
 	return cmd
 }
@@ -0,0 +1,516 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+
+	"sigs.k8s.io/kubebuilder/cmd/util"
+	"sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/cli/internal"
+	"sigs.k8s.io/kubebuilder/pkg/logging"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	controllerv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2/controller"
+	"sigs.k8s.io/kubebuilder/plugins/addon"
+	"sigs.k8s.io/kubebuilder/plugins/hybrid"
+	"sigs.k8s.io/kubebuilder/plugins/metaoperator"
+)
+
+type apiOptions struct {
+	cli                          *CLI
+	apiScaffolder                scaffold.API
+	resourceFlag, controllerFlag *flag.Flag
+	flags                        *flag.FlagSet
+
+	// webhooks are the flags of create webhook for the webhooks chosen in the wizard
+	webhooks []string
+
+	// runMake indicates whether to run make or not after scaffolding APIs
+	runMake bool
+
+	// statusConditions indicates whether the status of the resource has conditions
+	statusConditions bool
+
+	// sample indicates whether the sample of the resource is scaffolded
+	sample bool
+
+	// pattern indicates that we should use a plugin to build according to a pattern
+	pattern string
+}
+
+func (o *apiOptions) bindCmdFlags(cmd *cobra.Command) {
+	o.flags = cmd.Flags()
+	cmd.Flags().BoolVar(&o.runMake, "make", true,
+		"if true, run make after generating files")
+	cmd.Flags().BoolVar(&o.apiScaffolder.DoResource, "resource", true,
+		"if set, generate the resource without prompting the user")
+	o.resourceFlag = cmd.Flag("resource")
+	cmd.Flags().BoolVar(&o.apiScaffolder.DoController, "controller", true,
+		"if set, generate the controller without prompting the user")
+	o.controllerFlag = cmd.Flag("controller")
+	cmd.Flags().StringVar(&o.apiScaffolder.FromTypes, "from-types", "",
+		"path of a Go file whose struct (named <kind>Spec, <kind> or the only one) is imported into the spec "+
+			"of the resource, along with the other types and constants of the file (v2 only)")
+	cmd.Flags().StringVar(&o.apiScaffolder.Schema, "schema", "",
+		"path of an OpenAPI v3 or JSON schema (of the spec, of the object or defining <kind>Spec) the spec of the "+
+			"resource is generated from, with its nested structs, enums and validation markers (v2 only)")
+	cmd.Flags().StringVar(&o.apiScaffolder.FromCluster, "from-cluster", "",
+		"name of a CRD of the cluster, e.g. installed by another tool, whose group, kind, names, scope, printer "+
+			"columns and spec are copied to the resource, the version defaulting to its storage version, for the "+
+			"project to take ownership of the CRD (v2 only)")
+	cmd.Flags().StringVar(&o.apiScaffolder.Kubeconfig, "kubeconfig", "",
+		"path of the kubeconfig of the cluster of --from-cluster, the one of kubectl if not set")
+	cmd.Flags().StringArrayVar(&o.apiScaffolder.References, "ref", nil,
+		"field of the spec referencing an object of another kind, of the form spec.<field>:<group>/<version>/<Kind>, "+
+			"e.g. spec.secretRef:core/v1/Secret, can be repeated (v2 only)")
+	cmd.Flags().StringArrayVar(&o.apiScaffolder.Unions, "union", nil,
+		"field of the spec of which exactly one member is set, as named by its type, of the form "+
+			"spec.<field>=<member>|<member>..., e.g. spec.source=git|oci|http, can be repeated (v2 only)")
+	cmd.Flags().StringArrayVar(&o.apiScaffolder.Embeds, "embed", nil,
+		"field of the spec of a Kubernetes type, of the form <group>/<version>/<Type>:spec.<field>, e.g. "+
+			"core/v1/PodTemplateSpec:spec.template, the descriptions of the CRDs are dropped for them to stay "+
+			"small enough to be applied, can be repeated (v2 only)")
+	cmd.Flags().StringArrayVar(&o.apiScaffolder.Enums, "enum", nil,
+		"field of the spec or of the status whose value is one of a set of strings, of the form "+
+			"spec.<field>=<value>,<value>..., e.g. status.phase=Pending,Running,Failed, generating a string type "+
+			"with a constant per value, can be repeated (v2 only)")
+	cmd.Flags().StringArrayVar(&o.apiScaffolder.PrinterColumns, "printer-column", nil,
+		"column of kubectl get for the resource, of the form <name>:<JSONPath>:<type>, e.g. Phase:.status.phase:string, "+
+			"the type being one of string, integer, number, boolean and date, printed before the Ready and Age "+
+			"columns, can be repeated (v2 only)")
+	cmd.Flags().StringArrayVar(&o.apiScaffolder.Adopt, "adopt", nil,
+		"kind of the objects managed by the controller, of the form <group>/<version>/<Kind>, e.g. core/v1/ConfigMap, "+
+			"named after the resource: the existing ones no object controls, e.g. created by hand or by helm, are "+
+			"adopted when spec.adoptExisting is set, can be repeated (v2 only)")
+	cmd.Flags().StringVar(&o.apiScaffolder.ReconcileTemplate, "reconcile-template", controllerv2.ReconcileTemplateNone,
+		"body of the reconciler, one of "+strings.Join(controllerv2.ReconcileTemplateNames(), ", ")+": the "+
+			"reconciler creates the object of the kind named after every object of the resource, controlled by it, "+
+			"and updates it to its desired state, none leaves the logic to you (v2 only)")
+	cmd.Flags().BoolVar(&o.apiScaffolder.UnitTests, "unit-tests", false,
+		"if set, generate unit tests for the controller running against a fake client (v2 only)")
+	cmd.Flags().BoolVar(&o.apiScaffolder.HealthCheck, "health-check", false,
+		"if set, generate a health check of the controller failing while it can't reconcile successfully, "+
+			"registered as a readiness check of the manager, and a sample alerting rule (v2 only)")
+	cmd.Flags().BoolVar(&o.apiScaffolder.WatchConfig, "watch-config", false,
+		"if set, generate a watcher of a ConfigMap holding the configuration of the operator, reloaded without "+
+			"restarting the manager, and reconcile every object of the controller again when it changes (v2 only)")
+	cmd.Flags().BoolVar(&o.apiScaffolder.MessageBus, "message-bus", false,
+		"if set, generate a consumer of a message bus, abstracted for Kafka or NATS, whose messages trigger the "+
+			"reconciles of the objects they name, started and stopped with the manager (v2 only)")
+	cmd.Flags().BoolVar(&o.apiScaffolder.ValidateInReconcile, "validate-in-reconcile", false,
+		"if set, the controller validates the objects with the validation of their validating webhook and "+
+			"records the violations in their Valid condition, for the clusters disallowing webhooks (v2 only)")
+	cmd.Flags().BoolVar(&o.apiScaffolder.Finalizer, "with-finalizer", false,
+		"if set, the controller adds a finalizer to the objects and cleans up after them when they are deleted, "+
+			"before removing it (v2 only)")
+	cmd.Flags().BoolVar(&o.apiScaffolder.BackupHooks, "backup-hooks", false,
+		"if set, generate the stubs of the hooks Velero runs in the pods of the workloads of the objects around "+
+			"their backups and restores, the package annotating them and a Velero schedule under config/backup (v2 only)")
+	cmd.Flags().BoolVar(&o.apiScaffolder.LargeScale, "large-scale", false,
+		"if set, the controller lists the objects in pages and reads the objects of the kinds it doesn't watch "+
+			"from the API server, bypassing the cache of the manager, for the operators of very large numbers of "+
+			"objects whose caches run the manager out of memory (v2 only)")
+	cmd.Flags().BoolVar(&o.apiScaffolder.LongRunning, "long-running", false,
+		"if set, the status of the resource tracks a long-running operation of several steps, e.g. provisioning "+
+			"in an external system, which the controller runs across reconciles with idempotent steps, retried "+
+			"with backoff and resumed from the progress persisted in the status after a restart (v2 only)")
+	cmd.Flags().BoolVar(&o.apiScaffolder.CircuitBreaker, "circuit-breaker", false,
+		"if set, the controller calls the external systems the objects depend on through a circuit breaker, "+
+			"opening after consecutive failures to fail the calls fast and half-opening after a cooldown, its "+
+			"state recorded in the DependencyAvailable condition of the objects and in a metric; the steps of "+
+			"--long-running call through it (v2 only)")
+	cmd.Flags().BoolVar(&o.statusConditions, "status-conditions", true,
+		"if set, the status of the resource has conditions (v2 only)")
+	cmd.Flags().BoolVar(&o.sample, "sample", true,
+		"if set, scaffold the sample of the resource under config/samples; unset it for the example objects "+
+			"managed elsewhere, the tests of the webhooks and the scale tests reading the sample then need one "+
+			"(v2 only)")
+	cmd.Flags().BoolVar(&o.apiScaffolder.RBACAggregation, "rbac-aggregation", false,
+		"if set, the editor and viewer roles of the resource are aggregated into the admin, edit and view "+
+			"ClusterRoles of Kubernetes and deployed, the users granted them manage the objects of the resource "+
+			"without a binding of their own (v2 only)")
+	cmd.Flags().BoolVar(&o.apiScaffolder.GenerateClients, "generate-clients", false,
+		"if set, the kind is marked +genclient and make clients generates its typed clientset, informers and "+
+			"listers into pkg/client with k8s.io/code-generator, for the consumers of the API (v2 only)")
+	cmd.Flags().BoolVar(&o.apiScaffolder.ApplyConditions, "apply-conditions", false,
+		"if set, the controller applies the conditions it owns with server-side apply (v2 only)")
+	cmd.Flags().BoolVar(&o.apiScaffolder.E2E, "e2e", false,
+		"if set, scaffold an end-to-end test applying the sample of the resource to a test environment, or to the "+
+			"cluster running the manager when USE_EXISTING_CLUSTER=true, waiting until it is reconciled and "+
+			"deleting it, run by make test-e2e (v2 only)")
+	if os.Getenv("KUBEBUILDER_ENABLE_PLUGINS") != "" {
+		cmd.Flags().StringVar(&o.pattern, "pattern", "",
+			"generates an API following an extension pattern (addon, hybrid, meta-operator)")
+	}
+	cmd.Flags().Var(&forceFlag{api: &o.apiScaffolder}, "force",
+		"attempt to create resource even if it already exists, overwriting its files "+
+			"(modified files are backed up under .kubebuilder/backups); set to categories of files, e.g. "+
+			"--force=types,controller, to only overwrite the files of these categories ("+
+			strings.Join(scaffold.ForceCategories, ", ")+"), the existing files of the others are kept")
+	cmd.Flags().Lookup("force").NoOptDefVal = "true"
+	o.apiScaffolder.Resource = resourceForFlags(cmd.Flags())
+}
+
+// forceFlag is the value of the --force flag, either a boolean or the comma separated categories of the files
+// to overwrite
+type forceFlag struct {
+	api *scaffold.API
+}
+
+// String implements flag.Value
+func (f *forceFlag) String() string {
+	if f.api == nil || !f.api.Force {
+		return "false"
+	}
+	if len(f.api.ForceOnly) == 0 {
+		return "true"
+	}
+	return strings.Join(f.api.ForceOnly, ",")
+}
+
+// Set implements flag.Value
+func (f *forceFlag) Set(value string) error {
+	if force, err := strconv.ParseBool(value); err == nil {
+		f.api.Force, f.api.ForceOnly = force, nil
+		return nil
+	}
+	f.api.Force, f.api.ForceOnly = true, nil
+	for _, category := range strings.Split(value, ",") {
+		if category = strings.TrimSpace(category); category != "" {
+			f.api.ForceOnly = append(f.api.ForceOnly, category)
+		}
+	}
+	return nil
+}
+
+// Type implements flag.Value
+func (f *forceFlag) Type() string {
+	return "categories"
+}
+
+// resourceForFlags registers flags for Resource fields and returns the Resource
+func resourceForFlags(f *flag.FlagSet) *resource.Resource {
+	r := &resource.Resource{}
+	f.StringVar(&r.Kind, "kind", "", "resource Kind")
+	f.StringVar(&r.Group, "group", "", "resource Group")
+	f.StringVar(&r.Version, "version", "", "resource Version")
+	f.BoolVar(&r.Namespaced, "namespaced", true,
+		"resource is namespaced, if false the resource is cluster-scoped: its CRD has the Cluster scope, its "+
+			"sample has no namespace and its editor and viewer roles are bound cluster-wide")
+	f.StringVar(&r.Resource, "plural", "",
+		"resource plural, the lower case name of the resource in the API, e.g. redises, derived from the kind if not "+
+			"set: set it for the kinds whose plural isn't regular, it is recorded in the PROJECT file (v2 only)")
+	f.StringSliceVar(&r.ShortNames, "short-name", nil,
+		"short name of the resource, e.g. fm, listed by kubectl get fm, can be repeated or comma separated (v2 only)")
+	f.StringSliceVar(&r.Categories, "category", nil,
+		"category of the resource, e.g. all, listing it along with the other resources of the category with "+
+			"kubectl get <category>, can be repeated or comma separated (v2 only)")
+	allowReservedGroupFlag(f, r)
+	f.BoolVar(&r.AllowBuiltinKind, "allow-builtin-kind", false,
+		"if set, allow the kind of a built-in type in another group, e.g. Pod, whose CRD kubectl and the clients "+
+			"have to qualify by the group")
+	f.BoolVar(&r.NoCRD, "no-crd", false,
+		"if set, the kind is served by an aggregated API server or its CRD is managed elsewhere, its CRD is "+
+			"neither added to the kustomization of the CRDs nor sampled (v2 only)")
+	f.BoolVar(&r.CreateExampleReconcileBody, "example", true,
+		"if true an example reconcile body should be written while scaffolding a resource.")
+	return r
+}
+
+// allowReservedGroupFlag registers the flag allowing the resource to be of a group reserved by Kubernetes
+func allowReservedGroupFlag(f *flag.FlagSet, r *resource.Resource) {
+	f.BoolVar(&r.AllowReservedGroup, "allow-reserved-group", false,
+		"if set, allow the groups reserved by Kubernetes, core and the groups under k8s.io and kubernetes.io, "+
+			"e.g. for a built-in kind")
+}
+
+// APICmd represents the resource command
+func (o *apiOptions) runAddAPI() error {
+	if err := internal.CheckConfigured(o.cli.outputDir); err != nil {
+		return err
+	}
+	o.apiScaffolder.OutputDir = o.cli.outputDir
+	o.apiScaffolder.Diff = o.cli.diffOutput
+	o.apiScaffolder.NoStatusConditions = !o.statusConditions
+	o.apiScaffolder.NoSample = !o.sample
+
+	unlock, err := internal.LockProject(o.cli.outputDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	switch strings.ToLower(o.pattern) {
+	case "":
+		// Default pattern
+
+	case "addon":
+		o.apiScaffolder.Plugins = append(o.apiScaffolder.Plugins, &addon.Plugin{})
+
+	case "hybrid":
+		o.apiScaffolder.Plugins = append(o.apiScaffolder.Plugins, &hybrid.Plugin{})
+
+	case "meta-operator":
+		o.apiScaffolder.Plugins = append(o.apiScaffolder.Plugins, &metaoperator.Plugin{})
+
+	default:
+		return fmt.Errorf("unknown pattern %q", o.pattern)
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	wizard := o.interactive()
+	if wizard {
+		c, err := config.LoadFrom(config.PathIn(o.cli.outputDir))
+		if err != nil {
+			return fmt.Errorf("failed to read the configuration file: %v", err)
+		}
+		q, err := c.Query()
+		if err != nil {
+			return err
+		}
+		o.runWizard(reader, q.SupportsScaffoldMarkers())
+		o.apiScaffolder.NoStatusConditions = !o.statusConditions
+		logging.Info("Creating the API, equivalent to " + o.commandLine())
+	}
+
+	if err := o.apiScaffolder.Validate(); err != nil {
+		return err
+	}
+
+	if !o.resourceFlag.Changed && !wizard {
+		fmt.Fprintln(o.cli.commandOutput, "Create Resource [y/n]")
+		o.apiScaffolder.DoResource = util.YesNo(reader)
+	}
+
+	if !o.controllerFlag.Changed && !wizard {
+		fmt.Fprintln(o.cli.commandOutput, "Create Controller [y/n]")
+		o.apiScaffolder.DoController = util.YesNo(reader)
+	}
+
+	logging.Info("Writing scaffold for you to edit...")
+
+	if err := o.apiScaffolder.Scaffold(); err != nil {
+		return err
+	}
+	// make doesn't modify the project configuration, other commands can run meanwhile
+	unlock()
+
+	if err := o.createWebhooks(); err != nil {
+		return err
+	}
+
+	return o.postScaffold()
+}
+
+func (o *apiOptions) postScaffold() error {
+	if o.runMake {
+		if filesystem.IsDryRun() {
+			logging.Info("Skipping make in a dry run.")
+			return nil
+		}
+		logging.Info("Running make...")
+		defer logging.Phase("make")()
+		cm := exec.Command("make") // #nosec
+		cm.Dir = o.cli.outputDir
+		cm.Stderr = os.Stderr
+		cm.Stdout = o.cli.commandOutput
+		if err := cm.Run(); err != nil {
+			return fmt.Errorf("error running make: %v", err)
+		}
+	}
+	return nil
+}
+
+func (c *CLI) newAPICommand() *cobra.Command {
+	options := apiOptions{
+		cli:           c,
+		apiScaffolder: scaffold.API{},
+	}
+
+	apiCmd := &cobra.Command{
+		Use:   "api",
+		Short: "Scaffold a Kubernetes API",
+		Long: `Scaffold a Kubernetes API by creating a Resource definition and / or a Controller.
+
+create resource will prompt the user for if it should scaffold the Resource and / or Controller.  To only
+scaffold a Controller for an existing Resource, select "n" for Resource.  To only define
+the schema for a Resource without writing a Controller, select "n" for Controller.
+
+Run in a terminal without --group, --version or --kind, api is a wizard prompting for the group, the version
+and the kind, the scope of the resource, whether to create the resource and the controller, the conditions of
+the status and the webhooks, validating every answer. The flags set skip their questions.
+
+The status of the resource has conditions, shown in the Ready column of kubectl get, along with the helpers
+finding and setting them, unless --status-conditions=false. The status subresource is enabled either way.
+
+After the scaffold is written, api will run make on the project.
+`,
+		Example: `	# Create a frigates API with Group: ship, Version: v1beta1 and Kind: Frigate
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate
+
+	# Create an API answering the questions of the wizard
+	kubebuilder create api
+	
+	# Create a cluster-scoped fleets API, whose objects don't belong to any namespace
+	kubebuilder create api --group ship --version v1beta1 --kind Fleet --namespaced=false
+
+	# Create a controller for the built-in Deployments, importing k8s.io/api/apps/v1 rather than types of the project
+	kubebuilder create api --group apps --version v1 --kind Deployment --resource=false --controller
+
+	# Create the version v1 of the existing Frigate kind, converted to and from its storage version v1beta1 by
+	# the conversion webhook, the controller of the Frigates reconciling every version
+	kubebuilder create api --group ship --version v1 --kind Frigate --controller=false
+
+	# Create a redises API, whose plural isn't the one derived from its kind
+	kubebuilder create api --group cache --version v1 --kind Redis --plural redises
+
+	# Create a frigates API whose spec has the fields of an existing FrigateSpec or Frigate struct
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --from-types ../fleet/frigate.go
+
+	# Create a frigates API whose spec is generated from the JSON schema the API contract is defined with
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --schema frigate.schema.json
+
+	# Create a frigates API whose spec references a Secret in the namespace of the Frigate, the controller is
+	# allowed to read Secrets and resolves the reference
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --ref spec.secretRef:core/v1/Secret
+
+	# Create a frigates API whose spec has a source of which exactly one of the git, oci and http members is set
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --union 'spec.source=git|oci|http'
+
+	# Create a frigates API whose spec embeds the template of the Pods of the Frigate, the CRD staying small
+	# enough to be applied
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --embed core/v1/PodTemplateSpec:spec.template
+
+	# Create a frigates API whose status has a phase of type FrigatePhase, one of the constants
+	# FrigatePhasePending, FrigatePhaseRunning and FrigatePhaseFailed
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --enum status.phase=Pending,Running,Failed
+
+	# Create a frigates API whose phase and number of replicas are printed by kubectl get frigates
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --enum status.phase=Pending,Running,Failed \
+		--printer-column Phase:.status.phase:string --printer-column Replicas:.spec.replicas:integer
+
+	# Create the frigates API of the CRD frigates.ship.example.org installed in the cluster by another tool, its
+	# spec being generated from the schema of the CRD, for the project to take ownership of the CRD
+	kubebuilder create api --from-cluster frigates.ship.example.org --kubeconfig ~/.kube/config
+
+	# Create a frigates API listed by kubectl get fr, and by kubectl get ships along with the other kinds of the group
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --short-name fr --category ships
+
+	# Create a frigates API whose controller manages a ConfigMap per Frigate, adopting the existing ConfigMap
+	# named after the Frigate when its spec.adoptExisting is set, e.g. one created by helm
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --adopt core/v1/ConfigMap
+
+	# Create a frigates API whose controller runs a Deployment per Frigate, its pods to be set from the spec of the
+	# Frigate
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --reconcile-template deployment
+
+	# Create a frigates API whose controller flips the readiness of the manager when it is stuck
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --health-check
+
+	# Create a frigates API whose controller reads the configuration of the operator from a ConfigMap, and
+	# reconciles every Frigate again when it changes
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --watch-config
+
+	# Create a frigates API whose controller reconciles the Frigates named by the messages of a message bus,
+	# e.g. the events of an external system published on Kafka or NATS
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --message-bus
+
+	# Create a frigates API whose controller enforces the validation of its webhook at reconcile time,
+	# recording the violations in the Valid condition of the Frigates, for the clusters disallowing webhooks
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --validate-in-reconcile
+
+	# Create a frigates API whose controller cleans up after the deleted Frigates, their finalizer holding their
+	# deletion until then
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --with-finalizer
+
+	# Create a databases API whose controller annotates the pods of its workloads with the hooks Velero runs
+	# around their backups and restores
+	kubebuilder create api --group storage --version v1 --kind Database --backup-hooks
+
+	# Create a frigates API whose controller lists the Frigates in pages and reads the other kinds without caching
+	# them, for the clusters of so many Frigates that caching all of the objects runs the manager out of memory
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --large-scale
+
+	# Create a databases API whose controller provisions the Databases in steps, tracking the progress and the
+	# retries of every step in their status
+	kubebuilder create api --group storage --version v1 --kind Database --long-running
+
+	# Create a databases API whose controller stops calling the external system provisioning the Databases
+	# while it keeps failing, requeuing them until it is available again
+	kubebuilder create api --group storage --version v1 --kind Database --long-running --circuit-breaker
+
+	# Create a frigates API whose status has no conditions
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --status-conditions=false
+
+	# Create a frigates API without sample, the example Frigates being managed elsewhere
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --sample=false
+
+	# Create a frigates API whose Frigates are managed by the users granted the admin or edit ClusterRole, and
+	# viewed by the users granted the view ClusterRole
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --rbac-aggregation
+
+	# Create a frigates API with a typed clientset, informers and listers generated by make clients
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --generate-clients
+
+	# Create a frigates API whose controller applies its conditions with server-side apply as its own field
+	# manager, sharing the conditions of the Frigates with the other controllers setting theirs rather than
+	# clobbering them
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --apply-conditions
+
+	# Create a frigates API with an end-to-end test, run against the cluster of the current kubeconfig after
+	# make deploy
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --e2e
+	USE_EXISTING_CLUSTER=true make test-e2e
+
+	# Regenerate the controller of the existing frigates API, keeping its types and manifests as they are
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --force=controller
+
+	# Edit the API Scheme
+	nano api/v1beta1/frigate_types.go
+
+	# Edit the Controller
+	nano controllers/frigate/frigate_controller.go
+
+	# Edit the Controller Test
+	nano controllers/frigate/frigate_controller_test.go
+
+	# Install CRDs into the Kubernetes cluster using kubectl apply
+	make install
+
+	# Regenerate code and run against the Kubernetes cluster configured by ~/.kube/config
+	make run
+`,
+		Annotations: map[string]string{internal.ScaffoldAnnotation: ""},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return options.runAddAPI()
+		},
+	}
+
+	options.bindCmdFlags(apiCmd)
+
+	return apiCmd
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/cmd/util"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+)
+
+// isTerminal returns true if f is a terminal rather than a pipe or a file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// interactive returns true if create api runs its wizard: the group, the version or the kind of the resource
+// aren't set by the flags nor copied from a CRD of the cluster, and stdin is a terminal
+func (o *apiOptions) interactive() bool {
+	r := o.apiScaffolder.Resource
+	return (r.Group == "" || r.Version == "" || r.Kind == "") && o.apiScaffolder.FromCluster == "" &&
+		isTerminal(os.Stdin)
+}
+
+// runWizard prompts for the options of the API the flags left unset: the group, the version and the kind of the
+// resource, its scope, whether to create the resource and the controller, the conditions of its status and, if
+// the project supports webhooks, the webhooks to create along with the API. Every answer is validated before the
+// next question.
+func (o *apiOptions) runWizard(reader *bufio.Reader, webhooks bool) {
+	r := o.apiScaffolder.Resource
+	fmt.Println("Answer the following questions to create the API, their flags skip them next time.")
+
+	if r.Group == "" {
+		r.Group = util.Prompt(reader, "Group, e.g. ship", "", func(group string) error {
+			return resource.ValidateGroup(group, r.AllowReservedGroup)
+		})
+	}
+	if r.Version == "" {
+		r.Version = util.Prompt(reader, "Version, e.g. v1beta1", "v1", resource.ValidateVersion)
+	}
+	if r.Kind == "" {
+		r.Kind = util.Prompt(reader, "Kind, in PascalCase, e.g. Frigate", "", resource.ValidateKind)
+	}
+	if !o.flags.Changed("namespaced") {
+		r.Namespaced = util.YesNoDefault(reader, "Namespaced, rather than cluster-scoped", true)
+	}
+	if !o.resourceFlag.Changed {
+		o.apiScaffolder.DoResource = util.YesNoDefault(reader, "Create Resource", true)
+	}
+	if !o.controllerFlag.Changed {
+		o.apiScaffolder.DoController = util.YesNoDefault(reader, "Create Controller", true)
+	}
+	if !o.apiScaffolder.DoResource {
+		return
+	}
+
+	if !o.flags.Changed("status-conditions") {
+		o.statusConditions = util.YesNoDefault(reader, "Add conditions, e.g. Ready, to the status", true)
+	}
+	if !webhooks {
+		return
+	}
+	if util.YesNoDefault(reader, "Create a defaulting webhook", false) {
+		o.webhooks = append(o.webhooks, "--defaulting")
+	}
+	if util.YesNoDefault(reader, "Create a validating webhook", false) {
+		o.webhooks = append(o.webhooks, "--programmatic-validation")
+	}
+	if !r.NoCRD && util.YesNoDefault(reader, "Create a conversion webhook", false) {
+		o.webhooks = append(o.webhooks, "--conversion")
+	}
+}
+
+// commandLine returns the create api command line equivalent to the answers of the wizard
+func (o *apiOptions) commandLine() string {
+	r := o.apiScaffolder.Resource
+	args := []string{"kubebuilder", "create", "api", "--group", r.Group, "--version", r.Version, "--kind", r.Kind}
+	if !r.Namespaced {
+		args = append(args, "--namespaced=false")
+	}
+	args = append(args, fmt.Sprintf("--resource=%t", o.apiScaffolder.DoResource),
+		fmt.Sprintf("--controller=%t", o.apiScaffolder.DoController))
+	if o.apiScaffolder.DoResource && !o.statusConditions {
+		args = append(args, "--status-conditions=false")
+	}
+	return strings.Join(args, " ")
+}
+
+// createWebhooks creates the webhooks chosen in the wizard with create webhook
+func (o *apiOptions) createWebhooks() error {
+	if len(o.webhooks) == 0 {
+		return nil
+	}
+	r := o.apiScaffolder.Resource
+	cmd := o.cli.newWebhookV2Cmd()
+	cmd.SetArgs(append([]string{"--group", r.Group, "--version", r.Version, "--kind", r.Kind}, o.webhooks...))
+	return cmd.Execute()
+}
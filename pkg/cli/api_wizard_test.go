@@ -0,0 +1,61 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"bufio"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestRunWizard(t *testing.T) {
+	o := &apiOptions{}
+	o.bindCmdFlags(&cobra.Command{})
+	if err := o.flags.Set("version", "v1beta1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the invalid group and kind are asked again, the empty answers select the defaults
+	answers := []string{"Ship", "ship", "frigate", "Frigate", "n", "", "no", "", "y", "", "yes"}
+	o.runWizard(bufio.NewReader(strings.NewReader(strings.Join(answers, "\n")+"\n")), true)
+
+	r := o.apiScaffolder.Resource
+	if r.Group != "ship" || r.Version != "v1beta1" || r.Kind != "Frigate" {
+		t.Errorf("unexpected resource %s/%s/%s", r.Group, r.Version, r.Kind)
+	}
+	if r.Namespaced {
+		t.Errorf("expected a cluster-scoped resource")
+	}
+	if !o.apiScaffolder.DoResource || o.apiScaffolder.DoController {
+		t.Errorf("expected the resource without controller")
+	}
+	if !o.statusConditions {
+		t.Errorf("expected the status conditions by default")
+	}
+	if expected := []string{"--defaulting", "--conversion"}; !reflect.DeepEqual(o.webhooks, expected) {
+		t.Errorf("expected the webhooks %v, got %v", expected, o.webhooks)
+	}
+
+	expected := "kubebuilder create api --group ship --version v1beta1 --kind Frigate --namespaced=false " +
+		"--resource=true --controller=false"
+	if line := o.commandLine(); line != expected {
+		t.Errorf("expected the command line %q, got %q", expected, line)
+	}
+}
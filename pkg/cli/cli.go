@@ -0,0 +1,186 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cli is the command line of kubebuilder, which other binaries embed and extend with commands of their
+// own rather than exec-ing kubebuilder, e.g. the CLI of a platform scaffolding the operators of its teams:
+//
+//	c, err := cli.New(
+//		cli.WithCommandName("platform"),
+//		cli.WithDefaultProjectVersion(config.Version2),
+//		cli.WithCommands(c.newPublishCmd()),
+//	)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if err := c.Run(); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// The commands of a CLI share its state, e.g. the persistent flags of the root command. They return their errors
+// from Run rather than exiting the process, for the binary embedding them to report them.
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/pkg/cli/internal"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/version"
+)
+
+// CLI is the root command of kubebuilder along with the commands it was extended with
+type CLI struct {
+	// commandName is the name of the root command, e.g. kubebuilder
+	commandName string
+
+	// defaultProjectVersion is the version of the projects scaffolded by init without --project-version
+	defaultProjectVersion string
+
+	// commands are the commands added to the ones of kubebuilder
+	commands []*cobra.Command
+
+	// outputDir is the root directory of the project the commands operate on, an empty value means the current
+	// working directory
+	outputDir string
+
+	// profile is the profile of the user configuration providing the flag defaults
+	profile string
+
+	// dryRun keeps the files written by a scaffolding command in memory and prints them instead
+	dryRun bool
+
+	// diff prints the differences between the existing files and the templates of a scaffolding command rather
+	// than failing on or skipping the existing files
+	diff bool
+
+	// output is the format of the report of what a scaffolding command did, written to stdout, empty for no report
+	output string
+
+	// verbosity and logFormat configure the messages written by the commands, see the logging package
+	verbosity int
+	logFormat string
+
+	// diffOutput receives the diffs printed by --diff, nil without it
+	diffOutput io.Writer
+
+	// commandOutput receives the output of the commands run by the scaffolding commands, e.g. make or go mod tidy,
+	// and their prompts. It is stderr with --output, whose report is the only thing written to stdout then.
+	commandOutput io.Writer
+
+	cmd *cobra.Command
+}
+
+// Option configures a CLI
+type Option func(*CLI) error
+
+// WithCommandName sets the name of the root command, kubebuilder by default
+func WithCommandName(name string) Option {
+	return func(c *CLI) error {
+		if name == "" {
+			return fmt.Errorf("the name of the command can't be empty")
+		}
+		c.commandName = name
+		return nil
+	}
+}
+
+// WithDefaultProjectVersion sets the version of the projects scaffolded by init without --project-version, the
+// latest one by default
+func WithDefaultProjectVersion(projectVersion string) Option {
+	return func(c *CLI) error {
+		switch projectVersion {
+		case config.Version1, config.Version2:
+		default:
+			return fmt.Errorf("unknown project version %q, must be one of %q or %q", projectVersion,
+				config.Version1, config.Version2)
+		}
+		c.defaultProjectVersion = projectVersion
+		return nil
+	}
+}
+
+// WithCommands adds commands to the root command, next to the ones of kubebuilder. They may be annotated with
+// ScaffoldAnnotation to support the --dry-run, --diff and --output flags.
+func WithCommands(commands ...*cobra.Command) Option {
+	return func(c *CLI) error {
+		c.commands = append(c.commands, commands...)
+		return nil
+	}
+}
+
+// ScaffoldAnnotation annotates the commands scaffolding the project
+const ScaffoldAnnotation = internal.ScaffoldAnnotation
+
+// New returns the command line of kubebuilder configured by the options
+func New(options ...Option) (*CLI, error) {
+	c := &CLI{
+		commandName:           "kubebuilder",
+		defaultProjectVersion: config.Version2,
+		commandOutput:         os.Stdout,
+	}
+	for _, option := range options {
+		if err := option(c); err != nil {
+			return nil, err
+		}
+	}
+
+	c.cmd = c.defaultCommand()
+	c.cmd.Use = c.commandName
+	c.cmd.AddCommand(
+		c.newInitProjectCmd(c.defaultProjectVersion),
+		c.newEditProjectCmd(),
+		c.newCreateCmd(),
+		c.newAlphaCommand(),
+		c.newVerifyCmd(),
+		c.newFixCmd(),
+		version.NewVersionCmd(),
+	)
+	// the version of the project is only known once the flags are parsed, see checkProjectVersion
+	c.cmd.AddCommand(exceptV1(
+		c.newLintCmd(),
+		c.newReleaseCmd(),
+		c.newGenerateCmd(),
+	)...)
+	c.cmd.AddCommand(onlyV1(
+		c.newVendorUpdateCmd(),
+	)...)
+
+	for _, command := range c.commands {
+		if existing, _, err := c.cmd.Find([]string{command.Name()}); err == nil && existing != c.cmd {
+			return nil, fmt.Errorf("the command %s is already a command of %s", command.Name(), c.commandName)
+		}
+		c.cmd.AddCommand(command)
+	}
+	return c, nil
+}
+
+// Command returns the root command, e.g. to add it as a sub-command of another command line
+func (c *CLI) Command() *cobra.Command {
+	return c.cmd
+}
+
+// Run runs the command of the arguments of the process and returns its error
+func (c *CLI) Run() error {
+	err := c.cmd.Execute()
+	if err != nil {
+		internal.FinishScaffold(err)
+	}
+	return err
+}
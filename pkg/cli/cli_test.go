@@ -0,0 +1,209 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	internalconfig "sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+)
+
+func TestNew(t *testing.T) {
+	publish := &cobra.Command{Use: "publish"}
+	c, err := New(
+		WithCommandName("platform"),
+		WithDefaultProjectVersion(config.Version1),
+		WithCommands(publish),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Command().Use != "platform" {
+		t.Errorf("expected the command to be named platform, got %s", c.Command().Use)
+	}
+	if cmd, _, err := c.Command().Find([]string{"publish"}); err != nil || cmd != publish {
+		t.Errorf("expected the publish command to be added, got %v, %v", cmd, err)
+	}
+	initCmd, _, err := c.Command().Find([]string{"init"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if v := initCmd.Flags().Lookup("project-version").DefValue; v != config.Version1 {
+		t.Errorf("expected init to scaffold projects of version %s by default, got %s", config.Version1, v)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []Option
+	}{
+		{"unknown project version", []Option{WithDefaultProjectVersion("3")}},
+		{"empty command name", []Option{WithCommandName("")}},
+		{"command of kubebuilder", []Option{WithCommands(&cobra.Command{Use: "init"})}},
+	}
+	for _, test := range tests {
+		if _, err := New(test.options...); err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		}
+	}
+}
+
+func TestRunReleasesLockOnError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	project := "version: \"2\"\ndomain: example.org\nrepo: example.org/project\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "PROJECT"), []byte(project), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the resource is validated once the project is locked
+	c.Command().SetArgs([]string{"create", "api", "--output-dir", dir, "--group", "crew", "--version", "1",
+		"--kind", "FirstMate", "--resource", "--controller", "--make=false"})
+	if err := c.Run(); err == nil || !strings.Contains(err.Error(), "version") {
+		t.Fatalf("expected an invalid version error, got %v", err)
+	}
+
+	if _, err := os.Stat(internalconfig.LockPath(dir)); !os.IsNotExist(err) {
+		t.Errorf("expected the lock to be released, got %v", err)
+	}
+	lock, err := internalconfig.AcquireLock(dir)
+	if err != nil {
+		t.Fatalf("expected the project to be unlocked, got %v", err)
+	}
+	if err := lock.Release(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunReturnsErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.Command().SetArgs([]string{"lint", "api", "--output-dir", dir})
+	if err := c.Run(); err == nil || !strings.Contains(err.Error(), "kubebuilder init") {
+		t.Errorf("expected an error about the project not being initialized, got %v", err)
+	}
+}
+
+func TestDiffImpliesDryRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	project := "version: \"2\"\ndomain: example.org\nrepo: example.org/project\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "PROJECT"), []byte(project), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer filesystem.Reset()
+
+	c, err := New()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the dry run starts before the resource is validated
+	c.Command().SetArgs([]string{"create", "api", "--output-dir", dir, "--group", "crew", "--version", "1",
+		"--kind", "FirstMate", "--resource", "--controller", "--make=false", "--diff"})
+	if err := c.Run(); err == nil {
+		t.Fatal("expected an invalid version error")
+	}
+	if !filesystem.IsDryRun() {
+		t.Error("expected --diff to run the command dry")
+	}
+	if c.diffOutput == nil {
+		t.Error("expected --diff to print the diffs")
+	}
+}
+
+func TestRunChecksVersionOfOutputDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	project := "version: \"1\"\ndomain: example.org\nrepo: example.org/project\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "PROJECT"), []byte(project), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"lint", "api", "--output-dir", dir}, "not supported by the v1 projects"},
+		{[]string{"create", "webhook", "--output-dir", dir}, "not supported by the v1 projects"},
+		{[]string{"update", "vendor"}, "only supported by the v1 projects"},
+	}
+	for _, test := range tests {
+		// the version of the project is detected once the flags are parsed
+		c, err := New()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		c.Command().SetArgs(test.args)
+		if err := c.Run(); err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%v: expected an error containing %q, got %v", test.args, test.expected, err)
+		}
+	}
+}
+
+func TestFindCurrentRepoDryRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filesystem.DryRun()
+	defer filesystem.Reset()
+
+	// neither an existing directory nor one created by the dry run is initialized as a module
+	for _, d := range []string{dir, filepath.Join(dir, "project")} {
+		if err := filesystem.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := findCurrentRepo(d); err == nil || !strings.Contains(err.Error(), "--repo") {
+			t.Errorf("%s: expected an error asking for --repo, got %v", d, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); !os.IsNotExist(err) {
+		t.Errorf("expected no go.mod to be written, got %v", err)
+	}
+}
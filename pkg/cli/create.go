@@ -14,17 +14,15 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package cli
 
 import (
 	"fmt"
 
 	"github.com/spf13/cobra"
-
-	"sigs.k8s.io/kubebuilder/cmd/internal"
 )
 
-func newCreateCmd() *cobra.Command {
+func (c *CLI) newCreateCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "create",
 		Short: "Scaffold a Kubernetes API or webhook.",
@@ -34,14 +32,14 @@ func newCreateCmd() *cobra.Command {
 		},
 	}
 	cmd.AddCommand(
-		newAPICommand(),
+		c.newAPICommand(),
 	)
-
-	if !internal.ConfiguredAndV1() {
-		cmd.AddCommand(
-			newWebhookV2Cmd(),
-		)
-	}
+	cmd.AddCommand(exceptV1(
+		c.newWebhookV2Cmd(),
+		c.newStorageMigrationCmd(),
+		c.newScaleTestCmd(),
+		c.newValidationCmd(),
+	)...)
 
 	return cmd
 }
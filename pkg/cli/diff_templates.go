@@ -0,0 +1,271 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/pkg/cli/internal"
+	"sigs.k8s.io/kubebuilder/pkg/license"
+	"sigs.k8s.io/kubebuilder/pkg/migrate"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold"
+	"sigs.k8s.io/kubebuilder/pkg/version"
+)
+
+// diffTemplatesFormats are the formats of the output of alpha diff-templates
+var diffTemplatesFormats = []string{"text", "json", "yaml"}
+
+// diffTemplatesOptions represents the options of alpha diff-templates
+type diffTemplatesOptions struct {
+	// cli is the command line the command belongs to, e.g. for its --output-dir
+	cli *CLI
+
+	// format is the format of the changes, one of diffTemplatesFormats
+	format string
+
+	// nameOnly prints the paths of the changed files without their diffs
+	nameOnly bool
+
+	// exitCode exits with 1 when a file would change
+	exitCode bool
+}
+
+func (c *CLI) newDiffTemplatesCmd() *cobra.Command {
+	o := diffTemplatesOptions{cli: c}
+
+	cmd := &cobra.Command{
+		Use:   "diff-templates",
+		Short: "Compare the scaffolded files of the project with the current templates.",
+		Long: `Compare the scaffolded files of the project with the current templates.
+
+The scaffold operations recorded in SCAFFOLD_INFO.yaml, e.g. init and create api with their flags, are run again
+by this version of kubebuilder in a temporary directory, and the files it scaffolds are compared with the files of
+the project recorded in .kubebuilder/checksums.yaml. Nothing in the project is written.
+
+Every change is reported with its unified diff, as the upgrade to the current templates would make it:
+- update: a file unmodified since it was scaffolded whose template changed, it would be overwritten
+- create: a file the current templates scaffold which doesn't exist in the project
+- delete: a file unmodified since it was scaffolded which the current templates don't scaffold anymore
+- conflict: a file modified since it was scaffolded whose template differs, the change has to be merged by hand
+
+The files whose template didn't change are left out, even if they were modified. The copyright year of the
+boilerplate is the one of the project.
+`,
+		Example: `	# Review the changes an upgrade of kubebuilder would make to the project
+	kubebuilder alpha diff-templates
+
+	# List the files that would change, and fail the CI when the project is behind the templates
+	kubebuilder alpha diff-templates --name-only --exit-code
+
+	# Write the changes as JSON for the tooling upgrading the projects of a monorepo
+	kubebuilder alpha diff-templates --format json
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := internal.CheckConfigured(c.outputDir); err != nil {
+				return err
+			}
+
+			plan, err := o.diff()
+			if err != nil {
+				return err
+			}
+			if err := o.print(plan); err != nil {
+				return err
+			}
+			if o.exitCode && len(plan.Actions) > 0 {
+				return fmt.Errorf("%d file(s) differ from the current templates", len(plan.Actions))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&o.format, "format", "text",
+		fmt.Sprintf("format of the changes, one of %v", diffTemplatesFormats))
+	cmd.Flags().BoolVar(&o.nameOnly, "name-only", false,
+		"if set, print the changed files without their diffs (text format only)")
+	cmd.Flags().BoolVar(&o.exitCode, "exit-code", false,
+		"if set, exit with 1 when any file would change")
+
+	return cmd
+}
+
+// diff scaffolds the project again with the current templates and returns the changes of its scaffolded files
+func (o *diffTemplatesOptions) diff() (*migrate.Plan, error) {
+	if !o.validFormat() {
+		return nil, fmt.Errorf("unknown format %q, must be one of %v", o.format, diffTemplatesFormats)
+	}
+
+	projectConfig, err := config.ReadFrom(config.PathIn(o.cli.outputDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the configuration file: %v", err)
+	}
+	q, err := projectConfig.Query()
+	if err != nil {
+		return nil, err
+	}
+	if !q.SupportsScaffoldMarkers() {
+		return nil, fmt.Errorf("alpha diff-templates is only supported by the projects of version 2, the "+
+			"version of this project is %s", projectConfig.Version)
+	}
+
+	info, err := internal.ReadScaffoldInfo(o.cli.outputDir)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%s not found, the scaffold operations of the project weren't recorded",
+			internal.ScaffoldInfoFile)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(info.Operations) == 0 || info.Operations[0].Command != "init" {
+		return nil, fmt.Errorf("the project was initialized before its operations were recorded in %s, it "+
+			"can't be scaffolded again", internal.ScaffoldInfoFile)
+	}
+
+	checksums, err := scaffold.ReadChecksums(o.cli.outputDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(checksums) == 0 {
+		fmt.Fprintf(os.Stderr, "warning: no file is recorded in %s, the existing files differing from their "+
+			"template are reported as conflicts\n", filepath.Join(scaffold.MetadataDir, "checksums.yaml"))
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("unable to locate the kubebuilder binary: %v", err)
+	}
+	abs, err := filepath.Abs(o.cli.outputDir)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := ioutil.TempDir("", "kubebuilder-diff-templates")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	// the project is scaffolded again in a directory of the same name, the default name of the project
+	dir := filepath.Join(tmp, filepath.Base(abs))
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	if err := internal.Replay(executable, dir, info.Operations, projectConfig.Repo, checksums); err != nil {
+		return nil, err
+	}
+
+	current := version.Get().KubeBuilderVersion
+	rendered, err := migrate.ReadScaffolded(current, dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := keepBoilerplate(o.cli.outputDir, rendered); err != nil {
+		return nil, err
+	}
+
+	// the recorded files are loaded along with the rendered ones
+	recorded, err := migrate.ReadScaffolded(info.KubebuilderVersion, o.cli.outputDir)
+	if err != nil {
+		return nil, err
+	}
+	state, err := migrate.LoadState(o.cli.outputDir, rendered, recorded)
+	if err != nil {
+		return nil, err
+	}
+	return migrate.DiffTemplates(info.KubebuilderVersion, rendered, state), nil
+}
+
+// keepBoilerplate replaces the boilerplate of the rendered files, e.g. of the current year, with the one of the
+// project rooted at the provided directory
+func keepBoilerplate(dir string, rendered *migrate.Bundle) error {
+	replayed, found := rendered.Files[filepath.ToSlash(license.DefaultFile)]
+	if !found {
+		return nil
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, license.DefaultFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	old, boilerplate := strings.TrimSpace(replayed), strings.TrimSpace(string(b))
+	if old == "" || old == boilerplate {
+		return nil
+	}
+	for path, content := range rendered.Files {
+		rendered.Files[path] = strings.Replace(content, old, boilerplate, 1)
+	}
+	return nil
+}
+
+// validFormat returns true if the format is one of diffTemplatesFormats
+func (o *diffTemplatesOptions) validFormat() bool {
+	for _, f := range diffTemplatesFormats {
+		if f == o.format {
+			return true
+		}
+	}
+	return false
+}
+
+// print writes the changes to stdout in the format
+func (o *diffTemplatesOptions) print(plan *migrate.Plan) error {
+	switch o.format {
+	case "json":
+		out, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	case "yaml":
+		out, err := yaml.Marshal(plan)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(out))
+		return nil
+	}
+
+	if len(plan.Actions) == 0 {
+		fmt.Printf("The scaffolded files are up to date with the templates of kubebuilder %s.\n", plan.To)
+		return nil
+	}
+	conflicts := len(plan.Conflicts())
+	fmt.Printf("Scaffolding the project again with kubebuilder %s, it was scaffolded with %s, changes %d "+
+		"file(s), %d to merge by hand:\n", plan.To, plan.From, len(plan.Actions), conflicts)
+	for _, action := range plan.Actions {
+		fmt.Printf("  %-9s %s\n", action.Type, action.Path)
+	}
+	if o.nameOnly {
+		return nil
+	}
+	for _, action := range plan.Actions {
+		fmt.Printf("\n%s", action.Diff)
+	}
+	return nil
+}
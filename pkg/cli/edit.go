@@ -0,0 +1,168 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/pkg/cli/internal"
+	"sigs.k8s.io/kubebuilder/pkg/logging"
+	modelconfig "sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+)
+
+func (c *CLI) newEditProjectCmd() *cobra.Command {
+
+	opts := editProjectCmdOptions{}
+
+	editProjectCmd := &cobra.Command{
+		Use:   "edit",
+		Short: "This command will edit the project configuration",
+		Long:  `This command will edit the project configuration`,
+		Example: `
+		# To enable the multigroup layout/support
+		kubebuilder edit --multigroup
+		
+		# To disable the multigroup layout/support
+		kubebuilder edit --multigroup=false
+
+		# To keep the scaffolds from setting up the reconciler of the Captains in main.go again, e.g. after
+		# moving it behind a feature gate
+		kubebuilder edit --group crew --version v1 --kind Captain --wire-controller=false
+
+		# To register the types of the Prometheus operator in the schemes of main.go and of the suites of the
+		# controllers, and require its module in go.mod
+		kubebuilder edit --register-scheme monitoring.coreos.com/v1
+
+		# To register the types of an API group version whose package is not known by kubebuilder
+		kubebuilder edit --register-scheme example.com/v1=example.com/operator/api/v1`,
+		Annotations: map[string]string{internal.ScaffoldAnnotation: ""},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := internal.CheckConfigured(c.outputDir); err != nil {
+				return err
+			}
+
+			unlock, err := internal.LockProject(c.outputDir)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			projectConfig, err := config.LoadFrom(config.PathIn(c.outputDir))
+			if err != nil {
+				return fmt.Errorf("failed to read the configuration file: %v", err)
+			}
+
+			var schemes []modelconfig.Scheme
+			for _, spec := range opts.registerSchemes {
+				s, err := scaffold.ParseScheme(spec)
+				if err != nil {
+					return err
+				}
+				schemes = append(schemes, s)
+			}
+
+			if opts.multigroup {
+				q, err := projectConfig.Query()
+				if err != nil {
+					return fmt.Errorf("failed to read the configuration file: %v", err)
+				}
+				if !q.SupportsMultiGroup() {
+					return fmt.Errorf("kubebuilder multigroup is for project version: 2,"+
+						" the version of this project is: %s", projectConfig.Version)
+				}
+
+				// Set MultiGroup Option
+				projectConfig.MultiGroup = true
+			}
+
+			if cmd.Flags().Changed("wire-controller") || cmd.Flags().Changed("wire-webhook") {
+				if opts.res.Group == "" || opts.res.Version == "" || opts.res.Kind == "" {
+					return fmt.Errorf("the --group, --version and --kind of the resource whose wiring is edited are required")
+				}
+				wiring := projectConfig.WiringOf(opts.res)
+				if cmd.Flags().Changed("wire-controller") {
+					wiring.SkipController = !opts.wireController
+				}
+				if cmd.Flags().Changed("wire-webhook") {
+					wiring.SkipWebhook = !opts.wireWebhook
+				}
+				if !projectConfig.SetWiring(opts.res, wiring) {
+					return fmt.Errorf("group '%s', version '%s' and kind '%s' is not a resource of the project",
+						opts.res.Group, opts.res.Version, opts.res.Kind)
+				}
+			}
+
+			err = projectConfig.Save()
+			if err != nil {
+				return fmt.Errorf("error updating project file with resource information : %v", err)
+			}
+
+			if len(schemes) > 0 {
+				s := &scaffold.Schemes{OutputDir: c.outputDir, Schemes: schemes}
+				if err := s.Validate(); err != nil {
+					return err
+				}
+				if err := s.Scaffold(); err != nil {
+					return fmt.Errorf("error registering the schemes: %v", err)
+				}
+				for _, scheme := range schemes {
+					if scheme.Module == "" {
+						logging.Info(fmt.Sprintf("Next: require the module of the types of %s/%s with:\n$ go get %s",
+							scheme.Group, scheme.Version, scheme.Package))
+					}
+				}
+			}
+			return nil
+		},
+	}
+
+	editProjectCmd.Flags().BoolVar(&opts.multigroup, "multigroup", false,
+		"if set as true, then the tool will generate the project files with multigroup layout")
+	opts.res = gvkForFlags(editProjectCmd.Flags())
+	editProjectCmd.Flags().BoolVar(&opts.wireController, "wire-controller", true,
+		"if set as false, the scaffolds don't set up the reconciler of the resource in main.go, "+
+			"e.g. when it is set up conditionally by hand")
+	editProjectCmd.Flags().BoolVar(&opts.wireWebhook, "wire-webhook", true,
+		"if set as false, the scaffolds don't set up the webhooks of the resource in main.go")
+	editProjectCmd.Flags().StringSliceVar(&opts.registerSchemes, "register-scheme", nil,
+		"API group versions defined outside of the project whose types are registered in the schemes of main.go "+
+			"and of the suites of the controllers, e.g. monitoring.coreos.com/v1, or example.com/v1=<package> for the "+
+			"ones not among "+strings.Join(scaffold.KnownSchemes(), ","))
+
+	return editProjectCmd
+}
+
+type editProjectCmdOptions struct {
+	multigroup bool
+
+	// res is the resource whose wiring in main.go is edited
+	res *resource.Resource
+
+	// wireController and wireWebhook are the preferences of the wiring of res in main.go
+	wireController bool
+	wireWebhook    bool
+
+	// registerSchemes are the API group versions defined outside of the project to register, see
+	// scaffold.ParseScheme
+	registerSchemes []string
+}
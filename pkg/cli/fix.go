@@ -0,0 +1,201 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/pkg/cli/internal"
+	"sigs.k8s.io/kubebuilder/pkg/license"
+	scaffoldv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2"
+)
+
+func (c *CLI) newFixCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fix",
+		Short: "Fix the files of the project.",
+		Long:  `Fix the files of the project.`,
+	}
+	cmd.AddCommand(
+		c.newFixLicenseHeadersCmd(),
+		c.newFixImportAliasesCmd(),
+	)
+
+	return cmd
+}
+
+func (c *CLI) newFixLicenseHeadersCmd() *cobra.Command {
+	var (
+		updateYear bool
+		year       int
+		spdx       string
+		check      bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "license-headers",
+		Short: "Re-stamp the license headers of the Go files from the boilerplate.",
+		Long: `Re-stamp the license headers of the Go files of the project from the boilerplate file,
+hack/boilerplate.go.txt, templated with the license, the owner and the year at init.
+
+The first comment of a file mentioning a copyright or a license is replaced by the boilerplate, the files without
+one get it at the top. The directories vendor, bin and testdata are left alone.
+
+With --update-year, the copyright year of the boilerplate file is first set to the current year, or to --year,
+e.g. on the first release of the year. Run "make generate" afterwards or let the files generated by controller-gen
+be re-stamped along with the others.
+`,
+		Example: `	# Re-stamp the headers once the boilerplate changed
+	kubebuilder fix license-headers
+
+	# Update the copyright year of the boilerplate and of the headers
+	kubebuilder fix license-headers --update-year
+
+	# Add the SPDX identifier of the license to the headers
+	kubebuilder fix license-headers --spdx Apache-2.0
+
+	# Fail if a header is missing or outdated, e.g. in the CI
+	kubebuilder fix license-headers --check
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := internal.CheckConfigured(c.outputDir); err != nil {
+				return err
+			}
+
+			path := filepath.Join(c.outputDir, license.DefaultFile)
+			b, err := ioutil.ReadFile(path) // nolint:gosec
+			if err != nil {
+				return fmt.Errorf("failed to read the boilerplate file: %v", err)
+			}
+			boilerplate := string(b)
+			if updateYear {
+				boilerplate = license.UpdateYear(boilerplate, year)
+				if boilerplate != string(b) && !check {
+					if err := ioutil.WriteFile(path, []byte(boilerplate), 0644); err != nil {
+						return fmt.Errorf("failed to write the boilerplate file: %v", err)
+					}
+					fmt.Printf("Updated %s\n", license.DefaultFile)
+				}
+			}
+
+			header, err := license.Header(boilerplate, license.SPDX(spdx))
+			if err != nil {
+				return err
+			}
+			dir := c.outputDir
+			if dir == "" {
+				dir = "."
+			}
+			changed, err := license.Fix(dir, header, check)
+			if err != nil {
+				return err
+			}
+			for _, p := range changed {
+				fmt.Println(p)
+			}
+			if check && len(changed) > 0 {
+				return fmt.Errorf("%d file(s) with a missing or outdated license header, run kubebuilder fix license-headers",
+					len(changed))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&updateYear, "update-year", false,
+		"if set, update the copyright year of the boilerplate file before stamping the headers")
+	cmd.Flags().IntVar(&year, "year", time.Now().Year(), "copyright year set by --update-year")
+	cmd.Flags().StringVar(&spdx, "spdx", "", "SPDX identifier of the license added to the headers, e.g. Apache-2.0")
+	cmd.Flags().BoolVar(&check, "check", false,
+		"if set, list the files whose header is missing or outdated without changing them and fail if any")
+
+	return cmd
+}
+
+func (c *CLI) newFixImportAliasesCmd() *cobra.Command {
+	var check bool
+
+	cmd := &cobra.Command{
+		Use:   "import-aliases",
+		Short: "Rename the imports of the registered schemes whose alias collides with another group version.",
+		Long: `Rename the imports of the API group versions registered with --register-scheme whose alias collides with
+the alias of a group version of the project or of another registered group version.
+
+The packages of the group versions are imported as the first label of their group followed by their version,
+e.g. certmanagerv1, unless the alias is taken, e.g. by the group monitoring of the project for
+monitoring.coreos.com/v1, they are then imported as their whole group followed by their version, e.g.
+monitoringcoreoscomv1. The group versions of the project keep their aliases.
+
+The older scaffolds imported them by their short alias regardless, main.go or the suites of the controllers
+then either import two packages under the same name or skip the registration of the group version. The imports
+and their uses are renamed in the Go files of the project, and the skipped registrations are added. The imports
+named by hand are left alone.
+`,
+		Example: `	# Rename the colliding imports
+	kubebuilder fix import-aliases
+
+	# Fail if an import collides, e.g. in the CI
+	kubebuilder fix import-aliases --check
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := internal.CheckConfigured(c.outputDir); err != nil {
+				return err
+			}
+
+			projectConfig, err := config.LoadFrom(config.PathIn(c.outputDir))
+			if err != nil {
+				return fmt.Errorf("failed to read the configuration file: %v", err)
+			}
+			if !check {
+				unlock, err := internal.LockProject(c.outputDir)
+				if err != nil {
+					return err
+				}
+				defer unlock()
+			}
+
+			renamed, err := scaffoldv2.FixImportAliases(c.outputDir, &projectConfig.Config, check)
+			if err != nil {
+				return err
+			}
+			for _, p := range renamed {
+				fmt.Println(p)
+			}
+			if check {
+				if len(renamed) > 0 {
+					return fmt.Errorf("%d file(s) with a colliding import alias, run kubebuilder fix import-aliases",
+						len(renamed))
+				}
+				return nil
+			}
+			if err := scaffoldv2.RegisterSchemes(c.outputDir, &projectConfig.Config, projectConfig.Schemes); err != nil {
+				return fmt.Errorf("error registering the schemes: %v", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&check, "check", false,
+		"if set, list the files with a colliding import alias without changing them and fail if any")
+
+	return cmd
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/pkg/cli/internal"
+	"sigs.k8s.io/kubebuilder/pkg/logging"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/examples"
+)
+
+func (c *CLI) newGenerateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate code derived from the APIs of the project.",
+		Long:  `Generate code derived from the APIs of the project, overwritten every time it is generated.`,
+		Run: func(cmd *cobra.Command, args []string) {
+			fmt.Println("Coming soon.")
+		},
+	}
+	cmd.AddCommand(
+		c.newGenerateExamplesCmd(),
+	)
+
+	return cmd
+}
+
+func (c *CLI) newGenerateExamplesCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "examples",
+		Short: "Generate sample programs of the clients of the APIs of the project.",
+		Long: `Generate sample programs of the clients of the APIs of the project in ` + examples.Dir + `, for the
+teams consuming them: a program per kind of the project creates, lists and updates its objects with a
+controller-runtime client, in the cluster of the current kubeconfig, and the scheme package registers the kinds
+of the project along with the built-in kinds.
+
+The examples are generated from the kinds of the PROJECT file and overwrite the previous ones. Once generated,
+they are generated again by kubebuilder create api, keeping them in sync with the APIs of the project.
+`,
+		Example: `	# Generate the examples of every kind of the project
+	kubebuilder generate examples
+
+	# Create, list and update a Frigate of ship/v1beta1 in the default namespace
+	go run ./examples/client-go/ship_v1beta1_frigate -namespace default -name frigate-example
+`,
+		Annotations: map[string]string{internal.ScaffoldAnnotation: ""},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := internal.CheckConfigured(c.outputDir); err != nil {
+				return err
+			}
+
+			unlock, err := internal.LockProject(c.outputDir)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			e := &scaffold.Examples{OutputDir: c.outputDir}
+			if err := e.Validate(); err != nil {
+				return err
+			}
+
+			logging.Info("Writing the examples...")
+			if err := e.Scaffold(); err != nil {
+				return err
+			}
+			logging.Path(examples.Dir)
+			return nil
+		},
+	}
+}
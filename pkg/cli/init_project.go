@@ -0,0 +1,511 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+
+	"sigs.k8s.io/kubebuilder/cmd/util"
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/cli/internal"
+	"sigs.k8s.io/kubebuilder/pkg/logging"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/sbom"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/project"
+)
+
+// newInitProjectCmd returns the init command, scaffolding projects of projectVersion unless --project-version is set
+func (c *CLI) newInitProjectCmd(projectVersion string) *cobra.Command {
+	o := projectOptions{cli: c, defaultProjectVersion: projectVersion}
+
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "Initialize a new project",
+		Long: `Initialize a new project including vendor/ directory and Go package directories.
+
+Writes the following files:
+- a boilerplate license file
+- a PROJECT file with the project configuration
+- a Makefile to build the project
+- a go.mod with project dependencies
+- a Kustomization.yaml for customizating manifests
+- a Patch file for customizing image for manager manifests
+- a Patch file for enabling prometheus metrics, served behind the kube-rbac-proxy sidecar by default or over
+  TLS by the manager (--metrics-protection tls): the manager then serves them with a certificate of cert-manager
+  to the clients authorized by the API server, and the deployment patches, the certificate, the RBAC and the TLS
+  configuration of the prometheus monitor match it
+- a kustomize component exposing the metrics endpoint through an Ingress or a Gateway (--expose)
+- a LimitRange and a ResourceQuota for the namespace of the manager (--resource-quota)
+- a tenancy package isolating the tenants by namespace and the RoleBinding of a tenant (--tenancy namespace)
+- the manifests mounting the credentials of the manager, e.g. the keys of a cloud provider, from an external
+  secret store, loaded by the credentials package (--secret-store): a SecretProviderClass of the Secrets Store
+  CSI driver (csi) or an ExternalSecret of the external-secrets operator (external-secrets)
+- the provider sets and the injector of the reconcilers and the webhooks, generated by make generate
+  (--dependency-injection wire)
+- manifests for the clusters of the minimum Kubernetes version and above (--min-k8s-version), e.g. the
+  apiextensions.k8s.io/v1 CRDs from 1.16
+- a cmd/manager/main.go to run, registering the types of the API group versions defined outside of the
+  project, e.g. by cert-manager (--register-scheme)
+- an SPDX SBOM of the dependencies once they are fetched (sbom.spdx.json), refreshed by make sbom
+- a SCAFFOLD_INFO.yaml recording the scaffold operations for the bug reports, checked by kubebuilder verify
+
+project will prompt the user to run 'dep ensure' after writing the project files.
+`,
+		Example: `# Scaffold a project using the apache2 license with "The Kubernetes authors" as owners
+kubebuilder init --domain example.org --license apache2 --owner "The Kubernetes authors"
+
+# Scaffold a project generating its CRDs, roles and webhook configurations under deploy instead of config
+kubebuilder init --domain example.org --crd-dir deploy/crd --rbac-dir deploy/rbac --webhook-dir deploy/webhook
+
+# Scaffold a project naming the reconciler types e.g. FirstMateController and the files e.g. first_mate_types.go
+kubebuilder init --domain example.org --reconciler-suffix Controller --file-names snake
+
+# Scaffold a project whose tenants are isolated by namespace: the reconciles of the objects of a namespace only
+# access the objects of this namespace, and the manager may be limited to the namespaces of the tenants
+kubebuilder init --domain example.org --tenancy namespace
+
+# Scaffold a project whose manager loads its credentials from the files mounted by the Secrets Store CSI driver
+kubebuilder init --domain example.org --secret-store csi
+
+# Scaffold a project whose reconcilers and webhooks are injected by google/wire from the provider sets of
+# providers.go rather than constructed by main.go
+kubebuilder init --domain example.org --dependency-injection wire
+
+# Scaffold a project whose manager serves its metrics over TLS with a certificate of cert-manager, to the
+# clients authorized to get /metrics, rather than behind the kube-rbac-proxy sidecar
+kubebuilder init --domain example.org --metrics-protection tls
+
+# Scaffold a project whose metrics endpoint is exposed out of the cluster by an Ingress, deployed by
+# uncommenting the component in config/default/kustomization.yaml
+kubebuilder init --domain example.org --expose ingress
+
+# Scaffold a project for the clusters of Kubernetes 1.19 and above: apiextensions.k8s.io/v1 CRDs,
+# networking.k8s.io/v1 ingresses and the seccomp profile of the container runtime for the manager
+kubebuilder init --domain example.org --min-k8s-version 1.19
+
+# Scaffold a project whose controllers use the ServiceMonitors of the Prometheus operator, registered in the
+# schemes of main.go and of the suites of the controllers
+kubebuilder init --domain example.org --register-scheme monitoring.coreos.com/v1
+`,
+		Annotations: map[string]string{internal.ScaffoldAnnotation: ""},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.initializeProject()
+		},
+	}
+
+	o.bindCmdlineFlags(initCmd)
+
+	return initCmd
+}
+
+type projectOptions struct {
+	cli *CLI
+
+	boilerplate project.Boilerplate
+	project     project.Project
+
+	// defaultProjectVersion is the default of --project-version
+	defaultProjectVersion string
+	// query is the view of the configuration in terms of the capabilities of the project version
+	query config.Query
+
+	// final result
+	scaffolder scaffold.ProjectScaffolder
+
+	// deprecated flags
+	depFlag *flag.Flag
+	depArgs []string
+
+	// flags
+	fetchDeps          bool
+	skipGoVersionCheck bool
+	expose             string
+	resourceQuota      bool
+	image              string
+	registerSchemes    []string
+
+	// deprecated flags
+	dep bool
+}
+
+func (o *projectOptions) bindCmdlineFlags(cmd *cobra.Command) {
+
+	cmd.Flags().BoolVar(&o.skipGoVersionCheck, "skip-go-version-check",
+		false, "if specified, skip checking the Go version")
+
+	// dependency args
+	cmd.Flags().BoolVar(&o.fetchDeps, "fetch-deps", true, "ensure dependencies are downloaded")
+
+	// deprecated dependency args
+	cmd.Flags().BoolVar(&o.dep, "dep", true, "if specified, determines whether dep will be used.")
+	o.depFlag = cmd.Flag("dep")
+	cmd.Flags().StringArrayVar(&o.depArgs, "depArgs", nil, "Additional arguments for dep")
+
+	if err := cmd.Flags().MarkDeprecated("dep", "use the fetch-deps flag instead"); err != nil {
+		log.Printf("error to mark dep flag as deprecated: %v", err)
+	}
+	if err := cmd.Flags().MarkDeprecated("depArgs", "will be removed with version 1 scaffolding"); err != nil {
+		log.Printf("error to mark dep flag as deprecated: %v", err)
+	}
+
+	// boilerplate args
+	cmd.Flags().StringVar(&o.boilerplate.Path, "path", "", "path for boilerplate")
+	cmd.Flags().StringVar(&o.boilerplate.License, "license", "apache2",
+		"license to use to boilerplate.  May be one of apache2,none")
+	cmd.Flags().StringVar(&o.boilerplate.Owner, "owner", "", "Owner to add to the copyright")
+	cmd.Flags().StringVar(&o.boilerplate.Year, "year", "", "copyright year, defaults to the current year")
+
+	// project args
+	cmd.Flags().StringVar(&o.project.Repo, "repo", "",
+		"name to use for go module, e.g. github.com/user/repo.  "+
+			"defaults to the go package of the current working directory.")
+	cmd.Flags().StringVar(&o.project.Domain, "domain", "my.domain", "domain for groups")
+	cmd.Flags().StringVar(&o.project.Version, "project-version", o.defaultProjectVersion, "project version")
+	cmd.Flags().StringVar(&o.image, "image", "controller:latest", "controller manager image, only for project version 2")
+
+	// manifests args
+	o.project.Manifests = &config.Manifests{}
+	cmd.Flags().StringVar(&o.project.Manifests.CRDDir, "crd-dir", "",
+		"directory the CRDs and their kustomization are generated into, defaults to config/crd, "+
+			"only for project version 2")
+	cmd.Flags().StringVar(&o.project.Manifests.RBACDir, "rbac-dir", "",
+		"directory the roles and their kustomization are generated into, defaults to config/rbac, "+
+			"only for project version 2")
+	cmd.Flags().StringVar(&o.project.Manifests.WebhookDir, "webhook-dir", "",
+		"directory the webhook configurations and their kustomization are generated into, "+
+			"defaults to config/webhook, only for project version 2")
+
+	// naming args
+	o.project.Naming = &config.Naming{}
+	cmd.Flags().StringVar(&o.project.Naming.ReconcilerPrefix, "reconciler-prefix", "",
+		"prefix of the kind in the names of the reconciler types, only for project version 2")
+	cmd.Flags().StringVar(&o.project.Naming.ReconcilerSuffix, "reconciler-suffix", "",
+		"suffix of the kind in the names of the reconciler types, defaults to Reconciler, "+
+			"only for project version 2")
+	cmd.Flags().StringVar(&o.project.Naming.FileNames, "file-names", "",
+		"case of the kind in the names of its files, one of lower,snake, defaults to lower, "+
+			"only for project version 2")
+
+	// exposure args
+	cmd.Flags().StringVar(&o.expose, "expose", "",
+		"if set, expose the metrics endpoint out of the cluster through one of ingress,gateway")
+
+	// quota args
+	cmd.Flags().BoolVar(&o.resourceQuota, "resource-quota", false,
+		"scaffold a LimitRange and a ResourceQuota for the namespace of the manager, deployed by config/default, "+
+			"only for project version 2")
+
+	// tenancy args
+	cmd.Flags().StringVar(&o.project.Tenancy, "tenancy", "",
+		"way the tenants of the operator are isolated, one of namespace: the objects of a tenant are the ones of "+
+			"its namespace, only for project version 2")
+
+	// secret store args
+	cmd.Flags().StringVar(&o.project.SecretStore, "secret-store", "",
+		"if set, mount the credentials of the manager from one of csi,external-secrets, only for project version 2")
+
+	// metrics protection args
+	cmd.Flags().StringVar(&o.project.MetricsProtection, "metrics-protection", "",
+		"if set, protect the metrics endpoint with one of rbac-proxy,tls, only for project version 2")
+
+	// dependency injection args
+	cmd.Flags().StringVar(&o.project.DependencyInjection, "dependency-injection", "",
+		"framework injecting the reconcilers and the webhooks set up by main.go, one of wire: google/wire "+
+			"generates their injector from the provider sets of providers.go, only for project version 2")
+
+	// compatibility args
+	cmd.Flags().StringVar(&o.project.MinKubernetesVersion, "min-k8s-version", "",
+		"oldest Kubernetes version the manifests are scaffolded for, e.g. 1.16, selecting the API versions of the "+
+			"CRDs, the webhook configurations and the ingresses and the fields of the manager deployment, "+
+			"only for project version 2")
+
+	// scheme args
+	cmd.Flags().StringSliceVar(&o.registerSchemes, "register-scheme", nil,
+		"API group versions defined outside of the project whose types are registered in the schemes of main.go "+
+			"and of the suites of the controllers, e.g. monitoring.coreos.com/v1, or example.com/v1=<package> for the "+
+			"ones not among "+strings.Join(scaffold.KnownSchemes(), ",")+", only for project version 2")
+}
+
+func (o *projectOptions) initializeProject() error {
+	if err := internal.CheckNotConfigured(o.cli.outputDir); err != nil {
+		return err
+	}
+
+	if err := o.validate(); err != nil {
+		return err
+	}
+
+	if !o.query.SupportsScaffoldMarkers() {
+		printV1DeprecationWarning()
+	}
+
+	if err := o.scaffolder.Scaffold(); err != nil {
+		return fmt.Errorf("error scaffolding project: %v", err)
+	}
+
+	if err := o.postScaffold(); err != nil {
+		return err
+	}
+
+	logging.Info("Next: Define a resource with:\n" +
+		"$ kubebuilder create api")
+	return nil
+}
+
+func (o *projectOptions) validate() error {
+	q, err := o.project.Query()
+	if err != nil {
+		return err
+	}
+	o.query = q
+
+	if !o.skipGoVersionCheck {
+		if err := validateGoVersion(); err != nil {
+			return err
+		}
+	}
+
+	if o.boilerplate.Year != "" {
+		if _, err := strconv.Atoi(o.boilerplate.Year); err != nil || len(o.boilerplate.Year) != 4 {
+			return fmt.Errorf("invalid copyright year %q", o.boilerplate.Year)
+		}
+	}
+
+	if o.cli.outputDir != "" {
+		if !o.query.SupportsScaffoldMarkers() {
+			return fmt.Errorf("--output-dir is not supported for project version %s", o.project.Version)
+		}
+		if err := filesystem.MkdirAll(o.cli.outputDir, 0755); err != nil {
+			return fmt.Errorf("error to create the output directory: %v", err)
+		}
+	}
+
+	// use directory name as prefix
+	dir, err := filepath.Abs(o.cli.outputDir)
+	if err != nil {
+		return fmt.Errorf("error to get the project path: %v", err)
+	}
+
+	// check if the name of th project pass is a valid name for k8s objects
+	// it will be used to create the namespace
+	projectName := filepath.Base(dir)
+	if err := util.IsValidName(strings.ToLower(projectName)); err != nil {
+		return fmt.Errorf("project name (%v) is invalid: (%v)", projectName, err)
+	}
+
+	if o.project.Repo == "" {
+		repoPath, err := findCurrentRepo(o.cli.outputDir)
+		if err != nil {
+			return fmt.Errorf("error finding current repository: %v", err)
+		}
+		o.project.Repo = repoPath
+	}
+
+	// the directories are only recorded in the configuration when set
+	if *o.project.Manifests == (config.Manifests{}) {
+		o.project.Manifests = nil
+	}
+	// so are the naming policies
+	if *o.project.Naming == (config.Naming{}) {
+		o.project.Naming = nil
+	}
+
+	switch {
+	case !o.query.SupportsScaffoldMarkers():
+		if o.expose != "" {
+			return fmt.Errorf("--expose is not supported for project version %s", o.project.Version)
+		}
+		if o.resourceQuota {
+			return fmt.Errorf("--resource-quota is not supported for project version %s", o.project.Version)
+		}
+		if o.project.Tenancy != "" {
+			return fmt.Errorf("--tenancy is not supported for project version %s", o.project.Version)
+		}
+		if o.project.SecretStore != "" {
+			return fmt.Errorf("--secret-store is not supported for project version %s", o.project.Version)
+		}
+		if o.project.MetricsProtection != "" {
+			return fmt.Errorf("--metrics-protection is not supported for project version %s", o.project.Version)
+		}
+		if o.project.DependencyInjection != "" {
+			return fmt.Errorf("--dependency-injection is not supported for project version %s", o.project.Version)
+		}
+		if o.project.MinKubernetesVersion != "" {
+			return fmt.Errorf("--min-k8s-version is not supported for project version %s", o.project.Version)
+		}
+		if len(o.registerSchemes) > 0 {
+			return fmt.Errorf("--register-scheme is not supported for project version %s", o.project.Version)
+		}
+		if o.project.Manifests != nil {
+			return fmt.Errorf("--crd-dir, --rbac-dir and --webhook-dir are not supported for project version %s",
+				o.project.Version)
+		}
+		if o.project.Naming != nil {
+			return fmt.Errorf("--reconciler-prefix, --reconciler-suffix and --file-names are not supported "+
+				"for project version %s", o.project.Version)
+		}
+
+		var defEnsure *bool
+		if o.depFlag.Changed {
+			defEnsure = &o.dep
+		}
+		o.scaffolder = &scaffold.V1Project{
+			Project:     o.project,
+			Boilerplate: o.boilerplate,
+
+			DepArgs:          o.depArgs,
+			DefinitelyEnsure: defEnsure,
+			CommandOutput:    o.cli.commandOutput,
+			Diff:             o.cli.diffOutput,
+		}
+	default:
+		// the minimum version is recorded as the minor version, e.g. 1.16 for v1.16
+		if o.project.MinKubernetesVersion != "" {
+			c, err := config.CompatibilityOf(o.project.MinKubernetesVersion)
+			if err != nil {
+				return err
+			}
+			o.project.MinKubernetesVersion = c.MinKubernetesVersion
+		}
+		for _, spec := range o.registerSchemes {
+			s, err := scaffold.ParseScheme(spec)
+			if err != nil {
+				return err
+			}
+			o.project.AddScheme(s)
+		}
+		o.scaffolder = &scaffold.V2Project{
+			Project:       o.project,
+			Boilerplate:   o.boilerplate,
+			OutputDir:     o.cli.outputDir,
+			Expose:        o.expose,
+			ResourceQuota: o.resourceQuota,
+			Image:         o.image,
+			CommandOutput: o.cli.commandOutput,
+			Diff:          o.cli.diffOutput,
+		}
+	}
+
+	if err := o.scaffolder.Validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func validateGoVersion() error {
+	err := fetchAndCheckGoVersion()
+	if err != nil {
+		return fmt.Errorf("%s. You can skip this check using the --skip-go-version-check flag", err)
+	}
+	return nil
+}
+
+func fetchAndCheckGoVersion() error {
+	cmd := exec.Command("go", "version")
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to retrieve 'go version': %v", string(out))
+	}
+
+	split := strings.Split(string(out), " ")
+	if len(split) < 3 {
+		return fmt.Errorf("found invalid Go version: %q", string(out))
+	}
+	goVer := split[2]
+	if err := checkGoVersion(goVer); err != nil {
+		return fmt.Errorf("go version '%s' is incompatible because '%s'", goVer, err)
+	}
+	return nil
+}
+
+func checkGoVersion(verStr string) error {
+	goVerRegex := `^go?([0-9]+)\.([0-9]+)([\.0-9A-Za-z\-]+)?$`
+	m := regexp.MustCompile(goVerRegex).FindStringSubmatch(verStr)
+	if m == nil {
+		return fmt.Errorf("invalid version string")
+	}
+
+	major, err := strconv.Atoi(m[1])
+	if err != nil {
+		return fmt.Errorf("error parsing major version '%s': %s", m[1], err)
+	}
+
+	minor, err := strconv.Atoi(m[2])
+	if err != nil {
+		return fmt.Errorf("error parsing minor version '%s': %s", m[2], err)
+	}
+
+	if major < 1 || minor < 11 {
+		return fmt.Errorf("requires version >= 1.11")
+	}
+
+	return nil
+}
+
+func (o *projectOptions) postScaffold() error {
+	if filesystem.IsDryRun() {
+		logging.Info("Skipping fetching dependencies and running make in a dry run.")
+		return nil
+	}
+
+	// preserve old "ask if not explicitly set" behavior for the `--dep` flag
+	// (asking is handled by the v1 scaffolder)
+	if (o.depFlag.Changed && !o.dep) || !o.fetchDeps {
+		logging.Info("Skipping fetching dependencies.")
+		return nil
+	}
+
+	endDependencies := logging.Phase("dependencies")
+	ensured, err := o.scaffolder.EnsureDependencies()
+	endDependencies()
+	if err != nil {
+		return err
+	}
+
+	if !ensured {
+		return nil
+	}
+
+	logging.Info("Running make...")
+	defer logging.Phase("make")()
+	c := exec.Command("make") // #nosec
+	c.Dir = o.cli.outputDir
+	c.Stderr = os.Stderr
+	c.Stdout = o.cli.commandOutput
+	logging.Command(c.Args)
+	if err := c.Run(); err != nil {
+		return err
+	}
+
+	if o.query.SupportsScaffoldMarkers() {
+		logging.Info(fmt.Sprintf("Writing the SBOM of the dependencies to %s...", sbom.DefaultFile))
+		return writeSBOM(o.cli.outputDir, sbom.DefaultFile)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/logging"
+)
+
+// CheckNotConfigured returns an error if a configuration file was found in the provided project root
+func CheckNotConfigured(dir string) error {
+	exists, err := config.ExistsIn(dir)
+	if err != nil {
+		return fmt.Errorf("unable to check if configuration file exists: %v", err)
+	}
+	if exists {
+		return fmt.Errorf("project is already initialized")
+	}
+	return nil
+}
+
+// CheckConfigured returns an error if no configuration file was found in the provided project root
+func CheckConfigured(dir string) error {
+	exists, err := config.ExistsIn(dir)
+	if err != nil {
+		return fmt.Errorf("unable to check if configuration file exists: %v", err)
+	}
+	if !exists {
+		return fmt.Errorf("command must be run after `kubebuilder init ...`")
+	}
+	return nil
+}
+
+// ConfiguredAndV1 returns true if the project rooted at the provided directory is already configured and its version
+// doesn't support the scaffold markers, i.e. v1: the commands extending the project at the markers are replaced by
+// the ones of its version
+func ConfiguredAndV1(dir string) bool {
+	// the commands reading the configuration file report the errors reading it
+	projectConfig, err := config.ReadFrom(config.PathIn(dir))
+	if err != nil {
+		return false
+	}
+	q, err := projectConfig.Query()
+	if err != nil {
+		// the commands of the unknown project versions refuse them
+		return false
+	}
+
+	return !q.SupportsScaffoldMarkers()
+}
+
+// LockProject locks the project rooted at the provided directory while the command modifies it, failing if another
+// command holds the lock, and returns the function releasing it. The commands holding the lock return their errors
+// rather than exiting, which would skip the deferred release and leave the lock behind.
+func LockProject(dir string) (func(), error) {
+	// a dry run leaves the project untouched, there is nothing to lock
+	if filesystem.IsDryRun() {
+		return func() {}, nil
+	}
+
+	lock, err := config.AcquireLock(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return func() {
+		if err := lock.Release(); err != nil {
+			logging.Warning(err.Error())
+		}
+	}, nil
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// EnvPrefix is the prefix of the environment variables providing flag defaults
+	EnvPrefix = "KUBEBUILDER_"
+
+	// userConfigEnv is the environment variable overriding the path of the user configuration file
+	userConfigEnv = EnvPrefix + "CONFIG"
+
+	// userConfigFile is the name of the user configuration file in the home directory
+	userConfigFile = ".kubebuilder.yaml"
+)
+
+// UserConfig is the unmarshalled representation of the user configuration file, which provides
+// defaults for the flags of every command, indexed by flag name
+type UserConfig struct {
+	// Defaults are the flag values used by every profile
+	Defaults map[string]interface{} `json:"defaults,omitempty"`
+
+	// Profiles are named sets of flag values overriding the defaults
+	Profiles map[string]map[string]interface{} `json:"profiles,omitempty"`
+}
+
+// UserConfigPath returns the path of the user configuration file
+func UserConfigPath() (string, error) {
+	if path := os.Getenv(userConfigEnv); path != "" {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, userConfigFile), nil
+}
+
+// ReadUserConfig reads the user configuration file from the provided path, a missing file is an empty configuration
+func ReadUserConfig(path string) (*UserConfig, error) {
+	c := &UserConfig{}
+
+	in, err := ioutil.ReadFile(path) // nolint: gosec
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(in, c); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+	return c, nil
+}
+
+// values returns the flag values of the provided profile, the defaults if no profile is provided
+func (c UserConfig) values(profile string) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(c.Defaults))
+	for name, value := range c.Defaults {
+		values[name] = value
+	}
+
+	if profile == "" {
+		return values, nil
+	}
+
+	profileValues, found := c.Profiles[profile]
+	if !found {
+		return nil, fmt.Errorf("unknown profile %q", profile)
+	}
+	for name, value := range profileValues {
+		values[name] = value
+	}
+	return values, nil
+}
+
+// EnvVar returns the name of the environment variable providing the default of a flag
+func EnvVar(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.Replace(flagName, "-", "_", -1))
+}
+
+// ApplyDefaults sets the flags that were not provided in the command line, first from the KUBEBUILDER_*
+// environment variables and then from the selected profile of the user configuration.
+// Flags that are not set by any of them keep their default value.
+func ApplyDefaults(flags *pflag.FlagSet, c *UserConfig, profile string) error {
+	values, err := c.values(profile)
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Changed || f.Name == "help" {
+			return
+		}
+
+		if value, found := os.LookupEnv(EnvVar(f.Name)); found {
+			if err := flags.Set(f.Name, value); err != nil {
+				errs = append(errs, fmt.Sprintf("invalid value %q for %s: %v", value, EnvVar(f.Name), err))
+			}
+			return
+		}
+
+		if value, found := values[f.Name]; found {
+			switch value.(type) {
+			case map[string]interface{}, []interface{}:
+				errs = append(errs, fmt.Sprintf("invalid value for %s in the user configuration: must be a scalar", f.Name))
+				return
+			}
+			if err := flags.Set(f.Name, fmt.Sprint(value)); err != nil {
+				errs = append(errs, fmt.Sprintf("invalid value %v for %s in the user configuration: %v", value, f.Name, err))
+			}
+		}
+	})
+
+	if len(errs) != 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
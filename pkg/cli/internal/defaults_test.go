@@ -0,0 +1,110 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestApplyDefaults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubebuilder-defaults")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.yaml")
+	content := `defaults:
+  domain: config.org
+  owner: Config Owner
+  license: none
+  fetch-deps: false
+profiles:
+  platform:
+    owner: Platform Team
+`
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := ReadUserConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Setenv(EnvVar("license"), "apache2"); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Unsetenv(EnvVar("license")) // nolint:errcheck
+
+	tests := []struct {
+		profile  string
+		args     []string
+		expected map[string]string
+	}{
+		{
+			args: nil,
+			expected: map[string]string{
+				"domain": "config.org", "owner": "Config Owner", "license": "apache2", "fetch-deps": "false", "repo": "",
+			},
+		},
+		{
+			args: []string{"--domain", "flag.org", "--license", "none"},
+			expected: map[string]string{
+				"domain": "flag.org", "owner": "Config Owner", "license": "none", "fetch-deps": "false", "repo": "",
+			},
+		},
+		{
+			profile: "platform",
+			args:    nil,
+			expected: map[string]string{
+				"domain": "config.org", "owner": "Platform Team", "license": "apache2", "fetch-deps": "false", "repo": "",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		flags := pflag.NewFlagSet("init", pflag.ContinueOnError)
+		flags.String("domain", "my.domain", "")
+		flags.String("owner", "", "")
+		flags.String("license", "apache2", "")
+		flags.Bool("fetch-deps", true, "")
+		flags.String("repo", "", "")
+		if err := flags.Parse(test.args); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ApplyDefaults(flags, c, test.profile); err != nil {
+			t.Fatalf("unexpected error applying the defaults of profile %q: %v", test.profile, err)
+		}
+
+		for name, value := range test.expected {
+			if actual := flags.Lookup(name).Value.String(); actual != value {
+				t.Errorf("flag %s with args %v and profile %q: expected %q, got %q",
+					name, test.args, test.profile, value, actual)
+			}
+		}
+	}
+
+	if err := ApplyDefaults(pflag.NewFlagSet("init", pflag.ContinueOnError), c, "unknown"); err == nil {
+		t.Errorf("expected an error for an unknown profile")
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// replayIgnoredFlags are the flags of the recorded operations which don't change what is scaffolded, e.g. the
+// format of the messages, left out of the replays
+var replayIgnoredFlags = map[string]bool{
+	"dry-run":    true,
+	"diff":       true,
+	"output":     true,
+	"verbose":    true,
+	"log-format": true,
+	"profile":    true,
+}
+
+// replayFlags are appended to the replays of the commands running tools once they scaffolded the files, for
+// them to scaffold the files only
+var replayFlags = map[string][]string{
+	"init":       {"--fetch-deps=false", "--skip-go-version-check"},
+	"create api": {"--make=false"},
+}
+
+// ReplayArgs returns the arguments of kubebuilder replaying the operation of a project of the repo whose
+// scaffolded files are recorded, keyed by slash separated path. The resource and the controller of create api
+// are prompted for when their flags aren't set, the answers, which aren't recorded, are told by the recorded
+// files of the kind.
+func ReplayArgs(op ScaffoldOperation, repo string, recorded map[string]string) []string {
+	args := append(strings.Fields(op.Command), op.Args...)
+	set := map[string]string{}
+	for _, flag := range op.Flags {
+		name := strings.TrimPrefix(strings.SplitN(flag, "=", 2)[0], "--")
+		if replayIgnoredFlags[name] {
+			continue
+		}
+		value := strings.TrimPrefix(flag, "--"+name+"=")
+		// the flags of several values were recorded by the older versions as a list, e.g. --short-name=[fm]
+		if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+			value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+			flag = "--" + name + "=" + value
+		}
+		set[name] = value
+		args = append(args, flag)
+	}
+
+	switch op.Command {
+	case "init":
+		if _, found := set["repo"]; !found && repo != "" {
+			args = append(args, "--repo="+repo)
+		}
+	case "create api":
+		kind := strings.ToLower(set["kind"])
+		for _, prompted := range []struct{ flag, file string }{
+			{flag: "resource", file: kind + "_types.go"},
+			{flag: "controller", file: kind + "_controller.go"},
+		} {
+			if _, found := set[prompted.flag]; !found {
+				args = append(args, fmt.Sprintf("--%s=%t", prompted.flag, hasRecorded(recorded, prompted.file)))
+			}
+		}
+	}
+	return append(args, replayFlags[op.Command]...)
+}
+
+// hasRecorded returns true if a file named name is recorded in any directory
+func hasRecorded(recorded map[string]string, name string) bool {
+	for p := range recorded {
+		if path.Base(p) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Replay runs the executable of kubebuilder with the arguments of every operation in turn in dir, e.g. an empty
+// directory to scaffold the project again with the current templates. The replays don't read the KUBEBUILDER_*
+// environment variables nor the user configuration, the recorded flags were defaulted from them already.
+func Replay(executable, dir string, operations []ScaffoldOperation, repo string, recorded map[string]string) error {
+	env := []string{userConfigEnv + "=" + os.DevNull}
+	for _, e := range os.Environ() {
+		// the plugins stay enabled for the operations of a --pattern
+		if !strings.HasPrefix(e, EnvPrefix) || strings.HasPrefix(e, EnvPrefix+"ENABLE_PLUGINS=") {
+			env = append(env, e)
+		}
+	}
+
+	for n, op := range operations {
+		c := exec.Command(executable, ReplayArgs(op, repo, recorded)...) // #nosec
+		c.Dir = dir
+		c.Env = env
+		if out, err := c.CombinedOutput(); err != nil {
+			return fmt.Errorf("unable to replay operation %d, %s: %v: %s", n+1, op.Command, err,
+				strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
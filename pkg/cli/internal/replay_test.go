@@ -0,0 +1,124 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestReplayArgs(t *testing.T) {
+	recorded := map[string]string{
+		"api/v1/captain_types.go":           "sha256:1",
+		"controllers/captain_controller.go": "sha256:2",
+		"api/v1/firstmate_types.go":         "sha256:3",
+	}
+
+	tests := []struct {
+		name     string
+		op       ScaffoldOperation
+		expected []string
+	}{
+		{
+			name: "init without repo",
+			op:   ScaffoldOperation{Command: "init", Flags: []string{"--domain=example.org", "--log-format=json"}},
+			expected: []string{"init", "--domain=example.org", "--repo=example.org/project", "--fetch-deps=false",
+				"--skip-go-version-check"},
+		},
+		{
+			name:     "init with repo",
+			op:       ScaffoldOperation{Command: "init", Flags: []string{"--repo=example.org/other"}},
+			expected: []string{"init", "--repo=example.org/other", "--fetch-deps=false", "--skip-go-version-check"},
+		},
+		{
+			name: "create api with prompted resource and controller",
+			op:   ScaffoldOperation{Command: "create api", Flags: []string{"--kind=Captain", "--dry-run=true"}},
+			expected: []string{"create", "api", "--kind=Captain", "--resource=true", "--controller=true",
+				"--make=false"},
+		},
+		{
+			name:     "create api without controller",
+			op:       ScaffoldOperation{Command: "create api", Flags: []string{"--kind=FirstMate"}},
+			expected: []string{"create", "api", "--kind=FirstMate", "--resource=true", "--controller=false", "--make=false"},
+		},
+		{
+			name: "create api with flags",
+			op: ScaffoldOperation{Command: "create api",
+				Flags: []string{"--kind=Admiral", "--resource=true", "--controller=false"}},
+			expected: []string{"create", "api", "--kind=Admiral", "--resource=true", "--controller=false",
+				"--make=false"},
+		},
+		{
+			name: "create api with slice flags recorded as lists",
+			op: ScaffoldOperation{Command: "create api",
+				Flags: []string{"--kind=Admiral", "--short-name=[adm,ad]", "--resource=true", "--controller=true"}},
+			expected: []string{"create", "api", "--kind=Admiral", "--short-name=adm,ad", "--resource=true",
+				"--controller=true", "--make=false"},
+		},
+		{
+			name:     "edit",
+			op:       ScaffoldOperation{Command: "edit", Flags: []string{"--multigroup=true", "--verbose=1"}},
+			expected: []string{"edit", "--multigroup=true"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if args := ReplayArgs(test.op, "example.org/project", recorded); !reflect.DeepEqual(args, test.expected) {
+				t.Errorf("expected %q, got %q", test.expected, args)
+			}
+		})
+	}
+}
+
+func TestReplayArgsOfRecordedFlags(t *testing.T) {
+	newFlags := func() *pflag.FlagSet {
+		flags := pflag.NewFlagSet("create api", pflag.ContinueOnError)
+		flags.String("kind", "", "")
+		flags.StringSlice("short-name", nil, "")
+		flags.StringSlice("category", []string{"all"}, "")
+		flags.StringArray("adopt", nil, "")
+		flags.Bool("resource", true, "")
+		flags.Bool("controller", true, "")
+		flags.Bool("make", true, "")
+		return flags
+	}
+
+	recorded := newFlags()
+	if err := recorded.Parse([]string{"--kind=Admiral", "--short-name=adm,ad", "--category=", "--short-name=adml",
+		"--adopt=core/v1/ConfigMap"}); err != nil {
+		t.Fatal(err)
+	}
+	op := ScaffoldOperation{Command: "create api", Flags: changedFlags(recorded)}
+
+	args := ReplayArgs(op, "example.org/project", map[string]string{})
+	replayed := newFlags()
+	if err := replayed.Parse(args[2:]); err != nil {
+		t.Fatalf("unable to parse the replayed flags %q: %v", args, err)
+	}
+	for _, name := range []string{"short-name", "category"} {
+		expected, _ := recorded.GetStringSlice(name)
+		if got, _ := replayed.GetStringSlice(name); !reflect.DeepEqual(got, expected) {
+			t.Errorf("expected --%s %q, got %q", name, expected, got)
+		}
+	}
+	expected, _ := recorded.GetStringArray("adopt")
+	if got, _ := replayed.GetStringArray("adopt"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected --adopt %q, got %q", expected, got)
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	modelconfig "sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+)
+
+// ReportFormats are the formats of the scaffold reports
+var ReportFormats = []string{"json", "yaml"}
+
+// scaffoldMarker prefixes the name of the markers the scaffolds inject code at, e.g. +kubebuilder:scaffold:imports
+const scaffoldMarker = "+kubebuilder:scaffold:"
+
+// ScaffoldReport is the machine-readable report of what a scaffolding command did, for the CI and the tools
+// wrapping kubebuilder to parse
+type ScaffoldReport struct {
+	// Command is the command without the name of the binary, e.g. create api
+	Command string `json:"command"`
+
+	// DryRun is true if the files were not written
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Created and Updated are the slash separated paths of the files the command created and modified, relative
+	// to the project root
+	Created []string `json:"created"`
+	Updated []string `json:"updated"`
+
+	// Markers are the lines injected at the scaffold markers of the updated files, e.g. the registration of a
+	// controller at +kubebuilder:scaffold:builder in main.go
+	Markers []InjectedLines `json:"markers,omitempty"`
+
+	// Project is the change of the PROJECT file, nil if it didn't change
+	Project *ProjectChange `json:"project,omitempty"`
+}
+
+// InjectedLines are the lines injected at a scaffold marker of a file
+type InjectedLines struct {
+	// Path is the slash separated path of the file relative to the project root
+	Path string `json:"path"`
+
+	// Marker is the name of the marker, e.g. builder for +kubebuilder:scaffold:builder
+	Marker string `json:"marker"`
+
+	Lines []string `json:"lines"`
+}
+
+// ProjectChange is the change of the PROJECT file
+type ProjectChange struct {
+	// Fields are the top-level fields that changed, e.g. resources
+	Fields []string `json:"fields"`
+
+	// AddedResources are the resources the command added
+	AddedResources []modelconfig.GVK `json:"addedResources,omitempty"`
+}
+
+// NewScaffoldReport reports the files created and modified by the command, as recorded by a dry run or a tracked
+// run of the filesystem, in the project rooted at dir
+func NewScaffoldReport(dir, command string) (*ScaffoldReport, error) {
+	changes, err := filesystem.Changes()
+	if err != nil {
+		return nil, err
+	}
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ScaffoldReport{Command: command, DryRun: filesystem.IsDryRun(), Created: []string{}, Updated: []string{}}
+	for _, change := range changes {
+		path, err := filepath.Abs(change.Path)
+		if err != nil {
+			return nil, err
+		}
+		if rel, err := filepath.Rel(root, path); err == nil {
+			path = rel
+		}
+		path = filepath.ToSlash(path)
+
+		if change.Action == filesystem.Create {
+			report.Created = append(report.Created, path)
+			continue
+		}
+		report.Updated = append(report.Updated, path)
+		previous, current, err := filesystem.Contents(change)
+		if err != nil {
+			return nil, err
+		}
+		for _, insertion := range util.Insertions(string(previous), string(current)) {
+			i := strings.Index(insertion.Before, scaffoldMarker)
+			if i < 0 {
+				continue
+			}
+			report.Markers = append(report.Markers, InjectedLines{
+				Path:   path,
+				Marker: strings.TrimSpace(insertion.Before[i+len(scaffoldMarker):]),
+				Lines:  insertion.Lines,
+			})
+		}
+		if path == config.DefaultPath {
+			if report.Project, err = diffProject(previous, current); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return report, nil
+}
+
+// diffProject returns the change between the previous and the current content of the PROJECT file
+func diffProject(previous, current []byte) (*ProjectChange, error) {
+	var before, after map[string]interface{}
+	if err := yaml.Unmarshal(previous, &before); err != nil {
+		return nil, fmt.Errorf("unable to parse the previous %s: %v", config.DefaultPath, err)
+	}
+	if err := yaml.Unmarshal(current, &after); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", config.DefaultPath, err)
+	}
+
+	change := &ProjectChange{}
+	for field, value := range after {
+		if !reflect.DeepEqual(before[field], value) {
+			change.Fields = append(change.Fields, field)
+		}
+	}
+	for field := range before {
+		if _, found := after[field]; !found {
+			change.Fields = append(change.Fields, field)
+		}
+	}
+	if len(change.Fields) == 0 {
+		return nil, nil
+	}
+	sort.Strings(change.Fields)
+
+	var beforeConfig, afterConfig modelconfig.Config
+	if err := yaml.Unmarshal(previous, &beforeConfig); err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(current, &afterConfig); err != nil {
+		return nil, err
+	}
+	for _, r := range afterConfig.Resources {
+		added := true
+		for _, existing := range beforeConfig.Resources {
+			if existing.Group == r.Group && existing.Version == r.Version && existing.Kind == r.Kind {
+				added = false
+				break
+			}
+		}
+		if added {
+			change.AddedResources = append(change.AddedResources, r)
+		}
+	}
+	return change, nil
+}
+
+// Write writes the report to w in the format, one of ReportFormats
+func (r *ScaffoldReport) Write(w io.Writer, format string) error {
+	var out []byte
+	var err error
+	switch format {
+	case "json":
+		out, err = json.MarshalIndent(r, "", "  ")
+		out = append(out, '\n')
+	case "yaml":
+		out, err = yaml.Marshal(r)
+	default:
+		return fmt.Errorf("unknown output format %q, must be one of %v", format, ReportFormats)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+)
+
+const reportMainGo = `package main
+
+import (
+	// +kubebuilder:scaffold:imports
+)
+
+func init() {
+	// +kubebuilder:scaffold:scheme
+}
+`
+
+const reportProject = `domain: example.org
+repo: example.org/project
+version: "2"
+`
+
+func TestScaffoldReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubebuilder-report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for path, content := range map[string]string{"main.go": reportMainGo, "PROJECT": reportProject} {
+		if err := ioutil.WriteFile(filepath.Join(dir, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	filesystem.DryRun()
+	defer filesystem.Reset()
+	writes := map[string]string{
+		filepath.Join("api", "v1", "frigate_types.go"): "package v1\n",
+		"main.go": `package main
+
+import (
+	shipv1 "example.org/project/api/v1"
+	// +kubebuilder:scaffold:imports
+)
+
+func init() {
+	_ = shipv1.AddToScheme(scheme)
+	// +kubebuilder:scaffold:scheme
+}
+`,
+		"PROJECT": reportProject + "resources:\n- group: ship\n  kind: Frigate\n  version: v1\n",
+	}
+	for path, content := range writes {
+		path = filepath.Join(dir, path)
+		if err := filesystem.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := filesystem.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := NewScaffoldReport(dir, "create api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &ScaffoldReport{
+		Command: "create api",
+		DryRun:  true,
+		Created: []string{"api/v1/frigate_types.go"},
+		Updated: []string{"PROJECT", "main.go"},
+		Markers: []InjectedLines{
+			{Path: "main.go", Marker: "imports", Lines: []string{`	shipv1 "example.org/project/api/v1"`}},
+			{Path: "main.go", Marker: "scheme", Lines: []string{"	_ = shipv1.AddToScheme(scheme)"}},
+		},
+		Project: &ProjectChange{
+			Fields:         []string{"resources"},
+			AddedResources: []config.GVK{{Group: "ship", Version: "v1", Kind: "Frigate"}},
+		},
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Errorf("expected %+v, got %+v", expected, report)
+	}
+
+	var out bytes.Buffer
+	if err := report.Write(&out, "json"); err != nil {
+		t.Fatal(err)
+	}
+	var written ScaffoldReport
+	if err := json.Unmarshal(out.Bytes(), &written); err != nil {
+		t.Fatalf("invalid JSON report %s: %v", out.String(), err)
+	}
+	if !reflect.DeepEqual(&written, expected) {
+		t.Errorf("expected the JSON report of %+v, got %+v", expected, written)
+	}
+	if err := report.Write(&out, "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/version"
+)
+
+const (
+	// ScaffoldInfoFile is the file of the project root recording how the project was scaffolded
+	ScaffoldInfoFile = "SCAFFOLD_INFO.yaml"
+
+	// ScaffoldAnnotation annotates the commands scaffolding the project, which are recorded in the ScaffoldInfoFile
+	ScaffoldAnnotation = "kubebuilder.io/scaffold"
+
+	scaffoldInfoHeader = `# SCAFFOLD_INFO.yaml records how this project was scaffolded by kubebuilder, nothing is sent anywhere.
+# Attach it to the bug reports about the generated code, and check it with kubebuilder verify.
+# It is updated by every scaffold operation, do not edit it.
+`
+)
+
+// ScaffoldInfo is the unmarshalled representation of the ScaffoldInfoFile
+type ScaffoldInfo struct {
+	// KubebuilderVersion is the version of kubebuilder of the last operation
+	KubebuilderVersion string `json:"kubebuilderVersion"`
+
+	// Templates identifies the bundle of templates the project is scaffolded with, e.g. go/v2 for the
+	// projects of version 2
+	Templates string `json:"templates"`
+
+	// Created and Updated are the times of the first and the last operation
+	Created string `json:"created"`
+	Updated string `json:"updated"`
+
+	// Operations are the scaffold operations, in order
+	Operations []ScaffoldOperation `json:"operations"`
+}
+
+// ScaffoldOperation is a run of a command scaffolding the project
+type ScaffoldOperation struct {
+	// Command is the command without the name of the binary, e.g. create api
+	Command string `json:"command"`
+
+	// Args are the positional arguments of the command
+	Args []string `json:"args,omitempty"`
+
+	// Flags are the flags set on the command line or defaulted from the environment or the user configuration,
+	// e.g. --kind=Captain
+	Flags []string `json:"flags,omitempty"`
+
+	// KubebuilderVersion and GitCommit identify the build of kubebuilder
+	KubebuilderVersion string `json:"kubebuilderVersion"`
+	GitCommit          string `json:"gitCommit,omitempty"`
+
+	// Timestamp is the time of the operation in RFC 3339 format
+	Timestamp string `json:"timestamp"`
+}
+
+// TemplatesFor returns the bundle of templates of the projects of the version
+func TemplatesFor(projectVersion string) string {
+	return "go/v" + projectVersion
+}
+
+// ReadScaffoldInfo reads the ScaffoldInfoFile of the project rooted at dir
+func ReadScaffoldInfo(dir string) (*ScaffoldInfo, error) {
+	path := filepath.Join(dir, ScaffoldInfoFile)
+	in, err := filesystem.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &ScaffoldInfo{}
+	if err := yaml.UnmarshalStrict(in, info); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+	return info, nil
+}
+
+// WriteScaffoldInfo writes the ScaffoldInfoFile of the project rooted at dir
+func WriteScaffoldInfo(dir string, info *ScaffoldInfo) error {
+	out, err := yaml.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return filesystem.WriteFile(filepath.Join(dir, ScaffoldInfoFile), append([]byte(scaffoldInfoHeader), out...), 0644)
+}
+
+// Record appends the operation to the scaffold info of a project of the templates
+func (i *ScaffoldInfo) Record(op ScaffoldOperation, templates string) {
+	if len(i.Operations) == 0 {
+		i.Created = op.Timestamp
+	}
+	i.Operations = append(i.Operations, op)
+	i.KubebuilderVersion = op.KubebuilderVersion
+	i.Templates = templates
+	i.Updated = op.Timestamp
+}
+
+// RecordScaffold records the run of cmd with args in the ScaffoldInfoFile of the project rooted at dir
+func RecordScaffold(dir string, cmd *cobra.Command, args []string) error {
+	unlock, err := LockProject(dir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	projectConfig, err := config.ReadFrom(config.PathIn(dir))
+	if err != nil {
+		return fmt.Errorf("failed to read the configuration file: %v", err)
+	}
+
+	info, err := ReadScaffoldInfo(dir)
+	if err != nil {
+		// a project scaffolded before the operations were recorded, or whose file is invalid, starts a new one
+		info = &ScaffoldInfo{}
+	}
+
+	v := version.Get()
+	info.Record(ScaffoldOperation{
+		Command:            commandName(cmd),
+		Args:               args,
+		Flags:              changedFlags(cmd.Flags()),
+		KubebuilderVersion: v.KubeBuilderVersion,
+		GitCommit:          v.GitCommit,
+		Timestamp:          time.Now().UTC().Format(time.RFC3339),
+	}, TemplatesFor(projectConfig.Version))
+
+	if err := WriteScaffoldInfo(dir, info); err != nil {
+		return fmt.Errorf("unable to write %s: %v", ScaffoldInfoFile, err)
+	}
+	return nil
+}
+
+// ScaffoldCommands returns the names of the commands under root scaffolding the project, e.g. create api
+func ScaffoldCommands(root *cobra.Command) []string {
+	var names []string
+	for _, cmd := range root.Commands() {
+		if _, scaffolds := cmd.Annotations[ScaffoldAnnotation]; scaffolds {
+			names = append(names, commandName(cmd))
+		}
+		names = append(names, ScaffoldCommands(cmd)...)
+	}
+	return names
+}
+
+// commandName returns the path of the command without the name of the binary
+func commandName(cmd *cobra.Command) string {
+	path := strings.SplitN(cmd.CommandPath(), " ", 2)
+	if len(path) < 2 {
+		return ""
+	}
+	return path[1]
+}
+
+// changedFlags returns the flags that are set, but the root directory of the project which doesn't change what
+// is scaffolded. The flags of several values are returned once per value, e.g. --short-name=fm, for the replays to
+// set them again.
+func changedFlags(flags *pflag.FlagSet) []string {
+	var result []string
+	flags.Visit(func(f *pflag.Flag) {
+		if f.Name == "output-dir" {
+			return
+		}
+		var values []string
+		switch f.Value.Type() {
+		case "stringArray":
+			values, _ = flags.GetStringArray(f.Name)
+		case "stringSlice":
+			values, _ = flags.GetStringSlice(f.Name)
+			if len(values) == 0 {
+				// set explicitly empty, e.g. --category=
+				result = append(result, fmt.Sprintf("--%s=", f.Name))
+			}
+		default:
+			result = append(result, fmt.Sprintf("--%s=%s", f.Name, f.Value.String()))
+			return
+		}
+		for _, v := range values {
+			result = append(result, fmt.Sprintf("--%s=%s", f.Name, v))
+		}
+	})
+	return result
+}
+
+// Verify returns the problems of the scaffold info of a project of the version whose scaffold commands are
+// provided, and the warnings about the reproducibility of the scaffold with the current version of kubebuilder
+func (i *ScaffoldInfo) Verify(projectVersion string, commands []string, currentVersion string) ([]string, []string) {
+	var problems, warnings []string
+	if len(i.Operations) == 0 {
+		return append(problems, "no scaffold operation is recorded"), warnings
+	}
+
+	if templates := TemplatesFor(projectVersion); i.Templates != templates {
+		problems = append(problems, fmt.Sprintf("templates %q don't match the version %s of the project, "+
+			"expected %q", i.Templates, projectVersion, templates))
+	}
+
+	known := map[string]bool{}
+	for _, c := range commands {
+		known[c] = true
+	}
+	var previous time.Time
+	for n, op := range i.Operations {
+		if !known[op.Command] {
+			problems = append(problems, fmt.Sprintf("operation %d: unknown scaffold command %q", n+1, op.Command))
+		}
+		if op.KubebuilderVersion == "" {
+			problems = append(problems, fmt.Sprintf("operation %d: missing kubebuilderVersion", n+1))
+		}
+		timestamp, err := time.Parse(time.RFC3339, op.Timestamp)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("operation %d: invalid timestamp %q", n+1, op.Timestamp))
+			continue
+		}
+		if timestamp.Before(previous) {
+			problems = append(problems, fmt.Sprintf("operation %d: timestamp %s is before the one of the "+
+				"previous operation", n+1, op.Timestamp))
+		}
+		previous = timestamp
+	}
+
+	first, last := i.Operations[0], i.Operations[len(i.Operations)-1]
+	if i.Created != first.Timestamp {
+		problems = append(problems, fmt.Sprintf("created %q is not the time of the first operation %q",
+			i.Created, first.Timestamp))
+	}
+	if i.Updated != last.Timestamp {
+		problems = append(problems, fmt.Sprintf("updated %q is not the time of the last operation %q",
+			i.Updated, last.Timestamp))
+	}
+	if i.KubebuilderVersion != last.KubebuilderVersion {
+		problems = append(problems, fmt.Sprintf("kubebuilderVersion %q is not the version of the last operation %q",
+			i.KubebuilderVersion, last.KubebuilderVersion))
+	}
+
+	if first.Command != "init" {
+		warnings = append(warnings, fmt.Sprintf("the first recorded operation is %q, the project was initialized "+
+			"before its operations were recorded", first.Command))
+	}
+	if currentVersion != last.KubebuilderVersion {
+		warnings = append(warnings, fmt.Sprintf("the last operation was run by kubebuilder %s, this is %s: "+
+			"the same commands may scaffold different files", last.KubebuilderVersion, currentVersion))
+	}
+	return problems, warnings
+}
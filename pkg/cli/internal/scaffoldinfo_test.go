@@ -0,0 +1,141 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func TestScaffoldInfoRoundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubebuilder-scaffold-info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	info := &ScaffoldInfo{}
+	info.Record(ScaffoldOperation{Command: "init", Flags: []string{"--domain=example.org"},
+		KubebuilderVersion: "2.3.0", Timestamp: "2020-03-01T10:00:00Z"}, "go/v2")
+	info.Record(ScaffoldOperation{Command: "create api", Flags: []string{"--kind=Captain"},
+		KubebuilderVersion: "2.3.1", Timestamp: "2020-03-02T10:00:00Z"}, "go/v2")
+
+	if info.Created != "2020-03-01T10:00:00Z" || info.Updated != "2020-03-02T10:00:00Z" {
+		t.Errorf("unexpected times %q and %q", info.Created, info.Updated)
+	}
+	if info.KubebuilderVersion != "2.3.1" {
+		t.Errorf("expected the version of the last operation, got %q", info.KubebuilderVersion)
+	}
+
+	if err := WriteScaffoldInfo(dir, info); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadScaffoldInfo(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, info) {
+		t.Errorf("expected %+v, got %+v", info, read)
+	}
+}
+
+func TestScaffoldInfoVerify(t *testing.T) {
+	commands := []string{"init", "create api"}
+	valid := func() *ScaffoldInfo {
+		info := &ScaffoldInfo{}
+		info.Record(ScaffoldOperation{Command: "init", KubebuilderVersion: "2.3.0",
+			Timestamp: "2020-03-01T10:00:00Z"}, "go/v2")
+		info.Record(ScaffoldOperation{Command: "create api", KubebuilderVersion: "2.3.0",
+			Timestamp: "2020-03-02T10:00:00Z"}, "go/v2")
+		return info
+	}
+
+	problems, warnings := valid().Verify("2", commands, "2.3.0")
+	if len(problems) != 0 || len(warnings) != 0 {
+		t.Errorf("expected no problem nor warning, got %v and %v", problems, warnings)
+	}
+
+	_, warnings = valid().Verify("2", commands, "2.4.0")
+	if len(warnings) != 1 {
+		t.Errorf("expected a warning about the version, got %v", warnings)
+	}
+
+	tests := map[string]func(*ScaffoldInfo){
+		"templates":         func(i *ScaffoldInfo) { i.Templates = "go/v1" },
+		"unknown command":   func(i *ScaffoldInfo) { i.Operations[1].Command = "create foo" },
+		"missing version":   func(i *ScaffoldInfo) { i.Operations[0].KubebuilderVersion = "" },
+		"invalid timestamp": func(i *ScaffoldInfo) { i.Operations[1].Timestamp = "yesterday"; i.Updated = "yesterday" },
+		"unordered": func(i *ScaffoldInfo) {
+			i.Operations[1].Timestamp = "2020-02-01T10:00:00Z"
+			i.Updated = "2020-02-01T10:00:00Z"
+		},
+		"created": func(i *ScaffoldInfo) { i.Created = "2020-01-01T10:00:00Z" },
+		"updated": func(i *ScaffoldInfo) { i.Updated = "2020-01-01T10:00:00Z" },
+	}
+	for name, corrupt := range tests {
+		info := valid()
+		corrupt(info)
+		if problems, _ := info.Verify("2", commands, "2.3.0"); len(problems) != 1 {
+			t.Errorf("%s: expected a problem, got %v", name, problems)
+		}
+	}
+
+	if problems, _ := (&ScaffoldInfo{}).Verify("2", commands, "2.3.0"); len(problems) != 1 {
+		t.Errorf("expected a problem without operations, got %v", problems)
+	}
+}
+
+func TestChangedFlags(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("kind", "", "")
+	flags.String("group", "", "")
+	flags.String("output-dir", "", "")
+	flags.StringArray("adopt", nil, "")
+	flags.StringSlice("short-name", nil, "")
+	flags.StringSlice("category", []string{"all"}, "")
+	if err := flags.Parse([]string{"--kind=Captain", "--output-dir=/tmp", "--adopt=core/v1/ConfigMap",
+		"--adopt=apps/v1/Deployment", "--short-name=cpt,cap", "--category="}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"--adopt=core/v1/ConfigMap", "--adopt=apps/v1/Deployment", "--category=",
+		"--kind=Captain", "--short-name=cpt", "--short-name=cap"}
+	if got := changedFlags(flags); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestScaffoldCommands(t *testing.T) {
+	root := &cobra.Command{Use: "kubebuilder"}
+	create := &cobra.Command{Use: "create"}
+	create.AddCommand(&cobra.Command{Use: "api", Annotations: map[string]string{ScaffoldAnnotation: ""}})
+	root.AddCommand(create, &cobra.Command{Use: "version"},
+		&cobra.Command{Use: "init", Annotations: map[string]string{ScaffoldAnnotation: ""}})
+
+	names := map[string]bool{}
+	for _, name := range ScaffoldCommands(root) {
+		names[name] = true
+	}
+	if !reflect.DeepEqual(names, map[string]bool{"init": true, "create api": true}) {
+		t.Errorf("unexpected scaffold commands %v", names)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"errors"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/pkg/telemetry"
+	"sigs.k8s.io/kubebuilder/pkg/version"
+)
+
+// scaffoldRun is the event of the scaffolding command running, nil once it is emitted
+var scaffoldRun *telemetry.Event
+
+// StartScaffold starts the telemetry event of the run of cmd with args, emitted by FinishScaffold. Until then,
+// the log package finishes it as failed when it writes: the commands only log the fatal errors they exit on with
+// it.
+func StartScaffold(cmd *cobra.Command, args []string) {
+	scaffoldRun = &telemetry.Event{
+		Command:            commandName(cmd),
+		Args:               args,
+		Flags:              changedFlags(cmd.Flags()),
+		KubebuilderVersion: version.Get().KubeBuilderVersion,
+		Start:              time.Now(),
+	}
+	log.SetOutput(fatalRecorder{os.Stderr})
+}
+
+// FinishScaffold emits the telemetry event of the scaffolding command running, failed with err if it isn't nil.
+// It does nothing if no command runs, e.g. if the event was already emitted.
+func FinishScaffold(err error) {
+	if scaffoldRun == nil {
+		return
+	}
+	e := *scaffoldRun
+	scaffoldRun = nil
+
+	e.Duration = time.Since(e.Start)
+	e.Success = err == nil
+	if err != nil {
+		e.Error = err.Error()
+	}
+	telemetry.Emit(e)
+}
+
+// fatalRecorder finishes the scaffolding command as failed with the messages of the log package, before they are
+// written and the command exits. It only writes the messages once the event is emitted: it mustn't set the output
+// of the log package, which is locked while it writes.
+type fatalRecorder struct {
+	io.Writer
+}
+
+// logTimestampRe matches the timestamp prefixing the messages of the log package
+var logTimestampRe = regexp.MustCompile(`^\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}(\.\d+)? `)
+
+// Write implements io.Writer
+func (w fatalRecorder) Write(p []byte) (int, error) {
+	FinishScaffold(errors.New(logTimestampRe.ReplaceAllString(strings.TrimSpace(string(p)), "")))
+	return w.Writer.Write(p)
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/pkg/telemetry"
+)
+
+func TestScaffoldTelemetry(t *testing.T) {
+	var events []telemetry.Event
+	telemetry.SetHook(telemetry.HookFunc(func(e telemetry.Event) {
+		events = append(events, e)
+	}))
+	defer telemetry.SetHook(nil)
+	defer log.SetOutput(os.Stderr)
+
+	root := &cobra.Command{Use: "kubebuilder"}
+	create := &cobra.Command{Use: "create"}
+	api := &cobra.Command{Use: "api"}
+	api.Flags().String("kind", "", "")
+	root.AddCommand(create)
+	create.AddCommand(api)
+	if err := api.Flags().Set("kind", "Captain"); err != nil {
+		t.Fatal(err)
+	}
+
+	StartScaffold(api, nil)
+	FinishScaffold(nil)
+	// the event is only emitted once
+	FinishScaffold(fmt.Errorf("failed"))
+
+	// the fatal errors of the log package fail the command
+	StartScaffold(api, nil)
+	log.SetOutput(fatalRecorder{Writer: discard{}})
+	log.Print("API resource already exists")
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", events)
+	}
+	if e := events[0]; e.Command != "create api" || !reflect.DeepEqual(e.Flags, []string{"--kind=Captain"}) ||
+		!e.Success || e.Error != "" || e.Start.IsZero() {
+		t.Errorf("unexpected event of a success %+v", e)
+	}
+	if e := events[1]; e.Success || e.Error != "API resource already exists" {
+		t.Errorf("unexpected event of a failure %+v", e)
+	}
+}
+
+// discard is a writer discarding what it is written
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) {
+	return len(p), nil
+}
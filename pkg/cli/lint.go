@@ -0,0 +1,152 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/pkg/cli/internal"
+	"sigs.k8s.io/kubebuilder/pkg/lint"
+)
+
+func (c *CLI) newLintCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Check the project against the Kubernetes API conventions.",
+		Long: `Check the project against the Kubernetes API conventions, and the reconcilers against the mistakes
+of the controllers.`,
+	}
+	cmd.AddCommand(
+		c.newLintAPICmd(),
+		c.newLintControllersCmd(),
+	)
+
+	return cmd
+}
+
+func (c *CLI) newLintAPICmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "api [paths...]",
+		Short: "Check the API types for convention violations.",
+		Long: `Check the API types for convention violations.
+
+The following checks are performed over every struct declared in the API packages:
+- exported fields must have json tags
+- fields omitted when empty must be marked with // +optional
+- required fields must not be omitted when empty nor be marked as optional
+- optional struct fields must be pointers
+- spec types must not contain status fields
+- floating point types must not be used
+
+Each reported issue comes with a suggestion describing how to fix it.
+`,
+		Example: `	# Check every API package of the project
+	kubebuilder lint api
+
+	# Check a single API version
+	kubebuilder lint api api/v1
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := internal.CheckConfigured(c.outputDir); err != nil {
+				return err
+			}
+
+			projectConfig, err := config.ReadFrom(config.PathIn(c.outputDir))
+			if err != nil {
+				return fmt.Errorf("failed to read the configuration file: %v", err)
+			}
+
+			if len(args) == 0 {
+				if projectConfig.MultiGroup {
+					args = []string{filepath.Join(c.outputDir, "apis")}
+				} else {
+					args = []string{filepath.Join(c.outputDir, "api")}
+				}
+			}
+
+			var issues []lint.Issue
+			for _, path := range args {
+				pathIssues, err := lint.Dir(path)
+				if err != nil {
+					return fmt.Errorf("error checking %s: %v", path, err)
+				}
+				issues = append(issues, pathIssues...)
+			}
+
+			for _, issue := range issues {
+				fmt.Println(issue)
+			}
+			if len(issues) != 0 {
+				return fmt.Errorf("%d issue(s) found", len(issues))
+			}
+			return nil
+		},
+	}
+}
+
+func (c *CLI) newLintControllersCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "controllers [paths...]",
+		Short: "Check the reconcilers for writes of the spec of their own objects.",
+		Long: `Check the reconcilers for writes of the spec of their own objects.
+
+The spec of the objects of the kind a reconciler is set up For is owned by the users: the methods of the
+reconciler may update or patch their status, with Status().Update, Status().Patch or the patchStatus method of
+the scaffolded controllers, not the objects themselves. An Update or a Patch of an object of this kind is
+reported, unless preceded by the // +kubebuilder:lint:ignore=own-spec-write marker, e.g. when it only adds a
+finalizer.
+
+Each reported issue comes with a suggestion describing how to fix it.
+`,
+		Example: `	# Check every controller of the project
+	kubebuilder lint controllers
+
+	# Check the controllers of a single group
+	kubebuilder lint controllers controllers/crew
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := internal.CheckConfigured(c.outputDir); err != nil {
+				return err
+			}
+
+			if len(args) == 0 {
+				args = []string{filepath.Join(c.outputDir, "controllers")}
+			}
+
+			var issues []lint.Issue
+			for _, path := range args {
+				pathIssues, err := lint.ControllersDir(path)
+				if err != nil {
+					return fmt.Errorf("error checking %s: %v", path, err)
+				}
+				issues = append(issues, pathIssues...)
+			}
+
+			for _, issue := range issues {
+				fmt.Println(issue)
+			}
+			if len(issues) != 0 {
+				return fmt.Errorf("%d issue(s) found", len(issues))
+			}
+			return nil
+		},
+	}
+}
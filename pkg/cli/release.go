@@ -0,0 +1,225 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/pkg/cli/internal"
+	modelconfig "sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/release"
+)
+
+func (c *CLI) newReleaseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "release",
+		Short: "Prepare the releases of the project.",
+		Long:  `Prepare the releases of the project.`,
+	}
+	cmd.AddCommand(
+		c.newReleasePrepareCmd(),
+	)
+
+	return cmd
+}
+
+// releasePrepareOptions represents the options of release prepare
+type releasePrepareOptions struct {
+	// cli is the command line of the command, whose --output-dir is the project root
+	cli *CLI
+
+	// since is the git revision of the previous release
+	since string
+
+	// bump overrides the bump derived from the API changes
+	bump string
+
+	// changelog is the path of the changelog relative to the project root
+	changelog string
+
+	// dryRun prints the changelog entry and the versions without writing them
+	dryRun bool
+}
+
+func (c *CLI) newReleasePrepareCmd() *cobra.Command {
+	o := releasePrepareOptions{cli: c}
+
+	cmd := &cobra.Command{
+		Use:   "prepare",
+		Short: "Draft the API change notes of the release and bump the chart and CSV versions.",
+		Long: `Draft the API change notes of the release and bump the chart and CSV versions.
+
+The API types are compared with the ones of a previous revision, e.g. the tag of the previous release, kind by
+kind: the fields of the spec and the status added, removed or whose type or markers, e.g. their validation,
+changed. The notes are added as an entry to the changelog, breaking changes flagged.
+
+The version of the release follows the changes: removed kinds or fields, changed types and new required fields
+are breaking and bump the major version, the minor one for 0.x versions, new kinds and fields bump the minor
+version and other changes the patch one. The versions of the Helm charts (Chart.yaml) and of the OLM
+ClusterServiceVersions (*.clusterserviceversion.yaml) of the project are bumped accordingly, the charts of the
+resources of the hybrid pattern aside.
+`,
+		Example: `	# Draft the notes of the API changes since the v0.1.0 tag and bump the versions
+	kubebuilder release prepare --since v0.1.0
+
+	# Print the changelog entry and the bumped versions without writing them
+	kubebuilder release prepare --since v0.1.0 --dry-run
+
+	# Bump the minor version whatever the changes
+	kubebuilder release prepare --since v0.1.0 --bump minor
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := internal.CheckConfigured(c.outputDir); err != nil {
+				return err
+			}
+
+			projectConfig, err := config.ReadFrom(config.PathIn(c.outputDir))
+			if err != nil {
+				return fmt.Errorf("failed to read the configuration file: %v", err)
+			}
+
+			if o.since == "" {
+				return errors.New("--since is required, set it to the git revision of the previous release")
+			}
+			switch release.Bump(o.bump) {
+			case "", release.Major, release.Minor, release.Patch:
+			default:
+				return fmt.Errorf("invalid bump %q, expected %s, %s or %s", o.bump, release.Major, release.Minor, release.Patch)
+			}
+
+			if err := o.prepare(projectConfig); err != nil {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&o.since, "since", "",
+		"git revision of the previous release the API types are compared with, e.g. its tag")
+	cmd.Flags().StringVar(&o.bump, "bump", "",
+		"part of the versions to bump, one of major, minor and patch, derived from the API changes if not set")
+	cmd.Flags().StringVar(&o.changelog, "changelog", "CHANGELOG.md",
+		"changelog the entry of the release is added to, relative to the project root")
+	cmd.Flags().BoolVar(&o.dryRun, "dry-run", false,
+		"if set, print the changelog entry and the bumped versions without writing them")
+
+	return cmd
+}
+
+// prepare drafts the changelog entry of the release and bumps the versions of the project
+func (o *releasePrepareOptions) prepare(c *modelconfig.Config) error {
+	apiDirs := []string{"api"}
+	if c.MultiGroup {
+		apiDirs = []string{"apis"}
+	}
+
+	oldFiles, err := release.ReadAPIAt(o.cli.outputDir, o.since, apiDirs...)
+	if err != nil {
+		return fmt.Errorf("error reading the API types as of %s: %v", o.since, err)
+	}
+	newFiles, err := release.ReadAPI(o.cli.outputDir, apiDirs...)
+	if err != nil {
+		return fmt.Errorf("error reading the API types: %v", err)
+	}
+	oldKinds, err := release.ParseAPI(oldFiles)
+	if err != nil {
+		return fmt.Errorf("error parsing the API types as of %s: %v", o.since, err)
+	}
+	newKinds, err := release.ParseAPI(newFiles)
+	if err != nil {
+		return fmt.Errorf("error parsing the API types: %v", err)
+	}
+	changes := release.Diff(oldKinds, newKinds)
+
+	bump := release.Bump(o.bump)
+	if bump == "" {
+		bump = release.BumpFor(changes)
+	}
+
+	var skip []string
+	if c.Hybrid != nil && c.Hybrid.Source == modelconfig.HybridSourceHelm {
+		// the charts the manifests of the resources are rendered from aren't released
+		chartsDir := c.Hybrid.ChartsDir
+		if chartsDir == "" {
+			chartsDir = "charts"
+		}
+		skip = append(skip, chartsDir)
+	}
+	versionFiles, err := release.FindVersionFiles(o.cli.outputDir, skip...)
+	if err != nil {
+		return fmt.Errorf("error looking for the charts and the ClusterServiceVersions: %v", err)
+	}
+	paths := make([]string, 0, len(versionFiles))
+	for path := range versionFiles {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// the version of the release is the new version of the first chart or ClusterServiceVersion
+	version := "Unreleased"
+	bumped := map[string]string{}
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path) // nolint: gosec
+		if err != nil {
+			return err
+		}
+		bumpFile := release.BumpChart
+		if versionFiles[path] == release.CSV {
+			bumpFile = release.BumpCSV
+		}
+		updated, change, err := bumpFile(string(content), bump)
+		if err != nil {
+			return fmt.Errorf("error bumping the version of %s: %v", path, err)
+		}
+		bumped[path] = updated
+		if version == "Unreleased" {
+			version = change.To
+		}
+		fmt.Printf("%s: %s -> %s\n", path, change.From, change.To)
+	}
+
+	entry := fmt.Sprintf("## %s\n\n%s", version, release.Notes(changes, o.since))
+	fmt.Printf("Bumping the %s version, the changelog entry of the release is:\n\n%s\n", bump, entry)
+	if o.dryRun {
+		return nil
+	}
+
+	for path, content := range bumped {
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil { // nolint: gosec
+			return err
+		}
+	}
+	changelogPath := filepath.Join(o.cli.outputDir, o.changelog)
+	changelog, err := ioutil.ReadFile(changelogPath) // nolint: gosec
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := ioutil.WriteFile(changelogPath,
+		[]byte(release.AddChangelogEntry(string(changelog), entry)), 0644); err != nil { // nolint: gosec
+		return err
+	}
+	fmt.Printf("Added the entry to %s, review it before releasing\n", o.changelog)
+	return nil
+}
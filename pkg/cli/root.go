@@ -0,0 +1,402 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+	"golang.org/x/tools/go/packages"
+
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/cli/internal"
+	"sigs.k8s.io/kubebuilder/pkg/logging"
+)
+
+const (
+	NoticeColor = "\033[1;36m%s\033[0m"
+)
+
+// module and goMod arg just enough of the output of `go mod edit -json` for our purposes
+type goMod struct {
+	Module module
+}
+type module struct {
+	Path string
+}
+
+// findGoModulePath finds the path of the module in the provided directory, if present.
+func findGoModulePath(dir string, forceModules bool) (string, error) {
+	cmd := exec.Command("go", "mod", "edit", "-json")
+	cmd.Dir = dir
+	cmd.Env = append(cmd.Env, os.Environ()...)
+	if forceModules {
+		cmd.Env = append(cmd.Env, "GO111MODULE=on" /* turn on modules just for these commands */)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, isExitErr := err.(*exec.ExitError); isExitErr {
+			err = fmt.Errorf("%s", string(exitErr.Stderr))
+		}
+		return "", err
+	}
+	mod := goMod{}
+	if err := json.Unmarshal(out, &mod); err != nil {
+		return "", err
+	}
+	return mod.Module.Path, nil
+}
+
+// findCurrentRepo attempts to determine the repository of the provided directory
+// though a combination of go/packages and `go mod` commands/tricks.
+func findCurrentRepo(dir string) (string, error) {
+	// easiest case: existing go module
+	path, err := findGoModulePath(dir, false)
+	if err == nil {
+		return path, nil
+	}
+
+	// next, check if we've got a package in the current directory
+	pkgCfg := &packages.Config{
+		Mode: packages.NeedName, // name gives us path as well
+		Dir:  dir,
+	}
+	pkgs, err := packages.Load(pkgCfg, ".")
+	// NB(directxman12): when go modules are off and we're outside GOPATH and
+	// we don't otherwise have a good guess packages.Load will fabricate a path
+	// that consists of `_/absolute/path/to/current/directory`.  We shouldn't
+	// use that when it happens.
+	if err == nil && len(pkgs) > 0 && len(pkgs[0].PkgPath) > 0 && pkgs[0].PkgPath[0] != '_' {
+		return pkgs[0].PkgPath, nil
+	}
+
+	// a dry run leaves the disk untouched, its directory may not even exist there
+	if filesystem.IsDryRun() {
+		return "", fmt.Errorf("could not determine repository path from module data or package data, " +
+			"set it with --repo for a dry run")
+	}
+
+	// otherwise, try to get `go mod init` to guess for us -- it's pretty good
+	cmd := exec.Command("go", "mod", "init")
+	cmd.Dir = dir
+	cmd.Env = append(cmd.Env, os.Environ()...)
+	cmd.Env = append(cmd.Env, "GO111MODULE=on" /* turn on modules just for these commands */)
+	if _, err := cmd.Output(); err != nil {
+		if exitErr, isExitErr := err.(*exec.ExitError); isExitErr {
+			err = fmt.Errorf("%s", string(exitErr.Stderr))
+		}
+		// give up, let the user figure it out
+		return "", fmt.Errorf("could not determine repository path from module data, "+
+			"package data, or by initializing a module: %v", err)
+	}
+	defer os.Remove(filepath.Join(dir, "go.mod")) // clean up after ourselves
+	return findGoModulePath(dir, true)
+}
+
+func (c *CLI) defaultCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "kubebuilder",
+		Short: "Development kit for building Kubernetes extensions and tools.",
+		Long: `
+Development kit for building Kubernetes extensions and tools.
+
+Provides libraries and tools to create new projects, APIs and controllers.
+Includes tools for packaging artifacts into an installer container.
+
+Typical project lifecycle:
+
+- initialize a project:
+
+  kubebuilder init --domain example.com --license apache2 --owner "The Kubernetes authors"
+
+- create one or more a new resource APIs and add your code to them:
+
+  kubebuilder create api --group <group> --version <version> --kind <Kind>
+
+Create resource will prompt the user for if it should scaffold the Resource and / or Controller. To only
+scaffold a Controller for an existing Resource, select "n" for Resource. To only define
+the schema for a Resource without writing a Controller, select "n" for Controller.
+
+After the scaffold is written, api will run make on the project.
+
+Flags that are not provided in the command line default to the value of the matching KUBEBUILDER_*
+environment variable (e.g. KUBEBUILDER_DOMAIN for --domain, KUBEBUILDER_FETCH_DEPS for --fetch-deps)
+or to the value set in the user configuration file (~/.kubebuilder.yaml, or KUBEBUILDER_CONFIG):
+
+  defaults:
+    domain: example.com
+    owner: The Kubernetes authors
+  profiles:
+    platform:
+      image: registry.example.com/operators/controller:latest
+
+Profiles override the defaults and are selected with --profile or KUBEBUILDER_PROFILE.
+
+The messages are written as plain text by default, --log-format json writes them as one JSON object per line
+for the CI to parse. -v additionally writes the time spent in each phase of the scaffolding, e.g. rendering the
+templates and running make, to diagnose a slow generation. --output json or --output yaml writes a report of
+what a scaffolding command did to stdout, e.g. the files it created and updated and the lines it injected at the
+scaffold markers, and its messages to stderr.
+
+--dry-run runs a scaffolding command without writing any file nor running make, and prints the files it would
+create or modify, including the PROJECT file and the updates of main.go and of the kustomizations. --diff
+implies --dry-run and prints the unified diffs between the existing files and their templates rather than
+failing on or skipping the existing files, to compare a drifted project with the current templates.
+`,
+		Example: `
+	# Initialize your project
+	kubebuilder init --domain example.com --license apache2 --owner "The Kubernetes authors"
+
+	# Create a frigates API with Group: ship, Version: v1beta1 and Kind: Frigate
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate
+
+	# Edit the API Scheme
+	nano api/v1beta1/frigate_types.go
+
+	# Edit the Controller
+	nano controllers/frigate_controller.go
+
+	# Install CRDs into the Kubernetes cluster using kubectl apply
+	make install
+
+	# Regenerate code and run against the Kubernetes cluster configured by ~/.kube/config
+	make run
+
+	# List the files the creation of an API would create or modify, without writing them
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --dry-run
+
+	# Compare the files of the existing frigates API with the current templates, without writing them
+	kubebuilder create api --group ship --version v1beta1 --kind Frigate --diff
+`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := cmd.Help(); err != nil {
+				return fmt.Errorf("failed to call the help: %v", err)
+			}
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&c.outputDir, "output-dir", "",
+		"root directory of the project, defaults to the current working directory")
+	cmd.PersistentFlags().StringVar(&c.profile, "profile", "",
+		"profile of the user configuration file providing the defaults of the flags")
+	cmd.PersistentFlags().CountVarP(&c.verbosity, "verbose", "v",
+		"verbosity of the messages, -v writes the timings of the phases of the scaffolding")
+	cmd.PersistentFlags().StringVar(&c.logFormat, "log-format", string(logging.TextFormat),
+		fmt.Sprintf("format of the messages, one of %v", logging.Formats))
+	cmd.PersistentFlags().BoolVar(&c.dryRun, "dry-run", false,
+		"if set, the scaffolding commands print the files they would create or modify without writing them")
+	cmd.PersistentFlags().BoolVar(&c.diff, "diff", false,
+		"if set, the scaffolding commands print the diffs between the existing files and their templates, "+
+			"without writing them")
+	cmd.PersistentFlags().StringVar(&c.output, "output", "",
+		fmt.Sprintf("if set, the scaffolding commands write a report of the files they created and updated, the "+
+			"lines they injected at the scaffold markers and the changes of the PROJECT file to stdout, in this "+
+			"format, one of %v, and their messages to stderr", internal.ReportFormats))
+
+	// The errors of the commands are returned by CLI.Run for the binary embedding the command line to report them,
+	// the usage is only printed for the errors of the command line itself, e.g. an unknown flag
+	cmd.SilenceErrors = true
+
+	// Flags not provided in the command line are defaulted from the environment and the user configuration
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		if err := c.applyFlagDefaults(cmd.Flags()); err != nil {
+			return err
+		}
+		// the project is only known once --output-dir is parsed
+		if err := checkProjectVersion(cmd, internal.ConfiguredAndV1(c.outputDir)); err != nil {
+			return err
+		}
+		if c.diff {
+			if _, scaffolds := cmd.Annotations[internal.ScaffoldAnnotation]; !scaffolds {
+				return fmt.Errorf("--diff is only supported by the commands scaffolding the project")
+			}
+			c.diffOutput = os.Stdout
+			// the existing files are compared with their templates, none is written
+			c.dryRun = true
+		}
+		if c.dryRun {
+			if _, scaffolds := cmd.Annotations[internal.ScaffoldAnnotation]; !scaffolds {
+				return fmt.Errorf("--dry-run is only supported by the commands scaffolding the project")
+			}
+			filesystem.DryRun()
+		}
+		// The report is the only thing written to stdout
+		messages := os.Stdout
+		if c.output != "" {
+			if _, scaffolds := cmd.Annotations[internal.ScaffoldAnnotation]; !scaffolds {
+				return fmt.Errorf("--output is only supported by the commands scaffolding the project")
+			}
+			if !validReportFormat(c.output) {
+				return fmt.Errorf("unknown output format %q, must be one of %v", c.output, internal.ReportFormats)
+			}
+			filesystem.Track()
+			messages = os.Stderr
+			c.commandOutput = os.Stderr
+			if c.diff {
+				c.diffOutput = os.Stderr
+			}
+		}
+		if _, scaffolds := cmd.Annotations[internal.ScaffoldAnnotation]; scaffolds {
+			internal.StartScaffold(cmd, args)
+		}
+		return logging.Configure(messages, c.verbosity, logging.Format(c.logFormat))
+	}
+
+	// The commands scaffolding the project record their run in SCAFFOLD_INFO.yaml for the bug reports, and emit
+	// it to the telemetry hook of the tools embedding kubebuilder, see the telemetry package
+	cmd.PersistentPostRunE = func(cmd *cobra.Command, args []string) error {
+		if _, scaffolds := cmd.Annotations[internal.ScaffoldAnnotation]; !scaffolds {
+			return nil
+		}
+		if err := internal.RecordScaffold(c.outputDir, cmd, args); err != nil {
+			return err
+		}
+		if c.output != "" {
+			if err := c.printReport(cmd); err != nil {
+				return err
+			}
+		} else if c.dryRun {
+			if err := printDryRunChanges(); err != nil {
+				return err
+			}
+		}
+		internal.FinishScaffold(nil)
+		return nil
+	}
+
+	return cmd
+}
+
+// printDryRunChanges prints the files a dry run would have created or modified
+func printDryRunChanges() error {
+	changes, err := filesystem.Changes()
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		logging.Info("Dry run: no file would be created or modified.")
+		return nil
+	}
+	logging.Info("Dry run: no file was written, the following files would be created or modified:")
+	for _, change := range changes {
+		logging.Info("would "+string(change.Action), "path", change.Path)
+	}
+	return nil
+}
+
+// validReportFormat returns true if format is one of the formats of the scaffold reports
+func validReportFormat(format string) bool {
+	for _, f := range internal.ReportFormats {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// printReport writes the report of what the scaffolding command did to stdout, in the format set by --output
+func (c *CLI) printReport(cmd *cobra.Command) error {
+	command := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	report, err := internal.NewScaffoldReport(c.outputDir, command)
+	if err != nil {
+		return fmt.Errorf("unable to report the changes of the project: %v", err)
+	}
+	return report.Write(os.Stdout, c.output)
+}
+
+// applyFlagDefaults defaults the flags that were not provided in the command line with the following precedence:
+// KUBEBUILDER_* environment variables, the user configuration file (~/.kubebuilder.yaml) and the flag defaults.
+func (c *CLI) applyFlagDefaults(flags *flag.FlagSet) error {
+	path, err := internal.UserConfigPath()
+	if err != nil {
+		return fmt.Errorf("unable to locate the user configuration file: %v", err)
+	}
+
+	userConfig, err := internal.ReadUserConfig(path)
+	if err != nil {
+		return fmt.Errorf("unable to read the user configuration file: %v", err)
+	}
+
+	// The profile has to be known before defaulting the rest of the flags
+	selectedProfile := c.profile
+	if f := flags.Lookup("profile"); f != nil && !f.Changed {
+		selectedProfile = os.Getenv(internal.EnvVar("profile"))
+	}
+
+	return internal.ApplyDefaults(flags, userConfig, selectedProfile)
+}
+
+const (
+	// v1Annotation annotates the commands only supported by the v1 projects
+	v1Annotation = "kubebuilder.io/v1"
+
+	// exceptV1Annotation annotates the commands not supported by the v1 projects
+	exceptV1Annotation = "kubebuilder.io/except-v1"
+)
+
+// onlyV1 annotates the commands only supported by the v1 projects
+func onlyV1(cmds ...*cobra.Command) []*cobra.Command {
+	return annotate(v1Annotation, cmds)
+}
+
+// exceptV1 annotates the commands not supported by the v1 projects
+func exceptV1(cmds ...*cobra.Command) []*cobra.Command {
+	return annotate(exceptV1Annotation, cmds)
+}
+
+func annotate(annotation string, cmds []*cobra.Command) []*cobra.Command {
+	for _, cmd := range cmds {
+		if cmd.Annotations == nil {
+			cmd.Annotations = map[string]string{}
+		}
+		cmd.Annotations[annotation] = ""
+	}
+	return cmds
+}
+
+// checkProjectVersion returns an error if the command, or one of its parents, isn't supported by the version of the
+// project, and notices the deprecation of the v1 projects
+func checkProjectVersion(cmd *cobra.Command, v1 bool) error {
+	if v1 {
+		printV1DeprecationWarning()
+	}
+	for parent := cmd; parent != nil; parent = parent.Parent() {
+		if _, only := parent.Annotations[v1Annotation]; only && !v1 {
+			return fmt.Errorf("%s is only supported by the v1 projects", cmd.CommandPath())
+		}
+		if _, except := parent.Annotations[exceptV1Annotation]; except && v1 {
+			return fmt.Errorf("%s is not supported by the v1 projects, see how to upgrade your project to v2: "+
+				"https://book.kubebuilder.io/migration/guide.html", cmd.CommandPath())
+		}
+	}
+	return nil
+}
+
+func printV1DeprecationWarning() {
+	fmt.Printf(NoticeColor, "[Deprecation Notice] The v1 projects are deprecated and will not be supported "+
+		"beyond Feb 1, 2020.\nSee how to upgrade your project to v2:"+
+		" https://book.kubebuilder.io/migration/guide.html\n")
+}
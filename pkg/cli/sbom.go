@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/pkg/cli/internal"
+	"sigs.k8s.io/kubebuilder/pkg/sbom"
+)
+
+func (c *CLI) newSBOMCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "sbom",
+		Short: "Generate the SBOM of the dependencies of the project",
+		Long: `Generate the software bill of materials of the project as an SPDX JSON document listing the Go modules
+it depends on, in the format of the SBOMs generated by syft.
+
+The SBOM is generated when the project is initialized, refresh it with "make sbom" before every release.
+`,
+		Example: `	# Refresh the SBOM of the project
+	kubebuilder alpha sbom
+
+	# Write the SBOM to another file
+	kubebuilder alpha sbom --output dist/sbom.spdx.json
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := internal.CheckConfigured(c.outputDir); err != nil {
+				return err
+			}
+
+			if err := writeSBOM(c.outputDir, output); err != nil {
+				return err
+			}
+			fmt.Printf("Wrote %s\n", output)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&output, "output", sbom.DefaultFile,
+		"file the SBOM is written to, relative to the project root")
+
+	return cmd
+}
+
+// writeSBOM writes the SBOM of the dependencies of the project in dir to the file at path relative to dir
+func writeSBOM(dir, path string) error {
+	modules, err := sbom.ListModules(dir)
+	if err != nil {
+		return err
+	}
+	doc, err := sbom.Generate(modules, time.Now())
+	if err != nil {
+		return fmt.Errorf("error generating the SBOM: %v", err)
+	}
+
+	path = filepath.Join(dir, path)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return doc.Write(f)
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/pkg/cli/internal"
+	"sigs.k8s.io/kubebuilder/pkg/logging"
+	"sigs.k8s.io/kubebuilder/pkg/model"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/scaletest"
+)
+
+func (c *CLI) newScaleTestCmd() *cobra.Command {
+	var res *resource.Resource
+
+	cmd := &cobra.Command{
+		Use:   "scale-test",
+		Short: "Scaffold a scale test of the controller of a kind.",
+		Long: `Scaffold a scale test of the controller of a kind, creating objects of the kind at a configurable rate
+and reporting the latency distribution of their reconciles, as measured by the metrics of the controller.
+
+The objects are reconciled by the controller running in a test environment, or by the manager running in the
+cluster of the current kubeconfig when SCALE_TEST_METRICS_URL is set to its metrics. The test is skipped by
+default, make scale-test runs it and writes the report to scale-test-report.json. The number of objects, their
+rate and the timeout are set by SCALE_TEST_OBJECTS, SCALE_TEST_RATE and SCALE_TEST_TIMEOUT.
+`,
+		Example: `	# Scaffold a scale test of the FirstMate controller
+	kubebuilder create scale-test --group crew --version v1 --kind FirstMate
+
+	# Create 1000 FirstMates at 50 per second in a test environment
+	SCALE_TEST_OBJECTS=1000 SCALE_TEST_RATE=50 make scale-test
+`,
+		Annotations: map[string]string{internal.ScaffoldAnnotation: ""},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := internal.CheckConfigured(c.outputDir); err != nil {
+				return err
+			}
+
+			unlock, err := internal.LockProject(c.outputDir)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			projectConfig, err := config.ReadFrom(config.PathIn(c.outputDir))
+			if err != nil {
+				return fmt.Errorf("failed to read the configuration file: %v", err)
+			}
+
+			q, err := projectConfig.Query()
+			if err != nil {
+				return err
+			}
+			if !q.SupportsScaffoldMarkers() {
+				return fmt.Errorf("kubebuilder scale-test is for project version: 2,"+
+					" the version of this project is: %s", projectConfig.Version)
+			}
+
+			if err := res.Validate(); err != nil {
+				return err
+			}
+			if !projectConfig.HasResource(res) {
+				return fmt.Errorf("the version %s of the kind %s of the group %s is not a resource of the project, "+
+					"create it with kubebuilder create api", res.Version, res.Kind, res.Group)
+			}
+
+			universe, err := model.NewUniverse(
+				model.WithConfig(projectConfig),
+				// TODO: missing model.WithBoilerplate[From], needs boilerplate or path
+				model.WithResource(res, projectConfig),
+			)
+			if err != nil {
+				return fmt.Errorf("error scaffolding the scale test: %v", err)
+			}
+
+			logging.Info("Writing scaffold for you to edit...")
+
+			harness := &scaletest.Harness{}
+			kindTest := &scaletest.KindTest{Resource: res}
+			err = (&scaffold.Scaffold{OutputDir: c.outputDir, Diff: c.diffOutput}).Execute(
+				universe,
+				input.Options{},
+				harness,
+				&scaletest.HarnessTest{},
+				kindTest,
+			)
+			if err != nil {
+				return fmt.Errorf("error scaffolding the scale test: %v", err)
+			}
+			logging.Path(kindTest.Path)
+
+			if err := harness.Update(); err != nil {
+				return fmt.Errorf("error updating the Makefile: %v", err)
+			}
+			return nil
+		},
+	}
+	res = gvkForFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/pkg/cli/internal"
+	"sigs.k8s.io/kubebuilder/pkg/logging"
+	"sigs.k8s.io/kubebuilder/pkg/model"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	scaffoldv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/storagemigration"
+)
+
+func (c *CLI) newStorageMigrationCmd() *cobra.Command {
+	var res *resource.Resource
+
+	cmd := &cobra.Command{
+		Use:   "storage-migration",
+		Short: "Scaffold the migration of the objects of a kind to its new storage version.",
+		Long: `Scaffold the migration of the objects of a kind to its new storage version, once the
++kubebuilder:storageversion marker moved to another version of the kind.
+
+The manager rewrites every object of the kind at its storage version when it starts, and then records in the
+CRD that the previous versions are no longer stored, so that they can be removed from it. The migration only
+runs while the CRD still stores other versions. It can also be run out of the cluster with make migrate-storage.
+`,
+		Example: `	# Migrate the FirstMates to v2 after moving the +kubebuilder:storageversion marker to the v2 FirstMate type
+	kubebuilder create storage-migration --group crew --version v2 --kind FirstMate
+`,
+		Annotations: map[string]string{internal.ScaffoldAnnotation: ""},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := internal.CheckConfigured(c.outputDir); err != nil {
+				return err
+			}
+
+			unlock, err := internal.LockProject(c.outputDir)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			projectConfig, err := config.ReadFrom(config.PathIn(c.outputDir))
+			if err != nil {
+				return fmt.Errorf("failed to read the configuration file: %v", err)
+			}
+
+			q, err := projectConfig.Query()
+			if err != nil {
+				return err
+			}
+			if !q.SupportsScaffoldMarkers() {
+				return fmt.Errorf("kubebuilder storage-migration is for project version: 2,"+
+					" the version of this project is: %s", projectConfig.Version)
+			}
+
+			if err := res.Validate(); err != nil {
+				return err
+			}
+			if !projectConfig.HasResource(res) {
+				return fmt.Errorf("the version %s of the kind %s of the group %s is not a resource of the project, "+
+					"create it with kubebuilder create api", res.Version, res.Kind, res.Group)
+			}
+			if !projectConfig.HasCRD(res) {
+				return fmt.Errorf("the kind %s has no CRD in the project, its storage versions are not managed by "+
+					"the project", res.Kind)
+			}
+
+			logging.Info("Writing scaffold for you to edit...")
+			logging.Path(filepath.Join("storagemigration", "resources.go"))
+
+			universe, err := model.NewUniverse(
+				model.WithConfig(projectConfig),
+				// TODO: missing model.WithBoilerplate[From], needs boilerplate or path
+				model.WithResource(res, projectConfig),
+			)
+			if err != nil {
+				return fmt.Errorf("error scaffolding the storage migration: %v", err)
+			}
+
+			resources := &storagemigration.Resources{Resource: res}
+			command := &storagemigration.Command{}
+			err = (&scaffold.Scaffold{OutputDir: c.outputDir, Diff: c.diffOutput}).Execute(
+				universe,
+				input.Options{},
+				&storagemigration.Migrator{},
+				&storagemigration.MigratorTest{},
+				resources,
+				command,
+			)
+			if err != nil {
+				return fmt.Errorf("error scaffolding the storage migration: %v", err)
+			}
+
+			if err := resources.Update(); err != nil {
+				return fmt.Errorf("error updating %s: %v", resources.Path, err)
+			}
+			if err := command.Update(); err != nil {
+				return fmt.Errorf("error updating the Makefile: %v", err)
+			}
+
+			err = (&scaffoldv2.Main{}).Update(
+				&scaffoldv2.MainUpdateOptions{
+					Config:              projectConfig,
+					OutputDir:           c.outputDir,
+					WireStorageMigrator: true,
+				})
+			if err != nil {
+				return fmt.Errorf("error updating main.go: %v", err)
+			}
+			return nil
+		},
+	}
+	res = gvkForFlags(cmd.Flags())
+
+	return cmd
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/pkg/cli/internal"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold"
+)
+
+func (c *CLI) newUpgradeDepsCmd() *cobra.Command {
+	upgrade := &scaffold.DependencyUpgrade{}
+
+	cmd := &cobra.Command{
+		Use:   "upgrade-deps",
+		Short: "Upgrade the dependencies pinned in the PROJECT file",
+		Long: `Upgrade the versions of controller-runtime, controller-gen and kustomize pinned in the PROJECT file.
+
+controller-runtime is bumped in go.mod and the known breaking changes between the current and the
+target versions (e.g. renamed manager options) are applied to the go files of the project.
+controller-gen is bumped in the Makefile.
+
+Run "go mod tidy" and "make" afterwards and review the changes before committing them.
+`,
+		Example: `	# Upgrade to the versions new projects are scaffolded with
+	kubebuilder alpha upgrade-deps
+
+	# Upgrade controller-runtime to a given version
+	kubebuilder alpha upgrade-deps --controller-runtime v0.5.0
+`,
+		Annotations: map[string]string{internal.ScaffoldAnnotation: ""},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := internal.CheckConfigured(c.outputDir); err != nil {
+				return err
+			}
+			upgrade.OutputDir = c.outputDir
+
+			unlock, err := internal.LockProject(c.outputDir)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			if err := upgrade.Validate(); err != nil {
+				return err
+			}
+			if err := upgrade.Upgrade(); err != nil {
+				return err
+			}
+
+			if len(upgrade.Changes) == 0 {
+				fmt.Println("Dependencies are up to date.")
+				return nil
+			}
+			fmt.Println("Upgraded dependencies:")
+			for _, change := range upgrade.Changes {
+				fmt.Printf("  %s\n", change)
+			}
+			fmt.Println(`Next: run "go mod tidy" and "make" and review the changes.`)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&upgrade.Target.ControllerRuntime, "controller-runtime", "",
+		"controller-runtime version to upgrade to (defaults to the one new projects are scaffolded with)")
+	cmd.Flags().StringVar(&upgrade.Target.ControllerGen, "controller-gen", "",
+		"controller-gen version to upgrade to (defaults to the one new projects are scaffolded with)")
+	cmd.Flags().StringVar(&upgrade.Target.Kustomize, "kustomize", "",
+		"kustomize version to upgrade to (defaults to the one new projects are scaffolded with)")
+
+	return cmd
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/pkg/cli/internal"
+	"sigs.k8s.io/kubebuilder/pkg/logging"
+	"sigs.k8s.io/kubebuilder/pkg/model"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	scaffoldv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/admissionpolicy"
+)
+
+func (c *CLI) newValidationCmd() *cobra.Command {
+	var res *resource.Resource
+	var cel bool
+
+	cmd := &cobra.Command{
+		Use:   "validation",
+		Short: "Scaffold the validation of the objects of a kind by the API server.",
+		Long: `Scaffold the validation of the objects of a kind by the API server, an alternative to the validating
+webhooks of create webhook for the clusters where running a webhook server is undesirable.
+
+With --cel, a ValidatingAdmissionPolicy and its binding are scaffolded into config/validation, which
+config/default deploys. Its CEL rules are derived from the validation markers of the fields of the spec of the
+kind, e.g. +kubebuilder:validation:Maximum, add the ones the schema of the CRD can't express, e.g. comparing
+several fields. ValidatingAdmissionPolicies are served from Kubernetes 1.30.
+`,
+		Example: `	# Scaffold a ValidatingAdmissionPolicy validating the FirstMates
+	kubebuilder create validation --group crew --version v1 --kind FirstMate --cel
+`,
+		Annotations: map[string]string{internal.ScaffoldAnnotation: ""},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := internal.CheckConfigured(c.outputDir); err != nil {
+				return err
+			}
+
+			unlock, err := internal.LockProject(c.outputDir)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			projectConfig, err := config.ReadFrom(config.PathIn(c.outputDir))
+			if err != nil {
+				return fmt.Errorf("failed to read the configuration file: %v", err)
+			}
+
+			q, err := projectConfig.Query()
+			if err != nil {
+				return err
+			}
+			if !q.SupportsScaffoldMarkers() {
+				return fmt.Errorf("kubebuilder validation is for project version: 2,"+
+					" the version of this project is: %s", projectConfig.Version)
+			}
+			if !cel {
+				return errors.New("--cel is required, the only validation by the API server scaffolded yet, " +
+					"see kubebuilder create webhook --programmatic-validation for a validating webhook")
+			}
+
+			// the policies of a tracked resource are for the plural it was created with
+			res.Resource = projectConfig.PluralOf(res)
+			if err := res.Validate(); err != nil {
+				return err
+			}
+			if !projectConfig.HasCRD(res) {
+				return fmt.Errorf("the version %s of the kind %s of the group %s has no CRD in the project, "+
+					"create it with kubebuilder create api", res.Version, res.Kind, res.Group)
+			}
+			res.SharedKind = projectConfig.SharesKind(res)
+
+			types := filepath.Join("api", res.Version)
+			if projectConfig.MultiGroup {
+				types = filepath.Join("apis", res.Group, res.Version)
+			}
+			types = filepath.Join(types, projectConfig.Names().FileName(res.Kind)+"_types.go")
+			rules, err := scaffoldv2.CELRules(filepath.Join(c.outputDir, types), res.Kind)
+			if err != nil {
+				return fmt.Errorf("error deriving the rules from the markers: %v", err)
+			}
+
+			universe, err := model.NewUniverse(
+				model.WithConfig(projectConfig),
+				// TODO: missing model.WithBoilerplate[From], needs boilerplate or path
+				model.WithResource(res, projectConfig),
+			)
+			if err != nil {
+				return fmt.Errorf("error scaffolding the validation: %v", err)
+			}
+
+			logging.Info("Writing scaffold for you to edit...")
+
+			kustomization := &admissionpolicy.Kustomization{}
+			policy := &admissionpolicy.Policy{Resource: res, Rules: rules}
+			err = (&scaffold.Scaffold{OutputDir: c.outputDir, Diff: c.diffOutput}).Execute(
+				universe,
+				input.Options{},
+				kustomization,
+				&admissionpolicy.KustomizeConfig{},
+				policy,
+			)
+			if err != nil {
+				return fmt.Errorf("error scaffolding the validation: %v", err)
+			}
+			logging.Path(policy.Path)
+			if len(rules) == 0 {
+				logging.Info(fmt.Sprintf("the spec of %s has no validation markers, replace the rule of %s",
+					res.Kind, policy.Path))
+			}
+
+			if err := policy.Update(); err != nil {
+				return fmt.Errorf("error updating %s: %v", filepath.Join(filepath.Dir(policy.Path), "kustomization.yaml"),
+					err)
+			}
+			if err := kustomization.Update(); err != nil {
+				return fmt.Errorf("error updating %s: %v", kustomization.DefaultKustomization, err)
+			}
+			return nil
+		},
+	}
+	res = gvkForFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&cel, "cel", false,
+		"scaffold a ValidatingAdmissionPolicy evaluating CEL rules derived from the validation markers of the kind")
+
+	return cmd
+}
@@ -14,21 +14,21 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package cli
 
 import (
-	"log"
+	"fmt"
 
 	"github.com/spf13/cobra"
 
-	"sigs.k8s.io/kubebuilder/cmd/internal"
+	"sigs.k8s.io/kubebuilder/pkg/cli/internal"
 	"sigs.k8s.io/kubebuilder/pkg/model"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/project"
 )
 
-func newVendorUpdateCmd() *cobra.Command {
+func (c *CLI) newVendorUpdateCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "update",
 		Short: "Update vendor dependencies",
@@ -36,15 +36,17 @@ func newVendorUpdateCmd() *cobra.Command {
 		Example: `Update the vendor dependencies:
 kubebuilder update vendor
 `,
-		Run: func(cmd *cobra.Command, args []string) {
-			internal.DieIfNotConfigured()
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := internal.CheckConfigured(""); err != nil {
+				return err
+			}
 
 			universe, err := model.NewUniverse(
 				model.WithConfigFrom("PROJECT"),
 				model.WithoutBoilerplate,
 			)
 			if err != nil {
-				log.Fatalf("error updating vendor dependencies: %v", err)
+				return fmt.Errorf("error updating vendor dependencies: %v", err)
 			}
 
 			err = (&scaffold.Scaffold{}).Execute(
@@ -53,8 +55,9 @@ kubebuilder update vendor
 				&project.GopkgToml{},
 			)
 			if err != nil {
-				log.Fatalf("error updating vendor dependencies: %v", err)
+				return fmt.Errorf("error updating vendor dependencies: %v", err)
 			}
+			return nil
 		},
 	}
 }
@@ -0,0 +1,234 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/pkg/cli/internal"
+	"sigs.k8s.io/kubebuilder/pkg/release"
+	"sigs.k8s.io/kubebuilder/pkg/version"
+)
+
+func (c *CLI) newVerifyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Verify the SCAFFOLD_INFO.yaml of the project.",
+		Long: `Verify the SCAFFOLD_INFO.yaml of the project.
+
+Every scaffold operation, e.g. init, create api or create webhook, is recorded in the SCAFFOLD_INFO.yaml of the
+project: the command with its flags, the version of kubebuilder and the time it ran at. Nothing is sent anywhere,
+the file is meant to be committed and attached to the bug reports about the generated code, so that the scaffold
+can be reproduced.
+
+verify checks that the file is consistent with the project and fails if it isn't. It warns when the version of
+kubebuilder differs from the one of the last operation, the same commands may then scaffold different files.
+`,
+		Example: `	# Verify the SCAFFOLD_INFO.yaml before attaching it to a bug report
+	kubebuilder verify
+
+	# Check the generated CRDs for breaking changes of their schema
+	kubebuilder verify crds
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := internal.CheckConfigured(c.outputDir); err != nil {
+				return err
+			}
+
+			projectConfig, err := config.ReadFrom(config.PathIn(c.outputDir))
+			if err != nil {
+				return fmt.Errorf("failed to read the configuration file: %v", err)
+			}
+
+			info, err := internal.ReadScaffoldInfo(c.outputDir)
+			if os.IsNotExist(err) {
+				return fmt.Errorf("%s not found, it is written by the scaffold operations of kubebuilder",
+					internal.ScaffoldInfoFile)
+			}
+			if err != nil {
+				return err
+			}
+
+			problems, warnings := info.Verify(projectConfig.Version, internal.ScaffoldCommands(cmd.Root()),
+				version.Get().KubeBuilderVersion)
+			for _, w := range warnings {
+				fmt.Printf("warning: %s\n", w)
+			}
+			for _, p := range problems {
+				fmt.Printf("error: %s\n", p)
+			}
+			if len(problems) > 0 {
+				return fmt.Errorf("%s is invalid: %d problem(s)", internal.ScaffoldInfoFile, len(problems))
+			}
+			fmt.Printf("%s is valid: %d operation(s) recorded since %s\n", internal.ScaffoldInfoFile,
+				len(info.Operations), info.Created)
+			return nil
+		},
+	}
+	cmd.AddCommand(
+		c.newVerifyCRDsCmd(),
+	)
+
+	return cmd
+}
+
+// verifyCRDsOptions represents the options of verify crds
+type verifyCRDsOptions struct {
+	// cli is the command line of the command, whose --output-dir is the project root
+	cli *CLI
+
+	// since is the git revision of the previous CRDs
+	since string
+
+	// allowlist is the path of the allowed breaking changes relative to the project root
+	allowlist string
+}
+
+func (c *CLI) newVerifyCRDsCmd() *cobra.Command {
+	o := verifyCRDsOptions{cli: c}
+
+	cmd := &cobra.Command{
+		Use:   "crds",
+		Short: "Check the generated CRDs for breaking changes of their schema.",
+		Long: `Check the generated CRDs for breaking changes of their schema.
+
+The CRDs just generated by make manifests are compared with the ones of a git revision, the last commit by
+default, version by version of their kind. The changes breaking the clients or the existing objects of the kinds
+are reported before they reach a cluster:
+- a CRD or one of its versions removed
+- the scope of a CRD changed, from namespaced to cluster-scoped or the other way around
+- a field of the spec or the status removed, or whose type changed
+- a field which became required, or a new required field
+
+The breaking changes made on purpose are allowed by listing them in the allowlist, by default
+allowed-breaking-changes.yaml in the directory of the CRDs, e.g.:
+
+  - kind: Captain
+    version: v1
+    change: field-removed
+    field: spec.rank
+    reason: the rank is replaced by the grade, no object set it yet
+
+The version and the field can be left out to allow the change for every version or field, the changes are
+` + strings.Join(crdChangeTypes, ", ") + `.
+
+verify crds fails if any breaking change isn't allowed. It is run by make verify-crds after generating the CRDs.
+`,
+		Example: `	# Check the CRDs against the ones of the last commit
+	kubebuilder verify crds
+
+	# Check the CRDs against the ones of the previous release
+	kubebuilder verify crds --since v0.1.0
+`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := internal.CheckConfigured(c.outputDir); err != nil {
+				return err
+			}
+
+			projectConfig, err := config.ReadFrom(config.PathIn(c.outputDir))
+			if err != nil {
+				return fmt.Errorf("failed to read the configuration file: %v", err)
+			}
+			q, err := projectConfig.Query()
+			if err != nil {
+				return err
+			}
+			if !q.SupportsScaffoldMarkers() {
+				return fmt.Errorf("verify crds is only supported by the projects of version 2, the version of this "+
+					"project is %s", projectConfig.Version)
+			}
+
+			if o.allowlist == "" {
+				o.allowlist = filepath.Join(projectConfig.CRDDir(), crdAllowlistFile)
+			}
+			changes, err := o.diff(filepath.Join(projectConfig.CRDDir(), "bases"))
+			if err != nil {
+				return err
+			}
+			allowlist, err := release.ReadAllowlist(filepath.Join(c.outputDir, o.allowlist))
+			if err != nil {
+				return err
+			}
+			allowed, disallowed := release.Allowed(changes, allowlist)
+
+			for _, k := range allowed {
+				for _, c := range k.Changes {
+					fmt.Printf("allowed: %s (%s/%s): %s\n", k.Kind, k.Group, k.Version, c.Summary())
+				}
+			}
+			for _, k := range disallowed {
+				for _, c := range k.Changes {
+					fmt.Printf("error: %s (%s/%s): %s\n", k.Kind, k.Group, k.Version, c.Summary())
+				}
+			}
+			if len(disallowed) > 0 {
+				return fmt.Errorf("breaking changes of the CRDs since %s, allow the ones made on purpose in %s",
+					o.since, o.allowlist)
+			}
+			fmt.Printf("no breaking change of the CRDs since %s\n", o.since)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&o.since, "since", "HEAD",
+		"git revision the generated CRDs are compared with, e.g. the tag of the previous release")
+	cmd.Flags().StringVar(&o.allowlist, "allowlist", "",
+		"file listing the allowed breaking changes relative to the project root, defaults to "+
+			crdAllowlistFile+" in the directory of the CRDs")
+
+	return cmd
+}
+
+// crdAllowlistFile is the name of the allowlist of the breaking changes of the CRDs in the directory of the CRDs
+const crdAllowlistFile = "allowed-breaking-changes.yaml"
+
+// crdChangeTypes are the types of the breaking changes of the CRDs
+var crdChangeTypes = []string{
+	string(release.KindRemoved),
+	string(release.ScopeChanged),
+	string(release.FieldRemoved),
+	string(release.TypeChanged),
+	string(release.ValidationChanged),
+	string(release.FieldAdded),
+}
+
+// diff returns the breaking changes of the CRDs of the directory since the revision
+func (o *verifyCRDsOptions) diff(crdDir string) ([]release.KindChanges, error) {
+	oldFiles, err := release.ReadCRDsAt(o.cli.outputDir, o.since, crdDir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the CRDs as of %s: %v", o.since, err)
+	}
+	newFiles, err := release.ReadCRDs(o.cli.outputDir, crdDir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading the CRDs: %v", err)
+	}
+	oldCRDs, err := release.ParseCRDs(oldFiles)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the CRDs as of %s: %v", o.since, err)
+	}
+	newCRDs, err := release.ParseCRDs(newFiles)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the CRDs: %v", err)
+	}
+	return release.DiffCRDs(oldCRDs, newCRDs), nil
+}
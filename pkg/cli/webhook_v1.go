@@ -14,19 +14,20 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package main
+package cli
 
 import (
 	"fmt"
-	"log"
 	"os"
 	"os/exec"
 
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
 
-	"sigs.k8s.io/kubebuilder/cmd/internal"
 	"sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/cli/internal"
+	"sigs.k8s.io/kubebuilder/pkg/logging"
 	"sigs.k8s.io/kubebuilder/pkg/model"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
@@ -35,7 +36,7 @@ import (
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/v1/webhook"
 )
 
-func newWebhookCmd() *cobra.Command {
+func (c *CLI) newWebhookCmd() *cobra.Command {
 	o := webhookOptions{}
 
 	cmd := &cobra.Command{
@@ -49,23 +50,36 @@ This command is only available for v1 scaffolding project.
 	# Set type to be mutating and operations to be create and update.
 	kubebuilder alpha webhook --group crew --version v1 --kind FirstMate --type=mutating --operations=create,update
 `,
-		Run: func(cmd *cobra.Command, args []string) {
-			internal.DieIfNotConfigured()
+		Annotations: map[string]string{internal.ScaffoldAnnotation: ""},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := internal.CheckConfigured(""); err != nil {
+				return err
+			}
+
+			unlock, err := internal.LockProject("")
+			if err != nil {
+				return err
+			}
+			defer unlock()
 
 			projectConfig, err := config.Read()
 			if err != nil {
-				log.Fatalf("failed to read the configuration file: %v", err)
+				return fmt.Errorf("failed to read the configuration file: %v", err)
 			}
 
-			if !projectConfig.IsV1() {
-				log.Fatalf("webhook scaffolding is not supported for this project version: %s \n", projectConfig.Version)
+			q, err := projectConfig.Query()
+			if err != nil {
+				return err
+			}
+			if q.SupportsScaffoldMarkers() {
+				return fmt.Errorf("webhook scaffolding is not supported for this project version: %s", projectConfig.Version)
 			}
 
 			if err := o.res.Validate(); err != nil {
-				log.Fatal(err)
+				return err
 			}
 
-			fmt.Println("Writing scaffold for you to edit...")
+			logging.Info("Writing scaffold for you to edit...")
 
 			universe, err := model.NewUniverse(
 				model.WithConfig(projectConfig),
@@ -73,12 +87,12 @@ This command is only available for v1 scaffolding project.
 				model.WithResource(o.res, projectConfig),
 			)
 			if err != nil {
-				log.Fatalf("error scaffolding webhook: %v", err)
+				return fmt.Errorf("error scaffolding webhook: %v", err)
 			}
 
 			webhookConfig := webhook.Config{Server: o.server, Type: o.webhookType, Operations: o.operations}
 
-			err = (&scaffold.Scaffold{}).Execute(
+			err = (&scaffold.Scaffold{Diff: c.diffOutput}).Execute(
 				universe,
 				input.Options{},
 				&manager.Webhook{},
@@ -90,18 +104,20 @@ This command is only available for v1 scaffolding project.
 				&webhook.AddServer{Config: webhookConfig},
 			)
 			if err != nil {
-				log.Fatalf("error scaffolding webhook: %v", err)
+				return fmt.Errorf("error scaffolding webhook: %v", err)
 			}
+			unlock()
 
-			if o.doMake {
-				fmt.Println("Running make...")
+			if o.doMake && !filesystem.IsDryRun() {
+				logging.Info("Running make...")
 				cm := exec.Command("make") // #nosec
 				cm.Stderr = os.Stderr
-				cm.Stdout = os.Stdout
+				cm.Stdout = c.commandOutput
 				if err := cm.Run(); err != nil {
-					log.Fatal(err)
+					return err
 				}
 			}
+			return nil
 		},
 	}
 	cmd.Flags().StringVar(&o.server, "server", "default",
@@ -132,5 +148,6 @@ func gvkForFlags(f *flag.FlagSet) *resource.Resource {
 	f.StringVar(&r.Version, "version", "", "resource Version")
 	f.StringVar(&r.Kind, "kind", "", "resource Kind")
 	f.StringVar(&r.Resource, "resource", "", "resource Resource")
+	allowReservedGroupFlag(f, r)
 	return r
 }
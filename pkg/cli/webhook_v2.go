@@ -0,0 +1,755 @@
+/*
+Copyright 2019 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/pkg/cli/internal"
+	"sigs.k8s.io/kubebuilder/pkg/logging"
+	"sigs.k8s.io/kubebuilder/pkg/model"
+	modelconfig "sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+	scaffoldv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/crd"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/prometheus"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/webhook"
+)
+
+func (c *CLI) newWebhookV2Cmd() *cobra.Command {
+	o := webhookV2Options{cli: c}
+
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "Scaffold a webhook for an API resource.",
+		Long: `Scaffold a webhook for an API resource. You can choose to scaffold defaulting, ` +
+			`validating and (or) conversion webhooks.
+
+The webhooks are set up with the manager in main.go, and the webhook server is deployed by the [WEBHOOK] sections
+of config/default/kustomization.yaml, uncommented along with the [CERTMANAGER] ones issuing its serving
+certificate, unless the certificate is self-signed.
+
+The defaulting and validating webhooks of a kind of several versions are registered for its storage version,
+whichever --version is set: their match policy is Equivalent, the API server converts the requests to the other
+versions to the storage version with the conversion webhook before calling them. A test admitting the sample of
+every version through the conversions is scaffolded along with them.
+
+The webhooks of a built-in kind, e.g. the Pods, are admission handlers of the webhooks package decoding the objects
+of the requests, registered in the webhook server of main.go at the paths of their markers, from which make
+manifests generates their webhook configurations. The paths can be set with --defaulting-path and
+--validating-path.
+
+The conversion webhook converts the versions of a kind in the hub and spoke model: the hub, the storage version of
+the kind unless --hub is set, implements conversion.Hub, and the other versions, the spokes, implement
+conversion.Convertible converting them to and from the hub. The stubs of the conversions missing from the project
+are scaffolded, and the patches enabling the conversion webhook and the injection of the CA of cert-manager in the
+CRD are enabled in the kustomization of the CRDs. The hub can only be changed until the conversions are scaffolded.
+
+The serving certificate of the webhook server is provisioned by cert-manager, which must be installed in the
+cluster, unless --cert-provider is self-signed: the manager then generates a self-signed CA and the certificate,
+stored in the secret webhook-server-cert shared by its replicas, injects the CA into the webhook configurations and
+the conversion webhooks of the CRDs, and renews the certificate before it expires, see internal/certrotator. The
+webhooks of the project share the certificate, the provider is set by the first webhook.`,
+		Example: `	# Create defaulting and validating webhooks for CRD of group crew, version v1 and kind FirstMate.
+	kubebuilder create webhook --group crew --version v1 --kind FirstMate --defaulting --programmatic-validation
+
+	# Create conversion webhook for CRD of group crew, version v1 and kind FirstMate.
+	kubebuilder create webhook --group crew --version v1 --kind FirstMate --conversion
+
+	# Create conversion webhook for CRD of group crew and kind FirstMate, whose versions convert to and from v2.
+	kubebuilder create webhook --group crew --version v1 --kind FirstMate --conversion --hub v2
+
+	# Create conversion webhook for CRD of group crew, version v1 and kind FirstMate, served by a conversion-only
+	# webhook server on its own port, with its own service and certificate.
+	kubebuilder create webhook --group crew --version v1 --kind FirstMate --conversion --conversion-server
+
+	# Create a validating webhook along with a readiness check for its serving certificate.
+	kubebuilder create webhook --group crew --version v1 --kind FirstMate --programmatic-validation --cert-readiness
+
+	# Create defaulting and validating webhooks recording who requested a change and why it was mutated or rejected
+	# in the audit events of the API server.
+	kubebuilder create webhook --group crew --version v1 --kind FirstMate --defaulting --programmatic-validation \
+		--audit-annotations
+
+	# Create a validating webhook along with a load test reporting its p99 latency.
+	kubebuilder create webhook --group crew --version v1 --kind FirstMate --programmatic-validation --load-test
+
+	# Create a validating webhook admitting the requests while it is down and answering within 5 seconds, along
+	# with a test showing the effect of the webhook being down on the requests of the users.
+	kubebuilder create webhook --group crew --version v1 --kind FirstMate --programmatic-validation \
+		--failure-policy ignore --timeout-seconds 5
+
+	# Create defaulting and validating webhooks for the built-in Deployments, decoding the Deployments of the requests.
+	kubebuilder create webhook --group apps --version v1 --kind Deployment --defaulting --programmatic-validation
+
+	# Create a validating webhook for the built-in Pods, served at the path /validate-pods.
+	kubebuilder create webhook --group core --version v1 --kind Pod --programmatic-validation \
+		--validating-path /validate-pods
+
+	# Create defaulting and validating webhooks rolled out in canary, admitting the requests when they are down and only
+	# called for the namespaces labelled <domain>/webhook-canary=true, then promote them once they are trusted.
+	kubebuilder create webhook --group crew --version v1 --kind FirstMate --defaulting --programmatic-validation \
+		--canary
+	make webhook-promote
+
+	# Create a mutating webhook injecting a sidecar container into the pods labelled as managed by the operator.
+	kubebuilder create webhook --inject-sidecar
+
+	# Create defaulting and validating webhooks served with a self-signed certificate generated and rotated by the
+	# manager, in a cluster without cert-manager.
+	kubebuilder create webhook --group crew --version v1 --kind FirstMate --defaulting --programmatic-validation \
+		--cert-provider self-signed
+`,
+		Annotations: map[string]string{internal.ScaffoldAnnotation: ""},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := internal.CheckConfigured(c.outputDir); err != nil {
+				return err
+			}
+
+			unlock, err := internal.LockProject(c.outputDir)
+			if err != nil {
+				return err
+			}
+			defer unlock()
+
+			// the configuration is saved when the webhooks of a built-in kind are scaffolded, to track the kind
+			storedConfig, err := config.LoadFrom(config.PathIn(c.outputDir))
+			if err != nil {
+				return fmt.Errorf("failed to read the configuration file: %v", err)
+			}
+			projectConfig := &storedConfig.Config
+
+			q, err := projectConfig.Query()
+			if err != nil {
+				return err
+			}
+			if !q.SupportsScaffoldMarkers() {
+				return fmt.Errorf("kubebuilder webhook is for project version: 2,"+
+					" the version of this project is: %s", projectConfig.Version)
+			}
+
+			// the downtime test is scaffolded when the failure policy or the timeout of the webhooks is tuned
+			tuned := cmd.Flags().Changed("failure-policy") || cmd.Flags().Changed("timeout-seconds")
+
+			if err := o.validateCertProvider(projectConfig); err != nil {
+				return err
+			}
+
+			if o.injectSidecar {
+				if o.defaulting || o.validation || o.conversion || o.conversionServer || o.certReadiness ||
+					o.auditAnnotations || o.loadTest || o.canary || tuned || o.defaultingPath != "" ||
+					o.validatingPath != "" {
+					return fmt.Errorf("kubebuilder webhook scaffolds the sidecar injector, a webhook for pods, on its own," +
+						" --inject-sidecar can't be combined with the flags of the webhooks of a resource")
+				}
+				if err := o.saveCertProvider(storedConfig); err != nil {
+					return err
+				}
+				if err := o.scaffoldSidecarInjector(projectConfig); err != nil {
+					return err
+				}
+				if err := o.enableWebhooks(projectConfig); err != nil {
+					return err
+				}
+				return o.scaffoldCertRotator(projectConfig)
+			}
+
+			if !o.defaulting && !o.validation && !o.conversion {
+				return fmt.Errorf("kubebuilder webhook requires at least one of" +
+					" --defaulting, --programmatic-validation and --conversion to be true")
+			}
+
+			if o.auditAnnotations && !o.defaulting && !o.validation {
+				return fmt.Errorf("kubebuilder webhook requires --defaulting or --programmatic-validation" +
+					" to be true to add audit annotations")
+			}
+
+			if o.hub != "" && !o.conversion {
+				return fmt.Errorf("kubebuilder webhook requires --conversion to be true to set the hub of the conversions")
+			}
+
+			if o.conversionServer && !o.conversion {
+				return fmt.Errorf("kubebuilder webhook requires --conversion to be true to serve the conversion webhook " +
+					"from the conversion webhook server")
+			}
+
+			if o.loadTest && !o.validation {
+				return fmt.Errorf("kubebuilder webhook requires --programmatic-validation to be true to scaffold a load test")
+			}
+
+			if tuned && !o.defaulting && !o.validation {
+				return fmt.Errorf("kubebuilder webhook requires --defaulting or --programmatic-validation" +
+					" to be true to tune their failure policy and timeout")
+			}
+
+			if o.canary && !o.defaulting && !o.validation {
+				return fmt.Errorf("kubebuilder webhook requires --defaulting or --programmatic-validation" +
+					" to be true to roll them out in canary")
+			}
+
+			if err := webhook.ValidateTuning(o.failurePolicy, o.timeoutSeconds); err != nil {
+				return err
+			}
+
+			if o.defaultingPath != "" && !o.defaulting {
+				return fmt.Errorf("kubebuilder webhook requires --defaulting to be true to set the path of the defaulting " +
+					"webhook")
+			}
+			if o.validatingPath != "" && !o.validation {
+				return fmt.Errorf("kubebuilder webhook requires --programmatic-validation to be true to set the path of the " +
+					"validating webhook")
+			}
+
+			// the webhooks of the built-in kinds are admission handlers of the webhooks package, the methods of
+			// the webhooks of the kinds of the project can't be declared on their types
+			builtin := util.IsBuiltin(c.outputDir, o.res, projectConfig.MultiGroup)
+			if builtin {
+				if o.conversion || o.auditAnnotations || o.loadTest {
+					return fmt.Errorf("kubebuilder webhook scaffolds the defaulting and validating webhooks of the built-in "+
+						"kind %s, --conversion, --audit-annotations and --load-test are only supported for the "+
+						"kinds of the project", o.res.Kind)
+				}
+				if projectConfig.HasWireInjection() {
+					return fmt.Errorf("the webhooks of the built-in kind %s can't be injected by wire yet, scaffold them in "+
+						"a project without wire injection or add their providers to providers.go", o.res.Kind)
+				}
+				if o.defaultingPath != "" && o.defaultingPath == o.validatingPath {
+					return fmt.Errorf("the defaulting and validating webhooks can't be served at the same path %s",
+						o.defaultingPath)
+				}
+				for _, path := range []string{o.defaultingPath, o.validatingPath} {
+					if path == "" {
+						continue
+					}
+					if err := webhook.ValidateWebhookPath(c.outputDir, path); err != nil {
+						return err
+					}
+				}
+				// the core group is reserved for the built-in kinds
+				o.res.AllowReservedGroup = true
+			}
+
+			// the paths of the webhooks of the kinds of the project are the ones of controller-runtime
+			if !builtin && (o.defaultingPath != "" || o.validatingPath != "") {
+				return fmt.Errorf("the paths of the webhooks can only be set for the built-in kinds, the webhooks of %s are "+
+					"served at the paths set by controller-runtime", o.res.Kind)
+			}
+
+			// the webhooks of a tracked resource are for the plural it was created with
+			o.res.Resource = projectConfig.PluralOf(o.res)
+			if err := o.res.Validate(); err != nil {
+				return err
+			}
+			// the webhooks of a kind of several groups are named after the group, but in the first one
+			o.res.SharedKind = projectConfig.SharesKind(o.res)
+
+			// the conversion webhook reads the provider to enable the injection of the CA of cert-manager or not
+			if err := o.saveCertProvider(storedConfig); err != nil {
+				return err
+			}
+
+			// the conversion webhook is registered along with the webhooks of the hub, which becomes the storage
+			// version of the kind
+			var conversion *scaffold.Conversion
+			if o.conversion {
+				conversion = &scaffold.Conversion{
+					OutputDir: c.outputDir,
+					Resource:  o.res,
+					Hub:       o.hub,
+					Diff:      c.diffOutput,
+				}
+				if err := conversion.Validate(); err != nil {
+					return err
+				}
+				if hub := conversion.HubResource(); hub.Version != o.res.Version {
+					logging.Info(fmt.Sprintf("The conversion webhook of %s is registered for %s, the hub of its "+
+						"conversions, rather than %s", o.res.Kind, hub.Version, o.res.Version))
+					o.res.Version = hub.Version
+					if err := o.res.Validate(); err != nil {
+						return err
+					}
+				}
+				projectConfig.SetStorageVersion(o.res)
+			}
+
+			// the webhooks of a kind of several versions are registered for its storage version, the hub of its
+			// conversions, and called for the requests to its other versions once converted to it
+			var spokes []string
+			if versions := projectConfig.VersionsOf(o.res); !builtin && (o.defaulting || o.validation) &&
+				len(versions) > 1 {
+				storage := projectConfig.StorageVersionOf(o.res)
+				if o.res.Version != storage {
+					logging.Info(fmt.Sprintf("%s has several versions, its webhooks are registered for %s, its "+
+						"storage version, rather than %s", o.res.Kind, storage, o.res.Version))
+					o.res.Version = storage
+					if err := o.res.Validate(); err != nil {
+						return err
+					}
+				}
+				for _, version := range versions {
+					if version != storage {
+						spokes = append(spokes, version)
+					}
+				}
+			}
+
+			logging.Info("Writing scaffold for you to edit...")
+
+			if builtin {
+				logging.Path(webhook.BuiltinWebhookPath(o.res, projectConfig.MultiGroup,
+					projectConfig.Names().FileName(o.res.Kind)))
+			} else if projectConfig.MultiGroup {
+				logging.Path(filepath.Join("apis", o.res.Group, o.res.Version,
+					fmt.Sprintf("%s_webhook.go", projectConfig.Names().FileName(o.res.Kind))))
+			} else {
+				logging.Path(filepath.Join("api", o.res.Version,
+					fmt.Sprintf("%s_webhook.go", projectConfig.Names().FileName(o.res.Kind))))
+			}
+
+			if o.conversion {
+				if err := conversion.Scaffold(); err != nil {
+					return err
+				}
+				// the conversions of the next versions are scaffolded by create api along with them
+				if versions := projectConfig.VersionsOf(o.res); len(versions) > 1 {
+					logging.Info(fmt.Sprintf("%s is stored as %s, the hub: implement ConvertTo and ConvertFrom of "+
+						"its other versions", o.res.Kind, o.res.Version))
+				} else {
+					logging.Info(fmt.Sprintf("%s has a single version, the stubs of its conversions are scaffolded "+
+						"along with its next version by kubebuilder create api --version <version> --controller=false",
+						o.res.Kind))
+				}
+			}
+
+			universe, err := model.NewUniverse(
+				model.WithConfig(projectConfig),
+				// TODO: missing model.WithBoilerplate[From], needs boilerplate or path
+				model.WithResource(o.res, projectConfig),
+			)
+			if err != nil {
+				return fmt.Errorf("error scaffolding webhook: %v", err)
+			}
+
+			// the webhook only registering the conversions is scaffolded along with them
+			var files []input.File
+			if o.defaulting || o.validation {
+				files = append(files, &webhook.Webhook{
+					Resource:         o.res,
+					Defaulting:       o.defaulting,
+					Validating:       o.validation,
+					AuditAnnotations: o.auditAnnotations,
+					FailurePolicy:    o.failurePolicy,
+				})
+			}
+			if builtin {
+				files = []input.File{
+					&webhook.BuiltinWebhook{
+						Resource:       o.res,
+						Defaulting:     o.defaulting,
+						Validating:     o.validation,
+						FailurePolicy:  o.failurePolicy,
+						DefaultingPath: o.defaultingPath,
+						ValidatingPath: o.validatingPath,
+					},
+				}
+			}
+			auditPolicy := &webhook.AuditPolicy{Resource: o.res}
+			if o.auditAnnotations {
+				files = append(files,
+					&webhook.Audit{Resource: o.res, Defaulting: o.defaulting, Validating: o.validation},
+					&webhook.AuditTest{Resource: o.res, Defaulting: o.defaulting, Validating: o.validation},
+					auditPolicy,
+				)
+			}
+			if o.loadTest {
+				files = append(files, &webhook.LoadTest{Resource: o.res})
+			}
+			timeoutPatch := &webhook.TimeoutPatch{
+				Resource:       o.res,
+				Defaulting:     o.defaulting,
+				Validating:     o.validation,
+				TimeoutSeconds: o.timeoutSeconds,
+			}
+			if o.timeoutSeconds != 0 {
+				files = append(files, timeoutPatch)
+			}
+			if tuned && builtin {
+				logging.Info(fmt.Sprintf("The downtime test creates the sample of a kind of the project, it isn't "+
+					"scaffolded for the built-in kind %s", o.res.Kind))
+			} else if tuned {
+				files = append(files, &webhook.DowntimeTest{
+					Resource:       o.res,
+					Validating:     o.validation,
+					FailurePolicy:  o.failurePolicy,
+					TimeoutSeconds: o.timeoutSeconds,
+				})
+			}
+			if o.certReadiness {
+				files = append(files,
+					&webhook.CertReadiness{},
+					&prometheus.WebhookCertAlert{},
+				)
+			}
+			canaryPatch := &webhook.CanaryPatch{
+				Resource:   o.res,
+				Defaulting: o.defaulting,
+				Validating: o.validation,
+			}
+			if o.canary {
+				files = append(files, &webhook.CanaryKustomization{}, canaryPatch)
+			}
+			matchPolicyPatch := &webhook.MatchPolicyPatch{
+				Resource:   o.res,
+				Defaulting: o.defaulting,
+				Validating: o.validation,
+			}
+			if len(spokes) > 0 {
+				files = append(files, matchPolicyPatch, &webhook.VersionsTest{
+					Resource:   o.res,
+					Versions:   spokes,
+					Defaulting: o.defaulting,
+					Validating: o.validation,
+				})
+			}
+			conversionPatch := &webhook.ConversionManagerPatch{}
+			if o.conversionServer {
+				files = append(files,
+					&webhook.ConversionServer{},
+					&webhook.ConversionService{},
+					&webhook.ConversionCertificate{},
+					&webhook.ConversionKustomization{},
+					&webhook.ConversionKustomizeConfig{},
+					conversionPatch,
+					&crd.EnableWebhookPatch{Resource: o.res, ConversionServer: true},
+					&crd.EnableCAInjectionPatch{Resource: o.res, ConversionServer: true},
+				)
+			}
+
+			err = (&scaffold.Scaffold{OutputDir: c.outputDir, Diff: c.diffOutput}).Execute(
+				universe,
+				input.Options{},
+				files...,
+			)
+			if err != nil {
+				return fmt.Errorf("error scaffolding webhook: %v", err)
+			}
+
+			if o.auditAnnotations {
+				if err := auditPolicy.Update(); err != nil {
+					return fmt.Errorf("error updating the audit policy: %v", err)
+				}
+			}
+			if o.timeoutSeconds != 0 {
+				if err := timeoutPatch.Update(); err != nil {
+					return fmt.Errorf("error updating the webhook kustomization: %v", err)
+				}
+			}
+			if o.canary {
+				if err := canaryPatch.Update(); err != nil {
+					return fmt.Errorf("error updating the canary kustomization: %v", err)
+				}
+				logging.Info(fmt.Sprintf("The webhooks of %s are rolled out in canary by %s, run make "+
+					"webhook-promote to promote them", o.res.Kind, filepath.Dir(canaryPatch.Path)))
+			}
+			if len(spokes) > 0 {
+				if err := matchPolicyPatch.Update(); err != nil {
+					return fmt.Errorf("error updating the webhook kustomization: %v", err)
+				}
+				logging.Info(fmt.Sprintf("The webhooks of %s are called for the requests to %s converted to %s by "+
+					"the conversion webhook", o.res.Kind, strings.Join(spokes, ", "), o.res.Version))
+				if !o.conversion {
+					logging.Info("Run kubebuilder create webhook --conversion if the conversion webhook isn't " +
+						"enabled yet")
+				}
+			}
+			if err := o.enableWebhooks(projectConfig); err != nil {
+				return err
+			}
+			if o.conversionServer {
+				if err := conversionPatch.Update(); err != nil {
+					return fmt.Errorf("error updating the default kustomization: %v", err)
+				}
+			}
+
+			// the built-in kinds have no types in the project, they are tracked along with the package of their types
+			if builtin && projectConfig.AddExternalResource(o.res, util.BuiltinPackage(o.res)+"/"+o.res.Version) {
+				if err := storedConfig.Save(); err != nil {
+					return fmt.Errorf("error updating project file with resource information: %v", err)
+				}
+			}
+
+			err = (&scaffoldv2.Main{}).Update(
+				&scaffoldv2.MainUpdateOptions{
+					Config:            projectConfig,
+					WireResource:      false,
+					WireController:    false,
+					WireWebhook:       true,
+					WireCertReadiness: o.certReadiness,
+					Resource:          o.res,
+					OutputDir:         c.outputDir,
+				})
+			if err != nil {
+				return fmt.Errorf("error updating main.go: %v", err)
+			}
+			if o.conversionServer {
+				err = (&scaffoldv2.Main{}).Update(
+					&scaffoldv2.MainUpdateOptions{
+						Config:               projectConfig,
+						WireConversionServer: true,
+						OutputDir:            c.outputDir,
+					})
+				if err != nil {
+					return fmt.Errorf("error updating main.go: %v", err)
+				}
+				logging.Info(fmt.Sprintf("The conversion webhook of %s is served by the conversion webhook server "+
+					"on port %d, its service and certificate are in config/conversion", o.res.Kind,
+					webhook.ConversionServerPort))
+			}
+			if err := o.scaffoldCertRotator(projectConfig); err != nil {
+				return err
+			}
+			if projectConfig.WiringOf(o.res).SkipWebhook {
+				logging.Info(fmt.Sprintf("The webhooks of %s are not set up in main.go, their wiring is skipped in %s",
+					o.res.Kind, config.DefaultPath))
+			}
+
+			return nil
+		},
+	}
+	o.res = gvkForFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&o.defaulting, "defaulting", false,
+		"if set, scaffold the defaulting webhook")
+	cmd.Flags().BoolVar(&o.validation, "programmatic-validation", false,
+		"if set, scaffold the validating webhook")
+	cmd.Flags().BoolVar(&o.conversion, "conversion", false,
+		"if set, scaffold the conversion webhook")
+	cmd.Flags().StringVar(&o.hub, "hub", "",
+		"version of the kind which is the hub of its conversions, the other versions converting to and from it; "+
+			"its storage version if not set")
+	cmd.Flags().BoolVar(&o.conversionServer, "conversion-server", false,
+		"if set, serve the conversion webhook from a conversion-only webhook server, on its own port and with its "+
+			"own service and certificate, instead of the webhook server of the defaulting and validating webhooks")
+	cmd.Flags().BoolVar(&o.certReadiness, "cert-readiness", false,
+		"if set, scaffold a readiness check verifying the webhook serving certificate and a sample alerting rule")
+	cmd.Flags().BoolVar(&o.auditAnnotations, "audit-annotations", false,
+		"if set, add audit annotations recording who requested a change and why it was mutated or rejected "+
+			"to the webhook responses, and scaffold an audit policy recording them")
+	cmd.Flags().BoolVar(&o.loadTest, "load-test", false,
+		"if set, scaffold a load test sending the sample to the validating webhook and reporting its p99 latency")
+	cmd.Flags().StringVar(&o.failurePolicy, "failure-policy", webhook.FailurePolicyFail,
+		"what the API server does with the requests when it can't call the defaulting and validating webhooks: "+
+			"fail rejects them, ignore admits them; setting it or --timeout-seconds scaffolds a test showing the "+
+			"effect of the webhooks being down against envtest")
+	cmd.Flags().IntVar(&o.timeoutSeconds, "timeout-seconds", 0,
+		"time in seconds, between 1 and 30, the API server waits for the defaulting and validating webhooks, "+
+			"set by a patch of the webhook manifests; the default of the API server, 30, if not set")
+	cmd.Flags().StringVar(&o.defaultingPath, "defaulting-path", "",
+		"path the defaulting webhook of a built-in kind is served at, e.g. /mutate-pods; "+
+			"/mutate-<group>-<version>-<kind> if not set")
+	cmd.Flags().StringVar(&o.validatingPath, "validating-path", "",
+		"path the validating webhook of a built-in kind is served at, e.g. /validate-pods; "+
+			"/validate-<group>-<version>-<kind> if not set")
+	cmd.Flags().BoolVar(&o.canary, "canary", false,
+		"if set, roll out the defaulting and validating webhooks in canary: config/default deploys them through "+
+			"an overlay admitting the requests when they can't be called and only calling them for the namespaces "+
+			"labelled <domain>/webhook-canary=true, until make webhook-promote promotes them")
+	cmd.Flags().StringVar(&o.certProvider, "cert-provider", "",
+		fmt.Sprintf("how the serving certificate of the webhook server is provisioned, one of %q, issued by "+
+			"cert-manager installed in the cluster, or %q, generated, injected and renewed by the manager itself; "+
+			"the one of the project if not set, %q for its first webhook", modelconfig.CertManagerCertProvider,
+			modelconfig.SelfSignedCertProvider, modelconfig.CertManagerCertProvider))
+	cmd.Flags().BoolVar(&o.injectSidecar, "inject-sidecar", false,
+		"if set, scaffold a mutating webhook injecting a sidecar container into the pods managed by the operator, "+
+			"as selected by their labels, along with its tests, instead of the webhooks of a resource")
+
+	return cmd
+}
+
+// webhookOptions represents commandline options for scaffolding a webhook.
+type webhookV2Options struct {
+	cli        *CLI
+	res        *resource.Resource
+	defaulting bool
+	validation bool
+	conversion bool
+
+	// hub is the version of the kind which is the hub of its conversions, its storage version if empty
+	hub string
+
+	// conversionServer indicates whether the conversion webhook is served by the conversion webhook server
+	conversionServer bool
+
+	// certReadiness indicates whether the serving certificate readiness check should be scaffolded
+	certReadiness bool
+
+	// auditAnnotations indicates whether the webhooks should add audit annotations to their responses
+	auditAnnotations bool
+
+	// loadTest indicates whether a load test of the validating webhook should be scaffolded
+	loadTest bool
+
+	// canary indicates whether the defaulting and validating webhooks are rolled out in canary
+	canary bool
+
+	// injectSidecar indicates whether the sidecar injector should be scaffolded
+	injectSidecar bool
+
+	// certProvider is the provider of the serving certificate of the webhook server, the one of the project if empty
+	certProvider string
+
+	// failurePolicy is the failure policy of the defaulting and validating webhooks
+	failurePolicy string
+
+	// timeoutSeconds is the timeout of the defaulting and validating webhooks, 0 if not set
+	timeoutSeconds int
+
+	// defaultingPath and validatingPath are the paths the webhooks of a built-in kind are served at, the default
+	// ones if empty
+	defaultingPath string
+	validatingPath string
+}
+
+// scaffoldSidecarInjector scaffolds the sidecar injector and registers it in main.go
+func (o *webhookV2Options) scaffoldSidecarInjector(projectConfig *modelconfig.Config) error {
+	logging.Info("Writing scaffold for you to edit...")
+	logging.Path(filepath.Join("sidecar", "injector.go"))
+
+	universe, err := model.NewUniverse(model.WithConfig(projectConfig))
+	if err != nil {
+		return fmt.Errorf("error scaffolding webhook: %v", err)
+	}
+
+	selectorPatch := &webhook.SidecarSelectorPatch{}
+	err = (&scaffold.Scaffold{OutputDir: o.cli.outputDir, Diff: o.cli.diffOutput}).Execute(
+		universe,
+		input.Options{},
+		&webhook.SidecarInjector{},
+		&webhook.SidecarInjectorTest{},
+		selectorPatch,
+	)
+	if err != nil {
+		return fmt.Errorf("error scaffolding webhook: %v", err)
+	}
+
+	if err := selectorPatch.Update(); err != nil {
+		return fmt.Errorf("error updating the kustomization of the webhook manifests: %v", err)
+	}
+
+	err = (&scaffoldv2.Main{}).Update(
+		&scaffoldv2.MainUpdateOptions{
+			Config:              projectConfig,
+			WireSidecarInjector: true,
+			OutputDir:           o.cli.outputDir,
+		})
+	if err != nil {
+		return fmt.Errorf("error updating main.go: %v", err)
+	}
+	return nil
+}
+
+// enableWebhooks deploys the webhook server from the default kustomization, with the serving certificate of
+// cert-manager unless the manager provisions a self-signed one
+func (o *webhookV2Options) enableWebhooks(projectConfig *modelconfig.Config) error {
+	kustomize := &scaffoldv2.Kustomize{Input: input.Input{ProjectPath: o.cli.outputDir}}
+	if err := kustomize.EnableWebhooks(!projectConfig.HasSelfSignedCerts()); err != nil {
+		return fmt.Errorf("error updating the default kustomization: %v", err)
+	}
+	return nil
+}
+
+// validateCertProvider validates the provider of the serving certificate of the webhook server, which the webhooks of
+// the project share: it can only be set until the project records one
+func (o *webhookV2Options) validateCertProvider(projectConfig *modelconfig.Config) error {
+	switch o.certProvider {
+	case "":
+		o.certProvider = projectConfig.CertProvider
+		return nil
+	case modelconfig.CertManagerCertProvider, modelconfig.SelfSignedCertProvider:
+	default:
+		return fmt.Errorf("unknown certificate provider %q, must be one of %q or %q", o.certProvider,
+			modelconfig.CertManagerCertProvider, modelconfig.SelfSignedCertProvider)
+	}
+	if projectConfig.CertProvider != "" && projectConfig.CertProvider != o.certProvider {
+		return fmt.Errorf("the webhooks of the project share the serving certificate of the webhook server, "+
+			"provisioned by %s, --cert-provider can't be set to %s", projectConfig.CertProvider, o.certProvider)
+	}
+	if o.certProvider == modelconfig.SelfSignedCertProvider && o.conversionServer {
+		return fmt.Errorf("the certificate of the conversion webhook server is issued by cert-manager, " +
+			"--conversion-server can't be combined with --cert-provider self-signed")
+	}
+	return nil
+}
+
+// saveCertProvider records the provider of the serving certificate in the configuration of the project, once set
+func (o *webhookV2Options) saveCertProvider(storedConfig *config.Config) error {
+	if o.certProvider == storedConfig.CertProvider {
+		return nil
+	}
+	storedConfig.CertProvider = o.certProvider
+	if err := storedConfig.Save(); err != nil {
+		return fmt.Errorf("error updating project file with the certificate provider: %v", err)
+	}
+	return nil
+}
+
+// scaffoldCertRotator scaffolds the rotator of the self-signed serving certificate, mounts the certificate from an
+// emptyDir volume rather than the secret of cert-manager and sets the rotator up in main.go
+func (o *webhookV2Options) scaffoldCertRotator(projectConfig *modelconfig.Config) error {
+	if !projectConfig.HasSelfSignedCerts() {
+		return nil
+	}
+
+	universe, err := model.NewUniverse(model.WithConfig(projectConfig))
+	if err != nil {
+		return fmt.Errorf("error scaffolding the certificate rotator: %v", err)
+	}
+	patch := &scaffoldv2.ManagerWebhookPatch{SelfSigned: true}
+	err = (&scaffold.Scaffold{OutputDir: o.cli.outputDir, Diff: o.cli.diffOutput}).Execute(
+		universe,
+		input.Options{},
+		&webhook.CertRotator{},
+		&webhook.CertRotatorTest{},
+		patch,
+	)
+	if err != nil {
+		return fmt.Errorf("error scaffolding the certificate rotator: %v", err)
+	}
+	if err := patch.UseSelfSigned(); err != nil {
+		return fmt.Errorf("error updating the manager webhook patch: %v", err)
+	}
+
+	err = (&scaffoldv2.Main{}).Update(
+		&scaffoldv2.MainUpdateOptions{
+			Config:          projectConfig,
+			WireCertRotator: true,
+			OutputDir:       o.cli.outputDir,
+		})
+	if err != nil {
+		return fmt.Errorf("error updating main.go: %v", err)
+	}
+	logging.Info("The serving certificate of the webhook server is generated by the manager, see " +
+		"internal/certrotator")
+	return nil
+}
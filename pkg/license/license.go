@@ -0,0 +1,212 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package license manages the license headers of the Go files of a project. The header is the boilerplate file of
+// the project, hack/boilerplate.go.txt, written at the top of the scaffolded files and of the files generated by
+// controller-gen. The headers of the existing files are re-stamped from the boilerplate once it changes, e.g. when
+// its copyright year is updated.
+package license
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultFile is the path of the boilerplate file relative to the project root
+var DefaultFile = filepath.Join("hack", "boilerplate.go.txt")
+
+// HeaderPlugin is implemented by the plugins customizing the license header of the files, e.g. to add an SPDX
+// identifier or the header required by a company
+type HeaderPlugin interface {
+	// Header returns the header of the files given the one built from the boilerplate file
+	Header(header string) (string, error)
+}
+
+// Header returns the header of the files: the boilerplate without its trailing blank lines, transformed by the
+// plugins in order
+func Header(boilerplate string, plugins ...HeaderPlugin) (string, error) {
+	header := strings.TrimRight(boilerplate, "\n")
+	for _, p := range plugins {
+		var err error
+		if header, err = p.Header(header); err != nil {
+			return "", err
+		}
+		header = strings.TrimRight(header, "\n")
+	}
+	return header, nil
+}
+
+var _ HeaderPlugin = SPDX("")
+
+// SPDX is the header plugin adding the SPDX identifier of the license to the header, e.g. Apache-2.0, see
+// https://spdx.org/licenses/
+type SPDX string
+
+// Header implements HeaderPlugin
+func (s SPDX) Header(header string) (string, error) {
+	if s == "" || strings.Contains(header, spdxTag) {
+		return header, nil
+	}
+	line := spdxTag + " " + string(s)
+	if header == "" {
+		return "// " + line, nil
+	}
+	if strings.HasPrefix(header, "/*") && strings.HasSuffix(header, "*/") {
+		return strings.TrimSuffix(header, "*/") + "\n" + line + "\n*/", nil
+	}
+	return header + "\n//\n// " + line, nil
+}
+
+const spdxTag = "SPDX-License-Identifier:"
+
+var copyrightYearRe = regexp.MustCompile(`(Copyright\s+(?:\(c\)\s+)?)([0-9]{4})(\s*-\s*[0-9]{4})?`)
+
+// UpdateYear sets the copyright year of the boilerplate, the ranges of years, e.g. 2018-2019, are replaced by the
+// year
+func UpdateYear(boilerplate string, year int) string {
+	return copyrightYearRe.ReplaceAllString(boilerplate, "${1}"+strconv.Itoa(year))
+}
+
+// Stamp writes the header at the top of the source of a Go file. The first comment leading the source which
+// mentions a copyright or a license, e.g. the header written by the scaffolds, is replaced by the header, the header
+// is added before the other comments, e.g. the build tags, otherwise. It returns whether the source changed.
+func Stamp(src []byte, header string) ([]byte, bool) {
+	if header == "" {
+		return src, false
+	}
+	start, end := headerOf(src, []byte(header))
+	var stamped []byte
+	if end > start {
+		stamped = append(stamped, src[:start]...)
+		stamped = append(stamped, header...)
+		stamped = append(stamped, src[end:]...)
+	} else {
+		stamped = append(stamped, header...)
+		stamped = append(stamped, "\n\n"...)
+		stamped = append(stamped, bytes.TrimLeft(src, " \t\r\n")...)
+	}
+	return stamped, !bytes.Equal(stamped, src)
+}
+
+// headerOf returns the offsets of the license header of the source, equal if it doesn't have one: the first comment
+// mentioning a copyright or a license, or equal to the header. Only the comments before the package clause are
+// considered, the comments separated by a blank line being distinct, but the package doc.
+func headerOf(src, header []byte) (int, int) {
+	i := 0
+	for {
+		i = skipSpace(src, i)
+		start := i
+		switch {
+		case bytes.HasPrefix(src[i:], []byte("/*")):
+			n := bytes.Index(src[i+2:], []byte("*/"))
+			if n < 0 {
+				return 0, 0
+			}
+			i += 2 + n + 2
+		case bytes.HasPrefix(src[i:], []byte("//")):
+			// consecutive line comments are a single comment
+			for bytes.HasPrefix(src[i:], []byte("//")) {
+				n := bytes.IndexByte(src[i:], '\n')
+				if n < 0 {
+					i = len(src)
+					break
+				}
+				i += n + 1
+				i += len(src[i:]) - len(bytes.TrimLeft(src[i:], " \t"))
+			}
+			i = start + len(bytes.TrimRight(src[start:i], " \t\r\n"))
+		default:
+			return 0, 0
+		}
+		if bytes.Equal(src[start:i], header) {
+			return start, i
+		}
+		if bytes.HasPrefix(bytes.TrimLeft(src[i:], " \t\r"), []byte("\npackage")) {
+			// the package doc
+			return 0, 0
+		}
+		if isLicense(src[start:i]) {
+			return start, i
+		}
+	}
+}
+
+// skipSpace returns the offset of the first character which isn't a space from i
+func skipSpace(src []byte, i int) int {
+	return i + len(src[i:]) - len(bytes.TrimLeft(src[i:], " \t\r\n"))
+}
+
+// isLicense returns whether a comment is a license header
+func isLicense(comment []byte) bool {
+	lower := bytes.ToLower(comment)
+	return bytes.Contains(lower, []byte("copyright")) || bytes.Contains(lower, []byte("license"))
+}
+
+// skippedDirs are the directories of the project whose files aren't stamped
+var skippedDirs = map[string]bool{
+	".git":     true,
+	"bin":      true,
+	"vendor":   true,
+	"testdata": true,
+}
+
+// Fix stamps the header on the Go files of the project in dir and returns the paths of the files changed, relative
+// to dir. The files are left untouched if dryRun is set.
+func Fix(dir, header string, dryRun bool) ([]string, error) {
+	var changed []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != dir && skippedDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".go" {
+			return nil
+		}
+
+		src, err := ioutil.ReadFile(path) // nolint:gosec
+		if err != nil {
+			return err
+		}
+		stamped, ok := Stamp(src, header)
+		if !ok {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		changed = append(changed, rel)
+		if dryRun {
+			return nil
+		}
+		if err := ioutil.WriteFile(path, stamped, info.Mode()); err != nil {
+			return fmt.Errorf("error stamping the header of %s: %v", rel, err)
+		}
+		return nil
+	})
+	return changed, err
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package license_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "sigs.k8s.io/kubebuilder/pkg/license"
+)
+
+const (
+	header = `/*
+Copyright 2020 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License").
+*/`
+
+	oldHeader = `/*
+Copyright 2019 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License").
+*/`
+)
+
+var _ = Describe("UpdateYear", func() {
+	It("should set the copyright year", func() {
+		Expect(UpdateYear(oldHeader, 2020)).To(Equal(header))
+		Expect(UpdateYear("// Copyright (c) 2018-2019 Example", 2020)).To(Equal("// Copyright (c) 2020 Example"))
+		Expect(UpdateYear("/*\nLicensed.\n*/", 2020)).To(Equal("/*\nLicensed.\n*/"))
+	})
+})
+
+var _ = Describe("Header", func() {
+	It("should trim the boilerplate", func() {
+		Expect(Header(header + "\n\n")).To(Equal(header))
+	})
+
+	It("should add the SPDX identifier", func() {
+		Expect(Header(header, SPDX("Apache-2.0"))).To(Equal(`/*
+Copyright 2020 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License").
+
+SPDX-License-Identifier: Apache-2.0
+*/`))
+		Expect(Header("// Copyright 2020 Example", SPDX("MIT"))).To(Equal(
+			"// Copyright 2020 Example\n//\n// SPDX-License-Identifier: MIT"))
+		Expect(Header("// SPDX-License-Identifier: MIT", SPDX("MIT"))).To(Equal("// SPDX-License-Identifier: MIT"))
+	})
+})
+
+var _ = Describe("Stamp", func() {
+	It("should replace the header", func() {
+		src := []byte(oldHeader + "\n\npackage v1\n")
+		stamped, changed := Stamp(src, header)
+		Expect(changed).To(BeTrue())
+		Expect(string(stamped)).To(Equal(header + "\n\npackage v1\n"))
+
+		_, changed = Stamp(stamped, header)
+		Expect(changed).To(BeFalse())
+	})
+
+	It("should replace the header following the build tags", func() {
+		src := []byte("// +build !ignore_autogenerated\n\n" + oldHeader + "\n\n// Code generated. DO NOT EDIT.\n\npackage v1\n")
+		stamped, changed := Stamp(src, header)
+		Expect(changed).To(BeTrue())
+		Expect(string(stamped)).To(Equal(
+			"// +build !ignore_autogenerated\n\n" + header + "\n\n// Code generated. DO NOT EDIT.\n\npackage v1\n"))
+	})
+
+	It("should add the missing header before the package doc", func() {
+		src := []byte("\n// Package v1 is licensed to the users.\npackage v1\n")
+		stamped, changed := Stamp(src, header)
+		Expect(changed).To(BeTrue())
+		Expect(string(stamped)).To(Equal(header + "\n\n// Package v1 is licensed to the users.\npackage v1\n"))
+
+		_, changed = Stamp(stamped, header)
+		Expect(changed).To(BeFalse())
+	})
+
+	It("should recognize a header without copyright", func() {
+		src := []byte("/*\n.\n*/\n\npackage v1\n")
+		_, changed := Stamp(src, "/*\n.\n*/")
+		Expect(changed).To(BeFalse())
+	})
+})
+
+var _ = Describe("Fix", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "license")
+		Expect(err).NotTo(HaveOccurred())
+
+		for path, content := range map[string]string{
+			"main.go":                   oldHeader + "\n\npackage main\n",
+			"api/v1/types.go":           header + "\n\npackage v1\n",
+			"controllers/suite_test.go": "package controllers\n",
+			"vendor/example.org/a/a.go": "package a\n",
+			"hack/boilerplate.go.txt":   oldHeader,
+			"testdata/project/main.go":  "package main\n",
+		} {
+			path = filepath.Join(dir, path)
+			Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(path, []byte(content), 0644)).To(Succeed())
+		}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should stamp the Go files of the project", func() {
+		changed, err := Fix(dir, header, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(Equal([]string{filepath.Join("controllers", "suite_test.go"), "main.go"}))
+
+		b, err := ioutil.ReadFile(filepath.Join(dir, "controllers", "suite_test.go"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(Equal(header + "\n\npackage controllers\n"))
+
+		changed, err = Fix(dir, header, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeEmpty())
+	})
+
+	It("should leave the files untouched in a dry run", func() {
+		changed, err := Fix(dir, header, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(HaveLen(2))
+
+		b, err := ioutil.ReadFile(filepath.Join(dir, "main.go"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(Equal(oldHeader + "\n\npackage main\n"))
+	})
+})
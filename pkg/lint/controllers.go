@@ -0,0 +1,223 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+// OwnSpecWriteCheck is the name of the check of the reconcilers writing the objects they reconcile, whose spec is
+// owned by the users
+const OwnSpecWriteCheck = "own-spec-write"
+
+// ignoreMarker allows a write of a reconciled object when it precedes it, e.g. to add a finalizer
+const ignoreMarker = "+kubebuilder:lint:ignore=" + OwnSpecWriteCheck
+
+// ControllersDir checks the reconcilers of every Go package found under the provided directory
+func ControllersDir(root string) ([]Issue, error) {
+	return walk(root, ControllerFiles)
+}
+
+// ControllerFiles checks the reconcilers declared in the provided files, which must belong to the same package.
+// The reconciled kind of a reconciler is the one of For in its SetupWithManager method: its methods may update
+// and patch the status of the objects of this kind, not the objects themselves.
+func ControllerFiles(fset *token.FileSet, files ...*ast.File) []Issue {
+	// the reconciled kinds, e.g. crewv1.Captain, indexed by reconciler type
+	kinds := map[string]string{}
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == "SetupWithManager" && fn.Body != nil {
+				if kind := forKind(fn.Body); kind != "" {
+					kinds[receiverType(fn)] = kind
+				}
+			}
+		}
+	}
+
+	var issues []Issue
+	for _, f := range files {
+		ignored := ignoredLines(fset, f)
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			reconciler := receiverType(fn)
+			kind, found := kinds[reconciler]
+			if !found {
+				continue
+			}
+
+			objects := kindVariables(fn, kind)
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				method, obj := ownWrite(call, objects)
+				if obj == "" || ignored[fset.Position(call.Pos()).Line] {
+					return true
+				}
+				issues = append(issues, Issue{
+					Position: fset.Position(call.Pos()),
+					Check:    OwnSpecWriteCheck,
+					Message: fmt.Sprintf("%s.%s writes the spec of its own %s %s with %s",
+						reconciler, fn.Name.Name, kind, obj, method),
+					Suggestion: fmt.Sprintf("write its status with Status().%s or patchStatus, the spec is owned by "+
+						"the users; precede the call with // %s if it only changes the metadata, e.g. a finalizer",
+						method, ignoreMarker),
+				})
+				return true
+			})
+		}
+	}
+	return issues
+}
+
+// receiverType returns the name of the type of the receiver of the method, empty for a function
+func receiverType(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+	return baseTypeName(fn.Recv.List[0].Type)
+}
+
+// forKind returns the kind passed to For in the body, e.g. crewv1.Captain for For(&crewv1.Captain{})
+func forKind(body *ast.BlockStmt) string {
+	var kind string
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 {
+			return true
+		}
+		if sel, ok := call.Fun.(*ast.SelectorExpr); !ok || sel.Sel.Name != "For" {
+			return true
+		}
+		arg := call.Args[0]
+		if unary, ok := arg.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+			arg = unary.X
+		}
+		if lit, ok := arg.(*ast.CompositeLit); ok {
+			kind = typeString(lit.Type)
+		}
+		return kind == ""
+	})
+	return kind
+}
+
+// kindVariables returns the names of the parameters and of the variables of the method, including the ones of
+// its closures, holding an object of kind
+func kindVariables(fn *ast.FuncDecl, kind string) map[string]bool {
+	objects := map[string]bool{}
+	addFields := func(fields *ast.FieldList) {
+		if fields == nil {
+			return
+		}
+		for _, field := range fields.List {
+			if typeString(field.Type) == kind {
+				for _, name := range field.Names {
+					objects[name.Name] = true
+				}
+			}
+		}
+	}
+	addFields(fn.Type.Params)
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			addFields(node.Type.Params)
+		case *ast.ValueSpec:
+			if node.Type != nil && typeString(node.Type) == kind {
+				for _, name := range node.Names {
+					objects[name.Name] = true
+				}
+			}
+		case *ast.AssignStmt:
+			if node.Tok != token.DEFINE || len(node.Lhs) != len(node.Rhs) {
+				return true
+			}
+			for i, rhs := range node.Rhs {
+				if unary, ok := rhs.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+					rhs = unary.X
+				}
+				if lit, ok := rhs.(*ast.CompositeLit); ok && typeString(lit.Type) == kind {
+					if ident, ok := node.Lhs[i].(*ast.Ident); ok {
+						objects[ident.Name] = true
+					}
+				}
+			}
+		}
+		return true
+	})
+	return objects
+}
+
+// ownWrite returns the method and the object of a call updating or patching one of the objects, e.g. r.Update(ctx,
+// &instance), empty if the call writes another object or the status, e.g. r.Status().Update(ctx, &instance)
+func ownWrite(call *ast.CallExpr, objects map[string]bool) (string, string) {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || (sel.Sel.Name != "Update" && sel.Sel.Name != "Patch") || len(call.Args) < 2 {
+		return "", ""
+	}
+	if writer, ok := sel.X.(*ast.CallExpr); ok {
+		if status, ok := writer.Fun.(*ast.SelectorExpr); ok && status.Sel.Name == "Status" {
+			return "", ""
+		}
+	}
+
+	arg := call.Args[1]
+	if unary, ok := arg.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		arg = unary.X
+	}
+	if ident, ok := arg.(*ast.Ident); ok && objects[ident.Name] {
+		return sel.Sel.Name, ident.Name
+	}
+	return "", ""
+}
+
+// typeString returns the name of the type after removing pointers, qualified by its package, e.g. crewv1.Captain
+func typeString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return typeString(t.X)
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok {
+			return pkg.Name + "." + t.Sel.Name
+		}
+	}
+	return ""
+}
+
+// ignoredLines returns the lines of the file following the marker ignoring the check, and the ones it ends
+func ignoredLines(fset *token.FileSet, f *ast.File) map[int]bool {
+	lines := map[int]bool{}
+	for _, group := range f.Comments {
+		for _, comment := range group.List {
+			if strings.TrimSpace(strings.TrimPrefix(comment.Text, "//")) == ignoreMarker {
+				line := fset.Position(comment.Pos()).Line
+				lines[line], lines[line+1] = true, true
+			}
+		}
+	}
+	return lines
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint_test
+
+import (
+	"go/parser"
+	"go/token"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "sigs.k8s.io/kubebuilder/pkg/lint"
+)
+
+func lintController(src string) []Issue {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "captain_controller.go", src, parser.ParseComments)
+	Expect(err).NotTo(HaveOccurred())
+	return ControllerFiles(fset, f)
+}
+
+const setupCaptainReconciler = `
+func (r *CaptainReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&crewv1.Captain{}).
+		Owns(&corev1.ConfigMap{}).
+		Complete(r)
+}
+`
+
+var _ = Describe("Lint controllers", func() {
+	It("should accept the writes of the status and of the other objects", func() {
+		Expect(lintController(`package controllers
+
+func (r *CaptainReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	var instance crewv1.Captain
+	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.Status().Update(ctx, &instance); err != nil {
+		return ctrl.Result{}, err
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.Update(ctx, cm); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, r.patchStatus(ctx, &instance, func(instance *crewv1.Captain) {})
+}
+
+func (r *CaptainReconciler) patchStatus(ctx context.Context, instance *crewv1.Captain,
+	mutate func(*crewv1.Captain)) error {
+	base := instance.DeepCopy()
+	mutate(instance)
+	return r.Status().Patch(ctx, instance, client.MergeFrom(base))
+}
+` + setupCaptainReconciler)).To(BeEmpty())
+	})
+
+	It("should report the writes of the reconciled objects", func() {
+		issues := lintController(`package controllers
+
+func (r *CaptainReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	var instance crewv1.Captain
+	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
+		return ctrl.Result{}, err
+	}
+	instance.Spec.Foo = "bar"
+	if err := r.Update(ctx, &instance); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *CaptainReconciler) reset(ctx context.Context, captain *crewv1.Captain) error {
+	base := captain.DeepCopy()
+	captain.Spec.Foo = ""
+	return r.Client.Patch(ctx, captain, client.MergeFrom(base))
+}
+
+func (r *CaptainReconciler) retry(ctx context.Context, key client.ObjectKey) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		latest := &crewv1.Captain{}
+		if err := r.Get(ctx, key, latest); err != nil {
+			return err
+		}
+		return r.Update(ctx, latest)
+	})
+}
+` + setupCaptainReconciler)
+		Expect(checks(issues)).To(Equal([]string{OwnSpecWriteCheck, OwnSpecWriteCheck, OwnSpecWriteCheck}))
+		Expect(issues[0].Message).To(Equal(
+			"CaptainReconciler.Reconcile writes the spec of its own crewv1.Captain instance with Update"))
+		Expect(issues[0].Position.Line).To(Equal(9))
+		Expect(issues[1].Message).To(ContainSubstring("reset writes the spec of its own crewv1.Captain captain with Patch"))
+		Expect(issues[2].Message).To(ContainSubstring("retry writes the spec of its own crewv1.Captain latest"))
+	})
+
+	It("should accept the writes preceded by the ignore marker", func() {
+		Expect(lintController(`package controllers
+
+func (r *CaptainReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	var instance crewv1.Captain
+	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
+		return ctrl.Result{}, err
+	}
+	instance.Finalizers = append(instance.Finalizers, "crew.testproject.org/finalizer")
+	// +kubebuilder:lint:ignore=own-spec-write
+	if err := r.Update(ctx, &instance); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
+` + setupCaptainReconciler)).To(BeEmpty())
+	})
+
+	It("should only check the reconcilers", func() {
+		Expect(lintController(`package controllers
+
+func update(ctx context.Context, c client.Client, instance *crewv1.Captain) error {
+	return c.Update(ctx, instance)
+}
+`)).To(BeEmpty())
+	})
+})
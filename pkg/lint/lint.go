@@ -0,0 +1,329 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package lint contains static checks for the API conventions that scaffolded types are expected to follow, and
+// for the mistakes of the reconcilers that scaffolded controllers are expected to avoid
+package lint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Names of the checks performed over the API types
+const (
+	JSONTagCheck         = "json-tag"
+	OptionalMarkerCheck  = "optional-marker"
+	RequiredMarkerCheck  = "required-marker"
+	PointerOptionalCheck = "pointer-optional"
+	StatusInSpecCheck    = "status-in-spec"
+	BannedTypeCheck      = "banned-type"
+)
+
+// Markers of the optional and required fields, either the ones of the Kubernetes API conventions or of
+// controller-gen
+const (
+	optionalMarker           = "+optional"
+	validationOptionalMarker = "+kubebuilder:validation:Optional"
+	requiredMarker           = "+required"
+	validationRequiredMarker = "+kubebuilder:validation:Required"
+)
+
+// Issue describes a convention violation found in an API type
+type Issue struct {
+	// Position is the location of the offending field
+	Position token.Position
+
+	// Check is the name of the check that reported the issue
+	Check string
+
+	// Message describes the violation
+	Message string
+
+	// Suggestion describes how the violation can be fixed
+	Suggestion string
+}
+
+// String implements fmt.Stringer
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: [%s] %s (suggestion: %s)", i.Position, i.Check, i.Message, i.Suggestion)
+}
+
+// Dir checks the API types of every Go package found under the provided directory
+func Dir(root string) ([]Issue, error) {
+	return walk(root, Files)
+}
+
+// walk runs check over the files of every Go package found under the provided directory, and returns the
+// issues sorted by position
+func walk(root string, check func(fset *token.FileSet, files ...*ast.File) []Issue) ([]Issue, error) {
+	var issues []Issue
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+
+		fset := token.NewFileSet()
+		pkgs, err := parser.ParseDir(fset, path, isAPIFile, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		for _, pkg := range pkgs {
+			files := make([]*ast.File, 0, len(pkg.Files))
+			for _, f := range pkg.Files {
+				files = append(files, f)
+			}
+			issues = append(issues, check(fset, files...)...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(issues, func(i, j int) bool {
+		if issues[i].Position.Filename != issues[j].Position.Filename {
+			return issues[i].Position.Filename < issues[j].Position.Filename
+		}
+		return issues[i].Position.Line < issues[j].Position.Line
+	})
+	return issues, nil
+}
+
+// isAPIFile filters out test and generated files
+func isAPIFile(info os.FileInfo) bool {
+	name := info.Name()
+	return !strings.HasSuffix(name, "_test.go") && !strings.HasPrefix(name, "zz_generated")
+}
+
+// Files checks the API types declared in the provided files, which must belong to the same package
+func Files(fset *token.FileSet, files ...*ast.File) []Issue {
+	// Collect the struct types first so that fields can be resolved to them
+	structs := map[string]*ast.StructType{}
+	for _, f := range files {
+		for name, s := range structTypes(f) {
+			structs[name] = s
+		}
+	}
+
+	var issues []Issue
+	for _, f := range files {
+		for name, s := range structTypes(f) {
+			root := isRootType(s)
+			for _, field := range s.Fields.List {
+				issues = append(issues, checkField(fset, name, root, field, structs)...)
+			}
+		}
+	}
+	return issues
+}
+
+// structTypes returns the struct types declared in the file indexed by name
+func structTypes(f *ast.File) map[string]*ast.StructType {
+	structs := map[string]*ast.StructType{}
+	for _, decl := range f.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			if s, ok := typeSpec.Type.(*ast.StructType); ok {
+				structs[typeSpec.Name.Name] = s
+			}
+		}
+	}
+	return structs
+}
+
+// isRootType returns true if the struct embeds metav1.TypeMeta, i.e. it is a Kind or a List
+func isRootType(s *ast.StructType) bool {
+	for _, field := range s.Fields.List {
+		if len(field.Names) == 0 && baseTypeName(field.Type) == "TypeMeta" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkField runs every check over a field of the provided type
+// Fields of root types (metadata, spec, status and items) are only checked for json tags and banned types
+func checkField(
+	fset *token.FileSet,
+	typeName string,
+	root bool,
+	field *ast.Field,
+	structs map[string]*ast.StructType,
+) []Issue {
+	var issues []Issue
+	report := func(check, message, suggestion string) {
+		issues = append(issues, Issue{
+			Position:   fset.Position(field.Pos()),
+			Check:      check,
+			Message:    message,
+			Suggestion: suggestion,
+		})
+	}
+
+	// Embedded fields (e.g. metav1.TypeMeta) are inlined and do not follow the field conventions
+	if len(field.Names) == 0 {
+		return nil
+	}
+	name := field.Names[0].Name
+	if !ast.IsExported(name) {
+		return nil
+	}
+	fieldName := fmt.Sprintf("%s.%s", typeName, name)
+
+	jsonName, omitEmpty, hasTag := jsonTag(field)
+	if !hasTag {
+		report(JSONTagCheck, fmt.Sprintf("field %s has no json tag", fieldName),
+			fmt.Sprintf("add the tag `json:\"%s,omitempty\"`", lowerFirst(name)))
+	}
+
+	optional := hasMarker(field, optionalMarker) || hasMarker(field, validationOptionalMarker)
+	required := hasMarker(field, requiredMarker) || hasMarker(field, validationRequiredMarker)
+	if omitEmpty && !optional && !required && !root {
+		report(OptionalMarkerCheck, fmt.Sprintf("field %s is omitted when empty but not marked as optional", fieldName),
+			fmt.Sprintf("add the %q marker or remove omitempty from the json tag", "// "+optionalMarker))
+	}
+
+	if required && !root {
+		switch {
+		case optional:
+			report(RequiredMarkerCheck, fmt.Sprintf("field %s is marked as both required and optional", fieldName),
+				"remove the marker that doesn't apply")
+		case omitEmpty:
+			report(RequiredMarkerCheck, fmt.Sprintf("required field %s is omitted when empty", fieldName),
+				fmt.Sprintf("remove omitempty from the json tag or replace the required marker by %q",
+					"// "+optionalMarker))
+		}
+	}
+
+	if optional && !root {
+		if ident, ok := field.Type.(*ast.Ident); ok {
+			if _, isStruct := structs[ident.Name]; isStruct {
+				report(PointerOptionalCheck, fmt.Sprintf("optional field %s is a struct value", fieldName),
+					fmt.Sprintf("use *%s so that unset and empty values can be told apart", ident.Name))
+			}
+		}
+	}
+
+	// the types of other statuses, e.g. metav1.ConditionStatus or corev1.PodStatus, are legitimate in a spec
+	if strings.HasSuffix(typeName, "Spec") && (name == "Status" || jsonName == "status" ||
+		baseTypeName(field.Type) == strings.TrimSuffix(typeName, "Spec")+"Status") {
+		report(StatusInSpecCheck, fmt.Sprintf("spec type %s contains the status field %s", typeName, name),
+			"move the field to the status type of the resource")
+	}
+
+	if banned := bannedType(field.Type); banned != "" {
+		report(BannedTypeCheck, fmt.Sprintf("field %s uses the banned type %s", fieldName, banned),
+			"use resource.Quantity, an integer or a string instead of floating point numbers")
+	}
+
+	return issues
+}
+
+// jsonTag returns the json name of the field and whether it is omitted when empty
+func jsonTag(field *ast.Field) (name string, omitEmpty bool, found bool) {
+	if field.Tag == nil {
+		return "", false, false
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return "", false, false
+	}
+	value, found := reflect.StructTag(tag).Lookup("json")
+	if !found {
+		return "", false, false
+	}
+	parts := strings.Split(value, ",")
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return parts[0], omitEmpty, true
+}
+
+// hasMarker returns true if the field documentation contains the provided marker
+func hasMarker(field *ast.Field, marker string) bool {
+	if field.Doc == nil {
+		return false
+	}
+	for _, comment := range field.Doc.List {
+		text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+		if text == marker || strings.HasPrefix(text, marker+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// baseTypeName returns the name of the type after removing pointers, slices and maps
+func baseTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return baseTypeName(t.X)
+	case *ast.ArrayType:
+		return baseTypeName(t.Elt)
+	case *ast.MapType:
+		return baseTypeName(t.Value)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return ""
+}
+
+// bannedType returns the name of the banned type used by the expression, if any
+func bannedType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if t.Name == "float32" || t.Name == "float64" {
+			return t.Name
+		}
+	case *ast.StarExpr:
+		return bannedType(t.X)
+	case *ast.ArrayType:
+		return bannedType(t.Elt)
+	case *ast.MapType:
+		if banned := bannedType(t.Key); banned != "" {
+			return banned
+		}
+		return bannedType(t.Value)
+	}
+	return ""
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package lint_test
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "sigs.k8s.io/kubebuilder/pkg/lint"
+)
+
+func lint(src string) []Issue {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "types.go", src, parser.ParseComments)
+	Expect(err).NotTo(HaveOccurred())
+	return Files(fset, f)
+}
+
+func checks(issues []Issue) []string {
+	names := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		names = append(names, issue.Check)
+	}
+	return names
+}
+
+var _ = Describe("Lint", func() {
+	It("should accept a well formed API", func() {
+		Expect(lint(`package v1
+
+type FrigateSpec struct {
+	// +optional
+	Source *Source ` + "`json:\"source,omitempty\"`" + `
+
+	Replicas int32 ` + "`json:\"replicas\"`" + `
+}
+
+type Source struct {
+	URL string ` + "`json:\"url\"`" + `
+}
+
+type FrigateStatus struct {
+	// +optional
+	Ready bool ` + "`json:\"ready,omitempty\"`" + `
+}
+
+type Frigate struct {
+	metav1.TypeMeta   ` + "`json:\",inline\"`" + `
+	metav1.ObjectMeta ` + "`json:\"metadata,omitempty\"`" + `
+
+	Spec   FrigateSpec   ` + "`json:\"spec,omitempty\"`" + `
+	Status FrigateStatus ` + "`json:\"status,omitempty\"`" + `
+}
+`)).To(BeEmpty())
+	})
+
+	It("should accept the scaffolded APIs", func() {
+		for _, dir := range []string{
+			filepath.Join("..", "..", "testdata", "project-v2", "api"),
+			filepath.Join("..", "..", "testdata", "project-v2-multigroup", "apis"),
+		} {
+			Expect(Dir(dir)).To(BeEmpty())
+		}
+	})
+
+	It("should report fields without json tags", func() {
+		issues := lint(`package v1
+
+type FrigateSpec struct {
+	Replicas int32
+	internal string
+}
+`)
+		Expect(checks(issues)).To(Equal([]string{JSONTagCheck}))
+		Expect(issues[0].Suggestion).To(ContainSubstring(`json:"replicas,omitempty"`))
+	})
+
+	It("should report omitempty fields without the optional marker", func() {
+		Expect(checks(lint(`package v1
+
+type FrigateSpec struct {
+	Replicas int32 ` + "`json:\"replicas,omitempty\"`" + `
+}
+`))).To(Equal([]string{OptionalMarkerCheck}))
+	})
+
+	It("should accept required fields", func() {
+		Expect(lint(`package v1
+
+type FrigateSpec struct {
+	// +required
+	Replicas int32 ` + "`json:\"replicas\"`" + `
+
+	// +kubebuilder:validation:Required
+	Image string ` + "`json:\"image\"`" + `
+
+	// +kubebuilder:validation:Optional
+	Paused bool ` + "`json:\"paused,omitempty\"`" + `
+}
+`)).To(BeEmpty())
+	})
+
+	It("should report required fields omitted when empty or marked as optional", func() {
+		issues := lint(`package v1
+
+type FrigateSpec struct {
+	// +kubebuilder:validation:Required
+	Replicas int32 ` + "`json:\"replicas,omitempty\"`" + `
+
+	// +optional
+	// +required
+	Image string ` + "`json:\"image\"`" + `
+}
+`)
+		Expect(checks(issues)).To(Equal([]string{RequiredMarkerCheck, RequiredMarkerCheck}))
+		Expect(issues[0].Message).To(ContainSubstring("FrigateSpec.Replicas is omitted when empty"))
+		Expect(issues[1].Message).To(ContainSubstring("FrigateSpec.Image is marked as both required and optional"))
+	})
+
+	It("should report optional struct values", func() {
+		Expect(checks(lint(`package v1
+
+type FrigateSpec struct {
+	// +optional
+	Source Source ` + "`json:\"source,omitempty\"`" + `
+}
+
+type Source struct {
+	URL string ` + "`json:\"url\"`" + `
+}
+`))).To(Equal([]string{PointerOptionalCheck}))
+	})
+
+	It("should report status fields in the spec", func() {
+		Expect(checks(lint(`package v1
+
+type FrigateSpec struct {
+	Status FrigateStatus ` + "`json:\"status\"`" + `
+}
+
+type FrigateStatus struct{}
+`))).To(Equal([]string{StatusInSpecCheck}))
+	})
+
+	It("should report the status type of the kind in the spec", func() {
+		Expect(checks(lint(`package v1
+
+type FrigateSpec struct {
+	// +optional
+	Observed *FrigateStatus ` + "`json:\"observed,omitempty\"`" + `
+}
+
+type FrigateStatus struct{}
+`))).To(Equal([]string{StatusInSpecCheck}))
+	})
+
+	It("should not report the other status types in the spec", func() {
+		Expect(lint(`package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type FrigateSpec struct {
+	Ready    metav1.ConditionStatus ` + "`json:\"ready\"`" + `
+	Template corev1.PodStatus       ` + "`json:\"template\"`" + `
+}
+`)).To(BeEmpty())
+	})
+
+	It("should report floating point fields", func() {
+		Expect(checks(lint(`package v1
+
+type FrigateSpec struct {
+	Ratio  float64            ` + "`json:\"ratio\"`" + `
+	Limits map[string]float32 ` + "`json:\"limits\"`" + `
+}
+`))).To(Equal([]string{BannedTypeCheck, BannedTypeCheck}))
+	})
+})
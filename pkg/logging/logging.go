@@ -0,0 +1,207 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logging is the leveled logger of the kubebuilder commands and of the scaffold engine. The messages are
+// written as plain text by default, e.g. the paths of the scaffolded files one per line, or as one JSON object per
+// line for the CI to parse. The verbose messages, e.g. the timings of the phases of the scaffolding, are only
+// written when the verbosity is raised with -v.
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Format is the format of the messages
+type Format string
+
+const (
+	// TextFormat writes the messages as plain text, the key and value pairs following the message
+	TextFormat Format = "text"
+
+	// JSONFormat writes every message as a JSON object on its own line
+	JSONFormat Format = "json"
+)
+
+// Formats are the supported formats of the messages
+var Formats = []Format{TextFormat, JSONFormat}
+
+const (
+	levelInfo    = "info"
+	levelWarning = "warning"
+	levelDebug   = "debug"
+)
+
+var (
+	mu        sync.Mutex
+	out       io.Writer = os.Stdout
+	verbosity int
+	format    = TextFormat
+
+	// now is the clock of the timestamps and of the timings, replaced by the tests
+	now = time.Now
+)
+
+// Configure sets the writer, the verbosity and the format of the messages, the verbose messages of a level are
+// written when the verbosity is at least this level
+func Configure(w io.Writer, v int, f Format) error {
+	switch f {
+	case TextFormat, JSONFormat:
+	default:
+		return fmt.Errorf("unknown log format %q, must be one of %v", f, Formats)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	out, verbosity, format = w, v, f
+	return nil
+}
+
+// V returns whether the verbose messages of the level are written
+func V(level int) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return verbosity >= level
+}
+
+// Info writes a message followed by its key and value pairs, e.g. Info("scaffolded", "path", "main.go")
+func Info(msg string, keysAndValues ...interface{}) {
+	write(levelInfo, msg, keysAndValues)
+}
+
+// Warning writes a message about a problem the command recovered from, prefixed by warning in the text format
+func Warning(msg string, keysAndValues ...interface{}) {
+	write(levelWarning, msg, keysAndValues)
+}
+
+// Debug writes a verbose message, only when the verbosity is at least 1
+func Debug(msg string, keysAndValues ...interface{}) {
+	if V(1) {
+		write(levelDebug, msg, keysAndValues)
+	}
+}
+
+// Path writes the path of a file scaffolded or updated by a command, alone on its line in the text format
+func Path(path string) {
+	alone("scaffolded", "path", path)
+}
+
+// Command writes the command run by a command, e.g. go mod tidy, alone on its line in the text format
+func Command(args []string) {
+	alone("running", "command", strings.Join(args, " "))
+}
+
+// alone writes the value alone on its line in the text format, as the value of the key of the message otherwise
+func alone(msg, key, value string) {
+	mu.Lock()
+	text := format == TextFormat
+	mu.Unlock()
+
+	if text {
+		write(levelInfo, value, nil)
+		return
+	}
+	write(levelInfo, msg, []interface{}{key, value})
+}
+
+// Phase starts timing a phase of a command and returns the function ending it, which writes its duration as a
+// verbose message, e.g. defer logging.Phase("templates")()
+func Phase(name string, keysAndValues ...interface{}) func() {
+	start := now()
+	return func() {
+		Debug("phase completed", append([]interface{}{"phase", name, "duration", now().Sub(start)},
+			keysAndValues...)...)
+	}
+}
+
+// write writes a message of the level in the configured format
+func write(level, msg string, keysAndValues []interface{}) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if format == JSONFormat {
+		entry := map[string]interface{}{
+			"time":  now().UTC().Format(time.RFC3339Nano),
+			"level": level,
+			"msg":   msg,
+		}
+		for i := 0; i < len(keysAndValues); i += 2 {
+			entry[key(keysAndValues[i])] = jsonValue(keysAndValues, i+1)
+		}
+		b, err := json.Marshal(entry)
+		if err != nil {
+			b, _ = json.Marshal(map[string]interface{}{"level": level, "msg": msg, "error": err.Error()})
+		}
+		_, _ = fmt.Fprintf(out, "%s\n", b)
+		return
+	}
+
+	line := msg
+	if level != levelInfo {
+		line = level + ": " + msg
+	}
+	pairs := make([]string, 0, len(keysAndValues)/2+1)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", key(keysAndValues[i]), textValue(keysAndValues, i+1)))
+	}
+	if len(pairs) > 0 {
+		line += " " + strings.Join(pairs, " ")
+	}
+	_, _ = fmt.Fprintln(out, line)
+}
+
+// key returns the key of a pair as a string
+func key(k interface{}) string {
+	if s, ok := k.(string); ok {
+		return s
+	}
+	return fmt.Sprint(k)
+}
+
+// textValue returns the value at i of the pairs, quoted if it contains spaces
+func textValue(keysAndValues []interface{}, i int) string {
+	if i >= len(keysAndValues) {
+		return "<missing>"
+	}
+	v := fmt.Sprint(keysAndValues[i])
+	if strings.ContainsAny(v, " \t\n\"") {
+		return fmt.Sprintf("%q", v)
+	}
+	return v
+}
+
+// jsonValue returns the value at i of the pairs as encoded in the JSON objects: the durations in seconds and the
+// errors as their message
+func jsonValue(keysAndValues []interface{}, i int) interface{} {
+	if i >= len(keysAndValues) {
+		return "<missing>"
+	}
+	switch v := keysAndValues[i].(type) {
+	case time.Duration:
+		return v.Seconds()
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return v
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Logging", func() {
+	var buf *bytes.Buffer
+
+	BeforeEach(func() {
+		buf = &bytes.Buffer{}
+		clock := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		now = func() time.Time {
+			clock = clock.Add(time.Second)
+			return clock
+		}
+	})
+
+	AfterEach(func() {
+		now = time.Now
+		Expect(Configure(os.Stdout, 0, TextFormat)).To(Succeed())
+	})
+
+	It("should write the paths and the commands alone on their line in the text format", func() {
+		Expect(Configure(buf, 0, TextFormat)).To(Succeed())
+		Path("api/v1/captain_types.go")
+		Command([]string{"go", "mod", "tidy"})
+		Info("scaffolded", "kind", "Captain", "note", "with spaces")
+		Warning("the Makefile was changed")
+		Expect(buf.String()).To(Equal("api/v1/captain_types.go\ngo mod tidy\n" +
+			"scaffolded kind=Captain note=\"with spaces\"\nwarning: the Makefile was changed\n"))
+	})
+
+	It("should only write the verbose messages when the verbosity is raised", func() {
+		Expect(Configure(buf, 0, TextFormat)).To(Succeed())
+		Phase("templates", "files", 3)()
+		Debug("hidden")
+		Expect(buf.String()).To(BeEmpty())
+		Expect(V(1)).To(BeFalse())
+
+		Expect(Configure(buf, 1, TextFormat)).To(Succeed())
+		Phase("templates", "files", 3)()
+		Expect(buf.String()).To(Equal("debug: phase completed phase=templates duration=1s files=3\n"))
+		Expect(V(1)).To(BeTrue())
+	})
+
+	It("should write one JSON object per message in the JSON format", func() {
+		Expect(Configure(buf, 1, JSONFormat)).To(Succeed())
+		Path("api/v1/captain_types.go")
+		Phase("write")()
+		Warning("unable to update", "error", fmt.Errorf("not found"))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		Expect(lines).To(HaveLen(3))
+		var entries []map[string]interface{}
+		for _, line := range lines {
+			entry := map[string]interface{}{}
+			Expect(json.Unmarshal([]byte(line), &entry)).To(Succeed())
+			entries = append(entries, entry)
+		}
+		Expect(entries[0]).To(Equal(map[string]interface{}{"time": "2020-01-02T03:04:06Z", "level": "info",
+			"msg": "scaffolded", "path": "api/v1/captain_types.go"}))
+		Expect(entries[1]).To(HaveKeyWithValue("level", "debug"))
+		Expect(entries[1]).To(HaveKeyWithValue("phase", "write"))
+		Expect(entries[1]).To(HaveKeyWithValue("duration", 1.0))
+		Expect(entries[2]).To(HaveKeyWithValue("level", "warning"))
+		Expect(entries[2]).To(HaveKeyWithValue("error", "not found"))
+	})
+
+	It("should reject the unknown formats", func() {
+		Expect(Configure(buf, 0, Format("yaml"))).NotTo(Succeed())
+	})
+})
@@ -0,0 +1,88 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrate
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/model"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+// Render renders the files of the templates linked into the binary for the project in dir as a bundle of the
+// provided version, without writing them. Bundles of other versions are rendered by the binaries of these
+// versions, e.g. serialized as JSON.
+func Render(version, dir string, options input.Options, files ...input.File) (*Bundle, error) {
+	root := dir
+	if root == "" {
+		root = "."
+	}
+
+	rendered := map[string]*bytes.Buffer{}
+	s := &scaffold.Scaffold{
+		OutputDir: dir,
+		GetWriter: func(path string) (io.Writer, error) {
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return nil, err
+			}
+			b := &bytes.Buffer{}
+			rendered[filepath.ToSlash(rel)] = b
+			return b, nil
+		},
+		// the files scaffolded only once are part of the bundle as well
+		FileExists: func(string) bool { return false },
+	}
+	if err := s.Execute(&model.Universe{}, options, files...); err != nil {
+		return nil, fmt.Errorf("error rendering the templates: %v", err)
+	}
+
+	bundle := &Bundle{Version: version, Files: make(map[string]string, len(rendered))}
+	for path, b := range rendered {
+		bundle.Files[path] = b.String()
+	}
+	return bundle, nil
+}
+
+// ReadScaffolded reads the files of the project in dir recorded in its checksums, i.e. the files scaffolded by
+// kubebuilder, as a bundle of the provided version, e.g. to compare a project scaffolded again by the current
+// templates with an older one. The recorded files which were deleted are left out.
+func ReadScaffolded(version, dir string) (*Bundle, error) {
+	checksums, err := scaffold.ReadChecksums(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &Bundle{Version: version, Files: make(map[string]string, len(checksums))}
+	for path := range checksums {
+		b, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(path))) // nolint: gosec
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		bundle.Files[path] = string(b)
+	}
+	return bundle, nil
+}
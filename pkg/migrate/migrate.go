@@ -0,0 +1,247 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package migrate computes the changes upgrading the scaffolded files of a project from a version of the
+// templates to another one, leaving the files modified by the user to be merged by hand. It is meant to be
+// consumed by the CLI as well as by external tooling, e.g. upgrading every project of a monorepo at once.
+package migrate
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+)
+
+// Bundle is the content of the files scaffolded by a version of the templates
+type Bundle struct {
+	// Version identifies the templates, e.g. the version of kubebuilder they were rendered by
+	Version string `json:"version"`
+
+	// Files maps the slash separated path of every scaffolded file to its content
+	Files map[string]string `json:"files"`
+}
+
+// State is the state of the files of a project
+type State struct {
+	// Files maps the slash separated path of the existing files to their content
+	Files map[string]string `json:"files"`
+
+	// Checksums maps the slash separated path of the scaffolded files to the checksum of the content
+	// they had when they were scaffolded, as recorded under .kubebuilder
+	Checksums map[string]string `json:"checksums,omitempty"`
+}
+
+// LoadState reads the state of the files of the project in dir that are scaffolded by any of the bundles
+func LoadState(dir string, bundles ...*Bundle) (*State, error) {
+	checksums, err := scaffold.ReadChecksums(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &State{Files: map[string]string{}, Checksums: checksums}
+	for _, bundle := range bundles {
+		for path := range bundle.Files {
+			if _, found := state.Files[path]; found {
+				continue
+			}
+			b, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(path))) // nolint: gosec
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			state.Files[path] = string(b)
+		}
+	}
+	return state, nil
+}
+
+// modified returns true if the file at path was modified since it was scaffolded by from. Files scaffolded
+// before checksums were recorded are only considered unmodified if they have the content of from.
+func (s *State) modified(path string, from *Bundle) bool {
+	current := s.Files[path]
+	if content, found := from.Files[path]; found && content == current {
+		return false
+	}
+	sum, found := s.Checksums[path]
+	return !found || sum != scaffold.Checksum([]byte(current))
+}
+
+// ActionType is the type of change of a file
+type ActionType string
+
+const (
+	// Create writes a file scaffolded by the new templates that doesn't exist in the project
+	Create ActionType = "create"
+
+	// Update overwrites a file not modified by the user whose template changed
+	Update ActionType = "update"
+
+	// Delete removes a file not modified by the user that isn't scaffolded anymore
+	Delete ActionType = "delete"
+
+	// Conflict reports a file modified by the user whose template changed, the change has to be merged by hand
+	Conflict ActionType = "conflict"
+)
+
+// Action is a change of a file of the project
+type Action struct {
+	// Type is the type of change
+	Type ActionType `json:"type"`
+
+	// Path is the slash separated path of the file relative to the project root
+	Path string `json:"path"`
+
+	// Content is the content of the file scaffolded by the new templates, empty when it isn't scaffolded anymore
+	Content string `json:"content,omitempty"`
+
+	// Diff is the unified diff of the change. For conflicts, it is the change of the template to merge
+	// into the file modified by the user.
+	Diff string `json:"diff"`
+}
+
+// Plan is the set of changes upgrading a project from a version of the templates to another one
+type Plan struct {
+	// From is the version of the templates the project was scaffolded with
+	From string `json:"from"`
+
+	// To is the version of the templates the project is upgraded to
+	To string `json:"to"`
+
+	// Actions are the changes of the files of the project, sorted by path
+	Actions []Action `json:"actions"`
+}
+
+// Diff returns the changes upgrading the project in state from the templates of the from bundle to the ones
+// of the to bundle. The files whose template didn't change are left as they are, even if the user modified them.
+func Diff(from, to *Bundle, state *State) *Plan {
+	plan := &Plan{From: from.Version, To: to.Version}
+
+	for path, content := range to.Files {
+		current, exists := state.Files[path]
+		previous, scaffolded := from.Files[path]
+		switch {
+		case !exists:
+			plan.add(Create, path, content, util.UnifiedDiff("/dev/null", "b/"+path, "", content))
+		case current == content:
+			// already up to date
+		case !state.modified(path, from):
+			plan.add(Update, path, content, util.UnifiedDiff("a/"+path, "b/"+path, current, content))
+		case !scaffolded:
+			// the user created a file the new templates scaffold
+			plan.add(Conflict, path, content, util.UnifiedDiff("a/"+path, "b/"+path, current, content))
+		case previous != content:
+			plan.add(Conflict, path, content, util.UnifiedDiff("a/"+path, "b/"+path, previous, content))
+		}
+	}
+
+	for path, previous := range from.Files {
+		if _, scaffolded := to.Files[path]; scaffolded {
+			continue
+		}
+		current, exists := state.Files[path]
+		switch {
+		case !exists:
+			// already removed
+		case !state.modified(path, from):
+			plan.add(Delete, path, "", util.UnifiedDiff("a/"+path, "/dev/null", current, ""))
+		default:
+			plan.add(Conflict, path, "", util.UnifiedDiff("a/"+path, "/dev/null", previous, ""))
+		}
+	}
+
+	sort.Slice(plan.Actions, func(i, j int) bool { return plan.Actions[i].Path < plan.Actions[j].Path })
+	return plan
+}
+
+// DiffTemplates returns the changes scaffolding the project in state again with the templates of the to bundle
+// would make, when the templates of the from version it was scaffolded with aren't available, e.g. rendered by an
+// older version of kubebuilder. The files recorded in the checksums with their scaffolded content are compared
+// with the templates and updated or deleted, the modified ones are reported as conflicts along with their diff
+// with the templates.
+func DiffTemplates(from string, to *Bundle, state *State) *Plan {
+	// the content of the unmodified files is the content of the templates they were scaffolded with
+	scaffolded := &Bundle{Version: from, Files: map[string]string{}}
+	for path, sum := range state.Checksums {
+		if current, exists := state.Files[path]; exists && sum == scaffold.Checksum([]byte(current)) {
+			scaffolded.Files[path] = current
+		}
+	}
+	plan := Diff(scaffolded, to, state)
+
+	// the modified files which aren't scaffolded anymore, unknown to Diff
+	for path := range state.Checksums {
+		current, exists := state.Files[path]
+		_, rendered := to.Files[path]
+		_, unmodified := scaffolded.Files[path]
+		if exists && !rendered && !unmodified {
+			plan.add(Conflict, path, "", util.UnifiedDiff("a/"+path, "/dev/null", current, ""))
+		}
+	}
+
+	sort.Slice(plan.Actions, func(i, j int) bool { return plan.Actions[i].Path < plan.Actions[j].Path })
+	return plan
+}
+
+func (p *Plan) add(t ActionType, path, content, diff string) {
+	p.Actions = append(p.Actions, Action{Type: t, Path: path, Content: content, Diff: diff})
+}
+
+// Conflicts returns the actions that have to be merged by hand
+func (p *Plan) Conflicts() []Action {
+	var conflicts []Action
+	for _, action := range p.Actions {
+		if action.Type == Conflict {
+			conflicts = append(conflicts, action)
+		}
+	}
+	return conflicts
+}
+
+// Apply creates, updates and deletes the files of the project in dir, recording the checksums of the
+// written files. Conflicts are left to the user.
+func (p *Plan) Apply(dir string) error {
+	checksums, err := scaffold.ReadChecksums(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, action := range p.Actions {
+		path := filepath.Join(dir, filepath.FromSlash(action.Path))
+		switch action.Type {
+		case Create, Update:
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			if err := ioutil.WriteFile(path, []byte(action.Content), 0644); err != nil {
+				return err
+			}
+			checksums[action.Path] = scaffold.Checksum([]byte(action.Content))
+		case Delete:
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			delete(checksums, action.Path)
+		}
+	}
+
+	return scaffold.WriteChecksums(dir, checksums)
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package migrate_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "sigs.k8s.io/kubebuilder/pkg/migrate"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/project"
+	scaffoldv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2"
+)
+
+func actions(plan *Plan) map[string]ActionType {
+	types := map[string]ActionType{}
+	for _, action := range plan.Actions {
+		types[action.Path] = action.Type
+	}
+	return types
+}
+
+var _ = Describe("Diff", func() {
+	from := &Bundle{Version: "v2.2.0", Files: map[string]string{
+		"Makefile":     "all: build\n",
+		"main.go":      "package main\n",
+		"Dockerfile":   "FROM golang:1.12\n",
+		"hack/old.sh":  "echo old\n",
+		"hack/kept.sh": "echo kept\n",
+	}}
+	to := &Bundle{Version: "v2.3.0", Files: map[string]string{
+		"Makefile":     "all: build test\n",
+		"main.go":      "package main\n\nfunc main() {}\n",
+		"Dockerfile":   "FROM golang:1.13\n",
+		"hack/kept.sh": "echo kept\n",
+		"PROJECT":      "version: \"2\"\n",
+	}}
+
+	It("should update the unmodified files and report the conflicts of the modified ones", func() {
+		state := &State{
+			Files: map[string]string{
+				"Makefile":     "all: build\n",
+				"main.go":      "package main\n\n// modified\n",
+				"Dockerfile":   "FROM golang:1.13\n",
+				"hack/old.sh":  "echo old\n",
+				"hack/kept.sh": "echo modified\n",
+			},
+		}
+
+		plan := Diff(from, to, state)
+		Expect(plan.From).To(Equal("v2.2.0"))
+		Expect(plan.To).To(Equal("v2.3.0"))
+		Expect(actions(plan)).To(Equal(map[string]ActionType{
+			"Makefile":    Update,
+			"main.go":     Conflict,
+			"hack/old.sh": Delete,
+			"PROJECT":     Create,
+		}))
+		Expect(plan.Actions[0].Path).To(Equal("Makefile"))
+		Expect(plan.Actions[0].Diff).To(ContainSubstring("-all: build\n+all: build test\n"))
+
+		conflicts := plan.Conflicts()
+		Expect(conflicts).To(HaveLen(1))
+		Expect(conflicts[0].Content).To(Equal(to.Files["main.go"]))
+		Expect(conflicts[0].Diff).To(ContainSubstring("+func main() {}"))
+	})
+
+	It("should consider the files with the checksum of their scaffolded content unmodified", func() {
+		state := &State{
+			Files: map[string]string{"main.go": "package main\n\n// regenerated\n"},
+			Checksums: map[string]string{
+				"main.go": scaffold.Checksum([]byte("package main\n\n// regenerated\n")),
+			},
+		}
+
+		Expect(actions(Diff(from, to, state))).To(HaveKeyWithValue("main.go", Update))
+	})
+
+	It("should report the modified files that aren't scaffolded anymore as conflicts", func() {
+		state := &State{Files: map[string]string{"hack/old.sh": "echo modified\n"}}
+
+		Expect(actions(Diff(from, to, state))).To(HaveKeyWithValue("hack/old.sh", Conflict))
+	})
+})
+
+var _ = Describe("DiffTemplates", func() {
+	to := &Bundle{Version: "v2.3.0", Files: map[string]string{
+		"Makefile":   "all: build test\n",
+		"main.go":    "package main\n\nfunc main() {}\n",
+		"Dockerfile": "FROM golang:1.13\n",
+		"PROJECT":    "version: \"2\"\n",
+	}}
+	sum := func(content string) string { return scaffold.Checksum([]byte(content)) }
+
+	It("should compare the recorded files with the templates", func() {
+		state := &State{
+			Files: map[string]string{
+				"Makefile":     "all: build\n",
+				"main.go":      "package main\n\n// modified\n",
+				"Dockerfile":   "FROM golang:1.13\n",
+				"hack/old.sh":  "echo old\n",
+				"hack/kept.sh": "echo modified\n",
+			},
+			Checksums: map[string]string{
+				"Makefile":     sum("all: build\n"),
+				"main.go":      sum("package main\n"),
+				"Dockerfile":   sum("FROM golang:1.12\n"),
+				"hack/old.sh":  sum("echo old\n"),
+				"hack/kept.sh": sum("echo kept\n"),
+			},
+		}
+
+		plan := DiffTemplates("v2.2.0", to, state)
+		Expect(plan.From).To(Equal("v2.2.0"))
+		Expect(plan.To).To(Equal("v2.3.0"))
+		Expect(actions(plan)).To(Equal(map[string]ActionType{
+			"Makefile":     Update,
+			"main.go":      Conflict,
+			"hack/old.sh":  Delete,
+			"hack/kept.sh": Conflict,
+			"PROJECT":      Create,
+		}))
+		Expect(plan.Actions[2].Path).To(Equal("hack/kept.sh"))
+		Expect(plan.Actions[2].Diff).To(ContainSubstring("-echo modified\n"))
+		Expect(plan.Actions[4].Path).To(Equal("main.go"))
+		Expect(plan.Actions[4].Diff).To(ContainSubstring("-// modified\n+func main() {}\n"))
+	})
+
+	It("should read the recorded files of a project", func() {
+		dir, err := ioutil.TempDir("", "migrate")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+
+		Expect(ioutil.WriteFile(filepath.Join(dir, "Makefile"), []byte("all: build\n"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644)).To(Succeed())
+		Expect(scaffold.WriteChecksums(dir, map[string]string{
+			"Makefile":    sum("all: build\n"),
+			"hack/old.sh": sum("echo old\n"),
+		})).To(Succeed())
+
+		bundle, err := ReadScaffolded("v2.3.0", dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bundle.Version).To(Equal("v2.3.0"))
+		Expect(bundle.Files).To(Equal(map[string]string{"Makefile": "all: build\n"}))
+	})
+})
+
+var _ = Describe("Plan", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "migrate")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should apply the changes but the conflicts and record the checksums", func() {
+		Expect(ioutil.WriteFile(filepath.Join(dir, "Makefile"), []byte("all: build\n"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "old.sh"), []byte("echo old\n"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte("// modified\n"), 0644)).To(Succeed())
+
+		plan := &Plan{Actions: []Action{
+			{Type: Update, Path: "Makefile", Content: "all: build test\n"},
+			{Type: Create, Path: "hack/new.sh", Content: "echo new\n"},
+			{Type: Delete, Path: "old.sh"},
+			{Type: Conflict, Path: "main.go", Content: "package main\n"},
+		}}
+		Expect(plan.Apply(dir)).To(Succeed())
+
+		b, err := ioutil.ReadFile(filepath.Join(dir, "Makefile"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(Equal("all: build test\n"))
+		b, err = ioutil.ReadFile(filepath.Join(dir, "hack", "new.sh"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(Equal("echo new\n"))
+		_, err = os.Stat(filepath.Join(dir, "old.sh"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+		b, err = ioutil.ReadFile(filepath.Join(dir, "main.go"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(b)).To(Equal("// modified\n"))
+
+		checksums, err := scaffold.ReadChecksums(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(checksums).To(Equal(map[string]string{
+			"Makefile":    scaffold.Checksum([]byte("all: build test\n")),
+			"hack/new.sh": scaffold.Checksum([]byte("echo new\n")),
+		}))
+	})
+
+	It("should upgrade the files rendered from the templates", func() {
+		Expect(ioutil.WriteFile(filepath.Join(dir, "PROJECT"),
+			[]byte("version: \"2\"\ndomain: example.org\nrepo: example.org/project\n"), 0644)).To(Succeed())
+		Expect(os.MkdirAll(filepath.Join(dir, "hack"), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "hack", "boilerplate.go.txt"), nil, 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM golang:1.12\n"), 0644)).To(Succeed())
+
+		to, err := Render("v2.3.0", dir, input.Options{}, &project.GitIgnore{}, &scaffoldv2.Dockerfile{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(to.Files).To(HaveKey(".gitignore"))
+		Expect(to.Files).To(HaveKey("Dockerfile"))
+		_, err = os.Stat(filepath.Join(dir, ".gitignore"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+
+		from := &Bundle{Version: "v2.2.0", Files: map[string]string{"Dockerfile": "FROM golang:1.12\n"}}
+		state, err := LoadState(dir, from, to)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Files).To(Equal(map[string]string{"Dockerfile": "FROM golang:1.12\n"}))
+
+		plan := Diff(from, to, state)
+		Expect(actions(plan)).To(Equal(map[string]ActionType{".gitignore": Create, "Dockerfile": Update}))
+		Expect(plan.Apply(dir)).To(Succeed())
+
+		state, err = LoadState(dir, to)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.Files).To(Equal(to.Files))
+		Expect(Diff(to, to, state).Actions).To(BeEmpty())
+	})
+})
@@ -17,6 +17,15 @@ limitations under the License.
 package config
 
 import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gobuffalo/flect"
+
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
 )
 
@@ -26,6 +35,59 @@ const (
 	Version2 = "2"
 )
 
+const (
+	// LowerFileNames names the files of a kind after its lower case name, e.g. firstmate_types.go, the default
+	LowerFileNames = "lower"
+
+	// SnakeFileNames names the files of a kind after its snake case name, e.g. first_mate_types.go
+	SnakeFileNames = "snake"
+
+	// DefaultReconcilerSuffix follows the kind in the name of its reconciler type, e.g. FirstMateReconciler
+	DefaultReconcilerSuffix = "Reconciler"
+)
+
+const (
+	// NamespaceTenancy isolates the tenants of the operator by namespace: the objects of a tenant are the ones of
+	// its namespace, the reconciles of its objects only access them
+	NamespaceTenancy = "namespace"
+)
+
+const (
+	// CSISecretStore mounts the credentials of the manager from an external secret store with the Secrets Store
+	// CSI driver, described by a SecretProviderClass
+	CSISecretStore = "csi"
+
+	// ExternalSecretsStore syncs the credentials of the manager from an external secret store into a Secret with
+	// the external-secrets operator, described by an ExternalSecret
+	ExternalSecretsStore = "external-secrets"
+)
+
+const (
+	// RBACProxyMetricsProtection protects the metrics endpoint with the kube-rbac-proxy sidecar of the manager,
+	// terminating TLS with a self-signed certificate and authorizing the clients
+	RBACProxyMetricsProtection = "rbac-proxy"
+
+	// TLSMetricsProtection serves the metrics over TLS from the manager itself, with a certificate of cert-manager,
+	// authenticating and authorizing the clients against the API server
+	TLSMetricsProtection = "tls"
+)
+
+const (
+	// CertManagerCertProvider provisions the serving certificate of the webhook server with cert-manager, which
+	// injects its CA into the webhooks
+	CertManagerCertProvider = "cert-manager"
+
+	// SelfSignedCertProvider provisions the serving certificate of the webhook server from the manager itself,
+	// which generates a self-signed CA, injects it into the webhooks and renews the certificate
+	SelfSignedCertProvider = "self-signed"
+)
+
+const (
+	// WireInjection injects the reconcilers and the webhooks set up by main.go with google/wire, from the
+	// provider sets of providers.go
+	WireInjection = "wire"
+)
+
 // Config is the unmarshalled representation of the configuration file
 type Config struct {
 	// Version is the project version, defaults to "1" (backwards compatibility)
@@ -41,25 +103,126 @@ type Config struct {
 	// This info is tracked only in project with version 2
 	Resources []GVK `json:"resources,omitempty"`
 
+	// Schemes are the API group versions defined outside of the project whose types are registered in the
+	// schemes of main.go and of the suites of the controllers, e.g. monitoring.coreos.com/v1
+	Schemes []Scheme `json:"schemes,omitempty"`
+
 	// Multigroup tracks if the project has more than one group
 	MultiGroup bool `json:"multigroup,omitempty"`
+
+	// Hybrid configures the resources scaffolded with the hybrid pattern,
+	// which are reconciled by applying rendered manifests or Helm charts
+	Hybrid *Hybrid `json:"hybrid,omitempty"`
+
+	// Dependencies pins the versions of the libraries and tools the project was scaffolded for
+	Dependencies *Dependencies `json:"dependencies,omitempty"`
+
+	// Manifests configures the directories the manifests are generated into, defaults to the ones under config
+	Manifests *Manifests `json:"manifests,omitempty"`
+
+	// Naming configures the names of the scaffolded types and files, e.g. to follow a style guide
+	Naming *Naming `json:"naming,omitempty"`
+
+	// Tenancy is the way the tenants of the operator are isolated, none if empty
+	Tenancy string `json:"tenancy,omitempty"`
+
+	// DependencyInjection is the framework injecting the reconcilers and the webhooks set up by main.go, they are
+	// constructed by main.go if empty
+	DependencyInjection string `json:"dependencyInjection,omitempty"`
+
+	// SecretStore is the way the credentials of the manager are read from an external secret store, e.g. the keys
+	// of a cloud provider, none if empty
+	SecretStore string `json:"secretStore,omitempty"`
+
+	// MetricsProtection is the way the metrics endpoint of the manager is protected, RBACProxyMetricsProtection if
+	// empty
+	MetricsProtection string `json:"metricsProtection,omitempty"`
+
+	// CertProvider is the way the serving certificate of the webhook server is provisioned, set by the first
+	// webhook, CertManagerCertProvider if empty
+	CertProvider string `json:"certProvider,omitempty"`
+
+	// MinKubernetesVersion is the oldest Kubernetes version the manifests are scaffolded for, e.g. "1.16", the
+	// first row of the compatibility matrix if empty
+	MinKubernetesVersion string `json:"minKubernetesVersion,omitempty"`
+}
+
+// HasNamespaceTenancy returns true if the tenants of the operator are isolated by namespace
+func (config Config) HasNamespaceTenancy() bool {
+	return config.Tenancy == NamespaceTenancy
+}
+
+// HasSecretStore returns true if the credentials of the manager are read from an external secret store
+func (config Config) HasSecretStore() bool {
+	return config.SecretStore != ""
+}
+
+// HasTLSMetrics returns true if the manager serves its metrics over TLS itself rather than behind kube-rbac-proxy
+func (config Config) HasTLSMetrics() bool {
+	return config.MetricsProtection == TLSMetricsProtection
+}
+
+// HasSelfSignedCerts returns true if the manager provisions the serving certificate of the webhook server itself
+// rather than cert-manager
+func (config Config) HasSelfSignedCerts() bool {
+	return config.CertProvider == SelfSignedCertProvider
+}
+
+// HasWireInjection returns true if the reconcilers and the webhooks set up by main.go are injected by google/wire
+func (config Config) HasWireInjection() bool {
+	return config.DependencyInjection == WireInjection
+}
+
+// CRDDir returns the directory of the CRD manifests relative to the project root
+func (config Config) CRDDir() string {
+	if config.Manifests != nil && config.Manifests.CRDDir != "" {
+		return filepath.FromSlash(config.Manifests.CRDDir)
+	}
+	return filepath.Join("config", "crd")
+}
+
+// RBACDir returns the directory of the RBAC manifests relative to the project root
+func (config Config) RBACDir() string {
+	if config.Manifests != nil && config.Manifests.RBACDir != "" {
+		return filepath.FromSlash(config.Manifests.RBACDir)
+	}
+	return filepath.Join("config", "rbac")
+}
+
+// WebhookDir returns the directory of the webhook manifests relative to the project root
+func (config Config) WebhookDir() string {
+	if config.Manifests != nil && config.Manifests.WebhookDir != "" {
+		return filepath.FromSlash(config.Manifests.WebhookDir)
+	}
+	return filepath.Join("config", "webhook")
 }
 
-// IsV1 returns true if it is a v1 project
-func (config Config) IsV1() bool {
-	return config.Version == Version1
+// Names returns the naming policies of the project, the default ones if it doesn't configure them
+func (config Config) Names() Naming {
+	if config.Naming == nil {
+		return Naming{}
+	}
+	return *config.Naming
 }
 
-// IsV2 returns true if it is a v2 project
-func (config Config) IsV2() bool {
-	return config.Version == Version2
+// Kubernetes returns the row of the compatibility matrix of the minimum Kubernetes version of the project, the
+// first row if it doesn't set one or sets an unsupported one
+func (config Config) Kubernetes() Compatibility {
+	c, err := CompatibilityOf(config.MinKubernetesVersion)
+	if err != nil {
+		c, _ = CompatibilityOf("")
+	}
+	return c
 }
 
 // ResourceGroups returns unique groups of scaffolded resources in the project
 func (config Config) ResourceGroups() []string {
 	groupSet := map[string]struct{}{}
 	for _, r := range config.Resources {
-		groupSet[r.Group] = struct{}{}
+		// the groups of the kinds defined outside of the project, e.g. apps, aren't groups of the project
+		if r.Package == "" {
+			groupSet[r.Group] = struct{}{}
+		}
 	}
 
 	groups := make([]string, 0, len(groupSet))
@@ -104,16 +267,395 @@ func (config *Config) AddResource(r *resource.Resource) bool {
 	}
 
 	// Append the resource to the tracked ones, return true
-	config.Resources = append(config.Resources,
-		GVK{Group: r.Group, Version: r.Version, Kind: r.Kind})
+	gvk := GVK{Group: r.Group, Version: r.Version, Kind: r.Kind, NoCRD: r.NoCRD}
+	if !r.HasDefaultPlural() {
+		gvk.Plural = r.Plural()
+	}
+	config.Resources = append(config.Resources, gvk)
+	return true
+}
+
+// AddExternalResource appends a kind defined outside of the project, e.g. a built-in kind, to the tracked ones
+// along with the go package of its version, e.g. k8s.io/api/apps/v1 for the Deployments.
+// It returns if the configuration was modified
+// NOTE: this works only for v2, since in v1 resources are not tracked
+func (config *Config) AddExternalResource(r *resource.Resource, pkg string) bool {
+	// Short-circuit v1
+	if config.Version == Version1 {
+		return false
+	}
+
+	// No-op if the resource was already tracked, return false
+	if config.HasResource(r) {
+		return false
+	}
+
+	// Append the resource to the tracked ones, return true
+	gvk := GVK{Group: r.Group, Version: r.Version, Kind: r.Kind, Package: pkg}
+	if !r.HasDefaultPlural() {
+		gvk.Plural = r.Plural()
+	}
+	config.Resources = append(config.Resources, gvk)
+	return true
+}
+
+// PluralOf returns the plural the API resource was tracked with if it isn't the one derived from its kind, an
+// empty string otherwise
+func (config Config) PluralOf(target *resource.Resource) string {
+	for _, r := range config.Resources {
+		if r.isEqualTo(target) {
+			return r.Plural
+		}
+	}
+	return ""
+}
+
+// SharesKind returns true if the kind of the API resource is the kind of a resource of another group tracked
+// before it, any of them if it isn't tracked: the first group keeps the names the groups share, e.g. of the roles
+// of the kind, the next ones qualify them by their group
+func (config Config) SharesKind(target *resource.Resource) bool {
+	for _, r := range config.Resources {
+		if r.Kind == target.Kind {
+			return r.Group != target.Group
+		}
+	}
+	return false
+}
+
+// GroupImportedAs returns the tracked group other than group whose Go import aliases are the ones of group,
+// e.g. sea-creatures and seacreatures are both imported as seacreatures<version>, or an empty string
+func (config Config) GroupImportedAs(group string) string {
+	safe := strings.NewReplacer("-", "", ".", "")
+	for _, r := range config.Resources {
+		if r.Group != group && safe.Replace(r.Group) == safe.Replace(group) {
+			return r.Group
+		}
+	}
+	return ""
+}
+
+// SchemeImportAlias returns the name the package of the registered group version is imported as in the Go files
+// of the project: its short name, e.g. certmanagerv1, unless a group version of the project or a group version
+// registered before it is imported as such, e.g. the group monitoring of the project and monitoring.coreos.com
+// are both monitoringv1, the name is then qualified by its whole group, e.g. monitoringcoreoscomv1.
+// The group versions of the project keep their names, their packages are the ones imported the most.
+func (config Config) SchemeImportAlias(scheme Scheme) string {
+	safe := strings.NewReplacer("-", "", ".", "")
+	taken := map[string]bool{}
+	for _, r := range config.Resources {
+		taken[safe.Replace(r.Group)+r.Version] = true
+	}
+	for _, s := range config.Schemes {
+		alias := s.ImportAlias()
+		if taken[alias] {
+			alias = s.qualifiedImportAlias()
+		}
+		if s.Group == scheme.Group && s.Version == scheme.Version {
+			return alias
+		}
+		taken[alias] = true
+	}
+	// the group version isn't registered yet, it is named as if it were the last one
+	if alias := scheme.ImportAlias(); !taken[alias] {
+		return alias
+	}
+	return scheme.qualifiedImportAlias()
+}
+
+// WiringOf returns the preferences of the wiring of the API resource in main.go, the default ones if it isn't
+// tracked or doesn't record any
+func (config Config) WiringOf(target *resource.Resource) Wiring {
+	for _, r := range config.Resources {
+		if r.isEqualTo(target) && r.Wiring != nil {
+			return *r.Wiring
+		}
+	}
+	return Wiring{}
+}
+
+// SetWiring records the preferences of the wiring of the API resource in main.go
+// It returns false if the resource is not tracked
+func (config *Config) SetWiring(target *resource.Resource, wiring Wiring) bool {
+	for i, r := range config.Resources {
+		if r.isEqualTo(target) {
+			config.Resources[i].Wiring = nil
+			if wiring != (Wiring{}) {
+				config.Resources[i].Wiring = &wiring
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// SetClients records that the typed clients of the API resource are generated
+// It returns false if the resource is not tracked
+func (config *Config) SetClients(target *resource.Resource) bool {
+	for i, r := range config.Resources {
+		if r.isEqualTo(target) {
+			config.Resources[i].Clients = true
+			return true
+		}
+	}
+	return false
+}
+
+// ClientPackages returns the sorted directories of the group versions of the kinds whose typed clients are
+// generated, relative to the project root, e.g. api/v1
+func (config Config) ClientPackages() []string {
+	seen := map[string]bool{}
+	var packages []string
+	for _, r := range config.Resources {
+		if !r.Clients {
+			continue
+		}
+		dir := path.Join("api", r.Version)
+		if config.MultiGroup {
+			dir = path.Join("apis", r.Group, r.Version)
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			packages = append(packages, dir)
+		}
+	}
+	sort.Strings(packages)
+	return packages
+}
+
+// HasCRD returns false if the API resource is tracked as not defined by a CRD of the project, e.g. a kind defined
+// outside of the project
+func (config Config) HasCRD(target *resource.Resource) bool {
+	for _, r := range config.Resources {
+		if r.isEqualTo(target) {
+			return !r.NoCRD && r.Package == ""
+		}
+	}
+	return true
+}
+
+// VersionsOf returns the tracked versions of the kind of the API resource defined by the project, in the order
+// they were scaffolded
+func (config Config) VersionsOf(target *resource.Resource) []string {
+	var versions []string
+	for _, r := range config.Resources {
+		if r.Group == target.Group && r.Kind == target.Kind && r.Package == "" {
+			versions = append(versions, r.Version)
+		}
+	}
+	return versions
+}
+
+// StorageVersionOf returns the version the kind of the API resource is stored as: the version tracked as the
+// storage one, the first tracked version otherwise. It returns an empty string if the kind isn't tracked.
+func (config Config) StorageVersionOf(target *resource.Resource) string {
+	for _, r := range config.Resources {
+		if r.Group == target.Group && r.Kind == target.Kind && r.Storage {
+			return r.Version
+		}
+	}
+	if versions := config.VersionsOf(target); len(versions) > 0 {
+		return versions[0]
+	}
+	return ""
+}
+
+// SetStorageVersion records the version of the API resource as the one its kind is stored as
+// It returns false if the resource is not tracked
+func (config *Config) SetStorageVersion(target *resource.Resource) bool {
+	if !config.HasResource(target) {
+		return false
+	}
+	for i, r := range config.Resources {
+		if r.Group == target.Group && r.Kind == target.Kind {
+			config.Resources[i].Storage = r.isEqualTo(target)
+		}
+	}
+	return true
+}
+
+// AddScheme records the API group version defined outside of the project as registered in its schemes
+// It returns false if the project version is 1 or the group version was already registered
+func (config *Config) AddScheme(scheme Scheme) bool {
+	// Short-circuit v1
+	if config.Version == Version1 {
+		return false
+	}
+
+	for _, s := range config.Schemes {
+		if s.Group == scheme.Group && s.Version == scheme.Version {
+			return false
+		}
+	}
+	config.Schemes = append(config.Schemes, scheme)
 	return true
 }
 
+// Hybrid sources
+const (
+	HybridSourceManifests = "manifests"
+	HybridSourceHelm      = "helm"
+)
+
+// Hybrid contains the configuration of the hybrid pattern
+type Hybrid struct {
+	// Source is the kind of source the manifests are rendered from, one of "manifests" or "helm"
+	Source string `json:"source,omitempty"`
+
+	// ManifestsDir is the directory containing the rendered manifests of every resource
+	ManifestsDir string `json:"manifestsDir,omitempty"`
+
+	// ChartsDir is the directory containing the Helm charts of every resource, only used for the "helm" source
+	ChartsDir string `json:"chartsDir,omitempty"`
+}
+
+// Dependencies contains the pinned versions of the project dependencies
+type Dependencies struct {
+	// ControllerRuntime is the version of sigs.k8s.io/controller-runtime required in go.mod
+	ControllerRuntime string `json:"controllerRuntime,omitempty"`
+
+	// ControllerGen is the version of controller-gen installed by the Makefile
+	ControllerGen string `json:"controllerGen,omitempty"`
+
+	// Kustomize is the version of kustomize the config directory is written for
+	Kustomize string `json:"kustomize,omitempty"`
+
+	// Wire is the version of github.com/google/wire required in go.mod, for the projects injecting with it
+	Wire string `json:"wire,omitempty"`
+}
+
+// Manifests contains the directories of the generated manifests, slash separated and relative to the
+// project root, for projects following other conventions than the config directory, e.g. deploy/crd
+type Manifests struct {
+	// CRDDir is the directory of the CRDs and their kustomization, defaults to config/crd
+	CRDDir string `json:"crdDir,omitempty"`
+
+	// RBACDir is the directory of the roles and their kustomization, defaults to config/rbac
+	RBACDir string `json:"rbacDir,omitempty"`
+
+	// WebhookDir is the directory of the webhook configurations and their kustomization, defaults to config/webhook
+	WebhookDir string `json:"webhookDir,omitempty"`
+}
+
+// Naming contains the naming policies of the scaffolded types and files, the zero value is the default policy
+type Naming struct {
+	// ReconcilerPrefix and ReconcilerSuffix surround the kind in the name of its reconciler type, the suffix
+	// defaults to Reconciler, e.g. FirstMateReconciler
+	ReconcilerPrefix string `json:"reconcilerPrefix,omitempty"`
+	ReconcilerSuffix string `json:"reconcilerSuffix,omitempty"`
+
+	// FileNames is the case of the kind in the names of its files, "lower" (the default) or "snake"
+	FileNames string `json:"fileNames,omitempty"`
+}
+
+var identifierPartRe = regexp.MustCompile(`^[A-Za-z0-9_]*$`)
+
+// Validate returns an error if the names following the policies are not valid Go identifiers or file names
+func (n Naming) Validate() error {
+	if n.ReconcilerPrefix != "" && !regexp.MustCompile(`^[A-Z]`).MatchString(n.ReconcilerPrefix) {
+		return fmt.Errorf("invalid reconciler prefix %q, the reconciler types are exported, "+
+			"the prefix must start with an upper case letter", n.ReconcilerPrefix)
+	}
+	for _, part := range []string{n.ReconcilerPrefix, n.ReconcilerSuffix} {
+		if !identifierPartRe.MatchString(part) {
+			return fmt.Errorf("invalid reconciler prefix or suffix %q, it must only contain letters, digits "+
+				"and underscores", part)
+		}
+	}
+	switch n.FileNames {
+	case "", LowerFileNames, SnakeFileNames:
+	default:
+		return fmt.Errorf("unknown file names %q, must be one of %q or %q", n.FileNames, LowerFileNames,
+			SnakeFileNames)
+	}
+	return nil
+}
+
+// ReconcilerName returns the name of the reconciler type of the kind
+func (n Naming) ReconcilerName(kind string) string {
+	suffix := n.ReconcilerSuffix
+	if suffix == "" {
+		suffix = DefaultReconcilerSuffix
+	}
+	return n.ReconcilerPrefix + kind + suffix
+}
+
+// FileName returns the name of the kind in the names of its files, e.g. firstmate in firstmate_types.go
+func (n Naming) FileName(kind string) string {
+	if n.FileNames == SnakeFileNames {
+		return flect.Underscore(kind)
+	}
+	return strings.ToLower(kind)
+}
+
 // GVK contains information about scaffolded resources
 type GVK struct {
 	Group   string `json:"group,omitempty"`
 	Version string `json:"version,omitempty"`
 	Kind    string `json:"kind,omitempty"`
+
+	// Plural is the resource of the kind if it isn't the plural derived from the kind, e.g. redises for Redis
+	Plural string `json:"plural,omitempty"`
+
+	// NoCRD is true if the kind isn't defined by a CRD of the project, its CRD is neither deployed nor sampled
+	NoCRD bool `json:"noCRD,omitempty"`
+
+	// Package is the go package of the version of the kind if it is defined outside of the project, e.g.
+	// k8s.io/api/apps/v1 for the built-in Deployments, whose controllers and webhooks are scaffolded without types
+	Package string `json:"package,omitempty"`
+
+	// Storage is true for the version the kind is stored as if the project has several versions of the kind, the
+	// hub of the conversions between them
+	Storage bool `json:"storage,omitempty"`
+
+	// Wiring contains the preferences of the wiring of the kind in main.go, the scaffolds wire everything if unset
+	Wiring *Wiring `json:"wiring,omitempty"`
+
+	// Clients is true if the typed clientset, informers and listers of the kind are generated by make clients
+	Clients bool `json:"clients,omitempty"`
+}
+
+// Wiring contains the preferences of the wiring of a kind in main.go, so that the scaffolds don't add again the
+// wiring a user removed on purpose, e.g. to set up the reconciler behind a feature gate
+type Wiring struct {
+	// SkipController indicates that the scaffolds don't set up the reconciler of the kind in main.go
+	SkipController bool `json:"skipController,omitempty"`
+
+	// SkipWebhook indicates that the scaffolds don't set up the webhooks of the kind in main.go
+	SkipWebhook bool `json:"skipWebhook,omitempty"`
+}
+
+// Scheme is an API group version defined outside of the project, e.g. by the CRDs of another operator, whose
+// types are used by the controllers of the project
+type Scheme struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+
+	// Package is the go package of the types of the group version, e.g.
+	// github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1
+	Package string `json:"package"`
+
+	// Module and ModuleVersion are the go module of the package and its version required by go.mod, the
+	// requirement is left to go get if they are empty
+	Module        string `json:"module,omitempty"`
+	ModuleVersion string `json:"moduleVersion,omitempty"`
+
+	// Replace are the versions of the modules go.mod replaces for the module to build along with
+	// controller-runtime, indexed by module, e.g. the k8s.io/client-go required by the module as
+	// v12.0.0+incompatible, which would be selected over the one of controller-runtime
+	Replace map[string]string `json:"replace,omitempty"`
+}
+
+// ImportAlias returns the short name the package of the group version is imported as, e.g. certmanagerv1, see
+// Config.SchemeImportAlias for the name it is imported as in a project
+func (s Scheme) ImportAlias() string {
+	name := strings.SplitN(s.Group, ".", 2)[0]
+	return strings.Replace(name, "-", "", -1) + s.Version
+}
+
+// qualifiedImportAlias returns the name the package of the group version is imported as when its short name is
+// taken, qualified by the whole group, e.g. monitoringcoreoscomv1
+func (s Scheme) qualifiedImportAlias() string {
+	return strings.NewReplacer("-", "", ".", "").Replace(s.Group) + s.Version
 }
 
 // isEqualTo compares it with another resource
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Compatibility is the row of the compatibility matrix of a minimum Kubernetes version: the API versions and the
+// fields of the manifests the scaffolds write for the clusters of this version and above
+type Compatibility struct {
+	// MinKubernetesVersion is the oldest Kubernetes version supported by the manifests, e.g. "1.16"
+	MinKubernetesVersion string
+
+	// CRDVersion is the version of apiextensions.k8s.io of the CRDs, v1 is served from 1.16
+	CRDVersion string
+
+	// WebhookVersion is the version of admissionregistration.k8s.io of the webhook configurations. The patches of
+	// the configurations must match the ones generated by controller-gen, which only generates v1beta1 up to the
+	// version scaffolded in the Makefile.
+	WebhookVersion string
+
+	// IngressVersion is the version of networking.k8s.io of the ingresses, v1 is served from 1.19
+	IngressVersion string
+
+	// SeccompProfile sets the seccomp profile of the manager to the one of the container runtime, with the
+	// seccompProfile field of its security context which is GA from 1.19
+	SeccompProfile bool
+
+	// EphemeralStorage requests and limits the ephemeral storage of the manager, e.g. its logs, which are only
+	// accounted for by the kubelets with the local storage capacity isolation, enabled by default from 1.10
+	EphemeralStorage bool
+}
+
+// compatibilityMatrix are the rows of the compatibility matrix sorted by minimum version, the row of a version
+// is the last one whose minimum version isn't newer. The first row is the one of the projects which don't set
+// their minimum version: the manifests as scaffolded before it could be selected.
+var compatibilityMatrix = []Compatibility{
+	{
+		MinKubernetesVersion: "1.14",
+		CRDVersion:           "v1beta1",
+		WebhookVersion:       "v1beta1",
+		IngressVersion:       "v1beta1",
+	},
+	{
+		MinKubernetesVersion: "1.16",
+		CRDVersion:           "v1",
+		WebhookVersion:       "v1beta1",
+		IngressVersion:       "v1beta1",
+		EphemeralStorage:     true,
+	},
+	{
+		MinKubernetesVersion: "1.19",
+		CRDVersion:           "v1",
+		WebhookVersion:       "v1beta1",
+		IngressVersion:       "v1",
+		SeccompProfile:       true,
+		EphemeralStorage:     true,
+	},
+}
+
+// maxKubernetesMinor is the newest minor version supported as a minimum: 1.22 stops serving the v1beta1 webhook
+// configurations generated by controller-gen
+const maxKubernetesMinor = 21
+
+var kubernetesVersionRe = regexp.MustCompile(`^v?1\.([0-9]+)$`)
+
+// CompatibilityOf returns the row of the compatibility matrix of a minimum Kubernetes version, e.g. "1.16", the
+// first row if the version is empty. It returns an error if the version isn't supported.
+func CompatibilityOf(version string) (Compatibility, error) {
+	if version == "" {
+		return compatibilityMatrix[0], nil
+	}
+	m := kubernetesVersionRe.FindStringSubmatch(version)
+	if m == nil {
+		return Compatibility{}, fmt.Errorf("invalid Kubernetes version %q, expected a minor version, e.g. 1.16",
+			version)
+	}
+	minor, _ := strconv.Atoi(m[1])
+	if minor < minorOf(compatibilityMatrix[0]) || minor > maxKubernetesMinor {
+		return Compatibility{}, fmt.Errorf("unsupported Kubernetes version %q, %s", version,
+			SupportedKubernetesVersions())
+	}
+	row := compatibilityMatrix[0]
+	for _, c := range compatibilityMatrix {
+		if minorOf(c) <= minor {
+			row = c
+		}
+	}
+	row.MinKubernetesVersion = fmt.Sprintf("1.%d", minor)
+	return row, nil
+}
+
+// SupportedKubernetesVersions describes the range of the supported minimum Kubernetes versions
+func SupportedKubernetesVersions() string {
+	return fmt.Sprintf("the minimum version must be between %s and 1.%d",
+		compatibilityMatrix[0].MinKubernetesVersion, maxKubernetesMinor)
+}
+
+// minorOf returns the minor version of the minimum version of a row
+func minorOf(c Compatibility) int {
+	minor, _ := strconv.Atoi(kubernetesVersionRe.FindStringSubmatch(c.MinKubernetesVersion)[1])
+	return minor
+}
+
+// CRDOptions returns the options of controller-gen generating the CRDs of the API version of the row
+func (c Compatibility) CRDOptions() string {
+	if c.CRDVersion == "v1beta1" {
+		// single version CRDs work back to Kubernetes 1.11 (no version conversion)
+		return "crd:trivialVersions=true"
+	}
+	return "crd:crdVersions=" + c.CRDVersion
+}
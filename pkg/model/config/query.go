@@ -0,0 +1,127 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"sort"
+)
+
+// QueryContractVersion is the version of the contract of Query. Methods are only added to Query, and the meaning
+// of the existing ones only changes, with a new contract version, so that the plugins and the external tools
+// reading the configuration can check the one they are written for. The contract 2 adds SupportsScaffoldMarkers.
+const QueryContractVersion = 2
+
+// Query is the read-only view of the configuration of a project in terms of the capabilities of its version,
+// rather than of the version itself, so that its users keep working with the project versions to come
+type Query interface {
+	// ProjectVersion returns the version of the project, e.g. "2"
+	ProjectVersion() string
+
+	// SupportsMultiGroup returns true if the APIs of the project version may be laid out by group
+	SupportsMultiGroup() bool
+
+	// IsMultiGroup returns true if the APIs of the project are laid out by group, e.g. apis/<group>/<version>
+	IsMultiGroup() bool
+
+	// TracksResources returns true if the project version tracks the scaffolded resources in the configuration
+	TracksResources() bool
+
+	// Resources returns the resources scaffolded in the project, none if they aren't tracked
+	Resources() []GVK
+
+	// ResourceGroups returns the sorted groups of the resources scaffolded in the project
+	ResourceGroups() []string
+
+	// SupportsScaffoldMarkers returns true if the files of the project version are scaffolded with the markers
+	// the commands insert their code at, e.g. the imports and the setup of the reconcilers in main.go, which the
+	// commands extending an existing project and the optional features of the scaffolds rely on
+	SupportsScaffoldMarkers() bool
+}
+
+// capabilities are the capabilities of a project version
+type capabilities struct {
+	multiGroup     bool
+	trackResources bool
+	markers        bool
+}
+
+// versionCapabilities are the capabilities of the known project versions
+var versionCapabilities = map[string]capabilities{
+	Version1: {},
+	Version2: {multiGroup: true, trackResources: true, markers: true},
+}
+
+// UnknownVersionError is the error querying the configuration of a project of an unknown version
+type UnknownVersionError struct {
+	Version string
+}
+
+// Error implements error
+func (e UnknownVersionError) Error() string {
+	return fmt.Sprintf("unknown project version %v", e.Version)
+}
+
+// Query returns the query of the configuration, or an UnknownVersionError if its version isn't known
+func (config Config) Query() (Query, error) {
+	c, ok := versionCapabilities[config.Version]
+	if !ok {
+		return nil, UnknownVersionError{Version: config.Version}
+	}
+	return query{config: config, capabilities: c}, nil
+}
+
+var _ Query = query{}
+
+// query implements Query for the known project versions
+type query struct {
+	config       Config
+	capabilities capabilities
+}
+
+func (q query) ProjectVersion() string {
+	return q.config.Version
+}
+
+func (q query) SupportsMultiGroup() bool {
+	return q.capabilities.multiGroup
+}
+
+func (q query) IsMultiGroup() bool {
+	return q.capabilities.multiGroup && q.config.MultiGroup
+}
+
+func (q query) TracksResources() bool {
+	return q.capabilities.trackResources
+}
+
+func (q query) Resources() []GVK {
+	if !q.capabilities.trackResources {
+		return nil
+	}
+	return append([]GVK(nil), q.config.Resources...)
+}
+
+func (q query) ResourceGroups() []string {
+	groups := q.config.ResourceGroups()
+	sort.Strings(groups)
+	return groups
+}
+
+func (q query) SupportsScaffoldMarkers() bool {
+	return q.capabilities.markers
+}
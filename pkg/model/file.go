@@ -31,4 +31,12 @@ type File struct {
 	// TODO: Move input.IfExistsAction into model
 	// IfExistsAction determines what to do if the file exists
 	IfExistsAction input.IfExistsAction `json:"ifExistsAction,omitempty"`
+
+	// Conditions are the conditions of the writing of the file, it is skipped unless all of them hold. They let
+	// the plugins add files to the universe conditionally.
+	Conditions []input.Condition `json:"-"`
+
+	// Skipped is whether the Conditions of the file don't hold, they are evaluated once after the plugins ran and
+	// before any file is written
+	Skipped bool `json:"-"`
 }
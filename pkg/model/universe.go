@@ -17,12 +17,8 @@ limitations under the License.
 package model
 
 import (
-	"io/ioutil"
-	"strings"
-
-	"github.com/gobuffalo/flect"
-
 	internalconfig "sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
 	"sigs.k8s.io/kubebuilder/pkg/model/config"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
@@ -84,7 +80,7 @@ func WithConfig(projectConfig *config.Config) UniverseOption {
 // WithBoilerplateFrom loads the boilerplate from the provided path
 func WithBoilerplateFrom(path string) UniverseOption {
 	return func(universe *Universe) error {
-		boilerplate, err := ioutil.ReadFile(path)
+		boilerplate, err := filesystem.ReadFile(path)
 		if err != nil {
 			return err
 		}
@@ -117,10 +113,11 @@ func WithResource(resource *resource.Resource, project *config.Config) UniverseO
 			Version:    resource.Version,
 			Kind:       resource.Kind,
 			Resource:   resource.Resource,
-			Plural:     flect.Pluralize(strings.ToLower(resource.Kind)),
+			Plural:     resource.Plural(),
 		}
 
 		resourceModel.GoPackage, resourceModel.GroupDomain = util.GetResourceInfo(
+			"",
 			resource,
 			project.Repo,
 			project.Domain,
@@ -0,0 +1,248 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChangeType is the type of a change of a kind
+type ChangeType string
+
+const (
+	// KindAdded is a kind declared by the new API types only
+	KindAdded ChangeType = "kind-added"
+
+	// KindRemoved is a kind declared by the old API types only
+	KindRemoved ChangeType = "kind-removed"
+
+	// FieldAdded is a field of the new schema of a kind only
+	FieldAdded ChangeType = "field-added"
+
+	// FieldRemoved is a field of the old schema of a kind only
+	FieldRemoved ChangeType = "field-removed"
+
+	// TypeChanged is a field whose Go type changed
+	TypeChanged ChangeType = "type-changed"
+
+	// ValidationChanged is a field whose markers changed, e.g. its validation or whether it is optional
+	ValidationChanged ChangeType = "validation-changed"
+
+	// ScopeChanged is a kind whose CRD changed from namespaced to cluster-scoped or the other way around
+	ScopeChanged ChangeType = "scope-changed"
+)
+
+const (
+	optionalMarker = "+optional"
+	requiredMarker = "+kubebuilder:validation:Required"
+)
+
+// Change is a change of the API of a kind
+type Change struct {
+	// Type is the type of change
+	Type ChangeType
+
+	// Field is the json path of the changed field, empty for the changes of the kind
+	Field string
+
+	// From and To describe the old and the new schema of the field, e.g. its type
+	From string
+	To   string
+
+	// Breaking indicates that the change breaks the clients or the existing objects of the kind
+	Breaking bool
+}
+
+// String implements fmt.Stringer
+func (c Change) String() string {
+	s := c.Summary()
+	if c.Breaking {
+		s += " **(breaking)**"
+	}
+	return s
+}
+
+// Summary describes the change, whether it is breaking aside
+func (c Change) Summary() string {
+	var s string
+	switch c.Type {
+	case KindAdded:
+		s = "Added the kind"
+	case KindRemoved:
+		s = "Removed the kind"
+	case FieldAdded:
+		s = fmt.Sprintf("Added the field `%s` (`%s`)", c.Field, c.To)
+	case FieldRemoved:
+		s = fmt.Sprintf("Removed the field `%s`", c.Field)
+	case TypeChanged:
+		s = fmt.Sprintf("Changed the type of `%s` from `%s` to `%s`", c.Field, c.From, c.To)
+	case ValidationChanged:
+		s = fmt.Sprintf("Changed the validation of `%s` from %s to %s", c.Field, c.From, c.To)
+	case ScopeChanged:
+		s = fmt.Sprintf("Changed the scope from `%s` to `%s`", c.From, c.To)
+	}
+	return s
+}
+
+// KindChanges are the changes of the API of a kind
+type KindChanges struct {
+	// Group, Version and Kind identify the kind
+	Group   string
+	Version string
+	Kind    string
+
+	// Changes are the changes of the kind, sorted by field
+	Changes []Change
+}
+
+// Diff returns the changes of the kinds from the old API types to the new ones, sorted by group, version and kind
+func Diff(old, new map[string]*Kind) []KindChanges {
+	var result []KindChanges
+	add := func(k *Kind, changes []Change) {
+		if len(changes) > 0 {
+			result = append(result, KindChanges{Group: k.Group, Version: k.Version, Kind: k.Kind, Changes: changes})
+		}
+	}
+
+	for id, k := range new {
+		previous, found := old[id]
+		if !found {
+			add(k, []Change{{Type: KindAdded}})
+			continue
+		}
+		add(k, diffFields(previous.Fields, k.Fields))
+	}
+	for id, k := range old {
+		if _, found := new[id]; !found {
+			add(k, []Change{{Type: KindRemoved, Breaking: true}})
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Version != b.Version {
+			return a.Version < b.Version
+		}
+		return a.Kind < b.Kind
+	})
+	return result
+}
+
+// diffFields returns the changes of the fields of a kind, sorted by field
+func diffFields(old, new map[string]Field) []Change {
+	var changes []Change
+	for p, f := range new {
+		previous, found := old[p]
+		switch {
+		case !found:
+			if parent := parentPath(p); parent != "" {
+				if _, added := old[parent]; !added {
+					// the field of a struct which was added
+					continue
+				}
+			}
+			changes = append(changes, Change{Type: FieldAdded, Field: p, To: f.Type, Breaking: f.Required})
+		case previous.Type != f.Type:
+			changes = append(changes, Change{Type: TypeChanged, Field: p, From: previous.Type, To: f.Type, Breaking: true})
+		case previous.Required != f.Required || strings.Join(previous.Markers, " ") != strings.Join(f.Markers, " "):
+			changes = append(changes, Change{
+				Type:     ValidationChanged,
+				Field:    p,
+				From:     describeValidation(previous),
+				To:       describeValidation(f),
+				Breaking: !previous.Required && f.Required,
+			})
+		}
+	}
+	for p := range old {
+		if _, found := new[p]; found {
+			continue
+		}
+		if parent := parentPath(p); parent != "" {
+			if _, kept := new[parent]; !kept {
+				// the field of a struct which was removed
+				continue
+			}
+		}
+		changes = append(changes, Change{Type: FieldRemoved, Field: p, Breaking: true})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
+
+// parentPath returns the path of the field containing the field at p, empty for spec and status
+func parentPath(p string) string {
+	if i := strings.LastIndex(p, "."); i >= 0 {
+		return p[:i]
+	}
+	return ""
+}
+
+// describeValidation describes whether the field is required and its markers, e.g. required, `+kubebuilder:...`
+func describeValidation(f Field) string {
+	described := []string{"optional"}
+	if f.Required {
+		described[0] = "required"
+	}
+	for _, m := range f.Markers {
+		if m != optionalMarker && m != requiredMarker {
+			described = append(described, "`"+m+"`")
+		}
+	}
+	return strings.Join(described, ", ")
+}
+
+// Breaking returns true if any of the changes is breaking
+func Breaking(changes []KindChanges) bool {
+	for _, k := range changes {
+		for _, c := range k.Changes {
+			if c.Breaking {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Notes drafts the API change notes of the changelog entry, a section per kind
+func Notes(changes []KindChanges, since string) string {
+	var b strings.Builder
+	b.WriteString("### API changes\n\n")
+	if len(changes) == 0 {
+		fmt.Fprintf(&b, "No API changes since %s.\n", since)
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "Changes of the API types since %s.\n", since)
+	for _, k := range changes {
+		fmt.Fprintf(&b, "\n#### %s (%s/%s)\n\n", k.Kind, k.Group, k.Version)
+		for _, c := range k.Changes {
+			fmt.Fprintf(&b, "- %s\n", c)
+		}
+	}
+	if Breaking(changes) {
+		b.WriteString("\nThe breaking changes require the clients to be updated, and may require the existing " +
+			"objects to be migrated.\n")
+	}
+	return b.String()
+}
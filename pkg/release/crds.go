@@ -0,0 +1,299 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// CRD is the schema of a kind as generated in its CustomResourceDefinition
+type CRD struct {
+	// Name is the name of the CRD, e.g. captains.crew.example.org
+	Name string
+
+	// Group and Kind identify the kind
+	Group string
+	Kind  string
+
+	// Scope is either Namespaced or Cluster
+	Scope string
+
+	// Versions maps the name of every version of the kind to the fields of the spec and the status of its
+	// schema, indexed by json path, e.g. spec.engine.power. The fields only have a type and whether they are
+	// required, the type being the one of the schema, e.g. integer (int32) or []string.
+	Versions map[string]map[string]Field
+}
+
+// crdManifest is the part of a CustomResourceDefinition of apiextensions.k8s.io/v1beta1 or v1 describing the
+// schema of its versions
+type crdManifest struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Group string `json:"group"`
+		Names struct {
+			Kind string `json:"kind"`
+		} `json:"names"`
+		Scope      string           `json:"scope"`
+		Version    string           `json:"version"`
+		Validation *crdValidation   `json:"validation"`
+		Versions   []crdVersionSpec `json:"versions"`
+	} `json:"spec"`
+}
+
+type crdVersionSpec struct {
+	Name   string         `json:"name"`
+	Schema *crdValidation `json:"schema"`
+}
+
+type crdValidation struct {
+	OpenAPIV3Schema *crdSchema `json:"openAPIV3Schema"`
+}
+
+type crdSchema struct {
+	Type       string                `json:"type"`
+	Format     string                `json:"format"`
+	Properties map[string]*crdSchema `json:"properties"`
+	Items      *crdSchema            `json:"items"`
+	Required   []string              `json:"required"`
+}
+
+// ReadCRDs reads the YAML files under the directory of the CRDs of the working tree of the project in dir, e.g.
+// config/crd/bases, indexed by their slash separated path relative to dir
+func ReadCRDs(dir, crdDir string) (map[string][]byte, error) {
+	return readFiles(dir, isYAMLFile, crdDir)
+}
+
+// ReadCRDsAt reads the YAML files under the directory of the CRDs of the project in dir as of the git revision
+// ref, indexed by their slash separated path relative to dir
+func ReadCRDsAt(dir, ref, crdDir string) (map[string][]byte, error) {
+	return readFilesAt(dir, ref, isYAMLFile, filepath.ToSlash(crdDir))
+}
+
+// isYAMLFile filters out the files that aren't YAML files
+func isYAMLFile(p string) bool {
+	ext := path.Ext(filepath.ToSlash(p))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// ParseCRDs parses the CustomResourceDefinitions of the YAML files indexed by their slash separated path and
+// returns them indexed by name, the other objects of the files being ignored
+func ParseCRDs(files map[string][]byte) (map[string]*CRD, error) {
+	crds := map[string]*CRD{}
+	for p, content := range files {
+		for _, doc := range strings.Split("\n"+string(content), "\n---") {
+			if strings.TrimSpace(doc) == "" {
+				continue
+			}
+			var m crdManifest
+			if err := yaml.Unmarshal([]byte(doc), &m); err != nil {
+				return nil, fmt.Errorf("unable to parse %s: %v", p, err)
+			}
+			if m.Kind != "CustomResourceDefinition" {
+				continue
+			}
+
+			crd := &CRD{
+				Name:     m.Metadata.Name,
+				Group:    m.Spec.Group,
+				Kind:     m.Spec.Names.Kind,
+				Scope:    m.Spec.Scope,
+				Versions: map[string]map[string]Field{},
+			}
+			versions := m.Spec.Versions
+			if len(versions) == 0 {
+				versions = []crdVersionSpec{{Name: m.Spec.Version}}
+			}
+			for _, v := range versions {
+				// the versions of apiextensions.k8s.io/v1beta1 share the schema of the CRD unless they have theirs
+				validation := v.Schema
+				if validation == nil {
+					validation = m.Spec.Validation
+				}
+				fields := map[string]Field{}
+				if validation != nil && validation.OpenAPIV3Schema != nil {
+					root := validation.OpenAPIV3Schema
+					for _, name := range []string{"spec", "status"} {
+						if s := root.Properties[name]; s != nil {
+							collectSchemaFields(fields, name, s, contains(root.Required, name))
+						}
+					}
+				}
+				crd.Versions[v.Name] = fields
+			}
+			crds[crd.Name] = crd
+		}
+	}
+	return crds, nil
+}
+
+// collectSchemaFields records the field of the schema s at p and the fields of its properties, and of the ones of
+// its items for the arrays of objects
+func collectSchemaFields(fields map[string]Field, p string, s *crdSchema, required bool) {
+	fields[p] = Field{Type: schemaType(s), Required: required}
+	for s.Items != nil {
+		s = s.Items
+	}
+	for name, property := range s.Properties {
+		collectSchemaFields(fields, p+"."+name, property, contains(s.Required, name))
+	}
+}
+
+// schemaType describes the type of a schema, e.g. integer (int32), []string or object
+func schemaType(s *crdSchema) string {
+	if s.Items != nil {
+		return "[]" + schemaType(s.Items)
+	}
+	if s.Format != "" {
+		return fmt.Sprintf("%s (%s)", s.Type, s.Format)
+	}
+	return s.Type
+}
+
+// DiffCRDs returns the breaking changes of the CRDs from the old ones to the new ones, per version of their kind
+// and sorted by group, version and kind: the removed CRDs and versions, the changes of scope, and the fields
+// removed, whose type changed or which became required. The changes which don't break the clients or the
+// existing objects, e.g. the new CRDs and optional fields, are left out.
+func DiffCRDs(old, new map[string]*CRD) []KindChanges {
+	var result []KindChanges
+	for name, previous := range old {
+		crd, found := new[name]
+		for version, previousFields := range previous.Versions {
+			k := KindChanges{Group: previous.Group, Version: version, Kind: previous.Kind}
+			var fields map[string]Field
+			kept := found
+			if kept {
+				fields, kept = crd.Versions[version]
+			}
+			if !kept {
+				k.Changes = []Change{{Type: KindRemoved, Breaking: true}}
+				result = append(result, k)
+				continue
+			}
+			if previous.Scope != crd.Scope {
+				k.Changes = append(k.Changes, Change{Type: ScopeChanged, From: previous.Scope, To: crd.Scope,
+					Breaking: true})
+			}
+			for _, c := range diffFields(previousFields, fields) {
+				if c.Breaking {
+					k.Changes = append(k.Changes, c)
+				}
+			}
+			if len(k.Changes) > 0 {
+				result = append(result, k)
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Version != b.Version {
+			return a.Version < b.Version
+		}
+		return a.Kind < b.Kind
+	})
+	return result
+}
+
+// AllowedChange is a breaking change of a CRD made on purpose, listed in the allowlist of the project
+type AllowedChange struct {
+	// Group, Version and Kind identify the kind, any group and any version matching if they aren't set
+	Group   string `json:"group,omitempty"`
+	Version string `json:"version,omitempty"`
+	Kind    string `json:"kind"`
+
+	// Change is the type of the change, e.g. field-removed
+	Change ChangeType `json:"change"`
+
+	// Field is the json path of the changed field, e.g. spec.engine.power, any field matching if it isn't set
+	Field string `json:"field,omitempty"`
+
+	// Reason explains why the change is allowed, e.g. the kind isn't used yet
+	Reason string `json:"reason,omitempty"`
+}
+
+// Matches returns true if the change of the kind is the allowed change
+func (a AllowedChange) Matches(k KindChanges, c Change) bool {
+	return a.Kind == k.Kind && a.Change == c.Type &&
+		(a.Group == "" || a.Group == k.Group) &&
+		(a.Version == "" || a.Version == k.Version) &&
+		(a.Field == "" || a.Field == c.Field)
+}
+
+// ReadAllowlist reads the allowed breaking changes of the YAML file at path, a list of AllowedChange. No change
+// is allowed if the file doesn't exist.
+func ReadAllowlist(path string) ([]AllowedChange, error) {
+	content, err := ioutil.ReadFile(path) // nolint: gosec
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var allowed []AllowedChange
+	if err := yaml.Unmarshal(content, &allowed); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+	for i, a := range allowed {
+		if a.Kind == "" || a.Change == "" {
+			return nil, fmt.Errorf("invalid allowed change %d of %s: the kind and the change are required", i+1, path)
+		}
+	}
+	return allowed, nil
+}
+
+// Allowed splits the changes of the kinds into the ones matched by an allowed change and the others
+func Allowed(changes []KindChanges, allowlist []AllowedChange) (allowed, disallowed []KindChanges) {
+	for _, k := range changes {
+		a, d := k, k
+		a.Changes, d.Changes = nil, nil
+		for _, c := range k.Changes {
+			matched := false
+			for _, allowedChange := range allowlist {
+				if allowedChange.Matches(k, c) {
+					matched = true
+					break
+				}
+			}
+			if matched {
+				a.Changes = append(a.Changes, c)
+			} else {
+				d.Changes = append(d.Changes, c)
+			}
+		}
+		if len(a.Changes) > 0 {
+			allowed = append(allowed, a)
+		}
+		if len(d.Changes) > 0 {
+			disallowed = append(disallowed, d)
+		}
+	}
+	return allowed, disallowed
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "sigs.k8s.io/kubebuilder/pkg/release"
+)
+
+const oldCRD = `
+---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: captains.crew.example.org
+spec:
+  group: crew.example.org
+  names:
+    kind: Captain
+  scope: Namespaced
+  validation:
+    openAPIV3Schema:
+      properties:
+        apiVersion:
+          type: string
+        spec:
+          properties:
+            engine:
+              properties:
+                power:
+                  format: int32
+                  type: integer
+              required:
+              - power
+              type: object
+            rank:
+              type: string
+            ships:
+              format: int32
+              type: integer
+            tags:
+              items:
+                type: string
+              type: array
+          required:
+          - engine
+          - ships
+          type: object
+      type: object
+  versions:
+  - name: v1
+    served: true
+    storage: true
+  - name: v1beta1
+    served: true
+    storage: false
+`
+
+const newCRD = `
+---
+apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: captains.crew.example.org
+spec:
+  group: crew.example.org
+  names:
+    kind: Captain
+  scope: Cluster
+  validation:
+    openAPIV3Schema:
+      properties:
+        apiVersion:
+          type: string
+        spec:
+          properties:
+            rank:
+              type: string
+            ships:
+              type: string
+            tags:
+              items:
+                type: string
+              type: array
+            fleet:
+              type: string
+          required:
+          - fleet
+          - rank
+          - ships
+          type: object
+      type: object
+  versions:
+  - name: v1
+    served: true
+    storage: true
+`
+
+var _ = Describe("ParseCRDs", func() {
+	It("should record the fields of the spec and the status of every version", func() {
+		crds, err := ParseCRDs(map[string][]byte{"config/crd/bases/crew.example.org_captains.yaml": []byte(oldCRD)})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(crds).To(HaveKey("captains.crew.example.org"))
+
+		crd := crds["captains.crew.example.org"]
+		Expect(crd.Kind).To(Equal("Captain"))
+		Expect(crd.Scope).To(Equal("Namespaced"))
+		Expect(crd.Versions).To(HaveLen(2))
+		Expect(crd.Versions["v1beta1"]).To(Equal(crd.Versions["v1"]))
+		Expect(crd.Versions["v1"]).To(Equal(map[string]Field{
+			"spec":              {Type: "object"},
+			"spec.engine":       {Type: "object", Required: true},
+			"spec.engine.power": {Type: "integer (int32)", Required: true},
+			"spec.rank":         {Type: "string"},
+			"spec.ships":        {Type: "integer (int32)", Required: true},
+			"spec.tags":         {Type: "[]string"},
+		}))
+	})
+
+	It("should ignore the objects which aren't CRDs", func() {
+		crds, err := ParseCRDs(map[string][]byte{"config/crd/kustomization.yaml": []byte("resources:\n- bases\n")})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(crds).To(BeEmpty())
+	})
+})
+
+var _ = Describe("DiffCRDs", func() {
+	var changes []KindChanges
+
+	BeforeEach(func() {
+		old, err := ParseCRDs(map[string][]byte{"captains.yaml": []byte(oldCRD)})
+		Expect(err).NotTo(HaveOccurred())
+		new, err := ParseCRDs(map[string][]byte{"captains.yaml": []byte(newCRD)})
+		Expect(err).NotTo(HaveOccurred())
+		changes = DiffCRDs(old, new)
+	})
+
+	It("should list the breaking changes of every version", func() {
+		Expect(changes).To(Equal([]KindChanges{
+			{Group: "crew.example.org", Version: "v1", Kind: "Captain", Changes: []Change{
+				{Type: ScopeChanged, From: "Namespaced", To: "Cluster", Breaking: true},
+				{Type: FieldRemoved, Field: "spec.engine", Breaking: true},
+				{Type: FieldAdded, Field: "spec.fleet", To: "string", Breaking: true},
+				{Type: ValidationChanged, Field: "spec.rank", From: "optional", To: "required", Breaking: true},
+				{Type: TypeChanged, Field: "spec.ships", From: "integer (int32)", To: "string", Breaking: true},
+			}},
+			{Group: "crew.example.org", Version: "v1beta1", Kind: "Captain", Changes: []Change{
+				{Type: KindRemoved, Breaking: true},
+			}},
+		}))
+	})
+
+	It("should split the allowed changes from the others", func() {
+		allowed, disallowed := Allowed(changes, []AllowedChange{
+			{Kind: "Captain", Change: FieldRemoved, Field: "spec.engine"},
+			{Kind: "Captain", Version: "v1", Change: ScopeChanged},
+			{Kind: "Admiral", Change: TypeChanged},
+		})
+		Expect(allowed).To(Equal([]KindChanges{
+			{Group: "crew.example.org", Version: "v1", Kind: "Captain", Changes: []Change{
+				{Type: ScopeChanged, From: "Namespaced", To: "Cluster", Breaking: true},
+				{Type: FieldRemoved, Field: "spec.engine", Breaking: true},
+			}},
+		}))
+		Expect(disallowed).To(HaveLen(2))
+		Expect(disallowed[0].Changes).To(HaveLen(3))
+		Expect(disallowed[1].Changes).To(Equal([]Change{{Type: KindRemoved, Breaking: true}}))
+	})
+})
+
+var _ = Describe("ReadAllowlist", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "allowlist")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should allow no change without allowlist", func() {
+		allowed, err := ReadAllowlist(filepath.Join(dir, "allowed-breaking-changes.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(allowed).To(BeEmpty())
+	})
+
+	It("should read the allowed changes", func() {
+		path := filepath.Join(dir, "allowed-breaking-changes.yaml")
+		Expect(ioutil.WriteFile(path, []byte(`- kind: Captain
+  version: v1
+  change: field-removed
+  field: spec.engine
+  reason: replaced by the fleet
+`), 0644)).To(Succeed())
+
+		allowed, err := ReadAllowlist(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(allowed).To(Equal([]AllowedChange{{
+			Kind: "Captain", Version: "v1", Change: FieldRemoved, Field: "spec.engine", Reason: "replaced by the fleet",
+		}}))
+	})
+
+	It("should fail on the changes without kind", func() {
+		path := filepath.Join(dir, "allowed-breaking-changes.yaml")
+		Expect(ioutil.WriteFile(path, []byte("- change: field-removed\n"), 0644)).To(Succeed())
+
+		_, err := ReadAllowlist(path)
+		Expect(err).To(MatchError(ContainSubstring("the kind and the change are required")))
+	})
+})
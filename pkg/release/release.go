@@ -0,0 +1,366 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package release prepares the release of a project: it drafts the notes of the changes of its API types since
+// a previous revision, by comparing their syntax trees, and bumps the versions of its Helm chart and OLM
+// ClusterServiceVersion accordingly.
+package release
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kind is the schema of a kind as declared by the API types
+type Kind struct {
+	// Group, Version and Kind identify the kind
+	Group   string
+	Version string
+	Kind    string
+
+	// Fields maps the json path of every field of the spec and the status, e.g. spec.engine.power, to its schema
+	Fields map[string]Field
+}
+
+// Field is the schema of a field of a kind
+type Field struct {
+	// Type is the Go type of the field, e.g. *int32
+	Type string
+
+	// Markers are the sorted markers of the field and of its type, e.g. +kubebuilder:validation:Minimum=0
+	Markers []string
+
+	// Required indicates that the field is required, as the fields neither marked optional nor omitempty
+	Required bool
+}
+
+// ID returns the identifier of the kind, e.g. crew.example.org/v1, Kind=Captain
+func (k *Kind) ID() string {
+	return fmt.Sprintf("%s/%s, Kind=%s", k.Group, k.Version, k.Kind)
+}
+
+// ReadAPI reads the Go files of the API packages under the directories of the working tree of the project in
+// dir, indexed by their slash separated path relative to dir
+func ReadAPI(dir string, apiDirs ...string) (map[string][]byte, error) {
+	return readFiles(dir, isAPIFile, apiDirs...)
+}
+
+// ReadAPIAt reads the Go files of the API packages under the directories of the project in dir as of the git
+// revision ref, indexed by their slash separated path relative to dir
+func ReadAPIAt(dir, ref string, apiDirs ...string) (map[string][]byte, error) {
+	return readFilesAt(dir, ref, isAPIFile, apiDirs...)
+}
+
+// readFiles reads the files selected by filter under the directories of the working tree of the project in dir,
+// indexed by their slash separated path relative to dir
+func readFiles(dir string, filter func(p string) bool, dirs ...string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	for _, d := range dirs {
+		err := filepath.Walk(filepath.Join(dir, d), func(p string, info os.FileInfo, err error) error {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if info.IsDir() || !filter(p) {
+				return nil
+			}
+			rel, err := filepath.Rel(dir, p)
+			if err != nil {
+				return err
+			}
+			if files[filepath.ToSlash(rel)], err = ioutil.ReadFile(p); err != nil { // nolint: gosec
+				return err
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// readFilesAt reads the files selected by filter under the directories of the project in dir as of the git
+// revision ref, indexed by their slash separated path relative to dir
+func readFilesAt(dir, ref string, filter func(p string) bool, dirs ...string) (map[string][]byte, error) {
+	args := append([]string{"ls-tree", "-r", "--name-only", ref, "--"}, dirs...)
+	out, err := git(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	for _, p := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if p == "" || !filter(p) {
+			continue
+		}
+		// ls-tree lists the paths relative to the current directory, show expects them prefixed by ./
+		if files[p], err = git(dir, "show", ref+":./"+p); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// git runs git in dir and returns its output
+func git(dir string, args ...string) ([]byte, error) {
+	c := exec.Command("git", args...) // #nosec
+	c.Dir = dir
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// isAPIFile filters out the files that aren't Go files, test and generated files
+func isAPIFile(p string) bool {
+	name := path.Base(filepath.ToSlash(p))
+	return strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") &&
+		!strings.HasPrefix(name, "zz_generated")
+}
+
+// ParseAPI parses the API types of the Go files indexed by their slash separated path and returns the kinds
+// they declare indexed by ID, the kinds being the types marked with +kubebuilder:object:root=true but the lists
+func ParseAPI(files map[string][]byte) (map[string]*Kind, error) {
+	packages := map[string][]*ast.File{}
+	fset := token.NewFileSet()
+	for p, src := range files {
+		f, err := parser.ParseFile(fset, p, src, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %s: %v", p, err)
+		}
+		packages[path.Dir(p)] = append(packages[path.Dir(p)], f)
+	}
+
+	kinds := map[string]*Kind{}
+	for _, files := range packages {
+		pkg := newPackage(fset, files)
+		for _, name := range pkg.roots {
+			k := &Kind{Group: pkg.group, Version: pkg.version, Kind: name, Fields: map[string]Field{}}
+			pkg.collectFields(k.Fields, "", pkg.types[name], map[string]bool{name: true})
+			kinds[k.ID()] = k
+		}
+	}
+	return kinds, nil
+}
+
+// apiPackage contains the declarations of an API package
+type apiPackage struct {
+	fset    *token.FileSet
+	group   string
+	version string
+	// types are the type declarations indexed by name
+	types map[string]*ast.TypeSpec
+	// markers are the markers of the type declarations indexed by name
+	markers map[string][]string
+	// roots are the names of the kinds
+	roots []string
+}
+
+func newPackage(fset *token.FileSet, files []*ast.File) *apiPackage {
+	pkg := &apiPackage{fset: fset, types: map[string]*ast.TypeSpec{}, markers: map[string][]string{}}
+	for _, f := range files {
+		pkg.version = f.Name.Name
+		for _, marker := range markers(f.Doc) {
+			if strings.HasPrefix(marker, "+groupName=") {
+				pkg.group = strings.TrimPrefix(marker, "+groupName=")
+			}
+		}
+		for _, decl := range f.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				t := spec.(*ast.TypeSpec)
+				doc := t.Doc
+				if doc == nil {
+					doc = genDecl.Doc
+				}
+				typeMarkers := append(markers(doc), markers(pkg.commentBefore(f, doc, genDecl))...)
+				pkg.types[t.Name.Name] = t
+				pkg.markers[t.Name.Name] = typeMarkers
+				if contains(typeMarkers, "+kubebuilder:object:root=true") && !strings.HasSuffix(t.Name.Name, "List") {
+					pkg.roots = append(pkg.roots, t.Name.Name)
+				}
+			}
+		}
+	}
+	return pkg
+}
+
+// commentBefore returns the comment group separated by a blank line from the doc comment of decl, where the
+// markers of the types are usually written, e.g. +kubebuilder:object:root=true
+func (p *apiPackage) commentBefore(f *ast.File, doc *ast.CommentGroup, decl ast.Node) *ast.CommentGroup {
+	pos := decl.Pos()
+	if doc != nil {
+		pos = doc.Pos()
+	}
+	line := p.fset.Position(pos).Line
+	var before *ast.CommentGroup
+	for _, c := range f.Comments {
+		if c.End() >= pos {
+			break
+		}
+		if c != doc && p.fset.Position(c.End()).Line == line-2 {
+			before = c
+		}
+	}
+	return before
+}
+
+// collectFields records the fields of the struct declared by t under prefix, recursing into the structs of the
+// package they use. seen are the types being collected, to stop on recursive types.
+func (p *apiPackage) collectFields(fields map[string]Field, prefix string, t *ast.TypeSpec, seen map[string]bool) {
+	s, ok := t.Type.(*ast.StructType)
+	if !ok {
+		return
+	}
+	for _, field := range s.Fields.List {
+		name, inline, omitEmpty := jsonName(field)
+		if name == "-" {
+			continue
+		}
+		if len(field.Names) == 0 && prefix == "" {
+			// metav1.TypeMeta and metav1.ObjectMeta
+			continue
+		}
+		if prefix == "" && name != "spec" && name != "status" {
+			continue
+		}
+
+		fieldPath := prefix
+		if !inline {
+			fieldPath = strings.TrimPrefix(prefix+"."+name, ".")
+			typeName := p.text(field.Type)
+			fieldMarkers := markers(field.Doc)
+			if local := localType(field.Type); local != "" {
+				fieldMarkers = append(fieldMarkers, p.markers[local]...)
+			}
+			sort.Strings(fieldMarkers)
+			fields[fieldPath] = Field{Type: typeName, Markers: fieldMarkers, Required: isRequired(fieldMarkers, omitEmpty)}
+		}
+
+		if local := localType(field.Type); local != "" && !seen[local] && p.types[local] != nil {
+			seen[local] = true
+			p.collectFields(fields, fieldPath, p.types[local], seen)
+			delete(seen, local)
+		}
+	}
+}
+
+// text returns the source of node
+func (p *apiPackage) text(node ast.Node) string {
+	var b bytes.Buffer
+	_ = printer.Fprint(&b, p.fset, node)
+	return b.String()
+}
+
+// localType returns the name of the type expr refers to through pointers, slices and maps, if it isn't
+// qualified by a package
+func localType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return localType(t.X)
+	case *ast.ArrayType:
+		return localType(t.Elt)
+	case *ast.MapType:
+		return localType(t.Value)
+	}
+	return ""
+}
+
+// jsonName returns the json name of a field, whether it is inlined and whether it is omitted when empty
+func jsonName(field *ast.Field) (string, bool, bool) {
+	var tag reflect.StructTag
+	if field.Tag != nil {
+		value, _ := strconv.Unquote(field.Tag.Value)
+		tag = reflect.StructTag(value)
+	}
+	options := strings.Split(tag.Get("json"), ",")
+	omitEmpty := false
+	for _, option := range options[1:] {
+		switch option {
+		case "inline":
+			return "", true, false
+		case "omitempty":
+			omitEmpty = true
+		}
+	}
+	if options[0] != "" {
+		return options[0], false, omitEmpty
+	}
+	if len(field.Names) == 0 {
+		return "", true, false
+	}
+	return field.Names[0].Name, false, omitEmpty
+}
+
+// isRequired returns true if a field is required: marked so, or neither marked optional nor omitted when empty
+// as controller-gen does
+func isRequired(fieldMarkers []string, omitEmpty bool) bool {
+	switch {
+	case contains(fieldMarkers, requiredMarker):
+		return true
+	case contains(fieldMarkers, optionalMarker):
+		return false
+	}
+	return !omitEmpty
+}
+
+// markers returns the markers of a doc comment
+func markers(doc *ast.CommentGroup) []string {
+	if doc == nil {
+		return nil
+	}
+	var result []string
+	for _, c := range doc.List {
+		line := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+		if strings.HasPrefix(line, "+") {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+func contains(markers []string, marker string) bool {
+	for _, m := range markers {
+		if m == marker {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,288 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "sigs.k8s.io/kubebuilder/pkg/release"
+)
+
+const groupVersionInfo = `// Package v1 contains API Schema definitions for the crew v1 API group
+// +kubebuilder:object:generate=true
+// +groupName=crew.example.org
+package v1
+`
+
+const oldTypes = `package v1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// CaptainSpec defines the desired state of Captain
+type CaptainSpec struct {
+	// +kubebuilder:validation:Minimum=0
+	Ships int32 ` + "`json:\"ships\"`" + `
+
+	// +optional
+	Rank string ` + "`json:\"rank,omitempty\"`" + `
+
+	Engine Engine ` + "`json:\"engine\"`" + `
+
+	Retired bool ` + "`json:\"retired\"`" + `
+}
+
+type Engine struct {
+	Power int32 ` + "`json:\"power\"`" + `
+}
+
+// CaptainStatus defines the observed state of Captain
+type CaptainStatus struct{}
+
+// +kubebuilder:object:root=true
+
+// Captain is the Schema for the captains API
+type Captain struct {
+	metav1.TypeMeta   ` + "`json:\",inline\"`" + `
+	metav1.ObjectMeta ` + "`json:\"metadata,omitempty\"`" + `
+
+	Spec   CaptainSpec   ` + "`json:\"spec,omitempty\"`" + `
+	Status CaptainStatus ` + "`json:\"status,omitempty\"`" + `
+}
+
+// +kubebuilder:object:root=true
+
+// CaptainList contains a list of Captain
+type CaptainList struct {
+	metav1.TypeMeta ` + "`json:\",inline\"`" + `
+	metav1.ListMeta ` + "`json:\"metadata,omitempty\"`" + `
+	Items           []Captain ` + "`json:\"items\"`" + `
+}
+`
+
+const newTypes = `package v1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// CaptainSpec defines the desired state of Captain
+type CaptainSpec struct {
+	// +kubebuilder:validation:Minimum=1
+	Ships int32 ` + "`json:\"ships\"`" + `
+
+	// +optional
+	Rank *string ` + "`json:\"rank,omitempty\"`" + `
+
+	Engine Engine ` + "`json:\"engine\"`" + `
+
+	// +optional
+	Motto string ` + "`json:\"motto,omitempty\"`" + `
+}
+
+type Engine struct {
+	Power int32 ` + "`json:\"power\"`" + `
+	// +optional
+	Fuel string ` + "`json:\"fuel,omitempty\"`" + `
+}
+
+// CaptainStatus defines the observed state of Captain
+type CaptainStatus struct{}
+
+// +kubebuilder:object:root=true
+
+// Captain is the Schema for the captains API
+type Captain struct {
+	metav1.TypeMeta   ` + "`json:\",inline\"`" + `
+	metav1.ObjectMeta ` + "`json:\"metadata,omitempty\"`" + `
+
+	Spec   CaptainSpec   ` + "`json:\"spec,omitempty\"`" + `
+	Status CaptainStatus ` + "`json:\"status,omitempty\"`" + `
+}
+
+// +kubebuilder:object:root=true
+
+// CaptainList contains a list of Captain
+type CaptainList struct {
+	metav1.TypeMeta ` + "`json:\",inline\"`" + `
+	metav1.ListMeta ` + "`json:\"metadata,omitempty\"`" + `
+	Items           []Captain ` + "`json:\"items\"`" + `
+}
+
+// +kubebuilder:object:root=true
+
+// FirstMate is the Schema for the firstmates API
+type FirstMate struct {
+	metav1.TypeMeta   ` + "`json:\",inline\"`" + `
+	metav1.ObjectMeta ` + "`json:\"metadata,omitempty\"`" + `
+}
+`
+
+var _ = Describe("ParseAPI", func() {
+	It("should record the fields of the spec and the status of the kinds", func() {
+		kinds, err := ParseAPI(map[string][]byte{
+			"api/v1/groupversion_info.go": []byte(groupVersionInfo),
+			"api/v1/captain_types.go":     []byte(oldTypes),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kinds).To(HaveLen(1))
+
+		captain := kinds["crew.example.org/v1, Kind=Captain"]
+		Expect(captain).NotTo(BeNil())
+		Expect(captain.Fields).To(HaveKey("spec"))
+		Expect(captain.Fields).To(HaveKey("status"))
+		Expect(captain.Fields).To(HaveKeyWithValue("spec.ships",
+			Field{Type: "int32", Markers: []string{"+kubebuilder:validation:Minimum=0"}, Required: true}))
+		Expect(captain.Fields).To(HaveKeyWithValue("spec.engine.power", Field{Type: "int32", Required: true}))
+		Expect(captain.Fields["spec.rank"].Required).To(BeFalse())
+		Expect(captain.Fields).NotTo(HaveKey("metadata"))
+	})
+})
+
+var _ = Describe("Diff", func() {
+	var changes []KindChanges
+
+	BeforeEach(func() {
+		old, err := ParseAPI(map[string][]byte{
+			"api/v1/groupversion_info.go": []byte(groupVersionInfo),
+			"api/v1/captain_types.go":     []byte(oldTypes),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		new, err := ParseAPI(map[string][]byte{
+			"api/v1/groupversion_info.go": []byte(groupVersionInfo),
+			"api/v1/captain_types.go":     []byte(newTypes),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		changes = Diff(old, new)
+	})
+
+	It("should list the changes of every kind", func() {
+		Expect(changes).To(HaveLen(2))
+		Expect(changes[0].Kind).To(Equal("Captain"))
+		Expect(changes[0].Changes).To(Equal([]Change{
+			{Type: FieldAdded, Field: "spec.engine.fuel", To: "string"},
+			{Type: FieldAdded, Field: "spec.motto", To: "string"},
+			{Type: TypeChanged, Field: "spec.rank", From: "string", To: "*string", Breaking: true},
+			{Type: FieldRemoved, Field: "spec.retired", Breaking: true},
+			{Type: ValidationChanged, Field: "spec.ships",
+				From: "required, `+kubebuilder:validation:Minimum=0`", To: "required, `+kubebuilder:validation:Minimum=1`"},
+		}))
+		Expect(changes[1].Kind).To(Equal("FirstMate"))
+		Expect(changes[1].Changes).To(Equal([]Change{{Type: KindAdded}}))
+	})
+
+	It("should flag the new required fields as breaking", func() {
+		id := "crew.example.org/v1, Kind=Captain"
+		old := map[string]*Kind{id: {Group: "crew.example.org", Version: "v1", Kind: "Captain", Fields: map[string]Field{
+			"spec": {Type: "CaptainSpec"}, "spec.rank": {Type: "string", Markers: []string{"+optional"}},
+		}}}
+		new := map[string]*Kind{id: {Group: "crew.example.org", Version: "v1", Kind: "Captain", Fields: map[string]Field{
+			"spec":       {Type: "CaptainSpec"},
+			"spec.rank":  {Type: "string", Required: true},
+			"spec.ships": {Type: "int32", Required: true},
+		}}}
+		Expect(Diff(old, new)[0].Changes).To(Equal([]Change{
+			{Type: ValidationChanged, Field: "spec.rank", From: "optional", To: "required", Breaking: true},
+			{Type: FieldAdded, Field: "spec.ships", To: "int32", Breaking: true},
+		}))
+	})
+
+	It("should require a major release for breaking changes", func() {
+		Expect(Breaking(changes)).To(BeTrue())
+		Expect(BumpFor(changes)).To(Equal(Major))
+		Expect(BumpFor(changes[1:])).To(Equal(Minor))
+		Expect(BumpFor(nil)).To(Equal(Patch))
+	})
+
+	It("should draft the notes per kind", func() {
+		notes := Notes(changes, "v0.1.0")
+		Expect(notes).To(HavePrefix("### API changes\n\nChanges of the API types since v0.1.0.\n"))
+		Expect(notes).To(ContainSubstring("#### Captain (crew.example.org/v1)\n\n" +
+			"- Added the field `spec.engine.fuel` (`string`)\n"))
+		Expect(notes).To(ContainSubstring("- Removed the field `spec.retired` **(breaking)**\n"))
+		Expect(notes).To(ContainSubstring("#### FirstMate (crew.example.org/v1)\n\n- Added the kind\n"))
+		Expect(Notes(nil, "v0.1.0")).To(Equal("### API changes\n\nNo API changes since v0.1.0.\n"))
+	})
+})
+
+var _ = Describe("BumpVersion", func() {
+	It("should bump the semantic versions", func() {
+		for _, c := range []struct {
+			version  string
+			bump     Bump
+			expected string
+		}{
+			{"1.2.3", Major, "2.0.0"},
+			{"v1.2.3", Minor, "v1.3.0"},
+			{"1.2.3", Patch, "1.2.4"},
+			{"0.1.0", Major, "0.2.0"},
+			{"v0.1.0-rc.1", Patch, "v0.1.1"},
+		} {
+			Expect(BumpVersion(c.version, c.bump)).To(Equal(c.expected))
+		}
+		_, err := BumpVersion("latest", Patch)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("BumpChart", func() {
+	It("should bump the version of the chart and its semantic appVersion", func() {
+		chart, change, err := BumpChart("apiVersion: v2\nname: project\nversion: 0.1.0\nappVersion: \"v0.1.0\"\n", Minor)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(chart).To(Equal("apiVersion: v2\nname: project\nversion: 0.2.0\nappVersion: \"v0.2.0\"\n"))
+		Expect(change).To(Equal(VersionChange{From: "0.1.0", To: "0.2.0"}))
+
+		chart, _, err = BumpChart("name: project\nversion: 1.0.0\nappVersion: latest\n", Patch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(chart).To(Equal("name: project\nversion: 1.0.1\nappVersion: latest\n"))
+
+		_, _, err = BumpChart("name: project\n", Patch)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("BumpCSV", func() {
+	It("should bump the version of the ClusterServiceVersion and replace the previous one", func() {
+		csv, change, err := BumpCSV(`apiVersion: operators.coreos.com/v1alpha1
+kind: ClusterServiceVersion
+metadata:
+  name: project.v0.1.0
+  namespace: placeholder
+spec:
+  displayName: Project
+  replaces: project.v0.0.1
+  version: 0.1.0
+  install:
+    spec:
+      deployments:
+      - name: project-controller-manager
+`, Patch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(change).To(Equal(VersionChange{From: "0.1.0", To: "0.1.1"}))
+		Expect(csv).To(ContainSubstring("  name: project.v0.1.1\n"))
+		Expect(csv).To(ContainSubstring("  replaces: project.v0.1.0\n"))
+		Expect(csv).To(ContainSubstring("  version: 0.1.1\n"))
+		Expect(csv).To(ContainSubstring("      - name: project-controller-manager\n"))
+	})
+})
+
+var _ = Describe("AddChangelogEntry", func() {
+	It("should add the entry before the previous releases", func() {
+		Expect(AddChangelogEntry("", "## 0.2.0\n")).To(Equal("# Changelog\n\n## 0.2.0\n"))
+		Expect(AddChangelogEntry("# Changelog\n\n## 0.1.0\n\nFirst release.\n", "## 0.2.0\n")).To(Equal(
+			"# Changelog\n\n## 0.2.0\n\n## 0.1.0\n\nFirst release.\n"))
+		Expect(AddChangelogEntry("# Changelog\n", "## 0.2.0\n")).To(Equal("# Changelog\n\n## 0.2.0\n"))
+	})
+})
@@ -0,0 +1,253 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package release
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Bump is the part of a semantic version incremented by a release
+type Bump string
+
+const (
+	// Major releases break the API, they bump the minor version of 0.x versions
+	Major Bump = "major"
+	// Minor releases add to the API
+	Minor Bump = "minor"
+	// Patch releases don't change the API
+	Patch Bump = "patch"
+)
+
+// BumpFor returns the bump of a release with the changes
+func BumpFor(changes []KindChanges) Bump {
+	if Breaking(changes) {
+		return Major
+	}
+	for _, k := range changes {
+		for _, c := range k.Changes {
+			if c.Type == KindAdded || c.Type == FieldAdded {
+				return Minor
+			}
+		}
+	}
+	return Patch
+}
+
+var versionRe = regexp.MustCompile(`^(v?)(\d+)\.(\d+)\.(\d+)(?:[-+].*)?$`)
+
+// BumpVersion returns the semantic version bumped, keeping its v prefix and dropping its pre-release, e.g.
+// v0.1.0-rc.1 bumped for a major release is v0.2.0
+func BumpVersion(version string, bump Bump) (string, error) {
+	m := versionRe.FindStringSubmatch(version)
+	if m == nil {
+		return "", fmt.Errorf("invalid semantic version %q", version)
+	}
+	major, _ := strconv.Atoi(m[2])
+	minor, _ := strconv.Atoi(m[3])
+	patch, _ := strconv.Atoi(m[4])
+	switch {
+	case bump == Major && major > 0:
+		major, minor, patch = major+1, 0, 0
+	case bump == Major || bump == Minor:
+		minor, patch = minor+1, 0
+	default:
+		patch++
+	}
+	return fmt.Sprintf("%s%d.%d.%d", m[1], major, minor, patch), nil
+}
+
+// VersionFileType is the type of a file holding the version of the project
+type VersionFileType string
+
+const (
+	// Chart is the Chart.yaml of a Helm chart
+	Chart VersionFileType = "chart"
+	// CSV is the ClusterServiceVersion of an OLM bundle
+	CSV VersionFileType = "csv"
+)
+
+// FindVersionFiles returns the Helm charts and the OLM ClusterServiceVersions under dir indexed by path,
+// skipping the directories named skip relative to dir
+func FindVersionFiles(dir string, skip ...string) (map[string]VersionFileType, error) {
+	if dir == "" {
+		dir = "."
+	}
+	skipped := map[string]bool{}
+	for _, s := range skip {
+		skipped[filepath.Join(dir, s)] = true
+	}
+
+	files := map[string]VersionFileType{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			switch {
+			case skipped[path], path != dir && strings.HasPrefix(info.Name(), "."):
+				return filepath.SkipDir
+			case info.Name() == "vendor", info.Name() == "bin", info.Name() == "testdata":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		switch {
+		case info.Name() == "Chart.yaml":
+			files[path] = Chart
+		case strings.HasSuffix(info.Name(), ".clusterserviceversion.yaml"):
+			files[path] = CSV
+		}
+		return nil
+	})
+	return files, err
+}
+
+// VersionChange is the change of the version of a file
+type VersionChange struct {
+	From string
+	To   string
+}
+
+// BumpChart bumps the version of the Helm chart whose Chart.yaml is content, and its appVersion if it is a
+// semantic version, and returns the new content along with the change of the version of the chart
+func BumpChart(content string, bump Bump) (string, VersionChange, error) {
+	var change VersionChange
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		key, value, ok := topLevelField(line)
+		if !ok || (key != "version" && key != "appVersion") {
+			continue
+		}
+		bumped, err := BumpVersion(unquote(value), bump)
+		if err != nil {
+			if key == "appVersion" {
+				continue
+			}
+			return "", change, fmt.Errorf("chart version: %v", err)
+		}
+		lines[i] = key + ": " + requote(value, bumped)
+		if key == "version" {
+			change = VersionChange{From: unquote(value), To: bumped}
+		}
+	}
+	if change.From == "" {
+		return "", change, fmt.Errorf("chart has no version")
+	}
+	return strings.Join(lines, "\n"), change, nil
+}
+
+// BumpCSV bumps the spec.version of the ClusterServiceVersion whose content is provided, along with the
+// version in its name, and sets spec.replaces, if present, to the previous name
+func BumpCSV(content string, bump Bump) (string, VersionChange, error) {
+	var change VersionChange
+	lines := strings.Split(content, "\n")
+
+	var section, childIndent string
+	nameLine, replacesLine := -1, -1
+	var name string
+	for i, line := range lines {
+		if key, _, ok := topLevelField(line); ok {
+			section, childIndent = key, ""
+			continue
+		}
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := line[:len(line)-len(trimmed)]
+		if childIndent == "" {
+			childIndent = indent
+		}
+		if indent != childIndent {
+			continue
+		}
+		key, value, ok := topLevelField(trimmed)
+		if !ok {
+			continue
+		}
+		switch {
+		case section == "metadata" && key == "name":
+			nameLine, name = i, unquote(value)
+		case section == "spec" && key == "replaces":
+			replacesLine = i
+		case section == "spec" && key == "version":
+			bumped, err := BumpVersion(unquote(value), bump)
+			if err != nil {
+				return "", change, fmt.Errorf("ClusterServiceVersion version: %v", err)
+			}
+			lines[i] = indent + key + ": " + requote(value, bumped)
+			change = VersionChange{From: unquote(value), To: bumped}
+		}
+	}
+	if change.From == "" {
+		return "", change, fmt.Errorf("ClusterServiceVersion has no spec.version")
+	}
+
+	suffix := ".v" + strings.TrimPrefix(change.From, "v")
+	if nameLine >= 0 && strings.HasSuffix(name, suffix) {
+		indent := lines[nameLine][:len(lines[nameLine])-len(strings.TrimLeft(lines[nameLine], " "))]
+		lines[nameLine] = indent + "name: " + strings.TrimSuffix(name, suffix) + ".v" + strings.TrimPrefix(change.To, "v")
+		if replacesLine >= 0 {
+			indent := lines[replacesLine][:len(lines[replacesLine])-len(strings.TrimLeft(lines[replacesLine], " "))]
+			lines[replacesLine] = indent + "replaces: " + name
+		}
+	}
+	return strings.Join(lines, "\n"), change, nil
+}
+
+// topLevelField returns the key and the value of a line of the form key: value without indentation
+func topLevelField(line string) (string, string, bool) {
+	if line == "" || line[0] == ' ' || line[0] == '#' || line[0] == '-' {
+		return "", "", false
+	}
+	parts := strings.SplitN(line, ":", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], strings.TrimSpace(parts[1]), true
+}
+
+func unquote(value string) string {
+	return strings.Trim(value, `"'`)
+}
+
+// requote returns value quoted as previous
+func requote(previous, value string) string {
+	if len(previous) > 0 && (previous[0] == '"' || previous[0] == '\'') {
+		return string(previous[0]) + value + string(previous[0])
+	}
+	return value
+}
+
+// AddChangelogEntry returns the changelog with the entry added before its first release, after its title if any
+func AddChangelogEntry(changelog, entry string) string {
+	if changelog == "" {
+		return "# Changelog\n\n" + entry
+	}
+	lines := strings.SplitAfter(changelog, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "## ") {
+			return strings.Join(lines[:i], "") + entry + "\n" + strings.Join(lines[i:], "")
+		}
+	}
+	return strings.TrimRight(changelog, "\n") + "\n\n" + entry
+}
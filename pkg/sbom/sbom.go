@@ -0,0 +1,243 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package sbom generates the software bill of materials of a project as an SPDX JSON document listing the Go
+// modules it depends on, in the format of the SBOMs generated by syft.
+package sbom
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultFile is the file of the project the SBOM is written to
+	DefaultFile = "sbom.spdx.json"
+
+	spdxVersion = "SPDX-2.2"
+	noAssertion = "NOASSERTION"
+)
+
+// Module is a Go module as listed by go list -m -json
+type Module struct {
+	Path     string  `json:"Path"`
+	Version  string  `json:"Version,omitempty"`
+	Main     bool    `json:"Main,omitempty"`
+	Indirect bool    `json:"Indirect,omitempty"`
+	Replace  *Module `json:"Replace,omitempty"`
+}
+
+// ListModules returns the main module of the project in dir and the modules it depends on
+func ListModules(dir string) ([]Module, error) {
+	c := exec.Command("go", "list", "-m", "-json", "all") // #nosec
+	c.Dir = dir
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the modules: %v: %s", err, stderr.String())
+	}
+	return decodeModules(bytes.NewReader(out))
+}
+
+// decodeModules decodes the stream of JSON modules written by go list -m -json
+func decodeModules(r io.Reader) ([]Module, error) {
+	var modules []Module
+	for d := json.NewDecoder(r); ; {
+		var m Module
+		err := d.Decode(&m)
+		if err == io.EOF {
+			return modules, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode the modules: %v", err)
+		}
+		modules = append(modules, m)
+	}
+}
+
+// Document is an SPDX document
+type Document struct {
+	SPDXVersion       string         `json:"spdxVersion"`
+	DataLicense       string         `json:"dataLicense"`
+	SPDXID            string         `json:"SPDXID"`
+	Name              string         `json:"name"`
+	DocumentNamespace string         `json:"documentNamespace"`
+	CreationInfo      CreationInfo   `json:"creationInfo"`
+	Packages          []Package      `json:"packages"`
+	Relationships     []Relationship `json:"relationships"`
+}
+
+// CreationInfo records who created an SPDX document and when
+type CreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+// Package is a package of an SPDX document, a Go module
+type Package struct {
+	SPDXID           string        `json:"SPDXID"`
+	Name             string        `json:"name"`
+	VersionInfo      string        `json:"versionInfo,omitempty"`
+	DownloadLocation string        `json:"downloadLocation"`
+	FilesAnalyzed    bool          `json:"filesAnalyzed"`
+	LicenseConcluded string        `json:"licenseConcluded"`
+	LicenseDeclared  string        `json:"licenseDeclared"`
+	CopyrightText    string        `json:"copyrightText"`
+	ExternalRefs     []ExternalRef `json:"externalRefs,omitempty"`
+}
+
+// ExternalRef identifies a package outside of the document, e.g. by its package URL
+type ExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+// Relationship relates two elements of an SPDX document
+type Relationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// Generate returns the SPDX document of the main module among modules, depending on the other ones. The document
+// only changes with the modules, but for its creation time.
+func Generate(modules []Module, created time.Time) (*Document, error) {
+	var main *Module
+	var deps []Module
+	for i := range modules {
+		switch m := modules[i]; {
+		case m.Main && main == nil:
+			main = &modules[i]
+		case m.Main:
+			return nil, fmt.Errorf("more than one main module: %s and %s", main.Path, m.Path)
+		default:
+			// replaced modules are recorded as the modules they are replaced by
+			if m.Replace != nil {
+				m = Module{Path: m.Replace.Path, Version: m.Replace.Version, Indirect: m.Indirect}
+			}
+			deps = append(deps, m)
+		}
+	}
+	if main == nil {
+		return nil, fmt.Errorf("no main module")
+	}
+	sort.Slice(deps, func(i, j int) bool {
+		if deps[i].Path != deps[j].Path {
+			return deps[i].Path < deps[j].Path
+		}
+		return deps[i].Version < deps[j].Version
+	})
+
+	doc := &Document{
+		SPDXVersion: spdxVersion,
+		DataLicense: "CC0-1.0",
+		SPDXID:      "SPDXRef-DOCUMENT",
+		Name:        main.Path,
+		CreationInfo: CreationInfo{
+			Created:  created.UTC().Format(time.RFC3339),
+			Creators: []string{"Tool: kubebuilder"},
+		},
+	}
+
+	mainID := "SPDXRef-Package-" + spdxID(main.Path)
+	doc.Packages = append(doc.Packages, newPackage(mainID, *main))
+	doc.Relationships = append(doc.Relationships,
+		Relationship{SPDXElementID: doc.SPDXID, RelationshipType: "DESCRIBES", RelatedSPDXElement: mainID})
+
+	sum := sha256.New()
+	for _, m := range deps {
+		id := "SPDXRef-Package-" + spdxID(m.Path+"-"+m.Version)
+		doc.Packages = append(doc.Packages, newPackage(id, m))
+		doc.Relationships = append(doc.Relationships,
+			Relationship{SPDXElementID: mainID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: id})
+		fmt.Fprintf(sum, "%s@%s\n", m.Path, m.Version)
+	}
+	// the namespace of the document identifies it among the SBOMs of every version of the project
+	doc.DocumentNamespace = fmt.Sprintf("https://%s/spdx/%x", main.Path, sum.Sum(nil)[:8])
+
+	return doc, nil
+}
+
+func newPackage(id string, m Module) Package {
+	p := Package{
+		SPDXID:           id,
+		Name:             m.Path,
+		VersionInfo:      m.Version,
+		DownloadLocation: noAssertion,
+		LicenseConcluded: noAssertion,
+		LicenseDeclared:  noAssertion,
+		CopyrightText:    noAssertion,
+	}
+	if m.Version != "" {
+		p.DownloadLocation = fmt.Sprintf("https://proxy.golang.org/%s/@v/%s.zip", escapePath(m.Path), m.Version)
+	}
+	p.ExternalRefs = []ExternalRef{{
+		ReferenceCategory: "PACKAGE_MANAGER",
+		ReferenceType:     "purl",
+		ReferenceLocator:  purl(m),
+	}}
+	return p
+}
+
+// purl returns the package URL of a module
+func purl(m Module) string {
+	if m.Version == "" {
+		return "pkg:golang/" + m.Path
+	}
+	return fmt.Sprintf("pkg:golang/%s@%s", m.Path, m.Version)
+}
+
+// escapePath escapes the upper case letters of a module path as the module proxy protocol requires
+func escapePath(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if 'A' <= r && r <= 'Z' {
+			b.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// spdxID returns s with the characters SPDX identifiers don't allow replaced by dashes
+func spdxID(s string) string {
+	return strings.Map(func(r rune) rune {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '-'
+	}, s)
+}
+
+// Write writes the document as indented JSON
+func (d *Document) Write(w io.Writer) error {
+	out, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(out, '\n'))
+	return err
+}
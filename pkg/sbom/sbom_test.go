@@ -0,0 +1,112 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sbom_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	. "sigs.k8s.io/kubebuilder/pkg/sbom"
+)
+
+var _ = Describe("Generate", func() {
+	created := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	modules := []Module{
+		{Path: "example.org/project", Main: true},
+		{Path: "sigs.k8s.io/controller-runtime", Version: "v0.4.0"},
+		{Path: "github.com/Azure/go-autorest", Version: "v11.1.2+incompatible", Indirect: true},
+		{Path: "k8s.io/api", Version: "v0.0.0-20190918155943-95b840bb6a1f", Indirect: true,
+			Replace: &Module{Path: "example.org/fork/api", Version: "v0.1.0"}},
+	}
+
+	It("should describe the main module depending on the other ones", func() {
+		doc, err := Generate(modules, created)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(doc.SPDXVersion).To(Equal("SPDX-2.2"))
+		Expect(doc.Name).To(Equal("example.org/project"))
+		Expect(doc.CreationInfo.Created).To(Equal("2020-03-01T12:00:00Z"))
+
+		var names, purls []string
+		for _, p := range doc.Packages {
+			names = append(names, p.Name)
+			purls = append(purls, p.ExternalRefs[0].ReferenceLocator)
+		}
+		Expect(names).To(Equal([]string{"example.org/project", "example.org/fork/api",
+			"github.com/Azure/go-autorest", "sigs.k8s.io/controller-runtime"}))
+		Expect(purls).To(ContainElement("pkg:golang/sigs.k8s.io/controller-runtime@v0.4.0"))
+		Expect(doc.Packages[2].DownloadLocation).To(Equal(
+			"https://proxy.golang.org/github.com/!azure/go-autorest/@v/v11.1.2+incompatible.zip"))
+
+		Expect(doc.Relationships).To(HaveLen(4))
+		Expect(doc.Relationships[0].RelationshipType).To(Equal("DESCRIBES"))
+		for _, r := range doc.Relationships[1:] {
+			Expect(r.SPDXElementID).To(Equal(doc.Packages[0].SPDXID))
+			Expect(r.RelationshipType).To(Equal("DEPENDS_ON"))
+		}
+	})
+
+	It("should only change with the modules", func() {
+		doc, err := Generate(modules, created)
+		Expect(err).NotTo(HaveOccurred())
+		again, err := Generate(append([]Module{modules[0]}, modules[3], modules[2], modules[1]), created)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(Equal(doc))
+
+		upgraded, err := Generate(append(modules[:3:3], Module{Path: "k8s.io/api", Version: "v0.17.0"}), created)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(upgraded.DocumentNamespace).NotTo(Equal(doc.DocumentNamespace))
+	})
+
+	It("should fail without main module", func() {
+		_, err := Generate(modules[1:], created)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should write the document as JSON", func() {
+		doc, err := Generate(modules, created)
+		Expect(err).NotTo(HaveOccurred())
+
+		var out bytes.Buffer
+		Expect(doc.Write(&out)).To(Succeed())
+		decoded := map[string]interface{}{}
+		Expect(json.Unmarshal(out.Bytes(), &decoded)).To(Succeed())
+		Expect(decoded).To(HaveKeyWithValue("SPDXID", "SPDXRef-DOCUMENT"))
+		Expect(decoded).To(HaveKey("packages"))
+	})
+})
+
+var _ = Describe("ListModules", func() {
+	It("should list the main module of the project", func() {
+		dir, err := ioutil.TempDir("", "sbom")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+		Expect(ioutil.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.org/project\n\ngo 1.13\n"),
+			0644)).To(Succeed())
+
+		modules, err := ListModules(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(modules).To(Equal([]Module{{Path: "example.org/project", Main: true}}))
+	})
+})
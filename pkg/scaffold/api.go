@@ -18,20 +18,48 @@ package scaffold
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/logging"
 	"sigs.k8s.io/kubebuilder/pkg/model"
+	modelconfig "sigs.k8s.io/kubebuilder/pkg/model/config"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/v1/controller"
 	crdv1 "sigs.k8s.io/kubebuilder/pkg/scaffold/v1/crd"
 	scaffoldv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/backup"
 	controllerv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2/controller"
 	crdv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2/crd"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/e2e"
+	managerv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2/manager"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/observability"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/prometheus"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/webhook"
 )
 
+// The categories of the files of an API whose existing files are overwritten by Force
+const (
+	// ForceTypes is the category of the files of the API package, the types, their webhooks and their tests
+	ForceTypes = "types"
+
+	// ForceController is the category of the files of the controllers package
+	ForceController = "controller"
+
+	// ForceManifests is the category of the manifests under config, e.g. the samples and the CRD patches
+	ForceManifests = "manifests"
+)
+
+// ForceCategories are the categories of the files of an API whose existing files are overwritten by Force
+var ForceCategories = []string{ForceTypes, ForceController, ForceManifests}
+
 // API contains configuration for generating scaffolding for Go type
 // representing the API and controller that implements the behavior for the API.
 type API struct {
@@ -41,6 +69,8 @@ type API struct {
 	Resource *resource.Resource
 
 	config *config.Config
+	// query is the view of the configuration in terms of the capabilities of the project version
+	query modelconfig.Query
 
 	// DoResource indicates whether to scaffold API Resource or not
 	DoResource bool
@@ -48,8 +78,161 @@ type API struct {
 	// DoController indicates whether to scaffold controller files or not
 	DoController bool
 
+	// UnitTests indicates whether to scaffold fake client based unit tests for the controller
+	UnitTests bool
+
+	// HealthCheck indicates whether to scaffold a health check of the controller based on its last
+	// successful reconcile, registered as a readiness check of the manager
+	HealthCheck bool
+
+	// WatchConfig indicates whether to scaffold the watcher of the ConfigMap holding the configuration of the
+	// operator, the controller is reconciling every object again when it changes
+	WatchConfig bool
+
+	// MessageBus indicates whether to scaffold the consumer of a message bus whose messages trigger the
+	// reconciles of the objects they name
+	MessageBus bool
+
+	// ValidateInReconcile indicates whether the controller validates the objects with the validation of their
+	// validating webhook and records the violations in their conditions, for the clusters disallowing webhooks
+	ValidateInReconcile bool
+
+	// Finalizer indicates whether the controller adds a finalizer to the objects and cleans up after them when
+	// they are deleted
+	Finalizer bool
+
+	// BackupHooks indicates whether to scaffold the stubs of the hooks Velero runs in the pods of the workloads of
+	// the objects around their backups and restores, the package annotating them and a Velero schedule
+	BackupHooks bool
+
+	// LargeScale indicates whether the controller lists the objects in pages and reads the kinds it doesn't watch
+	// from the API server, bypassing the cache of the manager which holds every object of the watched kinds
+	LargeScale bool
+
+	// LongRunning indicates whether the status tracks a long-running operation of several steps, which the
+	// controller runs across reconciles, resuming it from its progress after a restart
+	LongRunning bool
+
+	// CircuitBreaker indicates whether the controller calls the external systems the objects depend on through a
+	// circuit breaker failing the calls fast while the systems keep failing, its state recorded in a condition of
+	// the objects and in a metric
+	CircuitBreaker bool
+
+	// NoStatusConditions indicates that the status of the resource has no conditions, nor the helpers finding and
+	// setting them
+	NoStatusConditions bool
+
+	// NoSample indicates that the sample of the resource isn't scaffolded under config/samples, the example objects
+	// being managed elsewhere
+	NoSample bool
+
+	// RBACAggregation indicates whether the editor and viewer roles of the resource are aggregated into the admin,
+	// edit and view ClusterRoles of Kubernetes, for the users granted them to manage the objects of the resource
+	RBACAggregation bool
+
+	// GenerateClients indicates whether the typed clientset, informers and listers of the resource are generated by
+	// make clients, for the consumers of the API which don't use the client of controller-runtime
+	GenerateClients bool
+
+	// ApplyConditions indicates whether the controller applies the conditions of the objects with server-side
+	// apply as its own field manager, keeping the conditions set by the other controllers
+	ApplyConditions bool
+
+	// E2E indicates whether to scaffold the end-to-end test of the resource, applying its sample to a test
+	// environment or to a cluster and waiting until the controller reconciled it
+	E2E bool
+
+	// FromTypes is the path of a Go file whose struct is lifted into the spec of the resource
+	FromTypes string
+
+	// Schema is the path of an OpenAPI v3 or JSON schema the spec of the resource is generated from
+	Schema string
+
+	// FromCluster is the name of a CRD of the cluster the kind, the names and the spec of the resource are copied
+	// from, e.g. a CRD installed by another tool the project takes ownership of
+	FromCluster string
+
+	// Kubeconfig is the path of the kubeconfig of the cluster of FromCluster, the one of kubectl if empty
+	Kubeconfig string
+
+	// References are the fields of the spec referencing objects of other kinds,
+	// of the form spec.<field>:<group>/<version>/<Kind>
+	References []string
+
+	// Unions are the fields of the spec of which exactly one member is set,
+	// of the form spec.<field>=<member>|<member>...
+	Unions []string
+
+	// Embeds are the fields of the spec of Kubernetes types, of the form <group>/<version>/<Type>:spec.<field>
+	Embeds []string
+
+	// Enums are the fields of the spec or of the status whose value is one of a set of strings,
+	// of the form spec.<field>=<value>,<value>... or status.<field>=<value>,<value>...
+	Enums []string
+
+	// PrinterColumns are the columns of kubectl get for the resource, of the form <name>:<JSONPath>:<type>
+	PrinterColumns []string
+
+	// Adopt are the kinds of the objects managed by the controller, adopted when they exist and no object
+	// controls them, of the form <group>/<version>/<Kind>
+	Adopt []string
+
+	// ReconcileTemplate is the name of the body of the reconciler, e.g. deployment for a reconciler creating and
+	// updating a Deployment for every object, none to leave its logic to the user
+	ReconcileTemplate string
+
 	// Force indicates that the resource should be created even if it already exists.
 	Force bool
+
+	// ForceOnly restricts Force to the existing files of some categories, among ForceTypes, ForceController and
+	// ForceManifests, the files of the other categories are kept. Force overwrites the files of every category if
+	// it is empty.
+	ForceOnly []string
+
+	// Diff, if set, receives the unified diffs between the existing files and their templates, see Scaffold.Diff.
+	// A dry run compares an existing resource with its templates then, rather than refusing it.
+	Diff io.Writer
+
+	// OutputDir is the project root, defaults to the current working directory
+	OutputDir string
+
+	// scaffolds keeps track of the scaffolds used to write the files
+	scaffolds []*Scaffold
+
+	// backupDir is the directory the scaffolds back up the files they overwrite to
+	backupDir string
+
+	// imported contains the declarations read from FromTypes or generated from Schema or from the schema of the
+	// CRD of FromCluster
+	imported *scaffoldv2.ImportedTypes
+
+	// clusterCRD is the CRD of FromCluster
+	clusterCRD *scaffoldv2.ClusterCRD
+
+	// references are the parsed References
+	references []*scaffoldv2.Reference
+
+	// unions are the parsed Unions
+	unions []*scaffoldv2.Union
+
+	// embeds are the parsed Embeds
+	embeds []*scaffoldv2.Embed
+
+	// enums are the parsed Enums
+	enums []*scaffoldv2.Enum
+
+	// printerColumns are the parsed PrinterColumns
+	printerColumns []*scaffoldv2.PrinterColumn
+
+	// children are the parsed Adopt
+	children []*scaffoldv2.Child
+
+	// reconcile is the parsed ReconcileTemplate, nil for none
+	reconcile *controllerv2.ReconcileTemplate
+
+	// hub is the storage version of the kind if the resource is a new version of a kind of the project, the hub
+	// of the conversions between its versions
+	hub *resource.Resource
 }
 
 // Validate validates whether API scaffold has correct bits to generate
@@ -58,24 +241,347 @@ func (api *API) Validate() error {
 	if err := api.setDefaults(); err != nil {
 		return err
 	}
+	if err := api.validateSupported(); err != nil {
+		return err
+	}
+	if api.FromCluster != "" {
+		if err := api.copyClusterCRD(); err != nil {
+			return err
+		}
+	}
+	if api.Resource.Resource != "" && !api.query.TracksResources() {
+		return fmt.Errorf("the plural of the resource can only be set for v2 projects")
+	}
+	if api.Resource.Resource == "" {
+		// a tracked resource keeps the plural it was created with
+		api.Resource.Resource = api.config.PluralOf(api.Resource)
+	}
+	if !api.DoResource {
+		// no CRD is scaffolded, the controller may be for a built-in kind
+		api.Resource.AllowReservedGroup = true
+	}
 	if err := api.Resource.Validate(); err != nil {
 		return err
 	}
+	if api.DoResource && !api.Resource.AllowReservedGroup {
+		// the group of the CRD is the group of the resource qualified by the domain of the project
+		if group := api.Resource.Group + "." + api.config.Domain; resource.IsReservedGroup(group) {
+			return resource.ReservedGroupError(group)
+		}
+	}
+	// the other versions of a tracked kind were already checked when it was created
+	if api.DoResource && !api.Resource.AllowBuiltinKind && len(api.config.VersionsOf(api.Resource)) == 0 {
+		if groups := resource.BuiltinKindGroups(api.Resource.Group, api.Resource.Kind); groups != nil {
+			return resource.BuiltinKindError(api.Resource.Kind, groups)
+		}
+	}
+	if api.DoResource {
+		if group := api.config.GroupImportedAs(api.Resource.Group); group != "" {
+			return fmt.Errorf("the group %s would be imported as %s<version> like the group %s of the project, "+
+				"the imports of main.go would collide: choose another group", api.Resource.Group,
+				api.Resource.GroupImportSafe, group)
+		}
+	}
+	// the names the groups of a multigroup project share are qualified by the group for the next groups of a kind
+	api.Resource.SharedKind = api.config.SharesKind(api.Resource)
 
-	if api.config.HasResource(api.Resource) && !api.Force {
+	if api.config.HasResource(api.Resource) && !api.scaffoldsExisting() {
 		return fmt.Errorf("API resource already exists")
 	}
+	if err := api.validateForceOnly(); err != nil {
+		return err
+	}
+
+	if api.DoResource && api.query.TracksResources() && !api.Resource.NoCRD {
+		if storage := api.config.StorageVersionOf(api.Resource); storage != "" && storage != api.Resource.Version {
+			hub := *api.Resource
+			hub.Version = storage
+			api.hub = &hub
+		}
+	}
+	if api.hub != nil && api.DoController {
+		if _, err := filesystem.Stat(filepath.Join(api.OutputDir, api.controllerPath())); err == nil {
+			return fmt.Errorf("the controller of %s already exists and reconciles all of its versions, create "+
+				"the version %s with --controller=false", api.Resource.Kind, api.Resource.Version)
+		}
+	}
+
+	// the optional dependencies of the reconcilers are passed by main.go, they have no provider yet
+	if api.config.HasWireInjection() && (api.HealthCheck || api.WatchConfig || api.MessageBus || api.LargeScale) {
+		return fmt.Errorf("--health-check, --watch-config, --message-bus and --large-scale can't be injected by " +
+			"wire yet, scaffold the controller without them and add their providers to providers.go")
+	}
+
+	if api.CircuitBreaker && len(api.Plugins) > 0 {
+		return fmt.Errorf("the circuit breaker guards the calls of the default controller, the controller of " +
+			"--pattern doesn't use it")
+	}
+
+	if api.NoStatusConditions && api.DoResource &&
+		(api.ValidateInReconcile || api.ApplyConditions || api.UnitTests || api.CircuitBreaker) {
+		return fmt.Errorf("--validate-in-reconcile, --apply-conditions, --unit-tests and --circuit-breaker set " +
+			"the conditions of the status, they require --status-conditions")
+	}
+
+	if api.E2E && (api.NoSample || api.Resource.NoCRD) {
+		return fmt.Errorf("the end-to-end test applies the sample of the resource, it requires its sample and " +
+			"its CRD")
+	}
+
+	if api.FromTypes != "" {
+		imported, err := scaffoldv2.ImportTypes(api.FromTypes, api.Resource.Kind)
+		if err != nil {
+			return fmt.Errorf("error importing types: %v", err)
+		}
+		api.imported = imported
+	}
+
+	if api.Schema != "" {
+		if api.FromTypes != "" {
+			return fmt.Errorf("--schema and --from-types are mutually exclusive")
+		}
+		imported, err := scaffoldv2.ImportSchema(api.Schema, api.Resource.Kind)
+		if err != nil {
+			return fmt.Errorf("error importing schema: %v", err)
+		}
+		api.imported = imported
+	}
+
+	if api.clusterCRD != nil {
+		imported, err := api.clusterCRD.ImportSchema(api.Resource.Version)
+		if err != nil {
+			return fmt.Errorf("error importing schema: %v", err)
+		}
+		api.imported = imported
+	}
+
+	fields := map[string]bool{}
+	if api.imported == nil {
+		// the example field of the spec
+		fields["Foo"] = true
+	}
+	for _, value := range api.References {
+		ref, err := scaffoldv2.ParseReference(value, api.Resource.Namespaced)
+		if err != nil {
+			return err
+		}
+		if (ref.CrossNamespace || ref.ClusterScoped) && api.config.HasNamespaceTenancy() {
+			return fmt.Errorf("invalid reference %q: the tenants are isolated by namespace, the objects of a tenant "+
+				"can't reference objects out of its namespace", value)
+		}
+		if fields[ref.Field] {
+			return fmt.Errorf("field spec.%s is declared more than once", ref.JSONName)
+		}
+		fields[ref.Field] = true
+		if plural := api.config.PluralOf(ref.Resource); plural != "" {
+			ref.Resource.Resource = plural
+		}
+		api.references = append(api.references, ref)
+	}
+	for _, value := range api.Unions {
+		union, err := scaffoldv2.ParseUnion(value)
+		if err != nil {
+			return err
+		}
+		if fields[union.Field] {
+			return fmt.Errorf("field spec.%s is declared more than once", union.JSONName)
+		}
+		fields[union.Field] = true
+		api.unions = append(api.unions, union)
+	}
+	for _, value := range api.Embeds {
+		embed, err := scaffoldv2.ParseEmbed(value)
+		if err != nil {
+			return err
+		}
+		if fields[embed.Field] {
+			return fmt.Errorf("field spec.%s is declared more than once", embed.JSONName)
+		}
+		fields[embed.Field] = true
+		api.embeds = append(api.embeds, embed)
+	}
+	for _, value := range api.Enums {
+		enum, err := scaffoldv2.ParseEnum(value)
+		if err != nil {
+			return err
+		}
+		// the type of an enum is named after its field, the fields of the status can't share the names of the
+		// fields of the spec either
+		if fields[enum.Field] || (enum.Status && enum.Field == "Conditions" && !api.NoStatusConditions) ||
+			(enum.Status && enum.Field == "Operation" && api.LongRunning) {
+			return fmt.Errorf("field %s is declared more than once", enum.Path())
+		}
+		fields[enum.Field] = true
+		api.enums = append(api.enums, enum)
+	}
+
+	// the Ready and Age columns are always printed, Ready only if the status has conditions, and the Phase column
+	// of the long-running operation
+	columns := map[string]bool{"Age": true, "Ready": !api.NoStatusConditions, "Phase": api.LongRunning}
+	for _, value := range api.PrinterColumns {
+		column, err := scaffoldv2.ParsePrinterColumn(value)
+		if err != nil {
+			return err
+		}
+		if columns[column.Name] {
+			return fmt.Errorf("printer column %s is declared more than once, the Age column, the Ready column "+
+				"of the conditions and the Phase column of the long-running operation are always printed", column.Name)
+		}
+		columns[column.Name] = true
+		api.printerColumns = append(api.printerColumns, column)
+	}
+
+	if len(api.Adopt) > 0 && fields["AdoptExisting"] {
+		return fmt.Errorf("field spec.adoptExisting is declared more than once")
+	}
+	kinds := map[string]bool{}
+	for _, value := range api.Adopt {
+		child, err := scaffoldv2.ParseChild(value, api.Resource.Namespaced)
+		if err != nil {
+			return err
+		}
+		if kinds[child.Resource.Kind] {
+			return fmt.Errorf("child kind %s is adopted more than once", child.Resource.Kind)
+		}
+		kinds[child.Resource.Kind] = true
+		api.children = append(api.children, child)
+	}
+
+	reconcile, err := controllerv2.ReconcileTemplateOf(api.ReconcileTemplate)
+	if err != nil {
+		return err
+	}
+	if reconcile != nil {
+		if !api.Resource.Namespaced {
+			return fmt.Errorf("the reconcile template %s creates a %s in the namespace of every %s, the resource "+
+				"must be namespaced", api.ReconcileTemplate, reconcile.Kind, api.Resource.Kind)
+		}
+		if kinds[reconcile.Kind] {
+			return fmt.Errorf("child kind %s is managed by the reconcile template %s already", reconcile.Kind,
+				api.ReconcileTemplate)
+		}
+		api.reconcile = reconcile
+	}
+
+	return nil
+}
+
+// validateSupported validates that the project and the scaffolded resource and controller support the options set:
+// the options of the v2 projects extend the resource, the controller or both of them
+func (api *API) validateSupported() error {
+	markers := api.query.SupportsScaffoldMarkers()
+	withResource := markers && api.DoResource
+	withController := markers && api.DoController
+	withBoth := withResource && api.DoController
+	r := api.Resource
+	for _, option := range []struct {
+		enabled   bool
+		supported bool
+		flag      string
+		// along is what the option is scaffolded along with, empty if it only requires a v2 project
+		along string
+	}{
+		{api.UnitTests, markers, "unit-tests", ""},
+		{api.NoSample, markers, "sample=false", ""},
+		{r.NoCRD, withResource, "no-crd", "the resource"},
+		{len(r.ShortNames) > 0, withResource, "short-name", "the resource"},
+		{len(r.Categories) > 0, withResource, "category", "the resource"},
+		{api.FromTypes != "", withResource, "from-types", "the resource"},
+		{api.Schema != "", withResource, "schema", "the resource"},
+		{api.FromCluster != "", withResource, "from-cluster", "the resource"},
+		{len(api.References) > 0, withResource, "ref", "the resource"},
+		{len(api.Unions) > 0, withResource, "union", "the resource"},
+		{len(api.Embeds) > 0, withResource, "embed", "the resource"},
+		{len(api.Enums) > 0, withResource, "enum", "the resource"},
+		{len(api.PrinterColumns) > 0, withResource, "printer-column", "the resource"},
+		{api.RBACAggregation, withResource, "rbac-aggregation", "the resource"},
+		{api.GenerateClients, withResource, "generate-clients", "the resource"},
+		{api.HealthCheck, withController, "health-check", "the controller"},
+		{api.WatchConfig, withController, "watch-config", "the controller"},
+		{api.MessageBus, withController, "message-bus", "the controller"},
+		{api.LargeScale, withController, "large-scale", "the controller"},
+		{api.ValidateInReconcile, withController, "validate-in-reconcile", "the controller"},
+		{api.Finalizer, withController, "with-finalizer", "the controller"},
+		{api.BackupHooks, withController, "backup-hooks", "the controller"},
+		{api.CircuitBreaker, withController, "circuit-breaker", "the controller"},
+		{api.ReconcileTemplate != "" && api.ReconcileTemplate != controllerv2.ReconcileTemplateNone, withController,
+			"reconcile-template", "the controller"},
+		{api.LongRunning, withBoth, "long-running", "the resource and the controller"},
+		{api.ApplyConditions, withBoth, "apply-conditions", "the resource and the controller"},
+		{api.E2E, withBoth, "e2e", "the resource and the controller"},
+		{len(api.Adopt) > 0, withBoth, "adopt", "the resource and the controller"},
+	} {
+		switch {
+		case !option.enabled || option.supported:
+		case option.along == "":
+			return fmt.Errorf("--%s can only be set in a v2 project", option.flag)
+		default:
+			return fmt.Errorf("--%s can only be set when scaffolding %s of a v2 project", option.flag, option.along)
+		}
+	}
+	return nil
+}
+
+// copyClusterCRD reads the CRD of FromCluster and copies its kind, group and names to the resource, checking them
+// against the ones set, the version defaulting to its storage version
+func (api *API) copyClusterCRD() error {
+	if api.FromTypes != "" || api.Schema != "" {
+		return fmt.Errorf("--from-cluster, --schema and --from-types are mutually exclusive")
+	}
+	crd, err := scaffoldv2.FetchCRD(api.Kubeconfig, api.FromCluster)
+	if err != nil {
+		return err
+	}
 
+	r := api.Resource
+	group := strings.TrimSuffix(crd.Group, "."+api.config.Domain)
+	if group == crd.Group {
+		return fmt.Errorf("the group %s of the CRD %s isn't under the domain %s of the project", crd.Group,
+			crd.Name, api.config.Domain)
+	}
+	for _, value := range []struct{ name, set, crd string }{
+		{"group", r.Group, group},
+		{"kind", r.Kind, crd.Kind},
+		{"plural", r.Resource, crd.Plural},
+	} {
+		if value.set != "" && value.set != value.crd {
+			return fmt.Errorf("the %s %s doesn't match the %s %s of the CRD %s, leave it unset to copy it",
+				value.name, value.set, value.name, value.crd, crd.Name)
+		}
+	}
+	r.Group, r.Kind, r.Resource, r.Namespaced = group, crd.Kind, crd.Plural, crd.Namespaced
+	switch {
+	case r.Version == "" && crd.HasVersion(crd.StorageVersion):
+		r.Version = crd.StorageVersion
+	case r.Version == "":
+		r.Version = crd.Versions[0]
+	case !crd.HasVersion(r.Version):
+		return fmt.Errorf("the version %s isn't served by the CRD %s, its versions are %s", r.Version, crd.Name,
+			strings.Join(crd.Versions, ", "))
+	}
+	if len(r.ShortNames) == 0 {
+		r.ShortNames = crd.ShortNames
+	}
+	if len(r.Categories) == 0 {
+		r.Categories = crd.Categories
+	}
+	if len(api.PrinterColumns) == 0 {
+		api.PrinterColumns = crd.PrinterColumns(r.Version)
+	}
+	api.clusterCRD = crd
 	return nil
 }
 
 func (api *API) setDefaults() (err error) {
 	if api.config == nil {
-		api.config, err = config.Load()
+		api.config, err = config.LoadFrom(filepath.Join(api.OutputDir, config.DefaultPath))
 		if err != nil {
 			return
 		}
 	}
+	if api.query == nil {
+		api.query, err = api.config.Query()
+	}
 
 	return
 }
@@ -85,13 +591,53 @@ func (api *API) Scaffold() error {
 		return err
 	}
 
-	switch {
-	case api.config.IsV1():
-		return api.scaffoldV1()
-	case api.config.IsV2():
-		return api.scaffoldV2()
-	default:
-		return fmt.Errorf("unknown project version %v", api.config.Version)
+	defer logging.Phase("create api", "kind", api.Resource.Kind)()
+	var err error
+	// the projects inserting the code of the APIs at the scaffold markers are scaffolded with the v2 templates
+	if api.query.SupportsScaffoldMarkers() {
+		err = api.scaffoldV2()
+	} else {
+		err = api.scaffoldV1()
+	}
+
+	api.printBackups()
+
+	return err
+}
+
+// newScaffold returns a Scaffold writing the files of the API
+func (api *API) newScaffold() *Scaffold {
+	// the scaffolds of the API back up their files to the same directory
+	if api.backupDir == "" {
+		api.backupDir = newBackupDir(api.OutputDir, time.Now())
+	}
+	s := &Scaffold{
+		OutputDir: api.OutputDir,
+		Force:     api.Force,
+		BackupDir: api.backupDir,
+		Diff:      api.Diff,
+	}
+	if len(api.ForceOnly) > 0 {
+		s.ForceOnly = api.isForced
+	}
+	api.scaffolds = append(api.scaffolds, s)
+	return s
+}
+
+// printBackups summarizes the modified files that were backed up before being overwritten
+func (api *API) printBackups() {
+	var backups []string
+	for _, s := range api.scaffolds {
+		backups = append(backups, s.Backups...)
+	}
+	if len(backups) == 0 {
+		return
+	}
+
+	logging.Info(fmt.Sprintf("The following files were modified since they were scaffolded and have been "+
+		"overwritten, their previous content and a diff with the new one are stored in %s:", api.backupDir))
+	for _, path := range backups {
+		logging.Info("- " + path)
 	}
 }
 
@@ -107,9 +653,9 @@ func (api *API) scaffoldV1() error {
 	r := api.Resource
 
 	if api.DoResource {
-		fmt.Println(filepath.Join("pkg", "apis", r.Group, r.Version,
+		logging.Path(filepath.Join("pkg", "apis", r.Group, r.Version,
 			fmt.Sprintf("%s_types.go", strings.ToLower(r.Kind))))
-		fmt.Println(filepath.Join("pkg", "apis", r.Group, r.Version,
+		logging.Path(filepath.Join("pkg", "apis", r.Group, r.Version,
 			fmt.Sprintf("%s_types_test.go", strings.ToLower(r.Kind))))
 
 		universe, err := api.buildUniverse(r)
@@ -117,7 +663,7 @@ func (api *API) scaffoldV1() error {
 			return fmt.Errorf("error building API scaffold: %v", err)
 		}
 
-		err = (&Scaffold{}).Execute(
+		err = api.newScaffold().Execute(
 			universe,
 			input.Options{},
 			&crdv1.Register{Resource: r},
@@ -141,9 +687,9 @@ func (api *API) scaffoldV1() error {
 	}
 
 	if api.DoController {
-		fmt.Println(filepath.Join("pkg", "controller", strings.ToLower(r.Kind),
+		logging.Path(filepath.Join("pkg", "controller", strings.ToLower(r.Kind),
 			fmt.Sprintf("%s_controller.go", strings.ToLower(r.Kind))))
-		fmt.Println(filepath.Join("pkg", "controller", strings.ToLower(r.Kind),
+		logging.Path(filepath.Join("pkg", "controller", strings.ToLower(r.Kind),
 			fmt.Sprintf("%s_controller_test.go", strings.ToLower(r.Kind))))
 
 		universe, err := api.buildUniverse(r)
@@ -151,7 +697,7 @@ func (api *API) scaffoldV1() error {
 			return fmt.Errorf("error building controller scaffold: %v", err)
 		}
 
-		err = (&Scaffold{}).Execute(
+		err = api.newScaffold().Execute(
 			universe,
 			input.Options{},
 			&controller.Controller{Resource: r},
@@ -174,48 +720,117 @@ func (api *API) scaffoldV2() error {
 		if err := api.validateResourceGroup(r); err != nil {
 			return err
 		}
+		endResource := logging.Phase("resource")
 
 		// Only save the resource in the config file if it didn't exist
-		if api.config.AddResource(api.Resource) {
+		added := api.config.AddResource(api.Resource)
+		if api.GenerateClients && api.config.SetClients(api.Resource) {
+			added = true
+		}
+		if added {
 			if err := api.config.Save(); err != nil {
 				return fmt.Errorf("error updating project file with resource information : %v", err)
 			}
+			if err := api.fixImportAliases(); err != nil {
+				return err
+			}
 		}
 
-		var path string
-		if api.config.MultiGroup {
-			path = filepath.Join("apis", r.Group, r.Version, fmt.Sprintf("%s_types.go", strings.ToLower(r.Kind)))
-		} else {
-			path = filepath.Join("api", r.Version, fmt.Sprintf("%s_types.go", strings.ToLower(r.Kind)))
-		}
-		fmt.Println(path)
-
-		scaffold := &Scaffold{
-			Plugins: api.Plugins,
-		}
+		scaffold := api.newScaffold()
+		scaffold.Plugins = api.Plugins
 
 		universe, err := api.buildUniverse(r)
 		if err != nil {
 			return fmt.Errorf("error building API scaffold: %v", err)
 		}
 
+		editorRole := &scaffoldv2.CRDEditorRole{Resource: r, Aggregate: api.RBACAggregation}
+		viewerRole := &scaffoldv2.CRDViewerRole{Resource: r, Aggregate: api.RBACAggregation}
+		types := &scaffoldv2.Types{
+			Resource:         r,
+			Imported:         api.imported,
+			References:       api.references,
+			Unions:           api.unions,
+			Embeds:           api.embeds,
+			Enums:            api.enums,
+			PrinterColumns:   api.printerColumns,
+			AdoptExisting:    len(api.children) > 0,
+			StatusConditions: !api.NoStatusConditions,
+			ApplyConditions:  api.ApplyConditions,
+			LongRunning:      api.LongRunning,
+			GenerateClient:   api.GenerateClients,
+		}
+		clients := input.When(input.OnlyIf(api.GenerateClients))
+		clientsScript := &scaffoldv2.ClientsScript{Input: clients, Packages: api.config.ClientPackages()}
 		files := []input.File{
-			&scaffoldv2.Types{
-				Input: input.Input{
-					Path: path,
-				},
-				Resource: r},
+			types,
 			&scaffoldv2.Group{Resource: r},
-			&scaffoldv2.CRDSample{Resource: r},
-			&scaffoldv2.CRDEditorRole{Resource: r},
-			&scaffoldv2.CRDViewerRole{Resource: r},
-			&crdv2.EnableWebhookPatch{Resource: r},
-			&crdv2.EnableCAInjectionPatch{Resource: r},
+			editorRole,
+			viewerRole,
+			&scaffoldv2.Conditions{Input: input.When(input.SkipIf(api.NoStatusConditions)), Resource: r},
+			&scaffoldv2.Operations{Input: input.When(input.OnlyIf(api.LongRunning)), Resource: r},
+			&scaffoldv2.CRDSample{Input: input.When(input.SkipIf(r.NoCRD || api.NoSample)), Resource: r,
+				Imported: api.imported, References: api.references, Unions: api.unions, Embeds: api.embeds,
+				Enums: api.enums},
+			&crdv2.EnableWebhookPatch{Input: input.When(input.SkipIf(r.NoCRD)), Resource: r},
+			&crdv2.EnableCAInjectionPatch{Input: input.When(input.SkipIf(r.NoCRD)), Resource: r},
+			&scaffoldv2.ReferenceTypes{
+				Input:    input.When(input.OnlyIf(scaffoldv2.HasCrossNamespaceReference(api.references))),
+				Resource: r,
+			},
+			&scaffoldv2.ClientRegister{Input: clients, Resource: r},
+			clientsScript,
 		}
 
 		if err = scaffold.Execute(universe, input.Options{}, files...); err != nil {
 			return fmt.Errorf("error scaffolding APIs: %v", err)
 		}
+		logging.Path(types.Path)
+
+		for _, role := range []interface{ Update() error }{editorRole, viewerRole} {
+			if err := role.Update(); err != nil {
+				return fmt.Errorf("error updating the kustomization of the RBAC manifests: %v", err)
+			}
+		}
+		if api.GenerateClients {
+			if err := clientsScript.Update(); err != nil {
+				return fmt.Errorf("error updating the Makefile: %v", err)
+			}
+			logging.Info("Run make clients to generate the typed clientset, informers and listers into pkg/client")
+		}
+
+		if api.hub != nil {
+			if err := api.scaffoldConversion(r); err != nil {
+				return err
+			}
+		}
+
+		if api.imported != nil {
+			for _, warning := range api.imported.Warnings {
+				logging.Warning(fmt.Sprintf("%s: %s", api.imported.Source, warning))
+			}
+		}
+		if api.clusterCRD != nil {
+			logging.Info(fmt.Sprintf("make install replaces the CRD %s of the cluster with the one generated from "+
+				"the types, compare them with kubectl diff -f config/crd/bases first", api.clusterCRD.Name))
+		}
+		if len(api.unions) > 0 {
+			logging.Info(fmt.Sprintf("The unions are validated by %s.ValidateUnions, run \"kubebuilder create "+
+				"webhook --programmatic-validation\" for the validating webhook to call it", r.Kind))
+		}
+		if len(api.embeds) > 0 {
+			limited, err := scaffoldv2.LimitCRDDescriptions(api.OutputDir)
+			if err != nil {
+				return fmt.Errorf("error updating the Makefile: %v", err)
+			}
+			if !limited {
+				logging.Warning("the options of the CRDs were changed in the Makefile, add maxDescLen=0 to " +
+					"CRD_OPTIONS for the CRDs embedding Kubernetes types to be small enough to be applied")
+			}
+			logging.Info(fmt.Sprintf("The embedded fields are only validated structurally by the API server, run "+
+				"\"kubebuilder create webhook --programmatic-validation\" to validate them in the validating webhook "+
+				"of %s", r.Kind))
+		}
 
 		universe, err = api.buildUniverse(r)
 		if err != nil {
@@ -223,19 +838,29 @@ func (api *API) scaffoldV2() error {
 		}
 
 		crdKustomization := &crdv2.Kustomization{Resource: r}
-		err = (&Scaffold{}).Execute(
+		// the consumers of the API of a multigroup project register all of its groups with the scheme package
+		scheme := &scaffoldv2.Scheme{Input: input.When(input.OnlyMultiGroup()), Resource: r}
+		err = api.newScaffold().Execute(
 			universe,
 			input.Options{},
 			crdKustomization,
 			&crdv2.KustomizeConfig{},
+			scheme,
 		)
 		if err != nil {
 			return fmt.Errorf("error scaffolding kustomization: %v", err)
 		}
+		if err := scheme.Update(); err != nil {
+			return fmt.Errorf("error updating the scheme package: %v", err)
+		}
 
-		if err := crdKustomization.Update(); err != nil {
+		if r.NoCRD {
+			logging.Info(fmt.Sprintf("The CRD of %s is not deployed, the CRD generated for it by make manifests is "+
+				"not added to the kustomization of the CRDs", r.Kind))
+		} else if err := crdKustomization.Update(); err != nil {
 			return fmt.Errorf("error updating kustomization.yaml: %v", err)
 		}
+		endResource()
 
 	} else {
 		// disable generation of example reconcile body if not scaffolding resource
@@ -246,28 +871,82 @@ func (api *API) scaffoldV2() error {
 	}
 
 	if api.DoController {
-		if api.config.MultiGroup {
-			fmt.Println(filepath.Join("controllers", fmt.Sprintf("%s/%s_controller.go", r.Group, strings.ToLower(r.Kind))))
-		} else {
-			fmt.Println(filepath.Join("controllers", fmt.Sprintf("%s_controller.go", strings.ToLower(r.Kind))))
-		}
+		logging.Path(api.controllerPath())
 
-		scaffold := &Scaffold{
-			Plugins: api.Plugins,
-		}
+		endController := logging.Phase("controller")
+		scaffold := api.newScaffold()
+		scaffold.Plugins = api.Plugins
 
 		universe, err := api.buildUniverse(r)
 		if err != nil {
 			return fmt.Errorf("error building controller scaffold: %v", err)
 		}
 
+		// the tenants are isolated by namespace, the cluster-scoped objects don't belong to any
+		tenancy := api.config.HasNamespaceTenancy() && r.Namespaced
+
 		testsuiteScaffolder := &controllerv2.SuiteTest{Resource: r}
-		err = scaffold.Execute(
-			universe,
-			input.Options{},
+		files := []input.File{
 			testsuiteScaffolder,
-			&controllerv2.Controller{Resource: r},
+			&controllerv2.Controller{Resource: r, References: api.references, Children: api.children,
+				HealthCheck: api.HealthCheck, WatchConfig: api.WatchConfig, MessageBus: api.MessageBus,
+				Tenancy: tenancy, ValidateInReconcile: api.ValidateInReconcile, ApplyConditions: api.ApplyConditions,
+				Finalizer: api.Finalizer, BackupHooks: api.BackupHooks, LargeScale: api.LargeScale,
+				LongRunning: api.LongRunning, CircuitBreaker: api.CircuitBreaker, Reconcile: api.reconcile},
+			&controllerv2.ControllerTest{Resource: r, Children: api.children, HealthCheck: api.HealthCheck,
+				WatchConfig: api.WatchConfig, MessageBus: api.MessageBus, Tenancy: tenancy, Finalizer: api.Finalizer,
+				LargeScale: api.LargeScale, Reconcile: api.reconcile},
+			&controllerv2.Errors{},
+			&controllerv2.ErrorsTest{},
+			&controllerv2.Labels{},
+			&controllerv2.LabelsTest{},
+		}
+		unitTests := input.When(input.OnlyIf(api.UnitTests))
+		adoption := input.When(input.OnlyIf(len(api.children) > 0))
+		messageBus := input.When(input.OnlyIf(api.MessageBus))
+		validation := input.When(input.OnlyIf(api.ValidateInReconcile))
+		finalizers := input.When(input.OnlyIf(api.Finalizer))
+		backupHooks := input.When(input.OnlyIf(api.BackupHooks))
+		applyConditions := input.When(input.OnlyIf(api.ApplyConditions))
+		healthCheck := input.When(input.OnlyIf(api.HealthCheck))
+		watchConfig := input.When(input.OnlyIf(api.WatchConfig))
+		longRunning := input.When(input.OnlyIf(api.LongRunning))
+		circuitBreaker := input.When(input.OnlyIf(api.CircuitBreaker))
+		schedule := &backup.Schedule{Input: backupHooks, Resource: r}
+		configMap := &managerv2.OperatorConfig{Input: watchConfig}
+		configRole := &scaffoldv2.OperatorConfigRole{Input: watchConfig}
+		configRoleBinding := &scaffoldv2.OperatorConfigRoleBinding{Input: watchConfig}
+		files = append(files,
+			&controllerv2.ControllerUnitTest{Input: unitTests, Resource: r, Children: api.children,
+				HealthCheck: api.HealthCheck, WatchConfig: api.WatchConfig, MessageBus: api.MessageBus,
+				LargeScale: api.LargeScale, Reconcile: api.reconcile},
+			&controllerv2.Adoption{Input: adoption},
+			&controllerv2.AdoptionTest{Input: adoption},
+			&controllerv2.MessageBus{Input: messageBus},
+			&controllerv2.MessageBusFake{Input: messageBus},
+			&controllerv2.MessageBusTest{Input: messageBus},
+			&controllerv2.Validation{Input: validation},
+			&controllerv2.ValidationTest{Input: validation},
+			&controllerv2.Finalizers{Input: finalizers},
+			&controllerv2.FinalizersTest{Input: finalizers},
+			&controllerv2.Operation{Input: longRunning, Resource: r, CircuitBreaker: api.CircuitBreaker},
+			&controllerv2.OperationTest{Input: longRunning, Resource: r, CircuitBreaker: api.CircuitBreaker},
+			&controllerv2.CircuitBreaker{Input: circuitBreaker},
+			&controllerv2.CircuitBreakerTest{Input: circuitBreaker},
+			&controllerv2.Backup{Input: backupHooks},
+			&controllerv2.BackupTest{Input: backupHooks},
+			&backup.Kustomization{Input: backupHooks},
+			schedule,
+			&controllerv2.ApplyConditions{Input: applyConditions},
+			&controllerv2.ApplyConditionsTest{Input: applyConditions},
+			&controllerv2.ConditionsOwnershipTest{Input: applyConditions, Resource: r},
+			&controllerv2.Heartbeat{Input: healthCheck},
+			&prometheus.ControllerHeartbeatAlert{Input: healthCheck},
+			&controllerv2.OperatorConfig{Input: watchConfig},
+			&controllerv2.OperatorConfigTest{Input: watchConfig},
+			configMap, configRole, configRoleBinding,
 		)
+		err = scaffold.Execute(universe, input.Options{}, files...)
 		if err != nil {
 			return fmt.Errorf("error scaffolding controller: %v", err)
 		}
@@ -276,29 +955,243 @@ func (api *API) scaffoldV2() error {
 		if err != nil {
 			return fmt.Errorf("error updating suite_test.go under controllers pkg: %v", err)
 		}
+		// the suite of a new group registers the group versions defined outside of the project too
+		err = scaffoldv2.RegisterSchemesInSuites(api.OutputDir, &api.config.Config, api.config.Schemes)
+		if err != nil {
+			return fmt.Errorf("error registering the schemes in suite_test.go under controllers pkg: %v", err)
+		}
+
+		if api.WatchConfig {
+			for _, f := range []interface{ Update() error }{configMap, configRole, configRoleBinding} {
+				if err := f.Update(); err != nil {
+					return fmt.Errorf("error updating kustomization.yaml: %v", err)
+				}
+			}
+		}
+
+		if api.BackupHooks {
+			if err := schedule.Update(); err != nil {
+				return fmt.Errorf("error updating the kustomization of the backup schedules: %v", err)
+			}
+		}
+
+		// the controllers of the resources created without one are listed too, they are harmless in the dashboard
+		resources := append([]modelconfig.GVK{{Group: r.Group, Version: r.Version, Kind: r.Kind}}, api.config.Resources...)
+		err = api.newScaffold().Execute(
+			universe,
+			input.Options{},
+			&observability.Kustomization{},
+			&observability.Dashboard{Resources: resources},
+		)
+		if err != nil {
+			return fmt.Errorf("error scaffolding observability: %v", err)
+		}
+
+		// the built-in kinds have no types in the project, they are tracked along with the package of their types
+		if !api.DoResource && util.IsBuiltin(api.OutputDir, r, api.config.MultiGroup) {
+			if api.config.AddExternalResource(r, util.BuiltinPackage(r)+"/"+r.Version) {
+				if err := api.config.Save(); err != nil {
+					return fmt.Errorf("error updating project file with resource information : %v", err)
+				}
+				if err := api.fixImportAliases(); err != nil {
+					return err
+				}
+			}
+		}
+		endController()
+	}
+
+	if api.E2E {
+		universe, err := api.buildUniverse(r)
+		if err != nil {
+			return fmt.Errorf("error building end-to-end test scaffold: %v", err)
+		}
+		suite := &e2e.Suite{}
+		err = api.newScaffold().Execute(
+			universe,
+			input.Options{},
+			suite,
+			&e2e.KindTest{Resource: r, StatusConditions: !api.NoStatusConditions, HealthCheck: api.HealthCheck,
+				WatchConfig: api.WatchConfig, MessageBus: api.MessageBus, LargeScale: api.LargeScale},
+		)
+		if err != nil {
+			return fmt.Errorf("error scaffolding the end-to-end test: %v", err)
+		}
+		if err := suite.Update(); err != nil {
+			return fmt.Errorf("error updating the Makefile: %v", err)
+		}
 	}
 
+	endMain := logging.Phase("main.go")
 	err := (&scaffoldv2.Main{}).Update(
 		&scaffoldv2.MainUpdateOptions{
-			Config:         &api.config.Config,
-			WireResource:   api.DoResource,
-			WireController: api.DoController,
-			Resource:       r,
+			Config:            &api.config.Config,
+			WireResource:      api.DoResource,
+			WireController:    api.DoController,
+			WireHealthCheck:   api.HealthCheck,
+			WireConfigWatcher: api.WatchConfig,
+			WireMessageBus:    api.MessageBus,
+			WireAPIReader:     api.LargeScale,
+			Resource:          r,
+			OutputDir:         api.OutputDir,
+		})
+	if err != nil {
+		return fmt.Errorf("error updating main.go: %v", err)
+	}
+	endMain()
+	if api.DoController && api.config.WiringOf(r).SkipController {
+		logging.Info(fmt.Sprintf("The reconciler of %s is not set up in main.go, its wiring is skipped in %s",
+			r.Kind, config.DefaultPath))
+	}
+
+	// the generated examples are kept in sync with the kinds of the project
+	if api.DoResource && HasExamples(api.OutputDir) {
+		e := &Examples{OutputDir: api.OutputDir, config: api.config}
+		if err := e.Validate(); err != nil {
+			return err
+		}
+		if err := e.Scaffold(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scaffoldConversion scaffolds the conversions of the new version of a kind to and from its storage version, the
+// hub, along with the webhook serving them
+func (api *API) scaffoldConversion(r *resource.Resource) error {
+	hub := api.hub
+	universe, err := api.buildUniverse(hub)
+	if err != nil {
+		return fmt.Errorf("error building conversion scaffold: %v", err)
+	}
+	files := []input.File{
+		&scaffoldv2.Hub{Resource: hub},
+		&scaffoldv2.Conversion{Resource: r, Hub: hub},
+		&scaffoldv2.ConversionTest{Resource: r, Hub: hub},
+	}
+	hubWebhook := webhook.WebhookPath(hub, api.config.MultiGroup, api.config.Names())
+	if _, err := filesystem.Stat(filepath.Join(api.OutputDir, hubWebhook)); os.IsNotExist(err) {
+		// the conversion webhook is registered along with the webhooks of the hub
+		logging.Path(hubWebhook)
+		files = append(files, &webhook.Webhook{Resource: hub})
+	}
+	if err := api.newScaffold().Execute(universe, input.Options{}, files...); err != nil {
+		return fmt.Errorf("error scaffolding conversion: %v", err)
+	}
+
+	marked, err := scaffoldv2.MarkStorageVersion(api.OutputDir, hub, api.config.MultiGroup, api.config.Names())
+	if err != nil {
+		return fmt.Errorf("error marking the storage version: %v", err)
+	}
+	if !marked {
+		logging.Warning(fmt.Sprintf("the markers of %s %s were changed, add %s to them for %s to be the "+
+			"storage version", hub.Kind, hub.Version, scaffoldv2.StorageVersionMarker, hub.Version))
+	}
+	if api.config.SetStorageVersion(hub) {
+		if err := api.config.Save(); err != nil {
+			return fmt.Errorf("error updating project file with resource information : %v", err)
+		}
+	}
+
+	err = (&scaffoldv2.Main{}).Update(
+		&scaffoldv2.MainUpdateOptions{
+			Config:      &api.config.Config,
+			WireWebhook: true,
+			Resource:    hub,
+			OutputDir:   api.OutputDir,
 		})
 	if err != nil {
 		return fmt.Errorf("error updating main.go: %v", err)
 	}
 
+	logging.Info(fmt.Sprintf("%s %s is stored as %s and converted by the conversion webhook, implement "+
+		"ConvertTo and ConvertFrom of %s %s, and run kubebuilder create webhook --group %s --version %s --kind %s "+
+		"--conversion to enable the conversion webhook in the CRD of %s and deploy it",
+		r.Kind, r.Version, hub.Version, r.Kind, r.Version, r.Group, hub.Version, r.Kind, r.Plural()))
+	return nil
+}
+
+// fixImportAliases renames the imports of the registered group versions named like the group version of the
+// tracked resource, before main.go and the suites import it
+func (api *API) fixImportAliases() error {
+	renamed, err := scaffoldv2.FixImportAliases(api.OutputDir, &api.config.Config, false)
+	if err != nil {
+		return fmt.Errorf("error renaming the imports of the schemes: %v", err)
+	}
+	for _, path := range renamed {
+		logging.Info(fmt.Sprintf("The imports of the schemes colliding with %s/%s are renamed in %s",
+			api.Resource.Group, api.Resource.Version, path))
+	}
+	return nil
+}
+
+// controllerPath returns the path of the controller of the resource relative to the project root
+func (api *API) controllerPath() string {
+	name := fmt.Sprintf("%s_controller.go", api.config.Names().FileName(api.Resource.Kind))
+	if api.config.MultiGroup {
+		return filepath.Join("controllers", api.Resource.Group, name)
+	}
+	return filepath.Join("controllers", name)
+}
+
+// validateForceOnly returns an error if the categories of the forced files are unknown or set without Force
+func (api *API) validateForceOnly() error {
+	if len(api.ForceOnly) > 0 && !api.Force {
+		return fmt.Errorf("the categories of the files to overwrite are set without forcing the scaffolding")
+	}
+	for _, category := range api.ForceOnly {
+		if len(api.forceDirs(category)) == 0 {
+			return fmt.Errorf("unknown category of files to overwrite %q, must be one of %s", category,
+				strings.Join(ForceCategories, ", "))
+		}
+	}
 	return nil
 }
 
+// forceDirs returns the directories of the files of a category relative to the project root, slash-separated
+func (api *API) forceDirs(category string) []string {
+	var dirs []string
+	switch category {
+	case ForceTypes:
+		dirs = []string{"api", "apis", filepath.Join("pkg", "apis")}
+	case ForceController:
+		dirs = []string{"controllers", filepath.Join("pkg", "controller")}
+	case ForceManifests:
+		dirs = []string{"config", api.config.CRDDir(), api.config.RBACDir(), api.config.WebhookDir()}
+	}
+	for i := range dirs {
+		dirs[i] = filepath.ToSlash(dirs[i])
+	}
+	return dirs
+}
+
+// isForced returns whether the existing file of a path, relative to the project root and slash-separated, is of
+// one of the categories overwritten by Force
+func (api *API) isForced(path string) bool {
+	for _, category := range api.ForceOnly {
+		for _, dir := range api.forceDirs(category) {
+			if strings.HasPrefix(path, dir+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // isGroupAllowed will check if the group is == the group used before
 // and not allow new groups if the project is not enabled to use multigroup layout
 func (api *API) isGroupAllowed(r *resource.Resource) bool {
-	if api.config.MultiGroup {
+	q, err := api.config.Query()
+	if err != nil {
+		// the unknown project versions are refused when scaffolding
 		return true
 	}
-	for _, existingGroup := range api.config.ResourceGroups() {
+	if q.IsMultiGroup() {
+		return true
+	}
+	for _, existingGroup := range q.ResourceGroups() {
 		if !strings.EqualFold(r.Group, existingGroup) {
 			return false
 		}
@@ -306,9 +1199,15 @@ func (api *API) isGroupAllowed(r *resource.Resource) bool {
 	return true
 }
 
+// scaffoldsExisting returns whether an existing resource may be scaffolded again: its files are either
+// overwritten by Force, or compared with their templates by a dry run printing the diffs, e.g. --diff
+func (api *API) scaffoldsExisting() bool {
+	return api.Force || (api.Diff != nil && filesystem.IsDryRun())
+}
+
 // validateResourceGroup will return an error if the group cannot be created
 func (api *API) validateResourceGroup(r *resource.Resource) error {
-	if api.config.HasResource(api.Resource) && !api.Force {
+	if api.config.HasResource(api.Resource) && !api.scaffoldsExisting() {
 		return fmt.Errorf("group '%s', version '%s' and kind '%s' already exists", r.Group, r.Version, r.Kind)
 	}
 	if !api.isGroupAllowed(r) {
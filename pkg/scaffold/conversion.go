@@ -0,0 +1,205 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffold
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/logging"
+	"sigs.k8s.io/kubebuilder/pkg/model"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	scaffoldv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2"
+	crdv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2/crd"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/webhook"
+)
+
+// Conversion scaffolds the conversion webhook of a kind of several versions in the hub and spoke model: the hub,
+// the storage version of the kind, implements conversion.Hub, and the other versions, the spokes, convert to and
+// from it. The conversions missing from the project are scaffolded, e.g. for the versions created before their kind
+// was converted, and the conversion webhook and the injection of the CA of cert-manager are enabled in the CRD. The
+// CA of the projects provisioning a self-signed certificate is injected by the manager, see config.CertProvider.
+type Conversion struct {
+	// OutputDir is the project root, defaults to the current working directory
+	OutputDir string
+
+	// Resource is the kind to convert
+	Resource *resource.Resource
+
+	// Hub is the version of the kind which is the hub of the conversions, its storage version if empty. It can
+	// only be changed until the conversions are scaffolded, they convert to and from the hub.
+	Hub string
+
+	// Diff, if set, receives the unified diffs between the existing files and their templates, see Scaffold.Diff
+	Diff io.Writer
+
+	config *config.Config
+	hub    *resource.Resource
+	spokes []*resource.Resource
+}
+
+// Validate validates that the kind is a kind of the project defined by a CRD, of which the hub is a version
+func (c *Conversion) Validate() error {
+	if c.config == nil {
+		conf, err := config.LoadFrom(config.PathIn(c.OutputDir))
+		if err != nil {
+			return err
+		}
+		c.config = conf
+	}
+	q, err := c.config.Query()
+	if err != nil {
+		return err
+	}
+	if !q.SupportsScaffoldMarkers() {
+		return fmt.Errorf("the conversion webhooks can only be scaffolded in the projects of version 2, the "+
+			"version of this project is %s", c.config.Version)
+	}
+	if err := c.Resource.Validate(); err != nil {
+		return err
+	}
+
+	versions := c.config.VersionsOf(c.Resource)
+	if len(versions) == 0 {
+		return fmt.Errorf("the kind %s of the group %s isn't a kind of the project, create it with kubebuilder "+
+			"create api", c.Resource.Kind, c.Resource.Group)
+	}
+	if !c.config.HasCRD(c.Resource) {
+		return fmt.Errorf("the kind %s has no CRD in the project, its conversion webhook can't be configured in "+
+			"its CRD", c.Resource.Kind)
+	}
+	storage := c.config.StorageVersionOf(c.Resource)
+	if c.Hub == "" {
+		c.Hub = storage
+	}
+	found := false
+	for _, version := range versions {
+		found = found || version == c.Hub
+	}
+	if !found {
+		return fmt.Errorf("the hub %s isn't a version of %s, its versions are %s", c.Hub, c.Resource.Kind,
+			strings.Join(versions, ", "))
+	}
+	if c.Hub != storage {
+		// the conversions scaffolded before convert to and from the storage version
+		for _, version := range versions {
+			if _, err := filesystem.Stat(filepath.Join(c.OutputDir, c.conversionPath(version))); err == nil {
+				return fmt.Errorf("the conversions of %s convert to and from %s, its storage version, the hub can't "+
+					"be changed to %s", c.Resource.Kind, storage, c.Hub)
+			}
+		}
+	}
+
+	hub := *c.Resource
+	hub.Version = c.Hub
+	// the plural and the groups sharing the kind are the ones of the kind, whichever the version
+	hub.Resource = c.config.PluralOf(&hub)
+	if err := hub.Validate(); err != nil {
+		return err
+	}
+	hub.SharedKind = c.config.SharesKind(&hub)
+	c.hub = &hub
+	c.spokes = nil
+	for _, version := range versions {
+		if version != c.Hub {
+			spoke := hub
+			spoke.Version = version
+			c.spokes = append(c.spokes, &spoke)
+		}
+	}
+	return nil
+}
+
+// Scaffold scaffolds the hub and the missing conversions, marks the hub as the storage version and enables the
+// conversion webhook in the CRD. The webhooks of the hub, serving the conversions, are left to set up in main.go.
+func (c *Conversion) Scaffold() error {
+	universe, err := model.NewUniverse(
+		model.WithConfig(&c.config.Config),
+		// TODO: missing model.WithBoilerplate[From], needs boilerplate or path
+		model.WithResource(c.hub, &c.config.Config),
+	)
+	if err != nil {
+		return fmt.Errorf("error building conversion scaffold: %v", err)
+	}
+
+	files := []input.File{&scaffoldv2.Hub{Resource: c.hub}}
+	for _, spoke := range c.spokes {
+		// the conversions scaffolded before are owned by the user
+		if _, err := filesystem.Stat(filepath.Join(c.OutputDir, c.conversionPath(spoke.Version))); err == nil {
+			continue
+		}
+		logging.Path(c.conversionPath(spoke.Version))
+		files = append(files,
+			&scaffoldv2.Conversion{Resource: spoke, Hub: c.hub},
+			&scaffoldv2.ConversionTest{Resource: spoke, Hub: c.hub},
+		)
+	}
+	hubWebhook := webhook.WebhookPath(c.hub, c.config.MultiGroup, c.config.Names())
+	if _, err := filesystem.Stat(filepath.Join(c.OutputDir, hubWebhook)); os.IsNotExist(err) {
+		// the conversion webhook is registered along with the webhooks of the hub
+		logging.Path(hubWebhook)
+		files = append(files, &webhook.Webhook{Resource: c.hub})
+	}
+	caInjection := !c.config.HasSelfSignedCerts()
+	files = append(files, &crdv2.EnableWebhookPatch{Resource: c.hub})
+	if caInjection {
+		files = append(files, &crdv2.EnableCAInjectionPatch{Resource: c.hub})
+	}
+	if err := (&Scaffold{OutputDir: c.OutputDir, Diff: c.Diff}).Execute(universe, input.Options{}, files...); err != nil {
+		return fmt.Errorf("error scaffolding conversion: %v", err)
+	}
+
+	kustomization := filepath.Join(c.OutputDir, c.config.CRDDir(), "kustomization.yaml")
+	if err := crdv2.EnableConversion(kustomization, c.hub, caInjection); err != nil {
+		return fmt.Errorf("error enabling the conversion webhook in %s: %v", kustomization, err)
+	}
+
+	marked, err := scaffoldv2.MarkStorageVersion(c.OutputDir, c.hub, c.config.MultiGroup, c.config.Names())
+	if err != nil {
+		return fmt.Errorf("error marking the storage version: %v", err)
+	}
+	if !marked {
+		logging.Warning(fmt.Sprintf("the markers of %s %s were changed, add %s to them for %s to be the "+
+			"storage version", c.hub.Kind, c.hub.Version, scaffoldv2.StorageVersionMarker, c.hub.Version))
+	}
+	if c.config.SetStorageVersion(c.hub) {
+		if err := c.config.Save(); err != nil {
+			return fmt.Errorf("error updating project file with resource information : %v", err)
+		}
+	}
+	return nil
+}
+
+// HubResource returns the resource of the hub, once validated
+func (c *Conversion) HubResource() *resource.Resource {
+	return c.hub
+}
+
+// conversionPath returns the path of the conversions of the version of the kind relative to the project root
+func (c *Conversion) conversionPath(version string) string {
+	name := fmt.Sprintf("%s_conversion.go", c.config.Names().FileName(c.Resource.Kind))
+	if c.config.MultiGroup {
+		return filepath.Join("apis", c.Resource.Group, version, name)
+	}
+	return filepath.Join("api", version, name)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffold
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/model"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/examples"
+)
+
+// Examples generates the sample programs of the clients of the APIs of the project, one per kind defined by the
+// project, along with the package registering the kinds. The examples are overwritten every time they are
+// generated, from the kinds of the PROJECT file.
+type Examples struct {
+	// OutputDir is the project root, defaults to the current working directory
+	OutputDir string
+
+	config *config.Config
+
+	resources []*resource.Resource
+}
+
+// HasExamples returns whether the project of the directory has generated examples, which are kept in sync with
+// its APIs
+func HasExamples(dir string) bool {
+	_, err := filesystem.Stat(filepath.Join(dir, examples.Dir))
+	return err == nil
+}
+
+// Validate validates that the project has kinds to generate the examples of
+func (e *Examples) Validate() error {
+	if e.config == nil {
+		c, err := config.LoadFrom(config.PathIn(e.OutputDir))
+		if err != nil {
+			return err
+		}
+		e.config = c
+	}
+	q, err := e.config.Query()
+	if err != nil {
+		return err
+	}
+	if !q.SupportsScaffoldMarkers() {
+		return fmt.Errorf("the examples can only be generated for the projects of version 2, the version of this "+
+			"project is %s", e.config.Version)
+	}
+
+	e.resources = nil
+	for _, gvk := range e.config.Resources {
+		// the kinds defined outside of the project, e.g. the built-in ones, have examples of their own
+		if gvk.Package != "" {
+			continue
+		}
+		r := &resource.Resource{
+			Group:              gvk.Group,
+			Version:            gvk.Version,
+			Kind:               gvk.Kind,
+			Resource:           gvk.Plural,
+			AllowReservedGroup: true,
+		}
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("invalid resource %s of the project: %v", gvk.Kind, err)
+		}
+		r.Namespaced = !e.isClusterScoped(r)
+		e.resources = append(e.resources, r)
+	}
+	if len(e.resources) == 0 {
+		return fmt.Errorf("the project has no API to generate the examples of, create one with kubebuilder create api")
+	}
+	return nil
+}
+
+// isClusterScoped returns whether the types of the kind declare it cluster-scoped
+func (e *Examples) isClusterScoped(r *resource.Resource) bool {
+	name := fmt.Sprintf("%s_types.go", e.config.Names().FileName(r.Kind))
+	path := filepath.Join(e.OutputDir, "api", r.Version, name)
+	if e.config.MultiGroup {
+		path = filepath.Join(e.OutputDir, "apis", r.Group, r.Version, name)
+	}
+	content, err := filesystem.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(content), "scope=Cluster")
+}
+
+// Scaffold writes the examples, overwriting the existing ones
+func (e *Examples) Scaffold() error {
+	universe, err := model.NewUniverse(
+		model.WithConfig(&e.config.Config),
+		// TODO: missing model.WithBoilerplate[From], needs boilerplate or path
+	)
+	if err != nil {
+		return fmt.Errorf("error scaffolding the examples: %v", err)
+	}
+
+	files := []input.File{
+		&examples.Scheme{Resources: e.resources},
+		&examples.README{Resources: e.resources},
+	}
+	for _, r := range e.resources {
+		files = append(files, &examples.Program{Resource: r})
+	}
+	if err := (&Scaffold{OutputDir: e.OutputDir}).Execute(universe, input.Options{}, files...); err != nil {
+		return fmt.Errorf("error scaffolding the examples: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package input
+
+// State is the state of the project the conditions of a file are evaluated against
+type State struct {
+	// Path is the path of the file relative to the project root
+	Path string
+
+	// Exists is whether the file already exists
+	Exists bool
+
+	// MultiGroup is whether the project has multiple API groups
+	MultiGroup bool
+
+	// ClusterScoped is whether the resource being scaffolded is cluster-scoped, false if there is none
+	ClusterScoped bool
+}
+
+// Condition returns whether a file is scaffolded given the state of the project. The conditions are evaluated
+// before the template of the file is executed: a file skipped by its conditions isn't rendered, neither written nor
+// passed to the plugins.
+type Condition func(State) bool
+
+// Holds returns whether all the conditions hold
+func Holds(conditions []Condition, s State) bool {
+	for _, c := range conditions {
+		if !c(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// When returns the input of a file scaffolded only if all the conditions hold, e.g.
+// &Finalizers{Input: input.When(input.OnlyIf(finalizer))}
+func When(conditions ...Condition) Input {
+	return Input{Conditions: conditions}
+}
+
+// SkipIf skips the file if skip is set
+func SkipIf(skip bool) Condition {
+	return func(State) bool {
+		return !skip
+	}
+}
+
+// OnlyIf scaffolds the file only if scaffold is set
+func OnlyIf(scaffold bool) Condition {
+	return SkipIf(!scaffold)
+}
+
+// OnlyMultiGroup scaffolds the file only if the project has multiple API groups
+func OnlyMultiGroup() Condition {
+	return func(s State) bool {
+		return s.MultiGroup
+	}
+}
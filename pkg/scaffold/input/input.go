@@ -16,6 +16,10 @@ limitations under the License.
 
 package input
 
+import (
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+)
+
 // IfExistsAction determines what to do if the scaffold file already exists
 type IfExistsAction int
 
@@ -41,6 +45,9 @@ type Input struct {
 	// TemplateBody is the template body to execute
 	TemplateBody string
 
+	// Conditions are the conditions of the scaffolding of the file, it is skipped unless all of them hold
+	Conditions []Condition
+
 	// Boilerplate is the contents of a Boilerplate go header file
 	Boilerplate string
 
@@ -61,6 +68,21 @@ type Input struct {
 
 	// MultiGroup is the multi-group boolean from the PROJECT file
 	MultiGroup bool
+
+	// CRDDir is the directory of the CRD manifests relative to the project root
+	CRDDir string
+
+	// RBACDir is the directory of the RBAC manifests relative to the project root
+	RBACDir string
+
+	// WebhookDir is the directory of the webhook manifests relative to the project root
+	WebhookDir string
+
+	// Naming resolves the names of the types and files of a kind following the policies of the project
+	Naming config.Naming
+
+	// Kubernetes is the row of the compatibility matrix of the minimum Kubernetes version of the project
+	Kubernetes config.Compatibility
 }
 
 // Domain allows a domain to be set on an object
@@ -152,6 +174,49 @@ func (i *Input) SetProjectPath(p string) {
 	}
 }
 
+// ManifestDirs allows the directories of the generated manifests to be set on an object
+type ManifestDirs interface {
+	// SetManifestDirs sets the directories of the CRD, RBAC and webhook manifests
+	SetManifestDirs(crd, rbac, webhook string)
+}
+
+// SetManifestDirs sets the directories of the CRD, RBAC and webhook manifests
+func (i *Input) SetManifestDirs(crd, rbac, webhook string) {
+	if i.CRDDir == "" {
+		i.CRDDir = crd
+	}
+	if i.RBACDir == "" {
+		i.RBACDir = rbac
+	}
+	if i.WebhookDir == "" {
+		i.WebhookDir = webhook
+	}
+}
+
+// Naming allows the naming policies of the project to be set on an object
+type Naming interface {
+	// SetNaming sets the naming policies
+	SetNaming(config.Naming)
+}
+
+// SetNaming sets the naming policies
+func (i *Input) SetNaming(n config.Naming) {
+	i.Naming = n
+}
+
+// Kubernetes allows the compatibility of the manifests with the minimum Kubernetes version to be set on an object
+type Kubernetes interface {
+	// SetKubernetes sets the row of the compatibility matrix
+	SetKubernetes(config.Compatibility)
+}
+
+// SetKubernetes sets the row of the compatibility matrix
+func (i *Input) SetKubernetes(c config.Compatibility) {
+	if i.Kubernetes == (config.Compatibility{}) {
+		i.Kubernetes = c
+	}
+}
+
 // File is a scaffoldable file
 type File interface {
 	// GetInput returns the Input for creating a scaffold file
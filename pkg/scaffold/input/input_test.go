@@ -18,8 +18,30 @@ package input_test
 
 import (
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
 )
 
 var _ = Describe("Input", func() {
 
 })
+
+var _ = Describe("Conditions", func() {
+	It("should hold if all of them hold", func() {
+		Expect(input.Holds(nil, input.State{})).To(BeTrue())
+		Expect(input.Holds([]input.Condition{input.OnlyIf(true), input.SkipIf(false)}, input.State{})).To(BeTrue())
+		Expect(input.Holds([]input.Condition{input.OnlyIf(true), input.SkipIf(true)}, input.State{})).To(BeFalse())
+	})
+
+	It("should only scaffold the files of the multigroup projects", func() {
+		Expect(input.OnlyMultiGroup()(input.State{MultiGroup: true})).To(BeTrue())
+		Expect(input.OnlyMultiGroup()(input.State{})).To(BeFalse())
+	})
+
+	It("should set the conditions of the input", func() {
+		i := input.When(input.OnlyMultiGroup(), input.SkipIf(false))
+		Expect(i.Conditions).To(HaveLen(2))
+		Expect(i.Path).To(BeEmpty())
+	})
+})
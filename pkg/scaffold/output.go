@@ -24,17 +24,20 @@ import (
 	"path/filepath"
 
 	"github.com/spf13/afero"
+
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
 )
 
 // FileWriter is a io wrapper to write files
 type FileWriter struct {
+	// Fs is the filesystem the files are written to, the filesystem of the project if unset
 	Fs afero.Fs
 }
 
 // WriteCloser returns a WriteCloser to write to given path
 func (fw *FileWriter) WriteCloser(path string) (io.Writer, error) {
 	if fw.Fs == nil {
-		fw.Fs = afero.NewOsFs()
+		fw.Fs = filesystem.FS
 	}
 	dir := filepath.Dir(path)
 	err := fw.Fs.MkdirAll(dir, 0700)
@@ -19,12 +19,16 @@ package scaffold
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"sigs.k8s.io/kubebuilder/cmd/util"
+	"sigs.k8s.io/kubebuilder/pkg/logging"
 	"sigs.k8s.io/kubebuilder/pkg/model"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/project"
 	scaffoldv1 "sigs.k8s.io/kubebuilder/pkg/scaffold/v1"
@@ -32,9 +36,13 @@ import (
 	metricsauthv1 "sigs.k8s.io/kubebuilder/pkg/scaffold/v1/metricsauth"
 	scaffoldv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/certmanager"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/expose"
 	managerv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2/manager"
 	metricsauthv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2/metricsauth"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/prometheus"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/quota"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/secretstore"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/tenancy"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/webhook"
 )
 
@@ -43,8 +51,29 @@ const (
 	controllerRuntimeVersion = "v0.4.0"
 	// ControllerTools version to be used in the project
 	controllerToolsVersion = "v0.2.4"
+	// kustomize version the config directory of the project is written for
+	kustomizeVersion = "v3.5.4"
+	// wire version required by the projects injecting the reconcilers and the webhooks with it
+	wireVersion = "v0.4.0"
 )
 
+// DefaultDependencies returns the versions of the dependencies new projects are scaffolded with
+func DefaultDependencies() config.Dependencies {
+	return config.Dependencies{
+		ControllerRuntime: controllerRuntimeVersion,
+		ControllerGen:     controllerToolsVersion,
+		Kustomize:         kustomizeVersion,
+	}
+}
+
+// commandOutput returns the writer of the output of the commands run by the scaffolding, stdout by default
+func commandOutput(w io.Writer) io.Writer {
+	if w == nil {
+		return os.Stdout
+	}
+	return w
+}
+
 type ProjectScaffolder interface {
 	EnsureDependencies() (bool, error)
 	Scaffold() error
@@ -57,6 +86,12 @@ type V1Project struct {
 
 	DepArgs          []string
 	DefinitelyEnsure *bool
+
+	// CommandOutput receives the output of dep ensure and its prompt, stdout if nil
+	CommandOutput io.Writer
+
+	// Diff, if set, receives the unified diffs between the existing files and their templates, see Scaffold.Diff
+	Diff io.Writer
 }
 
 func (p *V1Project) Validate() error {
@@ -71,7 +106,7 @@ func (p *V1Project) Validate() error {
 func (p *V1Project) EnsureDependencies() (bool, error) {
 	if p.DefinitelyEnsure == nil {
 		reader := bufio.NewReader(os.Stdin)
-		fmt.Println("Run `dep ensure` to fetch dependencies (Recommended) [y/n]?")
+		fmt.Fprintln(commandOutput(p.CommandOutput), "Run `dep ensure` to fetch dependencies (Recommended) [y/n]?")
 		if !util.YesNo(reader) {
 			return false, nil
 		}
@@ -82,8 +117,8 @@ func (p *V1Project) EnsureDependencies() (bool, error) {
 	c := exec.Command("dep", "ensure") // #nosec
 	c.Args = append(c.Args, p.DepArgs...)
 	c.Stderr = os.Stderr
-	c.Stdout = os.Stdout
-	fmt.Println(strings.Join(c.Args, " "))
+	c.Stdout = commandOutput(p.CommandOutput)
+	logging.Command(c.Args)
 	return true, c.Run()
 }
 
@@ -91,6 +126,7 @@ func (p *V1Project) Scaffold() error {
 	s := &Scaffold{
 		BoilerplateOptional: true,
 		ConfigOptional:      true,
+		Diff:                p.Diff,
 	}
 
 	universe, err := model.NewUniverse(
@@ -124,7 +160,7 @@ func (p *V1Project) Scaffold() error {
 	// default controller manager image name
 	imgName := "controller:latest"
 
-	s = &Scaffold{}
+	s = &Scaffold{Diff: p.Diff}
 
 	universe, err = model.NewUniverse(
 		model.WithConfig(&p.Project.Config),
@@ -160,28 +196,122 @@ func (p *V1Project) Scaffold() error {
 type V2Project struct {
 	Project     project.Project
 	Boilerplate project.Boilerplate
+
+	// OutputDir is the project root, defaults to the current working directory
+	OutputDir string
+
+	// Expose is the kind of exposure of the metrics endpoint out of the cluster, none if empty
+	Expose string
+
+	// ResourceQuota scaffolds a LimitRange and a ResourceQuota for the namespace of the manager
+	ResourceQuota bool
+
+	// Image is the controller manager image, defaults to controller:latest
+	Image string
+
+	// CommandOutput receives the output of the commands fetching the dependencies, stdout if nil
+	CommandOutput io.Writer
+
+	// Diff, if set, receives the unified diffs between the existing files and their templates, see Scaffold.Diff
+	Diff io.Writer
 }
 
 func (p *V2Project) Validate() error {
-	return nil
+	switch p.Expose {
+	case "", expose.Ingress, expose.Gateway:
+	default:
+		return fmt.Errorf("unknown exposure %q, must be one of %q or %q", p.Expose, expose.Ingress, expose.Gateway)
+	}
+
+	if p.Project.Manifests != nil {
+		// every directory has its own kustomization.yaml
+		dirs := map[string]string{}
+		for _, m := range []struct{ name, dir string }{
+			{"CRD", p.Project.CRDDir()},
+			{"RBAC", p.Project.RBACDir()},
+			{"webhook", p.Project.WebhookDir()},
+		} {
+			name, dir := m.name, m.dir
+			clean := filepath.Clean(dir)
+			outside := clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator))
+			if filepath.IsAbs(dir) || clean == "." || outside {
+				return fmt.Errorf("invalid %s directory %q, must be a directory of the project relative to its root",
+					name, dir)
+			}
+			if other, found := dirs[clean]; found {
+				return fmt.Errorf("the %s and %s manifests can't share the directory %q", other, name, dir)
+			}
+			dirs[clean] = name
+		}
+	}
+
+	switch p.Project.Tenancy {
+	case "", config.NamespaceTenancy:
+	default:
+		return fmt.Errorf("unknown tenancy %q, must be %q", p.Project.Tenancy, config.NamespaceTenancy)
+	}
+
+	switch p.Project.SecretStore {
+	case "", config.CSISecretStore, config.ExternalSecretsStore:
+	default:
+		return fmt.Errorf("unknown secret store %q, must be one of %q or %q", p.Project.SecretStore,
+			config.CSISecretStore, config.ExternalSecretsStore)
+	}
+
+	switch p.Project.MetricsProtection {
+	case "", config.RBACProxyMetricsProtection, config.TLSMetricsProtection:
+	default:
+		return fmt.Errorf("unknown metrics protection %q, must be one of %q or %q", p.Project.MetricsProtection,
+			config.RBACProxyMetricsProtection, config.TLSMetricsProtection)
+	}
+
+	switch p.Project.DependencyInjection {
+	case "", config.WireInjection:
+	default:
+		return fmt.Errorf("unknown dependency injection %q, must be %q", p.Project.DependencyInjection,
+			config.WireInjection)
+	}
+
+	if _, err := config.CompatibilityOf(p.Project.MinKubernetesVersion); err != nil {
+		return err
+	}
+
+	return p.Project.Names().Validate()
 }
 
 func (p *V2Project) EnsureDependencies() (bool, error) {
 	// ensure that we are pinning controller-runtime version
 	// xref: https://github.com/kubernetes-sigs/kubebuilder/issues/997
-	c := exec.Command("go", "get", "sigs.k8s.io/controller-runtime@"+controllerRuntimeVersion) // #nosec
+	version := controllerRuntimeVersion
+	if p.Project.Dependencies != nil {
+		version = p.Project.Dependencies.ControllerRuntime
+	}
+	c := exec.Command("go", "get", "sigs.k8s.io/controller-runtime@"+version) // #nosec
+	c.Dir = p.OutputDir
 	c.Stderr = os.Stderr
-	c.Stdout = os.Stdout
-	fmt.Println(strings.Join(c.Args, " "))
+	c.Stdout = commandOutput(p.CommandOutput)
+	logging.Command(c.Args)
 	err := c.Run()
 	if err != nil {
 		return false, err
 	}
 
 	c = exec.Command("go", "mod", "tidy") // #nosec
+	c.Dir = p.OutputDir
 	c.Stderr = os.Stderr
-	c.Stdout = os.Stdout
-	fmt.Println(strings.Join(c.Args, " "))
+	c.Stdout = commandOutput(p.CommandOutput)
+	logging.Command(c.Args)
+	err = c.Run()
+	if err != nil {
+		return false, err
+	}
+
+	// the module testing the rendering of the manifests has dependencies of its own
+	c = exec.Command("go", "mod", "tidy") // #nosec
+	c.Dir = filepath.Join(p.OutputDir, scaffoldv2.ManifestsDir)
+	c.Stderr = os.Stderr
+	c.Stdout = commandOutput(p.CommandOutput)
+	logging.Command(c.Args)
 	err = c.Run()
 	if err != nil {
 		return false, err
@@ -190,9 +320,19 @@ func (p *V2Project) EnsureDependencies() (bool, error) {
 }
 
 func (p *V2Project) Scaffold() error {
+	if p.Project.Dependencies == nil {
+		dependencies := DefaultDependencies()
+		p.Project.Dependencies = &dependencies
+	}
+	if p.Project.HasWireInjection() && p.Project.Dependencies.Wire == "" {
+		p.Project.Dependencies.Wire = wireVersion
+	}
+
 	s := &Scaffold{
 		BoilerplateOptional: true,
 		ConfigOptional:      true,
+		OutputDir:           p.OutputDir,
+		Diff:                p.Diff,
 	}
 
 	universe, err := model.NewUniverse(
@@ -224,47 +364,138 @@ func (p *V2Project) Scaffold() error {
 	}
 
 	// default controller manager image name
-	imgName := "controller:latest"
+	imgName := p.Image
+	if imgName == "" {
+		imgName = "controller:latest"
+	}
 
-	s = &Scaffold{}
+	s = &Scaffold{OutputDir: p.OutputDir, Diff: p.Diff}
 
 	universe, err = model.NewUniverse(
 		model.WithConfig(&p.Project.Config),
-		model.WithBoilerplateFrom(p.Boilerplate.Path),
+		model.WithBoilerplateFrom(s.path(bpInput.Path)),
 	)
 	if err != nil {
 		return fmt.Errorf("error initializing project: %v", err)
 	}
 
-	return s.Execute(
-		universe,
-		input.Options{ProjectPath: projectInput.Path, BoilerplatePath: bpInput.Path},
+	tlsMetrics := p.Project.HasTLSMetrics()
+	files := []input.File{
 		&project.GitIgnore{},
-		&metricsauthv2.AuthProxyPatch{},
-		&metricsauthv2.AuthProxyService{},
-		&metricsauthv2.ClientClusterRole{},
-		&project.AuthProxyRole{},
-		&project.AuthProxyRoleBinding{},
 		&managerv2.Config{Image: imgName},
-		&scaffoldv2.Main{},
-		&scaffoldv2.GoMod{ControllerRuntimeVersion: controllerRuntimeVersion},
-		&scaffoldv2.Makefile{Image: imgName, ControllerToolsVersion: controllerToolsVersion},
+		&scaffoldv2.Main{Tenancy: p.Project.HasNamespaceTenancy(), Wire: p.Project.HasWireInjection(),
+			Credentials: p.Project.HasSecretStore(), TLSMetrics: tlsMetrics},
+		&scaffoldv2.Events{},
+		&scaffoldv2.EventsTest{},
+		&scaffoldv2.ManifestsGoMod{KustomizeVersion: p.Project.Dependencies.Kustomize},
+		&scaffoldv2.ManifestsTest{},
+		&scaffoldv2.GoMod{ControllerRuntimeVersion: p.Project.Dependencies.ControllerRuntime,
+			WireVersion: p.Project.Dependencies.Wire},
+		&scaffoldv2.Makefile{Image: imgName, ControllerToolsVersion: p.Project.Dependencies.ControllerGen,
+			Wire: p.Project.HasWireInjection()},
 		&scaffoldv2.Dockerfile{},
-		&scaffoldv2.Kustomize{},
+		&scaffoldv2.DockerIgnore{},
+		&scaffoldv2.Kustomize{Expose: p.Expose, Quota: p.ResourceQuota, SecretStore: p.Project.HasSecretStore(),
+			TLSMetrics: tlsMetrics},
 		&scaffoldv2.ManagerWebhookPatch{},
 		&scaffoldv2.ManagerRoleBinding{},
 		&scaffoldv2.LeaderElectionRole{},
 		&scaffoldv2.LeaderElectionRoleBinding{},
-		&scaffoldv2.KustomizeRBAC{},
+		&scaffoldv2.KustomizeRBAC{Tenancy: p.Project.HasNamespaceTenancy(), TLSMetrics: tlsMetrics},
 		&managerv2.Kustomization{},
 		&webhook.Kustomization{},
 		&webhook.KustomizeConfigWebhook{},
 		&webhook.Service{},
 		&webhook.InjectCAPatch{},
-		&prometheus.Kustomization{},
-		&prometheus.ServiceMonitor{},
+		&prometheus.Kustomization{TLSMetrics: tlsMetrics},
+		&prometheus.ServiceMonitor{TLSMetrics: tlsMetrics},
 		&certmanager.CertManager{},
 		&certmanager.Kustomization{},
 		&certmanager.KustomizeConfig{},
+	}
+
+	if tlsMetrics {
+		rbacDir := p.Project.RBACDir()
+		files = append(files,
+			&metricsauthv2.Server{},
+			&metricsauthv2.ServerTest{},
+			&metricsauthv2.MetricsPatch{},
+			&metricsauthv2.Certificate{},
+			&metricsauthv2.Kustomization{},
+			&metricsauthv2.KustomizeConfig{},
+			&metricsauthv2.AuthProxyService{Input: input.Input{Path: filepath.Join(rbacDir, "metrics_service.yaml")}},
+			&metricsauthv2.AuthRole{},
+			&metricsauthv2.AuthRoleBinding{},
+			&metricsauthv2.ClientClusterRole{Input: input.Input{Path: filepath.Join(rbacDir, "metrics_reader_role.yaml")}},
+			&prometheus.KustomizeConfig{},
+		)
+	} else {
+		files = append(files,
+			&metricsauthv2.AuthProxyPatch{},
+			&metricsauthv2.AuthProxyService{},
+			&metricsauthv2.ClientClusterRole{},
+			&project.AuthProxyRole{},
+			&project.AuthProxyRoleBinding{},
+		)
+	}
+
+	switch p.Expose {
+	case expose.Ingress:
+		files = append(files,
+			&expose.Kustomization{Kind: p.Expose},
+			&expose.MetricsIngress{},
+			&expose.WebhookTunnelPatch{},
+		)
+	case expose.Gateway:
+		files = append(files,
+			&expose.Kustomization{Kind: p.Expose},
+			&expose.MetricsGateway{},
+			&expose.KustomizeConfig{},
+			&expose.WebhookTunnelPatch{},
+		)
+	}
+
+	if p.ResourceQuota {
+		files = append(files,
+			&quota.Kustomization{},
+			&quota.LimitRange{},
+			&quota.ResourceQuota{},
+		)
+	}
+
+	if p.Project.HasSecretStore() {
+		files = append(files, secretstore.Files(p.Project.SecretStore)...)
+	}
+
+	if p.Project.HasWireInjection() {
+		files = append(files,
+			&scaffoldv2.Providers{},
+			&scaffoldv2.WireInjector{},
+			&scaffoldv2.WireGen{},
+		)
+	}
+
+	if p.Project.HasNamespaceTenancy() {
+		files = append(files,
+			&tenancy.Client{},
+			&tenancy.ClientTest{},
+			&tenancy.Kustomization{},
+			&tenancy.RoleBinding{},
+		)
+	}
+
+	err = s.Execute(
+		universe,
+		input.Options{ProjectPath: projectInput.Path, BoilerplatePath: bpInput.Path},
+		files...,
 	)
+	if err != nil {
+		return err
+	}
+
+	// the schemes of the group versions defined outside of the project, e.g. monitoring.coreos.com/v1
+	if err := scaffoldv2.RegisterSchemes(p.OutputDir, &p.Project.Config, p.Project.Schemes); err != nil {
+		return fmt.Errorf("error registering the schemes: %v", err)
+	}
+	return nil
 }
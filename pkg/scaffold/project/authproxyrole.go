@@ -32,7 +32,7 @@ type AuthProxyRole struct {
 // GetInput implements input.File
 func (f *AuthProxyRole) GetInput() (input.Input, error) {
 	if f.Path == "" {
-		f.Path = filepath.Join("config", "rbac", "auth_proxy_role.yaml")
+		f.Path = filepath.Join(f.RBACDir, "auth_proxy_role.yaml")
 	}
 	f.TemplateBody = proxyRoleTemplate
 	return f.Input, nil
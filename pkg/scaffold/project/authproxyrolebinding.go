@@ -32,7 +32,7 @@ type AuthProxyRoleBinding struct {
 // GetInput implements input.File
 func (f *AuthProxyRoleBinding) GetInput() (input.Input, error) {
 	if f.Path == "" {
-		f.Path = filepath.Join("config", "rbac", "auth_proxy_role_binding.yaml")
+		f.Path = filepath.Join(f.RBACDir, "auth_proxy_role_binding.yaml")
 	}
 	f.TemplateBody = proxyRoleBindinggTemplate
 	return f.Input, nil
@@ -60,4 +60,7 @@ bin
 *.swp
 *.swo
 *~
+
+# lock held by the kubebuilder commands modifying the project
+.kubebuilder/lock
 `
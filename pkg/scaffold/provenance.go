@@ -0,0 +1,129 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffold
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/model"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+)
+
+const (
+	// MetadataDir is the directory of the project where kubebuilder stores its own files
+	MetadataDir = ".kubebuilder"
+
+	// checksumsFile stores the checksum of the content every file had when it was scaffolded
+	checksumsFile = "checksums.yaml"
+
+	// backupsDir stores the files modified by the user that were overwritten by a forced scaffold
+	backupsDir = "backups"
+)
+
+// Checksum returns the checksum of the provided content
+func Checksum(content []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+}
+
+// ReadChecksums reads the checksums of the content every file of the project in dir had when it was
+// scaffolded, keyed by slash separated path. A project without checksums has none.
+func ReadChecksums(dir string) (map[string]string, error) {
+	checksums := map[string]string{}
+
+	in, err := filesystem.ReadFile(filepath.Join(dir, MetadataDir, checksumsFile))
+	if os.IsNotExist(err) {
+		return checksums, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(in, &checksums); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", filepath.Join(MetadataDir, checksumsFile), err)
+	}
+	return checksums, nil
+}
+
+// WriteChecksums writes the checksums of the scaffolded files of the project in dir
+func WriteChecksums(dir string, checksums map[string]string) error {
+	out, err := yaml.Marshal(checksums)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(dir, MetadataDir, checksumsFile)
+	if err := filesystem.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return filesystem.WriteFile(path, out, 0644)
+}
+
+// backup saves the current content of the file and its diff with the content about to be written if the
+// file was modified since it was scaffolded. Files without checksum are considered modified.
+func (s *Scaffold) backup(file *model.File, checksums map[string]string) error {
+	current, err := filesystem.ReadFile(s.path(file.Path))
+	if err != nil {
+		return err
+	}
+
+	// Nothing is lost if the content doesn't change or if the file wasn't modified by the user
+	if string(current) == file.Contents {
+		return nil
+	}
+	if sum, found := checksums[filepath.ToSlash(file.Path)]; found && sum == Checksum(current) {
+		return nil
+	}
+
+	if s.BackupDir == "" {
+		s.BackupDir = newBackupDir(s.OutputDir, time.Now())
+	}
+	path := s.path(filepath.Join(s.BackupDir, file.Path))
+	if err := filesystem.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := filesystem.WriteFile(path+".bak", current, 0644); err != nil {
+		return err
+	}
+	diff := util.UnifiedDiff(filepath.Join("a", file.Path), filepath.Join("b", file.Path), string(current), file.Contents)
+	if err := filesystem.WriteFile(path+".diff", []byte(diff), 0644); err != nil {
+		return err
+	}
+
+	s.Backups = append(s.Backups, file.Path)
+	return nil
+}
+
+// newBackupDir returns the directory, relative to the project root in dir, of the backups of a run started at
+// now: the directory of .kubebuilder/backups named after the time of the run, suffixed if a run of the same second
+// already backed up its files, so that no run overwrites the backups of another one
+func newBackupDir(dir string, now time.Time) string {
+	name := filepath.Join(MetadataDir, backupsDir, now.UTC().Format("20060102-150405"))
+	backupDir := name
+	for i := 2; ; i++ {
+		if _, err := filesystem.Stat(filepath.Join(dir, backupDir)); os.IsNotExist(err) {
+			return backupDir
+		}
+		backupDir = fmt.Sprintf("%s-%d", name, i)
+	}
+}
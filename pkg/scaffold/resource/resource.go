@@ -39,17 +39,126 @@ type Resource struct {
 	// Kind is the API Kind.
 	Kind string
 
-	// Resource is the API Resource.
+	// Resource is the API Resource, the plural lower case name of the Kind, derived from the Kind if not set,
+	// e.g. to be set for the kinds whose plural isn't regular, such as redises for Redis
 	Resource string
 
 	// ShortNames is the list of resource shortnames.
 	ShortNames []string
 
+	// Categories are the groups of resources the resource belongs to, e.g. all, listed together by
+	// kubectl get <category>
+	Categories []string
+
 	// CreateExampleReconcileBody will create a Deployment in the Reconcile example
 	CreateExampleReconcileBody bool
 
 	// Namespaced is true if the resource is namespaced
 	Namespaced bool
+
+	// AllowReservedGroup allows the groups reserved by Kubernetes, e.g. to scaffold a controller for a built-in kind
+	AllowReservedGroup bool
+
+	// AllowBuiltinKind allows a CRD of the kind of a built-in type in another group, e.g. a Pod of the group ship
+	AllowBuiltinKind bool
+
+	// SharedKind is true if the kind is the kind of a resource of another group of the project tracked before it,
+	// the names the groups share, e.g. of the roles, of the CRD patches and of the webhooks, are then qualified by
+	// the group
+	SharedKind bool
+
+	// NoCRD is true if the kind isn't defined by a CRD of the project, e.g. it is served by an aggregated API
+	// server or its CRD is managed elsewhere
+	NoCRD bool
+}
+
+// IsReservedGroup returns true if group is reserved by Kubernetes: the core group of the built-in kinds and the
+// groups under k8s.io and kubernetes.io, whose CRDs the API server only accepts once approved by the community
+// (https://github.com/kubernetes/enhancements/pull/1111)
+func IsReservedGroup(group string) bool {
+	switch {
+	case group == "core", group == "k8s.io", group == "kubernetes.io":
+		return true
+	default:
+		return strings.HasSuffix(group, ".k8s.io") || strings.HasSuffix(group, ".kubernetes.io")
+	}
+}
+
+// ReservedGroupError returns the error of a resource of a group reserved by Kubernetes
+func ReservedGroupError(group string) error {
+	return fmt.Errorf("group %q is reserved by Kubernetes: core is the group of the built-in kinds and the API "+
+		"server rejects the CRDs of the groups under k8s.io and kubernetes.io unless they are approved "+
+		"(https://github.com/kubernetes/enhancements/pull/1111), choose another group or set "+
+		"--allow-reserved-group if the resource is not a CRD of the project, e.g. a built-in kind", group)
+}
+
+// builtinKinds are the groups of the built-in kinds, for the kinds of the CRDs not to collide with them
+var builtinKinds = map[string][]string{
+	"APIService":                     {"apiregistration"},
+	"Binding":                        {"core"},
+	"CertificateSigningRequest":      {"certificates"},
+	"ClusterRole":                    {"rbac.authorization"},
+	"ClusterRoleBinding":             {"rbac.authorization"},
+	"ComponentStatus":                {"core"},
+	"ConfigMap":                      {"core"},
+	"ControllerRevision":             {"apps"},
+	"CronJob":                        {"batch"},
+	"CSIDriver":                      {"storage"},
+	"CSINode":                        {"storage"},
+	"CustomResourceDefinition":       {"apiextensions"},
+	"DaemonSet":                      {"apps", "extensions"},
+	"Deployment":                     {"apps", "extensions"},
+	"Endpoints":                      {"core"},
+	"EndpointSlice":                  {"discovery"},
+	"Event":                          {"core", "events"},
+	"HorizontalPodAutoscaler":        {"autoscaling"},
+	"Ingress":                        {"networking", "extensions"},
+	"Job":                            {"batch"},
+	"Lease":                          {"coordination"},
+	"LimitRange":                     {"core"},
+	"MutatingWebhookConfiguration":   {"admissionregistration"},
+	"Namespace":                      {"core"},
+	"NetworkPolicy":                  {"networking", "extensions"},
+	"Node":                           {"core"},
+	"PersistentVolume":               {"core"},
+	"PersistentVolumeClaim":          {"core"},
+	"Pod":                            {"core"},
+	"PodDisruptionBudget":            {"policy"},
+	"PodSecurityPolicy":              {"policy", "extensions"},
+	"PodTemplate":                    {"core"},
+	"PriorityClass":                  {"scheduling"},
+	"ReplicaSet":                     {"apps", "extensions"},
+	"ReplicationController":          {"core"},
+	"ResourceQuota":                  {"core"},
+	"Role":                           {"rbac.authorization"},
+	"RoleBinding":                    {"rbac.authorization"},
+	"RuntimeClass":                   {"node"},
+	"Secret":                         {"core"},
+	"Service":                        {"core"},
+	"ServiceAccount":                 {"core"},
+	"StatefulSet":                    {"apps"},
+	"StorageClass":                   {"storage"},
+	"ValidatingWebhookConfiguration": {"admissionregistration"},
+	"VolumeAttachment":               {"storage"},
+}
+
+// BuiltinKindGroups returns the groups of the built-in kind the kind of a resource of the group collides with,
+// nil if the kind isn't built-in or if the group is one of them, i.e. the resource is the built-in kind itself
+func BuiltinKindGroups(group, kind string) []string {
+	groups := builtinKinds[kind]
+	for _, g := range groups {
+		if g == group {
+			return nil
+		}
+	}
+	return groups
+}
+
+// BuiltinKindError returns the error of a CRD whose kind collides with the built-in kind of the groups
+func BuiltinKindError(kind string, groups []string) error {
+	return fmt.Errorf("kind %s collides with the built-in kind of the group %s: kubectl would resolve %s to "+
+		"the built-in kind and the clients would have to qualify it by the group, choose another kind or set "+
+		"--allow-builtin-kind", kind, strings.Join(groups, " and "), flect.Pluralize(strings.ToLower(kind)))
 }
 
 // Validate checks the Resource values to make sure they are valid.
@@ -63,25 +172,54 @@ func (r *Resource) Validate() error {
 	if r.isKindEmpty() {
 		return fmt.Errorf("kind cannot be empty")
 	}
-	// Check if the Group has a valid value for for it
-	if err := IsDNS1123Subdomain(r.Group); err != nil {
-		return fmt.Errorf("group name is invalid: (%v)", err)
+	if err := ValidateGroup(r.Group, r.AllowReservedGroup); err != nil {
+		return err
+	}
+	if err := ValidateVersion(r.Version); err != nil {
+		return err
+	}
+	if err := ValidateKind(r.Kind); err != nil {
+		return err
 	}
-	// Check if the version is a valid value
-	versionMatch := regexp.MustCompile(`^v\d+(alpha\d+|beta\d+)?$`)
-	if !versionMatch.MatchString(r.Version) {
-		return fmt.Errorf(
-			"version must match ^v\\d+(alpha\\d+|beta\\d+)?$ (was %s)", r.Version)
+
+	// Check if the plural is a valid value, it names the CRD along with the group
+	if len(r.Resource) != 0 && !pluralRe.MatchString(r.Resource) {
+		return fmt.Errorf("plural must be a lower case DNS-1035 label of at most 63 characters, "+
+			"e.g. firstmates (was %s)", r.Resource)
 	}
-	// Check if the Kind is a valid value
-	if r.Kind != flect.Pascalize(r.Kind) {
-		return fmt.Errorf("kind must be PascalCase (expected %s was %s)", flect.Pascalize(r.Kind), r.Kind)
+	if !pluralRe.MatchString(r.Plural()) {
+		return fmt.Errorf("the plural %s derived from the kind must be a DNS-1035 label of at most 63 "+
+			"characters, shorten the kind or set the plural", r.Plural())
+	}
+
+	// Check the short names and the categories, the API server requires lower case DNS-1035 labels
+	names := map[string]bool{r.Plural(): true, strings.ToLower(r.Kind): true}
+	for _, name := range r.ShortNames {
+		if !pluralRe.MatchString(name) {
+			return fmt.Errorf("short name must be a lower case DNS-1035 label of at most 63 characters, "+
+				"e.g. fm (was %s)", name)
+		}
+		if names[name] {
+			return fmt.Errorf("short name %s is declared more than once or is the plural or the kind", name)
+		}
+		names[name] = true
+	}
+	categories := map[string]bool{}
+	for _, category := range r.Categories {
+		if !pluralRe.MatchString(category) {
+			return fmt.Errorf("category must be a lower case DNS-1035 label of at most 63 characters, "+
+				"e.g. all (was %s)", category)
+		}
+		if categories[category] {
+			return fmt.Errorf("category %s is declared more than once", category)
+		}
+		categories[category] = true
 	}
 
 	// todo: move it for the proper place since they are not validations and then, should not be here
 	// Add in r.Resource the Kind plural
 	if len(r.Resource) == 0 {
-		r.Resource = flect.Pluralize(strings.ToLower(r.Kind))
+		r.Resource = r.Plural()
 	}
 	// Replace the caracter "-" for "" to allow scaffold the go imports
 	r.GroupImportSafe = strings.Replace(r.Group, "-", "", -1)
@@ -89,6 +227,99 @@ func (r *Resource) Validate() error {
 	return nil
 }
 
+// ValidateGroup checks that the group is a DNS-1123 subdomain starting with a letter, and that it isn't reserved
+// by Kubernetes unless allowReserved is set
+func ValidateGroup(group string, allowReserved bool) error {
+	if err := IsDNS1123Subdomain(group); err != nil {
+		return fmt.Errorf("group name is invalid: (%v), the group is qualified by the domain of the project, "+
+			"e.g. ship or ship.crew", err)
+	}
+	// the group prefixes the Go import aliases of the API, e.g. shipv1
+	if !groupStartRe.MatchString(group) {
+		return fmt.Errorf("group name is invalid: it must start with a letter as it prefixes the Go import "+
+			"aliases of the API, e.g. ship1 rather than 1ship (was %s)", group)
+	}
+	if IsReservedGroup(group) && !allowReserved {
+		return ReservedGroupError(group)
+	}
+	return nil
+}
+
+var groupStartRe = regexp.MustCompile(`^[a-z]`)
+
+var (
+	versionRe = regexp.MustCompile(`^v\d+(alpha\d+|beta\d+)?$`)
+	// versionNumberRe matches the numbers of a version without leading zeros, the API server sorting the versions
+	// by them
+	versionNumberRe = regexp.MustCompile(`^v[1-9]\d*((alpha|beta)[1-9]\d*)?$`)
+)
+
+// ValidateVersion checks that the version is a Kubernetes API version, e.g. v1 or v1beta1
+func ValidateVersion(version string) error {
+	if !versionRe.MatchString(version) {
+		return fmt.Errorf("version must match ^v\\d+(alpha\\d+|beta\\d+)?$ (was %s), "+
+			"e.g. v1, v1beta1 or v2alpha1", version)
+	}
+	if !versionNumberRe.MatchString(version) {
+		return fmt.Errorf("version must be numbered from 1 without leading zeros, e.g. v1 or v1beta1 (was %s)",
+			version)
+	}
+	return nil
+}
+
+var kindRe = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+// ValidateKind checks that the kind is PascalCase, and that it doesn't collide with the list type of another kind
+func ValidateKind(kind string) error {
+	if kind != flect.Pascalize(kind) {
+		return fmt.Errorf("kind must be PascalCase (expected %s was %s)", flect.Pascalize(kind), kind)
+	}
+	if !kindRe.MatchString(kind) {
+		return fmt.Errorf("kind must start with an upper case letter followed by letters and digits as it "+
+			"names the Go type of the API, e.g. FirstMate (was %s)", kind)
+	}
+	if strings.HasSuffix(kind, "List") && kind != "List" {
+		return fmt.Errorf("kind %s collides with the list type scaffolded for the kind %s, choose another kind",
+			kind, strings.TrimSuffix(kind, "List"))
+	}
+	return nil
+}
+
+var pluralRe = regexp.MustCompile(`^[a-z]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+// QualifiedName returns name, the name of a file or an object of the resource in a namespace the groups share,
+// prefixed by the group and sep if the kind is shared, e.g. crew_firstmate for firstmate and _
+func (r *Resource) QualifiedName(name, sep string) string {
+	if !r.SharedKind {
+		return name
+	}
+	return r.Group + sep + name
+}
+
+// WebhookName returns the name of a webhook of the resource, prefix being m for the mutating one and v for the
+// validating one, e.g. mfirstmate.kb.io, qualified by the group if the kind is shared, e.g. mfirstmate.crew.kb.io
+func (r *Resource) WebhookName(prefix string) string {
+	name := prefix + strings.ToLower(r.Kind)
+	if r.SharedKind {
+		name += "." + r.Group
+	}
+	return name + ".kb.io"
+}
+
+// Plural returns the API Resource, the plural derived from the Kind if it isn't set
+func (r *Resource) Plural() string {
+	if len(r.Resource) != 0 {
+		return r.Resource
+	}
+	return flect.Pluralize(strings.ToLower(r.Kind))
+}
+
+// HasDefaultPlural returns true if the API Resource is the plural derived from the Kind, the one controller-gen
+// names the CRD after unless the kind has a resource path marker
+func (r *Resource) HasDefaultPlural() bool {
+	return r.Plural() == flect.Pluralize(strings.ToLower(r.Kind))
+}
+
 // isKindEmpty will return true if the --kind flag do not be informed
 // NOTE: required check if the flags are assuming the other flags as value
 func (r *Resource) isKindEmpty() bool {
@@ -17,6 +17,8 @@ limitations under the License.
 package resource_test
 
 import (
+	"strings"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -83,6 +85,63 @@ var _ = Describe("Resource", func() {
 				`version must match ^v\d+(alpha\d+|beta\d+)?$ (was v1beta1alpha1)`))
 		})
 
+		It("should fail if the Group is reserved by Kubernetes", func() {
+			for _, group := range []string{"core", "k8s.io", "apps.k8s.io", "policy.kubernetes.io"} {
+				instance := &Resource{Group: group, Version: "v1", Kind: "FirstMate"}
+				Expect(instance.Validate()).NotTo(Succeed())
+				Expect(instance.Validate().Error()).To(ContainSubstring(
+					`group "` + group + `" is reserved by Kubernetes`))
+				Expect(instance.Validate().Error()).To(ContainSubstring("--allow-reserved-group"))
+			}
+		})
+
+		It("should succeed if the Group is reserved by Kubernetes and allowed", func() {
+			instance := &Resource{Group: "core", Version: "v1", Kind: "Pod", AllowReservedGroup: true}
+			Expect(instance.Validate()).To(Succeed())
+		})
+
+		It("should succeed if the Group only resembles a reserved one", func() {
+			for _, group := range []string{"corelogic", "k8s", "example.io"} {
+				instance := &Resource{Group: group, Version: "v1", Kind: "FirstMate"}
+				Expect(instance.Validate()).To(Succeed())
+			}
+		})
+
+		It("should fail if the Group does not start with a letter", func() {
+			instance := &Resource{Group: "1crew", Version: "v1", Kind: "FirstMate"}
+			Expect(instance.Validate()).To(MatchError(ContainSubstring("it must start with a letter")))
+		})
+
+		It("should fail if the Version is numbered from 0 or with leading zeros", func() {
+			for _, version := range []string{"v0", "v01", "v1beta0", "v1alpha01"} {
+				instance := &Resource{Group: "crew", Version: version, Kind: "FirstMate"}
+				Expect(instance.Validate()).To(MatchError(ContainSubstring(
+					"version must be numbered from 1 without leading zeros")))
+			}
+			for _, version := range []string{"v1", "v10", "v2alpha1", "v1beta10"} {
+				instance := &Resource{Group: "crew", Version: version, Kind: "FirstMate"}
+				Expect(instance.Validate()).To(Succeed())
+			}
+		})
+
+		It("should fail if the Kind is not a Go identifier or is a list type", func() {
+			instance := &Resource{Group: "crew", Version: "v1", Kind: "1stMate"}
+			Expect(instance.Validate()).To(MatchError(ContainSubstring(
+				"kind must start with an upper case letter followed by letters and digits")))
+
+			instance = &Resource{Group: "crew", Version: "v1", Kind: "FirstMateList"}
+			Expect(instance.Validate()).To(MatchError(ContainSubstring(
+				"kind FirstMateList collides with the list type scaffolded for the kind FirstMate")))
+		})
+
+		It("should fail if the plural derived from the Kind is too long", func() {
+			instance := &Resource{Group: "crew", Version: "v1", Kind: "F" + strings.Repeat("irstmate", 8)}
+			Expect(instance.Validate()).To(MatchError(ContainSubstring("shorten the kind or set the plural")))
+
+			instance.Resource = "firstmates"
+			Expect(instance.Validate()).To(Succeed())
+		})
+
 		It("should fail if the Kind is not specified", func() {
 			instance := &Resource{Group: "crew", Version: "v1"}
 			Expect(instance.Validate()).NotTo(Succeed())
@@ -123,6 +182,46 @@ var _ = Describe("Resource", func() {
 			Expect(instance.Resource).To(Equal("helmswomen"))
 		})
 
+		It("should keep the Resource if it is set", func() {
+			instance := &Resource{Group: "crew", Kind: "Redis", Version: "v1", Resource: "redises"}
+			Expect(instance.Validate()).To(Succeed())
+			Expect(instance.Resource).To(Equal("redises"))
+			Expect(instance.HasDefaultPlural()).To(BeFalse())
+
+			instance = &Resource{Group: "crew", Kind: "FirstMate", Version: "v1", Resource: "firstmates"}
+			Expect(instance.Validate()).To(Succeed())
+			Expect(instance.HasDefaultPlural()).To(BeTrue())
+		})
+
+		It("should fail if the Resource is not a lower case DNS-1035 label", func() {
+			for _, plural := range []string{"Redises", "redis.es", "1redises", "redises-", strings.Repeat("a", 64)} {
+				instance := &Resource{Group: "crew", Kind: "Redis", Version: "v1", Resource: plural}
+				Expect(instance.Validate()).To(MatchError(ContainSubstring("plural must be a lower case DNS-1035 label")))
+			}
+		})
+
+		It("should fail if a short name or a category is not a lower case DNS-1035 label", func() {
+			instance := &Resource{Group: "crew", Kind: "FirstMate", Version: "v1", ShortNames: []string{"fm"},
+				Categories: []string{"all", "crew"}}
+			Expect(instance.Validate()).To(Succeed())
+
+			instance = &Resource{Group: "crew", Kind: "FirstMate", Version: "v1", ShortNames: []string{"FM"}}
+			Expect(instance.Validate()).To(MatchError(ContainSubstring("short name must be a lower case DNS-1035 label")))
+
+			instance = &Resource{Group: "crew", Kind: "FirstMate", Version: "v1", Categories: []string{"crew.io"}}
+			Expect(instance.Validate()).To(MatchError(ContainSubstring("category must be a lower case DNS-1035 label")))
+		})
+
+		It("should fail if a short name or a category is declared more than once", func() {
+			for _, shortNames := range [][]string{{"fm", "fm"}, {"firstmates"}, {"firstmate"}} {
+				instance := &Resource{Group: "crew", Kind: "FirstMate", Version: "v1", ShortNames: shortNames}
+				Expect(instance.Validate()).To(MatchError(ContainSubstring("is declared more than once")))
+			}
+
+			instance := &Resource{Group: "crew", Kind: "FirstMate", Version: "v1", Categories: []string{"all", "all"}}
+			Expect(instance.Validate()).To(MatchError("category all is declared more than once"))
+		})
+
 		It("should allow Cat as a Kind", func() {
 			instance := &Resource{Group: "crew", Kind: "Cat", Version: "v1"}
 			Expect(instance.Validate()).To(Succeed())
@@ -147,4 +246,42 @@ var _ = Describe("Resource", func() {
 			Expect(instance.Resource).To(Equal("myresource"))
 		})
 	})
+
+	Describe("validating a single field", func() {
+		It("should validate the Group", func() {
+			Expect(ValidateGroup("crew", false)).To(Succeed())
+			Expect(ValidateGroup("Crew", false)).NotTo(Succeed())
+			Expect(ValidateGroup("apps.k8s.io", false)).NotTo(Succeed())
+			Expect(ValidateGroup("apps.k8s.io", true)).To(Succeed())
+		})
+
+		It("should validate the Version", func() {
+			Expect(ValidateVersion("v1beta1")).To(Succeed())
+			Expect(ValidateVersion("1")).NotTo(Succeed())
+		})
+
+		It("should validate the Kind", func() {
+			Expect(ValidateKind("FirstMate")).To(Succeed())
+			Expect(ValidateKind("firstMate")).NotTo(Succeed())
+		})
+
+		It("should qualify the shared names of a Kind of several groups", func() {
+			r := &Resource{Group: "crew", Version: "v1", Kind: "FirstMate"}
+			Expect(r.QualifiedName("firstmate", "_")).To(Equal("firstmate"))
+			Expect(r.WebhookName("m")).To(Equal("mfirstmate.kb.io"))
+
+			r.SharedKind = true
+			Expect(r.QualifiedName("firstmate", "_")).To(Equal("crew_firstmate"))
+			Expect(r.WebhookName("v")).To(Equal("vfirstmate.crew.kb.io"))
+		})
+
+		It("should find the built-in kinds a Kind collides with", func() {
+			Expect(BuiltinKindGroups("ship", "Pod")).To(Equal([]string{"core"}))
+			Expect(BuiltinKindGroups("ship", "Ingress")).To(Equal([]string{"networking", "extensions"}))
+			Expect(BuiltinKindGroups("core", "Pod")).To(BeEmpty())
+			Expect(BuiltinKindGroups("extensions", "Ingress")).To(BeEmpty())
+			Expect(BuiltinKindGroups("ship", "FirstMate")).To(BeEmpty())
+			Expect(BuiltinKindError("Pod", []string{"core"}).Error()).To(ContainSubstring("--allow-builtin-kind"))
+		})
+	})
 })
@@ -20,9 +20,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"log"
-	"os"
 	"path/filepath"
 	"strings"
 	"text/template"
@@ -30,9 +28,13 @@ import (
 	"golang.org/x/tools/imports"
 
 	internalconfig "sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/license"
+	"sigs.k8s.io/kubebuilder/pkg/logging"
 	"sigs.k8s.io/kubebuilder/pkg/model"
 	"sigs.k8s.io/kubebuilder/pkg/model/config"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
 )
 
 var options = imports.Options{
@@ -56,6 +58,10 @@ type Scaffold struct {
 	// ConfigPath is the relative path to the project root
 	ConfigPath string
 
+	// OutputDir is the directory where the project root is located, relative paths of the
+	// files and options are resolved against it. Defaults to the current working directory.
+	OutputDir string
+
 	GetWriter func(path string) (io.Writer, error)
 
 	// Plugins is the list of plugins we should allow to transform our generated scaffolding
@@ -68,6 +74,27 @@ type Scaffold struct {
 
 	// ConfigOptional, if true, skips errors reading the project configuration
 	ConfigOptional bool
+
+	// Force, if true, overwrites the existing files that would otherwise make the scaffolding fail.
+	// Files modified since they were scaffolded are backed up before being overwritten.
+	Force bool
+
+	// ForceOnly, if set, restricts Force to the files whose path relative to the project root it accepts, the
+	// existing files it refuses are kept as they are rather than making the scaffolding fail
+	ForceOnly func(path string) bool
+
+	// Backups lists the files that were backed up before being overwritten
+	Backups []string
+
+	// BackupDir is the directory, relative to the project root, the files are backed up to. Each run backs up its
+	// files to its own directory of .kubebuilder/backups, named after the time of the run: one is picked on the
+	// first backup if empty.
+	BackupDir string
+
+	// Diff, if set, receives the unified diffs between the existing files the scaffolding would fail on or skip
+	// and their templates, rather than making the scaffolding fail or skipping them silently, e.g. to compare a
+	// drifted project with the current templates.
+	Diff io.Writer
 }
 
 // Plugin is the interface that a plugin must implement
@@ -77,6 +104,18 @@ type Plugin interface {
 	Pipe(universe *model.Universe) error
 }
 
+// headerPlugins returns the plugins customizing the license header of the scaffolded files, they implement
+// license.HeaderPlugin on top of Plugin
+func (s *Scaffold) headerPlugins() []license.HeaderPlugin {
+	var plugins []license.HeaderPlugin
+	for _, p := range s.Plugins {
+		if h, ok := p.(license.HeaderPlugin); ok {
+			plugins = append(plugins, h)
+		}
+	}
+	return plugins
+}
+
 func (s *Scaffold) setFields(t input.File) {
 	// Inject project configuration into file templates
 	if s.Config != nil {
@@ -96,6 +135,26 @@ func (s *Scaffold) setFields(t input.File) {
 			b.SetMultiGroup(s.Config.MultiGroup)
 		}
 	}
+	// Inject the directories of the manifests, the default ones if the project doesn't configure them
+	if b, ok := t.(input.ManifestDirs); ok {
+		c := config.Config{}
+		if s.Config != nil {
+			c = *s.Config
+		}
+		b.SetManifestDirs(c.CRDDir(), c.RBACDir(), c.WebhookDir())
+	}
+	// Inject the naming policies, the default ones if the project doesn't configure them
+	if b, ok := t.(input.Naming); ok && s.Config != nil {
+		b.SetNaming(s.Config.Names())
+	}
+	// Inject the compatibility of the manifests, the first row of the matrix if the project doesn't configure it
+	if b, ok := t.(input.Kubernetes); ok {
+		c := config.Config{}
+		if s.Config != nil {
+			c = *s.Config
+		}
+		b.SetKubernetes(c.Kubernetes())
+	}
 	// Inject boilerplate into file templates
 	if s.BoilerplatePath != "" {
 		if b, ok := t.(input.BoilerplatePath); ok {
@@ -117,6 +176,14 @@ func validate(file input.File) error {
 	return nil
 }
 
+// path resolves the provided path against the output directory
+func (s *Scaffold) path(path string) string {
+	if s.OutputDir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(s.OutputDir, path)
+}
+
 func (s *Scaffold) defaultOptions(options *input.Options) error {
 	// Use the default Boilerplate path if unset
 	if options.BoilerplatePath == "" {
@@ -128,21 +195,32 @@ func (s *Scaffold) defaultOptions(options *input.Options) error {
 		options.ProjectPath = internalconfig.DefaultPath
 	}
 
+	// Templates receive the project root through the project path
+	if s.ConfigPath == "" {
+		s.ConfigPath = s.OutputDir
+	}
+
 	s.BoilerplatePath = options.BoilerplatePath
 
 	var err error
-	s.Config, err = internalconfig.ReadFrom(options.ProjectPath)
+	s.Config, err = internalconfig.ReadFrom(s.path(options.ProjectPath))
 	if !s.ConfigOptional && err != nil {
 		return err
 	}
 
 	var boilerplateBytes []byte
-	boilerplateBytes, err = ioutil.ReadFile(options.BoilerplatePath) // nolint:gosec
+	boilerplateBytes, err = filesystem.ReadFile(s.path(options.BoilerplatePath))
 	if !s.BoilerplateOptional && err != nil {
 		return err
 	}
 	s.Boilerplate = string(boilerplateBytes)
 
+	if plugins := s.headerPlugins(); len(plugins) > 0 {
+		if s.Boilerplate, err = license.Header(s.Boilerplate, plugins...); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -164,12 +242,14 @@ func (s *Scaffold) Execute(
 	options input.Options,
 	files ...input.File,
 ) error {
+	// Provenance checksums are only tracked when files are written to disk
+	trackProvenance := s.GetWriter == nil
 	if s.GetWriter == nil {
 		s.GetWriter = (&FileWriter{}).WriteCloser
 	}
 	if s.FileExists == nil {
 		s.FileExists = func(path string) bool {
-			_, err := os.Stat(path)
+			_, err := filesystem.Stat(path)
 			return err == nil
 		}
 	}
@@ -183,31 +263,64 @@ func (s *Scaffold) Execute(
 	// Set the repo as the local prefix so that it knows how to group imports
 	imports.LocalPrefix = universe.Config.Repo
 
+	endTemplates := logging.Phase("templates", "files", len(files))
 	for _, f := range files {
-		m, err := s.buildFileModel(f)
+		m, err := s.buildFileModel(universe, f)
 		if err != nil {
 			return err
 		}
+		if m == nil {
+			continue
+		}
 		universe.Files = append(universe.Files, m)
 	}
+	endTemplates()
 
+	endPlugins := logging.Phase("plugins", "plugins", len(s.Plugins))
 	for _, plugin := range s.Plugins {
 		if err := plugin.Pipe(universe); err != nil {
 			return err
 		}
 	}
+	endPlugins()
 
+	// The conditions of the files added by the plugins are evaluated once, before any file is written
 	for _, f := range universe.Files {
-		if err := s.writeFile(f); err != nil {
+		if len(f.Conditions) > 0 {
+			f.Skipped = !input.Holds(f.Conditions, s.state(universe, f.Path))
+		}
+	}
+
+	var checksums map[string]string
+	if trackProvenance {
+		var err error
+		if checksums, err = ReadChecksums(s.OutputDir); err != nil {
 			return err
 		}
 	}
 
+	endWrite := logging.Phase("write", "files", len(universe.Files))
+	for _, f := range universe.Files {
+		if f.Skipped {
+			logging.Debug("skipped by its conditions", "path", f.Path)
+			continue
+		}
+		if err := s.writeFile(f, checksums); err != nil {
+			return err
+		}
+	}
+	endWrite()
+
+	if trackProvenance {
+		return WriteChecksums(s.OutputDir, checksums)
+	}
+
 	return nil
 }
 
-// doFile scaffolds a single file
-func (s *Scaffold) buildFileModel(e input.File) (*model.File, error) {
+// buildFileModel renders a single file, it returns nil if the file is skipped by its conditions. The conditions of
+// the template are evaluated once, here, the model of a rendered file has none left to evaluate.
+func (s *Scaffold) buildFileModel(universe *model.Universe, e input.File) (*model.File, error) {
 	// Set common fields
 	s.setFields(e)
 
@@ -222,8 +335,14 @@ func (s *Scaffold) buildFileModel(e input.File) (*model.File, error) {
 		return nil, err
 	}
 
+	if !input.Holds(i.Conditions, s.state(universe, i.Path)) {
+		logging.Debug("skipped by its conditions", "path", i.Path)
+		return nil, nil
+	}
+
 	m := &model.File{
-		Path: i.Path,
+		Path:           i.Path,
+		IfExistsAction: i.IfExistsAction,
 	}
 
 	b, err := doTemplate(i, e)
@@ -235,19 +354,52 @@ func (s *Scaffold) buildFileModel(e input.File) (*model.File, error) {
 	return m, nil
 }
 
-func (s *Scaffold) writeFile(file *model.File) error {
+// state returns the state of the project the conditions of the file at the path are evaluated against
+func (s *Scaffold) state(universe *model.Universe, path string) input.State {
+	return input.State{
+		Path:          path,
+		Exists:        s.FileExists(s.path(path)),
+		MultiGroup:    s.Config != nil && s.Config.MultiGroup,
+		ClusterScoped: universe.Resource != nil && !universe.Resource.Namespaced,
+	}
+}
+
+// writeFile writes a single file, recording its checksum if checksums are tracked
+func (s *Scaffold) writeFile(file *model.File, checksums map[string]string) error {
+	path := s.path(file.Path)
+
 	// Check if the file to write already exists
-	if s.FileExists(file.Path) {
+	if s.FileExists(path) {
 		switch file.IfExistsAction {
 		case input.Overwrite:
 		case input.Skip:
+			if s.Diff != nil {
+				return s.diff(file, path)
+			}
 			return nil
 		case input.Error:
-			return fmt.Errorf("%s already exists", file.Path)
+			if !s.Force {
+				if s.Diff != nil {
+					return s.diff(file, path)
+				}
+				return fmt.Errorf("%s already exists", file.Path)
+			}
+			if s.ForceOnly != nil && !s.ForceOnly(filepath.ToSlash(file.Path)) {
+				if s.Diff != nil {
+					return s.diff(file, path)
+				}
+				logging.Info("kept the existing file", "path", file.Path)
+				return nil
+			}
+			if checksums != nil {
+				if err := s.backup(file, checksums); err != nil {
+					return fmt.Errorf("unable to back up %s: %v", file.Path, err)
+				}
+			}
 		}
 	}
 
-	f, err := s.GetWriter(file.Path)
+	f, err := s.GetWriter(path)
 	if err != nil {
 		return err
 	}
@@ -259,8 +411,30 @@ func (s *Scaffold) writeFile(file *model.File) error {
 		}()
 	}
 
-	_, err = f.Write([]byte(file.Contents))
+	if _, err = f.Write([]byte(file.Contents)); err != nil {
+		return err
+	}
+
+	if checksums != nil {
+		checksums[filepath.ToSlash(file.Path)] = Checksum([]byte(file.Contents))
+	}
+
+	return nil
+}
 
+// diff writes the unified diff between the existing file at path and its template to Diff, the file is kept as
+// it is
+func (s *Scaffold) diff(file *model.File, path string) error {
+	current, err := filesystem.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	slashPath := filepath.ToSlash(file.Path)
+	diff := util.UnifiedDiff("a/"+slashPath, "b/"+slashPath, string(current), file.Contents)
+	if diff == "" {
+		return nil
+	}
+	_, err = io.WriteString(s.Diff, diff)
 	return err
 }
 
@@ -282,7 +456,7 @@ func doTemplate(i input.Input, e input.File) ([]byte, error) {
 	if filepath.Ext(i.Path) == ".go" {
 		b, err = imports.Process(i.Path, b, &options)
 		if err != nil {
-			fmt.Printf("%s\n", out.Bytes())
+			logging.Debug("unable to format the generated file", "path", i.Path, "content", out.String())
 			return nil, err
 		}
 	}
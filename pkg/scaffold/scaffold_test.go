@@ -17,9 +17,1978 @@ limitations under the License.
 package scaffold_test
 
 import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/license"
+	"sigs.k8s.io/kubebuilder/pkg/model"
+	modelconfig "sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	scaffoldv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/admissionpolicy"
+	crdv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2/crd"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/expose"
+	managerv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2/manager"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/metricsauth"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/prometheus"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/secretstore"
+	webhookv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2/webhook"
 )
 
+type testFile struct {
+	input.Input
+}
+
+func (f *testFile) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "test.yaml")
+	}
+	f.TemplateBody = "domain: {{ .Domain }}\n"
+	f.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+type goFile struct {
+	input.Input
+}
+
+func (f *goFile) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = "test.go"
+	}
+	f.TemplateBody = "{{ .Boilerplate }}\n\npackage test\n"
+	f.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+type spdxPlugin struct {
+	license.SPDX
+}
+
+func (spdxPlugin) Pipe(*model.Universe) error {
+	return nil
+}
+
+type pluginFunc func(*model.Universe) error
+
+func (f pluginFunc) Pipe(u *model.Universe) error {
+	return f(u)
+}
+
 var _ = Describe("Scaffold", func() {
+	var dir string
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "kubebuilder-scaffold")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "PROJECT"),
+			[]byte("version: \"2\"\ndomain: example.org\nrepo: example.org/project\n"), 0644)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	Context("with an output directory", func() {
+		It("should read the configuration and write the files relative to it", func() {
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}
+			Expect(s.Execute(universe, input.Options{}, &testFile{})).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "config", "test.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("domain: example.org\n"))
+		})
+	})
+
+	Context("with manifest directories", func() {
+		It("should write the manifests into them and reference them from the overlay and the Makefile", func() {
+			Expect(ioutil.WriteFile(filepath.Join(dir, "PROJECT"), []byte(`version: "2"
+domain: example.org
+repo: example.org/project
+manifests:
+  crdDir: deploy/crd
+  rbacDir: deploy/rbac
+`), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}
+			Expect(s.Execute(universe, input.Options{},
+				&scaffoldv2.KustomizeRBAC{}, &scaffoldv2.Kustomize{Prefix: "project"}, &scaffoldv2.Makefile{},
+			)).To(Succeed())
+
+			Expect(filepath.Join(dir, "deploy", "rbac", "kustomization.yaml")).To(BeAnExistingFile())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "config", "default", "kustomization.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("- ../../deploy/crd\n- ../../deploy/rbac\n"))
+			Expect(string(content)).To(ContainSubstring("#- ../webhook\n"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "Makefile"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("kustomize build deploy/crd | kubectl apply -f -"))
+			Expect(string(content)).To(ContainSubstring(
+				"output:crd:artifacts:config=deploy/crd/bases output:rbac:artifacts:config=deploy/rbac\n"))
+		})
+	})
+
+	Context("with a minimum Kubernetes version", func() {
+		It("should write the manifests of its row of the compatibility matrix", func() {
+			Expect(ioutil.WriteFile(filepath.Join(dir, "PROJECT"),
+				[]byte("version: \"2\"\ndomain: example.org\nrepo: example.org/project\nminKubernetesVersion: \"1.19\"\n"),
+				0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			r := &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true}
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}
+			Expect(s.Execute(universe, input.Options{},
+				&scaffoldv2.Makefile{}, &managerv2.Config{}, &expose.MetricsIngress{}, &crdv2.EnableWebhookPatch{Resource: r},
+			)).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "Makefile"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(`CRD_OPTIONS ?= "crd:crdVersions=v1"`))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "manager", "manager.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("seccompProfile:\n          type: RuntimeDefault\n"))
+			Expect(string(content)).To(ContainSubstring("ephemeral-storage: 100Mi\n"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "expose", "ingress.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("apiVersion: networking.k8s.io/v1\n"))
+			Expect(string(content)).To(ContainSubstring("pathType: Prefix\n"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "crd", "patches", "webhook_in_captains.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("apiVersion: apiextensions.k8s.io/v1\n"))
+			Expect(string(content)).To(ContainSubstring("    webhook:\n      clientConfig:\n"))
+		})
+
+		It("should refuse the versions out of the compatibility matrix", func() {
+			p := &scaffold.V2Project{}
+			p.Project.Version = "2"
+			p.Project.MinKubernetesVersion = "1.22"
+			Expect(p.Validate()).To(MatchError(ContainSubstring("unsupported Kubernetes version")))
+
+			p.Project.MinKubernetesVersion = "v1.16"
+			Expect(p.Validate()).To(Succeed())
+		})
+	})
+
+	Context("with a secret store", func() {
+		It("should mount the credentials from the store into the manager and load them in main.go", func() {
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			files := append(secretstore.Files(modelconfig.CSISecretStore),
+				&scaffoldv2.Main{Credentials: true}, &scaffoldv2.Kustomize{Prefix: "project", SecretStore: true})
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}
+			Expect(s.Execute(universe, input.Options{}, files...)).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "config", "default", "manager_credentials_patch.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("mountPath: /etc/credentials\n"))
+			Expect(string(content)).To(ContainSubstring("secretProviderClass: credentials\n"))
+			Expect(filepath.Join(dir, "config", "secretstore", "secretproviderclass.yaml")).To(BeAnExistingFile())
+			Expect(filepath.Join(dir, "config", "secretstore", "externalsecret.yaml")).NotTo(BeAnExistingFile())
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "default", "kustomization.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("\n- ../secretstore\n"))
+			Expect(string(content)).To(ContainSubstring("\n- manager_credentials_patch.yaml\n"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "main.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("credentials.Load(credentialsDir)"))
+			Expect(filepath.Join(dir, "credentials", "credentials.go")).To(BeAnExistingFile())
+		})
+
+		It("should refuse the unknown secret stores", func() {
+			p := &scaffold.V2Project{}
+			p.Project.Version = "2"
+			p.Project.SecretStore = "vault"
+			Expect(p.Validate()).To(MatchError(ContainSubstring("unknown secret store")))
+
+			p.Project.SecretStore = modelconfig.ExternalSecretsStore
+			Expect(p.Validate()).To(Succeed())
+		})
+	})
+
+	Context("with metrics served over TLS", func() {
+		It("should serve the metrics from the manager with the certificate of cert-manager", func() {
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}
+			Expect(s.Execute(universe, input.Options{},
+				&scaffoldv2.Main{TLSMetrics: true},
+				&scaffoldv2.Kustomize{Prefix: "project", TLSMetrics: true},
+				&scaffoldv2.KustomizeRBAC{TLSMetrics: true},
+				&prometheus.ServiceMonitor{TLSMetrics: true},
+				&metricsauth.MetricsPatch{},
+				&metricsauth.Certificate{},
+			)).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "main.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("MetricsBindAddress:     \"0\","))
+			Expect(string(content)).To(ContainSubstring(
+				"metricsauth.NewServer(mgr.GetConfig(), metricsAddr, metricsCertDir)"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "default", "kustomization.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("\n- ../metrics\n"))
+			Expect(string(content)).To(ContainSubstring("\n- manager_metrics_patch.yaml\n"))
+			Expect(string(content)).NotTo(ContainSubstring("manager_auth_proxy_patch.yaml"))
+			Expect(string(content)).To(ContainSubstring("\n- name: METRICS_SERVICE_NAME\n"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "rbac", "kustomization.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("\n- metrics_auth_role.yaml\n"))
+			Expect(string(content)).NotTo(ContainSubstring("auth_proxy"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "prometheus", "monitor.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(
+				"serverName: $(METRICS_SERVICE_NAME).$(METRICS_SERVICE_NAMESPACE).svc\n"))
+			Expect(string(content)).To(ContainSubstring("name: " + metricsauth.CertSecretName + "\n"))
+			Expect(string(content)).NotTo(ContainSubstring("insecureSkipVerify"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "metrics", "certificate.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("secretName: " + metricsauth.CertSecretName + " "))
+		})
+
+		It("should refuse the unknown metrics protections", func() {
+			p := &scaffold.V2Project{}
+			p.Project.Version = "2"
+			p.Project.MetricsProtection = "mtls"
+			Expect(p.Validate()).To(MatchError(ContainSubstring("unknown metrics protection")))
+
+			p.Project.MetricsProtection = modelconfig.RBACProxyMetricsProtection
+			Expect(p.Validate()).To(Succeed())
+		})
+	})
+
+	Context("with a validation by the API server", func() {
+		It("should scaffold the policy of the kind and deploy it from config/default", func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "config", "default"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "config", "default", "kustomization.yaml"),
+				[]byte("bases:\n- ../crd\n- ../manager\n"), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			kustomization := &admissionpolicy.Kustomization{}
+			policy := &admissionpolicy.Policy{
+				Resource: &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				Rules: []scaffoldv2.CELRule{{Expression: "!has(object.spec.ships) || object.spec.ships <= 10",
+					Message: "spec.ships must be at most 10", Marker: "+kubebuilder:validation:Maximum=10"}},
+			}
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}
+			Expect(s.Execute(universe, input.Options{}, kustomization, &admissionpolicy.KustomizeConfig{}, policy)).
+				To(Succeed())
+			Expect(policy.Update()).To(Succeed())
+			Expect(kustomization.Update()).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "config", "validation", "captain_policy.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("kind: ValidatingAdmissionPolicy\n"))
+			Expect(string(content)).To(ContainSubstring(
+				"  - expression: \"!has(object.spec.ships) || object.spec.ships <= 10\"\n"))
+			Expect(string(content)).To(ContainSubstring("  policyName: captain-validation\n"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "validation", "kustomization.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("resources:\n- captain_policy.yaml\n"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "default", "kustomization.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("bases:\n- ../crd\n- ../manager\n- ../validation\n"))
+		})
+	})
+
+	Context("with typed clients", func() {
+		BeforeEach(func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "hack"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "hack", "boilerplate.go.txt"), []byte("/*\n*/"), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "Makefile"),
+				[]byte("# Build the docker image\ndocker-build: test\n"), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+			Expect((&scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}).
+				Execute(universe, input.Options{}, &scaffoldv2.Main{})).To(Succeed())
+		})
+
+		It("should mark the kinds +genclient and generate the clients of their versions", func() {
+			for _, r := range []*resource.Resource{
+				{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				{Group: "crew", Version: "v1", Kind: "Admiral"},
+				{Group: "crew", Version: "v2", Kind: "Captain", Namespaced: true},
+			} {
+				api := &scaffold.API{OutputDir: dir, Resource: r, DoResource: true, GenerateClients: true}
+				Expect(api.Validate()).To(Succeed())
+				Expect(api.Scaffold()).To(Succeed())
+			}
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "api", "v1", "captain_types.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("}\n\n// +genclient\n// +kubebuilder:object:root=true\n"))
+			content, err = ioutil.ReadFile(filepath.Join(dir, "api", "v1", "admiral_types.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("// +genclient\n// +genclient:nonNamespaced\n"))
+			Expect(filepath.Join(dir, "api", "v2", "register.go")).To(BeAnExistingFile())
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "hack", "update-codegen.sh"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("INPUTS=\"${REPO}/api/v1,${REPO}/api/v2\"\n"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "Makefile"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strings.Count(string(content), "\nclients: code-generator\n")).To(Equal(1))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "PROJECT"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strings.Count(string(content), "clients: true\n")).To(Equal(3))
+		})
+
+		It("should leave the kinds without typed clients unmarked", func() {
+			api := &scaffold.API{
+				OutputDir:  dir,
+				Resource:   &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource: true,
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Scaffold()).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "api", "v1", "captain_types.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("}\n\n// +kubebuilder:object:root=true\n"))
+			Expect(string(content)).NotTo(ContainSubstring("+genclient"))
+			Expect(filepath.Join(dir, "hack", "update-codegen.sh")).NotTo(BeAnExistingFile())
+		})
+	})
+
+	Context("with an end-to-end test", func() {
+		BeforeEach(func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "hack"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "hack", "boilerplate.go.txt"), []byte("/*\n*/"), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "Makefile"),
+				[]byte("# Build the docker image\ndocker-build: test\n"), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+			Expect((&scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}).
+				Execute(universe, input.Options{}, &scaffoldv2.Main{})).To(Succeed())
+		})
+
+		It("should apply the sample of every kind and wait until it is reconciled", func() {
+			for _, api := range []*scaffold.API{
+				{Resource: &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true}},
+				{Resource: &resource.Resource{Group: "crew", Version: "v1", Kind: "Admiral"}, NoStatusConditions: true},
+			} {
+				api.OutputDir, api.DoResource, api.DoController, api.E2E = dir, true, true, true
+				Expect(api.Validate()).To(Succeed())
+				Expect(api.Scaffold()).To(Succeed())
+			}
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "e2e", "suite_test.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(
+				`CRDDirectoryPaths: []string{filepath.Join("..", "config", "crd", "bases")},`))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "e2e", "captain_test.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(`readSample(t, "../config/samples/crew_v1_captain.yaml", obj)`))
+			Expect(string(content)).To(ContainSubstring("obj.Namespace = ns.Name\n"))
+			Expect(string(content)).To(ContainSubstring("crewv1.IsConditionTrue(obj.Status.Conditions, crewv1.ConditionReady)"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "e2e", "admiral_test.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).NotTo(ContainSubstring("createNamespace"))
+			Expect(string(content)).NotTo(ContainSubstring("IsConditionTrue"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "Makefile"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strings.Count(string(content), "\ntest-e2e: generate fmt vet manifests\n")).To(Equal(1))
+		})
+
+		It("should require the sample of the kind", func() {
+			api := &scaffold.API{
+				OutputDir:    dir,
+				Resource:     &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource:   true,
+				DoController: true,
+				NoSample:     true,
+				E2E:          true,
+			}
+			Expect(api.Validate()).To(MatchError(ContainSubstring("it requires its sample")))
+		})
+	})
+
+	Context("with a built-in kind", func() {
+		It("should scaffold its controller without types and track it along with its package", func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "hack"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "hack", "boilerplate.go.txt"), []byte("/*\n*/"), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+			Expect((&scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}).
+				Execute(universe, input.Options{}, &scaffoldv2.Main{})).To(Succeed())
+
+			api := &scaffold.API{
+				OutputDir:    dir,
+				Resource:     &resource.Resource{Group: "core", Version: "v1", Kind: "Pod", Namespaced: true},
+				DoController: true,
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Scaffold()).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "controllers", "pod_controller.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(`corev1 "k8s.io/api/core/v1"`))
+			Expect(string(content)).To(ContainSubstring("// +kubebuilder:rbac:groups=\"\",resources=pods,"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "main.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).NotTo(ContainSubstring("corev1"))
+			Expect(string(content)).To(ContainSubstring("controllers.NewPodReconciler("))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "PROJECT"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("package: k8s.io/api/core/v1\n"))
+		})
+	})
+
+	Context("with the webhooks of a built-in kind", func() {
+		It("should serve the admission handlers at the paths of their markers", func() {
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			r := &resource.Resource{Group: "core", Version: "v1", Kind: "Pod", Namespaced: true,
+				AllowReservedGroup: true}
+			Expect(r.Validate()).To(Succeed())
+			Expect(webhookv2.ValidateWebhookPath(dir, "/validate-pods")).To(Succeed())
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}
+			Expect(s.Execute(universe, input.Options{}, &webhookv2.BuiltinWebhook{
+				Resource:       r,
+				Defaulting:     true,
+				Validating:     true,
+				ValidatingPath: "/validate-pods",
+			})).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "webhooks", "pod_webhook.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(
+				`mgr.GetWebhookServer().Register("/mutate-core-v1-pod", &webhook.Admission{Handler: &PodDefaulter{}})`))
+			Expect(string(content)).To(ContainSubstring(
+				`mgr.GetWebhookServer().Register("/validate-pods", &webhook.Admission{Handler: &PodValidator{}})`))
+			Expect(string(content)).To(ContainSubstring(
+				"// +kubebuilder:webhook:verbs=create;update,path=/validate-pods,mutating=false,"))
+
+			Expect(webhookv2.ValidateWebhookPath(dir, "/validate-pods")).To(MatchError(
+				"the webhook path /validate-pods is the path of a webhook of webhooks/pod_webhook.go"))
+			Expect(webhookv2.ValidateWebhookPath(dir, "/convert")).To(MatchError(ContainSubstring("conversion")))
+			Expect(webhookv2.ValidateWebhookPath(dir, "validate pods")).To(MatchError(
+				ContainSubstring("invalid webhook path")))
+		})
+	})
+
+	Context("with a kind of several groups", func() {
+		BeforeEach(func() {
+			Expect(ioutil.WriteFile(filepath.Join(dir, "PROJECT"),
+				[]byte("version: \"2\"\ndomain: example.org\nrepo: example.org/project\nmultigroup: true\n"),
+				0644)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(dir, "hack"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "hack", "boilerplate.go.txt"), []byte("/*\n*/"), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+			Expect((&scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}).
+				Execute(universe, input.Options{}, &scaffoldv2.Main{}, &crdv2.Kustomization{})).To(Succeed())
+
+			api := &scaffold.API{
+				OutputDir:  dir,
+				Resource:   &resource.Resource{Group: "infra", Version: "v1", Kind: "Cluster", Namespaced: true},
+				DoResource: true,
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Scaffold()).To(Succeed())
+		})
+
+		It("should qualify the names the groups share by the next groups", func() {
+			api := &scaffold.API{
+				OutputDir:  dir,
+				Resource:   &resource.Resource{Group: "fleet", Version: "v1", Kind: "Cluster", Namespaced: true},
+				DoResource: true,
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Resource.SharedKind).To(BeTrue())
+			Expect(api.Scaffold()).To(Succeed())
+
+			for _, path := range []string{
+				filepath.Join("config", "rbac", "cluster_editor_role.yaml"),
+				filepath.Join("config", "rbac", "fleet_cluster_editor_role.yaml"),
+				filepath.Join("config", "rbac", "fleet_cluster_viewer_role.yaml"),
+				filepath.Join("config", "crd", "patches", "webhook_in_clusters.yaml"),
+				filepath.Join("config", "crd", "patches", "webhook_in_fleet_clusters.yaml"),
+				filepath.Join("config", "crd", "patches", "cainjection_in_fleet_clusters.yaml"),
+			} {
+				Expect(filepath.Join(dir, path)).To(BeAnExistingFile())
+			}
+			content, err := ioutil.ReadFile(filepath.Join(dir, "config", "rbac", "fleet_cluster_editor_role.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("name: fleet-cluster-editor-role\n"))
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "crd", "kustomization.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(
+				"#- patches/webhook_in_clusters.yaml\n#- patches/webhook_in_fleet_clusters.yaml\n"))
+
+			// the first group keeps its names
+			api = &scaffold.API{
+				OutputDir:  dir,
+				Resource:   &resource.Resource{Group: "infra", Version: "v2", Kind: "Cluster", Namespaced: true},
+				DoResource: true,
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Resource.SharedKind).To(BeFalse())
+		})
+
+		It("should refuse the groups imported like another one", func() {
+			api := &scaffold.API{
+				OutputDir:  dir,
+				Resource:   &resource.Resource{Group: "in-fra", Version: "v1", Kind: "Ship", Namespaced: true},
+				DoResource: true,
+			}
+			Expect(api.Validate()).To(MatchError(ContainSubstring(
+				"the group in-fra would be imported as infra<version> like the group infra of the project")))
+		})
+	})
+
+	Context("with a CRD of the cluster", func() {
+		var path string
+
+		BeforeEach(func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "hack"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "hack", "boilerplate.go.txt"), []byte("/*\n*/"), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+			Expect((&scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}).
+				Execute(universe, input.Options{}, &scaffoldv2.Main{})).To(Succeed())
+
+			// kubectl prints the CRD and records the arguments it was run with
+			bin := filepath.Join(dir, "bin")
+			Expect(os.MkdirAll(bin, 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(bin, "kubectl"), []byte(`#!/bin/sh
+echo "$@" > "$(dirname "$0")/args"
+cat <<EOF
+{
+  "kind": "CustomResourceDefinition",
+  "metadata": {"name": "frigates.ship.example.org"},
+  "spec": {
+    "group": "ship.example.org",
+    "names": {"kind": "Frigate", "plural": "frigates", "shortNames": ["fr"]},
+    "scope": "Cluster",
+    "versions": [{
+      "name": "v1beta1", "served": true, "storage": true,
+      "additionalPrinterColumns": [{"name": "Class", "type": "string", "jsonPath": ".spec.class"}],
+      "schema": {"openAPIV3Schema": {"type": "object", "properties": {
+        "spec": {"type": "object", "properties": {"class": {"type": "string"}}}
+      }}}
+    }]
+  }
+}
+EOF
+`), 0755)).To(Succeed())
+			path = os.Getenv("PATH")
+			Expect(os.Setenv("PATH", bin+string(os.PathListSeparator)+path)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			Expect(os.Setenv("PATH", path)).To(Succeed())
+		})
+
+		It("should copy its kind, names and spec to the resource", func() {
+			api := &scaffold.API{
+				OutputDir:   dir,
+				Resource:    &resource.Resource{Namespaced: true},
+				DoResource:  true,
+				FromCluster: "frigates.ship.example.org",
+				Kubeconfig:  "kubeconfig",
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Resource.Group).To(Equal("ship"))
+			Expect(api.Resource.Version).To(Equal("v1beta1"))
+			Expect(api.Resource.Kind).To(Equal("Frigate"))
+			Expect(api.Resource.Namespaced).To(BeFalse())
+			Expect(api.Resource.ShortNames).To(Equal([]string{"fr"}))
+			Expect(api.PrinterColumns).To(Equal([]string{"Class:.spec.class:string"}))
+			Expect(api.Scaffold()).To(Succeed())
+
+			args, err := ioutil.ReadFile(filepath.Join(dir, "bin", "args"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(args)).To(Equal("get customresourcedefinitions.apiextensions.k8s.io " +
+				"frigates.ship.example.org -o json --kubeconfig kubeconfig\n"))
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "api", "v1beta1", "frigate_types.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(
+				"// The fields below were imported from the CRD frigates.ship.example.org of the cluster"))
+			Expect(string(content)).To(ContainSubstring("Class string `json:\"class,omitempty\"`"))
+			Expect(string(content)).To(ContainSubstring("// +kubebuilder:resource:scope=Cluster"))
+		})
+
+		It("should only set the imported fields in the sample", func() {
+			api := &scaffold.API{
+				OutputDir:   dir,
+				Resource:    &resource.Resource{Namespaced: true},
+				DoResource:  true,
+				FromCluster: "frigates.ship.example.org",
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Scaffold()).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "api", "v1beta1", "frigate_types.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).NotTo(ContainSubstring("Foo string"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "samples", "ship_v1beta1_frigate.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(HaveSuffix("spec: {}\n" +
+				"  # TODO(user): set the fields imported from the CRD frigates.ship.example.org of the cluster\n"))
+		})
+
+		It("should refuse the group, kind and versions which don't match the CRD", func() {
+			api := &scaffold.API{
+				OutputDir:   dir,
+				Resource:    &resource.Resource{Kind: "Sloop"},
+				DoResource:  true,
+				FromCluster: "frigates.ship.example.org",
+			}
+			Expect(api.Validate()).To(MatchError(ContainSubstring(
+				"the kind Sloop doesn't match the kind Frigate of the CRD frigates.ship.example.org")))
+
+			api.Resource = &resource.Resource{Version: "v1"}
+			Expect(api.Validate()).To(MatchError(ContainSubstring(
+				"the version v1 isn't served by the CRD frigates.ship.example.org, its versions are v1beta1")))
+
+			api.Resource, api.Schema = &resource.Resource{}, "frigate.schema.json"
+			Expect(api.Validate()).To(MatchError(ContainSubstring("mutually exclusive")))
+		})
+	})
+
+	Context("with the kind of a built-in type in another group", func() {
+		BeforeEach(func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "hack"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "hack", "boilerplate.go.txt"), []byte("/*\n*/"), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+			Expect((&scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}).
+				Execute(universe, input.Options{}, &scaffoldv2.Main{})).To(Succeed())
+		})
+
+		It("should refuse its CRD unless the built-in kind is allowed", func() {
+			api := &scaffold.API{
+				OutputDir:  dir,
+				Resource:   &resource.Resource{Group: "ship", Version: "v1", Kind: "Deployment", Namespaced: true},
+				DoResource: true,
+			}
+			Expect(api.Validate()).To(MatchError(ContainSubstring(
+				"kind Deployment collides with the built-in kind of the group apps and extensions")))
+
+			api.Resource.AllowBuiltinKind = true
+			Expect(api.Validate()).To(Succeed())
+		})
+
+		It("should scaffold its controller without the CRD", func() {
+			api := &scaffold.API{
+				OutputDir:    dir,
+				Resource:     &resource.Resource{Group: "ship", Version: "v1", Kind: "Deployment", Namespaced: true},
+				DoController: true,
+			}
+			Expect(api.Validate()).To(Succeed())
+		})
+	})
+
+	Context("with a new version of a kind", func() {
+		BeforeEach(func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "hack"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "hack", "boilerplate.go.txt"), []byte("/*\n*/"), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+			Expect((&scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}).
+				Execute(universe, input.Options{}, &scaffoldv2.Main{})).To(Succeed())
+
+			api := &scaffold.API{
+				OutputDir:    dir,
+				Resource:     &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource:   true,
+				DoController: true,
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Scaffold()).To(Succeed())
+		})
+
+		It("should convert it to and from the storage version", func() {
+			api := &scaffold.API{
+				OutputDir:  dir,
+				Resource:   &resource.Resource{Group: "crew", Version: "v2", Kind: "Captain", Namespaced: true},
+				DoResource: true,
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Scaffold()).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "api", "v1", "captain_types.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(
+				"// +kubebuilder:object:root=true\n// +kubebuilder:storageversion\n"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "api", "v1", "captain_conversion.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("func (*Captain) Hub() {}"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "api", "v2", "captain_conversion.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("func (src *Captain) ConvertTo(dstRaw conversion.Hub) error"))
+			Expect(string(content)).To(ContainSubstring(`crewv1 "example.org/project/api/v1"`))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "main.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("(&crewv1.Captain{}).SetupWebhookWithManager(mgr)"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "PROJECT"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("  storage: true\n  version: v1\n"))
+		})
+
+		It("should scaffold the conversion webhook converting the spokes to and from the hub", func() {
+			// a version created before its kind was converted has no conversions
+			content, err := ioutil.ReadFile(filepath.Join(dir, "api", "v1", "captain_types.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.MkdirAll(filepath.Join(dir, "api", "v2"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "api", "v2", "captain_types.go"),
+				[]byte(strings.Replace(string(content), "package v1", "package v2", 1)), 0644)).To(Succeed())
+			c, err := config.LoadFrom(config.PathIn(dir))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.AddResource(&resource.Resource{Group: "crew", Version: "v2", Kind: "Captain"})).To(BeTrue())
+			Expect(c.Save()).To(Succeed())
+
+			conversion := &scaffold.Conversion{
+				OutputDir: dir,
+				Resource:  &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				Hub:       "v2",
+			}
+			Expect(conversion.Validate()).To(Succeed())
+			Expect(conversion.HubResource().Version).To(Equal("v2"))
+			Expect(conversion.Scaffold()).To(Succeed())
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "api", "v2", "captain_conversion.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("func (*Captain) Hub() {}"))
+			content, err = ioutil.ReadFile(filepath.Join(dir, "api", "v2", "captain_types.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(
+				"// +kubebuilder:object:root=true\n// +kubebuilder:storageversion\n"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "api", "v1", "captain_conversion.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("func (src *Captain) ConvertTo(dstRaw conversion.Hub) error"))
+			Expect(string(content)).To(ContainSubstring(`crewv2 "example.org/project/api/v2"`))
+			_, err = os.Stat(filepath.Join(dir, "api", "v1", "captain_conversion_test.go"))
+			Expect(err).NotTo(HaveOccurred())
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "crd", "kustomization.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("\n- patches/webhook_in_captains.yaml\n"))
+			Expect(string(content)).To(ContainSubstring("\n- patches/cainjection_in_captains.yaml\n"))
+			Expect(string(content)).NotTo(ContainSubstring("#- patches/webhook_in_captains.yaml"))
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "crd", "patches", "webhook_in_captains.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("strategy: Webhook"))
+
+			_, err = os.Stat(filepath.Join(dir, "api", "v2", "captain_webhook.go"))
+			Expect(err).NotTo(HaveOccurred())
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "PROJECT"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("  storage: true\n  version: v2\n"))
+
+			// scaffolding it again keeps the conversions and the kustomization as they are
+			conversion = &scaffold.Conversion{
+				OutputDir: dir,
+				Resource:  &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+			}
+			Expect(conversion.Validate()).To(Succeed())
+			Expect(conversion.HubResource().Version).To(Equal("v2"))
+			Expect(conversion.Scaffold()).To(Succeed())
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "crd", "kustomization.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strings.Count(string(content), "- patches/webhook_in_captains.yaml")).To(Equal(1))
+		})
+
+		It("should leave the injection of the CA to the manager provisioning a self-signed certificate", func() {
+			content, err := ioutil.ReadFile(filepath.Join(dir, "api", "v1", "captain_types.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.MkdirAll(filepath.Join(dir, "api", "v2"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "api", "v2", "captain_types.go"),
+				[]byte(strings.Replace(string(content), "package v1", "package v2", 1)), 0644)).To(Succeed())
+			c, err := config.LoadFrom(config.PathIn(dir))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.AddResource(&resource.Resource{Group: "crew", Version: "v2", Kind: "Captain"})).To(BeTrue())
+			c.CertProvider = modelconfig.SelfSignedCertProvider
+			Expect(c.Save()).To(Succeed())
+
+			conversion := &scaffold.Conversion{
+				OutputDir: dir,
+				Resource:  &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+			}
+			Expect(conversion.Validate()).To(Succeed())
+			Expect(conversion.Scaffold()).To(Succeed())
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "crd", "kustomization.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("\n- patches/webhook_in_captains.yaml\n"))
+			Expect(string(content)).NotTo(ContainSubstring("\n- patches/cainjection_in_captains.yaml\n"))
+		})
+
+		It("should refuse to change the hub of the scaffolded conversions", func() {
+			api := &scaffold.API{
+				OutputDir:  dir,
+				Resource:   &resource.Resource{Group: "crew", Version: "v2", Kind: "Captain", Namespaced: true},
+				DoResource: true,
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Scaffold()).To(Succeed())
+
+			conversion := &scaffold.Conversion{
+				OutputDir: dir,
+				Resource:  &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				Hub:       "v2",
+			}
+			Expect(conversion.Validate()).To(MatchError(ContainSubstring("the hub can't be changed to v2")))
+
+			conversion.Hub = "v3"
+			Expect(conversion.Validate()).To(MatchError("the hub v3 isn't a version of Captain, its versions are v1, v2"))
+		})
+
+		It("should refuse to scaffold a second controller of the kind", func() {
+			api := &scaffold.API{
+				OutputDir:    dir,
+				Resource:     &resource.Resource{Group: "crew", Version: "v2", Kind: "Captain", Namespaced: true},
+				DoResource:   true,
+				DoController: true,
+			}
+			Expect(api.Validate()).To(MatchError(ContainSubstring("create the version v2 with --controller=false")))
+		})
+	})
+
+	Context("with short names and categories", func() {
+		It("should set them with the other arguments of a single resource marker", func() {
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			r := &resource.Resource{Group: "crew", Version: "v1", Kind: "Redis", Resource: "redises",
+				ShortNames: []string{"rd"}, Categories: []string{"crew", "all"}}
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}
+			Expect(s.Execute(universe, input.Options{},
+				&scaffoldv2.Types{Resource: r}, &scaffoldv2.CRDSample{Resource: r}, &scaffoldv2.CRDViewerRole{Resource: r},
+			)).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "api", "v1", "redis_types.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(
+				"// +kubebuilder:resource:path=redises,scope=Cluster,shortName=rd,categories=crew;all\n"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "samples", "crew_v1_redis.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("kubectl get rd\n"))
+			Expect(string(content)).To(ContainSubstring("kubectl get crew\n"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "rbac", "redis_viewer_role.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("# kubectl get rd is resolved to redises"))
+		})
+	})
+
+	Context("with a reconcile template", func() {
+		BeforeEach(func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "hack"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "hack", "boilerplate.go.txt"), []byte("/*\n*/"), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+			Expect((&scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}).
+				Execute(universe, input.Options{}, &scaffoldv2.Main{})).To(Succeed())
+		})
+
+		It("should create and update the object of the template for every object", func() {
+			api := &scaffold.API{
+				OutputDir:         dir,
+				Resource:          &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource:        true,
+				DoController:      true,
+				UnitTests:         true,
+				ReconcileTemplate: "deployment",
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Scaffold()).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "controllers", "captain_controller.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(`appsv1 "k8s.io/api/apps/v1"`))
+			Expect(string(content)).To(ContainSubstring(
+				"// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete"))
+			Expect(string(content)).To(ContainSubstring("controllerutil.CreateOrUpdate(ctx, r.Client, deployment, func() error {"))
+			Expect(string(content)).To(ContainSubstring(
+				"func (r *CaptainReconciler) mutateDeployment(instance *crewv1.Captain, deployment *appsv1.Deployment) {"))
+			Expect(string(content)).To(ContainSubstring("Owns(&appsv1.Deployment{})"))
+
+			for _, name := range []string{"captain_controller_test.go", "captain_controller_unit_test.go"} {
+				content, err = ioutil.ReadFile(filepath.Join(dir, "controllers", name))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring("appsv1.AddToScheme(s)"))
+			}
+		})
+
+		It("should refuse the unknown templates", func() {
+			api := &scaffold.API{
+				OutputDir:         dir,
+				Resource:          &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource:        true,
+				DoController:      true,
+				ReconcileTemplate: "daemonset",
+			}
+			Expect(api.Validate()).To(MatchError(ContainSubstring(`unknown reconcile template "daemonset"`)))
+		})
+
+		It("should refuse the templates without a controller or for a cluster-scoped resource", func() {
+			api := &scaffold.API{
+				OutputDir:         dir,
+				Resource:          &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource:        true,
+				ReconcileTemplate: "job",
+			}
+			Expect(api.Validate()).To(MatchError(ContainSubstring("when scaffolding the controller")))
+
+			api = &scaffold.API{
+				OutputDir:         dir,
+				Resource:          &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain"},
+				DoResource:        true,
+				DoController:      true,
+				ReconcileTemplate: "job",
+			}
+			Expect(api.Validate()).To(MatchError(ContainSubstring("the resource must be namespaced")))
+		})
+
+		It("should refuse to adopt the kind of the template", func() {
+			api := &scaffold.API{
+				OutputDir:         dir,
+				Resource:          &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource:        true,
+				DoController:      true,
+				Adopt:             []string{"apps/v1/Deployment"},
+				ReconcileTemplate: "deployment",
+			}
+			Expect(api.Validate()).To(MatchError(ContainSubstring("managed by the reconcile template deployment")))
+		})
+	})
+
+	Context("with a long-running operation", func() {
+		BeforeEach(func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "hack"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "hack", "boilerplate.go.txt"), []byte("/*\n*/"), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+			Expect((&scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}).
+				Execute(universe, input.Options{}, &scaffoldv2.Main{})).To(Succeed())
+		})
+
+		It("should track the operation in the status and run its steps in the controller", func() {
+			api := &scaffold.API{
+				OutputDir:    dir,
+				Resource:     &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource:   true,
+				DoController: true,
+				LongRunning:  true,
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Scaffold()).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "api", "v1", "captain_types.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("Operation OperationStatus `json:\"operation,omitempty\"`"))
+			Expect(string(content)).To(ContainSubstring(
+				`// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.operation.phase"`))
+			Expect(filepath.Join(dir, "api", "v1", "operation_types.go")).To(BeAnExistingFile())
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "controllers", "captain_controller.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("result, stepErr := r.runOperation(ctx, &instance)"))
+			Expect(string(content)).To(ContainSubstring("instance.Status.Operation = operation"))
+			Expect(string(content)).To(ContainSubstring("return result, stepErr"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "controllers", "captain_operation.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("func runCaptainOperation(ctx context.Context"))
+			Expect(filepath.Join(dir, "controllers", "captain_operation_test.go")).To(BeAnExistingFile())
+		})
+
+		It("should refuse the operation without the resource and the controller", func() {
+			api := &scaffold.API{
+				OutputDir:   dir,
+				Resource:    &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource:  true,
+				LongRunning: true,
+			}
+			Expect(api.Validate()).To(MatchError(ContainSubstring("--long-running can only be set")))
+		})
+
+		It("should refuse the fields and the columns of the operation", func() {
+			api := &scaffold.API{
+				OutputDir:    dir,
+				Resource:     &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource:   true,
+				DoController: true,
+				LongRunning:  true,
+				Enums:        []string{"status.operation=Started,Stopped"},
+			}
+			Expect(api.Validate()).To(MatchError(ContainSubstring("field status.operation is declared more than once")))
+
+			api.Enums = nil
+			api.PrinterColumns = []string{"Phase:.status.phase:string"}
+			Expect(api.Validate()).To(MatchError(ContainSubstring("printer column Phase is declared more than once")))
+		})
+
+		It("should run the steps of the operation through a circuit breaker", func() {
+			api := &scaffold.API{
+				OutputDir:      dir,
+				Resource:       &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource:     true,
+				DoController:   true,
+				LongRunning:    true,
+				CircuitBreaker: true,
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Scaffold()).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "controllers", "captain_controller.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("Breaker *circuitbreaker.Breaker"))
+			Expect(string(content)).To(ContainSubstring(`circuitbreaker.New("captain", circuitbreaker.DefaultThreshold`))
+			Expect(string(content)).To(ContainSubstring("Type:               circuitbreaker.ConditionDependencyAvailable"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "controllers", "captain_operation.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("err := r.Breaker.Call(func() error {"))
+			Expect(string(content)).To(ContainSubstring("case circuitbreaker.IsOpen(err):"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "controllers", "captain_operation_test.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("func TestCaptainOperationWaitsForOpenCircuit(t *testing.T)"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "internal", "circuitbreaker", "circuitbreaker.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(`Name: "controller_circuit_breaker_state"`))
+			Expect(filepath.Join(dir, "internal", "circuitbreaker", "circuitbreaker_test.go")).To(BeAnExistingFile())
+		})
+
+		It("should refuse the circuit breaker without the controller or the conditions", func() {
+			api := &scaffold.API{
+				OutputDir:      dir,
+				Resource:       &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource:     true,
+				CircuitBreaker: true,
+			}
+			Expect(api.Validate()).To(MatchError(ContainSubstring("--circuit-breaker can only be set")))
+
+			api.DoController = true
+			api.NoStatusConditions = true
+			Expect(api.Validate()).To(MatchError(ContainSubstring("they require --status-conditions")))
+
+			api.NoStatusConditions = false
+			api.Plugins = []scaffold.Plugin{pluginFunc(func(*model.Universe) error { return nil })}
+			Expect(api.Validate()).To(MatchError(ContainSubstring("the controller of --pattern doesn't use it")))
+		})
+	})
+
+	Context("without sample", func() {
+		BeforeEach(func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "hack"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "hack", "boilerplate.go.txt"), []byte("/*\n*/"), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+			Expect((&scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}).
+				Execute(universe, input.Options{}, &scaffoldv2.Main{})).To(Succeed())
+		})
+
+		It("should scaffold the resource without writing its sample", func() {
+			api := &scaffold.API{
+				OutputDir:  dir,
+				Resource:   &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource: true,
+				NoSample:   true,
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Scaffold()).To(Succeed())
+
+			Expect(filepath.Join(dir, "api", "v1", "captain_types.go")).To(BeAnExistingFile())
+			Expect(filepath.Join(dir, "config", "samples")).NotTo(BeAnExistingFile())
+		})
+	})
+
+	Context("with aggregated roles", func() {
+		BeforeEach(func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "hack"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "hack", "boilerplate.go.txt"), []byte("/*\n*/"), 0644)).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(dir, "config", "rbac"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "config", "rbac", "kustomization.yaml"),
+				[]byte("resources:\n- role.yaml\n"), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+			Expect((&scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}).
+				Execute(universe, input.Options{}, &scaffoldv2.Main{})).To(Succeed())
+		})
+
+		It("should label the editor and viewer roles for the aggregation and deploy them", func() {
+			api := &scaffold.API{
+				OutputDir:       dir,
+				Resource:        &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource:      true,
+				RBACAggregation: true,
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Scaffold()).To(Succeed())
+
+			editor, err := ioutil.ReadFile(filepath.Join(dir, "config", "rbac", "captain_editor_role.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(editor)).To(ContainSubstring(`rbac.authorization.k8s.io/aggregate-to-admin: "true"`))
+			Expect(string(editor)).To(ContainSubstring(`rbac.authorization.k8s.io/aggregate-to-edit: "true"`))
+			viewer, err := ioutil.ReadFile(filepath.Join(dir, "config", "rbac", "captain_viewer_role.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(viewer)).To(ContainSubstring(`rbac.authorization.k8s.io/aggregate-to-view: "true"`))
+
+			kustomization, err := ioutil.ReadFile(filepath.Join(dir, "config", "rbac", "kustomization.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(kustomization)).To(Equal(
+				"resources:\n- role.yaml\n- captain_editor_role.yaml\n- captain_viewer_role.yaml\n"))
+		})
+
+		It("should refuse to aggregate the roles without the resource", func() {
+			api := &scaffold.API{
+				OutputDir:       dir,
+				Resource:        &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoController:    true,
+				RBACAggregation: true,
+			}
+			Expect(api.Validate()).To(MatchError(ContainSubstring("--rbac-aggregation can only be set")))
+		})
+	})
+
+	Context("with a conversion server", func() {
+		It("should point the conversion patches of the kind to its service and certificate", func() {
+			r := &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true}
+			for _, conversionServer := range []bool{false, true} {
+				universe, err := model.NewUniverse(model.WithoutBoilerplate)
+				Expect(err).NotTo(HaveOccurred())
+
+				s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}
+				Expect(s.Execute(universe, input.Options{},
+					&crdv2.EnableWebhookPatch{Resource: r, ConversionServer: conversionServer},
+					&crdv2.EnableCAInjectionPatch{Resource: r, ConversionServer: conversionServer},
+				)).To(Succeed())
+			}
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "config", "crd", "patches", "webhook_in_captains.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("name: conversion-service\n"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "crd", "patches", "cainjection_in_captains.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(
+				"$(CONVERSION_CERTIFICATE_NAMESPACE)/$(CONVERSION_CERTIFICATE_NAME)"))
+		})
+	})
+
+	Context("with canary webhooks", func() {
+		It("should deploy the webhooks of the kind through the canary overlay", func() {
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			r := &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true}
+			patch := &webhookv2.CanaryPatch{Resource: r, Validating: true}
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}
+			Expect(s.Execute(universe, input.Options{},
+				&scaffoldv2.Kustomize{Prefix: "project"}, &webhookv2.CanaryKustomization{}, patch,
+			)).To(Succeed())
+			Expect(patch.Update()).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "config", "webhook", "canary", "canary_in_captains.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("- name: vcaptain.kb.io\n  failurePolicy: Ignore\n"))
+			Expect(string(content)).To(ContainSubstring("example.org/webhook-canary: \"true\"\n"))
+			Expect(string(content)).NotTo(ContainSubstring("MutatingWebhookConfiguration"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "webhook", "canary", "kustomization.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(HaveSuffix("patchesStrategicMerge:\n- canary_in_captains.yaml\n"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "default", "kustomization.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("#- ../webhook/canary\n"))
+		})
+	})
+
+	Context("with a webhook certificate readiness check", func() {
+		It("should check and alert on the certificate of the webhook service", func() {
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}
+			Expect(s.Execute(universe, input.Options{},
+				&webhookv2.CertReadiness{Prefix: "project"}, &prometheus.WebhookCertAlert{},
+			)).To(Succeed())
+
+			readiness, err := ioutil.ReadFile(filepath.Join(dir, "webhookcert", "readiness.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(readiness)).To(ContainSubstring(`"project-webhook-service.project-system.svc"`))
+
+			alert, err := ioutil.ReadFile(filepath.Join(dir, "config", "prometheus", "webhook_cert_alert.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			for _, metric := range []string{"webhook_certificate_valid", "webhook_certificate_expiration_timestamp_seconds"} {
+				Expect(string(readiness)).To(ContainSubstring(`Name: "` + metric + `"`))
+				Expect(string(alert)).To(ContainSubstring("expr: " + metric))
+			}
+		})
+
+		It("should keep the existing check and alert", func() {
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, path := range []string{
+				filepath.Join(dir, "webhookcert", "readiness.go"),
+				filepath.Join(dir, "config", "prometheus", "webhook_cert_alert.yaml"),
+			} {
+				Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+				Expect(ioutil.WriteFile(path, []byte("edited\n"), 0644)).To(Succeed())
+			}
+
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}
+			Expect(s.Execute(universe, input.Options{},
+				&webhookv2.CertReadiness{Prefix: "project"}, &prometheus.WebhookCertAlert{},
+			)).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "webhookcert", "readiness.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("edited\n"))
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "prometheus", "webhook_cert_alert.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("edited\n"))
+		})
+	})
+
+	Context("with webhooks of a kind of several versions", func() {
+		It("should call the webhooks of the storage version for the requests to every version", func() {
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			r := &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true}
+			Expect(r.Validate()).To(Succeed())
+			patch := &webhookv2.MatchPolicyPatch{Resource: r, Defaulting: true, Validating: true}
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}
+			Expect(s.Execute(universe, input.Options{},
+				&webhookv2.Kustomization{}, patch,
+				&webhookv2.VersionsTest{Resource: r, Versions: []string{"v2"}, Validating: true},
+			)).To(Succeed())
+			Expect(patch.Update()).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "config", "webhook", "matchpolicy_in_captains.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("- name: mcaptain.kb.io\n  matchPolicy: Equivalent\n"))
+			Expect(string(content)).To(ContainSubstring("- name: vcaptain.kb.io\n  matchPolicy: Equivalent\n"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "config", "webhook", "kustomization.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(HaveSuffix("patchesStrategicMerge:\n- matchpolicy_in_captains.yaml\n"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "api", "v1", "captain_webhook_versions_test.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("package v1_test\n"))
+			Expect(string(content)).To(ContainSubstring(`crewv2 "example.org/project/api/v2"`))
+			Expect(string(content)).To(ContainSubstring(`"../../config/samples/crew_v2_captain.yaml"`))
+			Expect(string(content)).To(ContainSubstring(`"../../config/webhook/manifests.yaml"`))
+		})
+
+		It("should replace the webhook only registering the conversions of the storage version", func() {
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			r := &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true}
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}
+			Expect(s.Execute(universe, input.Options{}, &webhookv2.Webhook{Resource: r})).To(Succeed())
+			Expect(s.Execute(universe, input.Options{}, &webhookv2.Webhook{Resource: r, Validating: true})).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "api", "v1", "captain_webhook.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("func (r *Captain) ValidateCreate() error {"))
+
+			// the defaulting and validating webhooks aren't overwritten
+			Expect(s.Execute(universe, input.Options{}, &webhookv2.Webhook{Resource: r, Defaulting: true})).
+				To(MatchError(ContainSubstring("already exists")))
+		})
+	})
+
+	Context("with a header plugin", func() {
+		It("should write the header of the plugin", func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "hack"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "hack", "boilerplate.go.txt"),
+				[]byte("/*\nCopyright 2020 Example.\n*/\n"), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := &scaffold.Scaffold{OutputDir: dir, Plugins: []scaffold.Plugin{spdxPlugin{"Apache-2.0"}}}
+			Expect(s.Execute(universe, input.Options{}, &goFile{})).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "test.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal(
+				"/*\nCopyright 2020 Example.\n\nSPDX-License-Identifier: Apache-2.0\n*/\n\npackage test\n"))
+		})
+	})
+
+	Context("with conditions", func() {
+		var path string
+
+		BeforeEach(func() {
+			path = filepath.Join(dir, "config", "test.yaml")
+		})
+
+		It("should only write the files whose conditions hold", func() {
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}
+			Expect(s.Execute(universe, input.Options{},
+				&testFile{Input: input.When(input.OnlyMultiGroup())},
+				&goFile{Input: input.When(input.SkipIf(false))},
+			)).To(Succeed())
+			Expect(path).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(dir, "test.go")).To(BeAnExistingFile())
+		})
+
+		It("should skip an existing file rather than fail", func() {
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(path, []byte("domain: modified.org\n"), 0644)).To(Succeed())
+
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}
+			skipIfExists := func(s input.State) bool { return !s.Exists }
+			Expect(s.Execute(universe, input.Options{}, &testFile{Input: input.When(skipIfExists)})).
+				To(Succeed())
+			content, err := ioutil.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("domain: modified.org\n"))
+		})
+
+		It("should skip the files of the cluster-scoped resources", func() {
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+			universe.Resource = &model.Resource{Group: "crew", Version: "v1", Kind: "Admiral"}
+
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}
+			skipIfClusterScoped := func(s input.State) bool { return !s.ClusterScoped }
+			Expect(s.Execute(universe, input.Options{}, &testFile{Input: input.When(skipIfClusterScoped)})).
+				To(Succeed())
+			Expect(path).NotTo(BeAnExistingFile())
+		})
+
+		It("should hide the skipped files from the plugins and skip the files of the plugins", func() {
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			var paths []string
+			plugin := pluginFunc(func(u *model.Universe) error {
+				for _, f := range u.Files {
+					paths = append(paths, f.Path)
+				}
+				u.Files = append(u.Files,
+					&model.File{Path: "multigroup.txt", Conditions: []input.Condition{input.OnlyMultiGroup()}},
+					&model.File{Path: "plugin.txt", Contents: "plugin\n"},
+				)
+				return nil
+			})
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true, Plugins: []scaffold.Plugin{plugin}}
+			Expect(s.Execute(universe, input.Options{},
+				&testFile{Input: input.When(input.OnlyIf(false))},
+				&goFile{},
+			)).To(Succeed())
+			Expect(paths).To(Equal([]string{"test.go"}))
+			Expect(filepath.Join(dir, "multigroup.txt")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(dir, "plugin.txt")).To(BeAnExistingFile())
+		})
+
+		It("should evaluate the conditions of a file once, before writing any file", func() {
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			var states []input.State
+			record := func(s input.State) bool {
+				states = append(states, s)
+				return !s.Exists
+			}
+			plugin := pluginFunc(func(u *model.Universe) error {
+				u.Files = append(u.Files,
+					&model.File{Path: "first.txt", Contents: "first\n"},
+					&model.File{Path: "first.txt", Contents: "second\n", IfExistsAction: input.Overwrite,
+						Conditions: []input.Condition{record}},
+				)
+				return nil
+			})
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true, Plugins: []scaffold.Plugin{plugin}}
+			Expect(s.Execute(universe, input.Options{}, &testFile{Input: input.When(record)})).To(Succeed())
+			Expect(states).To(Equal([]input.State{
+				{Path: filepath.Join("config", "test.yaml")},
+				{Path: "first.txt"},
+			}))
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "first.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("second\n"))
+		})
+	})
+
+	Context("with an unknown project version", func() {
+		It("should refuse to scaffold the API", func() {
+			Expect(ioutil.WriteFile(filepath.Join(dir, "PROJECT"),
+				[]byte("version: \"3\"\ndomain: example.org\nrepo: example.org/project\n"), 0644)).To(Succeed())
+
+			api := &scaffold.API{
+				OutputDir: dir,
+				Resource:  &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+			}
+			Expect(api.Validate()).To(MatchError("unknown project version 3"))
+			Expect(api.Scaffold()).To(MatchError("unknown project version 3"))
+		})
+
+		It("should tell the capabilities of the known project versions only", func() {
+			q, err := modelconfig.Config{Version: modelconfig.Version2}.Query()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(q.SupportsScaffoldMarkers()).To(BeTrue())
+			Expect(q.TracksResources()).To(BeTrue())
+
+			q, err = modelconfig.Config{Version: modelconfig.Version1}.Query()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(q.SupportsScaffoldMarkers()).To(BeFalse())
+			Expect(q.SupportsMultiGroup()).To(BeFalse())
+
+			_, err = modelconfig.Config{Version: "3"}.Query()
+			Expect(err).To(Equal(modelconfig.UnknownVersionError{Version: "3"}))
+		})
+	})
+
+	Context("with registered schemes", func() {
+		BeforeEach(func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "hack"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "hack", "boilerplate.go.txt"), []byte("/*\n*/"), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+			Expect((&scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}).
+				Execute(universe, input.Options{}, &scaffoldv2.Main{},
+					&scaffoldv2.GoMod{ControllerRuntimeVersion: "v0.4.0"})).To(Succeed())
+		})
+
+		It("should parse the known and the explicit group versions", func() {
+			s, err := scaffold.ParseScheme("monitoring.coreos.com/v1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.Package).To(Equal("github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"))
+			Expect(s.Module).To(Equal("github.com/coreos/prometheus-operator"))
+			Expect(s.ImportAlias()).To(Equal("monitoringv1"))
+
+			s, err = scaffold.ParseScheme("example.com/v1alpha1=example.com/operator/api/v1alpha1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.Group).To(Equal("example.com"))
+			Expect(s.Version).To(Equal("v1alpha1"))
+			Expect(s.Package).To(Equal("example.com/operator/api/v1alpha1"))
+			Expect(s.Module).To(BeEmpty())
+
+			_, err = scaffold.ParseScheme("example.com/v1")
+			Expect(err).To(MatchError(ContainSubstring("unknown scheme")))
+			// the versions of cert-manager serving cert-manager.io/v1 don't build with the scaffolded
+			// controller-runtime
+			_, err = scaffold.ParseScheme("cert-manager.io/v1")
+			Expect(err).To(MatchError(ContainSubstring("unknown scheme")))
+			_, err = scaffold.ParseScheme("apps/v1")
+			Expect(err).To(MatchError(ContainSubstring("already registered by clientgoscheme")))
+			_, err = scaffold.ParseScheme("example.com")
+			Expect(err).To(MatchError(ContainSubstring("invalid scheme")))
+		})
+
+		It("should register the group versions in main.go, the suites and go.mod", func() {
+			monitoring, err := scaffold.ParseScheme("monitoring.coreos.com/v1")
+			Expect(err).NotTo(HaveOccurred())
+			s := &scaffold.Schemes{OutputDir: dir, Schemes: []modelconfig.Scheme{monitoring}}
+			Expect(s.Validate()).To(Succeed())
+			Expect(s.Scaffold()).To(Succeed())
+			// registering a group version again is a no-op
+			Expect(s.Validate()).To(Succeed())
+			Expect(s.Scaffold()).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "main.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(
+				`monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"`))
+			Expect(strings.Count(string(content), "_ = monitoringv1.AddToScheme(scheme)")).To(Equal(1))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strings.Count(string(content), "github.com/coreos/prometheus-operator v0.34.0")).To(Equal(1))
+			Expect(strings.Count(string(content),
+				"replace k8s.io/client-go => k8s.io/client-go v0.0.0-20190918160344-1fbdaa4c8d90")).To(Equal(1))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "PROJECT"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("group: monitoring.coreos.com"))
+
+			// the suite of the controllers created afterwards registers the group versions too
+			api := &scaffold.API{
+				OutputDir:    dir,
+				Resource:     &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource:   true,
+				DoController: true,
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Scaffold()).To(Succeed())
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "controllers", "suite_test.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(
+				`monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"`))
+			Expect(string(content)).To(ContainSubstring("err = monitoringv1.AddToScheme(scheme.Scheme)"))
+		})
+
+		It("should qualify by their group the group versions imported like a group version of the project", func() {
+			monitoring, err := scaffold.ParseScheme("monitoring.coreos.com/v1")
+			Expect(err).NotTo(HaveOccurred())
+			s := &scaffold.Schemes{OutputDir: dir, Schemes: []modelconfig.Scheme{monitoring}}
+			Expect(s.Validate()).To(Succeed())
+			Expect(s.Scaffold()).To(Succeed())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "main.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("_ = monitoringv1.AddToScheme(scheme)"))
+
+			// the group monitoring of the project created afterwards takes the alias of the registered one
+			api := &scaffold.API{
+				OutputDir:    dir,
+				Resource:     &resource.Resource{Group: "monitoring", Version: "v1", Kind: "Probe", Namespaced: true},
+				DoResource:   true,
+				DoController: true,
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Scaffold()).To(Succeed())
+
+			for _, path := range []string{"main.go", filepath.Join("controllers", "suite_test.go")} {
+				content, err = ioutil.ReadFile(filepath.Join(dir, path))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(ContainSubstring(
+					`monitoringcoreoscomv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"`))
+				Expect(string(content)).To(ContainSubstring(`monitoringv1 "example.org/project/api/v1"`))
+				Expect(string(content)).To(ContainSubstring("monitoringcoreoscomv1.AddToScheme("))
+				Expect(strings.Count(string(content), "monitoringv1.AddToScheme(")).To(Equal(1))
+			}
+
+			c, err := config.LoadFrom(config.PathIn(dir))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.SchemeImportAlias(monitoring)).To(Equal("monitoringcoreoscomv1"))
+			// a group version registered afterwards with the same first label is qualified too
+			Expect(c.SchemeImportAlias(modelconfig.Scheme{Group: "monitoring.example.org", Version: "v1"})).
+				To(Equal("monitoringexampleorgv1"))
+			Expect(c.SchemeImportAlias(modelconfig.Scheme{Group: "monitoring.example.org", Version: "v2"})).
+				To(Equal("monitoringv2"))
+		})
+
+		It("should rename the colliding imports of the older scaffolds", func() {
+			monitoring, err := scaffold.ParseScheme("monitoring.coreos.com/v1")
+			Expect(err).NotTo(HaveOccurred())
+			api := &scaffold.API{
+				OutputDir:    dir,
+				Resource:     &resource.Resource{Group: "monitoring", Version: "v1", Kind: "Probe", Namespaced: true},
+				DoResource:   true,
+				DoController: true,
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Scaffold()).To(Succeed())
+
+			c, err := config.LoadFrom(config.PathIn(dir))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.AddScheme(monitoring)).To(BeTrue())
+			Expect(c.Save()).To(Succeed())
+			// the older scaffolds imported the registered group version like the one of the project
+			path := filepath.Join(dir, "main.go")
+			content, err := ioutil.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			content = []byte(strings.Replace(string(content), "_ = monitoringv1.AddToScheme(scheme)\n",
+				"_ = monitoringv1.AddToScheme(scheme)\n\t_ = monitoringv1.AddToScheme(scheme)\n", 1))
+			content = []byte(strings.Replace(string(content), "import (\n",
+				"import (\n\tmonitoringv1 \"github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1\"\n", 1))
+			Expect(ioutil.WriteFile(path, content, 0644)).To(Succeed())
+
+			renamed, err := scaffoldv2.FixImportAliases(dir, &c.Config, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(renamed).To(Equal([]string{"main.go"}))
+			renamed, err = scaffoldv2.FixImportAliases(dir, &c.Config, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(renamed).To(Equal([]string{"main.go"}))
+
+			content, err = ioutil.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(
+				`monitoringcoreoscomv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"`))
+			Expect(strings.Count(string(content), "_ = monitoringv1.AddToScheme(scheme)")).To(Equal(1))
+			Expect(strings.Count(string(content), "_ = monitoringcoreoscomv1.AddToScheme(scheme)")).To(Equal(1))
+
+			// the files are left alone once renamed
+			renamed, err = scaffoldv2.FixImportAliases(dir, &c.Config, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(renamed).To(BeEmpty())
+		})
+	})
+
+	Context("with a dry run", func() {
+		BeforeEach(func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "hack"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "hack", "boilerplate.go.txt"), []byte("/*\n*/"), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+			Expect((&scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}).
+				Execute(universe, input.Options{}, &scaffoldv2.Main{})).To(Succeed())
+
+			filesystem.DryRun()
+		})
+
+		AfterEach(func() {
+			filesystem.Reset()
+		})
+
+		It("should list the files it would write without writing them", func() {
+			project, err := ioutil.ReadFile(filepath.Join(dir, "PROJECT"))
+			Expect(err).NotTo(HaveOccurred())
+			main, err := ioutil.ReadFile(filepath.Join(dir, "main.go"))
+			Expect(err).NotTo(HaveOccurred())
+
+			api := &scaffold.API{
+				OutputDir:    dir,
+				Resource:     &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource:   true,
+				DoController: true,
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Scaffold()).To(Succeed())
+
+			changes, err := filesystem.Changes()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changes).To(ContainElement(filesystem.Change{
+				Path: filepath.Join(dir, "PROJECT"), Action: filesystem.Modify}))
+			Expect(changes).To(ContainElement(filesystem.Change{
+				Path: filepath.Join(dir, "main.go"), Action: filesystem.Modify}))
+			Expect(changes).To(ContainElement(filesystem.Change{
+				Path: filepath.Join(dir, "api", "v1", "captain_types.go"), Action: filesystem.Create}))
+			Expect(changes).To(ContainElement(filesystem.Change{
+				Path: filepath.Join(dir, "controllers", "captain_controller.go"), Action: filesystem.Create}))
+
+			// the files written by the dry run are read back by the scaffolds
+			content, err := filesystem.ReadFile(filepath.Join(dir, "main.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("CaptainReconciler"))
+
+			Expect(filepath.Join(dir, "api", "v1", "captain_types.go")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(dir, "controllers")).NotTo(BeAnExistingFile())
+			content, err = ioutil.ReadFile(filepath.Join(dir, "PROJECT"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(content).To(Equal(project))
+			content, err = ioutil.ReadFile(filepath.Join(dir, "main.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(content).To(Equal(main))
+		})
+	})
+
+	Context("with examples", func() {
+		BeforeEach(func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "hack"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "hack", "boilerplate.go.txt"), []byte("/*\n*/"), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+			Expect((&scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}).
+				Execute(universe, input.Options{}, &scaffoldv2.Main{})).To(Succeed())
+		})
+
+		It("should generate a program per kind and keep them in sync with the APIs", func() {
+			api := &scaffold.API{
+				OutputDir:  dir,
+				Resource:   &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource: true,
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Scaffold()).To(Succeed())
+			Expect(scaffold.HasExamples(dir)).To(BeFalse())
+
+			e := &scaffold.Examples{OutputDir: dir}
+			Expect(e.Validate()).To(Succeed())
+			Expect(e.Scaffold()).To(Succeed())
+			Expect(scaffold.HasExamples(dir)).To(BeTrue())
+
+			content, err := ioutil.ReadFile(filepath.Join(dir, "examples", "client-go", "scheme", "scheme.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(`crewv1 "example.org/project/api/v1"`))
+			Expect(string(content)).To(ContainSubstring("_ = crewv1.AddToScheme(Scheme)"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "examples", "client-go", "crew_v1_captain", "main.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("// Code generated by kubebuilder generate examples. DO NOT EDIT."))
+			Expect(string(content)).To(ContainSubstring("obj := &crewv1.Captain{"))
+			Expect(string(content)).To(ContainSubstring("c.List(ctx, list, client.InNamespace(namespace))"))
+
+			api = &scaffold.API{
+				OutputDir:  dir,
+				Resource:   &resource.Resource{Group: "crew", Version: "v1", Kind: "Admiral"},
+				DoResource: true,
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Scaffold()).To(Succeed())
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "examples", "client-go", "crew_v1_admiral", "main.go"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(`run(context.Background(), "", *name, *cleanup)`))
+			Expect(string(content)).NotTo(ContainSubstring("client.InNamespace"))
+
+			content, err = ioutil.ReadFile(filepath.Join(dir, "examples", "client-go", "README.md"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(
+				"go run ./examples/client-go/crew_v1_captain -namespace default -name captain-example\n" +
+					"go run ./examples/client-go/crew_v1_admiral -name admiral-example\n"))
+		})
+
+		It("should refuse a project without APIs", func() {
+			e := &scaffold.Examples{OutputDir: dir}
+			Expect(e.Validate()).To(MatchError(ContainSubstring("the project has no API")))
+		})
+	})
+
+	Context("with force restricted to categories of files", func() {
+		BeforeEach(func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "hack"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "hack", "boilerplate.go.txt"), []byte("/*\n*/"), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+			Expect((&scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}).
+				Execute(universe, input.Options{}, &scaffoldv2.Main{})).To(Succeed())
+		})
+
+		It("should only overwrite the files of the categories", func() {
+			api := &scaffold.API{
+				OutputDir:    dir,
+				Resource:     &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource:   true,
+				DoController: true,
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Scaffold()).To(Succeed())
+
+			typesPath := filepath.Join(dir, "api", "v1", "captain_types.go")
+			controllerPath := filepath.Join(dir, "controllers", "captain_controller.go")
+			Expect(ioutil.WriteFile(typesPath, []byte("package v1\n"), 0644)).To(Succeed())
+			Expect(ioutil.WriteFile(controllerPath, []byte("package controllers\n"), 0644)).To(Succeed())
+
+			api = &scaffold.API{
+				OutputDir:    dir,
+				Resource:     &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource:   true,
+				DoController: true,
+				Force:        true,
+				ForceOnly:    []string{scaffold.ForceController},
+			}
+			Expect(api.Validate()).To(Succeed())
+			Expect(api.Scaffold()).To(Succeed())
+
+			content, err := ioutil.ReadFile(typesPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("package v1\n"))
+
+			content, err = ioutil.ReadFile(controllerPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("func (r *CaptainReconciler) Reconcile("))
+		})
+
+		It("should refuse the unknown categories", func() {
+			api := &scaffold.API{
+				OutputDir:    dir,
+				Resource:     &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource:   true,
+				DoController: true,
+				Force:        true,
+				ForceOnly:    []string{"webhooks"},
+			}
+			Expect(api.Validate()).To(MatchError(ContainSubstring(`unknown category of files to overwrite "webhooks"`)))
+		})
+
+		It("should refuse the categories without force", func() {
+			api := &scaffold.API{
+				OutputDir:    dir,
+				Resource:     &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain", Namespaced: true},
+				DoResource:   true,
+				DoController: true,
+				ForceOnly:    []string{scaffold.ForceTypes},
+			}
+			Expect(api.Validate()).To(MatchError(ContainSubstring("without forcing the scaffolding")))
+		})
+	})
+
+	Context("with force", func() {
+		var path string
+
+		BeforeEach(func() {
+			path = filepath.Join(dir, "config", "test.yaml")
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+			Expect((&scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}).
+				Execute(universe, input.Options{}, &testFile{})).To(Succeed())
+		})
+
+		It("should fail if the file exists and force is not set", func() {
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true}
+			Expect(s.Execute(universe, input.Options{}, &testFile{})).NotTo(Succeed())
+		})
+
+		It("should print the differences with the existing files instead of failing", func() {
+			Expect(ioutil.WriteFile(path, []byte("domain: modified.org\n"), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			var diff bytes.Buffer
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true, Diff: &diff}
+			Expect(s.Execute(universe, input.Options{}, &testFile{})).To(Succeed())
+			Expect(diff.String()).To(Equal(`--- a/config/test.yaml
++++ b/config/test.yaml
+@@ -1 +1 @@
+-domain: modified.org
++domain: example.org
+`))
+
+			content, err := ioutil.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("domain: modified.org\n"))
+		})
+
+		It("should overwrite unmodified files without backing them up", func() {
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true, Force: true}
+			Expect(s.Execute(universe, input.Options{}, &testFile{})).To(Succeed())
+			Expect(s.Backups).To(BeEmpty())
+		})
+
+		It("should keep the existing files refused by force only", func() {
+			Expect(ioutil.WriteFile(path, []byte("domain: modified.org\n"), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true, Force: true,
+				ForceOnly: func(path string) bool { return !strings.HasPrefix(path, "config/") }}
+			Expect(s.Execute(universe, input.Options{}, &testFile{})).To(Succeed())
+			Expect(s.Backups).To(BeEmpty())
+
+			content, err := ioutil.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("domain: modified.org\n"))
+		})
+
+		It("should back up modified files before overwriting them", func() {
+			Expect(ioutil.WriteFile(path, []byte("domain: modified.org\n"), 0644)).To(Succeed())
+
+			universe, err := model.NewUniverse(model.WithoutBoilerplate)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true, Force: true}
+			Expect(s.Execute(universe, input.Options{}, &testFile{})).To(Succeed())
+			Expect(s.Backups).To(Equal([]string{filepath.Join("config", "test.yaml")}))
+
+			content, err := ioutil.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("domain: example.org\n"))
+
+			Expect(s.BackupDir).To(HavePrefix(filepath.Join(scaffold.MetadataDir, "backups") + string(filepath.Separator)))
+			backupPath := filepath.Join(dir, s.BackupDir, "config", "test.yaml")
+			content, err = ioutil.ReadFile(backupPath + ".bak")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("domain: modified.org\n"))
+
+			content, err = ioutil.ReadFile(backupPath + ".diff")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal(`--- a/config/test.yaml
++++ b/config/test.yaml
+@@ -1 +1 @@
+-domain: modified.org
++domain: example.org
+`))
+		})
+
+		It("should back up the files of each forced scaffold to its own directory", func() {
+			var backupDirs []string
+			for _, domain := range []string{"first.org", "second.org"} {
+				Expect(ioutil.WriteFile(path, []byte("domain: "+domain+"\n"), 0644)).To(Succeed())
+
+				universe, err := model.NewUniverse(model.WithoutBoilerplate)
+				Expect(err).NotTo(HaveOccurred())
+
+				s := &scaffold.Scaffold{OutputDir: dir, BoilerplateOptional: true, Force: true}
+				Expect(s.Execute(universe, input.Options{}, &testFile{})).To(Succeed())
+				Expect(s.Backups).To(Equal([]string{filepath.Join("config", "test.yaml")}))
+				backupDirs = append(backupDirs, s.BackupDir)
+			}
+			Expect(backupDirs[0]).NotTo(Equal(backupDirs[1]))
 
+			for i, domain := range []string{"first.org", "second.org"} {
+				content, err := ioutil.ReadFile(filepath.Join(dir, backupDirs[i], "config", "test.yaml.bak"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("domain: " + domain + "\n"))
+			}
+		})
+	})
 })
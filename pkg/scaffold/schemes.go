@@ -0,0 +1,149 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffold
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/internal/config"
+	modelconfig "sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+	scaffoldv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2"
+)
+
+// knownSchemes are the API group versions of the operators commonly used by the controllers, along with the
+// package of their types and its module, indexed by group version. The modules are pinned to the versions built
+// against the Kubernetes release of the scaffolded controller-runtime (1.16): the versions of cert-manager serving
+// cert-manager.io/v1 require client-go 0.19, hence cert-manager isn't known.
+var knownSchemes = map[string]modelconfig.Scheme{
+	"monitoring.coreos.com/v1": {
+		Group:         "monitoring.coreos.com",
+		Version:       "v1",
+		Package:       "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1",
+		Module:        "github.com/coreos/prometheus-operator",
+		ModuleVersion: "v0.34.0",
+		// prometheus-operator requires k8s.io/client-go v12.0.0+incompatible, replaced by its own go.mod only
+		Replace: map[string]string{
+			"k8s.io/client-go": "v0.0.0-20190918160344-1fbdaa4c8d90",
+		},
+	},
+}
+
+// KnownSchemes returns the sorted group versions which can be registered without their package
+func KnownSchemes() []string {
+	known := make([]string, 0, len(knownSchemes))
+	for gv := range knownSchemes {
+		known = append(known, gv)
+	}
+	sort.Strings(known)
+	return known
+}
+
+var schemeVersionRe = regexp.MustCompile(`^v\d+(alpha\d+|beta\d+)?$`)
+
+// ParseScheme parses an API group version defined outside of the project to register in its schemes, either a
+// known one, e.g. monitoring.coreos.com/v1, or one followed by the go package of its types, e.g.
+// example.com/v1=example.com/operator/api/v1
+func ParseScheme(spec string) (modelconfig.Scheme, error) {
+	gv, pkg := spec, ""
+	if i := strings.Index(spec, "="); i >= 0 {
+		gv, pkg = spec[:i], spec[i+1:]
+	}
+
+	parts := strings.Split(gv, "/")
+	if len(parts) != 2 || parts[0] == "" || !schemeVersionRe.MatchString(parts[1]) {
+		return modelconfig.Scheme{}, fmt.Errorf("invalid scheme %q, must be an API group version, e.g. "+
+			"monitoring.coreos.com/v1, optionally followed by the go package of its types, e.g. "+
+			"example.com/v1=example.com/operator/api/v1", spec)
+	}
+	group, version := parts[0], parts[1]
+	// apiextensions.k8s.io and metrics.k8s.io aren't in k8s.io/api, clientgoscheme doesn't register them
+	if util.IsBuiltinGroup(group) && !strings.HasPrefix(group, "apiextensions") &&
+		!strings.HasPrefix(group, "metrics") {
+		return modelconfig.Scheme{}, fmt.Errorf("the types of the built-in API group %s are already registered "+
+			"by clientgoscheme", group)
+	}
+	if !strings.Contains(group, ".") {
+		return modelconfig.Scheme{}, fmt.Errorf("invalid scheme %q, the API group must be qualified by its "+
+			"domain, e.g. cert-manager.io", spec)
+	}
+
+	if pkg == "" {
+		known, found := knownSchemes[gv]
+		if !found {
+			return modelconfig.Scheme{}, fmt.Errorf("unknown scheme %q, set the go package of its types, e.g. "+
+				"%s=example.com/operator/api/%s, or use one of %s", spec, gv, version,
+				strings.Join(KnownSchemes(), ", "))
+		}
+		return known, nil
+	}
+	return modelconfig.Scheme{Group: group, Version: version, Package: pkg}, nil
+}
+
+// Schemes registers the types of API group versions defined outside of the project, e.g. by the CRDs of another
+// operator, in the schemes of main.go and of the suites of the controllers, and requires the modules of their
+// packages in go.mod. The group versions are recorded in the PROJECT file, and registered in the suites of the
+// controllers created afterwards.
+type Schemes struct {
+	// OutputDir is the project root, defaults to the current working directory
+	OutputDir string
+
+	// Schemes are the group versions to register, see ParseScheme
+	Schemes []modelconfig.Scheme
+
+	config *config.Config
+}
+
+// Validate validates that the project supports the registration of the group versions
+func (s *Schemes) Validate() error {
+	if s.config == nil {
+		c, err := config.LoadFrom(config.PathIn(s.OutputDir))
+		if err != nil {
+			return err
+		}
+		s.config = c
+	}
+	q, err := s.config.Query()
+	if err != nil {
+		return err
+	}
+	if !q.SupportsScaffoldMarkers() {
+		return fmt.Errorf("the schemes can only be registered in the projects of version 2, the version of this "+
+			"project is %s", s.config.Version)
+	}
+	if len(s.Schemes) == 0 {
+		return fmt.Errorf("no scheme to register")
+	}
+	return nil
+}
+
+// Scaffold registers the group versions and records them in the PROJECT file
+func (s *Schemes) Scaffold() error {
+	for _, scheme := range s.Schemes {
+		s.config.AddScheme(scheme)
+	}
+	if err := scaffoldv2.RegisterSchemes(s.OutputDir, &s.config.Config, s.Schemes); err != nil {
+		return err
+	}
+	if err := s.config.Save(); err != nil {
+		return fmt.Errorf("error updating project file with the schemes: %v", err)
+	}
+	return nil
+}
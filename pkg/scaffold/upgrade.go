@@ -0,0 +1,400 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffold
+
+import (
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	internalconfig "sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+)
+
+var (
+	controllerRuntimeRequireRe = regexp.MustCompile(`(sigs\.k8s\.io/controller-runtime) (v[^\s]+)`)
+	controllerGenInstallRe     = regexp.MustCompile(`(sigs\.k8s\.io/controller-tools/cmd/controller-gen)@(v[^\s;]+)`)
+)
+
+// codemod rewrites the go files of a project to adapt them to a breaking change of controller-runtime
+type codemod struct {
+	// version is the controller-runtime version introducing the breaking change
+	version string
+
+	// description is shown to the user when the codemod changes a file
+	description string
+
+	// apply returns the rewritten content of a go file
+	apply func(string) string
+}
+
+// replaceAll returns a codemod function replacing every match of expr with repl
+func replaceAll(expr, repl string) func(string) string {
+	re := regexp.MustCompile(expr)
+	return func(content string) string {
+		return re.ReplaceAllString(content, repl)
+	}
+}
+
+// withImport returns a codemod function applying f and importing path with the provided name,
+// empty for the package name, in the files f changed
+func withImport(name, path string, f func(string) string) func(string) string {
+	return func(content string) string {
+		changed := f(content)
+		if changed == content || strings.Contains(changed, strconv.Quote(path)) {
+			return changed
+		}
+		spec := strings.TrimSpace(name + " " + strconv.Quote(path))
+		return strings.Replace(changed, "import (\n", "import (\n\t"+spec+"\n", 1)
+	}
+}
+
+// inManagerOptions returns a codemod function applying f to the ctrl.Options literals only
+func inManagerOptions(f func(string) string) func(string) string {
+	return func(content string) string {
+		const literal = "ctrl.Options{"
+		var result strings.Builder
+		for {
+			i := strings.Index(content, literal)
+			if i < 0 {
+				break
+			}
+			start := i + len(literal)
+			end, depth := start, 1
+			for ; end < len(content) && depth > 0; end++ {
+				switch content[end] {
+				case '{':
+					depth++
+				case '}':
+					depth--
+				}
+			}
+			result.WriteString(content[:start])
+			result.WriteString(f(content[start:end]))
+			content = content[end:]
+		}
+		result.WriteString(content)
+		return result.String()
+	}
+}
+
+// codemods are the known breaking changes of controller-runtime affecting scaffolded files, sorted by version
+var codemods = []codemod{
+	{
+		version:     "v0.5.0",
+		description: "zap.Logger was replaced by zap.New",
+		apply:       replaceAll(`zap\.Logger\(true\)`, `zap.New(zap.UseDevMode(true))`),
+	},
+	{
+		version:     "v0.7.0",
+		description: "Reconcile receives the request context",
+		apply: replaceAll(`Reconcile\(req ctrl\.Request\) \(ctrl\.Result, error\) \{\n\tctx := context\.Background\(\)\n`,
+			"Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {\n"),
+	},
+	{
+		version:     "v0.7.0",
+		description: "Reconcile receives the request context",
+		apply: withImport("", "context",
+			replaceAll(`\.Reconcile\(ctrl\.Request\{`, ".Reconcile(context.Background(), ctrl.Request{")),
+	},
+	{
+		version:     "v0.15.0",
+		description: "manager option MetricsBindAddress was replaced by Metrics",
+		apply: withImport("metricsserver", "sigs.k8s.io/controller-runtime/pkg/metrics/server", inManagerOptions(
+			replaceAll(`MetricsBindAddress:\s*([^,\n]+),`, "Metrics: metricsserver.Options{BindAddress: $1},"))),
+	},
+	{
+		version:     "v0.15.0",
+		description: "manager option Port was replaced by WebhookServer",
+		apply: withImport("", "sigs.k8s.io/controller-runtime/pkg/webhook", inManagerOptions(
+			replaceAll(`\bPort:\s*([^,\n]+),`, "WebhookServer: webhook.NewServer(webhook.Options{Port: $1}),"))),
+	},
+}
+
+// DependencyUpgrade bumps the pinned dependencies of a project and applies the codemods
+// of the controller-runtime breaking changes in between to its go files.
+type DependencyUpgrade struct {
+	// Target contains the versions to upgrade to, empty versions default to the ones new projects
+	// are scaffolded with when they are newer than the current ones
+	Target config.Dependencies
+
+	// OutputDir is the project root, defaults to the current working directory
+	OutputDir string
+
+	// Changes lists the changes applied to the project files
+	Changes []string
+
+	config  *internalconfig.Config
+	current config.Dependencies
+}
+
+// Validate validates whether the project dependencies can be upgraded to the target versions
+func (u *DependencyUpgrade) Validate() (err error) {
+	u.config, err = internalconfig.LoadFrom(internalconfig.PathIn(u.OutputDir))
+	if err != nil {
+		return fmt.Errorf("error reading configuration: %v", err)
+	}
+	q, err := u.config.Query()
+	if err != nil {
+		return err
+	}
+	if !q.SupportsScaffoldMarkers() {
+		return fmt.Errorf("dependencies can only be upgraded for v2 projects")
+	}
+
+	if u.config.Dependencies != nil {
+		u.current = *u.config.Dependencies
+	}
+	// projects scaffolded before the dependencies were recorded are read from their files
+	if u.current.ControllerRuntime == "" {
+		u.current.ControllerRuntime = u.findVersion("go.mod", controllerRuntimeRequireRe)
+	}
+	if u.current.ControllerGen == "" {
+		u.current.ControllerGen = u.findVersion("Makefile", controllerGenInstallRe)
+	}
+
+	defaults := DefaultDependencies()
+	if err := validateTarget("controller-runtime", u.current.ControllerRuntime, &u.Target.ControllerRuntime,
+		defaults.ControllerRuntime); err != nil {
+		return err
+	}
+	if err := validateTarget("controller-gen", u.current.ControllerGen, &u.Target.ControllerGen,
+		defaults.ControllerGen); err != nil {
+		return err
+	}
+	return validateTarget("kustomize", u.current.Kustomize, &u.Target.Kustomize, defaults.Kustomize)
+}
+
+// validateTarget verifies that target is not older than current, defaulting it to fallback if it is newer
+func validateTarget(name, current string, target *string, fallback string) error {
+	if *target == "" {
+		if current == "" || compareVersions(fallback, current) > 0 {
+			*target = fallback
+		}
+		return nil
+	}
+
+	if _, err := parseVersion(*target); err != nil {
+		return fmt.Errorf("invalid %s version: %v", name, err)
+	}
+	if current != "" && compareVersions(*target, current) < 0 {
+		return fmt.Errorf("%s can't be downgraded from %s to %s", name, current, *target)
+	}
+	return nil
+}
+
+// Upgrade rewrites go.mod, the Makefile, the go files and the configuration of the project
+func (u *DependencyUpgrade) Upgrade() error {
+	if u.Target.ControllerRuntime != "" {
+		if err := u.rewrite("go.mod", func(content string) string {
+			return controllerRuntimeRequireRe.ReplaceAllString(content, "$1 "+u.Target.ControllerRuntime)
+		}, "controller-runtime "+u.Target.ControllerRuntime); err != nil {
+			return err
+		}
+		if err := u.applyCodemods(); err != nil {
+			return err
+		}
+	}
+
+	if u.Target.ControllerGen != "" {
+		if err := u.rewrite("Makefile", func(content string) string {
+			return controllerGenInstallRe.ReplaceAllString(content, "$1@"+u.Target.ControllerGen)
+		}, "controller-gen "+u.Target.ControllerGen); err != nil {
+			return err
+		}
+	}
+
+	dependencies := u.current
+	if u.Target.ControllerRuntime != "" {
+		dependencies.ControllerRuntime = u.Target.ControllerRuntime
+	}
+	if u.Target.ControllerGen != "" {
+		dependencies.ControllerGen = u.Target.ControllerGen
+	}
+	if u.Target.Kustomize != "" {
+		dependencies.Kustomize = u.Target.Kustomize
+	}
+	u.config.Dependencies = &dependencies
+
+	return u.config.Save()
+}
+
+// applyCodemods applies the codemods of the versions after the current one up to the target one
+func (u *DependencyUpgrade) applyCodemods() error {
+	var pending []codemod
+	for _, c := range codemods {
+		if (u.current.ControllerRuntime == "" || compareVersions(c.version, u.current.ControllerRuntime) > 0) &&
+			compareVersions(c.version, u.Target.ControllerRuntime) <= 0 {
+			pending = append(pending, c)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	var files []string
+	err := filepath.Walk(u.path("."), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if name := info.Name(); path != u.path(".") && (name == "vendor" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(path, ".go") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	for _, path := range files {
+		b, err := filesystem.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		content := string(b)
+		var applied []string
+		for _, c := range pending {
+			if changed := c.apply(content); changed != content {
+				content = changed
+				applied = append(applied, c.description)
+			}
+		}
+		if len(applied) == 0 {
+			continue
+		}
+
+		formatted, err := format.Source([]byte(content))
+		if err != nil {
+			return fmt.Errorf("error formatting %s after applying codemods: %v", path, err)
+		}
+		if err := filesystem.WriteFile(path, formatted, os.ModePerm); err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(u.path("."), path)
+		u.Changes = append(u.Changes, fmt.Sprintf("%s: %s", rel, strings.Join(unique(applied), ", ")))
+	}
+
+	return nil
+}
+
+// rewrite applies f to the content of the project file at path, recording change if it was modified
+func (u *DependencyUpgrade) rewrite(path string, f func(string) string, change string) error {
+	b, err := filesystem.ReadFile(u.path(path))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	content := f(string(b))
+	if content == string(b) {
+		return nil
+	}
+	if err := filesystem.WriteFile(u.path(path), []byte(content), os.ModePerm); err != nil {
+		return err
+	}
+	u.Changes = append(u.Changes, fmt.Sprintf("%s: %s", path, change))
+	return nil
+}
+
+// findVersion returns the version captured by re in the project file at path, if any
+func (u *DependencyUpgrade) findVersion(path string, re *regexp.Regexp) string {
+	b, err := filesystem.ReadFile(u.path(path))
+	if err != nil {
+		return ""
+	}
+	if m := re.FindStringSubmatch(string(b)); m != nil {
+		return m[2]
+	}
+	return ""
+}
+
+// path resolves path relative to the project root
+func (u *DependencyUpgrade) path(path string) string {
+	if u.OutputDir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(u.OutputDir, path)
+}
+
+func unique(values []string) []string {
+	var result []string
+	seen := map[string]bool{}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// parseVersion parses the major, minor and patch numbers of a vX.Y.Z version, ignoring pre-release suffixes
+func parseVersion(version string) ([3]int, error) {
+	var parsed [3]int
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 3)
+	if !strings.HasPrefix(version, "v") || len(parts) != 3 {
+		return parsed, fmt.Errorf("%q is not of the form vX.Y.Z", version)
+	}
+	parts[2] = strings.SplitN(parts[2], "-", 2)[0]
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return parsed, fmt.Errorf("%q is not of the form vX.Y.Z", version)
+		}
+		parsed[i] = n
+	}
+	return parsed, nil
+}
+
+// compareVersions returns -1, 0 or 1 if a is lower, equal or greater than b,
+// unparsable versions are considered lower than any other one
+func compareVersions(a, b string) int {
+	va, errA := parseVersion(a)
+	vb, errB := parseVersion(b)
+	switch {
+	case errA != nil && errB != nil:
+		return 0
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+	for i := range va {
+		if va[i] != vb[i] {
+			if va[i] < vb[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaffold_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	internalconfig "sigs.k8s.io/kubebuilder/internal/config"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold"
+)
+
+const upgradeMain = `package main
+
+import (
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+func main() {
+	ctrl.SetLogger(zap.Logger(true))
+
+	mgr, _ := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+		MetricsBindAddress: ":8080",
+		LeaderElection:     false,
+		Port:               9443,
+	})
+	_ = mgr
+}
+`
+
+const upgradeController = `package controllers
+
+import (
+	"context"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+type Reconciler struct{}
+
+func (r *Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	_ = ctx
+
+	return ctrl.Result{}, nil
+}
+`
+
+var _ = Describe("DependencyUpgrade", func() {
+	var dir string
+
+	read := func(path string) string {
+		b, err := ioutil.ReadFile(filepath.Join(dir, path))
+		Expect(err).NotTo(HaveOccurred())
+		return string(b)
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "kubebuilder-upgrade")
+		Expect(err).NotTo(HaveOccurred())
+
+		files := map[string]string{
+			"PROJECT": "version: \"2\"\ndomain: example.org\nrepo: example.org/project\n" +
+				"dependencies:\n  controllerRuntime: v0.4.0\n  controllerGen: v0.2.4\n  kustomize: v3.5.4\n",
+			"go.mod":   "module example.org/project\n\nrequire sigs.k8s.io/controller-runtime v0.4.0\n",
+			"Makefile": "\tgo get sigs.k8s.io/controller-tools/cmd/controller-gen@v0.2.4 ;\\\n",
+			"main.go":  upgradeMain,
+			filepath.Join("controllers", "controller.go"): upgradeController,
+		}
+		for path, content := range files {
+			Expect(os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, path), []byte(content), 0644)).To(Succeed())
+		}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("should bump the versions and apply the codemods in between", func() {
+		u := &scaffold.DependencyUpgrade{
+			OutputDir: dir,
+			Target:    config.Dependencies{ControllerRuntime: "v0.15.0", ControllerGen: "v0.12.0"},
+		}
+		Expect(u.Validate()).To(Succeed())
+		Expect(u.Upgrade()).To(Succeed())
+
+		Expect(read("go.mod")).To(ContainSubstring("sigs.k8s.io/controller-runtime v0.15.0"))
+		Expect(read("Makefile")).To(ContainSubstring("controller-gen@v0.12.0"))
+
+		main := read("main.go")
+		Expect(main).To(ContainSubstring("zap.New(zap.UseDevMode(true))"))
+		Expect(main).To(ContainSubstring("Metrics:        metricsserver.Options{BindAddress: \":8080\"},"))
+		Expect(main).To(ContainSubstring("WebhookServer:  webhook.NewServer(webhook.Options{Port: 9443}),"))
+		Expect(main).To(ContainSubstring(`metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"`))
+		Expect(main).To(ContainSubstring(`"sigs.k8s.io/controller-runtime/pkg/webhook"`))
+		Expect(read(filepath.Join("controllers", "controller.go"))).To(ContainSubstring(
+			"Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {\n\t_ = ctx"))
+
+		c, err := internalconfig.ReadFrom(filepath.Join(dir, "PROJECT"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*c.Dependencies).To(Equal(config.Dependencies{
+			ControllerRuntime: "v0.15.0", ControllerGen: "v0.12.0", Kustomize: "v3.5.4",
+		}))
+	})
+
+	It("should only apply the codemods of the versions being upgraded", func() {
+		u := &scaffold.DependencyUpgrade{OutputDir: dir, Target: config.Dependencies{ControllerRuntime: "v0.5.0"}}
+		Expect(u.Validate()).To(Succeed())
+		Expect(u.Upgrade()).To(Succeed())
+
+		main := read("main.go")
+		Expect(main).To(ContainSubstring("zap.New(zap.UseDevMode(true))"))
+		Expect(main).To(ContainSubstring("MetricsBindAddress"))
+		Expect(read(filepath.Join("controllers", "controller.go"))).To(Equal(upgradeController))
+		Expect(read("Makefile")).To(ContainSubstring("controller-gen@v0.2.4"))
+	})
+
+	It("should not downgrade to the default versions", func() {
+		u := &scaffold.DependencyUpgrade{OutputDir: dir, Target: config.Dependencies{ControllerRuntime: "v0.7.0"}}
+		Expect(u.Validate()).To(Succeed())
+		Expect(u.Upgrade()).To(Succeed())
+
+		u = &scaffold.DependencyUpgrade{OutputDir: dir}
+		Expect(u.Validate()).To(Succeed())
+		Expect(u.Upgrade()).To(Succeed())
+		Expect(u.Changes).To(BeEmpty())
+		Expect(read("go.mod")).To(ContainSubstring("sigs.k8s.io/controller-runtime v0.7.0"))
+	})
+
+	It("should refuse to downgrade", func() {
+		u := &scaffold.DependencyUpgrade{OutputDir: dir, Target: config.Dependencies{ControllerRuntime: "v0.3.0"}}
+		Expect(u.Validate()).NotTo(Succeed())
+	})
+})
@@ -23,65 +23,134 @@ import (
 	"path/filepath"
 	"strings"
 
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
 )
 
-func GetResourceInfo(r *resource.Resource,
+// coreGroups are the API groups of the built-in kinds whose types are in the k8s.io/api packages, along with the
+// domain qualifying the group
+var coreGroups = map[string]string{
+	"apps":                  "",
+	"admission":             "k8s.io",
+	"admissionregistration": "k8s.io",
+	"auditregistration":     "k8s.io",
+	"apiextensions":         "k8s.io",
+	"authentication":        "k8s.io",
+	"authorization":         "k8s.io",
+	"autoscaling":           "",
+	"batch":                 "",
+	"certificates":          "k8s.io",
+	"coordination":          "k8s.io",
+	"core":                  "",
+	"events":                "k8s.io",
+	"extensions":            "",
+	"imagepolicy":           "k8s.io",
+	"networking":            "k8s.io",
+	"node":                  "k8s.io",
+	"metrics":               "k8s.io",
+	"policy":                "",
+	"rbac.authorization":    "k8s.io",
+	"scheduling":            "k8s.io",
+	"setting":               "k8s.io",
+	"storage":               "k8s.io",
+}
+
+// GetResourceInfo returns the go package and the group domain of the resource.
+// The project path is used to check whether the resource type is defined in the project,
+// an empty project path refers to the current working directory.
+func GetResourceInfo(projectPath string,
+	r *resource.Resource,
 	repo string,
 	domain string,
 	isMultiGroup bool,
 ) (resourcePackage, groupDomain string) {
 	// Use the k8s.io/api package for core resources
-	coreGroups := map[string]string{
-		"apps":                  "",
-		"admission":             "k8s.io",
-		"admissionregistration": "k8s.io",
-		"auditregistration":     "k8s.io",
-		"apiextensions":         "k8s.io",
-		"authentication":        "k8s.io",
-		"authorization":         "k8s.io",
-		"autoscaling":           "",
-		"batch":                 "",
-		"certificates":          "k8s.io",
-		"coordination":          "k8s.io",
-		"core":                  "",
-		"events":                "k8s.io",
-		"extensions":            "",
-		"imagepolicy":           "k8s.io",
-		"networking":            "k8s.io",
-		"node":                  "k8s.io",
-		"metrics":               "k8s.io",
-		"policy":                "",
-		"rbac.authorization":    "k8s.io",
-		"scheduling":            "k8s.io",
-		"setting":               "k8s.io",
-		"storage":               "k8s.io",
+	if IsBuiltin(projectPath, r, isMultiGroup) {
+		return BuiltinPackage(r), BuiltinGroup(r)
 	}
+	// TODO: need to support '--resource-pkg-path' flag for specifying resourcePath
 
-	var resourcePath string
 	if isMultiGroup {
-		resourcePath = filepath.Join("apis", r.Group, r.Version, fmt.Sprintf("%s_types.go", strings.ToLower(r.Kind)))
-	} else {
-		resourcePath = filepath.Join("api", r.Version, fmt.Sprintf("%s_types.go", strings.ToLower(r.Kind)))
+		return path.Join(repo, "apis", r.Group), r.Group + "." + domain
 	}
+	return path.Join(repo, "api"), r.Group + "." + domain
+}
 
-	if _, err := os.Stat(resourcePath); os.IsNotExist(err) {
-		if domain, found := coreGroups[r.Group]; found {
-			// TODO: support apiextensions.k8s.io and metrics.k8s.io.
-			// apiextensions.k8s.io is in k8s.io/apiextensions-apiserver/pkg/apis/apiextensions
-			// metrics.k8s.io is in k8s.io/metrics/pkg/apis/metrics
-			resourcePackage := path.Join("k8s.io", "api", r.Group)
-			groupDomain = r.Group
-			if domain != "" {
-				groupDomain = r.Group + "." + domain
-			}
-			return resourcePackage, groupDomain
+// IsBuiltin returns true if the kind is a built-in kind of Kubernetes: its group is one of the API groups of
+// Kubernetes and the project doesn't define its types. An empty project path refers to the current working
+// directory.
+func IsBuiltin(projectPath string, r *resource.Resource, isMultiGroup bool) bool {
+	if _, found := coreGroups[r.Group]; !found {
+		return false
+	}
+	apiDir := filepath.Join(projectPath, "api", r.Version)
+	if isMultiGroup {
+		apiDir = filepath.Join(projectPath, "apis", r.Group, r.Version)
+	}
+	return !hasTypesFile(apiDir, r.Kind)
+}
+
+// IsBuiltinGroup returns true if the API group, qualified by its domain or not, e.g. networking.k8s.io or
+// networking, is one of the API groups of Kubernetes
+func IsBuiltinGroup(group string) bool {
+	for g, domain := range coreGroups {
+		if group == g || (domain != "" && group == g+"."+domain) {
+			return true
 		}
-		// TODO: need to support '--resource-pkg-path' flag for specifying resourcePath
 	}
+	return false
+}
 
-	if isMultiGroup {
-		return path.Join(repo, "apis", r.Group), r.Group + "." + domain
+// BuiltinPackage returns the go package of the versions of the group of a built-in kind, e.g. k8s.io/api/apps
+func BuiltinPackage(r *resource.Resource) string {
+	// TODO: support apiextensions.k8s.io and metrics.k8s.io.
+	// apiextensions.k8s.io is in k8s.io/apiextensions-apiserver/pkg/apis/apiextensions
+	// metrics.k8s.io is in k8s.io/metrics/pkg/apis/metrics
+	return path.Join("k8s.io", "api", r.Group)
+}
+
+// BuiltinGroup returns the API group of a built-in kind qualified by its domain, e.g. networking.k8s.io, or core
+// for the kinds of the core API group
+func BuiltinGroup(r *resource.Resource) string {
+	if domain := coreGroups[r.Group]; domain != "" {
+		return r.Group + "." + domain
 	}
-	return path.Join(repo, "api"), r.Group + "." + domain
+	return r.Group
+}
+
+// RBACGroup returns the group of the rbac markers of an API group qualified by its domain, the core API group is
+// the empty group
+func RBACGroup(groupDomain string) string {
+	if groupDomain == "core" {
+		return `""`
+	}
+	return groupDomain
+}
+
+// hasTypesFile returns true if the types of the kind are defined in the directory, in a file named after
+// the kind with any of the naming policies of the project
+func hasTypesFile(dir, kind string) bool {
+	for _, naming := range []config.Naming{{FileNames: config.LowerFileNames}, {FileNames: config.SnakeFileNames}} {
+		if _, err := filesystem.Stat(filepath.Join(dir, fmt.Sprintf("%s_types.go", naming.FileName(kind)))); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ProjectName returns the lowercase name of the project root directory.
+// An empty project path refers to the current working directory.
+func ProjectName(projectPath string) (string, error) {
+	var dir string
+	var err error
+	if projectPath == "" {
+		dir, err = os.Getwd()
+	} else {
+		dir, err = filepath.Abs(projectPath)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.ToLower(filepath.Base(dir)), nil
 }
@@ -0,0 +1,188 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package admissionpolicy scaffolds the ValidatingAdmissionPolicies validating the objects of the kinds of the
+// project with CEL expressions evaluated by the API server, an alternative to the validating webhooks for the
+// clusters where running a webhook server is undesirable
+package admissionpolicy
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	scaffoldv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/internal"
+)
+
+var _ input.File = &Kustomization{}
+
+// Kustomization scaffolds the kustomization in the validation folder
+type Kustomization struct {
+	input.Input
+
+	// DefaultKustomization is the kustomization deploying the policies along with the manager
+	DefaultKustomization string
+}
+
+// GetInput implements input.File
+func (f *Kustomization) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "validation", "kustomization.yaml")
+	}
+	if f.DefaultKustomization == "" {
+		f.DefaultKustomization = filepath.Join("config", "default", "kustomization.yaml")
+	}
+	f.TemplateBody = kustomizationTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+// Update deploys the policies from config/default
+func (f *Kustomization) Update() error {
+	rel, err := filepath.Rel(filepath.Dir(f.DefaultKustomization), filepath.Dir(f.Path))
+	if err != nil {
+		return err
+	}
+	return internal.AddToKustomization(filepath.Join(f.ProjectPath, f.DefaultKustomization), "bases",
+		filepath.ToSlash(rel))
+}
+
+const kustomizationTemplate = `# The ValidatingAdmissionPolicies of the kinds, evaluated by the API server
+# rather than by the webhook server of the manager. They are served from
+# Kubernetes 1.30, remove ../validation from config/default for older clusters.
+resources:
+
+configurations:
+- kustomizeconfig.yaml
+`
+
+var _ input.File = &KustomizeConfig{}
+
+// KustomizeConfig scaffolds the kustomizeconfig in the validation folder
+type KustomizeConfig struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *KustomizeConfig) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "validation", "kustomizeconfig.yaml")
+	}
+	f.TemplateBody = kustomizeConfigTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+const kustomizeConfigTemplate = `# This configuration is for teaching kustomize how to update the name of the
+# policies in their bindings
+nameReference:
+- kind: ValidatingAdmissionPolicy
+  group: admissionregistration.k8s.io
+  fieldSpecs:
+  - kind: ValidatingAdmissionPolicyBinding
+    group: admissionregistration.k8s.io
+    path: spec/policyName
+`
+
+var _ input.File = &Policy{}
+
+// Policy scaffolds the ValidatingAdmissionPolicy of a Resource and its binding
+type Policy struct {
+	input.Input
+
+	// Resource is the Resource whose objects are validated
+	Resource *resource.Resource
+
+	// Rules are the rules derived from the validation markers of the types of the Resource
+	Rules []scaffoldv2.CELRule
+}
+
+// GetInput implements input.File
+func (f *Policy) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "validation",
+			fmt.Sprintf("%s_policy.yaml", f.Resource.QualifiedName(f.Naming.FileName(f.Resource.Kind), "_")))
+	}
+	f.TemplateBody = policyTemplate
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *Policy) Validate() error {
+	return f.Resource.Validate()
+}
+
+// Update adds the policy to the resources of the kustomization next to it
+func (f *Policy) Update() error {
+	return internal.AddToKustomization(filepath.Join(f.ProjectPath, filepath.Dir(f.Path), "kustomization.yaml"),
+		"resources", filepath.Base(f.Path))
+}
+
+const policyTemplate = `# Validates the {{ .Resource.Resource }} in the API server, the rules were derived
+# from the validation markers of the {{ .Resource.Kind }} type of {{ .Resource.Version }}, which the
+# schema of the CRD enforces as well. Add the ones the schema can't express.
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicy
+metadata:
+  name: {{ .Resource.QualifiedName (lower .Resource.Kind) "-" }}-validation
+spec:
+  failurePolicy: Fail
+  matchConstraints:
+    # the requests for the other versions are converted to {{ .Resource.Version }}
+    matchPolicy: Equivalent
+    resourceRules:
+    - apiGroups:
+      - {{ .Resource.Group }}.{{ .Domain }}
+      apiVersions:
+      - {{ .Resource.Version }}
+      operations:
+      - CREATE
+      - UPDATE
+      resources:
+      - {{ .Resource.Resource }}
+  # the objects without spec are left to the schema of the CRD
+  matchConditions:
+  - name: has-spec
+    expression: has(object.spec)
+  validations:
+{{- range .Rules }}
+  # {{ .Marker }}
+  - expression: {{ printf "%q" .Expression }}
+    message: {{ printf "%q" .Message }}
+{{- else }}
+  # TODO(user): replace with the rules of the {{ .Resource.Kind }}
+  - expression: "true"
+    message: "the {{ .Resource.Kind }} is invalid"
+{{- end }}
+  # TODO(user): add the rules comparing several fields, or the object with the
+  # previous one on updates, e.g.
+  # - expression: "oldObject == null || object.spec.foo == oldObject.spec.foo"
+  #   message: "spec.foo is immutable"
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicyBinding
+metadata:
+  name: {{ .Resource.QualifiedName (lower .Resource.Kind) "-" }}-validation
+spec:
+  policyName: {{ .Resource.QualifiedName (lower .Resource.Kind) "-" }}-validation
+  # Warn and Audit report the denials without denying the requests, e.g. while
+  # rolling the policy out.
+  validationActions:
+  - Deny
+`
@@ -0,0 +1,63 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+)
+
+// Child is a kind of the objects managed by the controller of a resource, which adopts the existing ones not
+// controlled by any object, e.g. created by hand or by helm before the operator was installed
+type Child struct {
+	// Resource is the child resource, its group is a Kubernetes group, e.g. core, or a group of the project
+	Resource *resource.Resource
+
+	// Package is the Go package of the child resource, set when scaffolding the controller
+	Package string
+
+	// GroupDomain is the API group of the child resource, set when scaffolding the controller
+	GroupDomain string
+}
+
+// ParseChild parses a child kind of the form <group>/<version>/<Kind>, e.g. core/v1/ConfigMap, of a resource whose
+// objects are namespaced or not. The children are named after the objects of the resource, in their namespace,
+// so that they must be namespaced as the resource.
+func ParseChild(value string, namespaced bool) (*Child, error) {
+	kind := referenceKindRe.FindStringSubmatch(value)
+	if kind == nil {
+		return nil, fmt.Errorf("invalid child kind %q, expected <group>/<version>/<Kind>, e.g. core/v1/ConfigMap", value)
+	}
+
+	// the children are often built-in kinds
+	r := &resource.Resource{Group: kind[1], Version: kind[2], Kind: kind[3], AllowReservedGroup: true}
+	if err := r.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid child kind %q: %v", value, err)
+	}
+	if clusterScopedKinds[r.Kind] == namespaced {
+		return nil, fmt.Errorf("invalid child kind %q: the children must be namespaced as the resource, "+
+			"they are named after it in its namespace", value)
+	}
+	return &Child{Resource: r}, nil
+}
+
+// Var returns the name of the variable holding the child object
+func (c *Child) Var() string {
+	return strings.ToLower(c.Resource.Kind[:1]) + c.Resource.Kind[1:]
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"testing"
+)
+
+func TestParseChild(t *testing.T) {
+	tests := []struct {
+		value      string
+		namespaced bool
+		kind       string
+		variable   string
+	}{
+		{value: "core/v1/ConfigMap", namespaced: true, kind: "ConfigMap", variable: "configMap"},
+		{value: "apps/v1/Deployment", namespaced: true, kind: "Deployment", variable: "deployment"},
+		{value: "crew/v1/FirstMate", namespaced: true, kind: "FirstMate", variable: "firstMate"},
+		{value: "core/v1/Namespace", namespaced: false, kind: "Namespace", variable: "namespace"},
+	}
+	for _, test := range tests {
+		child, err := ParseChild(test.value, test.namespaced)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.value, err)
+			continue
+		}
+		if child.Resource.Kind != test.kind || child.Var() != test.variable {
+			t.Errorf("%s: unexpected child %+v of variable %s", test.value, child.Resource, child.Var())
+		}
+	}
+
+	for _, test := range []struct {
+		value      string
+		namespaced bool
+	}{
+		{value: "core/ConfigMap", namespaced: true},
+		{value: "core/v1/configMap", namespaced: true},
+		{value: "core/v1/Namespace", namespaced: true},
+		{value: "core/v1/ConfigMap", namespaced: false},
+	} {
+		if _, err := ParseChild(test.value, test.namespaced); err == nil {
+			t.Errorf("%s (namespaced: %v): expected an error", test.value, test.namespaced)
+		}
+	}
+}
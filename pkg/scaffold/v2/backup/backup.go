@@ -0,0 +1,113 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backup
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/internal"
+)
+
+var _ input.File = &Kustomization{}
+
+// Kustomization scaffolds the kustomization of the Velero schedules in the backup folder
+type Kustomization struct {
+	input.Input
+
+	// Prefix is the prefix of the names of the schedules, defaults to the project name
+	Prefix string
+}
+
+// GetInput implements input.File
+func (f *Kustomization) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "backup", "kustomization.yaml")
+	}
+	if f.Prefix == "" {
+		name, err := util.ProjectName(f.ProjectPath)
+		if err != nil {
+			return input.Input{}, err
+		}
+		f.Prefix = name
+	}
+	f.TemplateBody = kustomizationTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+const kustomizationTemplate = `# Deploys the Velero schedules backing up the objects of the operator, in the namespace of Velero. It isn't
+# deployed by config/default, the schedules are only served once Velero is installed (https://velero.io):
+#   kustomize build config/backup | kubectl apply -f -
+namespace: velero
+namePrefix: {{ .Prefix }}-
+
+resources:
+`
+
+var _ input.File = &Schedule{}
+
+// Schedule scaffolds the Velero schedule backing up the objects of a Resource and the state of the workloads they
+// manage
+type Schedule struct {
+	input.Input
+
+	// Resource is the Resource whose objects are backed up
+	Resource *resource.Resource
+}
+
+// GetInput implements input.File
+func (f *Schedule) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "backup", f.Naming.FileName(f.Resource.Kind)+"_schedule.yaml")
+	}
+	f.TemplateBody = scheduleTemplate
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *Schedule) Validate() error {
+	return f.Resource.Validate()
+}
+
+// Update adds the schedule to the resources of the kustomization next to it
+func (f *Schedule) Update() error {
+	return internal.AddToKustomization(filepath.Join(f.ProjectPath, filepath.Dir(f.Path), "kustomization.yaml"),
+		"resources", filepath.Base(f.Path))
+}
+
+const scheduleTemplate = `# Backs up the {{ .Resource.Resource }} and the state of the workloads they manage every day.
+# Velero runs the hooks of the pods annotated by backup.Annotate around their backup and after their restore, and
+# leaves out the objects labelled by backup.Exclude, which the controllers recreate.
+apiVersion: velero.io/v1
+kind: Schedule
+metadata:
+  name: {{ lower .Resource.Kind }}-backup
+spec:
+  schedule: "0 1 * * *"
+  template:
+    # TODO(user): restrict the backup to the namespaces of the {{ .Resource.Resource }}
+    includedNamespaces:
+    - "*"
+    # the CRDs of the {{ .Resource.Resource }} are restored along with them{{ if not .Resource.Namespaced }}, which are cluster-scoped{{ end }}
+    includeClusterResources: true
+    snapshotVolumes: true
+    ttl: 720h0m0s
+`
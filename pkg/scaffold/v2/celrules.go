@@ -0,0 +1,192 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// CELRule is a rule of a ValidatingAdmissionPolicy, derived from a validation marker of a field of the spec
+type CELRule struct {
+	// Expression is the CEL expression the objects must satisfy, e.g. object.spec.replicas <= 10
+	Expression string
+
+	// Message is the message denying the objects which don't satisfy the expression
+	Message string
+
+	// Marker is the marker the rule is derived from, e.g. +kubebuilder:validation:Maximum=10
+	Marker string
+}
+
+var validationMarkerRe = regexp.MustCompile(`^\+kubebuilder:validation:([A-Za-z]+)(?:=(.*))?$`)
+
+// CELRules parses the types of the kind at path and derives a rule from each validation marker of the fields of
+// its spec, or of their types declared in the same file. The markers without a CEL equivalent, e.g. Format, are
+// left out.
+func CELRules(path, kind string) ([]CELRule, error) {
+	src, err := ioutil.ReadFile(path) // nolint: gosec
+	if err != nil {
+		return nil, err
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), path, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+
+	var spec *ast.StructType
+	typeDocs := map[string]*ast.CommentGroup{}
+	for _, decl := range file.Decls {
+		if decl, ok := decl.(*ast.GenDecl); ok && decl.Tok == token.TYPE {
+			for _, s := range decl.Specs {
+				t := s.(*ast.TypeSpec)
+				typeDocs[t.Name.Name] = t.Doc
+				if !decl.Lparen.IsValid() {
+					typeDocs[t.Name.Name] = decl.Doc
+				}
+				if st, ok := t.Type.(*ast.StructType); ok && t.Name.Name == kind+"Spec" {
+					spec = st
+				}
+			}
+		}
+	}
+	if spec == nil {
+		return nil, fmt.Errorf("%s: no struct %sSpec found", path, kind)
+	}
+
+	var rules []CELRule
+	for _, field := range spec.Fields.List {
+		// the embedded fields and the unexported ones aren't fields of the spec of their own
+		if len(field.Names) == 0 || !field.Names[0].IsExported() {
+			continue
+		}
+		name := jsonName(field.Names[0].Name)
+		if field.Tag != nil {
+			value, _ := strconv.Unquote(field.Tag.Value)
+			if tag, found := reflect.StructTag(value).Lookup("json"); found {
+				name = strings.Split(tag, ",")[0]
+			}
+		}
+		if name == "-" || name == "" {
+			continue
+		}
+		markers := validationMarkers(field.Doc)
+		if ident, ok := field.Type.(*ast.Ident); ok {
+			markers = append(markers, validationMarkers(typeDocs[ident.Name])...)
+		}
+		for _, m := range markers {
+			if rule, ok := celRule(name, m[1], m[2]); ok {
+				rule.Marker = m[0]
+				rules = append(rules, rule)
+			}
+		}
+	}
+	return rules, nil
+}
+
+// validationMarkers returns the validation markers of doc, each with its name and value
+func validationMarkers(doc *ast.CommentGroup) [][]string {
+	if doc == nil {
+		return nil
+	}
+	var markers [][]string
+	for _, c := range doc.List {
+		text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+		if m := validationMarkerRe.FindStringSubmatch(text); m != nil {
+			markers = append(markers, m)
+		}
+	}
+	return markers
+}
+
+// celRule returns the rule of the validation marker named name of the field of the spec, false if the marker
+// has no CEL equivalent. The rules but the one of Required hold for the objects without the field.
+func celRule(field, name, value string) (CELRule, bool) {
+	path := "object.spec." + field
+	unless := "!has(" + path + ") || "
+	number := func() bool {
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil
+	}
+	switch name {
+	case "Required":
+		return CELRule{Expression: "has(" + path + ")", Message: fmt.Sprintf("spec.%s is required", field)}, true
+	case "Minimum", "Maximum":
+		if !number() {
+			return CELRule{}, false
+		}
+		op, bound := ">=", "at least"
+		if name == "Maximum" {
+			op, bound = "<=", "at most"
+		}
+		return CELRule{Expression: fmt.Sprintf("%s%s %s %s", unless, path, op, value),
+			Message: fmt.Sprintf("spec.%s must be %s %s", field, bound, value)}, true
+	case "MinLength", "MaxLength", "MinItems", "MaxItems":
+		if _, err := strconv.Atoi(value); err != nil {
+			return CELRule{}, false
+		}
+		op, bound, unit := ">=", "at least", "characters"
+		if strings.HasPrefix(name, "Max") {
+			op, bound = "<=", "at most"
+		}
+		if strings.HasSuffix(name, "Items") {
+			unit = "items"
+		}
+		return CELRule{Expression: fmt.Sprintf("%ssize(%s) %s %s", unless, path, op, value),
+			Message: fmt.Sprintf("spec.%s must have %s %s %s", field, bound, value, unit)}, true
+	case "Pattern":
+		pattern := unquoteMarkerValue(value)
+		// a raw string of CEL, quoted with the quote the pattern doesn't contain
+		quote := "'"
+		if strings.Contains(pattern, quote) {
+			quote = `"`
+		}
+		if pattern == "" || strings.Contains(pattern, quote) {
+			return CELRule{}, false
+		}
+		return CELRule{Expression: fmt.Sprintf("%s%s.matches(r%s%s%s)", unless, path, quote, pattern, quote),
+			Message: fmt.Sprintf("spec.%s must match %s", field, pattern)}, true
+	case "Enum":
+		var values []string
+		for _, v := range strings.Split(value, ";") {
+			v = unquoteMarkerValue(v)
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				v = "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
+			}
+			values = append(values, v)
+		}
+		return CELRule{Expression: fmt.Sprintf("%s%s in [%s]", unless, path, strings.Join(values, ", ")),
+			Message: fmt.Sprintf("spec.%s must be one of %s", field, strings.Join(values, ", "))}, true
+	}
+	return CELRule{}, false
+}
+
+// unquoteMarkerValue returns value without the back quotes or the double quotes around it, if any
+func unquoteMarkerValue(value string) string {
+	value = strings.TrimSpace(value)
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		return unquoted
+	}
+	return value
+}
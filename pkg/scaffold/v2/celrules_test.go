@@ -0,0 +1,95 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const celRulesSource = `package v1
+
+// +kubebuilder:validation:Enum=Frigate;Destroyer
+type Class string
+
+// CaptainSpec defines the desired state of Captain
+type CaptainSpec struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=` + "`" + `^[a-z]+$` + "`" + `
+	Name string ` + "`" + `json:"name"` + "`" + `
+
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	// +optional
+	Ships int32 ` + "`" + `json:"ships,omitempty"` + "`" + `
+
+	// +kubebuilder:validation:MaxItems=3
+	// +kubebuilder:validation:Format=hostname
+	Ports []string ` + "`" + `json:"ports,omitempty"` + "`" + `
+
+	Class Class ` + "`" + `json:"class,omitempty"` + "`" + `
+}
+
+// CaptainStatus defines the observed state of Captain
+type CaptainStatus struct {
+	// +kubebuilder:validation:Minimum=0
+	Ready int32 ` + "`" + `json:"ready"` + "`" + `
+}
+`
+
+func TestCELRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubebuilder-cel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "captain_types.go")
+	if err := ioutil.WriteFile(path, []byte(celRulesSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := CELRules(path, "Captain")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var expressions []string
+	for _, rule := range rules {
+		expressions = append(expressions, rule.Expression)
+	}
+	expected := []string{
+		"has(object.spec.name)",
+		"!has(object.spec.name) || object.spec.name.matches(r'^[a-z]+$')",
+		"!has(object.spec.ships) || object.spec.ships >= 1",
+		"!has(object.spec.ships) || object.spec.ships <= 10",
+		"!has(object.spec.ports) || size(object.spec.ports) <= 3",
+		"!has(object.spec.class) || object.spec.class in ['Frigate', 'Destroyer']",
+	}
+	if !reflect.DeepEqual(expressions, expected) {
+		t.Errorf("expected the expressions\n%q\ngot\n%q", expected, expressions)
+	}
+	if rules[2].Message != "spec.ships must be at least 1" || rules[2].Marker != "+kubebuilder:validation:Minimum=1" {
+		t.Errorf("unexpected rule %+v", rules[2])
+	}
+
+	if _, err := CELRules(path, "FirstMate"); err == nil {
+		t.Error("expected an error for a kind without spec")
+	}
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+)
+
+// CodeGeneratorVersion is the version of k8s.io/code-generator generating the typed clients, which must match the
+// version of k8s.io/client-go required by controller-runtime
+const CodeGeneratorVersion = "v0.16.4"
+
+var _ input.File = &ClientsScript{}
+
+// ClientsScript scaffolds the script generating the typed clientset, informers and listers of the kinds created
+// with --generate-clients, run by make clients
+type ClientsScript struct {
+	input.Input
+
+	// Packages are the directories of the group versions of the kinds with typed clients, e.g. api/v1
+	Packages []string
+}
+
+// GetInput implements input.File
+func (f *ClientsScript) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("hack", "update-codegen.sh")
+	}
+	f.TemplateBody = clientsScriptTemplate
+	// the group versions are listed again for every kind with typed clients
+	f.IfExistsAction = input.Overwrite
+	return f.Input, nil
+}
+
+// Update adds the clients target to the Makefile, once
+func (f *ClientsScript) Update() error {
+	path := filepath.Join(f.ProjectPath, "Makefile")
+	in, err := filesystem.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	content := string(in)
+	if strings.Contains(content, "\nclients:") {
+		return nil
+	}
+	target := strings.Replace(clientsMakefileTarget, "CODE_GENERATOR_VERSION", CodeGeneratorVersion, -1)
+	if i := strings.Index(content, "# Build the docker image"); i >= 0 {
+		content = content[:i] + target + content[i:]
+	} else {
+		content += "\n" + strings.TrimSuffix(target, "\n")
+	}
+	return filesystem.WriteFile(path, []byte(content), 0644)
+}
+
+const clientsMakefileTarget = `# Generate the typed clientset, informers and listers of the kinds created with --generate-clients into pkg/client
+clients: code-generator
+	CODE_GENERATOR_BIN=$(CODE_GENERATOR_BIN) bash hack/update-codegen.sh
+
+# find or download client-gen, lister-gen and informer-gen
+code-generator:
+ifeq (, $(shell which client-gen))
+	@{ \
+	set -e ;\
+	CODE_GENERATOR_TMP_DIR=$$(mktemp -d) ;\
+	cd $$CODE_GENERATOR_TMP_DIR ;\
+	go mod init tmp ;\
+	go get k8s.io/code-generator/cmd/client-gen@CODE_GENERATOR_VERSION \
+		k8s.io/code-generator/cmd/lister-gen@CODE_GENERATOR_VERSION \
+		k8s.io/code-generator/cmd/informer-gen@CODE_GENERATOR_VERSION ;\
+	rm -rf $$CODE_GENERATOR_TMP_DIR ;\
+	}
+CODE_GENERATOR_BIN=$(GOBIN)
+else
+CODE_GENERATOR_BIN=$(patsubst %/,%,$(dir $(shell which client-gen)))
+endif
+
+`
+
+const clientsScriptTemplate = `#!/usr/bin/env bash
+
+# Generates the typed clientset, informers and listers of the kinds marked +genclient, the ones created with
+# kubebuilder create api --generate-clients, into pkg/client for the consumers of the API which don't use the
+# client of controller-runtime. Run it with make clients, it is scaffolded again for every kind with typed clients.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+REPO={{ .Repo }}
+INPUTS="{{ range $i, $p := .Packages }}{{ if $i }},{{ end }}${REPO}/{{ $p }}{{ end }}"
+BIN=${CODE_GENERATOR_BIN:-$(go env GOPATH)/bin}
+HEADER=hack/boilerplate.go.txt
+
+# the generators write the packages under their output base as under a GOPATH
+OUTPUT=$(mktemp -d)
+trap 'rm -rf "${OUTPUT}"' EXIT
+
+"${BIN}/client-gen" --go-header-file "${HEADER}" --input-base "" --input "${INPUTS}" \
+  --clientset-name versioned --output-base "${OUTPUT}" --output-package "${REPO}/pkg/client/clientset"
+"${BIN}/lister-gen" --go-header-file "${HEADER}" --input-dirs "${INPUTS}" \
+  --output-base "${OUTPUT}" --output-package "${REPO}/pkg/client/listers"
+"${BIN}/informer-gen" --go-header-file "${HEADER}" --input-dirs "${INPUTS}" \
+  --versioned-clientset-package "${REPO}/pkg/client/clientset/versioned" \
+  --listers-package "${REPO}/pkg/client/listers" \
+  --output-base "${OUTPUT}" --output-package "${REPO}/pkg/client/informers"
+
+rm -rf pkg/client
+cp -r "${OUTPUT}/${REPO}/pkg/client" pkg/client
+`
+
+var _ input.File = &ClientRegister{}
+
+// ClientRegister scaffolds the declarations of the package of a version the typed clients of its kinds require,
+// besides the ones of groupversion_info.go
+type ClientRegister struct {
+	input.Input
+
+	// Resource is a resource of the version
+	Resource *resource.Resource
+}
+
+// GetInput implements input.File
+func (f *ClientRegister) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		if f.MultiGroup {
+			f.Path = filepath.Join("apis", f.Resource.Group, f.Resource.Version, "register.go")
+		} else {
+			f.Path = filepath.Join("api", f.Resource.Version, "register.go")
+		}
+	}
+	f.TemplateBody = clientRegisterTemplate
+	f.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *ClientRegister) Validate() error {
+	return f.Resource.Validate()
+}
+
+const clientRegisterTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// SchemeGroupVersion is the group version of the typed clients generated by make clients, see GroupVersion
+var SchemeGroupVersion = GroupVersion
+
+// Resource returns the group resource of the listers generated by make clients
+func Resource(resource string) schema.GroupResource {
+	return GroupVersion.WithResource(resource).GroupResource()
+}
+`
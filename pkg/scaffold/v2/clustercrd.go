@@ -0,0 +1,215 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ClusterCRD is a CustomResourceDefinition read from a cluster, e.g. installed by another tool, whose kind is
+// scaffolded into the project
+type ClusterCRD struct {
+	// Name is the name of the CRD, e.g. frigates.ship.example.org
+	Name string
+
+	// Group is the group of the kind qualified by its domain, e.g. ship.example.org
+	Group string
+
+	Kind       string
+	Plural     string
+	ShortNames []string
+	Categories []string
+	Namespaced bool
+
+	// Versions are the served versions, and StorageVersion the one the objects are stored in
+	Versions       []string
+	StorageVersion string
+
+	// schemas are the OpenAPI v3 schemas of the versions, nil for the versions without one
+	schemas map[string]*jsonSchema
+
+	// printerColumns are the printer columns of the versions, of the form <name>:<JSONPath>:<type>
+	printerColumns map[string][]string
+}
+
+// clusterCRDManifest is the part of a CustomResourceDefinition of apiextensions.k8s.io/v1beta1 or v1 describing
+// its kind and the schema of its versions
+type clusterCRDManifest struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec struct {
+		Group string `json:"group"`
+		Names struct {
+			Kind       string   `json:"kind"`
+			Plural     string   `json:"plural"`
+			ShortNames []string `json:"shortNames"`
+			Categories []string `json:"categories"`
+		} `json:"names"`
+		Scope          string                  `json:"scope"`
+		Version        string                  `json:"version"`
+		Validation     *clusterCRDValidation   `json:"validation"`
+		PrinterColumns []clusterCRDColumn      `json:"additionalPrinterColumns"`
+		Versions       []clusterCRDVersionSpec `json:"versions"`
+	} `json:"spec"`
+}
+
+type clusterCRDVersionSpec struct {
+	Name           string                `json:"name"`
+	Served         bool                  `json:"served"`
+	Storage        bool                  `json:"storage"`
+	Schema         *clusterCRDValidation `json:"schema"`
+	PrinterColumns []clusterCRDColumn    `json:"additionalPrinterColumns"`
+}
+
+type clusterCRDValidation struct {
+	OpenAPIV3Schema *jsonSchema `json:"openAPIV3Schema"`
+}
+
+// clusterCRDColumn is a printer column, whose JSONPath is named JSONPath in apiextensions.k8s.io/v1beta1 and
+// jsonPath in v1, both matching the tag
+type clusterCRDColumn struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	JSONPath string `json:"jsonPath"`
+}
+
+// FetchCRD reads the CustomResourceDefinition named name from the cluster of the kubeconfig, the one of the
+// current context of kubectl if kubeconfig is empty
+func FetchCRD(kubeconfig, name string) (*ClusterCRD, error) {
+	args := []string{"get", "customresourcedefinitions.apiextensions.k8s.io", name, "-o", "json"}
+	if kubeconfig != "" {
+		args = append(args, "--kubeconfig", kubeconfig)
+	}
+	var stderr bytes.Buffer
+	c := exec.Command("kubectl", args...) // #nosec
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the CRD %s from the cluster with kubectl: %v: %s", name, err,
+			strings.TrimSpace(stderr.String()))
+	}
+	return ParseCRD(out)
+}
+
+// ParseCRD parses the JSON of a CustomResourceDefinition of apiextensions.k8s.io/v1beta1 or v1
+func ParseCRD(data []byte) (*ClusterCRD, error) {
+	var m clusterCRDManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("unable to parse the CRD: %v", err)
+	}
+	if m.Kind != "CustomResourceDefinition" {
+		return nil, fmt.Errorf("expected a CustomResourceDefinition, got a %s", m.Kind)
+	}
+
+	crd := &ClusterCRD{
+		Name:           m.Metadata.Name,
+		Group:          m.Spec.Group,
+		Kind:           m.Spec.Names.Kind,
+		Plural:         m.Spec.Names.Plural,
+		ShortNames:     m.Spec.Names.ShortNames,
+		Categories:     m.Spec.Names.Categories,
+		Namespaced:     m.Spec.Scope != "Cluster",
+		schemas:        map[string]*jsonSchema{},
+		printerColumns: map[string][]string{},
+	}
+	versions := m.Spec.Versions
+	if len(versions) == 0 {
+		versions = []clusterCRDVersionSpec{{Name: m.Spec.Version, Served: true, Storage: true}}
+	}
+	for _, v := range versions {
+		if v.Storage {
+			crd.StorageVersion = v.Name
+		}
+		if !v.Served {
+			continue
+		}
+		crd.Versions = append(crd.Versions, v.Name)
+		// the versions of apiextensions.k8s.io/v1beta1 share the schema and the columns of the CRD unless they
+		// have theirs
+		validation := v.Schema
+		if validation == nil {
+			validation = m.Spec.Validation
+		}
+		if validation != nil {
+			crd.schemas[v.Name] = validation.OpenAPIV3Schema
+		}
+		columns := v.PrinterColumns
+		if columns == nil {
+			columns = m.Spec.PrinterColumns
+		}
+		for _, column := range columns {
+			crd.printerColumns[v.Name] = append(crd.printerColumns[v.Name],
+				fmt.Sprintf("%s:%s:%s", column.Name, column.JSONPath, column.Type))
+		}
+	}
+	if crd.Kind == "" || crd.Group == "" || len(crd.Versions) == 0 {
+		return nil, fmt.Errorf("the CRD %s has no kind, group or served version", crd.Name)
+	}
+	return crd, nil
+}
+
+// HasVersion returns true if the version is served by the CRD
+func (crd *ClusterCRD) HasVersion(version string) bool {
+	for _, v := range crd.Versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// PrinterColumns returns the printer columns of the version, of the form <name>:<JSONPath>:<type>, but the age
+// of the objects which the types print already
+func (crd *ClusterCRD) PrinterColumns(version string) []string {
+	var columns []string
+	for _, column := range crd.printerColumns[version] {
+		if !strings.Contains(column, ":.metadata.creationTimestamp:") {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// ImportSchema generates the fields of the spec of the version from its schema, along with the nested structs
+// and enums they use
+func (crd *ClusterCRD) ImportSchema(version string) (*ImportedTypes, error) {
+	source := fmt.Sprintf("the CRD %s of the cluster", crd.Name)
+	root := crd.schemas[version]
+	if root == nil || root.propertyNamed("spec") == nil {
+		return nil, fmt.Errorf("%s has no schema of the spec of %s, create the API without --from-cluster and "+
+			"write its types", source, version)
+	}
+	imported, err := importSchema(source, root, crd.Kind)
+	if err != nil {
+		return nil, err
+	}
+	if len(imported.Fields) == 0 {
+		imported.Warnings = append(imported.Warnings, fmt.Sprintf(
+			"the spec has no properties, e.g. it preserves the unknown fields, add its fields to %sSpec", crd.Kind))
+	}
+	if status := root.propertyNamed("status"); status != nil && len(status.Properties) > 0 {
+		imported.Warnings = append(imported.Warnings, fmt.Sprintf(
+			"the status was not imported, add its fields to %sStatus", crd.Kind))
+	}
+	return imported, nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const clusterCRDv1 = `{
+  "apiVersion": "apiextensions.k8s.io/v1",
+  "kind": "CustomResourceDefinition",
+  "metadata": {"name": "frigates.ship.example.org"},
+  "spec": {
+    "group": "ship.example.org",
+    "names": {"kind": "Frigate", "plural": "frigates", "shortNames": ["fr"], "categories": ["ships"]},
+    "scope": "Cluster",
+    "versions": [
+      {"name": "v1alpha1", "served": false, "storage": false},
+      {"name": "v1beta1", "served": true, "storage": false, "schema": {"openAPIV3Schema": {"type": "object"}}},
+      {
+        "name": "v1", "served": true, "storage": true,
+        "additionalPrinterColumns": [
+          {"name": "Class", "type": "string", "jsonPath": ".spec.class"},
+          {"name": "Age", "type": "date", "jsonPath": ".metadata.creationTimestamp"}
+        ],
+        "schema": {"openAPIV3Schema": {
+          "type": "object",
+          "properties": {
+            "spec": {"type": "object", "required": ["class"], "properties": {"class": {"type": "string"}}},
+            "status": {"type": "object", "properties": {"ready": {"type": "boolean"}}}
+          }
+        }}
+      }
+    ]
+  }
+}`
+
+const clusterCRDv1beta1 = `{
+  "apiVersion": "apiextensions.k8s.io/v1beta1",
+  "kind": "CustomResourceDefinition",
+  "metadata": {"name": "captains.crew.example.org"},
+  "spec": {
+    "group": "crew.example.org",
+    "names": {"kind": "Captain", "plural": "captains"},
+    "scope": "Namespaced",
+    "version": "v1",
+    "additionalPrinterColumns": [{"name": "Rank", "type": "integer", "JSONPath": ".spec.rank"}],
+    "validation": {"openAPIV3Schema": {
+      "properties": {"spec": {"properties": {"rank": {"type": "integer", "format": "int32"}}}}
+    }}
+  }
+}`
+
+func TestParseCRD(t *testing.T) {
+	crd, err := ParseCRD([]byte(clusterCRDv1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if crd.Group != "ship.example.org" || crd.Kind != "Frigate" || crd.Plural != "frigates" || crd.Namespaced {
+		t.Errorf("unexpected CRD %+v", crd)
+	}
+	if !reflect.DeepEqual(crd.ShortNames, []string{"fr"}) || !reflect.DeepEqual(crd.Categories, []string{"ships"}) {
+		t.Errorf("unexpected names %v and %v", crd.ShortNames, crd.Categories)
+	}
+	if !reflect.DeepEqual(crd.Versions, []string{"v1beta1", "v1"}) || crd.StorageVersion != "v1" {
+		t.Errorf("unexpected versions %v, stored in %s", crd.Versions, crd.StorageVersion)
+	}
+	if crd.HasVersion("v1alpha1") {
+		t.Error("expected the versions which aren't served to be left out")
+	}
+	if columns := crd.PrinterColumns("v1"); !reflect.DeepEqual(columns, []string{"Class:.spec.class:string"}) {
+		t.Errorf("unexpected printer columns %v", columns)
+	}
+
+	imported, err := crd.ImportSchema("v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"// +kubebuilder:validation:Required\nClass string `json:\"class\"`"}
+	if !reflect.DeepEqual(imported.Fields, expected) {
+		t.Errorf("expected the fields %q, got %q", expected, imported.Fields)
+	}
+	if imported.Source != "the CRD frigates.ship.example.org of the cluster" {
+		t.Errorf("unexpected source %q", imported.Source)
+	}
+	if len(imported.Warnings) != 1 || !strings.Contains(imported.Warnings[0], "the status was not imported") {
+		t.Errorf("expected a warning about the status, got %v", imported.Warnings)
+	}
+
+	if _, err := crd.ImportSchema("v1beta1"); err == nil || !strings.Contains(err.Error(), "has no schema of the spec") {
+		t.Errorf("expected an error for the version without schema of the spec, got %v", err)
+	}
+}
+
+func TestParseCRDv1beta1(t *testing.T) {
+	crd, err := ParseCRD([]byte(clusterCRDv1beta1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !crd.Namespaced || !reflect.DeepEqual(crd.Versions, []string{"v1"}) || crd.StorageVersion != "v1" {
+		t.Errorf("unexpected CRD %+v", crd)
+	}
+	if columns := crd.PrinterColumns("v1"); !reflect.DeepEqual(columns, []string{"Rank:.spec.rank:integer"}) {
+		t.Errorf("unexpected printer columns %v", columns)
+	}
+	imported, err := crd.ImportSchema("v1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"// +optional\nRank int32 `json:\"rank,omitempty\"`"}
+	if !reflect.DeepEqual(imported.Fields, expected) {
+		t.Errorf("expected the fields %q, got %q", expected, imported.Fields)
+	}
+
+	if _, err := ParseCRD([]byte(`{"kind": "ConfigMap"}`)); err == nil {
+		t.Error("expected an error for an object which isn't a CRD")
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+)
+
+var _ input.File = &Conditions{}
+
+// Conditions scaffolds the api/<version>/condition_types.go shared by the status of every kind in the version, with
+// the helpers finding and setting the conditions
+type Conditions struct {
+	input.Input
+
+	// Resource is a resource in the API group
+	Resource *resource.Resource
+}
+
+// GetInput implements input.File
+func (f *Conditions) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		if f.MultiGroup {
+			f.Path = filepath.Join("apis", f.Resource.Group, f.Resource.Version, "condition_types.go")
+		} else {
+			f.Path = filepath.Join("api", f.Resource.Version, "condition_types.go")
+		}
+	}
+	f.TemplateBody = conditionsTemplate
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *Conditions) Validate() error {
+	return f.Resource.Validate()
+}
+
+const conditionsTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConditionReady is the type of the condition shown in the Ready column of kubectl get
+const ConditionReady = "Ready"
+
+// Condition describes the state of a resource at a certain point
+type Condition struct {
+	// Type of the condition, e.g. Ready
+	Type string ` + "`" + `json:"type"` + "`" + `
+
+	// Status of the condition, one of True, False or Unknown
+	Status metav1.ConditionStatus ` + "`" + `json:"status"` + "`" + `
+
+	// LastTransitionTime is the last time the condition changed its status
+	// +optional
+	LastTransitionTime metav1.Time ` + "`" + `json:"lastTransitionTime,omitempty"` + "`" + `
+
+	// Reason is a machine readable explanation of the last transition
+	// +optional
+	Reason string ` + "`" + `json:"reason,omitempty"` + "`" + `
+
+	// Message is a human readable explanation of the last transition
+	// +optional
+	Message string ` + "`" + `json:"message,omitempty"` + "`" + `
+}
+
+// FindCondition returns the condition of the type, nil if there is none
+func FindCondition(conditions []Condition, conditionType string) *Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// IsConditionTrue returns whether the condition of the type has the True status
+func IsConditionTrue(conditions []Condition, conditionType string) bool {
+	c := FindCondition(conditions, conditionType)
+	return c != nil && c.Status == metav1.ConditionTrue
+}
+
+// SetCondition adds the condition or replaces the condition of its type, whose last transition time is kept
+// when its status doesn't change
+func SetCondition(conditions *[]Condition, condition Condition) {
+	if existing := FindCondition(*conditions, condition.Type); existing != nil {
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		*existing = condition
+		return
+	}
+	*conditions = append(*conditions, condition)
+}
+
+// RemoveCondition removes the condition of the type
+func RemoveCondition(conditions *[]Condition, conditionType string) {
+	kept := (*conditions)[:0]
+	for _, c := range *conditions {
+		if c.Type != conditionType {
+			kept = append(kept, c)
+		}
+	}
+	*conditions = kept
+}
+`
@@ -0,0 +1,369 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &Adoption{}
+
+// Adoption scaffolds the package adopting the existing objects not controlled by any object, and orphaning them
+type Adoption struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *Adoption) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "adoption", "adoption.go")
+	}
+	f.TemplateBody = adoptionTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+var _ input.File = &AdoptionTest{}
+
+// AdoptionTest scaffolds the tests of the adoption package
+type AdoptionTest struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *AdoptionTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "adoption", "adoption_test.go")
+	}
+	f.TemplateBody = adoptionTestTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+const adoptionTemplate = `{{ .Boilerplate }}
+
+// Package adoption lets a controller take over the existing objects it manages which no object controls, e.g.
+// created by hand or by helm before the operator was installed, by making their owner their controller, and
+// release them again.
+//
+// The owner references are patched along with the resourceVersion the object was read at, so that the patch
+// fails with a conflict if the object was modified meanwhile, e.g. adopted by another controller. The object is
+// then read again and claimed again.
+package adoption
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Object is an object of the API server
+type Object interface {
+	metav1.Object
+	runtime.Object
+}
+
+// NotAdoptedError is the error claiming an object that is controlled by another object, or by no object while
+// the adoption is disabled
+type NotAdoptedError struct {
+	// Name is the name of the object
+	Name string
+
+	// Controller is the controller of the object, nil if it has none
+	Controller *metav1.OwnerReference
+}
+
+// Error implements error
+func (e *NotAdoptedError) Error() string {
+	if e.Controller == nil {
+		return fmt.Sprintf("%s exists and is not adopted, enable the adoption of the existing objects to manage it",
+			e.Name)
+	}
+	return fmt.Sprintf("%s is controlled by %s %s", e.Name, e.Controller.Kind, e.Controller.Name)
+}
+
+// IsNotAdopted returns true if err is, or wraps, a NotAdoptedError
+func IsNotAdopted(err error) bool {
+	var notAdopted *NotAdoptedError
+	return stderrors.As(err, &notAdopted)
+}
+
+// Claim ensures that owner controls obj: it does nothing if owner already controls it, adopts it if no object
+// controls it and adopt is set, and returns a NotAdoptedError otherwise. obj is updated with the patched object.
+func Claim(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner metav1.Object, obj Object,
+	adopt bool) error {
+	return retryOnConflict(ctx, c, obj, func() (bool, error) {
+		if controller := metav1.GetControllerOf(obj); controller != nil {
+			if controller.UID == owner.GetUID() {
+				return false, nil
+			}
+			return false, &NotAdoptedError{Name: obj.GetName(), Controller: controller}
+		}
+		if !adopt {
+			return false, &NotAdoptedError{Name: obj.GetName()}
+		}
+		return true, controllerutil.SetControllerReference(owner, obj, scheme)
+	})
+}
+
+// Adopt makes owner the controller of obj unless another object controls it
+func Adopt(ctx context.Context, c client.Client, scheme *runtime.Scheme, owner metav1.Object, obj Object) error {
+	return Claim(ctx, c, scheme, owner, obj, true)
+}
+
+// Orphan removes the owner references of owner from obj, e.g. to keep obj when owner is deleted or to hand it
+// over to another owner. It does nothing if owner doesn't own obj.
+func Orphan(ctx context.Context, c client.Client, owner metav1.Object, obj Object) error {
+	return retryOnConflict(ctx, c, obj, func() (bool, error) {
+		refs := obj.GetOwnerReferences()
+		kept := make([]metav1.OwnerReference, 0, len(refs))
+		for _, ref := range refs {
+			if ref.UID != owner.GetUID() {
+				kept = append(kept, ref)
+			}
+		}
+		if len(kept) == len(refs) {
+			return false, nil
+		}
+		obj.SetOwnerReferences(kept)
+		return true, nil
+	})
+}
+
+// retryOnConflict applies mutate to the owner references of obj and patches them if it returns true, reading obj
+// again and retrying when the patch conflicts with a concurrent modification
+func retryOnConflict(ctx context.Context, c client.Client, obj Object, mutate func() (bool, error)) error {
+	key := client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	first := true
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if !first {
+			// obj is reset as the decoding of the response doesn't clear the fields it lacks
+			reflect.ValueOf(obj).Elem().Set(reflect.Zero(reflect.TypeOf(obj).Elem()))
+			if err := c.Get(ctx, key, obj); err != nil {
+				return err
+			}
+		}
+		first = false
+
+		changed, err := mutate()
+		if err != nil || !changed {
+			return err
+		}
+		return patchOwnerReferences(ctx, c, obj)
+	})
+}
+
+// patchOwnerReferences patches the owner references of obj with a merge patch, replacing them all, failing with
+// a conflict if obj was modified since it was read
+func patchOwnerReferences(ctx context.Context, c client.Client, obj Object) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"ownerReferences": obj.GetOwnerReferences(),
+			"resourceVersion": obj.GetResourceVersion(),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return c.Patch(ctx, obj, client.ConstantPatch(types.MergePatchType, data))
+}
+`
+
+const adoptionTestTemplate = `{{ .Boilerplate }}
+
+package adoption
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// racingClient fails the first patches with a conflict after running race, to simulate a concurrent
+// modification of the patched object
+type racingClient struct {
+	client.Client
+
+	// conflicts is the number of patches that still have to fail
+	conflicts int
+
+	// race modifies the object before the patch fails, optional
+	race func(c client.Client)
+}
+
+func (c *racingClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch,
+	opts ...client.PatchOption) error {
+	if c.conflicts > 0 {
+		c.conflicts--
+		if c.race != nil {
+			c.race(c.Client)
+		}
+		return apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "child",
+			errors.New("the object has been modified"))
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func newObjects(t *testing.T, controller *metav1.OwnerReference) (*corev1.ConfigMap, *corev1.ConfigMap, client.Client) {
+	owner := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "ConfigMap"},
+		ObjectMeta: metav1.ObjectMeta{Name: "owner", Namespace: "default", UID: types.UID("owner-uid")},
+	}
+	child := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "child", Namespace: "default"}}
+	if controller != nil {
+		child.OwnerReferences = []metav1.OwnerReference{*controller}
+	}
+
+	c := fake.NewFakeClientWithScheme(clientgoscheme.Scheme, owner, child)
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "child"}, child); err != nil {
+		t.Fatalf("unable to read the child: %v", err)
+	}
+	return owner, child, c
+}
+
+func controllerOf(uid, name string) *metav1.OwnerReference {
+	isController := true
+	return &metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: name, UID: types.UID(uid),
+		Controller: &isController}
+}
+
+func readControllerUID(t *testing.T, c client.Client) types.UID {
+	var child corev1.ConfigMap
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "child"}, &child); err != nil {
+		t.Fatalf("unable to read the child: %v", err)
+	}
+	if controller := metav1.GetControllerOf(&child); controller != nil {
+		return controller.UID
+	}
+	return ""
+}
+
+func TestClaim(t *testing.T) {
+	tests := []struct {
+		name           string
+		controller     *metav1.OwnerReference
+		adopt          bool
+		wantNotAdopted bool
+		wantController types.UID
+	}{
+		{
+			name:           "adopts an object controlled by no object",
+			adopt:          true,
+			wantController: "owner-uid",
+		},
+		{
+			name:           "leaves an object controlled by no object if the adoption is disabled",
+			wantNotAdopted: true,
+		},
+		{
+			name:           "leaves an object controlled by another object",
+			controller:     controllerOf("other-uid", "other"),
+			adopt:          true,
+			wantNotAdopted: true,
+			wantController: "other-uid",
+		},
+		{
+			name:           "accepts an object it already controls",
+			controller:     controllerOf("owner-uid", "owner"),
+			wantController: "owner-uid",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			owner, child, c := newObjects(t, test.controller)
+
+			err := Claim(context.Background(), c, clientgoscheme.Scheme, owner, child, test.adopt)
+			if IsNotAdopted(err) != test.wantNotAdopted || (err != nil && !test.wantNotAdopted) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if uid := readControllerUID(t, c); uid != test.wantController {
+				t.Errorf("expected the child to be controlled by %q, got %q", test.wantController, uid)
+			}
+		})
+	}
+}
+
+func TestAdoptRetriesOnConflict(t *testing.T) {
+	owner, child, c := newObjects(t, nil)
+
+	racing := &racingClient{Client: c, conflicts: 2}
+	if err := Adopt(context.Background(), racing, clientgoscheme.Scheme, owner, child); err != nil {
+		t.Fatalf("expected the conflicts to be retried, got: %v", err)
+	}
+	if uid := readControllerUID(t, c); uid != "owner-uid" {
+		t.Errorf("expected the child to be adopted, got controller %q", uid)
+	}
+}
+
+func TestAdoptYieldsToConcurrentAdoption(t *testing.T) {
+	owner, child, c := newObjects(t, nil)
+
+	// another controller adopts the child between the read and the patch
+	racing := &racingClient{Client: c, conflicts: 1, race: func(c client.Client) {
+		var adopted corev1.ConfigMap
+		if err := c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "child"}, &adopted); err != nil {
+			t.Fatalf("unable to read the child: %v", err)
+		}
+		adopted.OwnerReferences = []metav1.OwnerReference{*controllerOf("other-uid", "other")}
+		if err := c.Update(context.Background(), &adopted); err != nil {
+			t.Fatalf("unable to adopt the child: %v", err)
+		}
+	}}
+	if err := Adopt(context.Background(), racing, clientgoscheme.Scheme, owner, child); !IsNotAdopted(err) {
+		t.Fatalf("expected the child not to be adopted, got: %v", err)
+	}
+	if uid := readControllerUID(t, c); uid != "other-uid" {
+		t.Errorf("expected the child to stay controlled by the other object, got %q", uid)
+	}
+}
+
+func TestOrphan(t *testing.T) {
+	owner, child, c := newObjects(t, controllerOf("owner-uid", "owner"))
+
+	if err := Orphan(context.Background(), c, owner, child); err != nil {
+		t.Fatalf("unable to orphan the child: %v", err)
+	}
+	if uid := readControllerUID(t, c); uid != "" {
+		t.Errorf("expected the child to be orphaned, got controller %q", uid)
+	}
+	if err := Orphan(context.Background(), c, owner, child); err != nil {
+		t.Fatalf("expected orphaning an orphan to do nothing, got: %v", err)
+	}
+}
+`
@@ -0,0 +1,347 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+)
+
+var _ input.File = &ApplyConditions{}
+
+// ApplyConditions scaffolds the package applying the conditions of the objects with server-side apply, every
+// controller owning its conditions as a distinct field manager
+type ApplyConditions struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *ApplyConditions) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "conditions", "conditions.go")
+	}
+	f.TemplateBody = applyConditionsTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+var _ input.File = &ApplyConditionsTest{}
+
+// ApplyConditionsTest scaffolds the tests of the conditions package
+type ApplyConditionsTest struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *ApplyConditionsTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "conditions", "conditions_test.go")
+	}
+	f.TemplateBody = applyConditionsTestTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+var _ input.File = &ConditionsOwnershipTest{}
+
+// ConditionsOwnershipTest scaffolds the test of the test environment demonstrating the co-ownership of the
+// conditions of a resource by its controller and another field manager
+type ConditionsOwnershipTest struct {
+	input.Input
+
+	// Resource is the Resource to make the test for
+	Resource *resource.Resource
+
+	// ResourcePackage is the package of the Resource
+	ResourcePackage string
+
+	// ReconcilerName is the name of the reconciler type of the Resource
+	ReconcilerName string
+}
+
+// GetInput implements input.File
+func (f *ConditionsOwnershipTest) GetInput() (input.Input, error) {
+	f.ResourcePackage, _ = util.GetResourceInfo(f.ProjectPath, f.Resource, f.Repo, f.Domain, f.MultiGroup)
+	f.ReconcilerName = f.Naming.ReconcilerName(f.Resource.Kind)
+
+	if f.Path == "" {
+		if f.MultiGroup {
+			f.Path = filepath.Join("controllers", f.Resource.Group,
+				f.Naming.FileName(f.Resource.Kind)+"_conditions_test.go")
+		} else {
+			f.Path = filepath.Join("controllers", f.Naming.FileName(f.Resource.Kind)+"_conditions_test.go")
+		}
+	}
+	f.TemplateBody = conditionsOwnershipTestTemplate
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *ConditionsOwnershipTest) Validate() error {
+	return f.Resource.Validate()
+}
+
+const applyConditionsTemplate = `{{ .Boilerplate }}
+
+// Package conditions applies the conditions of the objects with server-side apply. Every controller applies the
+// conditions it owns as its own field manager, e.g. the Ready condition as the captain-controller: the API server
+// merges the conditions by type, a controller never clobbers the conditions of the others, e.g. the Degraded
+// condition set by a monitoring controller, as an update of the whole status would. The Conditions field of the
+// status is a map keyed by type for that purpose, see its +listType=map marker.
+//
+// A field manager owns the conditions it applied last: applying a condition alone removes the other conditions
+// the field manager applied before, apply all the conditions the controller owns every time.
+package conditions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Condition is a condition applied by a field manager, its last transition time is set by the Applier
+type Condition struct {
+	// Type of the condition, e.g. Ready
+	Type string
+
+	// Status of the condition, one of True, False or Unknown
+	Status metav1.ConditionStatus
+
+	// Reason is a machine readable explanation of the status
+	Reason string
+
+	// Message is a human readable explanation of the status
+	Message string
+}
+
+// Applier applies the conditions of the objects as its field manager
+type Applier struct {
+	// Client writes the status of the objects
+	Client client.Client
+
+	// Scheme resolves the kinds of the objects
+	Scheme *runtime.Scheme
+
+	// FieldManager owns the applied conditions, distinct for every controller, e.g. captain-controller
+	FieldManager string
+
+	// Clock is the source of the last transition times
+	Clock clock.Clock
+}
+
+// Apply applies the conditions to the status of obj with server-side apply and updates obj with the applied
+// status. The conditions of the other field managers are kept; the ones of the Applier not applied again are
+// removed.
+func (a *Applier) Apply(ctx context.Context, obj runtime.Object, conditions ...Condition) error {
+	gvk, err := apiutil.GVKForObject(obj, a.Scheme)
+	if err != nil {
+		return err
+	}
+	applied, err := configuration(obj, conditions, a.Clock.Now())
+	if err != nil {
+		return err
+	}
+	applied.SetGroupVersionKind(gvk)
+
+	// the Applier takes over the conditions it applies from the field managers which set them before
+	if err := a.Client.Status().Patch(ctx, applied, client.Apply, client.FieldOwner(a.FieldManager),
+		client.ForceOwnership); err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(applied.Object, obj)
+}
+
+// configuration returns the configuration of obj applying the conditions, the last transition time of the
+// conditions whose status doesn't change is kept
+func configuration(obj runtime.Object, conditions []Condition, now time.Time) (*unstructured.Unstructured, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	var current map[string]interface{}
+	if u, ok := obj.(runtime.Unstructured); ok {
+		current = u.UnstructuredContent()
+	} else if current, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj); err != nil {
+		return nil, err
+	}
+	existing, _, err := unstructured.NestedSlice(current, "status", "conditions")
+	if err != nil {
+		return nil, fmt.Errorf("invalid status.conditions: %v", err)
+	}
+
+	applied := make([]interface{}, 0, len(conditions))
+	for _, condition := range conditions {
+		transition := now.UTC().Format(time.RFC3339)
+		for _, e := range existing {
+			c, ok := e.(map[string]interface{})
+			if ok && c["type"] == condition.Type && c["status"] == string(condition.Status) &&
+				c["lastTransitionTime"] != nil {
+				transition = fmt.Sprint(c["lastTransitionTime"])
+			}
+		}
+		c := map[string]interface{}{
+			"type":               condition.Type,
+			"status":             string(condition.Status),
+			"lastTransitionTime": transition,
+		}
+		if condition.Reason != "" {
+			c["reason"] = condition.Reason
+		}
+		if condition.Message != "" {
+			c["message"] = condition.Message
+		}
+		applied = append(applied, c)
+	}
+
+	u := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	u.SetName(accessor.GetName())
+	u.SetNamespace(accessor.GetNamespace())
+	if err := unstructured.SetNestedSlice(u.Object, applied, "status", "conditions"); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+`
+
+const applyConditionsTestTemplate = `{{ .Boilerplate }}
+
+package conditions
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestConfiguration(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True", "lastTransitionTime": "2020-01-01T00:00:00Z"},
+				map[string]interface{}{"type": "Degraded", "status": "False", "lastTransitionTime": "2020-01-01T00:00:00Z"},
+			},
+		},
+	}}
+	obj.SetName("test")
+	obj.SetNamespace("default")
+
+	now := time.Date(2020, 2, 1, 0, 0, 0, 0, time.UTC)
+	applied, err := configuration(obj, []Condition{
+		{Type: "Ready", Status: metav1.ConditionTrue, Reason: "Reconciled"},
+		{Type: "Progressing", Status: metav1.ConditionFalse},
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if applied.GetName() != "test" || applied.GetNamespace() != "default" {
+		t.Errorf("expected the configuration to identify the object, got %s/%s",
+			applied.GetNamespace(), applied.GetName())
+	}
+	conditions, _, _ := unstructured.NestedSlice(applied.Object, "status", "conditions")
+	if len(conditions) != 2 {
+		t.Fatalf("expected the applied conditions only, got %v", conditions)
+	}
+	ready := conditions[0].(map[string]interface{})
+	if ready["lastTransitionTime"] != "2020-01-01T00:00:00Z" || ready["reason"] != "Reconciled" {
+		t.Errorf("expected the Ready condition to keep its transition time, got %v", ready)
+	}
+	progressing := conditions[1].(map[string]interface{})
+	if progressing["lastTransitionTime"] != "2020-02-01T00:00:00Z" {
+		t.Errorf("expected the Progressing condition to transition now, got %v", progressing)
+	}
+	if _, found := progressing["reason"]; found {
+		t.Errorf("expected the empty reason to be omitted, got %v", progressing)
+	}
+}
+`
+
+const conditionsOwnershipTestTemplate = `{{ .Boilerplate }}
+
+package controllers
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	{{ .Resource.GroupImportSafe }}{{ .Resource.Version }} "{{ .ResourcePackage }}/{{ .Resource.Version }}"
+	"{{ .Repo }}/internal/conditions"
+)
+
+var _ = Describe("{{ .Resource.Kind }} conditions", func() {
+	It("should keep the conditions applied by the other field managers", func() {
+		ctx := context.Background()
+		instance := &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{
+			ObjectMeta: metav1.ObjectMeta{GenerateName: "{{ lower .Resource.Kind }}-"{{ if .Resource.Namespaced }}, Namespace: testNamespace{{ end }}},
+		}
+		Expect(k8sClient.Create(ctx, instance)).To(Succeed())
+{{- if not .Resource.Namespaced }}
+		defer func() {
+			Expect(client.IgnoreNotFound(k8sClient.Delete(ctx, instance))).To(Succeed())
+		}()
+{{- end }}
+
+		r := &{{ .ReconcilerName }}{Client: k8sClient, Scheme: scheme.Scheme, Clock: clock.RealClock{}}
+		monitor := &conditions.Applier{Client: k8sClient, Scheme: scheme.Scheme, FieldManager: "monitor",
+			Clock: clock.RealClock{}}
+
+		By("applying the conditions of the controller and of another field manager")
+		Expect(r.applyConditions(ctx, instance, conditions.Condition{
+			Type: {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.ConditionReady, Status: metav1.ConditionTrue, Reason: "Reconciled",
+		})).To(Succeed())
+		Expect(monitor.Apply(ctx, instance, conditions.Condition{
+			Type: "Degraded", Status: metav1.ConditionFalse, Reason: "Healthy",
+		})).To(Succeed())
+
+		By("applying the conditions of the controller again")
+		Expect(r.applyConditions(ctx, instance, conditions.Condition{
+			Type: {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.ConditionReady, Status: metav1.ConditionFalse, Reason: "Failed",
+		})).To(Succeed())
+
+		latest := &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{}
+		Expect(k8sClient.Get(ctx, client.ObjectKey{ {{- if .Resource.Namespaced }}Namespace: instance.Namespace, {{ end }}Name: instance.Name}, latest)).To(Succeed())
+		statuses := map[string]metav1.ConditionStatus{}
+		for _, c := range latest.Status.Conditions {
+			statuses[c.Type] = c.Status
+		}
+		Expect(statuses).To(Equal(map[string]metav1.ConditionStatus{
+			{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.ConditionReady: metav1.ConditionFalse,
+			"Degraded": metav1.ConditionFalse,
+		}))
+	})
+})
+`
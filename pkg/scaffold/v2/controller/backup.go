@@ -0,0 +1,261 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &Backup{}
+
+// Backup scaffolds the package annotating the objects created by the controllers with the hooks Velero runs in
+// their pods around their backups and restores
+type Backup struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *Backup) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "backup", "backup.go")
+	}
+	f.TemplateBody = backupTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+var _ input.File = &BackupTest{}
+
+// BackupTest scaffolds the tests of the backup package
+type BackupTest struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *BackupTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "backup", "backup_test.go")
+	}
+	f.TemplateBody = backupTestTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+const backupTemplate = `{{ .Boilerplate }}
+
+// Package backup annotates the objects created by the controllers for Velero (https://velero.io), which backs up
+// and restores the state managed by the operator: the hooks Velero runs in the pods around their backups, e.g. to
+// flush and freeze the data of a database while its volumes are snapshotted, and after their restore, and the
+// objects left out of the backups because the controllers recreate them.
+//
+// See https://velero.io/docs/main/backup-hooks/ and https://velero.io/docs/main/restore-hooks/
+package backup
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	preBackupPrefix   = "pre.hook.backup.velero.io/"
+	postBackupPrefix  = "post.hook.backup.velero.io/"
+	postRestorePrefix = "post.hook.restore.velero.io/"
+
+	// VolumesAnnotation lists the volumes of a pod backed up by the file system backup of Velero rather than
+	// snapshotted
+	VolumesAnnotation = "backup.velero.io/backup-volumes"
+
+	// ExcludeLabel leaves an object out of the backups
+	ExcludeLabel = "velero.io/exclude-from-backup"
+)
+
+const (
+	// Continue continues the backup or the restore when the command of a hook fails
+	Continue = "Continue"
+
+	// Fail fails the backup or the restore when the command of a hook fails
+	Fail = "Fail"
+)
+
+// Hook is a command run by Velero in a container of a pod
+type Hook struct {
+	// Container is the container the command runs in, the first container of the pod if empty
+	Container string
+
+	// Command is the command and its arguments, e.g. ["/bin/sh", "-c", "fsfreeze --freeze /data"]
+	Command []string
+
+	// OnError is Continue or Fail, the default of Velero if empty
+	OnError string
+
+	// Timeout is the time Velero waits for the command, the default of Velero if zero
+	Timeout time.Duration
+}
+
+// Hooks are the hooks run in the pods of a workload, and its volumes backed up by the file system backup
+type Hooks struct {
+	// PreBackup runs before the pod is backed up, e.g. to freeze the data
+	PreBackup *Hook
+
+	// PostBackup runs once the pod is backed up, e.g. to unfreeze the data
+	PostBackup *Hook
+
+	// PostRestore runs once the pod is restored and its containers are ready, e.g. to check the data
+	PostRestore *Hook
+
+	// Volumes are the volumes of the pod backed up by the file system backup
+	Volumes []string
+}
+
+// Annotate sets the annotations of the hooks on an object, e.g. the ObjectMeta of the pod template of a workload,
+// and removes the ones of the hooks which aren't set. It returns whether the annotations changed.
+func Annotate(obj metav1.Object, hooks Hooks) bool {
+	annotations := map[string]string{}
+	for k, v := range obj.GetAnnotations() {
+		if !isHookAnnotation(k) {
+			annotations[k] = v
+		}
+	}
+	setHook(annotations, preBackupPrefix, "timeout", hooks.PreBackup)
+	setHook(annotations, postBackupPrefix, "timeout", hooks.PostBackup)
+	setHook(annotations, postRestorePrefix, "exec-timeout", hooks.PostRestore)
+	if len(hooks.Volumes) > 0 {
+		annotations[VolumesAnnotation] = strings.Join(hooks.Volumes, ",")
+	}
+
+	if len(annotations) == 0 && len(obj.GetAnnotations()) == 0 {
+		return false
+	}
+	if reflect.DeepEqual(annotations, obj.GetAnnotations()) {
+		return false
+	}
+	obj.SetAnnotations(annotations)
+	return true
+}
+
+// Exclude labels an object to be left out of the backups, e.g. an object the controllers recreate from the object
+// it is created for. It returns whether the labels changed.
+func Exclude(obj metav1.Object) bool {
+	if obj.GetLabels()[ExcludeLabel] == "true" {
+		return false
+	}
+	labels := map[string]string{}
+	for k, v := range obj.GetLabels() {
+		labels[k] = v
+	}
+	labels[ExcludeLabel] = "true"
+	obj.SetLabels(labels)
+	return true
+}
+
+// isHookAnnotation returns whether the annotation is one of the hooks or of the volumes set by Annotate
+func isHookAnnotation(key string) bool {
+	return strings.HasPrefix(key, preBackupPrefix) || strings.HasPrefix(key, postBackupPrefix) ||
+		strings.HasPrefix(key, postRestorePrefix) || key == VolumesAnnotation
+}
+
+// setHook sets the annotations of the hook with the prefix, timeout is the name of the annotation of its timeout
+func setHook(annotations map[string]string, prefix, timeout string, hook *Hook) {
+	if hook == nil || len(hook.Command) == 0 {
+		return
+	}
+	// the command is a JSON array of the command and its arguments
+	command, _ := json.Marshal(hook.Command)
+	annotations[prefix+"command"] = string(command)
+	if hook.Container != "" {
+		annotations[prefix+"container"] = hook.Container
+	}
+	if hook.OnError != "" {
+		annotations[prefix+"on-error"] = hook.OnError
+	}
+	if hook.Timeout > 0 {
+		annotations[prefix+timeout] = hook.Timeout.String()
+	}
+}
+`
+
+const backupTestTemplate = `{{ .Boilerplate }}
+
+package backup
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAnnotate(t *testing.T) {
+	obj := &metav1.ObjectMeta{Annotations: map[string]string{"example.com/annotation": "kept"}}
+	hooks := Hooks{
+		PreBackup:   &Hook{Container: "db", Command: []string{"/bin/sh", "-c", "fsfreeze --freeze /data"}, OnError: Fail},
+		PostBackup:  &Hook{Container: "db", Command: []string{"/bin/sh", "-c", "fsfreeze --unfreeze /data"}},
+		PostRestore: &Hook{Command: []string{"/check"}, Timeout: time.Minute},
+		Volumes:     []string{"data", "wal"},
+	}
+	if !Annotate(obj, hooks) {
+		t.Errorf("expected the annotations to change")
+	}
+	if Annotate(obj, hooks) {
+		t.Errorf("expected the annotations to be set once")
+	}
+	expected := map[string]string{
+		"example.com/annotation":                "kept",
+		"pre.hook.backup.velero.io/container":   "db",
+		"pre.hook.backup.velero.io/command":     ` + "`" + `["/bin/sh","-c","fsfreeze --freeze /data"]` + "`" + `,
+		"pre.hook.backup.velero.io/on-error":    "Fail",
+		"post.hook.backup.velero.io/container":  "db",
+		"post.hook.backup.velero.io/command":    ` + "`" + `["/bin/sh","-c","fsfreeze --unfreeze /data"]` + "`" + `,
+		"post.hook.restore.velero.io/command":   ` + "`" + `["/check"]` + "`" + `,
+		"post.hook.restore.velero.io/exec-timeout": "1m0s",
+		"backup.velero.io/backup-volumes":       "data,wal",
+	}
+	if !reflect.DeepEqual(obj.Annotations, expected) {
+		t.Errorf("expected the annotations %v, got %v", expected, obj.Annotations)
+	}
+
+	if !Annotate(obj, Hooks{}) {
+		t.Errorf("expected the annotations of the hooks to be removed")
+	}
+	if expected := map[string]string{"example.com/annotation": "kept"}; !reflect.DeepEqual(obj.Annotations, expected) {
+		t.Errorf("expected the annotations %v, got %v", expected, obj.Annotations)
+	}
+	if Annotate(&metav1.ObjectMeta{}, Hooks{}) {
+		t.Errorf("expected an object without hooks to be left alone")
+	}
+}
+
+func TestExclude(t *testing.T) {
+	obj := &metav1.ObjectMeta{Labels: map[string]string{"app": "example"}}
+	if !Exclude(obj) {
+		t.Errorf("expected the labels to change")
+	}
+	if Exclude(obj) {
+		t.Errorf("expected the object to be excluded once")
+	}
+	if expected := map[string]string{"app": "example", ExcludeLabel: "true"}; !reflect.DeepEqual(obj.Labels, expected) {
+		t.Errorf("expected the labels %v, got %v", expected, obj.Labels)
+	}
+}
+`
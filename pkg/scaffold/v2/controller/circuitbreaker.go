@@ -0,0 +1,378 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &CircuitBreaker{}
+
+// CircuitBreaker scaffolds the circuit breaker of the calls of the reconcilers to the external systems they depend
+// on, failing fast while a system keeps failing rather than retrying every object against it
+type CircuitBreaker struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *CircuitBreaker) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "circuitbreaker", "circuitbreaker.go")
+	}
+	f.TemplateBody = circuitBreakerTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+var _ input.File = &CircuitBreakerTest{}
+
+// CircuitBreakerTest scaffolds the tests of the circuit breaker, driven by a fake clock
+type CircuitBreakerTest struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *CircuitBreakerTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "circuitbreaker", "circuitbreaker_test.go")
+	}
+	f.TemplateBody = circuitBreakerTestTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+const circuitBreakerTemplate = `{{ .Boilerplate }}
+
+// Package circuitbreaker guards the calls of the reconcilers to the external systems they depend on, e.g. the API
+// of a cloud provider. The breaker of a controller opens after a number of consecutive failures: the calls then
+// fail fast, requeuing the objects after a cooldown rather than retrying every one of them against the failing
+// system with backoff. Once the cooldown elapsed, the breaker is half-open and lets a single call through: it
+// closes when the call succeeds, and opens again when it fails.
+//
+// The state of a breaker is exported as the controller_circuit_breaker_state metric and recorded in the
+// DependencyAvailable condition of the reconciled objects.
+package circuitbreaker
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"{{ .Repo }}/internal/errors"
+)
+
+const (
+	// DefaultThreshold is the number of consecutive failures opening a breaker
+	DefaultThreshold = 5
+
+	// DefaultCooldown is the time a breaker stays open before letting a call through again
+	DefaultCooldown = time.Minute
+)
+
+// ConditionDependencyAvailable is the type of the condition recording whether the external systems an object
+// depends on are available, as seen by the breaker of its controller
+const ConditionDependencyAvailable = "DependencyAvailable"
+
+// State is the state of a breaker
+type State int
+
+const (
+	// Closed lets the calls through, the failures are counted
+	Closed State = iota
+
+	// HalfOpen lets a single call through, deciding whether the breaker closes or opens again
+	HalfOpen
+
+	// Open fails the calls fast until the cooldown elapsed
+	Open
+)
+
+// String returns the name of the state
+func (s State) String() string {
+	switch s {
+	case HalfOpen:
+		return "HalfOpen"
+	case Open:
+		return "Open"
+	default:
+		return "Closed"
+	}
+}
+
+var (
+	state = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "controller_circuit_breaker_state",
+		Help: "State of the circuit breaker of the external calls of the controller, closed (0), half-open (1) or open (2).",
+	}, []string{"breaker"})
+	trips = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_circuit_breaker_trips_total",
+		Help: "Number of times the circuit breaker of the external calls of the controller opened.",
+	}, []string{"breaker"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(state, trips)
+}
+
+// OpenError is the error of the calls failed fast by an open breaker
+type OpenError struct {
+	// Breaker is the name of the breaker
+	Breaker string
+
+	// Err is the last failure of the calls, which opened the breaker
+	Err error
+}
+
+// Error implements error
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("circuit breaker %s is open: %v", e.Breaker, e.Err)
+}
+
+// Unwrap returns the last failure of the calls
+func (e *OpenError) Unwrap() error {
+	return e.Err
+}
+
+// IsOpen returns true if err is, or wraps, the error of a call failed fast by an open breaker
+func IsOpen(err error) bool {
+	var open *OpenError
+	return stderrors.As(err, &open)
+}
+
+// Breaker is the circuit breaker of the external calls of a controller, safe for concurrent reconciles
+type Breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+	clock     clock.Clock
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	// openedAt is the time the breaker last opened
+	openedAt time.Time
+	// probing is true while the single call of the half-open breaker is in flight
+	probing bool
+	lastErr error
+}
+
+// New returns the closed breaker of the named controller, opening after threshold consecutive failures for the
+// cooldown
+func New(name string, threshold int, cooldown time.Duration, clk clock.Clock) *Breaker {
+	if threshold < 1 {
+		threshold = DefaultThreshold
+	}
+	state.WithLabelValues(name).Set(float64(Closed))
+	return &Breaker{name: name, threshold: threshold, cooldown: cooldown, clock: clk}
+}
+
+// Call calls fn unless the breaker is open, and records its outcome. The calls failed fast return an OpenError
+// requeuing the object once the cooldown elapsed, see errors.Result. The terminal errors and the cancellations,
+// which don't tell whether the external system is available, are not counted as failures. A nil breaker calls fn.
+func (b *Breaker) Call(fn func() error) error {
+	if b == nil {
+		return fn()
+	}
+	if wait, err := b.allow(); err != nil {
+		return errors.RequeueAfter(err, wait)
+	}
+	err := fn()
+	b.record(err)
+	return err
+}
+
+// allow returns an OpenError and the remaining cooldown if the call must fail fast
+func (b *Breaker) allow() (time.Duration, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Open:
+		remaining := b.cooldown - b.clock.Since(b.openedAt)
+		if remaining > 0 {
+			return remaining, &OpenError{Breaker: b.name, Err: b.lastErr}
+		}
+		b.setState(HalfOpen)
+	case HalfOpen:
+		if b.probing {
+			// the other calls wait for the outcome of the call in flight
+			return b.cooldown, &OpenError{Breaker: b.name, Err: b.lastErr}
+		}
+	}
+	b.probing = b.state == HalfOpen
+	return 0, nil
+}
+
+func (b *Breaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if err == nil {
+		b.failures = 0
+		b.lastErr = nil
+		b.setState(Closed)
+		return
+	}
+	if errors.IsTerminal(err) || stderrors.Is(err, context.Canceled) {
+		return
+	}
+
+	b.failures++
+	b.lastErr = err
+	if b.state == HalfOpen || b.failures >= b.threshold {
+		if b.state != Open {
+			trips.WithLabelValues(b.name).Inc()
+		}
+		b.openedAt = b.clock.Now()
+		b.setState(Open)
+	}
+}
+
+func (b *Breaker) setState(s State) {
+	b.state = s
+	state.WithLabelValues(b.name).Set(float64(s))
+}
+
+// State returns the state of the breaker, Closed for a nil breaker
+func (b *Breaker) State() State {
+	if b == nil {
+		return Closed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Condition returns the status, the reason and the message of the DependencyAvailable condition of the objects
+// reconciled through the breaker
+func (b *Breaker) Condition() (metav1.ConditionStatus, string, string) {
+	if b == nil {
+		return metav1.ConditionTrue, "Available", ""
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Open:
+		return metav1.ConditionFalse, "CircuitOpen", fmt.Sprintf(
+			"the calls to the external systems are failed fast after %d consecutive failures, the last one: %v",
+			b.failures, b.lastErr)
+	case HalfOpen:
+		return metav1.ConditionUnknown, "CircuitHalfOpen", "the availability of the external systems is being checked"
+	default:
+		return metav1.ConditionTrue, "Available", ""
+	}
+}
+`
+
+const circuitBreakerTestTemplate = `{{ .Boilerplate }}
+
+package circuitbreaker
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/clock"
+
+	"{{ .Repo }}/internal/errors"
+)
+
+func TestBreakerOpensAfterThreshold(t *testing.T) {
+	clk := clock.NewFakeClock(time.Now())
+	b := New("test-open", 3, time.Minute, clk)
+	failure := fmt.Errorf("unavailable")
+
+	for i := 0; i < 3; i++ {
+		if err := b.Call(func() error { return failure }); err != failure {
+			t.Fatalf("expected call %d to return its failure, got %v", i+1, err)
+		}
+	}
+	if b.State() != Open {
+		t.Fatalf("expected the breaker to open after 3 failures, got %s", b.State())
+	}
+
+	called := false
+	err := b.Call(func() error {
+		called = true
+		return nil
+	})
+	if called || !IsOpen(err) {
+		t.Errorf("expected the call to fail fast, got %v", err)
+	}
+	if after, ok := errors.IsRequeueAfter(err); !ok || after != time.Minute {
+		t.Errorf("expected the object to be requeued after the cooldown, got %s", after)
+	}
+	if status, reason, _ := b.Condition(); status != metav1.ConditionFalse || reason != "CircuitOpen" {
+		t.Errorf("expected the condition of an open breaker, got %s %s", status, reason)
+	}
+}
+
+func TestBreakerHalfOpensAfterCooldown(t *testing.T) {
+	clk := clock.NewFakeClock(time.Now())
+	b := New("test-half-open", 1, time.Minute, clk)
+	failure := fmt.Errorf("unavailable")
+
+	_ = b.Call(func() error { return failure })
+	clk.Step(time.Minute)
+
+	// the single call of the half-open breaker failing opens it again
+	if err := b.Call(func() error { return failure }); err != failure {
+		t.Fatalf("expected the call to be let through after the cooldown, got %v", err)
+	}
+	if b.State() != Open {
+		t.Fatalf("expected the breaker to open again, got %s", b.State())
+	}
+
+	clk.Step(time.Minute)
+	if err := b.Call(func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if b.State() != Closed {
+		t.Errorf("expected the breaker to close after a success, got %s", b.State())
+	}
+	if status, reason, _ := b.Condition(); status != metav1.ConditionTrue || reason != "Available" {
+		t.Errorf("expected the condition of a closed breaker, got %s %s", status, reason)
+	}
+}
+
+func TestBreakerIgnoresTerminalErrors(t *testing.T) {
+	b := New("test-terminal", 1, time.Minute, clock.NewFakeClock(time.Now()))
+
+	_ = b.Call(func() error { return errors.Terminal(fmt.Errorf("invalid spec")) })
+	if b.State() != Closed {
+		t.Errorf("expected a terminal error not to open the breaker, got %s", b.State())
+	}
+
+	var nilBreaker *Breaker
+	if err := nilBreaker.Call(func() error { return nil }); err != nil || nilBreaker.State() != Closed {
+		t.Errorf("expected a nil breaker to let the calls through, got %v", err)
+	}
+}
+`
@@ -17,14 +17,13 @@ limitations under the License.
 package controller
 
 import (
+	"fmt"
 	"path/filepath"
-	"strings"
-
-	"github.com/gobuffalo/flect"
 
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+	scaffoldv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2"
 )
 
 var _ input.File = &Controller{}
@@ -44,28 +43,173 @@ type Controller struct {
 
 	// Is the Group + "." + Domain for the Resource
 	GroupDomain string
+
+	// RBACGroup is the group of the rbac markers of the Resource, the empty group for the kinds of the core group
+	RBACGroup string
+
+	// References are the fields of the spec of the Resource referencing objects of other kinds
+	References []*scaffoldv2.Reference
+
+	// ReferenceImports are the import specs of the packages of the referenced kinds
+	ReferenceImports []string
+
+	// ReferenceRBAC are the rbac markers allowing to read the referenced objects
+	ReferenceRBAC []string
+
+	// Children are the kinds of the objects managed by the Controller, adopted when they exist
+	Children []*scaffoldv2.Child
+
+	// ChildImports are the import specs of the packages of the children kinds
+	ChildImports []string
+
+	// ChildRBAC are the rbac markers allowing to manage the children objects
+	ChildRBAC []string
+
+	// HealthCheck indicates that the reconciles are recorded in a heartbeat checked by the manager
+	HealthCheck bool
+
+	// WatchConfig indicates that the controller reads the configuration of the operator and reconciles every
+	// object when it changes
+	WatchConfig bool
+
+	// MessageBus indicates that the controller reconciles the objects named by the messages of a message bus
+	MessageBus bool
+
+	// Tenancy indicates that the reconciles only access the objects of the namespace of the reconciled object
+	Tenancy bool
+
+	// ValidateInReconcile indicates that the reconciles validate the objects with the validation of their
+	// validating webhook, for the clusters where the webhooks are disallowed
+	ValidateInReconcile bool
+
+	// Finalizer indicates that the reconciles add a finalizer to the objects and clean up after them when they are
+	// deleted, before removing it
+	Finalizer bool
+
+	// ApplyConditions indicates that the reconciles apply the conditions of the objects with server-side apply, as
+	// the field manager of the controller
+	ApplyConditions bool
+
+	// BackupHooks indicates that the reconciler has the stubs of the hooks Velero runs in the pods of the workloads
+	// of the objects around their backups and restores
+	BackupHooks bool
+
+	// LargeScale indicates that the reconciler reads through the reader of the API server, bypassing the cache of
+	// the manager, and lists the objects in pages, for the operators of very large numbers of objects
+	LargeScale bool
+
+	// LongRunning indicates that the reconciles run the steps of the long-running operation of the objects,
+	// resuming it from the progress persisted in their status
+	LongRunning bool
+
+	// CircuitBreaker indicates that the reconciler calls the external systems the objects depend on through a
+	// circuit breaker, whose state is recorded in the DependencyAvailable condition of the objects
+	CircuitBreaker bool
+
+	// Reconcile is the body of the reconciler creating and updating an object of a built-in kind for every object
+	// of the Resource, nil to leave the logic of the reconciler to the user
+	Reconcile *ReconcileTemplate
+
+	// ReconcileImports are the import specs of the packages used by the body of the reconciler
+	ReconcileImports []string
+
+	// ReconcileRBAC is the rbac marker allowing to manage the objects of the body of the reconciler, empty if a
+	// child kind allows it already
+	ReconcileRBAC string
+
+	// ReconcilerName is the name of the reconciler type of the Resource
+	ReconcilerName string
 }
 
 // GetInput implements input.File
 func (f *Controller) GetInput() (input.Input, error) {
 
-	f.ResourcePackage, f.GroupDomain = util.GetResourceInfo(f.Resource, f.Repo, f.Domain, f.MultiGroup)
+	f.ResourcePackage, f.GroupDomain = util.GetResourceInfo(f.ProjectPath, f.Resource, f.Repo, f.Domain, f.MultiGroup)
+	f.RBACGroup = util.RBACGroup(f.GroupDomain)
 
 	if f.Plural == "" {
-		f.Plural = flect.Pluralize(strings.ToLower(f.Resource.Kind))
+		f.Plural = f.Resource.Plural()
 	}
 
+	f.ReconcilerName = f.Naming.ReconcilerName(f.Resource.Kind)
+
 	if f.Path == "" {
 		if f.MultiGroup {
 			f.Path = filepath.Join("controllers",
 				f.Resource.Group,
-				strings.ToLower(f.Resource.Kind)+"_controller.go")
+				f.Naming.FileName(f.Resource.Kind)+"_controller.go")
 		} else {
 			f.Path = filepath.Join("controllers",
-				strings.ToLower(f.Resource.Kind)+"_controller.go")
+				f.Naming.FileName(f.Resource.Kind)+"_controller.go")
+		}
+	}
+
+	resourceImport := fmt.Sprintf("%s%s %q", f.Resource.GroupImportSafe, f.Resource.Version,
+		f.ResourcePackage+"/"+f.Resource.Version)
+	seen := map[string]bool{resourceImport: true}
+	for _, ref := range f.References {
+		ref.Package, ref.GroupDomain = util.GetResourceInfo(f.ProjectPath, ref.Resource, f.Repo, f.Domain, f.MultiGroup)
+		ref.Package += "/" + ref.Resource.Version
+
+		spec := fmt.Sprintf("%s%s %q", ref.Resource.GroupImportSafe, ref.Resource.Version, ref.Package)
+		if !seen[spec] {
+			seen[spec] = true
+			f.ReferenceImports = append(f.ReferenceImports, spec)
+		}
+		marker := fmt.Sprintf("// +kubebuilder:rbac:groups=%s,resources=%s,verbs=get;list;watch",
+			util.RBACGroup(ref.GroupDomain), ref.Resource.Resource)
+		if !seen[marker] {
+			seen[marker] = true
+			f.ReferenceRBAC = append(f.ReferenceRBAC, marker)
 		}
 	}
+	if len(f.Children) > 0 {
+		// the warnings recorded when the children can't be adopted use the event types of the core API
+		if spec := `corev1 "k8s.io/api/core/v1"`; !seen[spec] {
+			seen[spec] = true
+			f.ChildImports = append(f.ChildImports, spec)
+		}
+	}
+	for _, child := range f.Children {
+		child.Package, child.GroupDomain = util.GetResourceInfo(f.ProjectPath, child.Resource, f.Repo, f.Domain,
+			f.MultiGroup)
+		child.Package += "/" + child.Resource.Version
+
+		spec := fmt.Sprintf("%s%s %q", child.Resource.GroupImportSafe, child.Resource.Version, child.Package)
+		if !seen[spec] {
+			seen[spec] = true
+			f.ChildImports = append(f.ChildImports, spec)
+		}
+		marker := fmt.Sprintf(
+			"// +kubebuilder:rbac:groups=%s,resources=%s,verbs=get;list;watch;create;update;patch;delete",
+			util.RBACGroup(child.GroupDomain), child.Resource.Resource)
+		if !seen[marker] {
+			seen[marker] = true
+			f.ChildRBAC = append(f.ChildRBAC, marker)
+		}
+	}
+
 	f.TemplateBody = controllerTemplate
+	if f.Reconcile != nil {
+		specs := append([]string{`metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"`,
+			`"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"`, f.Reconcile.Package},
+			f.Reconcile.Imports...)
+		if f.ValidateInReconcile || f.CircuitBreaker {
+			// imported for the conditions of the validation or of the circuit breaker
+			seen[specs[0]] = true
+		}
+		for _, spec := range specs {
+			if !seen[spec] {
+				seen[spec] = true
+				f.ReconcileImports = append(f.ReconcileImports, spec)
+			}
+		}
+		if !seen[f.Reconcile.RBAC] {
+			seen[f.Reconcile.RBAC] = true
+			f.ReconcileRBAC = f.Reconcile.RBAC
+		}
+		f.TemplateBody += f.Reconcile.define()
+	}
 
 	f.Input.IfExistsAction = input.Error
 	return f.Input, nil
@@ -78,34 +222,638 @@ package controllers
 import (
 	"context"
 	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	{{ .Resource.GroupImportSafe }}{{ .Resource.Version }} "{{ .ResourcePackage }}/{{ .Resource.Version }}"
+	"{{ .Repo }}/internal/errors"
+{{- range .ReferenceImports }}
+	{{ . }}
+{{- end }}
+{{- range .ChildImports }}
+	{{ . }}
+{{- end }}
+{{- range .ReconcileImports }}
+	{{ . }}
+{{- end }}
+{{- if .Children }}
+	"{{ .Repo }}/internal/adoption"
+{{- end }}
+{{- if or .Children .Reconcile }}
+	"{{ .Repo }}/internal/labels"
+{{- end }}
+{{- if .HealthCheck }}
+	"{{ .Repo }}/heartbeat"
+{{- end }}
+{{- if .WatchConfig }}
+	"k8s.io/apimachinery/pkg/types"
+	"{{ .Repo }}/operatorconfig"
+{{- end }}
+{{- if or .WatchConfig .MessageBus }}
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+{{- end }}
+{{- if .MessageBus }}
+	"{{ .Repo }}/messagebus"
+{{- end }}
+{{- if .Tenancy }}
+	"{{ .Repo }}/tenancy"
+{{- end }}
+{{- if or .ValidateInReconcile .CircuitBreaker }}
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+{{- end }}
+{{- if .ValidateInReconcile }}
+	"{{ .Repo }}/internal/validation"
+{{- end }}
+{{- if .CircuitBreaker }}
+	"{{ .Repo }}/internal/circuitbreaker"
+{{- end }}
+{{- if .ApplyConditions }}
+	"{{ .Repo }}/internal/conditions"
+{{- end }}
+{{- if .Finalizer }}
+	"{{ .Repo }}/internal/finalizers"
+{{- end }}
+{{- if .BackupHooks }}
+	"{{ .Repo }}/internal/backup"
+{{- end }}
 )
+{{- if .Finalizer }}
+
+// {{ .Resource.Kind }}Finalizer holds the deletion of the {{ .Plural }} until the {{ .ReconcilerName }} cleans up after them
+const {{ .Resource.Kind }}Finalizer = "{{ .GroupDomain }}/finalizer"
+{{- end }}
 
-// {{ .Resource.Kind }}Reconciler reconciles a {{ .Resource.Kind }} object
-type {{ .Resource.Kind }}Reconciler struct {
+{{- if .LargeScale }}
+
+// {{ lower .Resource.Kind }}PageSize is the number of {{ .Plural }} listed per request by forEach{{ .Resource.Kind }}
+const {{ lower .Resource.Kind }}PageSize = 500
+{{- end }}
+
+// {{ .ReconcilerName }} reconciles a {{ .Resource.Kind }} object
+{{- if .LargeScale }}
+//
+// The client reads from the cache of the manager, which holds every object of the kinds the controller watches in
+// memory, e.g. the {{ .Plural }}, and starts an informer for the first read of any other kind: reading the objects of
+// the kinds which aren't watched, e.g. the Secrets, from the cache makes the manager hold all of them. Read them
+// with the APIReader instead, and restrict the cache to the namespace of the operator with the Namespace of the
+// options of the manager when the objects of the other namespaces don't matter.
+{{- end }}
+type {{ .ReconcilerName }} struct {
 	client.Client
 	Log logr.Logger
 	Scheme *runtime.Scheme
+
+	// Recorder records the events of the {{ .Plural }}
+	Recorder record.EventRecorder
+
+	// Clock is the source of the current time, faked by the tests
+	Clock clock.Clock
+{{- if .HealthCheck }}
+
+	// Heartbeat records the reconciles for the health check of the controller, optional
+	Heartbeat *heartbeat.Heartbeat
+{{- end }}
+{{- if .WatchConfig }}
+
+	// Config is the configuration of the operator, the default one if nil
+	Config *operatorconfig.Watcher
+{{- end }}
+{{- if .MessageBus }}
+
+	// Trigger triggers the reconciles of the {{ .Plural }} named by the messages of the message bus, optional
+	Trigger *messagebus.Trigger
+{{- end }}
+{{- if .LargeScale }}
+
+	// APIReader reads from the API server, bypassing the cache: a request per read, but no object held in memory
+	APIReader client.Reader
+{{- end }}
+{{- if .CircuitBreaker }}
+
+	// Breaker guards the calls to the external systems the {{ .Plural }} depend on, failing them fast while they keep
+	// failing
+	Breaker *circuitbreaker.Breaker
+{{- end }}
 }
 
-// +kubebuilder:rbac:groups={{.GroupDomain}},resources={{ .Plural }},verbs=get;list;watch;create;update;patch;delete
-// +kubebuilder:rbac:groups={{.GroupDomain}},resources={{ .Plural }}/status,verbs=get;update;patch
+// New{{ .ReconcilerName }} returns a reconciler of the {{ .Plural }} with its dependencies.
+// TODO(user): add the clients of the external systems the reconciler depends on, main.go passes them.
+func New{{ .ReconcilerName }}(c client.Client, log logr.Logger, scheme *runtime.Scheme, recorder record.EventRecorder,
+	clk clock.Clock{{ if .HealthCheck }}, hb *heartbeat.Heartbeat{{ end }}{{ if .WatchConfig }}, config *operatorconfig.Watcher{{ end }}{{ if .MessageBus }}, trigger *messagebus.Trigger{{ end }}{{ if .LargeScale }}, apiReader client.Reader{{ end }}) *{{ .ReconcilerName }} {
+	return &{{ .ReconcilerName }}{
+{{- if .Tenancy }}
+		// the reconciles of the objects of a tenant only access the objects of its namespace
+		Client: tenancy.NewClient(c),
+{{- else }}
+		Client: c,
+{{- end }}
+		Log: log,
+		Scheme: scheme,
+		Recorder: recorder,
+		Clock: clk,
+{{- if .HealthCheck }}
+		Heartbeat: hb,
+{{- end }}
+{{- if .WatchConfig }}
+		Config: config,
+{{- end }}
+{{- if .MessageBus }}
+		Trigger: trigger,
+{{- end }}
+{{- if .LargeScale }}
+		APIReader: apiReader,
+{{- end }}
+{{- if .CircuitBreaker }}
+		Breaker: circuitbreaker.New("{{ lower .Resource.Kind }}", circuitbreaker.DefaultThreshold, circuitbreaker.DefaultCooldown, clk),
+{{- end }}
+	}
+}
+
+// +kubebuilder:rbac:groups={{.RBACGroup}},resources={{ .Plural }},verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups={{.RBACGroup}},resources={{ .Plural }}/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+{{- range .ReferenceRBAC }}
+{{ . }}
+{{- end }}
+{{- range .ChildRBAC }}
+{{ . }}
+{{- end }}
+{{- if .ReconcileRBAC }}
+{{ .ReconcileRBAC }}
+{{- end }}
+
+func (r *{{ .ReconcilerName }}) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+{{- if .Tenancy }}
+	// the tenant of the {{ .Resource.Kind }} is its namespace, the children are created in the same namespace
+	ctx := tenancy.WithTenant(context.Background(), req.Namespace)
+{{- else }}
+	ctx := context.Background()
+{{- end }}
+	log := r.Log.WithValues("{{ .Resource.Kind | lower }}", req.NamespacedName)
+
+	var instance {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}
+	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
+		// not found errors can't be fixed by a requeue, the object will be reconciled again if it is recreated
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+{{- if .WatchConfig }}
+
+	// the objects are all reconciled again when the configuration changes
+	if r.Config.Get().Paused {
+		log.V(1).Info("reconciles are paused by the configuration of the operator")
+		return ctrl.Result{}, nil
+	}
+{{- end }}
+{{- if .Finalizer }}
+
+	// the deleted {{ .Resource.Kind }} is cleaned up, its finalizer holds its deletion until then
+	if !instance.DeletionTimestamp.IsZero() {
+		return r.finalize(ctx, &instance)
+	}
+	err := r.updateFinalizers(ctx, req.NamespacedName, func(instance *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) bool {
+		return instance.DeletionTimestamp.IsZero() && finalizers.Add(instance, {{ .Resource.Kind }}Finalizer)
+	})
+	if err != nil {
+		log.Error(err, "unable to add the finalizer")
+		return errors.Result(err)
+	}
+{{- end }}
+{{- if .ValidateInReconcile }}
+
+	// the webhooks may be disallowed in the cluster, the {{ .Resource.Kind }} is validated as its webhook does
+	if err := r.validate(ctx, &instance); err != nil {
+		log.Info("invalid {{ .Resource.Kind | lower }}, see its Valid condition", "error", err.Error())
+		return errors.Result(err)
+	}
+{{- end }}
+
+	// your logic here, return errors.Result(errors.Terminal(err)) for the errors a retry can't fix, e.g. an invalid
+	// spec, and errors.Result(errors.RequeueAfter(err, delay)) to retry after a delay rather than with backoff.
+	// Stamp the objects created for the {{ .Resource.Kind }} with the standard labels of labels.Stamp. Only write
+	// its status, with r.updateStatus or r.patchStatus, its spec is owned by its users.
+{{- if .ApplyConditions }}
+	// Apply the conditions owned by the controller with r.applyConditions, e.g.
+	// r.applyConditions(ctx, &instance, conditions.Condition{Type: {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.ConditionReady, Status: metav1.ConditionTrue,
+	// Reason: "Reconciled"}), the conditions of the other controllers are kept.
+{{- end }}
+{{- if .BackupHooks }}
+	// Annotate the pod templates of the workloads created for the {{ .Resource.Kind }} with
+	// backup.Annotate(&template.ObjectMeta, r.backupHooks(&instance)) for Velero to run their hooks around their
+	// backups, and label the objects recreated from the {{ .Resource.Kind }}, e.g. its ConfigMaps, with backup.Exclude.
+{{- end }}
+{{- if .LargeScale }}
+	// List the {{ .Plural }} in pages with r.forEach{{ .Resource.Kind }} rather than all at once with r.List, and read the
+	// objects of the kinds the controller doesn't watch with r.APIReader, e.g.
+	// r.APIReader.Get(ctx, client.ObjectKey{Namespace: instance.Namespace, Name: name}, &secret).
+{{- end }}
+{{- if and .CircuitBreaker (not .LongRunning) }}
+	// Call the external systems the {{ .Resource.Kind }} depends on through r.Breaker, e.g.
+	// err := r.Breaker.Call(func() error { return r.cloud.Provision(ctx, ...) }) and return errors.Result(err): the
+	// calls fail fast, requeuing the {{ .Resource.Kind }} after a cooldown, while the systems keep failing.
+{{- end }}
+{{- range .References }}
+
+	{{ .Var }}, err := r.resolve{{ .Field }}(ctx, &instance)
+	if err != nil {
+		// the {{ .Resource.Kind }} is not watched, the {{ $.Resource.Kind }} is reconciled again after a backoff until it exists
+		log.Error(err, "unable to resolve spec.{{ .JSONName }}")
+		return errors.Result(err)
+	}
+	if {{ .Var }} != nil {
+		// TODO(user): use the {{ .Resource.Kind }} referenced by spec.{{ .JSONName }}
+		log.V(1).Info("resolved spec.{{ .JSONName }}", "name", {{ .Var }}.Name)
+	}
+{{- end }}
+
+{{- range .Children }}
+
+	{{ .Var }}, err := r.claim{{ .Resource.Kind }}(ctx, &instance)
+	if err != nil {
+		log.Error(err, "unable to claim the {{ .Resource.Kind }}")
+		return errors.Result(err)
+	}
+	if {{ .Var }} == nil {
+		// TODO(user): create the {{ .Resource.Kind }} named after the {{ $.Resource.Kind }}, controlled by it and stamped by labels.Stamp
+		log.V(1).Info("{{ .Resource.Kind }} not found")
+	} else if labels.Stamp({{ .Var }}, &instance) {
+		// the adopted {{ .Resource.Kind }}, or one labelled by a previous version of the operator, gets the standard labels
+		if err := r.Update(ctx, {{ .Var }}); err != nil {
+			log.Error(err, "unable to label the {{ .Resource.Kind }}")
+			return errors.Result(err)
+		}
+	}
+{{- end }}
+{{- with .Reconcile }}
+
+	// the {{ .Kind }} named after the {{ $.Resource.Kind }} is created, and updated to its desired state when it changes
+	{{ .Var }} := &{{ .Type }}{ObjectMeta: metav1.ObjectMeta{Name: instance.Name, Namespace: instance.Namespace}}
+	op, err := controllerutil.CreateOrUpdate(ctx, r.Client, {{ .Var }}, func() error {
+		labels.Stamp({{ .Var }}, &instance)
+		r.mutate{{ .Kind }}(&instance, {{ .Var }})
+		return controllerutil.SetControllerReference(&instance, {{ .Var }}, r.Scheme)
+	})
+	if err != nil {
+		log.Error(err, "unable to reconcile the {{ .Kind }}")
+		return errors.Result(err)
+	}
+	if op != controllerutil.OperationResultNone {
+		log.V(1).Info("{{ .Kind }} reconciled", "operation", op)
+	}
+{{- end }}
+{{- if .LongRunning }}
+
+	// the operation of the {{ .Resource.Kind }} resumes from its progress, persisted with the status below
+	result, stepErr := r.runOperation(ctx, &instance)
+	if stepErr != nil {
+		log.Error(stepErr, "operation step failed, retrying")
+	}
+	operation := instance.Status.Operation
+{{- end }}
+
+	err {{ if or .References .Children .Finalizer .Reconcile }}={{ else }}:={{ end }} r.updateStatus(ctx, req.NamespacedName, func(instance *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) {
+		// set the observed state of the {{ .Resource.Kind }} here
+{{- if .LongRunning }}
+		instance.Status.Operation = operation
+{{- end }}
+{{- if .CircuitBreaker }}
+		status, reason, message := r.Breaker.Condition()
+		{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.SetCondition(&instance.Status.Conditions, {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.Condition{
+			Type:               circuitbreaker.ConditionDependencyAvailable,
+			Status:             status,
+			LastTransitionTime: metav1.NewTime(r.Clock.Now()),
+			Reason:             reason,
+			Message:            message,
+		})
+{{- end }}
+	})
+	if err != nil {
+		if apierrors.IsConflict(err) {
+			// the object is still being modified concurrently, it is requeued to try again with its next version
+			log.V(1).Info("conflict updating status, requeuing")
+		} else {
+			log.Error(err, "unable to update status")
+		}
+		return errors.Result(err)
+	}
 
-func (r *{{ .Resource.Kind }}Reconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
-	_ = context.Background()
-	_ = r.Log.WithValues("{{ .Resource.Kind | lower }}", req.NamespacedName)
+{{- if .LongRunning }}
 
-	// your logic here
+	return result, stepErr
+{{- else }}
 
 	return ctrl.Result{}, nil
+{{- end }}
+}
+
+// updateStatus applies mutate to the latest version of the {{ .Resource.Kind }} and updates its status,
+// retrying with backoff when the update conflicts with a concurrent modification of the object
+func (r *{{ .ReconcilerName }}) updateStatus(ctx context.Context, key client.ObjectKey,
+	mutate func(*{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }})) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		var instance {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}
+		if err := r.Get(ctx, key, &instance); err != nil {
+			return err
+		}
+
+		mutate(&instance)
+
+		return r.Status().Update(ctx, &instance)
+	})
+}
+
+// patchStatus patches the status of the {{ .Resource.Kind }} with the changes mutate makes to it. Unlike updateStatus,
+// it doesn't conflict with the concurrent modifications of the {{ .Resource.Kind }}, the fields it sets overwrite theirs.
+// Only the status subresource is patched: a reconciler never writes the spec of its own objects, which
+// kubebuilder lint controllers checks.
+func (r *{{ .ReconcilerName }}) patchStatus(ctx context.Context, instance *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }},
+	mutate func(*{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }})) error {
+	base := instance.DeepCopy()
+	mutate(instance)
+	return r.Status().Patch(ctx, instance, client.MergeFrom(base))
+}
+{{- with .Reconcile }}
+
+// mutate{{ .Kind }} sets the desired state of the {{ .Kind }} of the {{ $.Resource.Kind }}. It is called with the existing
+// {{ .Kind }}, or a new one, and only sets the fields the controller owns: the ones defaulted by the API server or set
+// by the other controllers are kept, the {{ .Kind }} is only updated when its desired state changes.
+func (r *{{ $.ReconcilerName }}) mutate{{ .Kind }}(instance *{{ $.Resource.GroupImportSafe }}{{ $.Resource.Version }}.{{ $.Resource.Kind }}, {{ .Var }} *{{ .Type }}) {
+{{- template "mutate" $ }}}
+{{- end }}
+{{- if .Finalizer }}
+
+// finalize cleans up after the deleted {{ .Resource.Kind }} and removes its finalizer for its deletion to complete.
+// The cleanup is retried with backoff until it succeeds.
+func (r *{{ .ReconcilerName }}) finalize(ctx context.Context,
+	instance *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) (ctrl.Result, error) {
+	if !finalizers.Has(instance, {{ .Resource.Kind }}Finalizer) {
+		// cleaned up already, the deletion is held by the other finalizers
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.cleanup(ctx, instance); err != nil {
+		r.Log.Error(err, "unable to clean up the deleted {{ .Resource.Kind | lower }}", "name", instance.Name)
+		return errors.Result(err)
+	}
+
+	key := client.ObjectKey{ {{- if .Resource.Namespaced }}Namespace: instance.Namespace, {{ end }}Name: instance.Name}
+	err := r.updateFinalizers(ctx, key, func(instance *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) bool {
+		return finalizers.Remove(instance, {{ .Resource.Kind }}Finalizer)
+	})
+	// the {{ .Resource.Kind }} is gone once its last finalizer is removed
+	return errors.Result(client.IgnoreNotFound(err))
+}
+
+// cleanup cleans up after the deleted {{ .Resource.Kind }}, it must be idempotent: it runs again when it fails or
+// when the finalizer can't be removed
+func (r *{{ .ReconcilerName }}) cleanup(ctx context.Context,
+	instance *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) error {
+	// TODO(user): delete the resources of the {{ .Resource.Kind }} outside of the cluster, e.g. a cloud resource. The
+	// objects it owns in the cluster are deleted by the garbage collector.
+	return nil
+}
+
+// updateFinalizers applies mutate to the finalizers of the latest version of the {{ .Resource.Kind }} and updates it
+// when they changed, retrying with backoff when the update conflicts with a concurrent modification of the object
+func (r *{{ .ReconcilerName }}) updateFinalizers(ctx context.Context, key client.ObjectKey,
+	mutate func(*{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) bool) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		var instance {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}
+		if err := r.Get(ctx, key, &instance); err != nil {
+			return err
+		}
+		if !mutate(&instance) {
+			return nil
+		}
+
+		// the finalizers are metadata, the spec of the {{ .Resource.Kind }} is left alone
+		// +kubebuilder:lint:ignore=own-spec-write
+		return r.Update(ctx, &instance)
+	})
+}
+{{- end }}
+{{- if .ApplyConditions }}
+
+// applyConditions applies the conditions owned by the controller to the status of the {{ .Resource.Kind }} with
+// server-side apply, as the {{ lower .Resource.Kind }}-controller field manager. The conditions set by the other
+// controllers are kept, the ones of the controller not applied again are removed: apply all of them every time.
+func (r *{{ .ReconcilerName }}) applyConditions(ctx context.Context,
+	instance *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}, owned ...conditions.Condition) error {
+	applier := &conditions.Applier{
+		Client:       r.Client,
+		Scheme:       r.Scheme,
+		FieldManager: "{{ lower .Resource.Kind }}-controller",
+		Clock:        r.Clock,
+	}
+	return applier.Apply(ctx, instance, owned...)
+}
+{{- end }}
+{{- if .BackupHooks }}
+
+// backupHook returns the hook Velero runs in the pods of the workloads of the {{ .Resource.Kind }} before backing them
+// up, e.g. to flush and freeze their data while their volumes are snapshotted, nil if none
+func (r *{{ .ReconcilerName }}) backupHook(instance *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) *backup.Hook {
+	// TODO(user): return the hook, e.g. &backup.Hook{Container: "db", Command: []string{"/bin/sh", "-c",
+	// "fsfreeze --freeze /data"}, OnError: backup.Fail}, and unfreeze the data in the PostBackup hook
+	return nil
+}
+
+// restoreHook returns the hook Velero runs in the pods of the workloads of the {{ .Resource.Kind }} once they are restored,
+// e.g. to check their data, nil if none
+func (r *{{ .ReconcilerName }}) restoreHook(instance *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) *backup.Hook {
+	// TODO(user): return the hook, e.g. &backup.Hook{Container: "db", Command: []string{"/check"}}
+	return nil
+}
+
+// backupHooks returns the hooks of the pods of the workloads of the {{ .Resource.Kind }}, set on their pod templates
+// with backup.Annotate
+func (r *{{ .ReconcilerName }}) backupHooks(instance *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) backup.Hooks {
+	return backup.Hooks{PreBackup: r.backupHook(instance), PostRestore: r.restoreHook(instance)}
+}
+{{- end }}
+{{- if .LargeScale }}
+
+// forEach{{ .Resource.Kind }} calls fn with every {{ .Resource.Kind }} matching the options, e.g. client.InNamespace, listed
+// from the API server in pages of {{ lower .Resource.Kind }}PageSize. The cache ignores client.Limit and client.Continue
+// and returns all the objects at once, the pages are listed through the APIReader: a request per page, but only one
+// page in memory at a time. fn must not keep the {{ .Plural }}, the page is released once it returns. A page listed
+// after its continue token expired fails with a Gone error, retried with the reconcile.
+func (r *{{ .ReconcilerName }}) forEach{{ .Resource.Kind }}(ctx context.Context,
+	fn func(*{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) error, opts ...client.ListOption) error {
+	var continueToken string
+	for {
+		var list {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}List
+		pageOpts := append([]client.ListOption{client.Limit({{ lower .Resource.Kind }}PageSize),
+			client.Continue(continueToken)}, opts...)
+		if err := r.APIReader.List(ctx, &list, pageOpts...); err != nil {
+			return err
+		}
+		for i := range list.Items {
+			if err := fn(&list.Items[i]); err != nil {
+				return err
+			}
+		}
+		continueToken = list.Continue
+		if continueToken == "" {
+			return nil
+		}
+	}
+}
+{{- end }}
+{{- if .ValidateInReconcile }}
+
+// validate validates the {{ .Resource.Kind }} with the validation of its webhook and records the result in its Valid
+// condition. It returns a terminal error if the {{ .Resource.Kind }} is invalid, it is reconciled again when it changes.
+func (r *{{ .ReconcilerName }}) validate(ctx context.Context,
+	instance *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) error {
+	invalid := validation.Validate(instance)
+	status, reason, message := validation.Condition(invalid)
+
+	condition := {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.Condition{
+		Type:               validation.ConditionValid,
+		Status:             status,
+		LastTransitionTime: metav1.NewTime(r.Clock.Now()),
+		Reason:             reason,
+		Message:            message,
+	}
+	recorded := {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.FindCondition(instance.Status.Conditions, condition.Type)
+	if recorded == nil || recorded.Status != status || recorded.Reason != reason || recorded.Message != message {
+		key := client.ObjectKey{Namespace: instance.Namespace, Name: instance.Name}
+		err := r.updateStatus(ctx, key, func(instance *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) {
+			{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.SetCondition(&instance.Status.Conditions, condition)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return errors.Terminal(invalid)
+}
+{{- end }}
+
+{{- range .References }}
+
+// resolve{{ .Field }} returns the {{ .Resource.Kind }} referenced by spec.{{ .JSONName }} of the {{ $.Resource.Kind }}, nil if the reference is not set
+func (r *{{ $.ReconcilerName }}) resolve{{ .Field }}(ctx context.Context,
+	instance *{{ $.Resource.GroupImportSafe }}{{ $.Resource.Version }}.{{ $.Resource.Kind }}) (*{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}, error) {
+	ref := instance.Spec.{{ .Field }}
+	if ref.Name == "" {
+		return nil, nil
+	}
+
+	var obj {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}
+	key := client.ObjectKey{ {{- if .CrossNamespace }}Namespace: ref.Namespace, {{ else if not .ClusterScoped }}Namespace: instance.Namespace, {{ end }}Name: ref.Name}
+	if err := r.Get(ctx, key, &obj); err != nil {
+		return nil, err
+	}
+	return &obj, nil
+}
+{{- end }}
+
+{{- range .Children }}
+
+// claim{{ .Resource.Kind }} returns the {{ .Resource.Kind }} named after the {{ $.Resource.Kind }}, nil if it doesn't exist. An existing
+// {{ .Resource.Kind }} which no object controls, e.g. created by hand or by helm, is adopted if spec.adoptExisting is set;
+// it is left alone with a terminal error otherwise, as one controlled by another object.
+func (r *{{ $.ReconcilerName }}) claim{{ .Resource.Kind }}(ctx context.Context,
+	instance *{{ $.Resource.GroupImportSafe }}{{ $.Resource.Version }}.{{ $.Resource.Kind }}) (*{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}, error) {
+	var {{ .Var }} {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}
+	key := client.ObjectKey{ {{- if $.Resource.Namespaced }}Namespace: instance.Namespace, {{ end }}Name: instance.Name}
+	if err := r.Get(ctx, key, &{{ .Var }}); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+
+	err := adoption.Claim(ctx, r.Client, r.Scheme, instance, &{{ .Var }}, instance.Spec.AdoptExisting)
+	if adoption.IsNotAdopted(err) {
+		// the {{ $.Resource.Kind }} is reconciled again when its spec changes, e.g. to enable the adoption
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "NotAdopted", "{{ .Resource.Kind }} %v", err)
+		return nil, errors.Terminal(err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &{{ .Var }}, nil
 }
+{{- end }}
 
-func (r *{{ .Resource.Kind }}Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *{{ .ReconcilerName }}) SetupWithManager(mgr ctrl.Manager) error {
+{{- if or .WatchConfig .MessageBus }}
+	b := ctrl.NewControllerManagedBy(mgr).
+		For(&{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{})
+{{- range .Children }}
+	b = b.Owns(&{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{})
+{{- end }}
+{{- with .Reconcile }}
+	b = b.Owns(&{{ .Type }}{})
+{{- end }}
+{{- if .WatchConfig }}
+	if r.Config != nil {
+		b = b.Watches(r.Config.Subscribe(),
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.requestsOnConfigChange)})
+	}
+{{- end }}
+{{- if .MessageBus }}
+	if r.Trigger != nil {
+		// the {{ .Plural }} named by the messages of the bus are reconciled
+		b = b.Watches(r.Trigger.Source(), &handler.EnqueueRequestForObject{})
+	}
+{{- end }}
+{{- if .HealthCheck }}
+	return b.Complete(r.Heartbeat.Reconciler(r))
+{{- else }}
+	return b.Complete(r)
+{{- end }}
+{{- else }}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{}).
+{{- range .Children }}
+		Owns(&{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{}).
+{{- end }}
+{{- with .Reconcile }}
+		Owns(&{{ .Type }}{}).
+{{- end }}
+{{- if .HealthCheck }}
+		Complete(r.Heartbeat.Reconciler(r))
+{{- else }}
 		Complete(r)
+{{- end }}
+{{- end }}
+}
+{{- if .WatchConfig }}
+
+// requestsOnConfigChange returns the requests reconciling every {{ .Resource.Kind }} when the configuration of the
+// operator changes
+func (r *{{ .ReconcilerName }}) requestsOnConfigChange(handler.MapObject) []ctrl.Request {
+{{- if .LargeScale }}
+	var requests []ctrl.Request
+	err := r.forEach{{ .Resource.Kind }}(context.Background(), func(item *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) error {
+		requests = append(requests, ctrl.Request{
+			NamespacedName: types.NamespacedName{Namespace: item.Namespace, Name: item.Name},
+		})
+		return nil
+	})
+	if err != nil {
+		r.Log.Error(err, "unable to list the {{ .Plural }} to reconcile on configuration change")
+		return nil
+	}
+	return requests
+{{- else }}
+	var list {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}List
+	if err := r.List(context.Background(), &list); err != nil {
+		r.Log.Error(err, "unable to list the {{ .Plural }} to reconcile on configuration change")
+		return nil
+	}
+	requests := make([]ctrl.Request, 0, len(list.Items))
+	for _, item := range list.Items {
+		requests = append(requests, ctrl.Request{
+			NamespacedName: types.NamespacedName{Namespace: item.Namespace, Name: item.Name},
+		})
+	}
+	return requests
+{{- end }}
 }
+{{- end }}
 `
@@ -19,6 +19,8 @@ package controller
 import (
 	"fmt"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
@@ -35,6 +37,9 @@ type SuiteTest struct {
 
 	// Resource is the Resource to make the Controller for
 	Resource *resource.Resource
+
+	// CRDDirectoryPath are the quoted elements of the path of the CRDs relative to the suite
+	CRDDirectoryPath string
 }
 
 // GetInput implements input.File
@@ -48,6 +53,16 @@ func (f *SuiteTest) GetInput() (input.Input, error) {
 		}
 	}
 
+	rel, err := filepath.Rel(filepath.Dir(f.Path), filepath.Join(f.CRDDir, "bases"))
+	if err != nil {
+		return input.Input{}, err
+	}
+	var elems []string
+	for _, elem := range strings.Split(filepath.ToSlash(rel), "/") {
+		elems = append(elems, strconv.Quote(elem))
+	}
+	f.CRDDirectoryPath = strings.Join(elems, ", ")
+
 	f.TemplateBody = controllerSuiteTestTemplate
 	return f.Input, nil
 }
@@ -62,10 +77,13 @@ const controllerSuiteTestTemplate = `{{ .Boilerplate }}
 package controllers
 
 import (
+	"context"
 	"path/filepath"
 	"testing"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -77,11 +95,17 @@ import (
 
 // These tests use Ginkgo (BDD-style Go testing framework). Refer to
 // http://onsi.github.io/ginkgo/ to learn more about Ginkgo.
+// The specs can run in parallel with 'ginkgo -p' (make test-parallel), every ginkgo process runs
+// BeforeSuite and starts its own test environment.
 
 var cfg *rest.Config
 var k8sClient client.Client
 var testEnv *envtest.Environment
 
+// testNamespace is the namespace of the running spec. Every spec creates its objects in its own namespace,
+// it doesn't depend on the objects of the previous specs, e.g. left over by a failure.
+var testNamespace string
+
 func TestAPIs(t *testing.T) {
 	RegisterFailHandler(Fail)
 
@@ -95,7 +119,7 @@ var _ = BeforeSuite(func(done Done) {
 
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
-		CRDDirectoryPaths: []string{filepath.Join("..", "config", "crd", "bases")},
+		CRDDirectoryPaths: []string{filepath.Join({{ .CRDDirectoryPath }})},
 	}
 
 	var err error
@@ -112,6 +136,18 @@ var _ = BeforeSuite(func(done Done) {
 	close(done)
 }, 60)
 
+var _ = BeforeEach(func() {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "test-"}}
+	Expect(k8sClient.Create(context.Background(), ns)).To(Succeed())
+	testNamespace = ns.Name
+})
+
+var _ = AfterEach(func() {
+	// the test environment runs no namespace controller, the namespace is only marked for deletion
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testNamespace}}
+	Expect(client.IgnoreNotFound(k8sClient.Delete(context.Background(), ns))).To(Succeed())
+})
+
 var _ = AfterSuite(func() {
 	By("tearing down the test environment")
 	err := testEnv.Stop()
@@ -123,7 +159,7 @@ var _ = AfterSuite(func() {
 // adding import paths and code setup for new types.
 func (f *SuiteTest) Update() error {
 
-	resourcePackage, _ := util.GetResourceInfo(f.Resource, f.Repo, f.Domain, f.MultiGroup)
+	resourcePackage, _ := util.GetResourceInfo(f.ProjectPath, f.Resource, f.Repo, f.Domain, f.MultiGroup)
 
 	ctrlImportCodeFragment := fmt.Sprintf(`"%s/controllers"
 `, f.Repo)
@@ -135,7 +171,7 @@ Expect(err).NotTo(HaveOccurred())
 
 `, f.Resource.GroupImportSafe, f.Resource.Version)
 
-	err := internal.InsertStringsInFile(f.Path,
+	err := internal.InsertStringsInFile(filepath.Join(f.ProjectPath, f.Path),
 		map[string][]string{
 			scaffoldv2.APIPkgImportScaffoldMarker: {ctrlImportCodeFragment, apiImportCodeFragment},
 			scaffoldv2.APISchemeScaffoldMarker:    {addschemeCodeFragment},
@@ -0,0 +1,472 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+	scaffoldv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2"
+)
+
+var _ input.File = &ControllerTest{}
+
+// ControllerTest scaffolds the unit tests of a Controller exercising its conflict handling
+type ControllerTest struct {
+	input.Input
+
+	// Resource is the Resource to make the Controller for
+	Resource *resource.Resource
+
+	// ResourcePackage is the package of the Resource
+	ResourcePackage string
+
+	// Plural is the plural lowercase of kind
+	Plural string
+
+	// Is the Group + "." + Domain for the Resource
+	GroupDomain string
+
+	// ReconcilerName is the name of the reconciler type of the Resource
+	ReconcilerName string
+
+	// HealthCheck, WatchConfig and MessageBus indicate that the constructor of the reconciler takes its heartbeat,
+	// the configuration watcher and the trigger of the message bus, which the tests leave nil
+	HealthCheck bool
+	WatchConfig bool
+	MessageBus  bool
+
+	// LargeScale indicates that the constructor of the reconciler takes the reader of the API server, which the tests
+	// replace by their client
+	LargeScale bool
+
+	// Finalizer indicates that the reconciler adds a finalizer to the objects, which the tests check the lifecycle of
+	Finalizer bool
+
+	// Tenancy indicates that the reconciler only accesses the objects of the namespace of the reconciled object
+	Tenancy bool
+
+	// Children are the kinds of the objects managed by the Controller, which the tests adopt
+	Children []*scaffoldv2.Child
+
+	// Reconcile is the reconcile template of the reconciler, whose kind is added to the scheme of the tests
+	Reconcile *ReconcileTemplate
+
+	// ChildImports are the import specs of the packages of the children kinds
+	ChildImports []string
+
+	// ChildSchemes are the package names of the children kinds whose types are added to the scheme
+	ChildSchemes []string
+}
+
+// GetInput implements input.File
+func (f *ControllerTest) GetInput() (input.Input, error) {
+
+	f.ResourcePackage, f.GroupDomain = util.GetResourceInfo(f.ProjectPath, f.Resource, f.Repo, f.Domain, f.MultiGroup)
+
+	if f.Plural == "" {
+		f.Plural = f.Resource.Plural()
+	}
+
+	f.ReconcilerName = f.Naming.ReconcilerName(f.Resource.Kind)
+
+	if f.Path == "" {
+		if f.MultiGroup {
+			f.Path = filepath.Join("controllers",
+				f.Resource.Group,
+				f.Naming.FileName(f.Resource.Kind)+"_controller_test.go")
+		} else {
+			f.Path = filepath.Join("controllers",
+				f.Naming.FileName(f.Resource.Kind)+"_controller_test.go")
+		}
+	}
+	f.ChildImports, f.ChildSchemes = childPackages(f.Input, f.Resource, f.Children, f.Reconcile)
+
+	f.TemplateBody = controllerTestTemplate
+
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *ControllerTest) Validate() error {
+	return f.Resource.Validate()
+}
+
+// childPackages returns the import specs and the names of the packages of the children kinds and of the kind of
+// the reconcile template other than the package of the resource
+func childPackages(in input.Input, r *resource.Resource, children []*scaffoldv2.Child,
+	reconcile *ReconcileTemplate) ([]string, []string) {
+	var imports, names []string
+	seen := map[string]bool{r.GroupImportSafe + r.Version: true}
+	for _, child := range children {
+		pkg, _ := util.GetResourceInfo(in.ProjectPath, child.Resource, in.Repo, in.Domain, in.MultiGroup)
+		name := child.Resource.GroupImportSafe + child.Resource.Version
+		if !seen[name] {
+			seen[name] = true
+			imports = append(imports, fmt.Sprintf("%s %q", name, pkg+"/"+child.Resource.Version))
+			names = append(names, name)
+		}
+	}
+	if reconcile != nil && !seen[reconcile.packageName()] {
+		imports = append(imports, reconcile.Package)
+		names = append(names, reconcile.packageName())
+	}
+	return imports, names
+}
+
+const controllerTestTemplate = `{{ .Boilerplate }}
+
+package controllers
+
+import (
+	"context"
+	"errors"
+{{- if .LargeScale }}
+	"strconv"
+{{- end }}
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	{{ .Resource.GroupImportSafe }}{{ .Resource.Version }} "{{ .ResourcePackage }}/{{ .Resource.Version }}"
+{{- range .ChildImports }}
+	{{ . }}
+{{- end }}
+{{- if .Children }}
+	"{{ .Repo }}/internal/labels"
+{{- end }}
+{{- if .Tenancy }}
+	"{{ .Repo }}/tenancy"
+{{- end }}
+{{- if .Finalizer }}
+	"{{ .Repo }}/internal/finalizers"
+{{- end }}
+)
+
+// conflicting{{ .Resource.Kind }}Client fails the first status updates with a conflict error
+// to simulate concurrent modifications of the {{ .Resource.Kind }}
+type conflicting{{ .Resource.Kind }}Client struct {
+	client.Client
+
+	// conflicts is the number of status updates that still have to fail
+	conflicts int
+}
+
+func (c *conflicting{{ .Resource.Kind }}Client) Status() client.StatusWriter {
+	return &conflicting{{ .Resource.Kind }}StatusWriter{StatusWriter: c.Client.Status(), client: c}
+}
+
+type conflicting{{ .Resource.Kind }}StatusWriter struct {
+	client.StatusWriter
+	client *conflicting{{ .Resource.Kind }}Client
+}
+
+func (w *conflicting{{ .Resource.Kind }}StatusWriter) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	if w.client.conflicts > 0 {
+		w.client.conflicts--
+		return apierrors.NewConflict(schema.GroupResource{Group: "{{ .GroupDomain }}", Resource: "{{ .Plural }}"},
+			"test", errors.New("the object has been modified"))
+	}
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func new{{ .ReconcilerName }}WithConflicts(t *testing.T, conflicts int) (*{{ .ReconcilerName }}, *conflicting{{ .Resource.Kind }}Client) {
+	s := runtime.NewScheme()
+	if err := {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.AddToScheme(s); err != nil {
+		t.Fatalf("unable to add types to the scheme: %v", err)
+	}
+{{- range .ChildSchemes }}
+	if err := {{ . }}.AddToScheme(s); err != nil {
+		t.Fatalf("unable to add types to the scheme: %v", err)
+	}
+{{- end }}
+
+	instance := &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"{{ if .Resource.Namespaced }}, Namespace: "default"{{ end }}},
+	}
+	c := &conflicting{{ .Resource.Kind }}Client{Client: fake.NewFakeClientWithScheme(s, instance), conflicts: conflicts}
+
+	r := New{{ .ReconcilerName }}(c, ctrl.Log.WithName("controllers").WithName("{{ .Resource.Kind }}"), s,
+		&record.FakeRecorder{}, clock.NewFakeClock(time.Now()){{ if .HealthCheck }}, nil{{ end }}{{ if .WatchConfig }}, nil{{ end }}{{ if .MessageBus }}, nil{{ end }}{{ if .LargeScale }}, c{{ end }})
+	return r, c
+}
+
+func Test{{ .ReconcilerName }}RetriesStatusUpdateOnConflict(t *testing.T) {
+	t.Parallel()
+
+	r, c := new{{ .ReconcilerName }}WithConflicts(t, retry.DefaultBackoff.Steps-1)
+
+	result, err := r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: "test"{{ if .Resource.Namespaced }}, Namespace: "default"{{ end }}}})
+	if err != nil {
+		t.Fatalf("expected the conflicts to be retried, got: %v", err)
+	}
+	if result.Requeue {
+		t.Errorf("expected no requeue once the status update succeeded")
+	}
+	if c.conflicts != 0 {
+		t.Errorf("expected every simulated conflict to be hit, %d left", c.conflicts)
+	}
+}
+
+func Test{{ .ReconcilerName }}RequeuesOnPersistentConflict(t *testing.T) {
+	t.Parallel()
+
+	r, _ := new{{ .ReconcilerName }}WithConflicts(t, retry.DefaultBackoff.Steps)
+
+	result, err := r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: "test"{{ if .Resource.Namespaced }}, Namespace: "default"{{ end }}}})
+	if err != nil {
+		t.Fatalf("expected the conflict to be handled with a requeue, got: %v", err)
+	}
+	if !result.Requeue {
+		t.Errorf("expected a requeue when the conflict persists")
+	}
+}
+
+func Test{{ .ReconcilerName }}IgnoresNotFound(t *testing.T) {
+	t.Parallel()
+
+	r, _ := new{{ .ReconcilerName }}WithConflicts(t, 0)
+
+	_, err := r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: "missing"{{ if .Resource.Namespaced }}, Namespace: "default"{{ end }}}})
+	if err != nil {
+		t.Fatalf("expected deleted objects to be ignored, got: %v", err)
+	}
+}
+{{- if .Finalizer }}
+
+// conflictingFinalizers{{ .Resource.Kind }}Client fails the first updates of the {{ .Plural }} with a conflict error
+type conflictingFinalizers{{ .Resource.Kind }}Client struct {
+	client.Client
+
+	// conflicts is the number of updates that still have to fail
+	conflicts int
+}
+
+func (c *conflictingFinalizers{{ .Resource.Kind }}Client) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	if c.conflicts > 0 {
+		c.conflicts--
+		return apierrors.NewConflict(schema.GroupResource{Group: "{{ .GroupDomain }}", Resource: "{{ .Plural }}"},
+			"test", errors.New("the object has been modified"))
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func Test{{ .ReconcilerName }}AddsFinalizer(t *testing.T) {
+	t.Parallel()
+
+	r, c := new{{ .ReconcilerName }}WithConflicts(t, 0)
+	key := types.NamespacedName{Name: "test"{{ if .Resource.Namespaced }}, Namespace: "default"{{ end }}}
+
+	if _, err := r.Reconcile(ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("expected the {{ .Resource.Kind }} to be reconciled, got: %v", err)
+	}
+
+	var instance {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}
+	if err := c.Get(context.Background(), key, &instance); err != nil {
+		t.Fatalf("unable to read the {{ .Resource.Kind }}: %v", err)
+	}
+	if !finalizers.Has(&instance, {{ .Resource.Kind }}Finalizer) {
+		t.Errorf("expected the finalizer to be added, got the finalizers %v", instance.Finalizers)
+	}
+}
+
+func Test{{ .ReconcilerName }}RemovesFinalizerOnDeletion(t *testing.T) {
+	t.Parallel()
+
+	r, c := new{{ .ReconcilerName }}WithConflicts(t, 0)
+	ctx := context.Background()
+	key := types.NamespacedName{Name: "deleted"{{ if .Resource.Namespaced }}, Namespace: "default"{{ end }}}
+
+	// the {{ .Resource.Kind }} is being deleted, held by its finalizer and by the one of another controller
+	now := metav1.Now()
+	deleted := &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{ObjectMeta: metav1.ObjectMeta{
+		Name: key.Name, Namespace: key.Namespace, DeletionTimestamp: &now,
+		Finalizers: []string{ {{- .Resource.Kind }}Finalizer, "other.example.com/finalizer"},
+	}}
+	if err := c.Create(ctx, deleted); err != nil {
+		t.Fatalf("unable to create the {{ .Resource.Kind }}: %v", err)
+	}
+	// removing the finalizer conflicts with concurrent modifications of the {{ .Resource.Kind }}
+	r.Client = &conflictingFinalizers{{ .Resource.Kind }}Client{Client: r.Client, conflicts: retry.DefaultBackoff.Steps - 1}
+
+	if _, err := r.Reconcile(ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("expected the {{ .Resource.Kind }} to be finalized, got: %v", err)
+	}
+
+	var instance {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}
+	if err := c.Get(ctx, key, &instance); err != nil {
+		t.Fatalf("unable to read the {{ .Resource.Kind }}: %v", err)
+	}
+	if finalizers.Has(&instance, {{ .Resource.Kind }}Finalizer) {
+		t.Errorf("expected the finalizer to be removed, got the finalizers %v", instance.Finalizers)
+	}
+	if !finalizers.Has(&instance, "other.example.com/finalizer") {
+		t.Errorf("expected the finalizer of the other controller to be kept, got the finalizers %v",
+			instance.Finalizers)
+	}
+}
+{{- end }}
+{{- if .Tenancy }}
+
+func Test{{ .ReconcilerName }}IsolatesTenants(t *testing.T) {
+	t.Parallel()
+
+	r, c := new{{ .ReconcilerName }}WithConflicts(t, 0)
+	ctx := context.Background()
+
+	// the {{ .Plural }} of every tenant are reconciled
+	other := &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "tenant-b"}}
+	if err := c.Create(ctx, other); err != nil {
+		t.Fatalf("unable to create the {{ .Resource.Kind }} of the other tenant: %v", err)
+	}
+	for _, namespace := range []string{"default", "tenant-b"} {
+		if _, err := r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: "test", Namespace: namespace}}); err != nil {
+			t.Errorf("expected the {{ .Resource.Kind }} of the tenant %s to be reconciled, got: %v", namespace, err)
+		}
+	}
+
+	// but the reconciles of a tenant can't access the objects of the others
+	tenantCtx := tenancy.WithTenant(ctx, "default")
+	err := r.Get(tenantCtx, types.NamespacedName{Name: "test", Namespace: "tenant-b"}, &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{})
+	if !tenancy.IsCrossTenant(err) {
+		t.Errorf("expected the reconciles of the tenant default not to read the objects of tenant-b, got: %v", err)
+	}
+	if err := r.Update(tenantCtx, other); !tenancy.IsCrossTenant(err) {
+		t.Errorf("expected the reconciles of the tenant default not to write the objects of tenant-b, got: %v", err)
+	}
+}
+{{- end }}
+{{- range .Children }}
+
+func Test{{ $.ReconcilerName }}Claims{{ .Resource.Kind }}(t *testing.T) {
+	t.Parallel()
+
+	for _, adoptExisting := range []bool{true, false} {
+		r, c := new{{ $.ReconcilerName }}WithConflicts(t, 0)
+		ctx := context.Background()
+		key := types.NamespacedName{Name: "test"{{ if $.Resource.Namespaced }}, Namespace: "default"{{ end }}}
+
+		var instance {{ $.Resource.GroupImportSafe }}{{ $.Resource.Version }}.{{ $.Resource.Kind }}
+		if err := c.Get(ctx, key, &instance); err != nil {
+			t.Fatalf("unable to read the {{ $.Resource.Kind }}: %v", err)
+		}
+		instance.UID = types.UID("test-uid")
+		instance.Spec.AdoptExisting = adoptExisting
+		if err := c.Update(ctx, &instance); err != nil {
+			t.Fatalf("unable to update the {{ $.Resource.Kind }}: %v", err)
+		}
+		// the {{ .Resource.Kind }} exists before the {{ $.Resource.Kind }}, e.g. created by hand or by helm
+		existing := &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace}}
+		if err := c.Create(ctx, existing); err != nil {
+			t.Fatalf("unable to create the {{ .Resource.Kind }}: %v", err)
+		}
+
+		if _, err := r.Reconcile(ctrl.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("expected the {{ .Resource.Kind }} to be claimed without error, got: %v", err)
+		}
+
+		var {{ .Var }} {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}
+		if err := c.Get(ctx, key, &{{ .Var }}); err != nil {
+			t.Fatalf("unable to read the {{ .Resource.Kind }}: %v", err)
+		}
+		if adopted := metav1.IsControlledBy(&{{ .Var }}, &instance); adopted != adoptExisting {
+			t.Errorf("expected the {{ .Resource.Kind }} to be adopted when spec.adoptExisting is %t, adopted: %t",
+				adoptExisting, adopted)
+		}
+		if labelled := {{ .Var }}.Labels[labels.InstanceLabel] == instance.Name; labelled != adoptExisting {
+			t.Errorf("expected the {{ .Resource.Kind }} to be labelled when spec.adoptExisting is %t, labelled: %t",
+				adoptExisting, labelled)
+		}
+	}
+}
+{{- end }}
+{{- if .LargeScale }}
+
+// paged{{ .Resource.Kind }}Reader lists the {{ .Plural }} in pages of the requested limit, as the API server does,
+// the continue token being the index of the first {{ .Resource.Kind }} of the next page
+type paged{{ .Resource.Kind }}Reader struct {
+	client.Reader
+
+	items []{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}
+
+	// requests is the number of pages listed
+	requests int
+}
+
+func (r *paged{{ .Resource.Kind }}Reader) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+	start, end := 0, len(r.items)
+	if listOpts.Continue != "" {
+		start, _ = strconv.Atoi(listOpts.Continue)
+	}
+	if listOpts.Limit > 0 && start+int(listOpts.Limit) < end {
+		end = start + int(listOpts.Limit)
+	}
+
+	page := list.(*{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}List)
+	page.Items = r.items[start:end]
+	page.Continue = ""
+	if end < len(r.items) {
+		page.Continue = strconv.Itoa(end)
+	}
+	r.requests++
+	return nil
+}
+
+func Test{{ .ReconcilerName }}ListsInPages(t *testing.T) {
+	t.Parallel()
+
+	r, _ := new{{ .ReconcilerName }}WithConflicts(t, 0)
+	reader := &paged{{ .Resource.Kind }}Reader{}
+	for i := 0; i < 2*{{ lower .Resource.Kind }}PageSize+1; i++ {
+		reader.items = append(reader.items, {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{
+			ObjectMeta: metav1.ObjectMeta{Name: strconv.Itoa(i){{ if .Resource.Namespaced }}, Namespace: "default"{{ end }}},
+		})
+	}
+	r.APIReader = reader
+
+	seen := map[string]bool{}
+	err := r.forEach{{ .Resource.Kind }}(context.Background(), func(instance *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) error {
+		seen[instance.Name] = true
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(seen) != len(reader.items) {
+		t.Errorf("expected the %d {{ .Plural }} to be listed, got %d", len(reader.items), len(seen))
+	}
+	if reader.requests != 3 {
+		t.Errorf("expected the {{ .Plural }} to be listed in 3 pages, got %d", reader.requests)
+	}
+}
+{{- end }}
+`
@@ -0,0 +1,272 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+	scaffoldv2 "sigs.k8s.io/kubebuilder/pkg/scaffold/v2"
+)
+
+var _ input.File = &ControllerUnitTest{}
+
+// ControllerUnitTest scaffolds unit tests of a Controller running against a fake client
+// whose calls can be intercepted to inject errors, so they don't need envtest binaries
+type ControllerUnitTest struct {
+	input.Input
+
+	// Resource is the Resource to make the Controller for
+	Resource *resource.Resource
+
+	// ResourcePackage is the package of the Resource
+	ResourcePackage string
+
+	// Is the Group + "." + Domain for the Resource
+	GroupDomain string
+
+	// ReconcilerName is the name of the reconciler type of the Resource
+	ReconcilerName string
+
+	// HealthCheck, WatchConfig and MessageBus indicate that the constructor of the reconciler takes its heartbeat,
+	// the configuration watcher and the trigger of the message bus, which the tests leave nil
+	HealthCheck bool
+	WatchConfig bool
+	MessageBus  bool
+
+	// LargeScale indicates that the constructor of the reconciler takes the reader of the API server, which the tests
+	// replace by their client
+	LargeScale bool
+
+	// Children are the kinds of the objects managed by the Controller, whose types are added to the scheme
+	Children []*scaffoldv2.Child
+
+	// Reconcile is the reconcile template of the reconciler, whose kind is added to the scheme of the tests
+	Reconcile *ReconcileTemplate
+
+	// ChildImports are the import specs of the packages of the children kinds
+	ChildImports []string
+
+	// ChildSchemes are the package names of the children kinds whose types are added to the scheme
+	ChildSchemes []string
+}
+
+// GetInput implements input.File
+func (f *ControllerUnitTest) GetInput() (input.Input, error) {
+
+	f.ResourcePackage, f.GroupDomain = util.GetResourceInfo(f.ProjectPath, f.Resource, f.Repo, f.Domain, f.MultiGroup)
+
+	f.ReconcilerName = f.Naming.ReconcilerName(f.Resource.Kind)
+
+	if f.Path == "" {
+		if f.MultiGroup {
+			f.Path = filepath.Join("controllers",
+				f.Resource.Group,
+				f.Naming.FileName(f.Resource.Kind)+"_controller_unit_test.go")
+		} else {
+			f.Path = filepath.Join("controllers",
+				f.Naming.FileName(f.Resource.Kind)+"_controller_unit_test.go")
+		}
+	}
+	f.ChildImports, f.ChildSchemes = childPackages(f.Input, f.Resource, f.Children, f.Reconcile)
+
+	f.TemplateBody = controllerUnitTestTemplate
+
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *ControllerUnitTest) Validate() error {
+	return f.Resource.Validate()
+}
+
+const controllerUnitTestTemplate = `{{ .Boilerplate }}
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	{{ .Resource.GroupImportSafe }}{{ .Resource.Version }} "{{ .ResourcePackage }}/{{ .Resource.Version }}"
+{{- range .ChildImports }}
+	{{ . }}
+{{- end }}
+)
+
+// {{ .Resource.Kind | lower }}InterceptorFuncs replace calls of the {{ .ReconcilerName }} to the fake client,
+// nil funcs fall through to the fake client
+type {{ .Resource.Kind | lower }}InterceptorFuncs struct {
+	Get          func(ctx context.Context, c client.Client, key client.ObjectKey, obj runtime.Object) error
+	Update       func(ctx context.Context, c client.Client, obj runtime.Object, opts ...client.UpdateOption) error
+	StatusUpdate func(ctx context.Context, c client.Client, obj runtime.Object, opts ...client.UpdateOption) error
+}
+
+// intercepted{{ .Resource.Kind }}Client is a fake client whose calls go through {{ .Resource.Kind | lower }}InterceptorFuncs
+type intercepted{{ .Resource.Kind }}Client struct {
+	client.Client
+	funcs {{ .Resource.Kind | lower }}InterceptorFuncs
+}
+
+func (c *intercepted{{ .Resource.Kind }}Client) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	if c.funcs.Get != nil {
+		return c.funcs.Get(ctx, c.Client, key, obj)
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func (c *intercepted{{ .Resource.Kind }}Client) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	if c.funcs.Update != nil {
+		return c.funcs.Update(ctx, c.Client, obj, opts...)
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *intercepted{{ .Resource.Kind }}Client) Status() client.StatusWriter {
+	return &intercepted{{ .Resource.Kind }}StatusWriter{StatusWriter: c.Client.Status(), client: c}
+}
+
+type intercepted{{ .Resource.Kind }}StatusWriter struct {
+	client.StatusWriter
+	client *intercepted{{ .Resource.Kind }}Client
+}
+
+func (w *intercepted{{ .Resource.Kind }}StatusWriter) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	if w.client.funcs.StatusUpdate != nil {
+		return w.client.funcs.StatusUpdate(ctx, w.client.Client, obj, opts...)
+	}
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func new{{ .Resource.Kind }}UnitTestReconciler(t *testing.T, funcs {{ .Resource.Kind | lower }}InterceptorFuncs,
+	objs ...runtime.Object) *{{ .ReconcilerName }} {
+	s := runtime.NewScheme()
+	if err := {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.AddToScheme(s); err != nil {
+		t.Fatalf("unable to add types to the scheme: %v", err)
+	}
+{{- range .ChildSchemes }}
+	if err := {{ . }}.AddToScheme(s); err != nil {
+		t.Fatalf("unable to add types to the scheme: %v", err)
+	}
+{{- end }}
+
+	c := &intercepted{{ .Resource.Kind }}Client{Client: fake.NewFakeClientWithScheme(s, objs...), funcs: funcs}
+	return New{{ .ReconcilerName }}(c, ctrl.Log.WithName("controllers").WithName("{{ .Resource.Kind }}"), s,
+		&record.FakeRecorder{}, clock.NewFakeClock(time.Now()){{ if .HealthCheck }}, nil{{ end }}{{ if .WatchConfig }}, nil{{ end }}{{ if .MessageBus }}, nil{{ end }}{{ if .LargeScale }}, c{{ end }})
+}
+
+func Test{{ .ReconcilerName }}Unit(t *testing.T) {
+	errInjected := errors.New("injected error")
+	key := types.NamespacedName{Name: "test"{{ if .Resource.Namespaced }}, Namespace: "default"{{ end }}}
+	existing := func() runtime.Object {
+		return &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		objs    []runtime.Object
+		funcs   {{ .Resource.Kind | lower }}InterceptorFuncs
+		result  ctrl.Result
+		wantErr error
+	}{
+		{
+			name: "reconciles an existing {{ .Resource.Kind }}",
+			objs: []runtime.Object{existing()},
+		},
+		{
+			name: "ignores a deleted {{ .Resource.Kind }}",
+		},
+		{
+			name: "returns errors reading the {{ .Resource.Kind }}",
+			objs: []runtime.Object{existing()},
+			funcs: {{ .Resource.Kind | lower }}InterceptorFuncs{
+				Get: func(context.Context, client.Client, client.ObjectKey, runtime.Object) error {
+					return errInjected
+				},
+			},
+			wantErr: errInjected,
+		},
+		{
+			name: "returns errors updating the status",
+			objs: []runtime.Object{existing()},
+			funcs: {{ .Resource.Kind | lower }}InterceptorFuncs{
+				StatusUpdate: func(context.Context, client.Client, runtime.Object, ...client.UpdateOption) error {
+					return errInjected
+				},
+			},
+			wantErr: errInjected,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := new{{ .Resource.Kind }}UnitTestReconciler(t, test.funcs, test.objs...)
+
+			result, err := r.Reconcile(ctrl.Request{NamespacedName: key})
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("expected error %v, got %v", test.wantErr, err)
+			}
+			if result != test.result {
+				t.Errorf("expected result %+v, got %+v", test.result, result)
+			}
+		})
+	}
+}
+
+func Test{{ .ReconcilerName }}PatchStatus(t *testing.T) {
+	key := types.NamespacedName{Name: "test"{{ if .Resource.Namespaced }}, Namespace: "default"{{ end }}}
+	instance := &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+	}
+	r := new{{ .Resource.Kind }}UnitTestReconciler(t, {{ .Resource.Kind | lower }}InterceptorFuncs{}, instance.DeepCopy())
+	ctx := context.Background()
+	if err := r.Get(ctx, key, instance); err != nil {
+		t.Fatal(err)
+	}
+
+	ready := {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.Condition{Type: {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.ConditionReady, Status: metav1.ConditionTrue}
+	err := r.patchStatus(ctx, instance, func(instance *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) {
+		instance.Status.Conditions = append(instance.Status.Conditions, ready)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var patched {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}
+	if err := r.Get(ctx, key, &patched); err != nil {
+		t.Fatal(err)
+	}
+	if len(patched.Status.Conditions) != 1 || patched.Status.Conditions[0].Type != ready.Type {
+		t.Errorf("expected the status to be patched with the Ready condition, got %+v", patched.Status)
+	}
+}
+`
@@ -0,0 +1,226 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &Errors{}
+
+// Errors scaffolds the package classifying the errors of the reconcilers into the ones that are retried with
+// backoff, after a delay or not at all
+type Errors struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *Errors) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "errors", "errors.go")
+	}
+	f.TemplateBody = errorsTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+var _ input.File = &ErrorsTest{}
+
+// ErrorsTest scaffolds the tests of the errors package
+type ErrorsTest struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *ErrorsTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "errors", "errors_test.go")
+	}
+	f.TemplateBody = errorsTestTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+const errorsTemplate = `{{ .Boilerplate }}
+
+// Package errors classifies the errors of the reconcilers by the way they are retried:
+//
+// - terminal errors, that a retry can't fix, e.g. an invalid spec, are not retried, the object is reconciled
+//   again when it changes
+// - errors requeuing after a delay, e.g. while waiting for an external system, are retried after that delay
+// - conflicts are retried right away with the next version of the object
+// - any other error is retried with exponential backoff
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// TerminalError is an error that a retry can't fix
+type TerminalError struct {
+	Err error
+}
+
+// Terminal wraps err into a TerminalError, nil if err is nil
+func Terminal(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &TerminalError{Err: err}
+}
+
+// Error implements error
+func (e *TerminalError) Error() string {
+	return fmt.Sprintf("terminal error: %v", e.Err)
+}
+
+// Unwrap returns the wrapped error
+func (e *TerminalError) Unwrap() error {
+	return e.Err
+}
+
+// IsTerminal returns true if err is, or wraps, a TerminalError
+func IsTerminal(err error) bool {
+	var terminal *TerminalError
+	return stderrors.As(err, &terminal)
+}
+
+// RequeueAfterError is an error retried after a delay rather than with backoff
+type RequeueAfterError struct {
+	Err   error
+	After time.Duration
+}
+
+// RequeueAfter wraps err into a RequeueAfterError retried after the delay, err may be nil to requeue without error
+func RequeueAfter(err error, after time.Duration) error {
+	return &RequeueAfterError{Err: err, After: after}
+}
+
+// Error implements error
+func (e *RequeueAfterError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("requeue after %s", e.After)
+	}
+	return fmt.Sprintf("%v, requeue after %s", e.Err, e.After)
+}
+
+// Unwrap returns the wrapped error
+func (e *RequeueAfterError) Unwrap() error {
+	return e.Err
+}
+
+// IsRequeueAfter returns the delay of err if it is, or wraps, a RequeueAfterError
+func IsRequeueAfter(err error) (time.Duration, bool) {
+	var requeue *RequeueAfterError
+	if !stderrors.As(err, &requeue) {
+		return 0, false
+	}
+	return requeue.After, true
+}
+
+// Result returns the result and the error a reconciler returns to retry err as classified by the package.
+// Terminal errors are not returned since the controller would retry them, record them beforehand, e.g. in the
+// status of the object or in an event.
+func Result(err error) (ctrl.Result, error) {
+	if err == nil || IsTerminal(err) {
+		return ctrl.Result{}, nil
+	}
+	if after, ok := IsRequeueAfter(err); ok {
+		return ctrl.Result{RequeueAfter: after}, nil
+	}
+	if apierrors.IsConflict(err) {
+		return ctrl.Result{Requeue: true}, nil
+	}
+	return ctrl.Result{}, err
+}
+`
+
+const errorsTestTemplate = `{{ .Boilerplate }}
+
+package errors
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestResult(t *testing.T) {
+	failure := fmt.Errorf("failure")
+	conflict := apierrors.NewConflict(schema.GroupResource{Resource: "tests"}, "test", failure)
+
+	tests := []struct {
+		name   string
+		err    error
+		result ctrl.Result
+		failed bool
+	}{
+		{name: "no error"},
+		{name: "error", err: failure, failed: true},
+		{name: "terminal error", err: Terminal(failure)},
+		{name: "wrapped terminal error", err: fmt.Errorf("reconciling: %w", Terminal(failure))},
+		{
+			name:   "requeue after",
+			err:    RequeueAfter(failure, time.Minute),
+			result: ctrl.Result{RequeueAfter: time.Minute},
+		},
+		{
+			name:   "requeue after without error",
+			err:    RequeueAfter(nil, time.Second),
+			result: ctrl.Result{RequeueAfter: time.Second},
+		},
+		{name: "conflict", err: conflict, result: ctrl.Result{Requeue: true}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Result(test.err)
+			if result != test.result {
+				t.Errorf("expected the result %+v, got %+v", test.result, result)
+			}
+			if failed := err != nil; failed != test.failed {
+				t.Errorf("expected the reconcile to fail: %t, got the error: %v", test.failed, err)
+			}
+		})
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	if Terminal(nil) != nil {
+		t.Errorf("expected no terminal error without error")
+	}
+
+	failure := fmt.Errorf("failure")
+	err := fmt.Errorf("reconciling: %w", Terminal(failure))
+	if !IsTerminal(err) {
+		t.Errorf("expected %v to be terminal", err)
+	}
+	if IsTerminal(failure) {
+		t.Errorf("expected %v not to be terminal", failure)
+	}
+}
+`
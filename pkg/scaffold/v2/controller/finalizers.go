@@ -0,0 +1,151 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &Finalizers{}
+
+// Finalizers scaffolds the package adding and removing the finalizers of the objects, holding their deletion until
+// their controller cleans up after them
+type Finalizers struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *Finalizers) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "finalizers", "finalizers.go")
+	}
+	f.TemplateBody = finalizersTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+var _ input.File = &FinalizersTest{}
+
+// FinalizersTest scaffolds the tests of the finalizers package
+type FinalizersTest struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *FinalizersTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "finalizers", "finalizers_test.go")
+	}
+	f.TemplateBody = finalizersTestTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+const finalizersTemplate = `{{ .Boilerplate }}
+
+// Package finalizers adds and removes the finalizers of the objects. The API server holds the deletion of an
+// object until its finalizers are removed: a controller adds its finalizer to the objects it reconciles, cleans up
+// the resources they own outside of the cluster once they are deleted, and removes it for the deletion to complete.
+// The objects owned in the cluster don't need a finalizer, the garbage collector deletes them.
+package finalizers
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Has returns whether the object has the finalizer
+func Has(obj metav1.Object, finalizer string) bool {
+	for _, f := range obj.GetFinalizers() {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// Add adds the finalizer to the object, it returns whether the finalizers changed
+func Add(obj metav1.Object, finalizer string) bool {
+	if Has(obj, finalizer) {
+		return false
+	}
+	obj.SetFinalizers(append(obj.GetFinalizers(), finalizer))
+	return true
+}
+
+// Remove removes the finalizer from the object, it returns whether the finalizers changed
+func Remove(obj metav1.Object, finalizer string) bool {
+	var kept []string
+	for _, f := range obj.GetFinalizers() {
+		if f != finalizer {
+			kept = append(kept, f)
+		}
+	}
+	if len(kept) == len(obj.GetFinalizers()) {
+		return false
+	}
+	obj.SetFinalizers(kept)
+	return true
+}
+`
+
+const finalizersTestTemplate = `{{ .Boilerplate }}
+
+package finalizers
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const finalizer = "example.com/finalizer"
+
+func TestAdd(t *testing.T) {
+	obj := &metav1.ObjectMeta{Finalizers: []string{"other.example.com/finalizer"}}
+	if !Add(obj, finalizer) {
+		t.Errorf("expected the finalizer to be added")
+	}
+	if Add(obj, finalizer) {
+		t.Errorf("expected the finalizer to be added once")
+	}
+	if expected := []string{"other.example.com/finalizer", finalizer}; !reflect.DeepEqual(obj.Finalizers, expected) {
+		t.Errorf("expected the finalizers %v, got %v", expected, obj.Finalizers)
+	}
+	if !Has(obj, finalizer) {
+		t.Errorf("expected the object to have the finalizer")
+	}
+}
+
+func TestRemove(t *testing.T) {
+	obj := &metav1.ObjectMeta{Finalizers: []string{finalizer, "other.example.com/finalizer"}}
+	if !Remove(obj, finalizer) {
+		t.Errorf("expected the finalizer to be removed")
+	}
+	if Remove(obj, finalizer) {
+		t.Errorf("expected the finalizers not to change once the finalizer is removed")
+	}
+	if expected := []string{"other.example.com/finalizer"}; !reflect.DeepEqual(obj.Finalizers, expected) {
+		t.Errorf("expected the finalizers %v, got %v", expected, obj.Finalizers)
+	}
+	if Has(obj, finalizer) {
+		t.Errorf("expected the object not to have the finalizer")
+	}
+}
+`
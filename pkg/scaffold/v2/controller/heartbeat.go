@@ -0,0 +1,152 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &Heartbeat{}
+
+// Heartbeat scaffolds the health check of the controllers based on their last successful reconcile
+type Heartbeat struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *Heartbeat) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("heartbeat", "heartbeat.go")
+	}
+	f.TemplateBody = heartbeatTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+const heartbeatTemplate = `{{ .Boilerplate }}
+
+// Package heartbeat contains a health check of a controller failing while it can't reconcile successfully
+package heartbeat
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// DefaultTimeout is the time a controller may be reconciling without success before its check fails
+const DefaultTimeout = 10 * time.Minute
+
+var (
+	lastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "controller_last_successful_reconcile_timestamp_seconds",
+		Help: "Time of the last successful reconcile of the controller in seconds since the epoch.",
+	}, []string{"controller"})
+	healthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "controller_heartbeat_healthy",
+		Help: "Whether the controller reconciled successfully within its timeout when it had work to do (1) or not (0).",
+	}, []string{"controller"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(lastSuccess, healthy)
+}
+
+// Heartbeat records the reconciles of a controller. An idle controller is healthy, a controller whose
+// reconciles have been running or failing for longer than the timeout without any success is not.
+type Heartbeat struct {
+	name    string
+	timeout time.Duration
+
+	mu sync.Mutex
+	// busySince is the time the controller started reconciling without succeeding since, zero when idle
+	busySince   time.Time
+	lastSuccess time.Time
+	inFlight    int
+}
+
+// New returns the heartbeat of the named controller
+func New(name string, timeout time.Duration) *Heartbeat {
+	healthy.WithLabelValues(name).Set(1)
+	return &Heartbeat{name: name, timeout: timeout}
+}
+
+// Reconciler returns r recording its reconciles in the heartbeat, r itself if the heartbeat is nil
+func (h *Heartbeat) Reconciler(r reconcile.Reconciler) reconcile.Reconciler {
+	if h == nil {
+		return r
+	}
+	return reconcile.Func(func(req reconcile.Request) (reconcile.Result, error) {
+		h.started()
+		result, err := r.Reconcile(req)
+		h.finished(err)
+		return result, err
+	})
+}
+
+func (h *Heartbeat) started() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.busySince.IsZero() {
+		h.busySince = time.Now()
+	}
+	h.inFlight++
+}
+
+func (h *Heartbeat) finished(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.inFlight--
+	if err != nil {
+		// the request is requeued, the controller stays busy until a reconcile succeeds
+		return
+	}
+	h.lastSuccess = time.Now()
+	lastSuccess.WithLabelValues(h.name).Set(float64(h.lastSuccess.Unix()))
+	if h.inFlight == 0 {
+		h.busySince = time.Time{}
+	}
+}
+
+// Check is a healthz.Checker failing if the controller has been reconciling for longer than the timeout
+// without any success, e.g. because its reconciles hang or keep failing.
+func (h *Heartbeat) Check(_ *http.Request) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.busySince.IsZero() {
+		since := h.busySince
+		if h.lastSuccess.After(since) {
+			since = h.lastSuccess
+		}
+		if stuck := time.Since(since); stuck > h.timeout {
+			healthy.WithLabelValues(h.name).Set(0)
+			return fmt.Errorf("controller %s has not reconciled successfully for %s, %d reconciles in flight",
+				h.name, stuck.Round(time.Second), h.inFlight)
+		}
+	}
+	healthy.WithLabelValues(h.name).Set(1)
+	return nil
+}
+`
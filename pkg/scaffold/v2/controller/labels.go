@@ -0,0 +1,205 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+)
+
+var _ input.File = &Labels{}
+
+// Labels scaffolds the package stamping the standard labels on the objects created by the controllers
+type Labels struct {
+	input.Input
+
+	// ProjectName is the name of the operator the objects are managed by, defaults to the project name
+	ProjectName string
+}
+
+// GetInput implements input.File
+func (f *Labels) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "labels", "labels.go")
+	}
+	if f.ProjectName == "" {
+		name, err := util.ProjectName(f.ProjectPath)
+		if err != nil {
+			return input.Input{}, err
+		}
+		f.ProjectName = name
+	}
+	f.TemplateBody = labelsTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+var _ input.File = &LabelsTest{}
+
+// LabelsTest scaffolds the tests of the labels package
+type LabelsTest struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *LabelsTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "labels", "labels_test.go")
+	}
+	f.TemplateBody = labelsTestTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+const labelsTemplate = `{{ .Boilerplate }}
+
+// Package labels stamps the recommended labels of Kubernetes on the objects created by the controllers, so that
+// the objects managed by the operator are identified consistently, e.g. by kubectl get -l or by dashboards:
+//
+// - app.kubernetes.io/managed-by, the operator
+// - app.kubernetes.io/part-of, the application the objects are part of
+// - app.kubernetes.io/instance, the object the objects are created for
+// - app.kubernetes.io/version, the version of the operator, if known
+//
+// See https://kubernetes.io/docs/concepts/overview/working-with-objects/common-labels/
+package labels
+
+import (
+	"runtime/debug"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// The recommended labels stamped on the objects
+const (
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	PartOfLabel    = "app.kubernetes.io/part-of"
+	InstanceLabel  = "app.kubernetes.io/instance"
+	VersionLabel   = "app.kubernetes.io/version"
+)
+
+// ManagedBy is the name of the operator
+const ManagedBy = "{{ .ProjectName }}"
+
+// PartOf is the name of the application the objects are part of.
+// TODO(user): set it to the name of your application.
+var PartOf = ManagedBy
+
+// BuildVersion is the version of the operator, set when building it, e.g. with
+// go build -ldflags "-X {{ .Repo }}/internal/labels.BuildVersion=v1.0.0".
+// The version of the main module of the build info is used if it is not set.
+var BuildVersion = ""
+
+// Version returns the version of the operator, empty if it isn't known
+func Version() string {
+	if BuildVersion != "" {
+		return BuildVersion
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return ""
+}
+
+// For returns the standard labels of the objects created for owner. The version is left out if it is unknown or
+// isn't a valid label value.
+func For(owner metav1.Object) map[string]string {
+	labels := map[string]string{
+		ManagedByLabel: ManagedBy,
+		PartOfLabel:    PartOf,
+		InstanceLabel:  owner.GetName(),
+	}
+	if version := Version(); version != "" && len(validation.IsValidLabelValue(version)) == 0 {
+		labels[VersionLabel] = version
+	}
+	return labels
+}
+
+// Stamp sets the standard labels of the objects created for owner on obj, keeping its other labels, and returns
+// true if they changed, i.e. if obj has to be updated
+func Stamp(obj, owner metav1.Object) bool {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	changed := false
+	for key, value := range For(owner) {
+		if current, ok := labels[key]; !ok || current != value {
+			labels[key] = value
+			changed = true
+		}
+	}
+	if changed {
+		obj.SetLabels(labels)
+	}
+	return changed
+}
+`
+
+const labelsTestTemplate = `{{ .Boilerplate }}
+
+package labels
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStamp(t *testing.T) {
+	defer func(version string) { BuildVersion = version }(BuildVersion)
+	BuildVersion = "v1.2.3"
+
+	owner := &metav1.ObjectMeta{Name: "owner"}
+	obj := &metav1.ObjectMeta{Name: "child", Labels: map[string]string{"app": "test", InstanceLabel: "other"}}
+	if !Stamp(obj, owner) {
+		t.Fatalf("expected the labels to change")
+	}
+
+	expected := map[string]string{
+		"app":          "test",
+		ManagedByLabel: ManagedBy,
+		PartOfLabel:    PartOf,
+		InstanceLabel:  "owner",
+		VersionLabel:   "v1.2.3",
+	}
+	for key, value := range expected {
+		if obj.Labels[key] != value {
+			t.Errorf("expected the label %s to be %q, got %q", key, value, obj.Labels[key])
+		}
+	}
+	if len(obj.Labels) != len(expected) {
+		t.Errorf("expected the labels %v, got %v", expected, obj.Labels)
+	}
+
+	if Stamp(obj, owner) {
+		t.Errorf("expected the labels not to change when stamped again")
+	}
+}
+
+func TestForInvalidVersion(t *testing.T) {
+	defer func(version string) { BuildVersion = version }(BuildVersion)
+	BuildVersion = "v1.2.3+not a label value"
+
+	if version, ok := For(&metav1.ObjectMeta{Name: "owner"})[VersionLabel]; ok {
+		t.Errorf("expected no version label for an invalid label value, got %q", version)
+	}
+}
+`
@@ -0,0 +1,374 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &MessageBus{}
+
+// MessageBus scaffolds the consumer of a message bus, e.g. Kafka or NATS, triggering the reconciles of the
+// objects named by its messages
+type MessageBus struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *MessageBus) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("messagebus", "bus.go")
+	}
+	f.TemplateBody = messageBusTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+var _ input.File = &MessageBusFake{}
+
+// MessageBusFake scaffolds the in-memory consumer of the message bus used by the tests
+type MessageBusFake struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *MessageBusFake) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("messagebus", "fake.go")
+	}
+	f.TemplateBody = messageBusFakeTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+var _ input.File = &MessageBusTest{}
+
+// MessageBusTest scaffolds the tests of the triggers of the message bus
+type MessageBusTest struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *MessageBusTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("messagebus", "bus_test.go")
+	}
+	f.TemplateBody = messageBusTestTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+const messageBusTemplate = `{{ .Boilerplate }}
+
+// Package messagebus consumes the messages of an external system, e.g. published on Kafka or NATS, and triggers
+// the reconciles of the objects they name, so that the controllers react to the events of the system rather
+// than polling it.
+//
+// The bus is abstracted by the Consumer interface, implement it with the client of your bus; the tests use the
+// in-memory Fake. The triggers are runnables of the manager, they stop consuming when the manager stops.
+package messagebus
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// URLEnv is the environment variable holding the URL of the bus, the messages aren't consumed if it is not set
+const URLEnv = "MESSAGE_BUS_URL"
+
+// Message is a message of the bus
+type Message struct {
+	// Subject is the subject, or the topic, the message is published on
+	Subject string
+
+	// Key is the key of the object the message is about, <namespace>/<name> or <name> for a cluster-scoped object
+	Key string
+
+	// Data is the payload of the message
+	Data []byte
+}
+
+// Handler handles the messages of a subscription
+type Handler func(Message)
+
+// Subscription is a subscription to the messages of a subject
+type Subscription interface {
+	// Unsubscribe stops the delivery of the messages, once the ones being handled are handled
+	Unsubscribe() error
+}
+
+// Consumer consumes the messages of the bus.
+// TODO(user): implement it with the client of your bus, e.g. a NATS connection subscribing to the subjects or a
+// Kafka consumer group reading the topics, setting the key of the messages to the key of the objects.
+type Consumer interface {
+	// Subscribe calls handler with the messages of subject until the subscription is unsubscribed
+	Subscribe(subject string, handler Handler) (Subscription, error)
+
+	// Close drains the subscriptions and closes the connection to the bus
+	Close() error
+}
+
+// Connect connects to the bus at the URL of URLEnv, it returns nil if it is not set: the triggers are disabled
+func Connect() (Consumer, error) {
+	url := os.Getenv(URLEnv)
+	if url == "" {
+		return nil, nil
+	}
+	// TODO(user): connect with the client of your bus, e.g. nats.Connect(url)
+	return nil, fmt.Errorf("unable to connect to %s: no client of the message bus is implemented", url)
+}
+
+// Close closes the connection to the bus, if any, logging the error
+func Close(c Consumer) {
+	if c == nil {
+		return
+	}
+	if err := c.Close(); err != nil {
+		ctrl.Log.WithName("messagebus").Error(err, "unable to close the connection to the message bus")
+	}
+}
+
+var _ manager.Runnable = &Trigger{}
+
+// Trigger triggers the reconciles of the objects named by the messages of a subject. It is a runnable of the
+// manager, consuming the messages while the manager runs, and a source of the controller of the objects.
+type Trigger struct {
+	// Consumer consumes the messages of the bus, the trigger is disabled if it is nil
+	Consumer Consumer
+
+	// Subject is the subject the messages are consumed from
+	Subject string
+
+	// Log logs the messages that aren't about an object
+	Log logr.Logger
+
+	events chan event.GenericEvent
+	source *source.Channel
+}
+
+// NewTrigger returns a trigger of the reconciles of the objects named by the messages of subject
+func NewTrigger(c Consumer, subject string, log logr.Logger) *Trigger {
+	events := make(chan event.GenericEvent)
+	return &Trigger{
+		Consumer: c,
+		Subject:  subject,
+		Log:      log,
+		events:   events,
+		source:   &source.Channel{Source: events},
+	}
+}
+
+// Source returns the source of the controller reconciling the objects named by the messages
+func (t *Trigger) Source() source.Source {
+	return t.source
+}
+
+// Start implements manager.Runnable, it consumes the messages until stop is closed
+func (t *Trigger) Start(stop <-chan struct{}) error {
+	if t.Consumer == nil {
+		<-stop
+		return nil
+	}
+
+	sub, err := t.Consumer.Subscribe(t.Subject, func(m Message) {
+		namespace, name, err := cache.SplitMetaNamespaceKey(m.Key)
+		if err != nil || name == "" {
+			t.Log.Info("ignoring a message which isn't about an object", "subject", m.Subject, "key", m.Key)
+			return
+		}
+		// the request is enqueued once the controller is started, the message is dropped if the manager stops
+		select {
+		case t.events <- event.GenericEvent{Meta: &metav1.ObjectMeta{Namespace: namespace, Name: name}}:
+		case <-stop:
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("unable to subscribe to %s: %v", t.Subject, err)
+	}
+
+	<-stop
+	return sub.Unsubscribe()
+}
+`
+
+const messageBusFakeTemplate = `{{ .Boilerplate }}
+
+package messagebus
+
+import (
+	"fmt"
+	"sync"
+)
+
+var _ Consumer = &Fake{}
+
+// Fake is an in-memory Consumer for the tests, delivering the published messages synchronously
+type Fake struct {
+	mu     sync.Mutex
+	subs   map[*fakeSubscription]bool
+	closed bool
+}
+
+// NewFake returns an in-memory Consumer
+func NewFake() *Fake {
+	return &Fake{subs: map[*fakeSubscription]bool{}}
+}
+
+type fakeSubscription struct {
+	bus     *Fake
+	subject string
+	handler Handler
+}
+
+// Unsubscribe implements Subscription
+func (s *fakeSubscription) Unsubscribe() error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	delete(s.bus.subs, s)
+	return nil
+}
+
+// Subscribe implements Consumer
+func (f *Fake) Subscribe(subject string, handler Handler) (Subscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return nil, fmt.Errorf("the bus is closed")
+	}
+	sub := &fakeSubscription{bus: f, subject: subject, handler: handler}
+	f.subs[sub] = true
+	return sub, nil
+}
+
+// Close implements Consumer
+func (f *Fake) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+	f.subs = map[*fakeSubscription]bool{}
+	return nil
+}
+
+// Publish delivers the message to the subscribers of its subject and returns their number
+func (f *Fake) Publish(m Message) int {
+	f.mu.Lock()
+	var handlers []Handler
+	for sub := range f.subs {
+		if sub.subject == m.Subject {
+			handlers = append(handlers, sub.handler)
+		}
+	}
+	f.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(m)
+	}
+	return len(handlers)
+}
+
+// Subscribers returns the number of subscribers of subject
+func (f *Fake) Subscribers(subject string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for sub := range f.subs {
+		if sub.subject == subject {
+			n++
+		}
+	}
+	return n
+}
+`
+
+const messageBusTestTemplate = `{{ .Boilerplate }}
+
+package messagebus
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+func TestTrigger(t *testing.T) {
+	bus := NewFake()
+	trigger := NewTrigger(bus, "tests", ctrl.Log)
+	events := trigger.Source().(*source.Channel).Source
+
+	stop := make(chan struct{})
+	done := make(chan error)
+	go func() { done <- trigger.Start(stop) }()
+	if err := waitFor(func() bool { return bus.Subscribers("tests") == 1 }); err != nil {
+		t.Fatalf("expected the trigger to subscribe to the subject")
+	}
+
+	received := make(chan event.GenericEvent, 1)
+	go func() { received <- <-events }()
+	bus.Publish(Message{Subject: "tests", Key: "default/test"})
+	select {
+	case e := <-received:
+		key := types.NamespacedName{Namespace: e.Meta.GetNamespace(), Name: e.Meta.GetName()}
+		if key != (types.NamespacedName{Namespace: "default", Name: "test"}) {
+			t.Errorf("expected the reconcile of default/test, got %v", key)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected the message to trigger a reconcile")
+	}
+
+	// the messages of other subjects or not about an object are ignored
+	if n := bus.Publish(Message{Subject: "others", Key: "default/test"}); n != 0 {
+		t.Errorf("expected no subscriber of the other subjects, got %d", n)
+	}
+	bus.Publish(Message{Subject: "tests", Key: "not/an/object"})
+
+	close(stop)
+	if err := <-done; err != nil {
+		t.Errorf("unexpected error stopping the trigger: %v", err)
+	}
+	if n := bus.Subscribers("tests"); n != 0 {
+		t.Errorf("expected the trigger to unsubscribe when stopped, %d subscribers left", n)
+	}
+}
+
+func TestTriggerDisabled(t *testing.T) {
+	stop := make(chan struct{})
+	close(stop)
+	if err := NewTrigger(nil, "tests", ctrl.Log).Start(stop); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// waitFor polls condition until it holds, for up to 5 seconds
+func waitFor(condition func() bool) error {
+	return wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) { return condition(), nil })
+}
+`
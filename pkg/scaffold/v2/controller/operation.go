@@ -0,0 +1,467 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+)
+
+var _ input.File = &Operation{}
+
+// Operation scaffolds the long-running operation of a Controller: idempotent steps run in order across reconciles,
+// their progress persisted in the status of the objects for the operation to resume after a restart
+type Operation struct {
+	input.Input
+
+	// Resource is the Resource to make the Controller for
+	Resource *resource.Resource
+
+	// ResourcePackage is the package of the Resource
+	ResourcePackage string
+
+	// ReconcilerName is the name of the reconciler type of the Resource
+	ReconcilerName string
+
+	// CircuitBreaker indicates that the steps call the external systems through the circuit breaker of the
+	// reconciler
+	CircuitBreaker bool
+}
+
+// GetInput implements input.File
+func (f *Operation) GetInput() (input.Input, error) {
+	f.ResourcePackage, _ = util.GetResourceInfo(f.ProjectPath, f.Resource, f.Repo, f.Domain, f.MultiGroup)
+	f.ReconcilerName = f.Naming.ReconcilerName(f.Resource.Kind)
+	if f.Path == "" {
+		f.Path = operationPath(f.Input, f.Resource, "_operation.go")
+	}
+	f.TemplateBody = operationTemplate
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *Operation) Validate() error {
+	return f.Resource.Validate()
+}
+
+var _ input.File = &OperationTest{}
+
+// OperationTest scaffolds the tests of the long-running operation of a Controller, running its steps without a
+// client
+type OperationTest struct {
+	input.Input
+
+	// Resource is the Resource to make the Controller for
+	Resource *resource.Resource
+
+	// ResourcePackage is the package of the Resource
+	ResourcePackage string
+
+	// CircuitBreaker indicates that the steps call the external systems through the circuit breaker of the
+	// reconciler
+	CircuitBreaker bool
+}
+
+// GetInput implements input.File
+func (f *OperationTest) GetInput() (input.Input, error) {
+	f.ResourcePackage, _ = util.GetResourceInfo(f.ProjectPath, f.Resource, f.Repo, f.Domain, f.MultiGroup)
+	if f.Path == "" {
+		f.Path = operationPath(f.Input, f.Resource, "_operation_test.go")
+	}
+	f.TemplateBody = operationTestTemplate
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *OperationTest) Validate() error {
+	return f.Resource.Validate()
+}
+
+// operationPath returns the path of a file of the operation of the resource next to its controller
+func operationPath(in input.Input, r *resource.Resource, suffix string) string {
+	if in.MultiGroup {
+		return filepath.Join("controllers", r.Group, in.Naming.FileName(r.Kind)+suffix)
+	}
+	return filepath.Join("controllers", in.Naming.FileName(r.Kind)+suffix)
+}
+
+// nolint:lll
+const operationTemplate = `{{ .Boilerplate }}
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	{{ .Resource.GroupImportSafe }}{{ .Resource.Version }} "{{ .ResourcePackage }}/{{ .Resource.Version }}"
+{{- if .CircuitBreaker }}
+	"{{ .Repo }}/internal/circuitbreaker"
+{{- end }}
+	"{{ .Repo }}/internal/errors"
+)
+
+// {{ .Resource.Kind }}MaxStepRetries is the number of times a failing step of the operation of a {{ .Resource.Kind }} is
+// retried before the operation fails
+const {{ .Resource.Kind }}MaxStepRetries = 5
+
+// {{ .Resource.Kind }}StepPollInterval is the interval a step of the operation of a {{ .Resource.Kind }} in progress is run
+// again at
+const {{ .Resource.Kind }}StepPollInterval = 10 * time.Second
+
+// {{ lower .Resource.Kind }}Step is a step of the operation of a {{ .Resource.Kind }}, e.g. provisioning a resource in an
+// external system. Run returns whether the step is done: it is run again while it is in progress, e.g. waiting for
+// the external system, and retried with backoff when it returns an error, errors.Terminal(err) failing the
+// operation right away.
+//
+// Run must be idempotent, checking what a previous run did before doing it again: a step runs again when the manager
+// restarts before its outcome is persisted in the status of the {{ .Resource.Kind }}, and the steps done start over when
+// its spec changes.
+type {{ lower .Resource.Kind }}Step struct {
+	// Name is the name of the step in the status, it must not change once the operator is released
+	Name string
+
+	// Run runs the step for the {{ .Resource.Kind }}
+	Run func(ctx context.Context, instance *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) (bool, error)
+}
+
+// operationSteps returns the steps of the operation of the {{ .Resource.Kind }}, run in order.
+// TODO(user): replace the example steps with the steps of the operation.
+func (r *{{ .ReconcilerName }}) operationSteps() []{{ lower .Resource.Kind }}Step {
+	return []{{ lower .Resource.Kind }}Step{
+		{Name: "Provision", Run: r.provision},
+		{Name: "Configure", Run: r.configure},
+	}
+}
+
+// provision is an example step provisioning the resources of the {{ .Resource.Kind }}
+func (r *{{ .ReconcilerName }}) provision(ctx context.Context, instance *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) (bool, error) {
+	// TODO(user): look up the resources of the {{ .Resource.Kind }}, e.g. by its UID, and create the missing ones
+	return true, nil
+}
+
+// configure is an example step configuring the provisioned resources of the {{ .Resource.Kind }}
+func (r *{{ .ReconcilerName }}) configure(ctx context.Context, instance *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) (bool, error) {
+	// TODO(user): apply the spec of the {{ .Resource.Kind }} to its resources, return false until it is applied
+	return true, nil
+}
+
+// runOperation runs the operation of the {{ .Resource.Kind }} and records an event when it completes or fails
+func (r *{{ .ReconcilerName }}) runOperation(ctx context.Context, instance *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) (ctrl.Result, error) {
+	steps := r.operationSteps()
+{{- if .CircuitBreaker }}
+	// the steps call the external systems through the circuit breaker, failing fast while they keep failing
+	for i := range steps {
+		run := steps[i].Run
+		steps[i].Run = func(ctx context.Context, instance *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) (bool, error) {
+			var done bool
+			err := r.Breaker.Call(func() error {
+				var err error
+				done, err = run(ctx, instance)
+				return err
+			})
+			return done, err
+		}
+	}
+{{- end }}
+	before := instance.Status.Operation
+	result, err := run{{ .Resource.Kind }}Operation(ctx, instance, steps, metav1.NewTime(r.Clock.Now()))
+	after := instance.Status.Operation
+	if after.Phase != before.Phase || after.ObservedGeneration != before.ObservedGeneration {
+		switch after.Phase {
+		case {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.OperationSucceeded:
+			r.Recorder.Event(instance, "Normal", "OperationSucceeded", "The operation completed")
+		case {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.OperationFailed:
+			r.Recorder.Event(instance, "Warning", "OperationFailed", "The operation failed, see the status of its steps")
+		}
+	}
+	return result, err
+}
+
+// run{{ .Resource.Kind }}Operation runs the steps of the operation of the {{ .Resource.Kind }} in order from the first one
+// which isn't done, recording their outcome in its status, until a step is in progress or fails. The operation
+// starts over when the spec of the {{ .Resource.Kind }} changes. It returns the result of the reconcile: a requeue while
+// a step is in progress, and the error of a failing step for it to be retried.
+func run{{ .Resource.Kind }}Operation(ctx context.Context, instance *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}, steps []{{ lower .Resource.Kind }}Step,
+	now metav1.Time) (ctrl.Result, error) {
+	operation := &instance.Status.Operation
+	if operation.ObservedGeneration != instance.Generation {
+		*operation = {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.OperationStatus{Phase: {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.OperationPending, ObservedGeneration: instance.Generation}
+	}
+	if operation.Phase == {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.OperationSucceeded || operation.Phase == {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.OperationFailed {
+		return ctrl.Result{}, nil
+	}
+
+	operation.Phase = {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.OperationRunning
+	for _, step := range steps {
+		var retries int32
+		if existing := operation.FindStep(step.Name); existing != nil {
+			if existing.Status == metav1.ConditionTrue {
+				// done by a previous reconcile
+				continue
+			}
+			retries = existing.Retries
+		}
+
+		done, err := step.Run(ctx, instance)
+		switch {
+{{- if .CircuitBreaker }}
+		case circuitbreaker.IsOpen(err):
+			// the step didn't run, it isn't counted as a retry: the {{ .Resource.Kind }} is requeued once the breaker lets the
+			// calls through again
+			operation.SetStep({{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.StepStatus{Name: step.Name, Status: metav1.ConditionUnknown, Retries: retries,
+				LastTransitionTime: now, Reason: "CircuitOpen", Message: err.Error()})
+			return errors.Result(err)
+{{- end }}
+		case err != nil:
+			retries++
+			operation.SetStep({{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.StepStatus{Name: step.Name, Status: metav1.ConditionFalse, Retries: retries,
+				LastTransitionTime: now, Reason: "Failed", Message: err.Error()})
+			if errors.IsTerminal(err) || retries > {{ .Resource.Kind }}MaxStepRetries {
+				// the operation is not retried until the spec changes
+				operation.Phase = {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.OperationFailed
+				return ctrl.Result{}, nil
+			}
+			return errors.Result(err)
+		case !done:
+			operation.SetStep({{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.StepStatus{Name: step.Name, Status: metav1.ConditionUnknown, Retries: retries,
+				LastTransitionTime: now, Reason: "InProgress"})
+			return ctrl.Result{RequeueAfter: {{ .Resource.Kind }}StepPollInterval}, nil
+		}
+		operation.SetStep({{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.StepStatus{Name: step.Name, Status: metav1.ConditionTrue, Retries: retries,
+			LastTransitionTime: now, Reason: "Done"})
+	}
+
+	operation.Phase = {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.OperationSucceeded
+	return ctrl.Result{}, nil
+}
+`
+
+// nolint:lll
+const operationTestTemplate = `{{ .Boilerplate }}
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	{{ .Resource.GroupImportSafe }}{{ .Resource.Version }} "{{ .ResourcePackage }}/{{ .Resource.Version }}"
+{{- if .CircuitBreaker }}
+	"{{ .Repo }}/internal/circuitbreaker"
+{{- end }}
+	"{{ .Repo }}/internal/errors"
+)
+
+// fake{{ .Resource.Kind }}Step returns a step failing with the errors in turn, done when the error is nil, the last error
+// repeating once they are exhausted, and the counter of its runs
+func fake{{ .Resource.Kind }}Step(name string, errs ...error) ({{ lower .Resource.Kind }}Step, *int) {
+	runs := 0
+	return {{ lower .Resource.Kind }}Step{Name: name, Run: func(context.Context, *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) (bool, error) {
+		err := errs[len(errs)-1]
+		if runs < len(errs) {
+			err = errs[runs]
+		}
+		runs++
+		return err == nil, err
+	}}, &runs
+}
+
+// inProgress{{ .Resource.Kind }}Step returns a step which is always in progress
+func inProgress{{ .Resource.Kind }}Step(name string) {{ lower .Resource.Kind }}Step {
+	return {{ lower .Resource.Kind }}Step{Name: name, Run: func(context.Context, *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) (bool, error) {
+		return false, nil
+	}}
+}
+
+func Test{{ .Resource.Kind }}OperationRunsStepsInOrder(t *testing.T) {
+	instance := &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+	first, firstRuns := fake{{ .Resource.Kind }}Step("First", nil)
+	second, secondRuns := fake{{ .Resource.Kind }}Step("Second", nil)
+
+	result, err := run{{ .Resource.Kind }}Operation(context.Background(), instance, []{{ lower .Resource.Kind }}Step{first, second}, metav1.Now())
+	if err != nil || result != (ctrl.Result{}) {
+		t.Fatalf("expected the operation to complete, got %+v, %v", result, err)
+	}
+	operation := instance.Status.Operation
+	if operation.Phase != {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.OperationSucceeded || operation.ObservedGeneration != 1 {
+		t.Errorf("expected the operation of generation 1 to succeed, got %+v", operation)
+	}
+	if len(operation.Steps) != 2 || operation.Steps[0].Name != "First" || operation.Steps[1].Status != metav1.ConditionTrue {
+		t.Errorf("expected both steps to be done in order, got %+v", operation.Steps)
+	}
+
+	// the steps of a completed operation don't run again
+	if _, err := run{{ .Resource.Kind }}Operation(context.Background(), instance, []{{ lower .Resource.Kind }}Step{first, second}, metav1.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if *firstRuns != 1 || *secondRuns != 1 {
+		t.Errorf("expected every step to run once, got %d and %d runs", *firstRuns, *secondRuns)
+	}
+}
+
+func Test{{ .Resource.Kind }}OperationResumesAtFirstStepNotDone(t *testing.T) {
+	// the first step was done before a restart of the manager
+	instance := &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+	instance.Status.Operation = {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.OperationStatus{
+		Phase:              {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.OperationRunning,
+		ObservedGeneration: 1,
+		Steps:              []{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.StepStatus{ {Name: "First", Status: metav1.ConditionTrue} },
+	}
+	first, firstRuns := fake{{ .Resource.Kind }}Step("First", nil)
+
+	result, err := run{{ .Resource.Kind }}Operation(context.Background(), instance, []{{ lower .Resource.Kind }}Step{first, inProgress{{ .Resource.Kind }}Step("Second")}, metav1.Now())
+	if err != nil || result.RequeueAfter != {{ .Resource.Kind }}StepPollInterval {
+		t.Fatalf("expected the step in progress to be polled, got %+v, %v", result, err)
+	}
+	if *firstRuns != 0 {
+		t.Errorf("expected the step done not to run again")
+	}
+	if step := instance.Status.Operation.FindStep("Second"); step == nil || step.Status != metav1.ConditionUnknown {
+		t.Errorf("expected the second step to be in progress, got %+v", step)
+	}
+	if instance.Status.Operation.Phase != {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.OperationRunning {
+		t.Errorf("expected the operation to be running, got %s", instance.Status.Operation.Phase)
+	}
+}
+
+func Test{{ .Resource.Kind }}OperationRetriesFailingStep(t *testing.T) {
+	instance := &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+	errFailed := fmt.Errorf("unavailable")
+	first, _ := fake{{ .Resource.Kind }}Step("First", errFailed, nil)
+	second, secondRuns := fake{{ .Resource.Kind }}Step("Second", nil)
+	steps := []{{ lower .Resource.Kind }}Step{first, second}
+
+	if _, err := run{{ .Resource.Kind }}Operation(context.Background(), instance, steps, metav1.Now()); err != errFailed {
+		t.Fatalf("expected the error of the step to be returned for a retry with backoff, got %v", err)
+	}
+	step := instance.Status.Operation.FindStep("First")
+	if step == nil || step.Status != metav1.ConditionFalse || step.Retries != 1 || step.Message != "unavailable" {
+		t.Errorf("expected the failure of the step to be recorded, got %+v", step)
+	}
+	if *secondRuns != 0 {
+		t.Errorf("expected the next step not to run after a failure")
+	}
+
+	if _, err := run{{ .Resource.Kind }}Operation(context.Background(), instance, steps, metav1.Now()); err != nil {
+		t.Fatal(err)
+	}
+	step = instance.Status.Operation.FindStep("First")
+	if step.Status != metav1.ConditionTrue || step.Retries != 1 {
+		t.Errorf("expected the step to be done after a retry, got %+v", step)
+	}
+	if instance.Status.Operation.Phase != {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.OperationSucceeded {
+		t.Errorf("expected the operation to succeed, got %s", instance.Status.Operation.Phase)
+	}
+}
+
+func Test{{ .Resource.Kind }}OperationFailsAfterMaxRetries(t *testing.T) {
+	instance := &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+	first, firstRuns := fake{{ .Resource.Kind }}Step("First", fmt.Errorf("unavailable"))
+	steps := []{{ lower .Resource.Kind }}Step{first}
+
+	for i := 0; i < {{ .Resource.Kind }}MaxStepRetries; i++ {
+		if _, err := run{{ .Resource.Kind }}Operation(context.Background(), instance, steps, metav1.Now()); err == nil {
+			t.Fatalf("expected retry %d to return the error of the step", i+1)
+		}
+	}
+	result, err := run{{ .Resource.Kind }}Operation(context.Background(), instance, steps, metav1.Now())
+	if err != nil || result != (ctrl.Result{}) {
+		t.Fatalf("expected the operation to fail without a retry, got %+v, %v", result, err)
+	}
+	if instance.Status.Operation.Phase != {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.OperationFailed {
+		t.Errorf("expected the operation to fail, got %s", instance.Status.Operation.Phase)
+	}
+	if _, err := run{{ .Resource.Kind }}Operation(context.Background(), instance, steps, metav1.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if *firstRuns != {{ .Resource.Kind }}MaxStepRetries+1 {
+		t.Errorf("expected the failed operation not to run again, got %d runs", *firstRuns)
+	}
+}
+
+func Test{{ .Resource.Kind }}OperationFailsOnTerminalError(t *testing.T) {
+	instance := &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+	first, _ := fake{{ .Resource.Kind }}Step("First", errors.Terminal(fmt.Errorf("invalid spec")))
+
+	if _, err := run{{ .Resource.Kind }}Operation(context.Background(), instance, []{{ lower .Resource.Kind }}Step{first}, metav1.Now()); err != nil {
+		t.Fatalf("expected a terminal error not to be retried, got %v", err)
+	}
+	if instance.Status.Operation.Phase != {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.OperationFailed {
+		t.Errorf("expected the operation to fail, got %s", instance.Status.Operation.Phase)
+	}
+}
+
+func Test{{ .Resource.Kind }}OperationStartsOverWhenSpecChanges(t *testing.T) {
+	instance := &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+	instance.Status.Operation = {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.OperationStatus{
+		Phase:              {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.OperationFailed,
+		ObservedGeneration: 1,
+		Steps: []{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.StepStatus{
+			{Name: "First", Status: metav1.ConditionFalse, Retries: {{ .Resource.Kind }}MaxStepRetries + 1},
+		},
+	}
+	first, firstRuns := fake{{ .Resource.Kind }}Step("First", nil)
+	now := metav1.NewTime(time.Now().Truncate(time.Second))
+
+	if _, err := run{{ .Resource.Kind }}Operation(context.Background(), instance, []{{ lower .Resource.Kind }}Step{first}, now); err != nil {
+		t.Fatal(err)
+	}
+	operation := instance.Status.Operation
+	if *firstRuns != 1 || operation.Phase != {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.OperationSucceeded || operation.ObservedGeneration != 2 {
+		t.Errorf("expected the operation to run again for generation 2, got %+v", operation)
+	}
+	if step := operation.FindStep("First"); step.Retries != 0 || !step.LastTransitionTime.Equal(&now) {
+		t.Errorf("expected the state of the step to be reset, got %+v", step)
+	}
+}
+{{- if .CircuitBreaker }}
+
+func Test{{ .Resource.Kind }}OperationWaitsForOpenCircuit(t *testing.T) {
+	instance := &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{ObjectMeta: metav1.ObjectMeta{Generation: 1}}
+	open := errors.RequeueAfter(&circuitbreaker.OpenError{Breaker: "test", Err: fmt.Errorf("unavailable")}, time.Minute)
+	first, _ := fake{{ .Resource.Kind }}Step("First", open)
+
+	for i := 0; i <= {{ .Resource.Kind }}MaxStepRetries; i++ {
+		result, err := run{{ .Resource.Kind }}Operation(context.Background(), instance, []{{ lower .Resource.Kind }}Step{first}, metav1.Now())
+		if err != nil || result.RequeueAfter != time.Minute {
+			t.Fatalf("expected the {{ lower .Resource.Kind }} to be requeued after the cooldown, got %+v, %v", result, err)
+		}
+	}
+	step := instance.Status.Operation.FindStep("First")
+	if step == nil || step.Status != metav1.ConditionUnknown || step.Reason != "CircuitOpen" || step.Retries != 0 {
+		t.Errorf("expected the step to wait for the circuit without retries, got %+v", step)
+	}
+	if instance.Status.Operation.Phase != {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.OperationRunning {
+		t.Errorf("expected the operation to keep running, got %s", instance.Status.Operation.Phase)
+	}
+}
+{{- end }}
+`
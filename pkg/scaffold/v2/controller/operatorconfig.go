@@ -0,0 +1,340 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &OperatorConfig{}
+
+// OperatorConfig scaffolds the watcher of the ConfigMap holding the runtime tunables of the operator
+type OperatorConfig struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *OperatorConfig) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("operatorconfig", "config.go")
+	}
+	f.TemplateBody = operatorConfigTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+var _ input.File = &OperatorConfigTest{}
+
+// OperatorConfigTest scaffolds the tests of the watcher of the configuration of the operator
+type OperatorConfigTest struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *OperatorConfigTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("operatorconfig", "config_test.go")
+	}
+	f.TemplateBody = operatorConfigTestTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+const operatorConfigTemplate = `{{ .Boilerplate }}
+
+// Package operatorconfig watches the ConfigMap holding the runtime tunables of the operator, in the namespace
+// of the operator, and notifies the controllers when they change without restarting the manager.
+package operatorconfig
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// ComponentLabel and Component label the ConfigMap of the configuration, its name is prefixed by kustomize
+	ComponentLabel = "app.kubernetes.io/component"
+	Component      = "operator-config"
+
+	// DataKey is the key of the data of the ConfigMap holding the configuration
+	DataKey = "config.yaml"
+
+	// NamespaceEnv overrides the namespace of the operator, e.g. when running it out of the cluster
+	NamespaceEnv = "OPERATOR_NAMESPACE"
+
+	namespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// Config holds the runtime tunables of the operator.
+// TODO(user): add your tunables, along with their defaults in Default.
+type Config struct {
+	// Paused stops the reconciles of the controllers
+	Paused bool ` + "`" + `json:"paused,omitempty"` + "`" + `
+}
+
+// Default returns the configuration of the operator when the ConfigMap doesn't set it
+func Default() Config {
+	return Config{}
+}
+
+// Parse returns the configuration in data, the fields it doesn't set have their default value
+func Parse(data string) (Config, error) {
+	config := Default()
+	if err := yaml.UnmarshalStrict([]byte(data), &config); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+// Namespace returns the namespace of the operator
+func Namespace() string {
+	if ns := os.Getenv(NamespaceEnv); ns != "" {
+		return ns
+	}
+	if ns, err := ioutil.ReadFile(namespaceFile); err == nil {
+		return strings.TrimSpace(string(ns))
+	}
+	return "default"
+}
+
+// Watcher watches the ConfigMap of the configuration of the operator. An invalid configuration is ignored, the
+// previous one is kept, a missing configuration is the default one.
+type Watcher struct {
+	Log logr.Logger
+
+	reader client.Reader
+
+	mu          sync.RWMutex
+	config      Config
+	subscribers []chan event.GenericEvent
+}
+
+// New returns a watcher of the configuration of the operator, starting with the default one
+func New() *Watcher {
+	return &Watcher{Log: ctrl.Log.WithName("operatorconfig"), config: Default()}
+}
+
+// Get returns the current configuration, the default one if the watcher is nil
+func (w *Watcher) Get() Config {
+	if w == nil {
+		return Default()
+	}
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.config
+}
+
+// Subscribe returns a source of a controller notified when the configuration changes
+func (w *Watcher) Subscribe() source.Source {
+	// a pending notification covers the following changes
+	ch := make(chan event.GenericEvent, 1)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, ch)
+	return &source.Channel{Source: ch}
+}
+
+// SetupWithManager watches the ConfigMap in the namespace of the operator, only the ConfigMaps of this
+// namespace are cached
+func (w *Watcher) SetupWithManager(mgr ctrl.Manager) error {
+	c, err := cache.New(mgr.GetConfig(), cache.Options{
+		Scheme:    mgr.GetScheme(),
+		Mapper:    mgr.GetRESTMapper(),
+		Namespace: Namespace(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := mgr.Add(c); err != nil {
+		return err
+	}
+	w.reader = c
+
+	informer, err := c.GetInformer(&corev1.ConfigMap{})
+	if err != nil {
+		return err
+	}
+	ctrlr, err := controller.New("operator-config", mgr, controller.Options{Reconciler: w})
+	if err != nil {
+		return err
+	}
+	return ctrlr.Watch(&source.Informer{Informer: informer}, &handler.EnqueueRequestForObject{},
+		predicate.Funcs{
+			CreateFunc:  func(e event.CreateEvent) bool { return e.Meta.GetLabels()[ComponentLabel] == Component },
+			UpdateFunc:  func(e event.UpdateEvent) bool { return e.MetaNew.GetLabels()[ComponentLabel] == Component },
+			DeleteFunc:  func(e event.DeleteEvent) bool { return e.Meta.GetLabels()[ComponentLabel] == Component },
+			GenericFunc: func(e event.GenericEvent) bool { return e.Meta.GetLabels()[ComponentLabel] == Component },
+		})
+}
+
+// Reconcile reads the configuration from the ConfigMap
+func (w *Watcher) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	log := w.Log.WithValues("configmap", req.NamespacedName)
+
+	var cm corev1.ConfigMap
+	err := w.reader.Get(context.Background(), req.NamespacedName, &cm)
+	if apierrors.IsNotFound(err) {
+		log.Info("configuration deleted, using the default one")
+		w.set(Default(), event.GenericEvent{})
+		return ctrl.Result{}, nil
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	config, err := Parse(cm.Data[DataKey])
+	if err != nil {
+		// retrying doesn't fix the configuration, the ConfigMap is reconciled again when it is fixed
+		log.Error(err, "invalid configuration, keeping the current one")
+		return ctrl.Result{}, nil
+	}
+	if w.set(config, event.GenericEvent{Meta: &cm, Object: &cm}) {
+		log.Info("configuration changed", "config", config)
+	}
+	return ctrl.Result{}, nil
+}
+
+// set stores the configuration and notifies the subscribers if it changed, it returns whether it changed
+func (w *Watcher) set(config Config, evt event.GenericEvent) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if reflect.DeepEqual(w.config, config) {
+		return false
+	}
+	w.config = config
+	for _, ch := range w.subscribers {
+		select {
+		case ch <- evt:
+		default:
+		}
+	}
+	return true
+}
+`
+
+const operatorConfigTestTemplate = `{{ .Boilerplate }}
+
+package operatorconfig
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+func TestParse(t *testing.T) {
+	config, err := Parse("paused: true\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !config.Paused {
+		t.Errorf("expected the configuration to be paused, got: %+v", config)
+	}
+
+	if config, err := Parse(""); err != nil || config != Default() {
+		t.Errorf("expected the default configuration, got: %+v, %v", config, err)
+	}
+	if _, err := Parse("unknown: true\n"); err == nil {
+		t.Errorf("expected unknown fields to be rejected")
+	}
+}
+
+func TestWatcherReconcile(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "operator-config", Namespace: "system",
+			Labels: map[string]string{ComponentLabel: Component}},
+		Data: map[string]string{DataKey: "paused: true\n"},
+	}
+	w := New()
+	w.reader = fake.NewFakeClientWithScheme(clientgoscheme.Scheme, cm)
+	notifications := w.Subscribe().(*source.Channel).Source
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: cm.Name, Namespace: cm.Namespace}}
+
+	if _, err := w.Reconcile(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !w.Get().Paused {
+		t.Errorf("expected the configuration of the ConfigMap, got: %+v", w.Get())
+	}
+	select {
+	case <-notifications:
+	default:
+		t.Errorf("expected the subscriber to be notified")
+	}
+
+	// an unchanged configuration doesn't notify the subscribers
+	if _, err := w.Reconcile(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	select {
+	case <-notifications:
+		t.Errorf("expected no notification")
+	default:
+	}
+
+	// an invalid configuration is ignored
+	cm.Data[DataKey] = "paused: maybe\n"
+	w.reader = fake.NewFakeClientWithScheme(clientgoscheme.Scheme, cm)
+	if _, err := w.Reconcile(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !w.Get().Paused {
+		t.Errorf("expected the previous configuration to be kept, got: %+v", w.Get())
+	}
+
+	// a deleted configuration is the default one
+	w.reader = fake.NewFakeClientWithScheme(clientgoscheme.Scheme)
+	if _, err := w.Reconcile(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if w.Get() != Default() {
+		t.Errorf("expected the default configuration, got: %+v", w.Get())
+	}
+}
+
+func TestNilWatcher(t *testing.T) {
+	var w *Watcher
+	if w.Get() != Default() {
+		t.Errorf("expected the default configuration, got: %+v", w.Get())
+	}
+}
+`
@@ -0,0 +1,185 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ReconcileTemplateNone is the reconcile template leaving the logic of the reconciler to the user
+const ReconcileTemplateNone = "none"
+
+// ReconcileTemplate is a body of the reconciler creating the object of a built-in kind named after every object of
+// the resource, in its namespace and controlled by it, and updating it to its desired state
+type ReconcileTemplate struct {
+	// Kind is the kind of the object, e.g. Deployment
+	Kind string
+
+	// Var is the name of the variable holding the object
+	Var string
+
+	// Type is the Go type of the kind, e.g. appsv1.Deployment
+	Type string
+
+	// Package is the import spec of the package of the kind, whose types are added to the scheme of the tests
+	Package string
+
+	// Imports are the import specs of the other packages of the desired state of the object
+	Imports []string
+
+	// RBAC is the rbac marker allowing to manage the objects of the kind
+	RBAC string
+
+	// Mutate is the template of the body of the function setting the desired state of the object, executed with
+	// the Controller as data
+	Mutate string
+}
+
+// reconcileTemplates are the reconcile templates by name
+var reconcileTemplates = map[string]*ReconcileTemplate{
+	"deployment": {
+		Kind:    "Deployment",
+		Var:     "deployment",
+		Type:    "appsv1.Deployment",
+		Package: `appsv1 "k8s.io/api/apps/v1"`,
+		Imports: []string{`corev1 "k8s.io/api/core/v1"`},
+		RBAC:    "// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete",
+		Mutate:  deploymentMutateTemplate,
+	},
+	"statefulset": {
+		Kind:    "StatefulSet",
+		Var:     "statefulSet",
+		Type:    "appsv1.StatefulSet",
+		Package: `appsv1 "k8s.io/api/apps/v1"`,
+		Imports: []string{`corev1 "k8s.io/api/core/v1"`},
+		RBAC:    "// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete",
+		Mutate:  statefulSetMutateTemplate,
+	},
+	"job": {
+		Kind:    "Job",
+		Var:     "job",
+		Type:    "batchv1.Job",
+		Package: `batchv1 "k8s.io/api/batch/v1"`,
+		Imports: []string{`corev1 "k8s.io/api/core/v1"`},
+		RBAC:    "// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete",
+		Mutate:  jobMutateTemplate,
+	},
+	"service": {
+		Kind:    "Service",
+		Var:     "service",
+		Type:    "corev1.Service",
+		Package: `corev1 "k8s.io/api/core/v1"`,
+		Imports: []string{`"k8s.io/apimachinery/pkg/util/intstr"`},
+		RBAC:    `// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;update;patch;delete`,
+		Mutate:  serviceMutateTemplate,
+	},
+}
+
+// ReconcileTemplateNames returns the names of the reconcile templates, none first
+func ReconcileTemplateNames() []string {
+	names := make([]string, 0, len(reconcileTemplates))
+	for name := range reconcileTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{ReconcileTemplateNone}, names...)
+}
+
+// ReconcileTemplateOf returns the reconcile template of a name, nil for none or an empty name
+func ReconcileTemplateOf(name string) (*ReconcileTemplate, error) {
+	if name == "" || name == ReconcileTemplateNone {
+		return nil, nil
+	}
+	t, ok := reconcileTemplates[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown reconcile template %q, must be one of %s", name,
+			strings.Join(ReconcileTemplateNames(), ", "))
+	}
+	return t, nil
+}
+
+// packageName returns the name of the package of the kind
+func (t *ReconcileTemplate) packageName() string {
+	return strings.SplitN(t.Type, ".", 2)[0]
+}
+
+// define returns the definition of the template of the body of the mutate function, invoked by the controller
+// template
+func (t *ReconcileTemplate) define() string {
+	return `{{ define "mutate" }}` + t.Mutate + `{{ end }}`
+}
+
+const deploymentMutateTemplate = `
+	selector := map[string]string{labels.ManagedByLabel: labels.ManagedBy, labels.InstanceLabel: instance.Name}
+	replicas := int32(1)
+	deployment.Spec.Replicas = &replicas
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: selector}
+	deployment.Spec.Template.Labels = labels.For(instance)
+	if len(deployment.Spec.Template.Spec.Containers) == 0 {
+		deployment.Spec.Template.Spec.Containers = []corev1.Container{ {Name: "main"} }
+	}
+	// TODO(user): set the pods from the spec of the {{ .Resource.Kind }}, the fields left out keep their defaults
+	container := &deployment.Spec.Template.Spec.Containers[0]
+	container.Image = "nginx:1.17"
+`
+
+const statefulSetMutateTemplate = `
+	selector := map[string]string{labels.ManagedByLabel: labels.ManagedBy, labels.InstanceLabel: instance.Name}
+	replicas := int32(1)
+	statefulSet.Spec.Replicas = &replicas
+	statefulSet.Spec.Selector = &metav1.LabelSelector{MatchLabels: selector}
+	// TODO(user): create the headless Service governing the StatefulSet, named after the {{ .Resource.Kind }}
+	statefulSet.Spec.ServiceName = instance.Name
+	statefulSet.Spec.Template.Labels = labels.For(instance)
+	if len(statefulSet.Spec.Template.Spec.Containers) == 0 {
+		statefulSet.Spec.Template.Spec.Containers = []corev1.Container{ {Name: "main"} }
+	}
+	// TODO(user): set the pods and the volume claim templates from the spec of the {{ .Resource.Kind }}, the volume
+	// claim templates can't change once the StatefulSet is created
+	container := &statefulSet.Spec.Template.Spec.Containers[0]
+	container.Image = "nginx:1.17"
+`
+
+const jobMutateTemplate = `
+	// the pod template of a Job can't change once it is created, the Job is only set when it is created: delete it
+	// to run it again with a new template
+	if !job.CreationTimestamp.IsZero() {
+		return
+	}
+	job.Spec.Template.Labels = labels.For(instance)
+	job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyOnFailure
+	// TODO(user): set the pod from the spec of the {{ .Resource.Kind }}
+	job.Spec.Template.Spec.Containers = []corev1.Container{ {
+		Name:    "main",
+		Image:   "busybox:1.31",
+		Command: []string{"echo", "reconciled"},
+	} }
+`
+
+const serviceMutateTemplate = `
+	// the cluster IP is allocated by the API server, it is kept
+	service.Spec.Selector = map[string]string{labels.ManagedByLabel: labels.ManagedBy, labels.InstanceLabel: instance.Name}
+	// TODO(user): set the ports from the spec of the {{ .Resource.Kind }}
+	service.Spec.Ports = []corev1.ServicePort{ {
+		Name:       "http",
+		Protocol:   corev1.ProtocolTCP,
+		Port:       80,
+		TargetPort: intstr.FromInt(8080),
+	} }
+`
@@ -0,0 +1,168 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &Validation{}
+
+// Validation scaffolds the package validating the objects at reconcile time with the validation of their
+// validating webhook, for the clusters where the webhooks are disallowed
+type Validation struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *Validation) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "validation", "validation.go")
+	}
+	f.TemplateBody = validationTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+var _ input.File = &ValidationTest{}
+
+// ValidationTest scaffolds the tests of the validation package
+type ValidationTest struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *ValidationTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "validation", "validation_test.go")
+	}
+	f.TemplateBody = validationTestTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+const validationTemplate = `{{ .Boilerplate }}
+
+// Package validation validates the objects at reconcile time with the validation of their validating webhook,
+// ValidateCreate of webhook.Validator, for the clusters where the webhooks are disallowed and the API server
+// admits the objects unchecked. The same rules are enforced whether the webhooks are deployed or not: the
+// reconcilers record the violations in the Valid condition of the objects and leave them alone until they change.
+package validation
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// ConditionValid is the type of the condition recording whether an object passes the validation of its webhook
+const ConditionValid = "Valid"
+
+const (
+	// ReasonValid is the reason of the Valid condition of the objects passing their validation
+	ReasonValid = "Valid"
+
+	// ReasonInvalid is the reason of the Valid condition of the objects failing their validation
+	ReasonInvalid = "Invalid"
+)
+
+// Validate validates obj as its validating webhook does when it is created, nil if its type isn't a
+// webhook.Validator, e.g. before its webhook is scaffolded. A reconcile sees the latest version of the object
+// only, the rules of ValidateUpdate comparing it to the previous one can't be enforced at reconcile time.
+func Validate(obj runtime.Object) error {
+	validator, ok := obj.(webhook.Validator)
+	if !ok {
+		return nil
+	}
+	return validator.ValidateCreate()
+}
+
+// Condition returns the status, the reason and the message of the Valid condition of an object validated by
+// Validate with err
+func Condition(err error) (metav1.ConditionStatus, string, string) {
+	if err != nil {
+		return metav1.ConditionFalse, ReasonInvalid, err.Error()
+	}
+	return metav1.ConditionTrue, ReasonValid, ""
+}
+`
+
+const validationTestTemplate = `{{ .Boilerplate }}
+
+package validation
+
+import (
+	"fmt"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// validated is an object whose validation fails with err
+type validated struct {
+	metav1.ObjectMeta
+	runtime.Object
+
+	err error
+}
+
+func (v *validated) ValidateCreate() error {
+	return v.err
+}
+
+func (v *validated) ValidateUpdate(old runtime.Object) error {
+	return fmt.Errorf("unexpected update validation")
+}
+
+func (v *validated) ValidateDelete() error {
+	return fmt.Errorf("unexpected delete validation")
+}
+
+// unvalidated is an object without validation
+type unvalidated struct {
+	metav1.ObjectMeta
+	runtime.Object
+}
+
+func TestValidate(t *testing.T) {
+	invalid := fmt.Errorf("spec.replicas must be positive")
+	if err := Validate(&validated{err: invalid}); err != invalid {
+		t.Errorf("expected the error of the validation, got %v", err)
+	}
+	if err := Validate(&validated{}); err != nil {
+		t.Errorf("expected the object to be valid, got %v", err)
+	}
+	if err := Validate(&unvalidated{}); err != nil {
+		t.Errorf("expected an object without validation to be valid, got %v", err)
+	}
+}
+
+func TestCondition(t *testing.T) {
+	status, reason, message := Condition(nil)
+	if status != metav1.ConditionTrue || reason != ReasonValid || message != "" {
+		t.Errorf("unexpected condition of a valid object: %s, %s, %s", status, reason, message)
+	}
+
+	status, reason, message = Condition(fmt.Errorf("spec.replicas must be positive"))
+	if status != metav1.ConditionFalse || reason != ReasonInvalid || message != "spec.replicas must be positive" {
+		t.Errorf("unexpected condition of an invalid object: %s, %s, %s", status, reason, message)
+	}
+}
+`
@@ -0,0 +1,266 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+)
+
+// StorageVersionMarker marks the type of the storage version of a kind of several versions
+const StorageVersionMarker = "// +kubebuilder:storageversion"
+
+var _ input.File = &Hub{}
+
+// Hub scaffolds the api/<version>/<kind>_conversion.go file marking the storage version of a kind as the hub of the
+// conversions between its versions
+type Hub struct {
+	input.Input
+
+	// Resource is the resource of the storage version
+	Resource *resource.Resource
+}
+
+// GetInput implements input.File
+func (f *Hub) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = conversionPath(f.Resource, f.MultiGroup, f.Naming, "conversion.go")
+	}
+	f.TemplateBody = hubTemplate
+	// the hub is shared by the conversions of every other version
+	f.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *Hub) Validate() error {
+	return f.Resource.Validate()
+}
+
+var _ input.File = &Conversion{}
+
+// Conversion scaffolds the api/<version>/<kind>_conversion.go file converting a version of a kind to and from the
+// hub, its storage version
+type Conversion struct {
+	input.Input
+
+	// Resource is the resource of the converted version
+	Resource *resource.Resource
+
+	// Hub is the resource of the storage version
+	Hub *resource.Resource
+
+	// HubPackage is the package of the storage version
+	HubPackage string
+}
+
+// GetInput implements input.File
+func (f *Conversion) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = conversionPath(f.Resource, f.MultiGroup, f.Naming, "conversion.go")
+	}
+	f.HubPackage = hubPackage(f.Input, f.Hub)
+	f.TemplateBody = conversionTemplate
+	f.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *Conversion) Validate() error {
+	if err := f.Resource.Validate(); err != nil {
+		return err
+	}
+	return f.Hub.Validate()
+}
+
+var _ input.File = &ConversionTest{}
+
+// ConversionTest scaffolds the round trip test of the conversions of a version of a kind
+type ConversionTest struct {
+	input.Input
+
+	// Resource is the resource of the converted version
+	Resource *resource.Resource
+
+	// Hub is the resource of the storage version
+	Hub *resource.Resource
+
+	// HubPackage is the package of the storage version
+	HubPackage string
+}
+
+// GetInput implements input.File
+func (f *ConversionTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = conversionPath(f.Resource, f.MultiGroup, f.Naming, "conversion_test.go")
+	}
+	f.HubPackage = hubPackage(f.Input, f.Hub)
+	f.TemplateBody = conversionTestTemplate
+	f.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *ConversionTest) Validate() error {
+	if err := f.Resource.Validate(); err != nil {
+		return err
+	}
+	return f.Hub.Validate()
+}
+
+// conversionPath returns the path of a file of the version of a kind, e.g. of its conversions
+func conversionPath(r *resource.Resource, multiGroup bool, naming config.Naming, suffix string) string {
+	name := fmt.Sprintf("%s_%s", naming.FileName(r.Kind), suffix)
+	if multiGroup {
+		return filepath.Join("apis", r.Group, r.Version, name)
+	}
+	return filepath.Join("api", r.Version, name)
+}
+
+// hubPackage returns the package of the storage version
+func hubPackage(in input.Input, hub *resource.Resource) string {
+	pkg, _ := util.GetResourceInfo(in.ProjectPath, hub, in.Repo, in.Domain, in.MultiGroup)
+	return pkg + "/" + hub.Version
+}
+
+// MarkStorageVersion adds the storage version marker to the markers of the type of the kind in its types file.
+// It returns false if the markers of the type aren't the scaffolded ones, e.g. when they were changed, leaving the
+// file as is.
+func MarkStorageVersion(projectPath string, r *resource.Resource, multiGroup bool, naming config.Naming) (bool, error) {
+	path := filepath.Join(projectPath, conversionPath(r, multiGroup, naming, "types.go"))
+	in, err := filesystem.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	content := string(in)
+
+	// the markers of the type are the comments above its declaration, up to the previous declaration
+	decl := regexp.MustCompile(`(?m)^type ` + regexp.QuoteMeta(r.Kind) + ` struct \{`).FindStringIndex(content)
+	if decl == nil {
+		return false, nil
+	}
+	start := decl[0]
+	for start > 0 {
+		prev := strings.LastIndex(content[:start-1], "\n") + 1
+		if line := content[prev:start]; !strings.HasPrefix(line, "//") && line != "\n" {
+			break
+		}
+		start = prev
+	}
+	markers := content[start:decl[0]]
+	if strings.Contains(markers, StorageVersionMarker+"\n") {
+		return true, nil
+	}
+	const rootMarker = "// +kubebuilder:object:root=true\n"
+	i := strings.Index(markers, rootMarker)
+	if i < 0 {
+		return false, nil
+	}
+	i += start + len(rootMarker)
+	content = content[:i] + StorageVersionMarker + "\n" + content[i:]
+	return true, filesystem.WriteFile(path, []byte(content), 0644)
+}
+
+const hubTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+var _ conversion.Hub = &{{ .Resource.Kind }}{}
+
+// Hub marks {{ .Resource.Version }}, the storage version, as the hub of the conversions between the versions of the
+// {{ .Resource.Kind }}: the other versions convert to and from it, see their ConvertTo and ConvertFrom.
+func (*{{ .Resource.Kind }}) Hub() {}
+`
+
+const conversionTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	{{ .Hub.GroupImportSafe }}{{ .Hub.Version }} "{{ .HubPackage }}"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+
+var _ conversion.Convertible = &{{ .Resource.Kind }}{}
+
+// ConvertTo converts the {{ .Resource.Kind }} to the hub version, {{ .Hub.Version }}
+func (src *{{ .Resource.Kind }}) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*{{ .Hub.GroupImportSafe }}{{ .Hub.Version }}.{{ .Resource.Kind }})
+	dst.ObjectMeta = src.ObjectMeta
+
+	// TODO(user): convert the spec and the status. The fields which don't exist in {{ .Hub.Version }} can be kept in
+	// annotations of the hub, for the round trips through {{ .Hub.Version }} not to lose them.
+	return nil
+}
+
+// ConvertFrom converts the hub version, {{ .Hub.Version }}, to the {{ .Resource.Kind }}
+func (dst *{{ .Resource.Kind }}) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*{{ .Hub.GroupImportSafe }}{{ .Hub.Version }}.{{ .Resource.Kind }})
+	dst.ObjectMeta = src.ObjectMeta
+
+	// TODO(user): convert the spec and the status
+	return nil
+}
+`
+
+const conversionTestTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	{{ .Hub.GroupImportSafe }}{{ .Hub.Version }} "{{ .HubPackage }}"
+)
+
+func Test{{ .Resource.Kind }}Conversion(t *testing.T) {
+	src := &{{ .Resource.Kind }}{
+		ObjectMeta: metav1.ObjectMeta{Name: "{{ lower .Resource.Kind }}-sample", Labels: map[string]string{"app": "test"}},
+		// TODO(user): set the fields of the spec and of the status, for the test to verify their conversions
+	}
+
+	hub := &{{ .Hub.GroupImportSafe }}{{ .Hub.Version }}.{{ .Resource.Kind }}{}
+	if err := src.ConvertTo(hub); err != nil {
+		t.Fatalf("unable to convert to {{ .Hub.Version }}: %v", err)
+	}
+	dst := &{{ .Resource.Kind }}{}
+	if err := dst.ConvertFrom(hub); err != nil {
+		t.Fatalf("unable to convert from {{ .Hub.Version }}: %v", err)
+	}
+	if !reflect.DeepEqual(src, dst) {
+		t.Errorf("expected the round trip through {{ .Hub.Version }} to keep the {{ .Resource.Kind }} %+v, got %+v", src, dst)
+	}
+}
+`
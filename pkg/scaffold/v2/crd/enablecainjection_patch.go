@@ -19,9 +19,6 @@ package crd
 import (
 	"fmt"
 	"path/filepath"
-	"strings"
-
-	"github.com/gobuffalo/flect"
 
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
@@ -35,14 +32,20 @@ type EnableCAInjectionPatch struct {
 
 	// Resource is the Resource to make the EnableCAInjectionPatch for
 	Resource *resource.Resource
+
+	// ConversionServer indicates that the conversion webhook is served by the conversion webhook server, the CA of
+	// its certificate is then injected and the patch overwritten
+	ConversionServer bool
 }
 
 // GetInput implements input.File
 func (f *EnableCAInjectionPatch) GetInput() (input.Input, error) {
 	if f.Path == "" {
-		plural := flect.Pluralize(strings.ToLower(f.Resource.Kind))
-		f.Path = filepath.Join("config", "crd", "patches",
-			fmt.Sprintf("cainjection_in_%s.yaml", plural))
+		f.Path = filepath.Join(f.CRDDir, "patches",
+			fmt.Sprintf("cainjection_in_%s.yaml", f.Resource.QualifiedName(f.Resource.Plural(), "_")))
+	}
+	if f.ConversionServer {
+		f.Input.IfExistsAction = input.Overwrite
 	}
 	f.TemplateBody = EnableCAInjectionPatchTemplate
 	return f.Input, nil
@@ -55,10 +58,14 @@ func (f *EnableCAInjectionPatch) Validate() error {
 
 const EnableCAInjectionPatchTemplate = `# The following patch adds a directive for certmanager to inject CA into the CRD
 # CRD conversion requires k8s 1.13 or later.
-apiVersion: apiextensions.k8s.io/v1beta1
+apiVersion: apiextensions.k8s.io/{{ .Kubernetes.CRDVersion }}
 kind: CustomResourceDefinition
 metadata:
   annotations:
+{{- if .ConversionServer }}
+    cert-manager.io/inject-ca-from: $(CONVERSION_CERTIFICATE_NAMESPACE)/$(CONVERSION_CERTIFICATE_NAME)
+{{- else }}
     cert-manager.io/inject-ca-from: $(CERTIFICATE_NAMESPACE)/$(CERTIFICATE_NAME)
+{{- end }}
   name: {{ .Resource.Resource }}.{{ .Resource.Group }}.{{ .Domain }}
 `
@@ -19,12 +19,10 @@ package crd
 import (
 	"fmt"
 	"path/filepath"
-	"strings"
-
-	"github.com/gobuffalo/flect"
 
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/webhook"
 )
 
 var _ input.File = &EnableWebhookPatch{}
@@ -35,14 +33,25 @@ type EnableWebhookPatch struct {
 
 	// Resource is the Resource to make the EnableWebhookPatch for
 	Resource *resource.Resource
+
+	// ConversionServer indicates that the conversion webhook is served by the conversion webhook server, the patch
+	// is then overwritten to point to its service
+	ConversionServer bool
+
+	// ServiceName is the name of the service of the webhook server converting the resource
+	ServiceName string
 }
 
 // GetInput implements input.File
 func (f *EnableWebhookPatch) GetInput() (input.Input, error) {
 	if f.Path == "" {
-		plural := flect.Pluralize(strings.ToLower(f.Resource.Kind))
-		f.Path = filepath.Join("config", "crd", "patches",
-			fmt.Sprintf("webhook_in_%s.yaml", plural))
+		f.Path = filepath.Join(f.CRDDir, "patches",
+			fmt.Sprintf("webhook_in_%s.yaml", f.Resource.QualifiedName(f.Resource.Plural(), "_")))
+	}
+	f.ServiceName = "webhook-service"
+	if f.ConversionServer {
+		f.ServiceName = webhook.ConversionServiceName
+		f.Input.IfExistsAction = input.Overwrite
 	}
 	f.TemplateBody = enableWebhookPatchTemplate
 	return f.Input, nil
@@ -55,19 +64,34 @@ func (f *EnableWebhookPatch) Validate() error {
 
 const enableWebhookPatchTemplate = `# The following patch enables conversion webhook for CRD
 # CRD conversion requires k8s 1.13 or later.
-apiVersion: apiextensions.k8s.io/v1beta1
+apiVersion: apiextensions.k8s.io/{{ .Kubernetes.CRDVersion }}
 kind: CustomResourceDefinition
 metadata:
   name: {{ .Resource.Resource }}.{{ .Resource.Group }}.{{ .Domain }}
 spec:
   conversion:
     strategy: Webhook
+{{- if eq .Kubernetes.CRDVersion "v1beta1" }}
     webhookClientConfig:
       # this is "\n" used as a placeholder, otherwise it will be rejected by the apiserver for being blank,
       # but we're going to set it later using the cert-manager (or potentially a patch if not using cert-manager)
       caBundle: Cg==
       service:
         namespace: system
-        name: webhook-service
+        name: {{ .ServiceName }}
         path: /convert
+{{- else }}
+    webhook:
+      clientConfig:
+        # this is "\n" used as a placeholder, otherwise it will be rejected by the apiserver for being blank,
+        # but we're going to set it later using the cert-manager (or potentially a patch if not using cert-manager)
+        caBundle: Cg==
+        service:
+          namespace: system
+          name: {{ .ServiceName }}
+          path: /convert
+      # the conversion webhook of controller-runtime reviews the conversions with apiextensions.k8s.io/v1beta1
+      conversionReviewVersions:
+      - v1beta1
+{{- end }}
 `
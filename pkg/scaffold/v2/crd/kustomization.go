@@ -19,10 +19,9 @@ package crd
 import (
 	"fmt"
 	"path/filepath"
-	"strings"
-
-	"github.com/gobuffalo/flect"
+	"regexp"
 
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/internal"
@@ -47,7 +46,7 @@ type Kustomization struct {
 // GetInput implements input.File
 func (f *Kustomization) GetInput() (input.Input, error) {
 	if f.Path == "" {
-		f.Path = filepath.Join("config", "crd", "kustomization.yaml")
+		f.Path = filepath.Join(f.CRDDir, "kustomization.yaml")
 	}
 	f.TemplateBody = kustomizationTemplate
 	return f.Input, nil
@@ -55,18 +54,19 @@ func (f *Kustomization) GetInput() (input.Input, error) {
 
 func (f *Kustomization) Update() error {
 	if f.Path == "" {
-		f.Path = filepath.Join("config", "crd", "kustomization.yaml")
+		f.Path = filepath.Join(f.CRDDir, "kustomization.yaml")
 	}
 
-	// TODO(directxman12): not technically valid if something changes from the default
-	// (we'd need to parse the markers)
-	plural := flect.Pluralize(strings.ToLower(f.Resource.Kind))
+	// the plural of the resource is the one of its resource path marker, if any
+	plural := f.Resource.Plural()
 
 	kustomizeResourceCodeFragment := fmt.Sprintf("- bases/%s.%s_%s.yaml\n", f.Resource.Group, f.Domain, plural)
-	kustomizeWebhookPatchCodeFragment := fmt.Sprintf("#- patches/webhook_in_%s.yaml\n", plural)
-	kustomizeCAInjectionPatchCodeFragment := fmt.Sprintf("#- patches/cainjection_in_%s.yaml\n", plural)
+	// the patches of a kind shared by several groups are qualified by the group
+	patch := f.Resource.QualifiedName(plural, "_")
+	kustomizeWebhookPatchCodeFragment := fmt.Sprintf("#- patches/webhook_in_%s.yaml\n", patch)
+	kustomizeCAInjectionPatchCodeFragment := fmt.Sprintf("#- patches/cainjection_in_%s.yaml\n", patch)
 
-	return internal.InsertStringsInFile(f.Path,
+	return internal.InsertStringsInFile(filepath.Join(f.ProjectPath, f.Path),
 		map[string][]string{
 			kustomizeResourceScaffoldMarker:         {kustomizeResourceCodeFragment},
 			kustomizeWebhookPatchScaffoldMarker:     {kustomizeWebhookPatchCodeFragment},
@@ -74,6 +74,45 @@ func (f *Kustomization) Update() error {
 		})
 }
 
+// EnableConversion uncomments the patches of the kustomization of the CRDs at path enabling the conversion webhook
+// in the CRD of the resource and, if caInjection is set, the injection of the CA of cert-manager into it, the patches
+// are added again if they were removed
+func EnableConversion(path string, r *resource.Resource, caInjection bool) error {
+	in, err := filesystem.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	patch := r.QualifiedName(r.Plural(), "_")
+	content, missing := string(in), []string{}
+	entries := []string{fmt.Sprintf("patches/webhook_in_%s.yaml", patch)}
+	if caInjection {
+		entries = append(entries, fmt.Sprintf("patches/cainjection_in_%s.yaml", patch))
+	}
+	for _, entry := range entries {
+		if regexp.MustCompile(`(?m)^- ` + regexp.QuoteMeta(entry) + `$`).MatchString(content) {
+			continue
+		}
+		commented := regexp.MustCompile(`(?m)^#\s*- ` + regexp.QuoteMeta(entry) + `$`)
+		if loc := commented.FindStringIndex(content); loc != nil {
+			content = content[:loc[0]] + "- " + entry + content[loc[1]:]
+			continue
+		}
+		missing = append(missing, entry)
+	}
+	if content != string(in) {
+		if err := filesystem.WriteFile(path, []byte(content), 0644); err != nil {
+			return err
+		}
+	}
+	for _, entry := range missing {
+		if err := internal.AddToKustomization(path, "patchesStrategicMerge", entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 var kustomizationTemplate = fmt.Sprintf(`# This kustomization.yaml is not intended to be run by itself,
 # since it depends on service name and namespace that are out of this kustomize package.
 # It should be run by config/default
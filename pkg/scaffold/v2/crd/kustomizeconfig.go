@@ -32,7 +32,7 @@ type KustomizeConfig struct {
 // GetInput implements input.File
 func (f *KustomizeConfig) GetInput() (input.Input, error) {
 	if f.Path == "" {
-		f.Path = filepath.Join("config", "crd", "kustomizeconfig.yaml")
+		f.Path = filepath.Join(f.CRDDir, "kustomizeconfig.yaml")
 	}
 	f.TemplateBody = kustomizeConfigTemplate
 	return f.Input, nil
@@ -46,12 +46,20 @@ nameReference:
   fieldSpecs:
   - kind: CustomResourceDefinition
     group: apiextensions.k8s.io
+{{- if eq .Kubernetes.CRDVersion "v1beta1" }}
     path: spec/conversion/webhookClientConfig/service/name
+{{- else }}
+    path: spec/conversion/webhook/clientConfig/service/name
+{{- end }}
 
 namespace:
 - kind: CustomResourceDefinition
   group: apiextensions.k8s.io
+{{- if eq .Kubernetes.CRDVersion "v1beta1" }}
   path: spec/conversion/webhookClientConfig/service/namespace
+{{- else }}
+  path: spec/conversion/webhook/clientConfig/service/namespace
+{{- end }}
   create: false
 
 varReference:
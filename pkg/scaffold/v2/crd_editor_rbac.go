@@ -19,26 +19,30 @@ package v2
 import (
 	"fmt"
 	"path/filepath"
-	"strings"
 
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/internal"
 )
 
 var _ input.File = &CRDEditorRole{}
 
-// CRD Editor role scaffolds the config/rbca/<kind>_editor_role.yaml
+// CRD Editor role scaffolds the config/rbca/<kind>_editor_role.yaml, prefixed by the group if the kind is shared
 type CRDEditorRole struct {
 	input.Input
 
 	// Resource is a resource in the API group
 	Resource *resource.Resource
+
+	// Aggregate indicates whether the role is aggregated into the admin and edit ClusterRoles of Kubernetes, and
+	// deployed by the kustomization next to it for the aggregation to apply
+	Aggregate bool
 }
 
 // GetInput implements input.File
 func (f *CRDEditorRole) GetInput() (input.Input, error) {
 	if f.Path == "" {
-		f.Path = filepath.Join("config", "rbac", fmt.Sprintf("%s_editor_role.yaml", strings.ToLower(f.Resource.Kind)))
+		f.Path = filepath.Join(f.RBACDir, fmt.Sprintf("%s_editor_role.yaml", f.Resource.QualifiedName(f.Naming.FileName(f.Resource.Kind), "_")))
 	}
 
 	f.TemplateBody = crdRoleEditorTemplate
@@ -50,11 +54,34 @@ func (f *CRDEditorRole) Validate() error {
 	return f.Resource.Validate()
 }
 
+// Update adds the aggregated role to the resources of the kustomization next to it
+func (f *CRDEditorRole) Update() error {
+	if !f.Aggregate {
+		return nil
+	}
+	return internal.AddToKustomization(filepath.Join(f.ProjectPath, filepath.Dir(f.Path), "kustomization.yaml"),
+		"resources", filepath.Base(f.Path))
+}
+
 const crdRoleEditorTemplate = `# permissions for end users to edit {{ .Resource.Resource }}.
+{{- if not .Resource.Namespaced }}
+# {{ .Resource.Resource }} are cluster-scoped: bind this role with a ClusterRoleBinding, a RoleBinding grants nothing.
+{{- end }}
+{{- if .Resource.ShortNames }}
+# kubectl get {{ index .Resource.ShortNames 0 }} is resolved to {{ .Resource.Resource }} by kubectl, RBAC only knows the plural.
+{{- end }}
+{{- if .Aggregate }}
+# aggregated into the admin and edit ClusterRoles: the users granted them can edit {{ .Resource.Resource }} too.
+{{- end }}
 apiVersion: rbac.authorization.k8s.io/v1
 kind: ClusterRole
 metadata:
-  name: {{ lower .Resource.Kind }}-editor-role
+  name: {{ .Resource.QualifiedName (lower .Resource.Kind) "-" }}-editor-role
+{{- if .Aggregate }}
+  labels:
+    rbac.authorization.k8s.io/aggregate-to-admin: "true"
+    rbac.authorization.k8s.io/aggregate-to-edit: "true"
+{{- end }}
 rules:
 - apiGroups:
   - {{ .Resource.Group }}.{{ .Domain }}
@@ -19,7 +19,6 @@ package v2
 import (
 	"fmt"
 	"path/filepath"
-	"strings"
 
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
@@ -33,13 +32,29 @@ type CRDSample struct {
 
 	// Resource is a resource in the API group
 	Resource *resource.Resource
+
+	// Imported contains the fields of the spec lifted from an existing Go file or schema, replacing the Foo
+	// example field of the types, if any
+	Imported *ImportedTypes
+
+	// References are the fields of the spec referencing objects of other kinds
+	References []*Reference
+
+	// Unions are the fields of the spec of which exactly one member is set
+	Unions []*Union
+
+	// Embeds are the fields of the spec of Kubernetes types
+	Embeds []*Embed
+
+	// Enums are the fields of the spec or of the status whose value is one of a set of strings
+	Enums []*Enum
 }
 
 // GetInput implements input.File
 func (f *CRDSample) GetInput() (input.Input, error) {
 	if f.Path == "" {
 		f.Path = filepath.Join("config", "samples", fmt.Sprintf(
-			"%s_%s_%s.yaml", f.Resource.Group, f.Resource.Version, strings.ToLower(f.Resource.Kind)))
+			"%s_%s_%s.yaml", f.Resource.Group, f.Resource.Version, f.Naming.FileName(f.Resource.Kind)))
 	}
 
 	f.IfExistsAction = input.Error
@@ -52,11 +67,57 @@ func (f *CRDSample) Validate() error {
 	return f.Resource.Validate()
 }
 
-const crdSampleTemplate = `apiVersion: {{ .Resource.Group }}.{{ .Domain }}/{{ .Resource.Version }}
+// HasSpecFields returns true if the sample sets fields of the spec
+func (f *CRDSample) HasSpecFields() bool {
+	if f.Imported == nil || len(f.References) > 0 || len(f.Unions) > 0 || len(f.Embeds) > 0 {
+		return true
+	}
+	for _, enum := range f.Enums {
+		if !enum.Status {
+			return true
+		}
+	}
+	return false
+}
+
+const crdSampleTemplate = `
+{{- if not .Resource.HasDefaultPlural -}}
+# The {{ .Resource.Kind }} objects are listed with kubectl get {{ .Resource.Resource }}.{{ .Resource.Group }}.{{ .Domain }}
+{{ end -}}
+{{- if .Resource.ShortNames -}}
+# The {{ .Resource.Kind }} objects are listed by their short name too: kubectl get {{ index .Resource.ShortNames 0 }}
+{{ end -}}
+{{- if .Resource.Categories -}}
+# The {{ .Resource.Kind }} objects are listed along with the other resources of their category: kubectl get {{ index .Resource.Categories 0 }}
+{{ end -}}
+apiVersion: {{ .Resource.Group }}.{{ .Domain }}/{{ .Resource.Version }}
 kind: {{ .Resource.Kind }}
 metadata:
   name: {{ lower .Resource.Kind }}-sample
-spec:
+spec:{{ if not .HasSpecFields }} {}{{ end }}
+{{- if .Imported }}
+  # TODO(user): set the fields imported from {{ .Imported.Source }}
+{{- else }}
   # Add fields here
   foo: bar
+{{- end }}
+{{- range .References }}
+  {{ .JSONName }}:
+    {{- if .CrossNamespace }}
+    namespace: default
+    {{- end }}
+    name: {{ lower .Resource.Kind }}-sample
+{{- end }}
+{{- range .Unions }}
+  {{ .JSONName }}:
+    type: {{ (index .Members 0).Name }}
+    {{ (index .Members 0).JSONName }}: {}
+{{- end }}
+{{- range .Embeds }}
+  # TODO(user): set the {{ .Type }}
+  {{ .JSONName }}: {}
+{{- end }}
+{{- range .Enums }}{{ if not .Status }}
+  {{ .JSONName }}: {{ (index .Values 0).Value }}
+{{- end }}{{ end }}
 `
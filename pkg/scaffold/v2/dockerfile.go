@@ -36,6 +36,35 @@ func (f *Dockerfile) GetInput() (input.Input, error) {
 	return f.Input, nil
 }
 
+var _ input.File = &DockerIgnore{}
+
+// DockerIgnore scaffolds the .dockerignore limiting the build context of the Dockerfile to the go source and
+// to the manifests read by the manager at runtime
+type DockerIgnore struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *DockerIgnore) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = ".dockerignore"
+	}
+	f.TemplateBody = dockerIgnoreTemplate
+	return f.Input, nil
+}
+
+const dockerIgnoreTemplate = `# More info: https://docs.docker.com/engine/reference/builder/#dockerignore-file
+# Ignore the files which are not go source, the manager is built from every package of the project
+**
+!**/*.go
+!**/go.mod
+!**/go.sum
+# Keep the manifests read by the manager at runtime: the channels of the addon pattern and the manifests of the
+# hybrid pattern
+!channels/**
+!manifests/**
+`
+
 const dockerfileTemplate = `# Build the manager binary
 FROM golang:1.13 as builder
 
@@ -47,10 +76,10 @@ COPY go.sum go.sum
 # and so that source changes don't invalidate our downloaded layer
 RUN go mod download
 
-# Copy the go source
-COPY main.go main.go
-COPY api/ api/
-COPY controllers/ controllers/
+# Copy the go source, every package of the project, the other files are excluded by .dockerignore
+COPY . .
+# Create the directories of the manifests read by the manager at runtime, so that they are copied even when empty
+RUN mkdir -p channels manifests
 
 # Build
 RUN CGO_ENABLED=0 GOOS=linux GOARCH=amd64 GO111MODULE=on go build -a -o manager main.go
@@ -60,6 +89,9 @@ RUN CGO_ENABLED=0 GOOS=linux GOARCH=amd64 GO111MODULE=on go build -a -o manager
 FROM gcr.io/distroless/static:nonroot
 WORKDIR /
 COPY --from=builder /workspace/manager .
+# Copy the manifests next to the manager, they are read relative to its working directory
+COPY --from=builder /workspace/channels channels
+COPY --from=builder /workspace/manifests manifests
 USER nonroot:nonroot
 
 ENTRYPOINT ["/manager"]
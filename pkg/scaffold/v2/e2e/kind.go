@@ -0,0 +1,194 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+)
+
+var _ input.File = &KindTest{}
+
+// KindTest scaffolds the end-to-end test of a Resource, applying its sample and waiting until it is reconciled
+type KindTest struct {
+	input.Input
+
+	// Resource is the Resource whose sample is applied
+	Resource *resource.Resource
+
+	// ResourcePackage is the package of the Resource
+	ResourcePackage string
+
+	// ControllersImport is the import of the package of the controller of the Resource
+	ControllersImport string
+
+	// ControllersPackage is the name of the package of the controller of the Resource in the test
+	ControllersPackage string
+
+	// ReconcilerName is the name of the reconciler type of the Resource
+	ReconcilerName string
+
+	// TestName is the name of the Resource in the names of the test functions, prefixed by its group in
+	// multigroup projects where kinds of different groups share the package of the test
+	TestName string
+
+	// SamplePath is the slash separated path of the sample of the Resource relative to the test
+	SamplePath string
+
+	// StatusConditions indicates that the status of the Resource has conditions, the test waits until it is Ready
+	StatusConditions bool
+
+	// HealthCheck, WatchConfig, MessageBus and LargeScale indicate the dependencies the constructor of the
+	// reconciler takes, the test leaves the heartbeat, the configuration watcher and the trigger nil
+	HealthCheck bool
+	WatchConfig bool
+	MessageBus  bool
+	LargeScale  bool
+}
+
+// GetInput implements input.File
+func (f *KindTest) GetInput() (input.Input, error) {
+	f.ResourcePackage, _ = util.GetResourceInfo(f.ProjectPath, f.Resource, f.Repo, f.Domain, f.MultiGroup)
+	f.ReconcilerName = f.Naming.ReconcilerName(f.Resource.Kind)
+
+	name := f.Naming.FileName(f.Resource.Kind)
+	f.TestName = f.Resource.Kind
+	f.ControllersPackage = "controllers"
+	f.ControllersImport = strconv.Quote(path.Join(f.Repo, "controllers"))
+	if f.MultiGroup {
+		name = f.Resource.Group + "_" + name
+		f.TestName = strings.Title(f.Resource.GroupImportSafe) + f.Resource.Kind
+		f.ControllersPackage = "controller" + f.Resource.GroupImportSafe
+		f.ControllersImport = f.ControllersPackage + " " +
+			strconv.Quote(path.Join(f.Repo, "controllers", f.Resource.Group))
+	}
+
+	if f.Path == "" {
+		f.Path = filepath.Join("e2e", name+"_test.go")
+	}
+
+	if f.SamplePath == "" {
+		sample := filepath.Join("config", "samples", fmt.Sprintf(
+			"%s_%s_%s.yaml", f.Resource.Group, f.Resource.Version, f.Naming.FileName(f.Resource.Kind)))
+		rel, err := filepath.Rel(filepath.Dir(f.Path), sample)
+		if err != nil {
+			return input.Input{}, err
+		}
+		f.SamplePath = filepath.ToSlash(rel)
+	}
+
+	f.TemplateBody = kindTestTemplate
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *KindTest) Validate() error {
+	return f.Resource.Validate()
+}
+
+// nolint:lll
+const kindTestTemplate = `{{ .Boilerplate }}
+
+package e2e
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	{{ .Resource.GroupImportSafe }}{{ .Resource.Version }} "{{ .ResourcePackage }}/{{ .Resource.Version }}"
+	{{ .ControllersImport }}
+)
+
+func Test{{ .TestName }}E2E(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatalf("unable to add the built-in types to the scheme: %v", err)
+	}
+	if err := {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.AddToScheme(s); err != nil {
+		t.Fatalf("unable to add types to the scheme: %v", err)
+	}
+
+	c, stop := start(t, s, func(mgr ctrl.Manager) error {
+		// TODO(user): pass the other dependencies of the reconciler as main.go does.
+		return {{ .ControllersPackage }}.New{{ .ReconcilerName }}(
+			mgr.GetClient(),
+			ctrl.Log.WithName("controllers").WithName("{{ .Resource.Kind }}"),
+			mgr.GetScheme(),
+			mgr.GetEventRecorderFor("{{ lower .Resource.Kind }}-controller"),
+			clock.RealClock{},{{ if .HealthCheck }}
+			nil,{{ end }}{{ if .WatchConfig }}
+			nil,{{ end }}{{ if .MessageBus }}
+			nil,{{ end }}{{ if .LargeScale }}
+			mgr.GetAPIReader(),{{ end }}
+		).SetupWithManager(mgr)
+	})
+	defer stop()
+{{- if .Resource.Namespaced }}
+
+	ns, deleteNamespace := createNamespace(t, c)
+	defer deleteNamespace()
+{{- end }}
+
+	obj := &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{}
+	readSample(t, "{{ .SamplePath }}", obj)
+{{- if .Resource.Namespaced }}
+	obj.Namespace = ns.Name
+{{- end }}
+	if err := c.Create(context.Background(), obj); err != nil {
+		t.Fatalf("unable to create the {{ .Resource.Kind }}: %v", err)
+	}
+	defer deleteAndWait(t, c, obj)
+
+	key, err := client.ObjectKeyFromObject(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	began := time.Now()
+	eventually(t, "the {{ .Resource.Kind }} was not reconciled", func() (bool, error) {
+		if err := c.Get(context.Background(), key, obj); err != nil {
+			return false, err
+		}
+		return {{ lower .TestName }}E2EReconciled(obj), nil
+	})
+	t.Logf("the {{ .Resource.Kind }} %s was reconciled in %v", key, time.Since(began))
+}
+
+// {{ lower .TestName }}E2EReconciled returns true once the controller reconciled the {{ .Resource.Kind }} to the expected status.
+// TODO(user): check the status your controller sets, and the objects it creates for the {{ .Resource.Kind }}.
+func {{ lower .TestName }}E2EReconciled(obj *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) bool {
+{{- if .StatusConditions }}
+	return {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.IsConditionTrue(obj.Status.Conditions, {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.ConditionReady)
+{{- else }}
+	return true
+{{- end }}
+}
+`
@@ -0,0 +1,253 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package e2e
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+const makefileTarget = `# Run the end-to-end tests of the kinds in a test environment, or against the configured Kubernetes cluster in
+# ~/.kube/config where the manager is deployed when USE_EXISTING_CLUSTER=true
+test-e2e: generate fmt vet manifests
+	E2E_TEST=true go test ./e2e/... -v -count=1 -timeout 30m
+
+`
+
+var _ input.File = &Suite{}
+
+// Suite scaffolds the helpers of the end-to-end tests, starting a test environment running the controllers or
+// connecting to a cluster running the manager
+type Suite struct {
+	input.Input
+
+	// CRDDirectoryPath are the quoted elements of the path of the CRDs relative to the suite
+	CRDDirectoryPath string
+}
+
+// GetInput implements input.File
+func (f *Suite) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("e2e", "suite_test.go")
+	}
+	var err error
+	if f.CRDDirectoryPath, err = quotedPath(filepath.Dir(f.Path), filepath.Join(f.CRDDir, "bases")); err != nil {
+		return input.Input{}, err
+	}
+	f.TemplateBody = suiteTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+// Update adds the test-e2e target to the Makefile, before the docker targets, unless it already has it
+func (f *Suite) Update() error {
+	path := filepath.Join(f.ProjectPath, "Makefile")
+	in, err := filesystem.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	content := string(in)
+	if strings.Contains(content, "\ntest-e2e:") {
+		return nil
+	}
+	if i := strings.Index(content, "# Build the docker image"); i >= 0 {
+		content = content[:i] + makefileTarget + content[i:]
+	} else {
+		content += "\n" + strings.TrimSuffix(makefileTarget, "\n")
+	}
+	return filesystem.WriteFile(path, []byte(content), 0644)
+}
+
+// quotedPath returns the quoted elements of the path of target relative to dir
+func quotedPath(dir, target string) (string, error) {
+	rel, err := filepath.Rel(dir, target)
+	if err != nil {
+		return "", err
+	}
+	var elems []string
+	for _, elem := range strings.Split(filepath.ToSlash(rel), "/") {
+		elems = append(elems, strconv.Quote(elem))
+	}
+	return strings.Join(elems, ", "), nil
+}
+
+const suiteTemplate = `{{ .Boilerplate }}
+
+// Package e2e applies the samples of the kinds to a test environment or to a cluster, waits until they are
+// reconciled and deletes them. The tests are skipped by default, run them with make test-e2e or:
+//
+//   E2E_TEST=true go test ./e2e -v
+//
+// The objects are reconciled by the controllers running in a test environment, or by the manager deployed to the
+// cluster of the current kubeconfig with make deploy when USE_EXISTING_CLUSTER=true. E2E_TIMEOUT bounds the time
+// the objects have to be reconciled and deleted in, 2m by default.
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+// useExistingCluster is true when the tests run against the cluster of the current kubeconfig
+func useExistingCluster() bool {
+	return os.Getenv("USE_EXISTING_CLUSTER") == "true"
+}
+
+// timeout returns the time the objects have to be reconciled and deleted in
+func timeout(t *testing.T) time.Duration {
+	v := os.Getenv("E2E_TIMEOUT")
+	if v == "" {
+		return 2 * time.Minute
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		t.Fatalf("invalid E2E_TIMEOUT %q, must be a positive duration, e.g. 5m", v)
+	}
+	return d
+}
+
+// start skips the test unless E2E_TEST is set and returns a client of the cluster the test runs against, along
+// with the function stopping it. The controllers set up by setup reconcile the objects in a test environment, the
+// manager deployed to the cluster reconciles them when USE_EXISTING_CLUSTER=true.
+func start(t *testing.T, s *runtime.Scheme, setup func(mgr ctrl.Manager) error) (client.Client, func()) {
+	if os.Getenv("E2E_TEST") == "" {
+		t.Skip("set E2E_TEST to run the end-to-end tests")
+	}
+
+	if useExistingCluster() {
+		cfg, err := ctrl.GetConfig()
+		if err != nil {
+			t.Fatalf("unable to get the configuration of the cluster: %v", err)
+		}
+		c, err := client.New(cfg, client.Options{Scheme: s})
+		if err != nil {
+			t.Fatalf("unable to create the client: %v", err)
+		}
+		return c, func() {}
+	}
+
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths: []string{filepath.Join({{ .CRDDirectoryPath }})},
+	}
+	cfg, err := testEnv.Start()
+	if err != nil {
+		t.Fatalf("unable to start the test environment: %v", err)
+	}
+	stopEnv := func() {
+		if err := testEnv.Stop(); err != nil {
+			t.Errorf("unable to stop the test environment: %v", err)
+		}
+	}
+
+	mgr, err := ctrl.NewManager(cfg, ctrl.Options{Scheme: s, MetricsBindAddress: "0"})
+	if err != nil {
+		stopEnv()
+		t.Fatalf("unable to create the manager: %v", err)
+	}
+	if err := setup(mgr); err != nil {
+		stopEnv()
+		t.Fatalf("unable to create the controller: %v", err)
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := mgr.Start(stop); err != nil {
+			t.Errorf("unable to run the manager: %v", err)
+		}
+	}()
+	return mgr.GetClient(), func() {
+		close(stop)
+		<-done
+		stopEnv()
+	}
+}
+
+// createNamespace creates a namespace for the objects of a test, deleted when the test ends
+func createNamespace(t *testing.T, c client.Client) (*corev1.Namespace, func()) {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "e2e-"}}
+	if err := c.Create(context.Background(), ns); err != nil {
+		t.Fatalf("unable to create the namespace of the test: %v", err)
+	}
+	return ns, func() {
+		if err := client.IgnoreNotFound(c.Delete(context.Background(), ns)); err != nil {
+			t.Errorf("unable to delete the namespace of the test: %v", err)
+		}
+	}
+}
+
+// readSample decodes the sample at the slash separated path into obj
+func readSample(t *testing.T, path string, obj runtime.Object) {
+	b, err := ioutil.ReadFile(filepath.FromSlash(path))
+	if err != nil {
+		t.Fatalf("unable to read the sample: %v", err)
+	}
+	if err := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(b), len(b)).Decode(obj); err != nil {
+		t.Fatalf("unable to decode the sample: %v", err)
+	}
+}
+
+// eventually polls condition until it returns true, failing the test with message when it doesn't within the
+// timeout
+func eventually(t *testing.T, message string, condition func() (bool, error)) {
+	if err := wait.PollImmediate(time.Second, timeout(t), condition); err != nil {
+		t.Fatalf("%s: %v", message, err)
+	}
+}
+
+// deleteAndWait deletes the object and waits until it is gone, its finalizers having run
+func deleteAndWait(t *testing.T, c client.Client, obj runtime.Object) {
+	if err := client.IgnoreNotFound(c.Delete(context.Background(), obj)); err != nil {
+		t.Errorf("unable to delete the object: %v", err)
+		return
+	}
+	key, err := client.ObjectKeyFromObject(obj)
+	if err != nil {
+		t.Errorf("unable to get the key of the object: %v", err)
+		return
+	}
+	err = wait.PollImmediate(time.Second, timeout(t), func() (bool, error) {
+		err := c.Get(context.Background(), key, obj)
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		t.Errorf("the object %s was not deleted: %v", key, err)
+	}
+}
+`
@@ -0,0 +1,148 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+)
+
+var embeddedTypeRe = regexp.MustCompile(`^([a-z.]+)/(v[0-9][a-z0-9]*)/([A-Z][a-zA-Z0-9]*)$`)
+
+// embeddableGroups are the Kubernetes groups whose types may be embedded, and their directories in k8s.io/api
+var embeddableGroups = map[string]string{
+	"apps":               "apps",
+	"autoscaling":        "autoscaling",
+	"batch":              "batch",
+	"core":               "core",
+	"networking":         "networking",
+	"policy":             "policy",
+	"rbac.authorization": "rbac",
+	"scheduling":         "scheduling",
+	"storage":            "storage",
+}
+
+// metadataTypes are the embeddable types having an ObjectMeta, whose labels and annotations are pruned by the
+// API server unless the unknown fields are preserved: controller-gen generates its schema without properties
+var metadataTypes = map[string]bool{
+	"JobTemplateSpec":       true,
+	"PersistentVolumeClaim": true,
+	"PodTemplateSpec":       true,
+}
+
+// crdVersions are the API versions of the CRDs generated by the scaffolded Makefiles
+var crdVersions = []string{"v1beta1", "v1"}
+
+// crdOptions is the line of the scaffolded Makefile setting the options of the generation of the CRDs of the API
+// version
+func crdOptions(crdVersion string) string {
+	return `CRD_OPTIONS ?= "` + config.Compatibility{CRDVersion: crdVersion}.CRDOptions() + `"`
+}
+
+// Embed is a field of the spec of a resource of a Kubernetes type, e.g. a corev1.PodTemplateSpec
+type Embed struct {
+	// Field is the name of the Go field, e.g. Template
+	Field string
+
+	// JSONName is the name of the field in the spec, e.g. template
+	JSONName string
+
+	// Alias is the alias of the import of the package of the type, e.g. corev1
+	Alias string
+
+	// Package is the Go package of the type, e.g. k8s.io/api/core/v1
+	Package string
+
+	// Type is the name of the type, e.g. PodTemplateSpec
+	Type string
+
+	// HasMetadata indicates that the type has an ObjectMeta, whose fields are preserved
+	HasMetadata bool
+}
+
+// ParseEmbed parses an embedded field of the form <group>/<version>/<Type>:spec.<field>, e.g.
+// core/v1/PodTemplateSpec:spec.template
+func ParseEmbed(value string) (*Embed, error) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid embedded field %q, expected <group>/<version>/<Type>:spec.<field>", value)
+	}
+
+	typ := embeddedTypeRe.FindStringSubmatch(parts[0])
+	if typ == nil {
+		return nil, fmt.Errorf("invalid embedded type %q, expected <group>/<version>/<Type>, "+
+			"e.g. core/v1/PodTemplateSpec", parts[0])
+	}
+	dir, found := embeddableGroups[typ[1]]
+	if !found {
+		return nil, fmt.Errorf("invalid embedded type %q, only the types of the Kubernetes groups may be "+
+			"embedded, e.g. core or apps", parts[0])
+	}
+	field := specFieldRe.FindStringSubmatch(parts[1])
+	if field == nil {
+		return nil, fmt.Errorf("invalid embedded field %q, expected a lower camel case field of the spec, "+
+			"e.g. spec.template", parts[1])
+	}
+
+	return &Embed{
+		Field:       goName(field[1]),
+		JSONName:    field[1],
+		Alias:       dir + typ[2],
+		Package:     path.Join("k8s.io", "api", dir, typ[2]),
+		Type:        typ[3],
+		HasMetadata: metadataTypes[typ[3]],
+	}, nil
+}
+
+// Import returns the import spec of the package of the type
+func (e *Embed) Import() string {
+	return fmt.Sprintf("%s %q", e.Alias, e.Package)
+}
+
+// GoType returns the qualified Go type of the field, e.g. corev1.PodTemplateSpec
+func (e *Embed) GoType() string {
+	return e.Alias + "." + e.Type
+}
+
+// LimitCRDDescriptions drops the descriptions from the CRDs generated by the Makefile of the project, which are
+// most of the size of the schema of the embedded types. It returns false if the Makefile doesn't set the default
+// options of the CRDs, e.g. when they were changed, leaving it as is.
+func LimitCRDDescriptions(projectPath string) (bool, error) {
+	makefile := filepath.Join(projectPath, "Makefile")
+	in, err := filesystem.ReadFile(makefile)
+	if err != nil {
+		return false, err
+	}
+	content := string(in)
+	if strings.Contains(content, "maxDescLen=") {
+		return true, nil
+	}
+	for _, v := range crdVersions {
+		options := crdOptions(v)
+		if strings.Contains(content, options) {
+			content = strings.Replace(content, options, strings.TrimSuffix(options, `"`)+`,maxDescLen=0"`, 1)
+			return true, filesystem.WriteFile(makefile, []byte(content), 0644)
+		}
+	}
+	return false, nil
+}
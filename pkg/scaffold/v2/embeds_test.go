@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseEmbed(t *testing.T) {
+	embed, err := ParseEmbed("core/v1/PodTemplateSpec:spec.template")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if embed.Field != "Template" || embed.GoType() != "corev1.PodTemplateSpec" || !embed.HasMetadata ||
+		embed.Import() != `corev1 "k8s.io/api/core/v1"` {
+		t.Errorf("unexpected embedded field %+v", embed)
+	}
+
+	embed, err = ParseEmbed("rbac.authorization/v1/PolicyRule:spec.rules")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if embed.GoType() != "rbacv1.PolicyRule" || embed.HasMetadata || embed.Import() != `rbacv1 "k8s.io/api/rbac/v1"` {
+		t.Errorf("unexpected embedded field %+v", embed)
+	}
+
+	for _, value := range []string{
+		"core/v1/PodTemplateSpec",
+		"core/v1/PodTemplateSpec:status.template",
+		"core/v1/podTemplateSpec:spec.template",
+		"core/PodTemplateSpec:spec.template",
+		"crew/v1/Captain:spec.captain",
+	} {
+		if _, err := ParseEmbed(value); err == nil {
+			t.Errorf("%s: expected an error", value)
+		}
+	}
+}
+
+func TestLimitCRDDescriptions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubebuilder-embeds")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	makefile := filepath.Join(dir, "Makefile")
+	for _, options := range []string{"crd:trivialVersions=true", "crd:crdVersions=v1"} {
+		line := `CRD_OPTIONS ?= "` + options + `"`
+		if err := ioutil.WriteFile(makefile, []byte("IMG ?= controller:latest\n"+line+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 2; i++ {
+			if limited, err := LimitCRDDescriptions(dir); err != nil || !limited {
+				t.Fatalf("expected the descriptions to be limited, got %v and %v", limited, err)
+			}
+		}
+		content, err := ioutil.ReadFile(makefile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := strings.Count(string(content), `CRD_OPTIONS ?= "`+options+`,maxDescLen=0"`); n != 1 {
+			t.Errorf("expected the descriptions to be limited once, got:\n%s", content)
+		}
+	}
+
+	if err := ioutil.WriteFile(makefile, []byte(`CRD_OPTIONS ?= "crd"`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if limited, err := LimitCRDDescriptions(dir); err != nil || limited {
+		t.Errorf("expected the changed options to be left as is, got %v and %v", limited, err)
+	}
+}
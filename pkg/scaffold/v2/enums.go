@@ -0,0 +1,96 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	enumFieldRe = regexp.MustCompile(`^(spec|status)\.([a-z][a-zA-Z0-9]*)$`)
+	enumValueRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9]*$`)
+)
+
+// Enum is a field of the spec or of the status of a resource whose value is one of a set of strings, e.g. a phase
+type Enum struct {
+	// Field is the name of the Go field, e.g. Phase
+	Field string
+
+	// JSONName is the name of the field in the spec or in the status, e.g. phase
+	JSONName string
+
+	// Status indicates that the field is in the status, in the spec otherwise
+	Status bool
+
+	// Values are the values of the enum
+	Values []EnumValue
+}
+
+// EnumValue is a value of an Enum
+type EnumValue struct {
+	// Name is the suffix of the name of the Go constant of the value, e.g. Pending
+	Name string
+
+	// Value is the value, e.g. Pending
+	Value string
+}
+
+// ParseEnum parses an enum of the form spec.<field>=<value>,<value>... or status.<field>=<value>,<value>..., e.g.
+// status.phase=Pending,Running,Failed
+func ParseEnum(value string) (*Enum, error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid enum %q, expected spec.<field>=<value>,<value>...", value)
+	}
+
+	field := enumFieldRe.FindStringSubmatch(parts[0])
+	if field == nil {
+		return nil, fmt.Errorf("invalid enum field %q, expected a lower camel case field of the spec or of the "+
+			"status, e.g. spec.phase", parts[0])
+	}
+
+	e := &Enum{Field: goName(field[2]), JSONName: field[2], Status: field[1] == "status"}
+	seen := map[string]bool{}
+	for _, v := range strings.Split(parts[1], ",") {
+		if !enumValueRe.MatchString(v) {
+			return nil, fmt.Errorf("invalid value %q of enum %s, expected an alphanumeric value starting with a "+
+				"letter, e.g. Pending", v, parts[0])
+		}
+		// the values only differing by the case of their first letter would have the same constant
+		name := goName(v)
+		if seen[name] {
+			return nil, fmt.Errorf("value %q of enum %s is repeated", v, parts[0])
+		}
+		seen[name] = true
+		e.Values = append(e.Values, EnumValue{Name: name, Value: v})
+	}
+	if len(e.Values) < 2 {
+		return nil, fmt.Errorf("enum %s must have at least two values", parts[0])
+	}
+
+	return e, nil
+}
+
+// Path returns the path of the field in the object, e.g. spec.phase
+func (e *Enum) Path() string {
+	if e.Status {
+		return "status." + e.JSONName
+	}
+	return "spec." + e.JSONName
+}
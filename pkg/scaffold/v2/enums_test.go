@@ -0,0 +1,69 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseEnum(t *testing.T) {
+	e, err := ParseEnum("spec.phase=Pending,Running,failed")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &Enum{Field: "Phase", JSONName: "phase", Values: []EnumValue{
+		{Name: "Pending", Value: "Pending"},
+		{Name: "Running", Value: "Running"},
+		{Name: "Failed", Value: "failed"},
+	}}
+	if !reflect.DeepEqual(e, expected) {
+		t.Errorf("unexpected enum %+v", e)
+	}
+	if e.Path() != "spec.phase" {
+		t.Errorf("unexpected path %s", e.Path())
+	}
+
+	e, err = ParseEnum("status.tlsMode=http,https")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = &Enum{Field: "TlsMode", JSONName: "tlsMode", Status: true, Values: []EnumValue{
+		{Name: "HTTP", Value: "http"},
+		{Name: "HTTPS", Value: "https"},
+	}}
+	if !reflect.DeepEqual(e, expected) {
+		t.Errorf("unexpected enum %+v", e)
+	}
+	if e.Path() != "status.tlsMode" {
+		t.Errorf("unexpected path %s", e.Path())
+	}
+
+	for _, value := range []string{
+		"spec.phase",
+		"spec.phase=Pending",
+		"spec.phase=Pending,Pending",
+		"spec.phase=Pending,pending",
+		"spec.phase=Pending,",
+		"spec.phase=Pending,In-Progress",
+		"metadata.phase=Pending,Running",
+	} {
+		if _, err := ParseEnum(value); err == nil {
+			t.Errorf("%s: expected an error", value)
+		}
+	}
+}
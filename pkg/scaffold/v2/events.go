@@ -0,0 +1,259 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &Events{}
+
+// Events scaffolds the events subcommand of the manager listing the recent Events of an object
+type Events struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *Events) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "events", "events.go")
+	}
+	f.TemplateBody = eventsTemplate
+	return f.Input, nil
+}
+
+var _ input.File = &EventsTest{}
+
+// EventsTest scaffolds the tests of the events subcommand
+type EventsTest struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *EventsTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "events", "events_test.go")
+	}
+	f.TemplateBody = eventsTestTemplate
+	return f.Input, nil
+}
+
+const eventsTemplate = `{{ .Boilerplate }}
+
+// Package events lists the recent Events emitted for an object, by its controller or by the other components of
+// the cluster, to correlate them with the logs of its reconciles when debugging, e.g.
+//
+//   bin/manager events --kind Captain --namespace default --name captain-sample
+//
+// or make events KIND=Captain NAMESPACE=default NAME=captain-sample.
+package events
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Command is the name of the subcommand of the manager
+const Command = "events"
+
+// Selector returns the field selector of the Events of the object of kind named name in namespace, empty for a
+// cluster-scoped object
+func Selector(kind, namespace, name string) fields.Selector {
+	set := fields.Set{"involvedObject.kind": kind, "involvedObject.name": name}
+	if namespace != "" {
+		set["involvedObject.namespace"] = namespace
+	}
+	return fields.SelectorFromSet(set)
+}
+
+// List returns the Events of the object emitted since since ago, all of them if it is 0, the most recent last.
+// The field selector is evaluated by the API server, c must not read from a cache.
+func List(ctx context.Context, c client.Client, kind, namespace, name string,
+	since time.Duration) ([]corev1.Event, error) {
+	list := &corev1.EventList{}
+	opts := []client.ListOption{client.MatchingFieldsSelector{Selector: Selector(kind, namespace, name)}}
+	if namespace != "" {
+		opts = append(opts, client.InNamespace(namespace))
+	}
+	if err := c.List(ctx, list, opts...); err != nil {
+		return nil, err
+	}
+
+	var events []corev1.Event
+	for _, e := range list.Items {
+		if since == 0 || time.Since(lastSeen(e)) <= since {
+			events = append(events, e)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return lastSeen(events[i]).Before(lastSeen(events[j])) })
+	return events, nil
+}
+
+// lastSeen returns the last time the Event occurred
+func lastSeen(e corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	case !e.FirstTimestamp.IsZero():
+		return e.FirstTimestamp.Time
+	default:
+		return e.CreationTimestamp.Time
+	}
+}
+
+// Print writes the Events as a table
+func Print(w io.Writer, events []corev1.Event) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "LAST SEEN\tTYPE\tREASON\tSOURCE\tCOUNT\tMESSAGE")
+	for _, e := range events {
+		source := e.Source.Component
+		if source == "" {
+			source = e.ReportingController
+		}
+		count := e.Count
+		if count == 0 {
+			count = 1
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\n", lastSeen(e).Format(time.RFC3339), e.Type, e.Reason, source,
+			count, e.Message)
+	}
+	return tw.Flush()
+}
+
+// Run runs the subcommand with args, writing the Events to w, and returns its exit code
+func Run(args []string, w io.Writer) int {
+	fs := flag.NewFlagSet(Command, flag.ContinueOnError)
+	kind := fs.String("kind", "", "The kind of the object, e.g. Captain.")
+	namespace := fs.String("namespace", "default", "The namespace of the object, empty for a cluster-scoped object.")
+	name := fs.String("name", "", "The name of the object.")
+	since := fs.Duration("since", time.Hour, "Only list the Events seen within this duration, all of them if 0.")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *kind == "" || *name == "" {
+		fmt.Fprintln(fs.Output(), "the --kind and the --name of the object are required")
+		fs.Usage()
+		return 2
+	}
+
+	config, err := ctrl.GetConfig()
+	if err != nil {
+		fmt.Fprintf(fs.Output(), "unable to get the configuration of the cluster: %v\n", err)
+		return 1
+	}
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(fs.Output(), "unable to create a client: %v\n", err)
+		return 1
+	}
+
+	events, err := List(context.Background(), c, *kind, *namespace, *name, *since)
+	if err != nil {
+		fmt.Fprintf(fs.Output(), "unable to list the Events of %s %s: %v\n", *kind, *name, err)
+		return 1
+	}
+	if len(events) == 0 {
+		fmt.Fprintf(fs.Output(), "no Events of %s %s\n", *kind, *name)
+		return 0
+	}
+	if err := Print(w, events); err != nil {
+		return 1
+	}
+	return 0
+}
+`
+
+const eventsTestTemplate = `{{ .Boilerplate }}
+
+package events
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+func TestSelector(t *testing.T) {
+	event := fields.Set{
+		"involvedObject.kind":      "Captain",
+		"involvedObject.namespace": "default",
+		"involvedObject.name":      "test",
+	}
+	if !Selector("Captain", "default", "test").Matches(event) {
+		t.Errorf("expected the selector to match the Events of the object")
+	}
+	if Selector("Captain", "other", "test").Matches(event) {
+		t.Errorf("expected the selector not to match the Events of an object of another namespace")
+	}
+	if Selector("FirstMate", "default", "test").Matches(event) {
+		t.Errorf("expected the selector not to match the Events of an object of another kind")
+	}
+	if !Selector("Captain", "", "test").Matches(event) {
+		t.Errorf("expected the selector without namespace to match the Events of the objects of every namespace")
+	}
+}
+
+func TestPrint(t *testing.T) {
+	seen := metav1.NewTime(time.Date(2020, 3, 1, 10, 0, 0, 0, time.UTC))
+	event := corev1.Event{
+		Type:          corev1.EventTypeWarning,
+		Reason:        "ReconcileError",
+		Message:       "unable to create the child",
+		Source:        corev1.EventSource{Component: "captain-controller"},
+		Count:         3,
+		LastTimestamp: seen,
+	}
+	var out bytes.Buffer
+	if err := Print(&out, []corev1.Event{event}); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a header and an Event, got:\n%s", out.String())
+	}
+	for _, field := range []string{"2020-03-01T10:00:00Z", "Warning", "ReconcileError", "captain-controller", "3",
+		"unable to create the child"} {
+		if !strings.Contains(lines[1], field) {
+			t.Errorf("expected %q in the Event line %q", field, lines[1])
+		}
+	}
+}
+`
@@ -0,0 +1,156 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package examples
+
+import (
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+)
+
+var _ input.File = &Program{}
+
+// Program scaffolds the sample program creating, listing and updating the objects of a kind
+type Program struct {
+	input.Input
+
+	// Resource is the kind whose objects the program manages
+	Resource *resource.Resource
+
+	// Package is the name of the package of the version of the kind in the program
+	Package string
+
+	// Imports are the import specs of the package of the version of the kind and of the scheme of the examples
+	Imports []string
+
+	// Command is the path of the program relative to the project root, slash-separated
+	Command string
+}
+
+// GetInput implements input.File
+func (f *Program) GetInput() (input.Input, error) {
+	dir := programDir(f.Resource, f.Input)
+	if f.Path == "" {
+		f.Path = filepath.Join(dir, "main.go")
+	}
+	f.Command = "./" + filepath.ToSlash(dir)
+	var spec string
+	f.Package, spec = apiImport(f.Input, f.Resource)
+	f.Imports = []string{spec, strconv.Quote(path.Join(f.Repo, filepath.ToSlash(Dir), "scheme"))}
+	f.TemplateBody = programTemplate
+	f.IfExistsAction = input.Overwrite
+	return f.Input, nil
+}
+
+const programTemplate = `{{ .Boilerplate }}
+
+` + generatedHeader + `
+
+// The program creates, lists and updates the {{ .Resource.Kind }} objects of {{ .Resource.Group }}/{{ .Resource.Version }} with the
+// client of the APIs of the operator, in the cluster of the current kubeconfig:
+//
+//	go run {{ .Command }}{{ if .Resource.Namespaced }} -namespace default{{ end }} -name {{ lower .Resource.Kind }}-example
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+{{ range .Imports }}
+	{{ . }}{{ end }}
+)
+
+func main() {
+	name := flag.String("name", "{{ lower .Resource.Kind }}-example", "name of the {{ .Resource.Kind }}")
+{{- if .Resource.Namespaced }}
+	namespace := flag.String("namespace", "default", "namespace of the {{ .Resource.Kind }}")
+{{- end }}
+	cleanup := flag.Bool("cleanup", false, "delete the {{ .Resource.Kind }} once updated")
+	flag.Parse()
+
+	if err := run(context.Background(), {{ if .Resource.Namespaced }}*namespace{{ else }}""{{ end }}, *name, *cleanup); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run creates the {{ .Resource.Kind }} unless it exists, lists the {{ .Resource.Kind }} objects and updates the labels of
+// the {{ .Resource.Kind }}, deleting it afterwards if cleanup is set
+func run(ctx context.Context, namespace, name string, cleanup bool) error {
+	c, err := scheme.NewClient()
+	if err != nil {
+		return fmt.Errorf("unable to create the client: %v", err)
+	}
+	key := client.ObjectKey{Namespace: namespace, Name: name}
+
+	// the spec is left to its defaults, set its required fields for the {{ .Resource.Kind }} to be valid
+	obj := &{{ .Package }}.{{ .Resource.Kind }}{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	switch err := c.Create(ctx, obj); {
+	case apierrors.IsAlreadyExists(err):
+		fmt.Printf("{{ .Resource.Kind }} %s already exists\n", key)
+	case err != nil:
+		return fmt.Errorf("unable to create the {{ .Resource.Kind }} %s: %v", key, err)
+	default:
+		fmt.Printf("created the {{ .Resource.Kind }} %s\n", key)
+	}
+
+	list := &{{ .Package }}.{{ .Resource.Kind }}List{}
+	if err := c.List(ctx, list{{ if .Resource.Namespaced }}, client.InNamespace(namespace){{ end }}); err != nil {
+		return fmt.Errorf("unable to list the {{ .Resource.Kind }} objects: %v", err)
+	}
+	fmt.Printf("%d {{ .Resource.Kind }} objects:\n", len(list.Items))
+	for _, item := range list.Items {
+		fmt.Printf("- %s\n", client.ObjectKey{Namespace: item.Namespace, Name: item.Name})
+	}
+
+	// the update is retried on conflict, e.g. with the controller updating the status of the {{ .Resource.Kind }}
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := c.Get(ctx, key, obj); err != nil {
+			return err
+		}
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels["examples.kubebuilder.io/updated"] = "true"
+		obj.SetLabels(labels)
+		return c.Update(ctx, obj)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to update the {{ .Resource.Kind }} %s: %v", key, err)
+	}
+	fmt.Printf("updated the {{ .Resource.Kind }} %s\n", key)
+
+	if !cleanup {
+		return nil
+	}
+	if err := c.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("unable to delete the {{ .Resource.Kind }} %s: %v", key, err)
+	}
+	fmt.Printf("deleted the {{ .Resource.Kind }} %s\n", key)
+	return nil
+}
+`
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package examples
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+)
+
+var _ input.File = &README{}
+
+// README scaffolds the quickstart of the examples, listing the program of every kind
+type README struct {
+	input.Input
+
+	// Resources are the kinds of the project
+	Resources []*resource.Resource
+
+	// Commands are the paths of the programs of the kinds relative to the project root, slash-separated
+	Commands []string
+}
+
+// GetInput implements input.File
+func (f *README) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join(Dir, "README.md")
+	}
+	f.Commands = nil
+	for _, r := range f.Resources {
+		f.Commands = append(f.Commands, "./"+filepath.ToSlash(programDir(r, f.Input)))
+	}
+	f.TemplateBody = readmeTemplate
+	f.IfExistsAction = input.Overwrite
+	return f.Input, nil
+}
+
+const readmeTemplate = `# Client examples
+
+These programs create, list and update the objects of the APIs of the operator with a
+[controller-runtime](https://github.com/kubernetes-sigs/controller-runtime) client, in the
+cluster of the current kubeconfig. The ` + "`scheme`" + ` package registers the kinds of the
+operator along with the built-in kinds of Kubernetes, copy it to read and write them from
+another program.
+
+Install the CRDs with ` + "`make install`" + `, then run a program, e.g.:
+
+` + "```" + `sh
+{{- range $i, $r := .Resources }}
+go run {{ index $.Commands $i }}{{ if $r.Namespaced }} -namespace default{{ end }} -name {{ lower $r.Kind }}-example
+{{- end }}
+` + "```" + `
+
+The objects are created with the defaults of their spec, and are left in the cluster unless
+` + "`-cleanup`" + ` is set.
+
+The examples are generated by ` + "`kubebuilder generate examples`" + ` from the PROJECT file,
+and generated again when an API is created: edit copies of them rather than the examples
+themselves. They are type-checked by ` + "`make vet`" + ` and ` + "`make test`" + ` along with the rest of
+the project, which catches the changes of the APIs breaking them.
+`
@@ -0,0 +1,124 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package examples scaffolds the sample programs of the clients of the APIs of the project, for the teams
+// consuming the operator: a program per kind creating, listing and updating its objects with the scheme of the
+// project. The programs are generated again from the PROJECT file to keep them in sync with the APIs.
+package examples
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+)
+
+// Dir is the directory of the examples relative to the project root
+var Dir = filepath.Join("examples", "client-go")
+
+// generatedHeader marks the examples as generated, they are overwritten when generated again
+const generatedHeader = "// Code generated by kubebuilder generate examples. DO NOT EDIT."
+
+var _ input.File = &Scheme{}
+
+// Scheme scaffolds the package of the examples registering the kinds of the project and building their client
+type Scheme struct {
+	input.Input
+
+	// Resources are the kinds of the project
+	Resources []*resource.Resource
+
+	// Imports are the import specs of the packages of the versions of the kinds
+	Imports []string
+
+	// Packages are the names of the packages of the versions of the kinds in the imports
+	Packages []string
+}
+
+// GetInput implements input.File
+func (f *Scheme) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join(Dir, "scheme", "scheme.go")
+	}
+	seen := map[string]bool{}
+	for _, r := range f.Resources {
+		name, spec := apiImport(f.Input, r)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		f.Imports = append(f.Imports, spec)
+		f.Packages = append(f.Packages, name)
+	}
+	sort.Strings(f.Imports)
+	sort.Strings(f.Packages)
+	f.TemplateBody = schemeTemplate
+	f.IfExistsAction = input.Overwrite
+	return f.Input, nil
+}
+
+// apiImport returns the name of the package of the version of a kind in the examples and its import spec
+func apiImport(in input.Input, r *resource.Resource) (name, spec string) {
+	pkg, _ := util.GetResourceInfo(in.ProjectPath, r, in.Repo, in.Domain, in.MultiGroup)
+	name = r.GroupImportSafe + r.Version
+	return name, name + " " + strconv.Quote(path.Join(pkg, r.Version))
+}
+
+// programDir returns the directory of the program of a kind relative to the project root
+func programDir(r *resource.Resource, in input.Input) string {
+	return filepath.Join(Dir, fmt.Sprintf("%s_%s_%s", r.Group, r.Version, in.Naming.FileName(r.Kind)))
+}
+
+const schemeTemplate = `{{ .Boilerplate }}
+
+` + generatedHeader + `
+
+// Package scheme registers the kinds of the APIs of the operator along with the built-in kinds of Kubernetes, for
+// the examples to read and write them with a client of the cluster.
+package scheme
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+{{ range .Imports }}
+	{{ . }}{{ end }}
+)
+
+// Scheme holds the kinds of the APIs of the operator and the built-in kinds
+var Scheme = runtime.NewScheme()
+
+func init() {
+	_ = clientgoscheme.AddToScheme(Scheme){{ range .Packages }}
+	_ = {{ . }}.AddToScheme(Scheme){{ end }}
+}
+
+// NewClient returns a client of the cluster of the current kubeconfig, or of the cluster the program runs in,
+// reading and writing the kinds of the Scheme
+func NewClient() (client.Client, error) {
+	cfg, err := ctrl.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(cfg, client.Options{Scheme: Scheme})
+}
+`
@@ -0,0 +1,106 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package expose
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &MetricsGateway{}
+
+// MetricsGateway scaffolds a Gateway API Gateway and TLSRoute exposing the metrics endpoint
+type MetricsGateway struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *MetricsGateway) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "expose", "gateway.yaml")
+	}
+	f.TemplateBody = metricsGatewayTemplate
+	return f.Input, nil
+}
+
+const metricsGatewayTemplate = `# Replace the gateway class and the host.
+# The TLS connections are passed through so that the metrics endpoint terminates them and authorizes the requests.
+apiVersion: gateway.networking.k8s.io/v1beta1
+kind: Gateway
+metadata:
+  name: metrics-gateway
+  namespace: system
+spec:
+  gatewayClassName: example
+  listeners:
+  - name: metrics
+    hostname: metrics.example.com
+    port: 443
+    protocol: TLS
+    tls:
+      mode: Passthrough
+---
+apiVersion: gateway.networking.k8s.io/v1alpha2
+kind: TLSRoute
+metadata:
+  name: metrics-route
+  namespace: system
+spec:
+  parentRefs:
+  - name: metrics-gateway
+    sectionName: metrics
+  hostnames:
+  - metrics.example.com
+  rules:
+  - backendRefs:
+    - name: controller-manager-metrics-service
+      port: 8443
+`
+
+var _ input.File = &KustomizeConfig{}
+
+// KustomizeConfig scaffolds the kustomize configuration teaching kustomize about the Gateway API references
+type KustomizeConfig struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *KustomizeConfig) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "expose", "kustomizeconfig.yaml")
+	}
+	f.TemplateBody = kustomizeConfigTemplate
+	return f.Input, nil
+}
+
+const kustomizeConfigTemplate = `# the following config is for teaching kustomize where to look at when substituting vars.
+# It requires kustomize v2.1.0 or newer to work properly.
+nameReference:
+- kind: Service
+  version: v1
+  fieldSpecs:
+  - kind: TLSRoute
+    group: gateway.networking.k8s.io
+    path: spec/rules/backendRefs/name
+- kind: Gateway
+  group: gateway.networking.k8s.io
+  fieldSpecs:
+  - kind: TLSRoute
+    group: gateway.networking.k8s.io
+    path: spec/parentRefs/name
+`
@@ -0,0 +1,72 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package expose
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &MetricsIngress{}
+
+// MetricsIngress scaffolds an Ingress exposing the metrics endpoint
+type MetricsIngress struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *MetricsIngress) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "expose", "ingress.yaml")
+	}
+	f.TemplateBody = metricsIngressTemplate
+	return f.Input, nil
+}
+
+const metricsIngressTemplate = `# Replace the host and provide a TLS certificate for it in the metrics-tls secret.
+apiVersion: networking.k8s.io/{{ .Kubernetes.IngressVersion }}
+kind: Ingress
+metadata:
+  name: metrics-ingress
+  namespace: system
+  annotations:
+    # The metrics endpoint only serves https, keep the traffic encrypted up to it
+    nginx.ingress.kubernetes.io/backend-protocol: HTTPS
+spec:
+  tls:
+  - hosts:
+    - metrics.example.com
+    secretName: metrics-tls
+  rules:
+  - host: metrics.example.com
+    http:
+      paths:
+      - path: /metrics
+{{- if eq .Kubernetes.IngressVersion "v1beta1" }}
+        backend:
+          serviceName: controller-manager-metrics-service
+          servicePort: https
+{{- else }}
+        pathType: Prefix
+        backend:
+          service:
+            name: controller-manager-metrics-service
+            port:
+              name: https
+{{- end }}
+`
@@ -0,0 +1,61 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package expose
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+// Kinds of exposure of the metrics endpoint
+const (
+	Ingress = "ingress"
+	Gateway = "gateway"
+)
+
+var _ input.File = &Kustomization{}
+
+// Kustomization scaffolds the kustomization in the expose folder
+type Kustomization struct {
+	input.Input
+
+	// Kind is the kind of exposure, one of "ingress" or "gateway"
+	Kind string
+}
+
+// GetInput implements input.File
+func (f *Kustomization) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "expose", "kustomization.yaml")
+	}
+	f.TemplateBody = kustomizationTemplate
+	return f.Input, nil
+}
+
+const kustomizationTemplate = `# Exposes the /metrics endpoint out of the cluster, which authorizes the requests as in the cluster.
+# Clients need to be bound to the metrics-reader cluster role to be authorized.
+resources:
+{{- if eq .Kind "gateway" }}
+- gateway.yaml
+
+configurations:
+- kustomizeconfig.yaml
+{{- else }}
+- ingress.yaml
+{{- end }}
+`
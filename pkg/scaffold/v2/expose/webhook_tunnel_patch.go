@@ -0,0 +1,54 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package expose
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &WebhookTunnelPatch{}
+
+// WebhookTunnelPatch scaffolds a patch pointing the webhook service to a tunnel, for developing the
+// webhooks out of the cluster
+type WebhookTunnelPatch struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *WebhookTunnelPatch) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "default", "webhook_tunnel_patch.yaml")
+	}
+	f.TemplateBody = webhookTunnelPatchTemplate
+	return f.Input, nil
+}
+
+const webhookTunnelPatchTemplate = `# Points the webhook service to a tunnel reaching the webhook server running out of the cluster,
+# e.g. with 'make run'. Replace the external name with the host of the tunnel, which must serve
+# the webhooks on port 443 with a certificate matching the caBundle of the webhook configurations.
+apiVersion: v1
+kind: Service
+metadata:
+  name: webhook-service
+  namespace: system
+spec:
+  type: ExternalName
+  externalName: webhook.tunnel.example.com
+  selector: null
+`
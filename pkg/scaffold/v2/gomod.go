@@ -26,6 +26,8 @@ var _ input.File = &GoMod{}
 type GoMod struct {
 	input.Input
 	ControllerRuntimeVersion string
+	// WireVersion is the version of github.com/google/wire, only required if the project injects with it
+	WireVersion string
 }
 
 // GetInput implements input.File
@@ -44,6 +46,9 @@ module {{ .Repo }}
 go 1.13
 
 require (
+{{- if .WireVersion }}
+	github.com/google/wire {{ .WireVersion }}
+{{- end }}
 	sigs.k8s.io/controller-runtime {{ .ControllerRuntimeVersion }}
 )
 `
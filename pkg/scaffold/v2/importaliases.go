@@ -0,0 +1,214 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+)
+
+// importAliasSkippedDirs are the directories whose Go files aren't the ones of the project
+var importAliasSkippedDirs = map[string]bool{"vendor": true, "bin": true, "testdata": true, ".git": true}
+
+// FixImportAliases renames the imports of the packages of the registered group versions which the older scaffolds
+// named by their short name although it is taken, e.g. monitoring.coreos.com/v1 imported as monitoringv1 like the
+// group monitoring of the project, in the Go files of the project in dir, see config.Config.SchemeImportAlias.
+// It returns the paths of the renamed files relative to dir, which are left as is if dryRun is true.
+func FixImportAliases(dir string, c *config.Config, dryRun bool) ([]string, error) {
+	renames := map[string]string{}
+	for _, s := range c.Schemes {
+		if alias := c.SchemeImportAlias(s); alias != s.ImportAlias() {
+			renames[s.Package] = alias
+		}
+	}
+	if len(renames) == 0 {
+		return nil, nil
+	}
+
+	if dir == "" {
+		dir = "."
+	}
+	var changed []string
+	err := afero.Walk(filesystem.FS, dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != dir && importAliasSkippedDirs[info.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".go" {
+			return nil
+		}
+
+		src, err := filesystem.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		renamed, ok, err := renameImports(src, c, renames)
+		if err != nil || !ok {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		changed = append(changed, rel)
+		if dryRun {
+			return nil
+		}
+		if err := filesystem.WriteFile(path, renamed, info.Mode()); err != nil {
+			return fmt.Errorf("error renaming the imports of %s: %v", rel, err)
+		}
+		return nil
+	})
+	return changed, err
+}
+
+// renameImports renames the imports of the packages named by their short name to their name in renames, indexed
+// by package, along with their uses. It returns false if the source imports none of them by its short name or
+// can't be parsed.
+func renameImports(src []byte, c *config.Config, renames map[string]string) ([]byte, bool, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		// the file isn't Go code the scaffolds wrote, it is left to the compiler
+		return nil, false, nil
+	}
+
+	// the offsets of the identifiers to rename, along with their length and their new name
+	type edit struct {
+		offset, length int
+		name           string
+	}
+	var edits []edit
+	for _, spec := range f.Imports {
+		pkg, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || spec.Name == nil {
+			continue
+		}
+		alias, found := renames[pkg]
+		if !found || spec.Name.Name == alias {
+			continue
+		}
+		old := spec.Name.Name
+		if s := schemeOfPackage(c, pkg); s == nil || s.ImportAlias() != old {
+			// the package was named on purpose, e.g. by hand
+			continue
+		}
+		edits = append(edits, edit{fset.Position(spec.Name.Pos()).Offset, len(old), alias})
+		for _, ident := range usesOfImport(f, spec, projectIdentifiers(c, old)) {
+			edits = append(edits, edit{fset.Position(ident.Pos()).Offset, len(old), alias})
+		}
+	}
+	if len(edits) == 0 {
+		return nil, false, nil
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].offset > edits[j].offset })
+	renamed := string(src)
+	for _, e := range edits {
+		renamed = renamed[:e.offset] + e.name + renamed[e.offset+e.length:]
+	}
+	formatted, err := format.Source([]byte(renamed))
+	if err != nil {
+		return nil, false, err
+	}
+	return formatted, true, nil
+}
+
+// usesOfImport returns the identifiers of the package imported by spec in the selectors of the file. If another
+// package of the file is imported under the same name, e.g. the group version of the project the older scaffolds
+// named alike, the selectors of the identifiers of the project are left to it, and the last one of its
+// registrations in the scheme is the registration of the package of spec.
+func usesOfImport(f *ast.File, spec *ast.ImportSpec, project map[string]bool) []*ast.Ident {
+	name := spec.Name.Name
+	shared := false
+	for _, other := range f.Imports {
+		if other != spec && other.Name != nil && other.Name.Name == name {
+			shared = true
+		}
+	}
+
+	var uses, registrations []*ast.Ident
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		// the identifiers of the imported packages aren't resolved by the parser, unlike the local ones
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok || ident.Name != name || ident.Obj != nil {
+			return true
+		}
+		switch {
+		case !shared:
+			uses = append(uses, ident)
+		case sel.Sel.Name == "AddToScheme":
+			registrations = append(registrations, ident)
+		case !project[sel.Sel.Name]:
+			uses = append(uses, ident)
+		}
+		return true
+	})
+	// a single registration is the one of the project, the older scaffolds skipped the one of the package
+	if len(registrations) > 1 {
+		uses = append(uses, registrations[len(registrations)-1])
+	}
+	return uses
+}
+
+// projectIdentifiers returns the identifiers of the group versions of the project imported as alias which the
+// scaffolds use, i.e. their kinds and their registration
+func projectIdentifiers(c *config.Config, alias string) map[string]bool {
+	safe := strings.NewReplacer("-", "", ".", "")
+	identifiers := map[string]bool{"GroupVersion": true, "SchemeBuilder": true}
+	for _, r := range c.Resources {
+		if safe.Replace(r.Group)+r.Version != alias {
+			continue
+		}
+		for _, suffix := range []string{"", "List", "Spec", "Status"} {
+			identifiers[r.Kind+suffix] = true
+		}
+	}
+	return identifiers
+}
+
+// schemeOfPackage returns the registered group version whose types are in the package, nil if there is none
+func schemeOfPackage(c *config.Config, pkg string) *config.Scheme {
+	for i := range c.Schemes {
+		if c.Schemes[i].Package == pkg {
+			return &c.Schemes[i]
+		}
+	}
+	return nil
+}
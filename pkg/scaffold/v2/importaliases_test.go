@@ -0,0 +1,103 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+)
+
+func TestRenameImports(t *testing.T) {
+	c := &config.Config{
+		Resources: []config.GVK{{Group: "monitoring", Version: "v1", Kind: "Probe"}},
+		Schemes: []config.Scheme{{
+			Group:   "monitoring.coreos.com",
+			Version: "v1",
+			Package: "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1",
+		}},
+	}
+	renames := map[string]string{
+		"github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1": "monitoringcoreoscomv1",
+	}
+
+	src := `package controllers
+
+import (
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+	monitoringv1 "example.org/project/api/v1"
+)
+
+func reconcile(probe *monitoringv1.Probe) *monitoringv1.ServiceMonitor {
+	_ = monitoringv1.AddToScheme(nil)
+	_ = monitoringv1.AddToScheme(nil)
+	return &monitoringv1.ServiceMonitor{}
+}
+`
+	renamed, ok, err := renameImports([]byte(src), c, renames)
+	if err != nil || !ok {
+		t.Fatalf("expected the imports to be renamed, got %v, %v", ok, err)
+	}
+	for _, expected := range []string{
+		`monitoringcoreoscomv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"`,
+		`monitoringv1 "example.org/project/api/v1"`,
+		"func reconcile(probe *monitoringv1.Probe) *monitoringcoreoscomv1.ServiceMonitor {",
+		"_ = monitoringv1.AddToScheme(nil)\n\t_ = monitoringcoreoscomv1.AddToScheme(nil)",
+		"return &monitoringcoreoscomv1.ServiceMonitor{}",
+	} {
+		if !strings.Contains(string(renamed), expected) {
+			t.Errorf("expected %q in:\n%s", expected, renamed)
+		}
+	}
+
+	// the imports named by hand and the local identifiers are left alone
+	src = `package controllers
+
+import (
+	prometheus "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+)
+
+var monitor prometheus.ServiceMonitor
+`
+	if _, ok, err := renameImports([]byte(src), c, renames); ok || err != nil {
+		t.Errorf("expected the import named by hand to be left alone, got %v, %v", ok, err)
+	}
+
+	src = `package controllers
+
+import (
+	monitoringv1 "github.com/coreos/prometheus-operator/pkg/apis/monitoring/v1"
+)
+
+func list() {
+	var monitor monitoringv1.ServiceMonitor
+	{
+		monitoringv1 := monitor
+		_ = monitoringv1.Spec
+	}
+}
+`
+	renamed, ok, err = renameImports([]byte(src), c, renames)
+	if err != nil || !ok {
+		t.Fatalf("expected the imports to be renamed, got %v, %v", ok, err)
+	}
+	if !strings.Contains(string(renamed), "var monitor monitoringcoreoscomv1.ServiceMonitor") ||
+		!strings.Contains(string(renamed), "_ = monitoringv1.Spec") {
+		t.Errorf("expected only the uses of the import to be renamed in:\n%s", renamed)
+	}
+}
@@ -0,0 +1,536 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gobuffalo/flect"
+)
+
+// jsonSchema is the subset of an OpenAPI v3 or JSON schema the spec of a resource is generated from
+type jsonSchema struct {
+	Ref                  string          `json:"$ref"`
+	Type                 string          `json:"type"`
+	Format               string          `json:"format"`
+	Description          string          `json:"description"`
+	Properties           properties      `json:"properties"`
+	Required             []string        `json:"required"`
+	Items                *jsonSchema     `json:"items"`
+	AdditionalProperties json.RawMessage `json:"additionalProperties"`
+	Enum                 []interface{}   `json:"enum"`
+	Minimum              *float64        `json:"minimum"`
+	Maximum              *float64        `json:"maximum"`
+	// ExclusiveMinimum and ExclusiveMaximum are booleans in OpenAPI v3 and numbers in recent JSON schemas
+	ExclusiveMinimum interface{}  `json:"exclusiveMinimum"`
+	ExclusiveMaximum interface{}  `json:"exclusiveMaximum"`
+	MinLength        *int64       `json:"minLength"`
+	MaxLength        *int64       `json:"maxLength"`
+	MinItems         *int64       `json:"minItems"`
+	MaxItems         *int64       `json:"maxItems"`
+	Pattern          string       `json:"pattern"`
+	Default          interface{}  `json:"default"`
+	Nullable         bool         `json:"nullable"`
+	IntOrString      bool         `json:"x-kubernetes-int-or-string"`
+	AllOf            []jsonSchema `json:"allOf"`
+	AnyOf            []jsonSchema `json:"anyOf"`
+	OneOf            []jsonSchema `json:"oneOf"`
+
+	Definitions map[string]*jsonSchema `json:"definitions"`
+	Defs        map[string]*jsonSchema `json:"$defs"`
+	Components  struct {
+		Schemas map[string]*jsonSchema `json:"schemas"`
+	} `json:"components"`
+}
+
+// property is a property of an object schema
+type property struct {
+	Name   string
+	Schema *jsonSchema
+}
+
+// properties are the properties of an object schema, in the order of the schema
+type properties []property
+
+// UnmarshalJSON implements json.Unmarshaler keeping the order of the properties, which is the order of the fields
+func (p *properties) UnmarshalJSON(data []byte) error {
+	d := json.NewDecoder(bytes.NewReader(data))
+	if t, err := d.Token(); err != nil {
+		return err
+	} else if t != json.Delim('{') {
+		return fmt.Errorf("properties must be an object")
+	}
+	for d.More() {
+		t, err := d.Token()
+		if err != nil {
+			return err
+		}
+		s := &jsonSchema{}
+		if err := d.Decode(s); err != nil {
+			return err
+		}
+		*p = append(*p, property{Name: t.(string), Schema: s})
+	}
+	return nil
+}
+
+// schemaRefPrefixes are the prefixes of the local references to the definitions of a schema
+var schemaRefPrefixes = []string{"#/definitions/", "#/$defs/", "#/components/schemas/"}
+
+var nonAlphanumericRe = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// schemaImporter generates the Go types of the schema of the spec of a kind
+type schemaImporter struct {
+	root     *jsonSchema
+	imported *ImportedTypes
+	// declared are the names of the types declared so far
+	declared map[string]bool
+	// reserved are the names of the types scaffolded along with the imported ones
+	reserved map[string]bool
+}
+
+// ImportSchema parses the OpenAPI v3 or JSON schema at path and generates the fields of the spec of kind, along
+// with the nested structs and enums they use. The schema is the one of the spec, of the whole object, with the
+// spec as a property, or a document defining <kind>Spec or <kind>.
+func ImportSchema(path, kind string) (*ImportedTypes, error) {
+	src, err := ioutil.ReadFile(path) // nolint: gosec
+	if err != nil {
+		return nil, err
+	}
+
+	root := &jsonSchema{}
+	if err := json.Unmarshal(src, root); err != nil {
+		return nil, fmt.Errorf("unable to parse %s as a JSON schema: %v", path, err)
+	}
+	return importSchema(path, root, kind)
+}
+
+// importSchema generates the fields of the spec of kind from the schema root read from source
+func importSchema(source string, root *jsonSchema, kind string) (*ImportedTypes, error) {
+	i := &schemaImporter{
+		root:     root,
+		imported: &ImportedTypes{Source: source},
+		declared: map[string]bool{},
+		reserved: map[string]bool{
+			kind: true, kind + "Spec": true, kind + "Status": true, kind + "List": true,
+			"Condition": true, "ConditionReady": true,
+		},
+	}
+
+	spec := root.specSchema(kind)
+	if spec == nil {
+		return nil, fmt.Errorf("%s: no schema of the spec found, expected an object schema, the schema of an "+
+			"object with a spec property or the definition of %sSpec or %s", source, kind, kind)
+	}
+	spec, err := i.resolve(spec)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", source, err)
+	}
+
+	fields, err := i.fields(kind, spec, "spec")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", source, err)
+	}
+	i.imported.Fields = fields
+	sort.Strings(i.imported.Imports)
+
+	return i.imported, nil
+}
+
+// specSchema returns the schema of the spec of kind in the document s
+func (s *jsonSchema) specSchema(kind string) *jsonSchema {
+	for _, definitions := range []map[string]*jsonSchema{s.Definitions, s.Defs, s.Components.Schemas} {
+		if spec, found := definitions[kind+"Spec"]; found {
+			return spec
+		}
+		if object, found := definitions[kind]; found {
+			return object.propertyNamed("spec")
+		}
+	}
+	if spec := s.propertyNamed("spec"); spec != nil {
+		return spec
+	}
+	if len(s.Properties) > 0 {
+		return s
+	}
+	return nil
+}
+
+// propertyNamed returns the schema of the property of s named name, or nil
+func (s *jsonSchema) propertyNamed(name string) *jsonSchema {
+	for _, p := range s.Properties {
+		if p.Name == name {
+			return p.Schema
+		}
+	}
+	return nil
+}
+
+// resolve returns the definition s refers to, or s if it isn't a reference
+func (i *schemaImporter) resolve(s *jsonSchema) (*jsonSchema, error) {
+	if s.Ref == "" {
+		return s, nil
+	}
+	if _, def := i.definition(s.Ref); def != nil {
+		return def, nil
+	}
+	return nil, fmt.Errorf("unable to resolve %s, only the local definitions of the schema can be referenced", s.Ref)
+}
+
+// definition returns the name and the schema of the local definition ref refers to
+func (i *schemaImporter) definition(ref string) (string, *jsonSchema) {
+	definitions := []map[string]*jsonSchema{i.root.Definitions, i.root.Defs, i.root.Components.Schemas}
+	for n, prefix := range schemaRefPrefixes {
+		if strings.HasPrefix(ref, prefix) {
+			name := strings.TrimPrefix(ref, prefix)
+			return name, definitions[n][name]
+		}
+	}
+	return "", nil
+}
+
+// fields returns the Go fields of the properties of the object schema s, the fields of the type named typeName
+func (i *schemaImporter) fields(typeName string, s *jsonSchema, path string) ([]string, error) {
+	required := map[string]bool{}
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	var fields []string
+	seen := map[string]bool{}
+	for _, p := range s.Properties {
+		name := exportedName(p.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%s.%s: unable to name the field of the property", path, p.Name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%s.%s: field %s is declared more than once", path, p.Name, name)
+		}
+		seen[name] = true
+
+		field, err := i.field(typeName+name, name, p, required[p.Name], path+"."+p.Name)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// field returns the Go field named name of the property p, naming the types it declares typeName
+func (i *schemaImporter) field(typeName, name string, p property, required bool, path string) (string, error) {
+	goType, err := i.goType(typeName, p.Schema, path)
+	if err != nil {
+		return "", err
+	}
+
+	s, err := i.resolve(p.Schema)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", path, err)
+	}
+
+	var b strings.Builder
+	writeDescription(&b, s.Description)
+	for _, marker := range i.markers(s, goType, path) {
+		b.WriteString("// " + marker + "\n")
+	}
+
+	jsonTag := p.Name
+	if required {
+		b.WriteString("// +kubebuilder:validation:Required\n")
+	} else {
+		b.WriteString("// +optional\n")
+		jsonTag += ",omitempty"
+		if i.declared[goType] && isObject(s) {
+			goType = "*" + goType
+		}
+	}
+	b.WriteString(fmt.Sprintf("%s %s `json:\"%s\"`", name, goType, jsonTag))
+	return b.String(), nil
+}
+
+// goType returns the Go type of the schema s, declaring the structs and enums it needs named typeName
+func (i *schemaImporter) goType(typeName string, s *jsonSchema, path string) (string, error) {
+	if len(s.AllOf) > 0 || len(s.AnyOf) > 0 || len(s.OneOf) > 0 {
+		return "", fmt.Errorf("%s: allOf, anyOf and oneOf are not supported, declare unions with --union", path)
+	}
+
+	if s.Ref != "" {
+		name, def := i.definition(s.Ref)
+		if def == nil {
+			return "", fmt.Errorf("%s: unable to resolve %s, only the local definitions of the schema can be "+
+				"referenced", path, s.Ref)
+		}
+		if !isObject(def) && len(def.Enum) == 0 {
+			// a definition of a scalar, e.g. a string of a given format, is inlined
+			return i.goType(typeName, def, path)
+		}
+		return i.declare(exportedName(name), def, s.Ref)
+	}
+
+	if s.IntOrString {
+		i.imported.Imports = appendUnique(i.imported.Imports, `"k8s.io/apimachinery/pkg/util/intstr"`)
+		return "intstr.IntOrString", nil
+	}
+
+	switch s.Type {
+	case "string":
+		if len(s.Enum) > 0 {
+			return i.declare(typeName, s, path)
+		}
+		if s.Format == "date-time" {
+			return "metav1.Time", nil
+		}
+		return "string", nil
+	case "integer":
+		if s.Format == "int32" {
+			return "int32", nil
+		}
+		return "int64", nil
+	case "number":
+		i.imported.Warnings = append(i.imported.Warnings, fmt.Sprintf(
+			"%s is a float, which the API conventions discourage, consider a resource.Quantity or a string", path))
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		if s.Items == nil {
+			return "", fmt.Errorf("%s: the items of the array have no schema", path)
+		}
+		item, err := i.goType(flect.Singularize(typeName), s.Items, path+"[]")
+		if err != nil {
+			return "", err
+		}
+		if items, err := i.resolve(s.Items); err == nil && !isObject(items) && len(i.markers(items, item, "")) > 0 {
+			i.imported.Warnings = append(i.imported.Warnings, fmt.Sprintf(
+				"the validations of the items of %s were not imported", path))
+		}
+		return "[]" + item, nil
+	case "object", "":
+		if len(s.Properties) > 0 {
+			return i.declare(typeName, s, path)
+		}
+		values, err := s.additionalProperties()
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", path, err)
+		}
+		if values != nil {
+			value, err := i.goType(flect.Singularize(typeName), values, path+"[]")
+			if err != nil {
+				return "", err
+			}
+			return "map[string]" + value, nil
+		}
+		if s.Type == "" {
+			return "", fmt.Errorf("%s: the property has no type", path)
+		}
+		i.imported.Warnings = append(i.imported.Warnings, fmt.Sprintf(
+			"%s is a free-form object, it was imported as a map[string]string", path))
+		return "map[string]string", nil
+	default:
+		return "", fmt.Errorf("%s: unsupported type %q", path, s.Type)
+	}
+}
+
+// additionalProperties returns the schema of the values of a map, or nil if s doesn't declare one
+func (s *jsonSchema) additionalProperties() (*jsonSchema, error) {
+	if len(s.AdditionalProperties) == 0 || string(s.AdditionalProperties) == "true" ||
+		string(s.AdditionalProperties) == "false" {
+		return nil, nil
+	}
+	values := &jsonSchema{}
+	if err := json.Unmarshal(s.AdditionalProperties, values); err != nil {
+		return nil, fmt.Errorf("invalid additionalProperties: %v", err)
+	}
+	return values, nil
+}
+
+// declare declares the struct or the enum of the schema s named name and returns its name
+func (i *schemaImporter) declare(name string, s *jsonSchema, path string) (string, error) {
+	if i.declared[name] {
+		return name, nil
+	}
+	if i.reserved[name] {
+		return "", fmt.Errorf("%s: type %s conflicts with the scaffolded types", path, name)
+	}
+	i.declared[name] = true
+
+	// reserve the place of the declaration so that it precedes the declarations of the types it uses
+	index := len(i.imported.Declarations)
+	i.imported.Declarations = append(i.imported.Declarations, "")
+
+	var b strings.Builder
+	if isObject(s) {
+		b.WriteString(fmt.Sprintf("// %s is the schema of %s\n", name, path))
+	} else {
+		b.WriteString(fmt.Sprintf("// %s is a value of %s\n", name, path))
+	}
+
+	if isObject(s) {
+		fields, err := i.fields(name, s, path)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(fmt.Sprintf("type %s struct {\n%s\n}", name, strings.Join(fields, "\n\n")))
+		i.imported.Declarations[index] = b.String()
+		return name, nil
+	}
+
+	values := make([]string, 0, len(s.Enum))
+	for _, v := range s.Enum {
+		value, ok := v.(string)
+		if !ok {
+			return "", fmt.Errorf("%s: the value %v of the enum is not a string", path, v)
+		}
+		values = append(values, value)
+	}
+	b.WriteString(fmt.Sprintf("// +kubebuilder:validation:Enum=%s\ntype %s string", strings.Join(values, ";"), name))
+
+	var constants []string
+	seen := map[string]bool{}
+	for _, value := range values {
+		constant := name + exportedName(value)
+		if constant == name || seen[constant] {
+			i.imported.Warnings = append(i.imported.Warnings, fmt.Sprintf(
+				"the values of %s can't be named, no constants were declared for them", path))
+			constants = nil
+			break
+		}
+		seen[constant] = true
+		constants = append(constants, fmt.Sprintf("// %s is the %s value of %s\n%s %s = %q",
+			constant, value, path, constant, name, value))
+	}
+	if len(constants) > 0 {
+		b.WriteString(fmt.Sprintf("\n\nconst (\n%s\n)", strings.Join(constants, "\n\n")))
+	}
+	i.imported.Declarations[index] = b.String()
+	return name, nil
+}
+
+// markers returns the validation markers of the schema s of a field of type goType
+func (i *schemaImporter) markers(s *jsonSchema, goType, path string) []string {
+	var markers []string
+	if len(s.Enum) > 0 && !i.declared[goType] {
+		values := make([]string, 0, len(s.Enum))
+		for _, v := range s.Enum {
+			values = append(values, fmt.Sprint(v))
+		}
+		markers = append(markers, "+kubebuilder:validation:Enum="+strings.Join(values, ";"))
+	}
+	if s.Minimum != nil {
+		markers = append(markers, "+kubebuilder:validation:Minimum="+formatNumber(*s.Minimum))
+	}
+	if s.Maximum != nil {
+		markers = append(markers, "+kubebuilder:validation:Maximum="+formatNumber(*s.Maximum))
+	}
+	switch v := s.ExclusiveMinimum.(type) {
+	case bool:
+		if v {
+			markers = append(markers, "+kubebuilder:validation:ExclusiveMinimum=true")
+		}
+	case float64:
+		markers = append(markers, "+kubebuilder:validation:Minimum="+formatNumber(v),
+			"+kubebuilder:validation:ExclusiveMinimum=true")
+	}
+	switch v := s.ExclusiveMaximum.(type) {
+	case bool:
+		if v {
+			markers = append(markers, "+kubebuilder:validation:ExclusiveMaximum=true")
+		}
+	case float64:
+		markers = append(markers, "+kubebuilder:validation:Maximum="+formatNumber(v),
+			"+kubebuilder:validation:ExclusiveMaximum=true")
+	}
+	if s.MinLength != nil {
+		markers = append(markers, fmt.Sprintf("+kubebuilder:validation:MinLength=%d", *s.MinLength))
+	}
+	if s.MaxLength != nil {
+		markers = append(markers, fmt.Sprintf("+kubebuilder:validation:MaxLength=%d", *s.MaxLength))
+	}
+	if s.Pattern != "" {
+		markers = append(markers, "+kubebuilder:validation:Pattern=`"+s.Pattern+"`")
+	}
+	if s.MinItems != nil {
+		markers = append(markers, fmt.Sprintf("+kubebuilder:validation:MinItems=%d", *s.MinItems))
+	}
+	if s.MaxItems != nil {
+		markers = append(markers, fmt.Sprintf("+kubebuilder:validation:MaxItems=%d", *s.MaxItems))
+	}
+	switch s.Format {
+	case "", "date-time", "int32", "int64":
+		// implied by the Go type
+	default:
+		markers = append(markers, "+kubebuilder:validation:Format="+s.Format)
+	}
+	if s.Nullable {
+		markers = append(markers, "+nullable")
+	}
+	if s.Default != nil && path != "" {
+		i.imported.Warnings = append(i.imported.Warnings, fmt.Sprintf(
+			"the default of %s was not imported, set it in the defaulting webhook", path))
+	}
+	return markers
+}
+
+// isObject returns true if s is the schema of an object with properties
+func isObject(s *jsonSchema) bool {
+	return len(s.Properties) > 0 && (s.Type == "object" || s.Type == "")
+}
+
+// exportedName returns the exported Go name of a property or a value, e.g. max-replicas is MaxReplicas
+func exportedName(name string) string {
+	var b strings.Builder
+	for _, word := range nonAlphanumericRe.Split(name, -1) {
+		if word != "" {
+			b.WriteString(goName(word))
+		}
+	}
+	exported := b.String()
+	if exported != "" && exported[0] >= '0' && exported[0] <= '9' {
+		return ""
+	}
+	return exported
+}
+
+// writeDescription writes the description of a schema as a comment
+func writeDescription(b *strings.Builder, description string) {
+	for _, line := range strings.Split(strings.TrimSpace(description), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			b.WriteString("// " + line + "\n")
+		}
+	}
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const importedSchemaSource = `{
+  "type": "object",
+  "required": ["class"],
+  "properties": {
+    "class": {"type": "string", "description": "Class of the frigate", "enum": ["sloop", "man-of-war"]},
+    "crewSize": {"type": "integer", "format": "int32", "minimum": 1},
+    "engine": {"$ref": "#/definitions/Engine"},
+    "ports": {"type": "array", "items": {"type": "object", "properties": {"number": {"type": "integer"}}}},
+    "budget": {"x-kubernetes-int-or-string": true, "default": 3}
+  },
+  "definitions": {
+    "Engine": {"type": "object", "properties": {"power": {"type": "integer", "exclusiveMinimum": 0}}}
+  }
+}`
+
+func writeSchema(t *testing.T, source string) (string, func()) {
+	dir, err := ioutil.TempDir("", "kubebuilder-schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "frigate.schema.json")
+	if err := ioutil.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+func TestImportSchema(t *testing.T) {
+	path, cleanup := writeSchema(t, importedSchemaSource)
+	defer cleanup()
+
+	imported, err := ImportSchema(path, "Frigate")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := &ImportedTypes{
+		Source: path,
+		Fields: []string{
+			"// Class of the frigate\n// +kubebuilder:validation:Required\nClass FrigateClass `json:\"class\"`",
+			"// +kubebuilder:validation:Minimum=1\n// +optional\nCrewSize int32 `json:\"crewSize,omitempty\"`",
+			"// +optional\nEngine *Engine `json:\"engine,omitempty\"`",
+			"// +optional\nPorts []FrigatePort `json:\"ports,omitempty\"`",
+			"// +optional\nBudget intstr.IntOrString `json:\"budget,omitempty\"`",
+		},
+		Declarations: []string{
+			"// FrigateClass is a value of spec.class\n// +kubebuilder:validation:Enum=sloop;man-of-war\n" +
+				"type FrigateClass string\n\nconst (\n" +
+				"// FrigateClassSloop is the sloop value of spec.class\nFrigateClassSloop FrigateClass = \"sloop\"\n\n" +
+				"// FrigateClassManOfWar is the man-of-war value of spec.class\n" +
+				"FrigateClassManOfWar FrigateClass = \"man-of-war\"\n)",
+			"// Engine is the schema of #/definitions/Engine\ntype Engine struct {\n" +
+				"// +kubebuilder:validation:Minimum=0\n// +kubebuilder:validation:ExclusiveMinimum=true\n// +optional\n" +
+				"Power int64 `json:\"power,omitempty\"`\n}",
+			"// FrigatePort is the schema of spec.ports[]\ntype FrigatePort struct {\n" +
+				"// +optional\nNumber int64 `json:\"number,omitempty\"`\n}",
+		},
+		Imports:  []string{`"k8s.io/apimachinery/pkg/util/intstr"`},
+		Warnings: []string{"the default of spec.budget was not imported, set it in the defaulting webhook"},
+	}
+	if !reflect.DeepEqual(imported, expected) {
+		t.Errorf("expected %#v, got %#v", expected, imported)
+	}
+}
+
+func TestImportSchemaSpecLocation(t *testing.T) {
+	for name, source := range map[string]string{
+		"spec":       `{"type": "object", "properties": {"name": {"type": "string"}}}`,
+		"object":     `{"properties": {"spec": {"type": "object", "properties": {"name": {"type": "string"}}}}}`,
+		"definition": `{"definitions": {"FrigateSpec": {"type": "object", "properties": {"name": {"type": "string"}}}}}`,
+		"openapi": `{"components": {"schemas": {"Frigate": {"properties": {"spec": {"$ref": "#/components/schemas/Spec"}}},
+			"Spec": {"type": "object", "properties": {"name": {"type": "string"}}}}}}`,
+	} {
+		path, cleanup := writeSchema(t, source)
+		imported, err := ImportSchema(path, "Frigate")
+		cleanup()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+			continue
+		}
+		expected := []string{"// +optional\nName string `json:\"name,omitempty\"`"}
+		if !reflect.DeepEqual(imported.Fields, expected) {
+			t.Errorf("%s: expected fields %q, got %q", name, expected, imported.Fields)
+		}
+	}
+}
+
+func TestImportSchemaErrors(t *testing.T) {
+	for name, source := range map[string]string{
+		"not json":   `type: object`,
+		"no spec":    `{"type": "string"}`,
+		"oneOf":      `{"properties": {"source": {"oneOf": [{"type": "string"}, {"type": "integer"}]}}}`,
+		"remote ref": `{"properties": {"engine": {"$ref": "engine.json"}}}`,
+		"conflict": `{"properties": {"status": {"$ref": "#/definitions/FrigateStatus"}},
+			"definitions": {"FrigateStatus": {"properties": {"ready": {"type": "boolean"}}}}}`,
+		"no items":   `{"properties": {"ports": {"type": "array"}}}`,
+		"mixed enum": `{"properties": {"class": {"type": "string", "enum": ["sloop", 1]}}}`,
+	} {
+		path, cleanup := writeSchema(t, source)
+		if _, err := ImportSchema(path, "Frigate"); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+		cleanup()
+	}
+}
+
+func TestExportedName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"name": "Name", "crewSize": "CrewSize", "max-replicas": "MaxReplicas", "url": "URL", "man-of-war": "ManOfWar",
+		"3d": "",
+	} {
+		if actual := exportedName(name); actual != expected {
+			t.Errorf("expected the exported name of %s to be %s, got %s", name, expected, actual)
+		}
+	}
+}
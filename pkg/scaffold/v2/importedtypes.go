@@ -0,0 +1,242 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ImportedTypes contains the declarations of an existing Go file lifted into the types of a resource
+type ImportedTypes struct {
+	// Source is the path of the file the declarations were imported from
+	Source string
+
+	// Fields are the fields of the spec, with json tags and markers
+	Fields []string
+
+	// Declarations are the other type and constant declarations of the file
+	Declarations []string
+
+	// Imports are the import specs used by the fields and declarations
+	Imports []string
+
+	// Warnings describe the parts of the file that could not be imported
+	Warnings []string
+}
+
+// ImportTypes parses the Go file at path and imports the fields of the struct named <kind>Spec, <kind> or,
+// if there is no such struct, of the only struct declared in the file
+func ImportTypes(path, kind string) (*ImportedTypes, error) {
+	src, err := ioutil.ReadFile(path) // nolint: gosec
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", path, err)
+	}
+
+	spec, err := findSpecStruct(file, kind)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	text := func(node ast.Node) string {
+		return string(src[fset.Position(node.Pos()).Offset:fset.Position(node.End()).Offset])
+	}
+
+	imported := &ImportedTypes{Source: path}
+	used := map[string]bool{}
+	reserved := map[string]bool{kind: true, kind + "Spec": true, kind + "Status": true, kind + "List": true}
+
+	for _, field := range spec.Type.(*ast.StructType).Fields.List {
+		if len(field.Names) > 0 && !field.Names[0].IsExported() {
+			imported.Warnings = append(imported.Warnings,
+				fmt.Sprintf("unexported field %s was not imported", field.Names[0].Name))
+			continue
+		}
+		imported.Fields = append(imported.Fields, importField(field, text))
+		collectPackages(field.Type, used)
+	}
+
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			switch decl.Tok {
+			case token.CONST:
+				imported.Declarations = append(imported.Declarations, withDoc(decl.Doc, text(decl), text))
+				collectPackages(decl, used)
+			case token.TYPE:
+				for _, s := range decl.Specs {
+					t := s.(*ast.TypeSpec)
+					if t == spec {
+						continue
+					}
+					if reserved[t.Name.Name] {
+						return nil, fmt.Errorf("%s: type %s conflicts with the scaffolded types", path, t.Name.Name)
+					}
+					doc := t.Doc
+					if !decl.Lparen.IsValid() {
+						doc = decl.Doc
+					}
+					imported.Declarations = append(imported.Declarations, withDoc(doc, "type "+text(t), text))
+					collectPackages(t, used)
+				}
+			}
+		case *ast.FuncDecl:
+			imported.Warnings = append(imported.Warnings,
+				fmt.Sprintf("function %s was not imported", decl.Name.Name))
+		}
+	}
+
+	for _, i := range file.Imports {
+		importPath, _ := strconv.Unquote(i.Path.Value)
+		name := importPath[strings.LastIndex(importPath, "/")+1:]
+		if i.Name != nil {
+			name = i.Name.Name
+		}
+		// metav1 is already imported by the scaffolded types
+		if used[name] && text(i) != `metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"` {
+			imported.Imports = append(imported.Imports, text(i))
+		}
+	}
+	sort.Strings(imported.Imports)
+
+	return imported, nil
+}
+
+// findSpecStruct returns the type spec of the struct whose fields are imported
+func findSpecStruct(file *ast.File, kind string) (*ast.TypeSpec, error) {
+	structs := map[string]*ast.TypeSpec{}
+	var names []string
+	for _, decl := range file.Decls {
+		if decl, ok := decl.(*ast.GenDecl); ok && decl.Tok == token.TYPE {
+			for _, s := range decl.Specs {
+				if t := s.(*ast.TypeSpec); isStruct(t) {
+					structs[t.Name.Name] = t
+					names = append(names, t.Name.Name)
+				}
+			}
+		}
+	}
+
+	for _, name := range []string{kind + "Spec", kind} {
+		if t, found := structs[name]; found {
+			return t, nil
+		}
+	}
+	if len(names) == 1 {
+		return structs[names[0]], nil
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no struct found")
+	}
+	return nil, fmt.Errorf("found structs %s, name the one to import %sSpec or %s",
+		strings.Join(names, ", "), kind, kind)
+}
+
+// withDoc returns source preceded by its doc comment
+func withDoc(doc *ast.CommentGroup, source string, text func(ast.Node) string) string {
+	if doc == nil {
+		return source
+	}
+	return text(doc) + "\n" + source
+}
+
+func isStruct(t *ast.TypeSpec) bool {
+	_, ok := t.Type.(*ast.StructType)
+	return ok
+}
+
+// importField returns the source of field, adding a json tag and the optional marker if missing
+func importField(field *ast.Field, text func(ast.Node) string) string {
+	var tag reflect.StructTag
+	if field.Tag != nil {
+		value, _ := strconv.Unquote(field.Tag.Value)
+		tag = reflect.StructTag(value)
+	}
+
+	jsonTag, hasJSONTag := tag.Lookup("json")
+	if !hasJSONTag {
+		if len(field.Names) == 0 {
+			jsonTag = ",inline"
+		} else {
+			jsonTag = jsonName(field.Names[0].Name) + ",omitempty"
+		}
+		tag = reflect.StructTag(strings.TrimSpace(fmt.Sprintf(`json:"%s" %s`, jsonTag, tag)))
+	}
+
+	var b strings.Builder
+	doc := ""
+	if field.Doc != nil {
+		doc = text(field.Doc)
+		b.WriteString(doc + "\n")
+	}
+	if strings.HasSuffix(jsonTag, ",omitempty") && !strings.Contains(doc, "+optional") {
+		b.WriteString("// +optional\n")
+	}
+
+	names := make([]string, 0, len(field.Names))
+	for _, name := range field.Names {
+		names = append(names, name.Name)
+	}
+	if len(names) > 0 {
+		b.WriteString(strings.Join(names, ", ") + " ")
+	}
+	b.WriteString(text(field.Type))
+	b.WriteString(" `" + string(tag) + "`")
+	if field.Comment != nil {
+		b.WriteString(" " + text(field.Comment))
+	}
+	return b.String()
+}
+
+// jsonName returns the lower camel case json name of a field, e.g. HTTPPort is httpPort
+func jsonName(name string) string {
+	runes := []rune(name)
+	for i := 0; i < len(runes) && unicode.IsUpper(runes[i]); i++ {
+		// keep the last upper case letter of an acronym followed by a lower case one, e.g. the P of HTTPPort
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
+
+// collectPackages records the names of the packages referenced by node
+func collectPackages(node ast.Node, used map[string]bool) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const importedTypesSource = `package fleet
+
+import (
+	"net/url"
+	"time"
+)
+
+// Class is the class of a ship
+type Class string
+
+// FrigateSpec is imported
+type FrigateSpec struct {
+	// Name of the frigate
+	Name     string
+	HTTPPort int32 ` + "`" + `yaml:"port"` + "`" + `
+	Class    Class ` + "`" + `json:"class"` + "`" + `
+	Delay    *time.Duration
+	secret   string
+}
+
+func (s FrigateSpec) Hello() {}
+`
+
+func TestImportTypes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubebuilder-types")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "frigate.go")
+	if err := ioutil.WriteFile(path, []byte(importedTypesSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := ImportTypes(path, "Frigate")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := &ImportedTypes{
+		Source: path,
+		Fields: []string{
+			"// Name of the frigate\n// +optional\nName string `json:\"name,omitempty\"`",
+			"// +optional\nHTTPPort int32 `json:\"httpPort,omitempty\" yaml:\"port\"`",
+			"Class Class `json:\"class\"`",
+			"// +optional\nDelay *time.Duration `json:\"delay,omitempty\"`",
+		},
+		Declarations: []string{"// Class is the class of a ship\ntype Class string"},
+		Imports:      []string{`"time"`},
+		Warnings:     []string{"unexported field secret was not imported", "function Hello was not imported"},
+	}
+	if !reflect.DeepEqual(imported, expected) {
+		t.Errorf("expected %#v, got %#v", expected, imported)
+	}
+
+	if _, err := ImportTypes(path, "Ship"); err != nil {
+		t.Errorf("expected the only struct of the file to be imported, got: %v", err)
+	}
+}
+
+func TestImportTypesConflicts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kubebuilder-types")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "frigate.go")
+	source := "package fleet\n\ntype FrigateSpec struct{}\n\ntype FrigateStatus struct{}\n"
+	if err := ioutil.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ImportTypes(path, "Frigate"); err == nil {
+		t.Errorf("expected an error for types conflicting with the scaffolded ones")
+	}
+}
+
+func TestJSONName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"Name": "name", "HTTPPort": "httpPort", "URL": "url", "ID": "id", "MaxURLLength": "maxURLLength",
+	} {
+		if actual := jsonName(name); actual != expected {
+			t.Errorf("expected the json name of %s to be %s, got %s", name, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+)
+
+// AddToKustomization appends entry to the list of the field, e.g. resources, of the kustomization at path unless it
+// is already listed. The field is added at the end of the kustomization if it doesn't have it yet.
+func AddToKustomization(path, field, entry string) error {
+	in, err := filesystem.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	content, item := string(in), "- "+entry+"\n"
+	if regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(item)).MatchString(content) {
+		return nil
+	}
+	if loc := regexp.MustCompile(`(?m)^` + regexp.QuoteMeta(field) + `:[ \t]*\n`).FindStringIndex(content); loc != nil {
+		end := listEnd(content, loc[1])
+		content = content[:end] + item + content[end:]
+	} else {
+		if !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		content += fmt.Sprintf("\n%s:\n%s", field, item)
+	}
+	return filesystem.WriteFile(path, []byte(content), 0644)
+}
+
+// listEnd returns the offset following the last item of the list starting at offset start of content, including
+// its nested lines, skipping the comments
+func listEnd(content string, start int) int {
+	end, inItem := start, false
+	for offset := start; offset < len(content); {
+		line := content[offset:]
+		if i := strings.IndexByte(line, '\n'); i >= 0 {
+			line = line[:i+1]
+		}
+		offset += len(line)
+		switch {
+		case strings.HasPrefix(line, "-"):
+			end, inItem = offset, true
+		case strings.HasPrefix(line, " ") && strings.TrimSpace(line) != "":
+			// the nested lines of an item, e.g. the objref of a var
+			if inItem && !strings.HasPrefix(strings.TrimSpace(line), "#") {
+				end = offset
+			}
+		case strings.HasPrefix(line, "#"), strings.TrimSpace(line) == "":
+			inItem = false
+		default:
+			return end
+		}
+	}
+	return end
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAddToKustomization(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "existing field",
+			input:    "resources:\n- manager.yaml\n",
+			expected: "resources:\n- manager.yaml\n- operator_config.yaml\n",
+		},
+		{
+			name:     "list followed by comments and fields",
+			input:    "resources:\n- manager.yaml\n# - metrics.yaml\n\npatchesStrategicMerge:\n- manager_patch.yaml\n",
+			expected: "resources:\n- manager.yaml\n- operator_config.yaml\n# - metrics.yaml\n\npatchesStrategicMerge:\n- manager_patch.yaml\n",
+		},
+		{
+			name:     "already listed",
+			input:    "resources:\n- manager.yaml\n- operator_config.yaml\n",
+			expected: "resources:\n- manager.yaml\n- operator_config.yaml\n",
+		},
+		{
+			name:     "items with nested lines",
+			input:    "resources:\n- path: manager.yaml\n  target: manager\n# - metrics.yaml\n",
+			expected: "resources:\n- path: manager.yaml\n  target: manager\n- operator_config.yaml\n# - metrics.yaml\n",
+		},
+		{
+			name:     "missing field",
+			input:    "patchesStrategicMerge:\n- manager_patch.yaml",
+			expected: "patchesStrategicMerge:\n- manager_patch.yaml\n\nresources:\n- operator_config.yaml\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "kustomization")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			path := filepath.Join(dir, "kustomization.yaml")
+			if err := ioutil.WriteFile(path, []byte(test.input), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := AddToKustomization(path, "resources", "operator_config.yaml"); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(b) != test.expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", test.expected, b)
+			}
+		})
+	}
+}
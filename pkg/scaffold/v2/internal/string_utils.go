@@ -25,6 +25,8 @@ import (
 	"strings"
 
 	"golang.org/x/tools/imports"
+
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
 )
 
 // insertStrings reads content from given reader and insert string below the
@@ -75,7 +77,7 @@ func InsertStringsInFile(path string, markerAndValues map[string][]string) error
 		isGoFile = true
 	}
 
-	f, err := os.Open(path)
+	f, err := filesystem.Open(path)
 	if err != nil {
 		return err
 	}
@@ -104,7 +106,7 @@ func InsertStringsInFile(path string, markerAndValues map[string][]string) error
 	}
 
 	// use Go import process to format the content
-	err = ioutil.WriteFile(path, formattedContent, os.ModePerm)
+	err = filesystem.WriteFile(path, formattedContent, os.ModePerm)
 	if err != nil {
 		return err
 	}
@@ -17,11 +17,12 @@ limitations under the License.
 package v2
 
 import (
-	"os"
 	"path/filepath"
 	"strings"
 
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
 )
 
 var _ input.File = &Kustomize{}
@@ -32,6 +33,24 @@ type Kustomize struct {
 
 	// Prefix to use for name prefix customization
 	Prefix string
+
+	// Expose is the kind of exposure of the metrics endpoint, none if empty
+	Expose string
+
+	// Quota deploys the LimitRange and the ResourceQuota of the namespace along with the manager
+	Quota bool
+
+	// SecretStore deploys the description of the credentials of the manager in the external secret store and
+	// mounts them into the manager
+	SecretStore bool
+
+	// TLSMetrics indicates that the manager serves its metrics over TLS with the certificate of config/metrics,
+	// rather than behind kube-rbac-proxy
+	TLSMetrics bool
+
+	// CRDBase, RBACBase and WebhookBase are the slash separated paths of the manifest directories
+	// relative to the overlay
+	CRDBase, RBACBase, WebhookBase string
 }
 
 // GetInput implements input.File
@@ -41,17 +60,64 @@ func (f *Kustomize) GetInput() (input.Input, error) {
 	}
 	if f.Prefix == "" {
 		// use directory name as prefix
-		dir, err := os.Getwd()
+		prefix, err := util.ProjectName(f.ProjectPath)
+		if err != nil {
+			return input.Input{}, err
+		}
+		f.Prefix = prefix
+	}
+	for base, dir := range map[*string]string{&f.CRDBase: f.CRDDir, &f.RBACBase: f.RBACDir, &f.WebhookBase: f.WebhookDir} {
+		rel, err := filepath.Rel(filepath.Dir(f.Path), dir)
 		if err != nil {
 			return input.Input{}, err
 		}
-		f.Prefix = strings.ToLower(filepath.Base(dir))
+		*base = filepath.ToSlash(rel)
 	}
 	f.TemplateBody = kustomizeTemplate
 	f.Input.IfExistsAction = input.Error
 	return f.Input, nil
 }
 
+// EnableWebhooks uncomments the [WEBHOOK] sections of the kustomization, deploying the webhook server along with
+// the manager, and the [CERTMANAGER] ones if certManager is set, provisioning its serving certificate with
+// cert-manager and injecting its CA into the webhook configurations. The sections already uncommented are kept.
+func (f *Kustomize) EnableWebhooks(certManager bool) error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "default", "kustomization.yaml")
+	}
+	path := filepath.Join(f.ProjectPath, f.Path)
+	in, err := filesystem.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	sections := []string{"# [WEBHOOK]"}
+	if certManager {
+		sections = append(sections, "# [CERTMANAGER]")
+	}
+	lines := strings.Split(string(in), "\n")
+	enabled := false
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "# ["):
+			enabled = false
+			for _, section := range sections {
+				enabled = enabled || strings.HasPrefix(line, section)
+			}
+		case !strings.HasPrefix(line, "#"):
+			// a section ends with its commented lines
+			enabled = false
+		case enabled && (strings.HasPrefix(line, "#- ") || strings.HasPrefix(line, "#  ")):
+			lines[i] = strings.TrimPrefix(line, "#")
+		}
+	}
+
+	if content := strings.Join(lines, "\n"); content != string(in) {
+		return filesystem.WriteFile(path, []byte(content), 0644)
+	}
+	return nil
+}
+
 const kustomizeTemplate = `# Adds namespace to all resources.
 namespace: {{.Prefix}}-system
 
@@ -67,22 +133,52 @@ namePrefix: {{.Prefix}}-
 #  someName: someValue
 
 bases:
-- ../crd
-- ../rbac
+- {{ .CRDBase }}
+- {{ .RBACBase }}
 - ../manager
+{{- if .TLSMetrics }}
+# The certificate of the metrics endpoint, issued by cert-manager which must be installed in the cluster.
+- ../metrics
+{{- end }}
+{{- if .Quota }}
+# The LimitRange and the ResourceQuota of the namespace, required by some cluster policies.
+- ../quota
+{{- end }}
+{{- if .SecretStore }}
+# The credentials of the manager in the external secret store.
+- ../secretstore
+{{- end }}
 # [WEBHOOK] To enable webhook, uncomment all the sections with [WEBHOOK] prefix including the one in 
 # crd/kustomization.yaml
-#- ../webhook
+#- {{ .WebhookBase }}
 # [CERTMANAGER] To enable cert-manager, uncomment all sections with 'CERTMANAGER'. 'WEBHOOK' components are required.
 #- ../certmanager
 # [PROMETHEUS] To enable prometheus monitor, uncomment all sections with 'PROMETHEUS'. 
 #- ../prometheus
+# [OBSERVABILITY] To deploy the Grafana dashboard of the controllers along with the prometheus monitor,
+# uncomment the following line instead of the 'PROMETHEUS' one. The dashboard is generated by 'create api'.
+#- ../observability
+{{- if .Expose }}
+# [EXPOSE] To expose the metrics endpoint out of the cluster, uncomment the following line.
+#- ../expose
+{{- end }}
 
 patchesStrategicMerge:
+{{- if .TLSMetrics }}
+  # Serve the /metrics endpoint over TLS to the clients authorized to get it,
+  # with the certificate of ../metrics.
+- manager_metrics_patch.yaml
+{{- else }}
   # Protect the /metrics endpoint by putting it behind auth.
   # If you want your controller-manager to expose the /metrics
   # endpoint w/o any authn/z, please comment the following line.
 - manager_auth_proxy_patch.yaml
+{{- end }}
+{{- if .SecretStore }}
+
+# Mounts the credentials of the manager from the external secret store.
+- manager_credentials_patch.yaml
+{{- end }}
 
 # [WEBHOOK] To enable webhook, uncomment all the sections with [WEBHOOK] prefix including the one in 
 # crd/kustomization.yaml
@@ -92,9 +188,31 @@ patchesStrategicMerge:
 # Uncomment 'CERTMANAGER' sections in crd/kustomization.yaml to enable the CA injection in the admission webhooks.
 # 'CERTMANAGER' needs to be enabled to use ca injection
 #- webhookcainjection_patch.yaml
+{{- if .Expose }}
+
+# [EXPOSE] To develop the webhooks out of the cluster through a tunnel, uncomment the following line.
+# 'WEBHOOK' components are required.
+#- webhook_tunnel_patch.yaml
+{{- end }}
 
 # the following config is for teaching kustomize how to do var substitution
 vars:
+{{- if .TLSMetrics }}
+# The name and the namespace of the metrics service, in the DNS names of the certificate of the metrics endpoint
+# and in the server name verified by the prometheus monitor.
+- name: METRICS_SERVICE_NAMESPACE
+  objref:
+    kind: Service
+    version: v1
+    name: controller-manager-metrics-service
+  fieldref:
+    fieldpath: metadata.namespace
+- name: METRICS_SERVICE_NAME
+  objref:
+    kind: Service
+    version: v1
+    name: controller-manager-metrics-service
+{{- end }}
 # [CERTMANAGER] To enable cert-manager, uncomment all sections with 'CERTMANAGER' prefix.
 #- name: CERTIFICATE_NAMESPACE # namespace of the certificate CR
 #  objref:
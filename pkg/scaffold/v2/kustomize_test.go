@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+func TestKustomizeEnableWebhooks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kustomize")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var out bytes.Buffer
+	tmpl := template.Must(template.New("kustomize").Parse(kustomizeTemplate))
+	if err := tmpl.Execute(&out, &Kustomize{Prefix: "project", CRDBase: "../crd", RBACBase: "../rbac",
+		WebhookBase: "../webhook"}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "config", "default", "kustomization.yaml")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, out.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func() string {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	kustomize := &Kustomize{Input: input.Input{ProjectPath: dir}}
+
+	// the webhook server of a self-signed certificate is deployed without cert-manager
+	if err := kustomize.EnableWebhooks(false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content := read()
+	for _, line := range []string{"\n- ../webhook\n", "\n- manager_webhook_patch.yaml\n", "\n#- ../certmanager\n",
+		"\n#- webhookcainjection_patch.yaml\n", "\n#- name: CERTIFICATE_NAMESPACE", "\n#- ../prometheus\n"} {
+		if !strings.Contains(content, line) {
+			t.Errorf("expected %q in the kustomization:\n%s", line, content)
+		}
+	}
+
+	if err := kustomize.EnableWebhooks(true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content = read()
+	for _, line := range []string{"\n- ../webhook\n", "\n- manager_webhook_patch.yaml\n", "\n- ../certmanager\n",
+		"\n- webhookcainjection_patch.yaml\n", "\n- name: CERTIFICATE_NAMESPACE", "\n  objref:\n",
+		"\n    name: webhook-service\n", "\n#- ../prometheus\n"} {
+		if !strings.Contains(content, line) {
+			t.Errorf("expected %q in the kustomization:\n%s", line, content)
+		}
+	}
+
+	// the enabled sections are kept as they are
+	if err := kustomize.EnableWebhooks(true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again := read(); again != content {
+		t.Errorf("expected the kustomization to be kept:\n%s\ngot:\n%s", content, again)
+	}
+}
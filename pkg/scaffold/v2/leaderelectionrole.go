@@ -32,7 +32,7 @@ type LeaderElectionRole struct {
 // GetInput implements input.File
 func (f *LeaderElectionRole) GetInput() (input.Input, error) {
 	if f.Path == "" {
-		f.Path = filepath.Join("config", "rbac", "leader_election_role.yaml")
+		f.Path = filepath.Join(f.RBACDir, "leader_election_role.yaml")
 	}
 	f.TemplateBody = leaderElectionRoleTemplate
 	return f.Input, nil
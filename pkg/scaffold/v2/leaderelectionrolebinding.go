@@ -32,7 +32,7 @@ type LeaderElectionRoleBinding struct {
 // GetInput implements input.File
 func (f *LeaderElectionRoleBinding) GetInput() (input.Input, error) {
 	if f.Path == "" {
-		f.Path = filepath.Join("config", "rbac", "leader_election_role_binding.yaml")
+		f.Path = filepath.Join(f.RBACDir, "leader_election_role_binding.yaml")
 	}
 	f.TemplateBody = leaderElectionRoleBindingTemplate
 	return f.Input, nil
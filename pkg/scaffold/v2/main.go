@@ -19,7 +19,9 @@ package v2
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
 	"sigs.k8s.io/kubebuilder/pkg/model/config"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
@@ -38,6 +40,21 @@ var _ input.File = &Main{}
 // Main scaffolds a main.go to run Controllers
 type Main struct {
 	input.Input
+
+	// Tenancy indicates that the tenants are isolated by namespace, the manager may cache the objects of the
+	// namespaces of the tenants only
+	Tenancy bool
+
+	// Wire indicates that the reconcilers and the webhooks are injected by google/wire, see Providers
+	Wire bool
+
+	// Credentials indicates that the credentials of the manager are loaded from the files the secret store mounts,
+	// see the secretstore package
+	Credentials bool
+
+	// TLSMetrics indicates that the manager serves its metrics over TLS to the authorized clients, see the
+	// metricsauth package, rather than behind kube-rbac-proxy
+	TLSMetrics bool
 }
 
 // GetInput implements input.File
@@ -52,9 +69,86 @@ func (f *Main) GetInput() (input.Input, error) {
 // Update updates main.go with code fragments required to wire a new
 // resource/controller.
 func (f *Main) Update(opts *MainUpdateOptions) error {
-	path := "main.go"
+	path := filepath.Join(opts.OutputDir, "main.go")
+
+	// the sidecar injector is a webhook for pods, it isn't related to any resource of the project
+	if opts.WireSidecarInjector {
+		return internal.InsertStringsInFile(path,
+			map[string][]string{
+				APIPkgImportScaffoldMarker: {fmt.Sprintf(`"%s/sidecar"
+`, opts.Config.Repo)},
+				ReconcilerSetupScaffoldMarker: {`sidecar.SetupWebhookWithManager(mgr)
+`},
+			})
+	}
 
-	resPkg, _ := util.GetResourceInfo(opts.Resource, opts.Config.Repo, opts.Config.Domain, opts.Config.MultiGroup)
+	// the storage version migrator migrates every kind listed in its package, it is only set up once
+	if opts.WireStorageMigrator {
+		content, err := filesystem.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if strings.Contains(string(content), "storagemigration.Migrator{") {
+			return nil
+		}
+		return internal.InsertStringsInFile(path,
+			map[string][]string{
+				APIPkgImportScaffoldMarker: {fmt.Sprintf(`"%s/storagemigration"
+`, opts.Config.Repo)},
+				ReconcilerSetupScaffoldMarker: {`if err = (&storagemigration.Migrator{Resources: storagemigration.Resources}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to set up the storage version migration")
+		os.Exit(1)
+	}
+`},
+			})
+	}
+
+	// the conversion webhook server converts every kind whose CRD points to its service, it is only set up once
+	if opts.WireConversionServer {
+		content, err := filesystem.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if strings.Contains(string(content), "conversionserver.SetupWithManager(mgr)") {
+			return nil
+		}
+		return internal.InsertStringsInFile(path,
+			map[string][]string{
+				APIPkgImportScaffoldMarker: {fmt.Sprintf(`"%s/conversionserver"
+`, opts.Config.Repo)},
+				ReconcilerSetupScaffoldMarker: {`if err = conversionserver.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to set up the conversion webhook server")
+		os.Exit(1)
+	}
+`},
+			})
+	}
+
+	// the certificate rotator provisions the serving certificate of the whole webhook server, it is only set up once
+	if opts.WireCertRotator {
+		content, err := filesystem.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if strings.Contains(string(content), "certrotator.SetupWithManager(mgr)") {
+			return nil
+		}
+		return internal.InsertStringsInFile(path,
+			map[string][]string{
+				APIPkgImportScaffoldMarker: {fmt.Sprintf(`"%s/internal/certrotator"
+`, opts.Config.Repo)},
+				ReconcilerSetupScaffoldMarker: {`if err = certrotator.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to set up the rotation of the webhook serving certificate")
+		os.Exit(1)
+	}
+`},
+			})
+	}
+
+	resPkg, _ := util.GetResourceInfo(opts.OutputDir, opts.Resource, opts.Config.Repo, opts.Config.Domain, opts.Config.MultiGroup)
+
+	// the built-in kinds are added to the scheme by clientgoscheme, main.go doesn't import their package
+	builtin := util.IsBuiltin(opts.OutputDir, opts.Resource, opts.Config.MultiGroup)
 
 	// generate all the code fragments
 	apiImportCodeFragment := fmt.Sprintf(`%s%s "%s/%s"
@@ -64,35 +158,126 @@ func (f *Main) Update(opts *MainUpdateOptions) error {
 `, opts.Resource.GroupImportSafe, opts.Resource.Version)
 
 	var reconcilerSetupCodeFragment, ctrlImportCodeFragment string
+	clockImportCodeFragment := `"k8s.io/apimachinery/pkg/util/clock"
+`
+
+	// the heartbeat of the controller is created before it and checked by the manager once it is set up
+	var heartbeatImportCodeFragment, heartbeatCodeFragment, heartbeatArgCodeFragment, heartbeatCheckCodeFragment string
+	if opts.WireHealthCheck {
+		heartbeat, check := strings.ToLower(opts.Resource.Kind[:1])+opts.Resource.Kind[1:]+"Heartbeat",
+			strings.ToLower(opts.Resource.Kind)+"-controller"
+		if opts.Config.MultiGroup {
+			heartbeat, check = opts.Resource.GroupImportSafe+opts.Resource.Kind+"Heartbeat",
+				opts.Resource.Group+"-"+check
+		}
+
+		heartbeatImportCodeFragment = fmt.Sprintf(`"%s/heartbeat"
+`, opts.Config.Repo)
+
+		heartbeatCodeFragment = fmt.Sprintf(`%s := heartbeat.New("%s", heartbeat.DefaultTimeout)
+`, heartbeat, strings.ToLower(opts.Resource.Kind))
+
+		heartbeatArgCodeFragment = fmt.Sprintf(`
+		%s,`, heartbeat)
+
+		heartbeatCheckCodeFragment = fmt.Sprintf(`if err = mgr.AddReadyzCheck("%s", %s.Check); err != nil {
+		setupLog.Error(err, "unable to set up ready check", "check", "%s")
+		os.Exit(1)
+	}
+`, check, heartbeat, check)
+	}
+
+	// the configuration watcher is shared by the controllers, it is only set up by the first one using it
+	var configImportCodeFragment, configCodeFragment, configArgCodeFragment string
+	if opts.WireConfigWatcher {
+		configImportCodeFragment = fmt.Sprintf(`"%s/operatorconfig"
+`, opts.Config.Repo)
+
+		configCodeFragment = `operatorConfig := operatorconfig.New()
+	if err = operatorConfig.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to watch the configuration of the operator")
+		os.Exit(1)
+	}
+`
+
+		configArgCodeFragment = `
+		operatorConfig,`
+	}
+	// the connection to the message bus is shared by the triggers of the controllers, it is closed on shutdown
+	var busImportCodeFragment, busCodeFragment, triggerCodeFragment, triggerArgCodeFragment string
+	if opts.WireMessageBus {
+		trigger, subject := strings.ToLower(opts.Resource.Kind[:1])+opts.Resource.Kind[1:]+"Trigger",
+			opts.Resource.Group+"."+strings.ToLower(opts.Resource.Kind)
+		if opts.Config.MultiGroup {
+			trigger = opts.Resource.GroupImportSafe + opts.Resource.Kind + "Trigger"
+		}
+
+		busImportCodeFragment = fmt.Sprintf(`"%s/messagebus"
+`, opts.Config.Repo)
+
+		busCodeFragment = `bus, err := messagebus.Connect()
+	if err != nil {
+		setupLog.Error(err, "unable to connect to the message bus")
+		os.Exit(1)
+	}
+	defer messagebus.Close(bus)
+`
+
+		triggerCodeFragment = fmt.Sprintf(`%s := messagebus.NewTrigger(bus, "%s", ctrl.Log.WithName("messagebus").WithName("%s"))
+	if err = mgr.Add(%s); err != nil {
+		setupLog.Error(err, "unable to set up the trigger of the message bus", "subject", "%s")
+		os.Exit(1)
+	}
+`, trigger, subject, opts.Resource.Kind, trigger, subject)
+
+		triggerArgCodeFragment = fmt.Sprintf(`
+		%s,`, trigger)
+	}
+	// the reconciles of very large numbers of objects list them in pages from the API server, bypassing the cache
+	var apiReaderArgCodeFragment string
+	if opts.WireAPIReader {
+		apiReaderArgCodeFragment = `
+		mgr.GetAPIReader(),`
+	}
+	// the reconciler is built by its constructor, the optional dependencies follow the common ones
+	argsCodeFragment := heartbeatArgCodeFragment + configArgCodeFragment + triggerArgCodeFragment +
+		apiReaderArgCodeFragment
+	reconciler := opts.Config.Names().ReconcilerName(opts.Resource.Kind)
+	recorder := strings.ToLower(opts.Resource.Kind) + "-controller"
 
 	if opts.Config.MultiGroup {
 
 		ctrlImportCodeFragment = fmt.Sprintf(`controller%s "%s/controllers/%s"
 `, opts.Resource.GroupImportSafe, opts.Config.Repo, opts.Resource.Group)
 
-		reconcilerSetupCodeFragment = fmt.Sprintf(`if err = (&controller%s.%sReconciler{
-		Client: mgr.GetClient(),
-		Log: ctrl.Log.WithName("controllers").WithName("%s"),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+		reconcilerSetupCodeFragment = fmt.Sprintf(`if err = controller%s.New%s(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName("%s"),
+		mgr.GetScheme(),
+		mgr.GetEventRecorderFor("%s"),
+		clock.RealClock{},%s
+	).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "%s")
 		os.Exit(1)
 	}
-`, opts.Resource.GroupImportSafe, opts.Resource.Kind, opts.Resource.Kind, opts.Resource.Kind)
+`, opts.Resource.GroupImportSafe, reconciler, opts.Resource.Kind, recorder, argsCodeFragment,
+			opts.Resource.Kind)
 	} else {
 
 		ctrlImportCodeFragment = fmt.Sprintf(`"%s/controllers"
 `, opts.Config.Repo)
 
-		reconcilerSetupCodeFragment = fmt.Sprintf(`if err = (&controllers.%sReconciler{
-		Client: mgr.GetClient(),
-		Log: ctrl.Log.WithName("controllers").WithName("%s"),
-		Scheme: mgr.GetScheme(),  
-	}).SetupWithManager(mgr); err != nil {
+		reconcilerSetupCodeFragment = fmt.Sprintf(`if err = controllers.New%s(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName("%s"),
+		mgr.GetScheme(),
+		mgr.GetEventRecorderFor("%s"),
+		clock.RealClock{},%s
+	).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "%s")
 		os.Exit(1)
 	}
-`, opts.Resource.Kind, opts.Resource.Kind, opts.Resource.Kind)
+`, reconciler, opts.Resource.Kind, recorder, argsCodeFragment, opts.Resource.Kind)
 
 	}
 
@@ -102,6 +287,45 @@ func (f *Main) Update(opts *MainUpdateOptions) error {
 	}
 `, opts.Resource.GroupImportSafe, opts.Resource.Version, opts.Resource.Kind, opts.Resource.Kind)
 
+	// the webhooks of the built-in kinds are registered by the webhooks package, see BuiltinWebhook
+	webhooksImportCodeFragment := fmt.Sprintf(`"%s/webhooks"
+`, opts.Config.Repo)
+	builtinWebhookSetupCodeFragment := fmt.Sprintf(`webhooks.Setup%sWebhookWithManager(mgr)
+`, opts.Resource.Kind)
+	if opts.Config.MultiGroup {
+		webhooksImportCodeFragment = fmt.Sprintf(`webhooks%s "%s/webhooks/%s"
+`, opts.Resource.GroupImportSafe, opts.Config.Repo, opts.Resource.Group)
+		builtinWebhookSetupCodeFragment = fmt.Sprintf(`webhooks%s.Setup%sWebhookWithManager(mgr)
+`, opts.Resource.GroupImportSafe, opts.Resource.Kind)
+	}
+
+	certReadinessImportCodeFragment := fmt.Sprintf(`"%s/webhookcert"
+`, opts.Config.Repo)
+
+	certReadinessCodeFragment := `if err = mgr.AddReadyzCheck("webhook-cert", webhookcert.ReadinessCheck); err != nil {
+		setupLog.Error(err, "unable to set up ready check", "check", "webhook-cert")
+		os.Exit(1)
+	}
+`
+
+	if opts.WireCertReadiness {
+		// multi-line fragments are not deduplicated by InsertStringsInFile, the check is added by the first webhook
+		content, err := filesystem.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(string(content), `mgr.AddReadyzCheck("webhook-cert"`) {
+			err := internal.InsertStringsInFile(path,
+				map[string][]string{
+					APIPkgImportScaffoldMarker:    {certReadinessImportCodeFragment},
+					ReconcilerSetupScaffoldMarker: {certReadinessCodeFragment},
+				})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
 	if opts.WireResource {
 		err := internal.InsertStringsInFile(path,
 			map[string][]string{
@@ -113,12 +337,80 @@ func (f *Main) Update(opts *MainUpdateOptions) error {
 		}
 	}
 
+	// the kinds whose wiring was removed on purpose are only added to the scheme, see config.Wiring
+	wiring := opts.Config.WiringOf(opts.Resource)
+	if (opts.WireController && wiring.SkipController) || (opts.WireWebhook && wiring.SkipWebhook) {
+		if builtin {
+			return nil
+		}
+		return internal.InsertStringsInFile(path,
+			map[string][]string{
+				APIPkgImportScaffoldMarker: {apiImportCodeFragment},
+				APISchemeScaffoldMarker:    {addschemeCodeFragment},
+			})
+	}
+
+	// the reconcilers and the webhooks are constructed by the providers injected by wire, see Providers
+	if opts.Config.HasWireInjection() && (opts.WireController || opts.WireWebhook) {
+		if !builtin {
+			err := internal.InsertStringsInFile(path,
+				map[string][]string{
+					APIPkgImportScaffoldMarker: {apiImportCodeFragment},
+					APISchemeScaffoldMarker:    {addschemeCodeFragment},
+				})
+			if err != nil {
+				return err
+			}
+		}
+		return updateProviders(opts, resPkg)
+	}
+
 	if opts.WireController {
+		imports := []string{apiImportCodeFragment, ctrlImportCodeFragment, clockImportCodeFragment}
+		scheme := []string{addschemeCodeFragment}
+		if builtin {
+			imports, scheme = imports[1:], nil
+		}
+		setup := []string{reconcilerSetupCodeFragment}
+		if opts.WireHealthCheck {
+			imports = append(imports, heartbeatImportCodeFragment)
+			setup = []string{heartbeatCodeFragment, reconcilerSetupCodeFragment, heartbeatCheckCodeFragment}
+		}
+		if opts.WireConfigWatcher {
+			imports = append(imports, configImportCodeFragment)
+			// multi-line fragments are not deduplicated by InsertStringsInFile
+			content, err := filesystem.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if !strings.Contains(string(content), "operatorconfig.New()") {
+				setup = append([]string{configCodeFragment}, setup...)
+			}
+		}
+		if opts.WireMessageBus {
+			imports = append(imports, busImportCodeFragment)
+			setup = append([]string{triggerCodeFragment}, setup...)
+			content, err := filesystem.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			if !strings.Contains(string(content), "messagebus.Connect()") {
+				setup = append([]string{busCodeFragment}, setup...)
+			}
+		}
 		return internal.InsertStringsInFile(path,
 			map[string][]string{
-				APIPkgImportScaffoldMarker:    {apiImportCodeFragment, ctrlImportCodeFragment},
-				APISchemeScaffoldMarker:       {addschemeCodeFragment},
-				ReconcilerSetupScaffoldMarker: {reconcilerSetupCodeFragment},
+				APIPkgImportScaffoldMarker:    imports,
+				APISchemeScaffoldMarker:       scheme,
+				ReconcilerSetupScaffoldMarker: setup,
+			})
+	}
+
+	if opts.WireWebhook && builtin {
+		return internal.InsertStringsInFile(path,
+			map[string][]string{
+				APIPkgImportScaffoldMarker:    {webhooksImportCodeFragment},
+				ReconcilerSetupScaffoldMarker: {builtinWebhookSetupCodeFragment},
 			})
 	}
 
@@ -143,10 +435,45 @@ type MainUpdateOptions struct {
 	// Resource is the resource being added
 	Resource *resource.Resource
 
+	// OutputDir is the project root, defaults to the current working directory
+	OutputDir string
+
 	// Flags to indicate if resource/controller is being scaffolded or not
 	WireResource   bool
 	WireController bool
 	WireWebhook    bool
+
+	// WireCertReadiness indicates if the webhook certificate readiness check should be registered
+	WireCertReadiness bool
+
+	// WireHealthCheck indicates if the controller should record its reconciles in a heartbeat registered
+	// as a readiness check
+	WireHealthCheck bool
+
+	// WireSidecarInjector indicates if the sidecar injector should be registered, Resource is not needed
+	WireSidecarInjector bool
+
+	// WireConfigWatcher indicates if the controller should be given the watcher of the configuration of the
+	// operator, which is set up unless main.go already does
+	WireConfigWatcher bool
+
+	// WireStorageMigrator indicates if the storage version migrator should be set up, Resource is not needed
+	WireStorageMigrator bool
+
+	// WireConversionServer indicates if the conversion webhook server should be set up, Resource is not needed
+	WireConversionServer bool
+
+	// WireCertRotator indicates if the rotator of the self-signed serving certificate of the webhook server should
+	// be set up, Resource is not needed
+	WireCertRotator bool
+
+	// WireMessageBus indicates if the controller should be given a trigger of the message bus, which is
+	// connected to unless main.go already does
+	WireMessageBus bool
+
+	// WireAPIReader indicates if the controller should be given the reader of the API server of the manager, which
+	// reads the objects bypassing its cache
+	WireAPIReader bool
 }
 
 var mainTemplate = fmt.Sprintf(`{{ .Boilerplate }}
@@ -156,11 +483,25 @@ package main
 import (
 	"flag"
 	"os"
+{{- if .Tenancy }}
+	"strings"
+{{- end }}
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
+{{- if .Tenancy }}
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+{{- end }}
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+{{ if .Credentials }}
+	"{{ .Repo }}/credentials"
+{{- end }}
+	"{{ .Repo }}/internal/events"
+{{- if .TLSMetrics }}
+	"{{ .Repo }}/internal/metricsauth"
+{{- end }}
 	%s
 )
 
@@ -176,31 +517,124 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == events.Command {
+		// list the recent Events of an object rather than running the manager, see make events
+		os.Exit(events.Run(os.Args[2:], os.Stdout))
+	}
+
 	var metricsAddr string
+	var probeAddr string
 	var enableLeaderElection bool
+{{- if .Tenancy }}
+	var tenantNamespaces string
+{{- end }}
+{{- if .Credentials }}
+	var credentialsDir string
+{{- end }}
+{{- if .TLSMetrics }}
+	var metricsCertDir string
+	flag.StringVar(&metricsAddr, "metrics-addr", ":8443",
+		"The address the metric endpoint binds to, serving the metrics over TLS to the authorized clients.")
+	flag.StringVar(&metricsCertDir, "metrics-cert-dir", metricsauth.DefaultCertDir,
+		"The directory of the certificate of the metric endpoint, tls.crt and tls.key.")
+{{- else }}
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+{{- end }}
+	flag.StringVar(&probeAddr, "health-probe-addr", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. " +
 		"Enabling this will ensure there is only one active controller manager.")
+{{- if .Tenancy }}
+	flag.StringVar(&tenantNamespaces, "tenant-namespaces", "",
+		"The comma-separated namespaces of the tenants, the only ones whose objects are cached. " +
+		"The objects of every namespace are cached if empty.")
+{{- end }}
+{{- if .Credentials }}
+	flag.StringVar(&credentialsDir, "credentials-dir", credentials.DefaultDir,
+		"The directory of the files of the credentials mounted from the secret store.")
+{{- end }}
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(func(o *zap.Options) {
 		o.Development = true
 	}))
+{{- if .Credentials }}
+
+	creds, err := credentials.Load(credentialsDir)
+	if err != nil {
+		setupLog.Error(err, "unable to load the credentials, set --credentials-dir out of the cluster")
+		os.Exit(1)
+	}
+	// give the credentials to the reconcilers calling the cloud provider
+	_ = creds
+{{- end }}
+
+{{- if .Tenancy }}
+
+	options := ctrl.Options{
+		Scheme:                 scheme,
+		MetricsBindAddress:     {{ if .TLSMetrics }}"0"{{ else }}metricsAddr{{ end }},
+		HealthProbeBindAddress: probeAddr,
+		LeaderElection:         enableLeaderElection,
+		Port:                   9443,
+	}
+	if tenantNamespaces != "" {
+		// an informer per namespace of the tenants, the manager doesn't need to access the others
+		options.NewCache = cache.MultiNamespacedCacheBuilder(strings.Split(tenantNamespaces, ","))
+	}
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), options)
+{{- else }}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:             scheme,
-		MetricsBindAddress: metricsAddr,
-		LeaderElection:     enableLeaderElection,
-		Port:               9443, 
+		Scheme:                 scheme,
+		MetricsBindAddress:     {{ if .TLSMetrics }}"0"{{ else }}metricsAddr{{ end }},
+		HealthProbeBindAddress: probeAddr,
+		LeaderElection:         enableLeaderElection,
+		Port:                   9443,
 	})
+{{- end }}
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
+{{- if .TLSMetrics }}
+
+	// the metrics are served by metricsauth rather than by the manager, over TLS to the authorized clients
+	metricsServer, err := metricsauth.NewServer(mgr.GetConfig(), metricsAddr, metricsCertDir)
+	if err != nil {
+		setupLog.Error(err, "unable to create the metrics server")
+		os.Exit(1)
+	}
+	if err := mgr.Add(metricsServer); err != nil {
+		setupLog.Error(err, "unable to set up the metrics server")
+		os.Exit(1)
+	}
+{{- end }}
+{{- if .Wire }}
+
+	// the reconcilers and the webhooks are injected by wire with the providers of providers.go
+	op, err := newOperator(mgr)
+	if err != nil {
+		setupLog.Error(err, "unable to inject the reconcilers and the webhooks")
+		os.Exit(1)
+	}
+	if err = op.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to set up the reconcilers and the webhooks")
+		os.Exit(1)
+	}
+{{- end }}
 
 	%s
 
+	if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to set up health check")
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("ping", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to set up ready check")
+		os.Exit(1)
+	}
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
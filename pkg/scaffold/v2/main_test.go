@@ -0,0 +1,508 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+)
+
+const mainWithMarkers = `package main
+
+import (
+	"os"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	// +kubebuilder:scaffold:imports
+)
+
+func main() {
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{})
+	if err != nil {
+		os.Exit(1)
+	}
+
+	// +kubebuilder:scaffold:builder
+}
+`
+
+func TestMainUpdateHealthCheck(t *testing.T) {
+	for _, multiGroup := range []bool{false, true} {
+		dir, err := ioutil.TempDir("", "main")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(mainWithMarkers), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		r := &resource.Resource{Group: "crew", Version: "v1", Kind: "FirstMate"}
+		if err := r.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		err = (&Main{}).Update(&MainUpdateOptions{
+			Config:          &config.Config{Repo: "example.org/project", Domain: "example.org", MultiGroup: multiGroup},
+			Resource:        r,
+			OutputDir:       dir,
+			WireController:  true,
+			WireHealthCheck: true,
+		})
+		if err != nil {
+			t.Fatalf("multigroup %v: unexpected error: %v", multiGroup, err)
+		}
+
+		b, err := ioutil.ReadFile(filepath.Join(dir, "main.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		heartbeat, check := "firstMateHeartbeat", `"firstmate-controller"`
+		if multiGroup {
+			heartbeat, check = "crewFirstMateHeartbeat", `"crew-firstmate-controller"`
+		}
+		for _, expected := range []string{
+			`"example.org/project/heartbeat"`,
+			heartbeat + ` := heartbeat.New("firstmate", heartbeat.DefaultTimeout)`,
+			heartbeat + ",\n\t).SetupWithManager(mgr)",
+			"mgr.AddReadyzCheck(" + check + ", " + heartbeat + ".Check)",
+		} {
+			if !strings.Contains(string(b), expected) {
+				t.Errorf("multigroup %v: main.go doesn't contain %s:\n%s", multiGroup, expected, b)
+			}
+		}
+	}
+}
+
+func TestMainUpdateSidecarInjector(t *testing.T) {
+	dir, err := ioutil.TempDir("", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(mainWithMarkers), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the sidecar injector is not related to any resource
+	err = (&Main{}).Update(&MainUpdateOptions{
+		Config:              &config.Config{Repo: "example.org/project", Domain: "example.org"},
+		OutputDir:           dir,
+		WireSidecarInjector: true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`"example.org/project/sidecar"`,
+		"sidecar.SetupWebhookWithManager(mgr)",
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Errorf("main.go doesn't contain %s:\n%s", expected, b)
+		}
+	}
+}
+
+func TestMainUpdateCertReadiness(t *testing.T) {
+	dir, err := ioutil.TempDir("", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(mainWithMarkers), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// every webhook created with --cert-readiness asks for the check
+	for _, kind := range []string{"Captain", "FirstMate"} {
+		r := &resource.Resource{Group: "crew", Version: "v1", Kind: kind}
+		if err := r.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		err = (&Main{}).Update(&MainUpdateOptions{
+			Config:            &config.Config{Repo: "example.org/project", Domain: "example.org"},
+			Resource:          r,
+			OutputDir:         dir,
+			WireCertReadiness: true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`"example.org/project/webhookcert"`,
+		`mgr.AddReadyzCheck("webhook-cert"`,
+	} {
+		if n := strings.Count(string(b), expected); n != 1 {
+			t.Errorf("main.go contains %s %d times:\n%s", expected, n, b)
+		}
+	}
+}
+
+func TestMainUpdateConfigWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(mainWithMarkers), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the controllers share the watcher set up for the first one
+	for _, kind := range []string{"FirstMate", "Captain"} {
+		r := &resource.Resource{Group: "crew", Version: "v1", Kind: kind}
+		if err := r.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		err = (&Main{}).Update(&MainUpdateOptions{
+			Config:            &config.Config{Repo: "example.org/project", Domain: "example.org"},
+			Resource:          r,
+			OutputDir:         dir,
+			WireController:    true,
+			WireConfigWatcher: true,
+		})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", kind, err)
+		}
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(b)
+	for expected, count := range map[string]int{
+		`"example.org/project/operatorconfig"`:   1,
+		"operatorConfig := operatorconfig.New()": 1,
+		"operatorConfig.SetupWithManager(mgr)":   1,
+		"operatorConfig,\n":                      2,
+	} {
+		if n := strings.Count(content, expected); n != count {
+			t.Errorf("main.go contains %s %d times instead of %d:\n%s", expected, n, count, content)
+		}
+	}
+	if strings.Index(content, "operatorconfig.New()") > strings.Index(content, "controllers.NewFirstMateReconciler") {
+		t.Errorf("expected the watcher to be created before the controllers:\n%s", content)
+	}
+}
+
+func TestMainUpdateMessageBus(t *testing.T) {
+	dir, err := ioutil.TempDir("", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(mainWithMarkers), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the triggers of the controllers share the connection to the bus made for the first one
+	for _, kind := range []string{"FirstMate", "Captain"} {
+		r := &resource.Resource{Group: "crew", Version: "v1", Kind: kind}
+		if err := r.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		err = (&Main{}).Update(&MainUpdateOptions{
+			Config:         &config.Config{Repo: "example.org/project", Domain: "example.org"},
+			Resource:       r,
+			OutputDir:      dir,
+			WireController: true,
+			WireMessageBus: true,
+		})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", kind, err)
+		}
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(b)
+	for expected, count := range map[string]int{
+		`"example.org/project/messagebus"`:                                1,
+		"bus, err := messagebus.Connect()":                                1,
+		"defer messagebus.Close(bus)":                                     1,
+		`firstMateTrigger := messagebus.NewTrigger(bus, "crew.firstmate"`: 1,
+		`captainTrigger := messagebus.NewTrigger(bus, "crew.captain"`:     1,
+		"mgr.Add(captainTrigger)":                                         1,
+		"firstMateTrigger,\n\t)":                                          1,
+	} {
+		if n := strings.Count(content, expected); n != count {
+			t.Errorf("main.go contains %s %d times instead of %d:\n%s", expected, n, count, content)
+		}
+	}
+	if strings.Index(content, "messagebus.Connect()") > strings.Index(content, "messagebus.NewTrigger") {
+		t.Errorf("expected the bus to be connected to before the triggers are created:\n%s", content)
+	}
+}
+
+func TestMainUpdateAPIReader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(mainWithMarkers), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &resource.Resource{Group: "crew", Version: "v1", Kind: "Captain"}
+	if err := r.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	err = (&Main{}).Update(&MainUpdateOptions{
+		Config:          &config.Config{Repo: "example.org/project", Domain: "example.org"},
+		Resource:        r,
+		OutputDir:       dir,
+		WireController:  true,
+		WireHealthCheck: true,
+		WireAPIReader:   true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the reader follows the other optional dependencies of the constructor
+	if expected := "captainHeartbeat,\n\t\tmgr.GetAPIReader(),\n\t).SetupWithManager(mgr)"; !strings.Contains(string(b), expected) {
+		t.Errorf("expected main.go to contain %q:\n%s", expected, b)
+	}
+}
+
+func TestMainUpdateStorageMigrator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(mainWithMarkers), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the migrator is set up once for every migrated kind
+	for i := 0; i < 2; i++ {
+		err = (&Main{}).Update(&MainUpdateOptions{
+			Config:              &config.Config{Repo: "example.org/project", Domain: "example.org"},
+			OutputDir:           dir,
+			WireStorageMigrator: true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for expected, count := range map[string]int{
+		`"example.org/project/storagemigration"`:                                                    1,
+		"(&storagemigration.Migrator{Resources: storagemigration.Resources}).SetupWithManager(mgr)": 1,
+	} {
+		if n := strings.Count(string(b), expected); n != count {
+			t.Errorf("main.go contains %s %d times instead of %d:\n%s", expected, n, count, b)
+		}
+	}
+}
+
+func TestMainUpdateConversionServer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(mainWithMarkers), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the server is set up once for every kind it converts
+	for i := 0; i < 2; i++ {
+		err = (&Main{}).Update(&MainUpdateOptions{
+			Config:               &config.Config{Repo: "example.org/project", Domain: "example.org"},
+			OutputDir:            dir,
+			WireConversionServer: true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for expected, count := range map[string]int{
+		`"example.org/project/conversionserver"`: 1,
+		"conversionserver.SetupWithManager(mgr)": 1,
+	} {
+		if n := strings.Count(string(b), expected); n != count {
+			t.Errorf("main.go contains %s %d times instead of %d:\n%s", expected, n, count, b)
+		}
+	}
+}
+
+func TestMainUpdateCertRotator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(mainWithMarkers), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the rotator is set up once for every webhook served with its certificate
+	for i := 0; i < 2; i++ {
+		err = (&Main{}).Update(&MainUpdateOptions{
+			Config:          &config.Config{Repo: "example.org/project", Domain: "example.org"},
+			OutputDir:       dir,
+			WireCertRotator: true,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for expected, count := range map[string]int{
+		`"example.org/project/internal/certrotator"`: 1,
+		"certrotator.SetupWithManager(mgr)":          1,
+	} {
+		if n := strings.Count(string(b), expected); n != count {
+			t.Errorf("main.go contains %s %d times instead of %d:\n%s", expected, n, count, b)
+		}
+	}
+}
+
+func TestMainUpdateReconcilerName(t *testing.T) {
+	for naming, reconciler := range map[config.Naming]string{
+		{}:                                 "controllers.NewFirstMateReconciler(",
+		{ReconcilerSuffix: "Controller"}:   "controllers.NewFirstMateController(",
+		{ReconcilerPrefix: "Crew"}:         "controllers.NewCrewFirstMateReconciler(",
+		{FileNames: config.SnakeFileNames}: "controllers.NewFirstMateReconciler(",
+	} {
+		dir, err := ioutil.TempDir("", "main")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(mainWithMarkers), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		r := &resource.Resource{Group: "crew", Version: "v1", Kind: "FirstMate"}
+		if err := r.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		naming := naming
+		err = (&Main{}).Update(&MainUpdateOptions{
+			Config:         &config.Config{Repo: "example.org/project", Domain: "example.org", Naming: &naming},
+			Resource:       r,
+			OutputDir:      dir,
+			WireController: true,
+		})
+		if err != nil {
+			t.Fatalf("naming %+v: unexpected error: %v", naming, err)
+		}
+
+		b, err := ioutil.ReadFile(filepath.Join(dir, "main.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), reconciler) {
+			t.Errorf("naming %+v: expected main.go to set up %s}, got:\n%s", naming, reconciler, b)
+		}
+	}
+}
+
+func TestMainUpdateSkipsWiring(t *testing.T) {
+	dir, err := ioutil.TempDir("", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// the kinds are still added to the scheme
+	withScheme := strings.Replace(mainWithMarkers, "func main() {", `var scheme = runtime.NewScheme()
+
+func init() {
+	// +kubebuilder:scaffold:scheme
+}
+
+func main() {`, 1)
+	withScheme = strings.Replace(withScheme, `"os"`, `"os"
+
+	"k8s.io/apimachinery/pkg/runtime"`, 1)
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(withScheme), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &resource.Resource{Group: "crew", Version: "v1", Kind: "FirstMate"}
+	if err := r.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	c := &config.Config{Repo: "example.org/project", Domain: "example.org",
+		Resources: []config.GVK{{Group: "crew", Version: "v1", Kind: "FirstMate"}}}
+	if !c.SetWiring(r, config.Wiring{SkipController: true, SkipWebhook: true}) {
+		t.Fatalf("expected the wiring of a tracked resource to be recorded")
+	}
+
+	for _, opts := range []*MainUpdateOptions{
+		{Config: c, Resource: r, OutputDir: dir, WireResource: true, WireController: true},
+		{Config: c, Resource: r, OutputDir: dir, WireWebhook: true},
+	} {
+		if err := (&Main{}).Update(opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "_ = crewv1.AddToScheme(scheme)") {
+		t.Errorf("expected the kind to be added to the scheme:\n%s", b)
+	}
+	for _, unexpected := range []string{"NewFirstMateReconciler", "SetupWebhookWithManager",
+		`"example.org/project/controllers"`} {
+		if strings.Contains(string(b), unexpected) {
+			t.Errorf("main.go contains %s, whose wiring is skipped:\n%s", unexpected, b)
+		}
+	}
+}
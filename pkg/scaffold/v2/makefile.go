@@ -17,7 +17,10 @@ limitations under the License.
 package v2
 
 import (
+	"path/filepath"
+
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/webhook"
 )
 
 var _ input.File = &Makefile{}
@@ -29,6 +32,17 @@ type Makefile struct {
 	Image string
 	// Controller tools version to use in the project
 	ControllerToolsVersion string
+	// CRDKustomization is the slash separated path of the kustomization of the CRDs
+	CRDKustomization string
+	// ManifestsOutput are the controller-gen output rules of the manifests, only the CRDs are
+	// generated outside of the default directories of controller-gen unless they are configured
+	ManifestsOutput string
+	// Wire indicates that make generate runs wire to generate the injector of the reconcilers and the webhooks
+	Wire bool
+	// WebhookBase is the slash separated path of the webhook manifests relative to config/default, and
+	// WebhookCanaryDir the one of their canary overlay relative to the project root
+	WebhookBase      string
+	WebhookCanaryDir string
 }
 
 // GetInput implements input.File
@@ -39,6 +53,20 @@ func (f *Makefile) GetInput() (input.Input, error) {
 	if f.Image == "" {
 		f.Image = "controller:latest"
 	}
+	f.CRDKustomization = filepath.ToSlash(f.CRDDir)
+	f.ManifestsOutput = "output:crd:artifacts:config=" + filepath.ToSlash(filepath.Join(f.CRDDir, "bases"))
+	if f.RBACDir != filepath.Join("config", "rbac") {
+		f.ManifestsOutput += " output:rbac:artifacts:config=" + filepath.ToSlash(f.RBACDir)
+	}
+	if f.WebhookDir != filepath.Join("config", "webhook") {
+		f.ManifestsOutput += " output:webhook:artifacts:config=" + filepath.ToSlash(f.WebhookDir)
+	}
+	webhookBase, err := filepath.Rel(filepath.Join("config", "default"), f.WebhookDir)
+	if err != nil {
+		return input.Input{}, err
+	}
+	f.WebhookBase = filepath.ToSlash(webhookBase)
+	f.WebhookCanaryDir = filepath.ToSlash(filepath.Join(f.WebhookDir, webhook.CanaryDir))
 	f.TemplateBody = makefileTemplate
 	f.Input.IfExistsAction = input.Error
 	return f.Input, nil
@@ -48,8 +76,12 @@ func (f *Makefile) GetInput() (input.Input, error) {
 const makefileTemplate = `
 # Image URL to use all building/pushing image targets
 IMG ?= {{ .Image }}
+{{- if eq .Kubernetes.CRDVersion "v1beta1" }}
 # Produce CRDs that work back to Kubernetes 1.11 (no version conversion)
-CRD_OPTIONS ?= "crd:trivialVersions=true"
+{{- else }}
+# Produce apiextensions.k8s.io/{{ .Kubernetes.CRDVersion }} CRDs, served from Kubernetes 1.16
+{{- end }}
+CRD_OPTIONS ?= "{{ .Kubernetes.CRDOptions }}"
 
 # Get the currently used golang install path (in GOPATH/bin, unless GOBIN is set)
 ifeq (,$(shell go env GOBIN))
@@ -60,9 +92,19 @@ endif
 
 all: manager
 
-# Run tests
+# Run tests, then test the manifests rendered from config/default against the golden ones of internal/manifests,
+# uncached as the config directory is out of their module
 test: generate fmt vet manifests
 	go test ./... -coverprofile cover.out
+	cd internal/manifests && go test -count=1 ./...
+
+# Update the golden manifests of internal/manifests after changing the config directory on purpose
+update-manifests-golden: manifests
+	cd internal/manifests && go test -count=1 ./... -update
+
+# Run tests, running the specs of every suite in parallel, each ginkgo process starts its own test environment
+test-parallel: generate fmt vet manifests ginkgo
+	$(GINKGO) -r -p
 
 # Build manager binary
 manager: generate fmt vet
@@ -72,22 +114,36 @@ manager: generate fmt vet
 run: generate fmt vet manifests
 	go run ./main.go
 
+# List the recent Events of an object, e.g. make events KIND=Captain NAMESPACE=default NAME=captain-sample
+events:
+	go run ./main.go events --kind "$(KIND)" --namespace "$(NAMESPACE)" --name "$(NAME)"
+
 # Install CRDs into a cluster
 install: manifests
-	kustomize build config/crd | kubectl apply -f -
+	kustomize build {{ .CRDKustomization }} | kubectl apply -f -
 
 # Uninstall CRDs from a cluster
 uninstall: manifests
-	kustomize build config/crd | kubectl delete -f -
+	kustomize build {{ .CRDKustomization }} | kubectl delete -f -
 
 # Deploy controller in the configured Kubernetes cluster in ~/.kube/config
 deploy: manifests
 	cd config/manager && kustomize edit set image controller=${IMG}
 	kustomize build config/default | kubectl apply -f -
 
+# Promote the webhooks rolled out in canary by create webhook --canary to every namespace and to their own failure
+# policy, Fail by default: config/default deploys the webhook manifests again and the canary overlay is removed
+webhook-promote:
+	sed -i.bak 's|- {{ .WebhookBase }}/canary$$|- {{ .WebhookBase }}|' config/default/kustomization.yaml
+	rm -rf config/default/kustomization.yaml.bak {{ .WebhookCanaryDir }}
+
 # Generate manifests e.g. CRD, RBAC etc.
 manifests: controller-gen
-	$(CONTROLLER_GEN) $(CRD_OPTIONS) rbac:roleName=manager-role webhook paths="./..." output:crd:artifacts:config=config/crd/bases
+	$(CONTROLLER_GEN) $(CRD_OPTIONS) rbac:roleName=manager-role webhook paths="./..." {{ .ManifestsOutput }}
+
+# Check the generated CRDs against the committed ones for breaking changes of their schema
+verify-crds: manifests
+	kubebuilder verify crds
 
 # Run go fmt against code
 fmt:
@@ -98,8 +154,15 @@ vet:
 	go vet ./...
 
 # Generate code
-generate: controller-gen
+generate: controller-gen{{ if .Wire }} wire{{ end }}
 	$(CONTROLLER_GEN) object:headerFile={{printf "%q" .BoilerplatePath}} paths="./..."
+{{- if .Wire }}
+	$(WIRE) .
+{{- end }}
+
+# Refresh the SBOM of the dependencies of the project, run it before every release
+sbom:
+	kubebuilder alpha sbom
 
 # Build the docker image
 docker-build: test
@@ -125,4 +188,24 @@ CONTROLLER_GEN=$(GOBIN)/controller-gen
 else
 CONTROLLER_GEN=$(shell which controller-gen)
 endif
+
+# find or install ginkgo, of the version required by go.mod
+ginkgo:
+ifeq (, $(shell which ginkgo))
+	go install github.com/onsi/ginkgo/ginkgo
+GINKGO=$(GOBIN)/ginkgo
+else
+GINKGO=$(shell which ginkgo)
+endif
+{{- if .Wire }}
+
+# find or install wire, of the version required by go.mod
+wire:
+ifeq (, $(shell which wire))
+	go install github.com/google/wire/cmd/wire
+WIRE=$(GOBIN)/wire
+else
+WIRE=$(shell which wire)
+endif
+{{- end }}
 `
@@ -64,6 +64,11 @@ spec:
       labels:
         control-plane: controller-manager
     spec:
+{{- if .Kubernetes.SeccompProfile }}
+      securityContext:
+        seccompProfile:
+          type: RuntimeDefault
+{{- end }}
       containers:
       - command:
         - /manager
@@ -71,12 +76,30 @@ spec:
         - --enable-leader-election
         image: {{ .Image }}
         name: manager
+        livenessProbe:
+          httpGet:
+            path: /healthz
+            port: 8081
+          initialDelaySeconds: 15
+          periodSeconds: 20
+        readinessProbe:
+          httpGet:
+            path: /readyz
+            port: 8081
+          initialDelaySeconds: 5
+          periodSeconds: 10
         resources:
           limits:
             cpu: 100m
             memory: 30Mi
+{{- if .Kubernetes.EphemeralStorage }}
+            ephemeral-storage: 100Mi
+{{- end }}
           requests:
             cpu: 100m
             memory: 20Mi
+{{- if .Kubernetes.EphemeralStorage }}
+            ephemeral-storage: 10Mi
+{{- end }}
       terminationGracePeriodSeconds: 10
 `
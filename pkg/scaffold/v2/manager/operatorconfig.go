@@ -0,0 +1,61 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/internal"
+)
+
+var _ input.File = &OperatorConfig{}
+
+// OperatorConfig scaffolds the ConfigMap holding the runtime tunables of the operator
+type OperatorConfig struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *OperatorConfig) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "manager", "operator_config.yaml")
+	}
+	f.TemplateBody = operatorConfigTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+// Update adds the ConfigMap to the resources of the kustomization next to it
+func (f *OperatorConfig) Update() error {
+	return internal.AddToKustomization(filepath.Join(f.ProjectPath, filepath.Dir(f.Path), "kustomization.yaml"),
+		"resources", filepath.Base(f.Path))
+}
+
+const operatorConfigTemplate = `# The runtime tunables of the operator, see the operatorconfig package. The controllers are notified when
+# they change, the manager doesn't need to be restarted.
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: operator-config
+  namespace: system
+  labels:
+    app.kubernetes.io/component: operator-config
+data:
+  config.yaml: |
+    paused: false
+`
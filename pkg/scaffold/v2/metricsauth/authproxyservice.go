@@ -32,7 +32,7 @@ type AuthProxyService struct {
 // GetInput implements input.File
 func (f *AuthProxyService) GetInput() (input.Input, error) {
 	if f.Path == "" {
-		f.Path = filepath.Join("config", "rbac", "auth_proxy_service.yaml")
+		f.Path = filepath.Join(f.RBACDir, "auth_proxy_service.yaml")
 	}
 	f.TemplateBody = AuthProxyServiceTemplate
 	return f.Input, nil
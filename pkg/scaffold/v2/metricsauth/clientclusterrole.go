@@ -32,7 +32,7 @@ type ClientClusterRole struct {
 // GetInput implements input.File
 func (f *ClientClusterRole) GetInput() (input.Input, error) {
 	if f.Path == "" {
-		f.Path = filepath.Join("config", "rbac", "auth_proxy_client_clusterrole.yaml")
+		f.Path = filepath.Join(f.RBACDir, "auth_proxy_client_clusterrole.yaml")
 	}
 	f.TemplateBody = ClientClusterRoleTemplate
 	return f.Input, nil
@@ -0,0 +1,323 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsauth
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &Server{}
+
+// Server scaffolds the server of the metrics of the manager, serving them over TLS to the authorized clients
+type Server struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *Server) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "metricsauth", "server.go")
+	}
+	f.TemplateBody = serverTemplate
+	return f.Input, nil
+}
+
+var _ input.File = &ServerTest{}
+
+// ServerTest scaffolds the tests of the authentication and the authorization of the clients of the metrics
+type ServerTest struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *ServerTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "metricsauth", "server_test.go")
+	}
+	f.TemplateBody = serverTestTemplate
+	return f.Input, nil
+}
+
+const serverTemplate = `{{ .Boilerplate }}
+
+// Package metricsauth serves the metrics of the manager over TLS to the clients authorized to get /metrics, e.g.
+// the service account of Prometheus bound to the metrics-reader ClusterRole. The clients are authenticated with
+// TokenReviews and authorized with SubjectAccessReviews, as kube-rbac-proxy does, without a sidecar.
+package metricsauth
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/client-go/kubernetes"
+	authenticationv1client "k8s.io/client-go/kubernetes/typed/authentication/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// DefaultCertDir is the directory the certificate of the metrics endpoint is mounted in
+const DefaultCertDir = "/tmp/k8s-metrics-server/metrics-certs"
+
+var log = ctrl.Log.WithName("metrics")
+
+// Server serves the metrics of the registry of controller-runtime over TLS, it is a Runnable of the manager
+type Server struct {
+	// Addr is the address the server binds to, e.g. :8443
+	Addr string
+
+	// CertDir is the directory of the certificate and of the key of the server, tls.crt and tls.key. They are
+	// loaded again when cert-manager renews them.
+	CertDir string
+
+	// TokenReviews authenticate the bearer tokens of the clients and SubjectAccessReviews authorize them
+	TokenReviews         authenticationv1client.TokenReviewInterface
+	SubjectAccessReviews authorizationv1client.SubjectAccessReviewInterface
+}
+
+// NewServer returns the server of the metrics authenticating and authorizing the clients against the API server
+// of cfg
+func NewServer(cfg *rest.Config, addr, certDir string) (*Server, error) {
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		Addr:                 addr,
+		CertDir:              certDir,
+		TokenReviews:         clientset.AuthenticationV1().TokenReviews(),
+		SubjectAccessReviews: clientset.AuthorizationV1().SubjectAccessReviews(),
+	}, nil
+}
+
+// NeedLeaderElection implements LeaderElectionRunnable, every replica serves its metrics
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements Runnable, it serves the metrics until stop is closed
+func (s *Server) Start(stop <-chan struct{}) error {
+	cert := &certificate{dir: s.CertDir}
+	if _, err := cert.get(nil); err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", s)
+	srv := &http.Server{
+		Addr:      s.Addr,
+		Handler:   mux,
+		TLSConfig: &tls.Config{MinVersion: tls.VersionTLS12, GetCertificate: cert.get},
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		log.Info("serving the metrics over TLS", "addr", s.Addr)
+		errs <- srv.ListenAndServeTLS("", "")
+	}()
+	select {
+	case err := <-errs:
+		return err
+	case <-stop:
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(ctx)
+	}
+}
+
+// ServeHTTP serves the metrics to the clients authorized to get the path of the request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, err := s.authenticate(r)
+	if err != nil {
+		log.V(1).Info("unauthenticated request of the metrics", "reason", err.Error())
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+	allowed, err := s.authorize(user, r)
+	if err != nil {
+		log.Error(err, "unable to authorize a request of the metrics", "user", user.Username)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if !allowed {
+		log.V(1).Info("unauthorized request of the metrics", "user", user.Username)
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+	promhttp.HandlerFor(metrics.Registry, promhttp.HandlerOpts{}).ServeHTTP(w, r)
+}
+
+// authenticate returns the user of the bearer token of the request
+func (s *Server) authenticate(r *http.Request) (authenticationv1.UserInfo, error) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return authenticationv1.UserInfo{}, fmt.Errorf("no bearer token")
+	}
+	review, err := s.TokenReviews.Create(&authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: strings.TrimPrefix(header, "Bearer ")},
+	})
+	if err != nil {
+		return authenticationv1.UserInfo{}, fmt.Errorf("unable to review the token: %v", err)
+	}
+	if !review.Status.Authenticated {
+		return authenticationv1.UserInfo{}, fmt.Errorf("invalid token: %s", review.Status.Error)
+	}
+	return review.Status.User, nil
+}
+
+// authorize returns true if the user may get the path of the request
+func (s *Server) authorize(user authenticationv1.UserInfo, r *http.Request) (bool, error) {
+	extra := map[string]authorizationv1.ExtraValue{}
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	review, err := s.SubjectAccessReviews.Create(&authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			Groups: user.Groups,
+			UID:    user.UID,
+			Extra:  extra,
+			NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+				Path: r.URL.Path,
+				Verb: strings.ToLower(r.Method),
+			},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
+
+// certificate loads the certificate of the server, again when its files change
+type certificate struct {
+	dir string
+
+	mu      sync.Mutex
+	modTime time.Time
+	cert    *tls.Certificate
+}
+
+// get returns the certificate, it is the GetCertificate of the TLS configuration of the server
+func (c *certificate) get(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	certFile, keyFile := filepath.Join(c.dir, "tls.crt"), filepath.Join(c.dir, "tls.key")
+	info, err := os.Stat(certFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the certificate of the metrics endpoint: %v", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cert == nil || !info.ModTime().Equal(c.modTime) {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the certificate of the metrics endpoint: %v", err)
+		}
+		c.cert, c.modTime = &cert, info.ModTime()
+	}
+	return c.cert, nil
+}
+`
+
+const serverTestTemplate = `{{ .Boilerplate }}
+
+package metricsauth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newTestServer returns a server authenticating the token "reader" as the user allowed to get /metrics and the
+// token "other" as a user who isn't
+func newTestServer(t *testing.T) *Server {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		switch review.Spec.Token {
+		case "reader", "other":
+			review.Status.Authenticated = true
+			review.Status.User = authenticationv1.UserInfo{Username: review.Spec.Token}
+		}
+		return true, review, nil
+	})
+	clientset.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		attributes := review.Spec.NonResourceAttributes
+		if attributes == nil || attributes.Path != "/metrics" || attributes.Verb != "get" {
+			t.Errorf("unexpected attributes %+v", attributes)
+		}
+		review.Status.Allowed = review.Spec.User == "reader"
+		return true, review, nil
+	})
+	return &Server{
+		TokenReviews:         clientset.AuthenticationV1().TokenReviews(),
+		SubjectAccessReviews: clientset.AuthorizationV1().SubjectAccessReviews(),
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	s := newTestServer(t)
+	tests := []struct {
+		name          string
+		authorization string
+		status        int
+	}{
+		{"authorized client", "Bearer reader", http.StatusOK},
+		{"client not allowed to get the metrics", "Bearer other", http.StatusForbidden},
+		{"invalid token", "Bearer invalid", http.StatusUnauthorized},
+		{"no token", "", http.StatusUnauthorized},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if test.authorization != "" {
+			r.Header.Set("Authorization", test.authorization)
+		}
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		if w.Code != test.status {
+			t.Errorf("%s: expected the status %d, got %d", test.name, test.status, w.Code)
+		}
+	}
+}
+
+func TestCertificate(t *testing.T) {
+	c := &certificate{dir: t.Name()}
+	if _, err := c.get(nil); err == nil {
+		t.Error("expected an error without certificate")
+	}
+}
+`
@@ -0,0 +1,234 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metricsauth
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+// CertSecretName is the name of the Secret cert-manager stores the certificate of the metrics endpoint in, which
+// isn't prefixed by kustomize since kustomize doesn't manage it
+const CertSecretName = "metrics-server-cert"
+
+var _ input.File = &MetricsPatch{}
+
+// MetricsPatch scaffolds the patch of the manager serving its metrics over TLS with the certificate of
+// cert-manager
+type MetricsPatch struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *MetricsPatch) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "default", "manager_metrics_patch.yaml")
+	}
+	f.TemplateBody = metricsPatchTemplate
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+const metricsPatchTemplate = `# This patch serves the metrics of the manager over TLS with the certificate of config/metrics, to the clients
+# authenticated and authorized by the API server to get /metrics, e.g. bound to the metrics-reader ClusterRole.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller-manager
+  namespace: system
+spec:
+  template:
+    spec:
+      containers:
+      - name: manager
+        args:
+        - "--metrics-addr=:8443"
+        - "--enable-leader-election"
+        ports:
+        - containerPort: 8443
+          name: https
+          protocol: TCP
+        volumeMounts:
+        - mountPath: /tmp/k8s-metrics-server/metrics-certs
+          name: metrics-cert
+          readOnly: true
+      volumes:
+      - name: metrics-cert
+        secret:
+          defaultMode: 420
+          secretName: ` + CertSecretName + `
+`
+
+var _ input.File = &Certificate{}
+
+// Certificate scaffolds the self-signed issuer and the certificate of the metrics endpoint
+type Certificate struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *Certificate) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "metrics", "certificate.yaml")
+	}
+	f.TemplateBody = certificateTemplate
+	return f.Input, nil
+}
+
+const certificateTemplate = `# The certificate of the metrics endpoint of the manager, issued by cert-manager which must be installed in the
+# cluster. The issuer is self-signed, the prometheus monitor verifies the endpoint with the ca.crt of the Secret.
+# Replace it with the issuer of your cluster to have the certificate trusted by the other clients.
+apiVersion: cert-manager.io/v1alpha2
+kind: Issuer
+metadata:
+  name: metrics-selfsigned-issuer
+  namespace: system
+spec:
+  selfSigned: {}
+---
+apiVersion: cert-manager.io/v1alpha2
+kind: Certificate
+metadata:
+  name: metrics-cert
+  namespace: system
+spec:
+  # $(METRICS_SERVICE_NAME) and $(METRICS_SERVICE_NAMESPACE) will be substituted by kustomize
+  dnsNames:
+  - $(METRICS_SERVICE_NAME).$(METRICS_SERVICE_NAMESPACE).svc
+  - $(METRICS_SERVICE_NAME).$(METRICS_SERVICE_NAMESPACE).svc.cluster.local
+  issuerRef:
+    kind: Issuer
+    name: metrics-selfsigned-issuer
+  secretName: ` + CertSecretName + ` # this secret will not be prefixed, since it's not managed by kustomize
+`
+
+var _ input.File = &Kustomization{}
+
+// Kustomization scaffolds the kustomization of the certificate of the metrics endpoint
+type Kustomization struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *Kustomization) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "metrics", "kustomization.yaml")
+	}
+	f.TemplateBody = kustomizationTemplate
+	return f.Input, nil
+}
+
+const kustomizationTemplate = `resources:
+- certificate.yaml
+
+configurations:
+- kustomizeconfig.yaml
+`
+
+var _ input.File = &KustomizeConfig{}
+
+// KustomizeConfig scaffolds the kustomize configuration of the certificate of the metrics endpoint
+type KustomizeConfig struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *KustomizeConfig) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "metrics", "kustomizeconfig.yaml")
+	}
+	f.TemplateBody = kustomizeConfigTemplate
+	return f.Input, nil
+}
+
+const kustomizeConfigTemplate = `# This configuration is for teaching kustomize how to update name ref and var substitution
+nameReference:
+- kind: Issuer
+  group: cert-manager.io
+  fieldSpecs:
+  - kind: Certificate
+    group: cert-manager.io
+    path: spec/issuerRef/name
+
+varReference:
+- kind: Certificate
+  group: cert-manager.io
+  path: spec/dnsNames
+`
+
+var _ input.File = &AuthRole{}
+
+// AuthRole scaffolds the ClusterRole of the manager authenticating and authorizing the clients of its metrics
+type AuthRole struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *AuthRole) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join(f.RBACDir, "metrics_auth_role.yaml")
+	}
+	f.TemplateBody = authRoleTemplate
+	return f.Input, nil
+}
+
+const authRoleTemplate = `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: metrics-auth-role
+rules:
+- apiGroups: ["authentication.k8s.io"]
+  resources:
+  - tokenreviews
+  verbs: ["create"]
+- apiGroups: ["authorization.k8s.io"]
+  resources:
+  - subjectaccessreviews
+  verbs: ["create"]
+`
+
+var _ input.File = &AuthRoleBinding{}
+
+// AuthRoleBinding scaffolds the binding of the ClusterRole authenticating and authorizing the clients of the
+// metrics to the manager
+type AuthRoleBinding struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *AuthRoleBinding) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join(f.RBACDir, "metrics_auth_role_binding.yaml")
+	}
+	f.TemplateBody = authRoleBindingTemplate
+	return f.Input, nil
+}
+
+const authRoleBindingTemplate = `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: metrics-auth-rolebinding
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: metrics-auth-role
+subjects:
+- kind: ServiceAccount
+  name: default
+  namespace: system
+`
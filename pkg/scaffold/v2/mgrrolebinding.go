@@ -32,7 +32,7 @@ type ManagerRoleBinding struct {
 // GetInput implements input.File
 func (f *ManagerRoleBinding) GetInput() (input.Input, error) {
 	if f.Path == "" {
-		f.Path = filepath.Join("config", "rbac", "role_binding.yaml")
+		f.Path = filepath.Join(f.RBACDir, "role_binding.yaml")
 	}
 	f.TemplateBody = managerBindingTemplate
 	return f.Input, nil
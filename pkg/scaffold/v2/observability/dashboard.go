@@ -0,0 +1,249 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package observability
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+)
+
+var _ input.File = &Dashboard{}
+
+// Dashboard scaffolds a Grafana dashboard of the work queues and reconciliations of the controllers
+type Dashboard struct {
+	input.Input
+
+	// Resources are the resources tracked in the PROJECT file, their controllers are named after their kinds
+	Resources []config.GVK
+
+	// Controllers are the names of the controllers, computed from Resources if empty
+	Controllers []string
+
+	// Title is the title of the dashboard
+	Title string
+
+	// UID is the unique identifier of the dashboard in Grafana
+	UID string
+}
+
+// GetInput implements input.File
+func (f *Dashboard) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "observability", "dashboard.json")
+	}
+	if len(f.Controllers) == 0 {
+		f.Controllers = controllerNames(f.Resources)
+	}
+	if f.Title == "" {
+		name, err := util.ProjectName(f.ProjectPath)
+		if err != nil {
+			return input.Input{}, err
+		}
+		f.Title = name + " controllers"
+	}
+	if f.UID == "" {
+		f.UID = uid(f.Title)
+	}
+	f.TemplateBody = dashboardTemplate
+	// the dashboard is regenerated from the PROJECT file every time an API is created
+	f.Input.IfExistsAction = input.Overwrite
+	return f.Input, nil
+}
+
+// controllerNames returns the sorted names controller-runtime gives to the controllers of the resources
+func controllerNames(resources []config.GVK) []string {
+	set := map[string]bool{}
+	for _, r := range resources {
+		set[strings.ToLower(r.Kind)] = true
+	}
+	names := make([]string, 0, len(set))
+	for name := range set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// uid returns a dashboard identifier made of the lower case alphanumeric characters of title,
+// truncated to the 40 characters allowed by Grafana
+func uid(title string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "-"):
+			b.WriteRune('-')
+		}
+	}
+	id := strings.Trim(b.String(), "-")
+	if len(id) > 40 {
+		id = strings.TrimRight(id[:40], "-")
+	}
+	return id
+}
+
+// nolint:lll
+const dashboardTemplate = `{
+  "annotations": {
+    "list": []
+  },
+  "editable": true,
+  "graphTooltip": 1,
+  "panels": [
+    {
+      "datasource": "$datasource",
+      "gridPos": {"h": 8, "w": 12, "x": 0, "y": 0},
+      "id": 1,
+      "legend": {"show": true},
+      "lines": true,
+      "linewidth": 1,
+      "targets": [
+        {
+          "expr": "sum(workqueue_depth{namespace=\"$namespace\", name=~\"$controller\"}) by (name)",
+          "legendFormat": "{{ "{{" }}name{{ "}}" }}",
+          "refId": "A"
+        }
+      ],
+      "title": "Work queue depth",
+      "type": "graph",
+      "yaxes": [{"format": "short", "min": 0}, {"format": "short"}]
+    },
+    {
+      "datasource": "$datasource",
+      "gridPos": {"h": 8, "w": 12, "x": 12, "y": 0},
+      "id": 2,
+      "legend": {"show": true},
+      "lines": true,
+      "linewidth": 1,
+      "targets": [
+        {
+          "expr": "histogram_quantile(0.99, sum(rate(workqueue_queue_duration_seconds_bucket{namespace=\"$namespace\", name=~\"$controller\"}[5m])) by (name, le))",
+          "legendFormat": "{{ "{{" }}name{{ "}}" }}",
+          "refId": "A"
+        }
+      ],
+      "title": "Work queue latency (p99)",
+      "type": "graph",
+      "yaxes": [{"format": "s", "min": 0}, {"format": "short"}]
+    },
+    {
+      "datasource": "$datasource",
+      "gridPos": {"h": 8, "w": 12, "x": 0, "y": 8},
+      "id": 3,
+      "legend": {"show": true},
+      "lines": true,
+      "linewidth": 1,
+      "targets": [
+        {
+          "expr": "sum(rate(controller_runtime_reconcile_total{namespace=\"$namespace\", controller=~\"$controller\"}[5m])) by (controller, result)",
+          "legendFormat": "{{ "{{" }}controller{{ "}}" }} {{ "{{" }}result{{ "}}" }}",
+          "refId": "A"
+        }
+      ],
+      "title": "Reconciliations per second",
+      "type": "graph",
+      "yaxes": [{"format": "ops", "min": 0}, {"format": "short"}]
+    },
+    {
+      "datasource": "$datasource",
+      "gridPos": {"h": 8, "w": 12, "x": 12, "y": 8},
+      "id": 4,
+      "legend": {"show": true},
+      "lines": true,
+      "linewidth": 1,
+      "targets": [
+        {
+          "expr": "sum(rate(controller_runtime_reconcile_errors_total{namespace=\"$namespace\", controller=~\"$controller\"}[5m])) by (controller) / sum(rate(controller_runtime_reconcile_total{namespace=\"$namespace\", controller=~\"$controller\"}[5m])) by (controller)",
+          "legendFormat": "{{ "{{" }}controller{{ "}}" }}",
+          "refId": "A"
+        }
+      ],
+      "title": "Reconcile error rate",
+      "type": "graph",
+      "yaxes": [{"format": "percentunit", "max": 1, "min": 0}, {"format": "short"}]
+    },
+    {
+      "datasource": "$datasource",
+      "gridPos": {"h": 8, "w": 24, "x": 0, "y": 16},
+      "id": 5,
+      "legend": {"show": true},
+      "lines": true,
+      "linewidth": 1,
+      "targets": [
+        {
+          "expr": "histogram_quantile(0.5, sum(rate(controller_runtime_reconcile_time_seconds_bucket{namespace=\"$namespace\", controller=~\"$controller\"}[5m])) by (controller, le))",
+          "legendFormat": "{{ "{{" }}controller{{ "}}" }} p50",
+          "refId": "A"
+        },
+        {
+          "expr": "histogram_quantile(0.99, sum(rate(controller_runtime_reconcile_time_seconds_bucket{namespace=\"$namespace\", controller=~\"$controller\"}[5m])) by (controller, le))",
+          "legendFormat": "{{ "{{" }}controller{{ "}}" }} p99",
+          "refId": "B"
+        }
+      ],
+      "title": "Reconcile duration",
+      "type": "graph",
+      "yaxes": [{"format": "s", "min": 0}, {"format": "short"}]
+    }
+  ],
+  "refresh": "30s",
+  "schemaVersion": 22,
+  "tags": ["kubebuilder"],
+  "templating": {
+    "list": [
+      {
+        "label": "Data source",
+        "name": "datasource",
+        "query": "prometheus",
+        "type": "datasource"
+      },
+      {
+        "datasource": "$datasource",
+        "label": "Namespace",
+        "name": "namespace",
+        "query": "label_values(controller_runtime_reconcile_total, namespace)",
+        "refresh": 2,
+        "type": "query"
+      },
+      {
+        "current": {"text": "All", "value": ["$__all"]},
+        "includeAll": true,
+        "label": "Controller",
+        "multi": true,
+        "name": "controller",
+        "options": [
+          {"selected": true, "text": "All", "value": "$__all"}
+          {{- range .Controllers }},
+          {"selected": false, "text": "{{ . }}", "value": "{{ . }}"}
+          {{- end }}
+        ],
+        "query": "{{ range $i, $c := .Controllers }}{{ if $i }},{{ end }}{{ $c }}{{ end }}",
+        "type": "custom"
+      }
+    ]
+  },
+  "time": {"from": "now-1h", "to": "now"},
+  "title": "{{ .Title }}",
+  "uid": "{{ .UID }}"
+}
+`
@@ -0,0 +1,55 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package observability
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &Kustomization{}
+
+// Kustomization scaffolds the kustomization in the observability folder
+type Kustomization struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *Kustomization) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "observability", "kustomization.yaml")
+	}
+	f.TemplateBody = kustomizationTemplate
+	return f.Input, nil
+}
+
+const kustomizationTemplate = `# Deploys the prometheus monitor of the controller manager and a Grafana dashboard of its controllers.
+# The dashboard ConfigMap is labelled to be loaded by the dashboards sidecar of the Grafana Helm chart.
+resources:
+- ../prometheus
+
+generatorOptions:
+  disableNameSuffixHash: true
+  labels:
+    grafana_dashboard: "1"
+
+configMapGenerator:
+- name: controller-dashboard
+  files:
+  - dashboard.json
+`
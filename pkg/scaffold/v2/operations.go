@@ -0,0 +1,147 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+)
+
+var _ input.File = &Operations{}
+
+// Operations scaffolds the api/<version>/operation_types.go shared by the status of the kinds in the version whose
+// controllers run long-running operations, tracking the phase of the operation and the state of its steps
+type Operations struct {
+	input.Input
+
+	// Resource is a resource in the API group
+	Resource *resource.Resource
+}
+
+// GetInput implements input.File
+func (f *Operations) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		if f.MultiGroup {
+			f.Path = filepath.Join("apis", f.Resource.Group, f.Resource.Version, "operation_types.go")
+		} else {
+			f.Path = filepath.Join("api", f.Resource.Version, "operation_types.go")
+		}
+	}
+	f.TemplateBody = operationsTemplate
+	f.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *Operations) Validate() error {
+	return f.Resource.Validate()
+}
+
+const operationsTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OperationPhase is the phase of a long-running operation
+// +kubebuilder:validation:Enum=Pending;Running;Succeeded;Failed
+type OperationPhase string
+
+// The phases of a long-running operation
+const (
+	// OperationPending is the phase of an operation whose steps haven't run yet
+	OperationPending OperationPhase = "Pending"
+
+	// OperationRunning is the phase of an operation whose steps are running
+	OperationRunning OperationPhase = "Running"
+
+	// OperationSucceeded is the phase of an operation whose steps are all done
+	OperationSucceeded OperationPhase = "Succeeded"
+
+	// OperationFailed is the phase of an operation one step of which failed for good, it starts over when the
+	// spec changes
+	OperationFailed OperationPhase = "Failed"
+)
+
+// StepStatus is the state of a step of a long-running operation, in the form of a condition
+type StepStatus struct {
+	// Name of the step
+	Name string ` + "`" + `json:"name"` + "`" + `
+
+	// Status of the step: True once it is done, Unknown while it is in progress and False when it failed
+	Status metav1.ConditionStatus ` + "`" + `json:"status"` + "`" + `
+
+	// Retries is the number of times the step failed and was retried
+	// +optional
+	Retries int32 ` + "`" + `json:"retries,omitempty"` + "`" + `
+
+	// LastTransitionTime is the last time the step changed its status
+	// +optional
+	LastTransitionTime metav1.Time ` + "`" + `json:"lastTransitionTime,omitempty"` + "`" + `
+
+	// Reason is a machine readable explanation of the last transition
+	// +optional
+	Reason string ` + "`" + `json:"reason,omitempty"` + "`" + `
+
+	// Message is a human readable explanation of the last transition, e.g. the error of the step
+	// +optional
+	Message string ` + "`" + `json:"message,omitempty"` + "`" + `
+}
+
+// OperationStatus is the progress of a long-running operation, persisted in the status for the operation to be
+// resumed by the next reconciles, including after a restart of the manager
+type OperationStatus struct {
+	// Phase of the operation
+	// +optional
+	Phase OperationPhase ` + "`" + `json:"phase,omitempty"` + "`" + `
+
+	// ObservedGeneration is the generation of the spec the operation runs for
+	// +optional
+	ObservedGeneration int64 ` + "`" + `json:"observedGeneration,omitempty"` + "`" + `
+
+	// Steps are the states of the steps which ran, in order
+	// +optional
+	Steps []StepStatus ` + "`" + `json:"steps,omitempty"` + "`" + `
+}
+
+// FindStep returns the state of the step of the name, nil if it didn't run
+func (s *OperationStatus) FindStep(name string) *StepStatus {
+	for i := range s.Steps {
+		if s.Steps[i].Name == name {
+			return &s.Steps[i]
+		}
+	}
+	return nil
+}
+
+// SetStep adds the state of the step or replaces the state of the step of its name, whose last transition time is
+// kept when its status doesn't change
+func (s *OperationStatus) SetStep(step StepStatus) {
+	if existing := s.FindStep(step.Name); existing != nil {
+		if existing.Status == step.Status {
+			step.LastTransitionTime = existing.LastTransitionTime
+		}
+		*existing = step
+		return
+	}
+	s.Steps = append(s.Steps, step)
+}
+`
@@ -0,0 +1,63 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/internal"
+)
+
+var _ input.File = &OperatorConfigRole{}
+
+// OperatorConfigRole scaffolds the config/rbac/operator_config_role.yaml file
+type OperatorConfigRole struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *OperatorConfigRole) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join(f.RBACDir, "operator_config_role.yaml")
+	}
+	f.TemplateBody = operatorConfigRoleTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+// Update adds the role to the resources of the kustomization next to it
+func (f *OperatorConfigRole) Update() error {
+	return internal.AddToKustomization(filepath.Join(f.ProjectPath, filepath.Dir(f.Path), "kustomization.yaml"),
+		"resources", filepath.Base(f.Path))
+}
+
+const operatorConfigRoleTemplate = `# permissions to watch the configuration of the operator.
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: operator-config-role
+rules:
+- apiGroups:
+  - ""
+  resources:
+  - configmaps
+  verbs:
+  - get
+  - list
+  - watch
+`
@@ -0,0 +1,61 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/internal"
+)
+
+var _ input.File = &OperatorConfigRoleBinding{}
+
+// OperatorConfigRoleBinding scaffolds the config/rbac/operator_config_role_binding.yaml file
+type OperatorConfigRoleBinding struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *OperatorConfigRoleBinding) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join(f.RBACDir, "operator_config_role_binding.yaml")
+	}
+	f.TemplateBody = operatorConfigRoleBindingTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+// Update adds the role binding to the resources of the kustomization next to it
+func (f *OperatorConfigRoleBinding) Update() error {
+	return internal.AddToKustomization(filepath.Join(f.ProjectPath, filepath.Dir(f.Path), "kustomization.yaml"),
+		"resources", filepath.Base(f.Path))
+}
+
+const operatorConfigRoleBindingTemplate = `apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: operator-config-rolebinding
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: operator-config-role
+subjects:
+- kind: ServiceAccount
+  name: default
+  namespace: system
+`
@@ -0,0 +1,78 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	printerColumnNameRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9 -]*$`)
+	printerColumnPathRe = regexp.MustCompile(`^\.[a-zA-Z]`)
+)
+
+// printerColumnTypes are the types of the printer columns supported by the CRDs
+var printerColumnTypes = []string{"string", "integer", "number", "boolean", "date"}
+
+// PrinterColumn is a column of kubectl get for a resource, printed besides the name of the objects
+type PrinterColumn struct {
+	// Name is the header of the column, e.g. Phase
+	Name string
+
+	// JSONPath is the path of the value of the column in the objects, e.g. .status.phase
+	JSONPath string
+
+	// Type is the type of the value, one of string, integer, number, boolean and date
+	Type string
+}
+
+// ParsePrinterColumn parses a printer column of the form <name>:<JSONPath>:<type>, e.g. Phase:.status.phase:string
+func ParsePrinterColumn(value string) (*PrinterColumn, error) {
+	first, last := strings.Index(value, ":"), strings.LastIndex(value, ":")
+	if first < 0 || first == last {
+		return nil, fmt.Errorf("invalid printer column %q, expected <name>:<JSONPath>:<type>", value)
+	}
+	c := &PrinterColumn{Name: value[:first], JSONPath: value[first+1 : last], Type: value[last+1:]}
+
+	if !printerColumnNameRe.MatchString(c.Name) {
+		return nil, fmt.Errorf("invalid name %q of printer column %q, expected letters, digits, spaces and dashes "+
+			"starting with a letter, e.g. Phase", c.Name, value)
+	}
+	if !printerColumnPathRe.MatchString(c.JSONPath) {
+		return nil, fmt.Errorf("invalid JSONPath %q of printer column %q, expected a path in the objects, e.g. "+
+			".status.phase", c.JSONPath, value)
+	}
+	supported := false
+	for _, t := range printerColumnTypes {
+		supported = supported || c.Type == t
+	}
+	if !supported {
+		return nil, fmt.Errorf("invalid type %q of printer column %q, must be one of %s", c.Type, value,
+			strings.Join(printerColumnTypes, ", "))
+	}
+
+	return c, nil
+}
+
+// Marker returns the printcolumn marker of the column
+func (c *PrinterColumn) Marker() string {
+	return fmt.Sprintf("// +kubebuilder:printcolumn:name=%s,type=%s,JSONPath=%s",
+		strconv.Quote(c.Name), strconv.Quote(c.Type), strconv.Quote(c.JSONPath))
+}
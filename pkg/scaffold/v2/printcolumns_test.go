@@ -0,0 +1,59 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePrinterColumn(t *testing.T) {
+	c, err := ParsePrinterColumn("Desired Replicas:.spec.replicas:integer")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &PrinterColumn{Name: "Desired Replicas", JSONPath: ".spec.replicas", Type: "integer"}
+	if !reflect.DeepEqual(c, expected) {
+		t.Errorf("unexpected printer column %+v", c)
+	}
+	if marker := `// +kubebuilder:printcolumn:name="Desired Replicas",type="integer",JSONPath=".spec.replicas"`; c.Marker() != marker {
+		t.Errorf("expected the marker %s, got %s", marker, c.Marker())
+	}
+
+	// the quotes of the filters of the JSONPath are escaped in the marker
+	c, err = ParsePrinterColumn(`Synced:.status.conditions[?(@.type=="Synced")].status:string`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if marker := `// +kubebuilder:printcolumn:name="Synced",type="string",JSONPath=".status.conditions[?(@.type==\"Synced\")].status"`; c.Marker() != marker {
+		t.Errorf("expected the marker %s, got %s", marker, c.Marker())
+	}
+
+	for _, value := range []string{
+		"Phase",
+		"Phase:.status.phase",
+		"Phase::string",
+		":.status.phase:string",
+		"Phase:status.phase:string",
+		"Phase:.status.phase:enum",
+		`"Phase":.status.phase:string`,
+	} {
+		if _, err := ParsePrinterColumn(value); err == nil {
+			t.Errorf("%s: expected an error", value)
+		}
+	}
+}
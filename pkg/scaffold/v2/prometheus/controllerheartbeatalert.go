@@ -0,0 +1,62 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheus
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &ControllerHeartbeatAlert{}
+
+// ControllerHeartbeatAlert scaffolds a sample alerting rule for the health checks of the controllers
+type ControllerHeartbeatAlert struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *ControllerHeartbeatAlert) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "prometheus", "controller_heartbeat_alert.yaml")
+	}
+	f.TemplateBody = controllerHeartbeatAlertTemplate
+	return f.Input, nil
+}
+
+const controllerHeartbeatAlertTemplate = `
+# Sample alerting rules for the health checks of the controllers.
+# Add this file to the resources in config/prometheus/kustomization.yaml to enable them.
+apiVersion: monitoring.coreos.com/v1
+kind: PrometheusRule
+metadata:
+  labels:
+    control-plane: controller-manager
+  name: controller-heartbeat-alerts
+  namespace: system
+spec:
+  groups:
+  - name: controller-heartbeat
+    rules:
+    - alert: ControllerStuck
+      expr: controller_heartbeat_healthy == 0
+      for: 5m
+      labels:
+        severity: critical
+      annotations:
+        summary: The {{"{{"}} $labels.controller {{"}}"}} controller has not reconciled successfully for longer than its timeout.
+`
@@ -27,6 +27,10 @@ var _ input.File = &Kustomization{}
 // Kustomization scaffolds the kustomizaiton in the prometheus folder
 type Kustomization struct {
 	input.Input
+
+	// TLSMetrics indicates that the monitor verifies the certificate of the metrics endpoint, whose server name is
+	// substituted by kustomize
+	TLSMetrics bool
 }
 
 // GetInput implements input.File
@@ -40,4 +44,32 @@ func (f *Kustomization) GetInput() (input.Input, error) {
 
 const kustomizationTemplate = `resources:
 - monitor.yaml
+{{- if .TLSMetrics }}
+
+configurations:
+- kustomizeconfig.yaml
+{{- end }}
+`
+
+var _ input.File = &KustomizeConfig{}
+
+// KustomizeConfig scaffolds the kustomize configuration substituting the server name of the monitor
+type KustomizeConfig struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *KustomizeConfig) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "prometheus", "kustomizeconfig.yaml")
+	}
+	f.TemplateBody = kustomizeConfigTemplate
+	return f.Input, nil
+}
+
+const kustomizeConfigTemplate = `# This configuration is for teaching kustomize how to do var substitution
+varReference:
+- kind: ServiceMonitor
+  group: monitoring.coreos.com
+  path: spec/endpoints/tlsConfig/serverName
 `
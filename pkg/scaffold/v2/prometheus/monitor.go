@@ -20,13 +20,18 @@ import (
 	"path/filepath"
 
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/metricsauth"
 )
 
 var _ input.File = &ServiceMonitor{}
 
-// ServiceMonitor scaffolds an issuer CR and a certificate CR
+// ServiceMonitor scaffolds the prometheus monitor of the metrics endpoint
 type ServiceMonitor struct {
 	input.Input
+
+	// TLSMetrics indicates that the manager serves its metrics over TLS with the certificate of config/metrics,
+	// which the monitor verifies, rather than kube-rbac-proxy with a self-signed certificate
+	TLSMetrics bool
 }
 
 // GetInput implements input.File
@@ -51,6 +56,21 @@ spec:
   endpoints:
     - path: /metrics
       port: https
+      scheme: https
+      # Prometheus is authorized to get /metrics by binding its service account to the metrics-reader ClusterRole
+      bearerTokenFile: /var/run/secrets/kubernetes.io/serviceaccount/token
+      tlsConfig:
+{{- if .TLSMetrics }}
+        # $(METRICS_SERVICE_NAME) and $(METRICS_SERVICE_NAMESPACE) will be substituted by kustomize
+        serverName: $(METRICS_SERVICE_NAME).$(METRICS_SERVICE_NAMESPACE).svc
+        ca:
+          secret:
+            name: ` + metricsauth.CertSecretName + `
+            key: ca.crt
+{{- else }}
+        # kube-rbac-proxy serves the metrics with a self-signed certificate
+        insecureSkipVerify: true
+{{- end }}
   selector:
     control-plane: controller-manager
 `
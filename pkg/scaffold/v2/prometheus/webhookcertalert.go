@@ -0,0 +1,70 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package prometheus
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &WebhookCertAlert{}
+
+// WebhookCertAlert scaffolds a sample alerting rule for the webhook serving certificate
+type WebhookCertAlert struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *WebhookCertAlert) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "prometheus", "webhook_cert_alert.yaml")
+	}
+	f.TemplateBody = webhookCertAlertTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+const webhookCertAlertTemplate = `
+# Sample alerting rules for the webhook serving certificate.
+# Add this file to the resources in config/prometheus/kustomization.yaml to enable them.
+apiVersion: monitoring.coreos.com/v1
+kind: PrometheusRule
+metadata:
+  labels:
+    control-plane: controller-manager
+  name: webhook-cert-alerts
+  namespace: system
+spec:
+  groups:
+  - name: webhook-certificate
+    rules:
+    - alert: WebhookCertificateInvalid
+      expr: webhook_certificate_valid == 0
+      for: 5m
+      labels:
+        severity: critical
+      annotations:
+        summary: The webhook serving certificate does not match the webhook service or is about to expire.
+    - alert: WebhookCertificateExpiringSoon
+      expr: webhook_certificate_expiration_timestamp_seconds - time() < 14 * 24 * 3600
+      for: 1h
+      labels:
+        severity: warning
+      annotations:
+        summary: The webhook serving certificate expires in less than 14 days.
+`
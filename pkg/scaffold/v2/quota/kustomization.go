@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package quota scaffolds the LimitRange and the ResourceQuota of the namespace of the operator, required by the
+// cluster policies that only admit workloads into namespaces with quotas
+package quota
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+// Resources of the containers that don't set theirs, e.g. the auth proxy, matching the ones of the manager
+const (
+	DefaultCPURequest    = "100m"
+	DefaultMemoryRequest = "20Mi"
+	DefaultCPULimit      = "100m"
+	DefaultMemoryLimit   = "30Mi"
+)
+
+// Maximum resources of a container of the namespace
+const (
+	MaxCPU    = "1"
+	MaxMemory = "512Mi"
+)
+
+// Quotas of the namespace, leaving room for a rolling update of the manager which runs two pods at once
+const (
+	RequestsCPUQuota    = "1"
+	RequestsMemoryQuota = "512Mi"
+	LimitsCPUQuota      = "2"
+	LimitsMemoryQuota   = "1Gi"
+	PodsQuota           = "10"
+)
+
+var _ input.File = &Kustomization{}
+
+// Kustomization scaffolds the kustomization in the quota folder
+type Kustomization struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *Kustomization) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "quota", "kustomization.yaml")
+	}
+	f.TemplateBody = kustomizationTemplate
+	return f.Input, nil
+}
+
+const kustomizationTemplate = `# Limits the resources of the namespace of the operator, some cluster policies
+# only admit workloads into namespaces with a LimitRange and a ResourceQuota.
+resources:
+- limitrange.yaml
+- resourcequota.yaml
+`
@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &LimitRange{}
+
+// LimitRange scaffolds the LimitRange of the namespace of the operator
+type LimitRange struct {
+	input.Input
+
+	// DefaultCPURequest, DefaultMemoryRequest, DefaultCPULimit and DefaultMemoryLimit are the resources of
+	// the containers that don't set theirs
+	DefaultCPURequest, DefaultMemoryRequest, DefaultCPULimit, DefaultMemoryLimit string
+
+	// MaxCPU and MaxMemory are the maximum resources of a container
+	MaxCPU, MaxMemory string
+}
+
+// GetInput implements input.File
+func (f *LimitRange) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "quota", "limitrange.yaml")
+	}
+	if f.DefaultCPURequest == "" {
+		f.DefaultCPURequest = DefaultCPURequest
+	}
+	if f.DefaultMemoryRequest == "" {
+		f.DefaultMemoryRequest = DefaultMemoryRequest
+	}
+	if f.DefaultCPULimit == "" {
+		f.DefaultCPULimit = DefaultCPULimit
+	}
+	if f.DefaultMemoryLimit == "" {
+		f.DefaultMemoryLimit = DefaultMemoryLimit
+	}
+	if f.MaxCPU == "" {
+		f.MaxCPU = MaxCPU
+	}
+	if f.MaxMemory == "" {
+		f.MaxMemory = MaxMemory
+	}
+	f.TemplateBody = limitRangeTemplate
+	return f.Input, nil
+}
+
+const limitRangeTemplate = `# Sets the resources of the containers that don't set theirs, e.g. the auth proxy,
+# which the ResourceQuota requires, and bounds the resources of every container.
+apiVersion: v1
+kind: LimitRange
+metadata:
+  name: limit-range
+  namespace: system
+spec:
+  limits:
+  - type: Container
+    defaultRequest:
+      cpu: {{ .DefaultCPURequest }}
+      memory: {{ .DefaultMemoryRequest }}
+    default:
+      cpu: {{ .DefaultCPULimit }}
+      memory: {{ .DefaultMemoryLimit }}
+    max:
+      cpu: "{{ .MaxCPU }}"
+      memory: {{ .MaxMemory }}
+`
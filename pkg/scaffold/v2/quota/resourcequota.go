@@ -0,0 +1,76 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &ResourceQuota{}
+
+// ResourceQuota scaffolds the ResourceQuota of the namespace of the operator
+type ResourceQuota struct {
+	input.Input
+
+	// RequestsCPU, RequestsMemory, LimitsCPU and LimitsMemory are the total resources of the namespace
+	RequestsCPU, RequestsMemory, LimitsCPU, LimitsMemory string
+
+	// Pods is the maximum number of pods of the namespace
+	Pods string
+}
+
+// GetInput implements input.File
+func (f *ResourceQuota) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "quota", "resourcequota.yaml")
+	}
+	if f.RequestsCPU == "" {
+		f.RequestsCPU = RequestsCPUQuota
+	}
+	if f.RequestsMemory == "" {
+		f.RequestsMemory = RequestsMemoryQuota
+	}
+	if f.LimitsCPU == "" {
+		f.LimitsCPU = LimitsCPUQuota
+	}
+	if f.LimitsMemory == "" {
+		f.LimitsMemory = LimitsMemoryQuota
+	}
+	if f.Pods == "" {
+		f.Pods = PodsQuota
+	}
+	f.TemplateBody = resourceQuotaTemplate
+	return f.Input, nil
+}
+
+const resourceQuotaTemplate = `# Bounds the total resources of the namespace of the operator.
+# TODO(user): raise the quotas along with the resources of the manager, a rolling update runs two pods at once.
+apiVersion: v1
+kind: ResourceQuota
+metadata:
+  name: resource-quota
+  namespace: system
+spec:
+  hard:
+    requests.cpu: "{{ .RequestsCPU }}"
+    requests.memory: {{ .RequestsMemory }}
+    limits.cpu: "{{ .LimitsCPU }}"
+    limits.memory: {{ .LimitsMemory }}
+    pods: "{{ .Pods }}"
+`
@@ -27,12 +27,20 @@ var _ input.File = &KustomizeRBAC{}
 // KustomizeRBAC scaffolds the Kustomization file in rbac folder.
 type KustomizeRBAC struct {
 	input.Input
+
+	// Tenancy indicates that the tenants are isolated by namespace, the manager may be bound to their namespaces
+	// only
+	Tenancy bool
+
+	// TLSMetrics indicates that the manager authenticates and authorizes the clients of its metrics itself, rather
+	// than kube-rbac-proxy
+	TLSMetrics bool
 }
 
 // GetInput implements input.File
 func (f *KustomizeRBAC) GetInput() (input.Input, error) {
 	if f.Path == "" {
-		f.Path = filepath.Join("config", "rbac", "kustomization.yaml")
+		f.Path = filepath.Join(f.RBACDir, "kustomization.yaml")
 	}
 	f.TemplateBody = kustomizeRBACTemplate
 	f.Input.IfExistsAction = input.Error
@@ -41,9 +49,22 @@ func (f *KustomizeRBAC) GetInput() (input.Input, error) {
 
 const kustomizeRBACTemplate = `resources:
 - role.yaml
+{{- if .Tenancy }}
+# [TENANCY] To limit the manager to the namespaces of the tenants, comment the following line, bind the
+# manager role in every namespace of a tenant with config/tenant and list them in the --tenant-namespaces
+# flag of the manager.
+{{- end }}
 - role_binding.yaml
 - leader_election_role.yaml
 - leader_election_role_binding.yaml
+{{- if .TLSMetrics }}
+# The service of the /metrics endpoint, the role of the manager authenticating
+# and authorizing its clients and the role granting them to get /metrics.
+- metrics_service.yaml
+- metrics_auth_role.yaml
+- metrics_auth_role_binding.yaml
+- metrics_reader_role.yaml
+{{- else }}
 # Comment the following 4 lines if you want to disable
 # the auth proxy (https://github.com/brancz/kube-rbac-proxy)
 # which protects your /metrics endpoint.
@@ -51,4 +72,5 @@ const kustomizeRBACTemplate = `resources:
 - auth_proxy_role.yaml
 - auth_proxy_role_binding.yaml
 - auth_proxy_client_clusterrole.yaml
+{{- end }}
 `
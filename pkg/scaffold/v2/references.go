@@ -0,0 +1,192 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+)
+
+var (
+	specFieldRe     = regexp.MustCompile(`^spec\.([a-z][a-zA-Z0-9]*)$`)
+	referenceKindRe = regexp.MustCompile(`^([a-z0-9.-]+)/(v[0-9][a-z0-9]*)/([A-Za-z][a-zA-Z0-9]*)$`)
+)
+
+// clusterScopedKinds are the Kubernetes kinds whose objects are not namespaced
+var clusterScopedKinds = map[string]bool{
+	"APIService":                     true,
+	"CertificateSigningRequest":      true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"ComponentStatus":                true,
+	"CSIDriver":                      true,
+	"CSINode":                        true,
+	"MutatingWebhookConfiguration":   true,
+	"Namespace":                      true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"PodSecurityPolicy":              true,
+	"PriorityClass":                  true,
+	"RuntimeClass":                   true,
+	"StorageClass":                   true,
+	"ValidatingWebhookConfiguration": true,
+	"VolumeAttachment":               true,
+}
+
+// Reference is a field of the spec of a resource referencing an object of another kind
+type Reference struct {
+	// Field is the name of the Go field, e.g. SecretRef
+	Field string
+
+	// JSONName is the name of the field in the spec, e.g. secretRef
+	JSONName string
+
+	// Resource is the referenced resource, its group is a Kubernetes group, e.g. core, or a group of the project
+	Resource *resource.Resource
+
+	// ClusterScoped indicates that the referenced objects are not namespaced
+	ClusterScoped bool
+
+	// CrossNamespace indicates that the referenced object is in a namespace set by the reference,
+	// which is the case when the referencing resource is cluster-scoped
+	CrossNamespace bool
+
+	// Package is the Go package of the referenced resource, set when scaffolding the controller
+	Package string
+
+	// GroupDomain is the API group of the referenced resource, set when scaffolding the controller
+	GroupDomain string
+}
+
+// ParseReference parses a reference of the form spec.<field>:<group>/<version>/<Kind>, e.g.
+// spec.secretRef:core/v1/Secret, from a resource whose objects are namespaced or not
+func ParseReference(value string, namespaced bool) (*Reference, error) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid reference %q, expected spec.<field>:<group>/<version>/<Kind>", value)
+	}
+
+	field := specFieldRe.FindStringSubmatch(parts[0])
+	if field == nil {
+		return nil, fmt.Errorf("invalid reference field %q, expected a lower camel case field of the spec, "+
+			"e.g. spec.secretRef", parts[0])
+	}
+	kind := referenceKindRe.FindStringSubmatch(parts[1])
+	if kind == nil {
+		return nil, fmt.Errorf("invalid referenced kind %q, expected <group>/<version>/<Kind>, e.g. core/v1/Secret",
+			parts[1])
+	}
+
+	// the referenced kinds are not CRDs of the project, they are often built-in kinds
+	r := &resource.Resource{Group: kind[1], Version: kind[2], Kind: kind[3], AllowReservedGroup: true}
+	if err := r.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid referenced kind %q: %v", parts[1], err)
+	}
+
+	ref := &Reference{
+		Field:         goName(field[1]),
+		JSONName:      field[1],
+		Resource:      r,
+		ClusterScoped: clusterScopedKinds[r.Kind],
+	}
+	ref.CrossNamespace = !namespaced && !ref.ClusterScoped
+	return ref, nil
+}
+
+// Type returns the Go type of the reference field
+func (r *Reference) Type() string {
+	if r.CrossNamespace {
+		return "NamespacedObjectReference"
+	}
+	return "corev1.LocalObjectReference"
+}
+
+// Var returns the name of the variable holding the referenced object
+func (r *Reference) Var() string {
+	return r.JSONName
+}
+
+// hasLocalReference returns true if any of refs is a corev1.LocalObjectReference
+func hasLocalReference(refs []*Reference) bool {
+	for _, ref := range refs {
+		if !ref.CrossNamespace {
+			return true
+		}
+	}
+	return false
+}
+
+// HasCrossNamespaceReference returns true if any of refs references an object in another namespace
+func HasCrossNamespaceReference(refs []*Reference) bool {
+	for _, ref := range refs {
+		if ref.CrossNamespace {
+			return true
+		}
+	}
+	return false
+}
+
+var _ input.File = &ReferenceTypes{}
+
+// ReferenceTypes scaffolds the api/<version>/reference_types.go shared by the references of every kind in the version
+type ReferenceTypes struct {
+	input.Input
+
+	// Resource is a resource in the API group
+	Resource *resource.Resource
+}
+
+// GetInput implements input.File
+func (f *ReferenceTypes) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		if f.MultiGroup {
+			f.Path = filepath.Join("apis", f.Resource.Group, f.Resource.Version, "reference_types.go")
+		} else {
+			f.Path = filepath.Join("api", f.Resource.Version, "reference_types.go")
+		}
+	}
+	f.TemplateBody = referenceTypesTemplate
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *ReferenceTypes) Validate() error {
+	return f.Resource.Validate()
+}
+
+const referenceTypesTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}
+
+// NamespacedObjectReference references an object by its namespace and name
+type NamespacedObjectReference struct {
+	// Namespace of the referenced object
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	Namespace string ` + "`" + `json:"namespace"` + "`" + `
+
+	// Name of the referenced object
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	Name string ` + "`" + `json:"name"` + "`" + `
+}
+`
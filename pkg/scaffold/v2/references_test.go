@@ -0,0 +1,65 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		value      string
+		namespaced bool
+		field      string
+		kind       string
+		typ        string
+		cluster    bool
+	}{
+		{value: "spec.secretRef:core/v1/Secret", namespaced: true,
+			field: "SecretRef", kind: "Secret", typ: "corev1.LocalObjectReference"},
+		{value: "spec.secretRef:core/v1/Secret", namespaced: false,
+			field: "SecretRef", kind: "Secret", typ: "NamespacedObjectReference"},
+		{value: "spec.nodeRef:core/v1/Node", namespaced: false,
+			field: "NodeRef", kind: "Node", typ: "corev1.LocalObjectReference", cluster: true},
+		{value: "spec.captainRef:crew/v1/Captain", namespaced: true,
+			field: "CaptainRef", kind: "Captain", typ: "corev1.LocalObjectReference"},
+	}
+	for _, test := range tests {
+		ref, err := ParseReference(test.value, test.namespaced)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", test.value, err)
+			continue
+		}
+		if ref.Field != test.field || ref.Resource.Kind != test.kind || ref.Type() != test.typ ||
+			ref.ClusterScoped != test.cluster {
+			t.Errorf("%s (namespaced: %v): unexpected reference %+v of type %s",
+				test.value, test.namespaced, ref, ref.Type())
+		}
+	}
+
+	for _, value := range []string{
+		"spec.secretRef",
+		"status.secretRef:core/v1/Secret",
+		"spec.secret.ref:core/v1/Secret",
+		"spec.secretRef:core/Secret",
+		"spec.secretRef:core/v1/secret",
+	} {
+		if _, err := ParseReference(value, true); err == nil {
+			t.Errorf("%s: expected an error", value)
+		}
+	}
+}
@@ -0,0 +1,469 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaletest
+
+import (
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+const makefileTarget = `# Create SCALE_TEST_OBJECTS objects of every kind with a scale test at SCALE_TEST_RATE per second in a test
+# environment, or in the configured Kubernetes cluster in ~/.kube/config when SCALE_TEST_METRICS_URL is set to the
+# metrics of its manager, and write the report of their reconciles to scale-test-report.json
+scale-test: generate fmt vet manifests
+	SCALE_TEST=true SCALE_TEST_REPORT=$(CURDIR)/scale-test-report.json go test ./scaletest/... -v -timeout 1h
+
+`
+
+var _ input.File = &Harness{}
+
+// Harness scaffolds the harness creating objects at a given rate and reporting the latency of their reconciles
+type Harness struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *Harness) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("scaletest", "harness.go")
+	}
+	f.TemplateBody = harnessTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+// Update adds the scale-test target to the Makefile, before the docker targets, unless it already has it
+func (f *Harness) Update() error {
+	path := filepath.Join(f.ProjectPath, "Makefile")
+	in, err := filesystem.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	content := string(in)
+	if strings.Contains(content, "\nscale-test:") {
+		return nil
+	}
+	if i := strings.Index(content, "# Build the docker image"); i >= 0 {
+		content = content[:i] + makefileTarget + content[i:]
+	} else {
+		content += "\n" + strings.TrimSuffix(makefileTarget, "\n")
+	}
+	return filesystem.WriteFile(path, []byte(content), 0644)
+}
+
+var _ input.File = &HarnessTest{}
+
+// HarnessTest scaffolds the tests of the estimation of the latencies of the reconciles from their metrics
+type HarnessTest struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *HarnessTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("scaletest", "harness_test.go")
+	}
+	f.TemplateBody = harnessTestTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+const harnessTemplate = `{{ .Boilerplate }}
+
+// Package scaletest creates objects at a given rate in a test environment or in a cluster and reports the latency
+// of their reconciles, as measured by the metrics of the controller reconciling them.
+package scaletest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	reconcileTimeMetric   = "controller_runtime_reconcile_time_seconds"
+	reconcileErrorsMetric = "controller_runtime_reconcile_errors_total"
+)
+
+// Options configures a scale test
+type Options struct {
+	// Objects is the number of objects created
+	Objects int
+
+	// Rate is the number of objects created per second
+	Rate float64
+
+	// Timeout bounds the time to create the objects and reconcile them
+	Timeout time.Duration
+
+	// MetricsURL is the URL of the metrics of a manager running in a cluster, e.g. http://localhost:8080/metrics
+	// when port-forwarding its pod. The metrics of the controllers running in the test are read if empty.
+	MetricsURL string
+}
+
+// OptionsFromEnv returns the options set by the SCALE_TEST_OBJECTS, SCALE_TEST_RATE, SCALE_TEST_TIMEOUT and
+// SCALE_TEST_METRICS_URL environment variables, 100 objects created at 10 per second within 5 minutes by default
+func OptionsFromEnv() (Options, error) {
+	o := Options{Objects: 100, Rate: 10, Timeout: 5 * time.Minute, MetricsURL: os.Getenv("SCALE_TEST_METRICS_URL")}
+	if v := os.Getenv("SCALE_TEST_OBJECTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return Options{}, fmt.Errorf("invalid SCALE_TEST_OBJECTS %q, must be a positive number", v)
+		}
+		o.Objects = n
+	}
+	if v := os.Getenv("SCALE_TEST_RATE"); v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil || rate <= 0 {
+			return Options{}, fmt.Errorf("invalid SCALE_TEST_RATE %q, must be a positive number of objects per second", v)
+		}
+		o.Rate = rate
+	}
+	if v := os.Getenv("SCALE_TEST_TIMEOUT"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil || timeout <= 0 {
+			return Options{}, fmt.Errorf("invalid SCALE_TEST_TIMEOUT %q, must be a positive duration, e.g. 10m", v)
+		}
+		o.Timeout = timeout
+	}
+	return o, nil
+}
+
+// Report reports the reconciles of the objects created by a scale test
+type Report struct {
+	// Controller is the name of the controller reconciling the objects, e.g. the lower case name of their kind
+	Controller string ` + "`" + `json:"controller"` + "`" + `
+
+	// Objects is the number of objects created
+	Objects int ` + "`" + `json:"objects"` + "`" + `
+
+	// Rate is the number of objects created per second, as measured
+	Rate float64 ` + "`" + `json:"rate"` + "`" + `
+
+	// Duration is the time to create the objects and reconcile them, in seconds
+	Duration float64 ` + "`" + `json:"durationSeconds"` + "`" + `
+
+	// Reconciles and ReconcileErrors are the number of reconciles of the controller during the test, and of
+	// reconciles returning an error
+	Reconciles      uint64 ` + "`" + `json:"reconciles"` + "`" + `
+	ReconcileErrors uint64 ` + "`" + `json:"reconcileErrors"` + "`" + `
+
+	// Mean, P50, P90 and P99 are the mean and the quantiles of the latency of the reconciles, in seconds. The
+	// quantiles are estimated from the buckets of the histogram of the latencies, as Prometheus does.
+	Mean float64 ` + "`" + `json:"meanSeconds"` + "`" + `
+	P50  float64 ` + "`" + `json:"p50Seconds"` + "`" + `
+	P90  float64 ` + "`" + `json:"p90Seconds"` + "`" + `
+	P99  float64 ` + "`" + `json:"p99Seconds"` + "`" + `
+}
+
+// String returns a summary of the report
+func (r *Report) String() string {
+	return fmt.Sprintf("%d objects created at %.1f/s and reconciled by the %s controller in %.1fs: "+
+		"%d reconciles, %d errors, latency mean %s, p50 %s, p90 %s, p99 %s",
+		r.Objects, r.Rate, r.Controller, r.Duration, r.Reconciles, r.ReconcileErrors,
+		seconds(r.Mean), seconds(r.P50), seconds(r.P90), seconds(r.P99))
+}
+
+// WriteFile writes the report in JSON to the file
+func (r *Report) WriteFile(path string) error {
+	b, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(b, '\n'), 0644)
+}
+
+// Run creates the objects returned by newObject at the rate of the options, waits until the controller reconciled
+// them at least as many times and reports its reconciles meanwhile. The objects are deleted once reconciled.
+func Run(ctx context.Context, c client.Client, controller string, newObject func(i int) runtime.Object,
+	o Options) (*Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, o.Timeout)
+	defer cancel()
+
+	before, err := o.reconciles(ctx, controller)
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]runtime.Object, 0, o.Objects)
+	defer func() {
+		for _, obj := range created {
+			_ = client.IgnoreNotFound(c.Delete(context.Background(), obj))
+		}
+	}()
+
+	start := time.Now()
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / o.Rate))
+	defer ticker.Stop()
+	for i := 0; i < o.Objects; i++ {
+		if i > 0 {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return nil, fmt.Errorf("%d of %d objects created: %v", i, o.Objects, ctx.Err())
+			}
+		}
+		obj := newObject(i)
+		if err := c.Create(ctx, obj); err != nil {
+			return nil, fmt.Errorf("unable to create the object %d: %v", i, err)
+		}
+		created = append(created, obj)
+	}
+	creation := time.Since(start)
+
+	for {
+		after, err := o.reconciles(ctx, controller)
+		if err != nil {
+			return nil, err
+		}
+		if after.count-before.count >= uint64(o.Objects) {
+			return newReport(controller, o.Objects, creation, time.Since(start), after.sub(before)), nil
+		}
+		select {
+		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%d reconciles of %d objects: %v", after.count-before.count, o.Objects, ctx.Err())
+		}
+	}
+}
+
+// bucket is a bucket of the histogram of the latencies of the reconciles
+type bucket struct {
+	upperBound float64
+	count      uint64
+}
+
+// reconciles are the metrics of the reconciles of a controller
+type reconciles struct {
+	count   uint64
+	sum     float64
+	errors  uint64
+	buckets []bucket
+}
+
+// sub returns the reconciles since the previous ones
+func (r reconciles) sub(previous reconciles) reconciles {
+	d := reconciles{count: r.count - previous.count, sum: r.sum - previous.sum, errors: r.errors - previous.errors}
+	for i, b := range r.buckets {
+		if i < len(previous.buckets) {
+			b.count -= previous.buckets[i].count
+		}
+		d.buckets = append(d.buckets, b)
+	}
+	return d
+}
+
+// quantile estimates the quantile of the latencies by interpolating linearly in the bucket it falls in
+func (r reconciles) quantile(q float64) float64 {
+	if r.count == 0 {
+		return 0
+	}
+	rank := q * float64(r.count)
+	lower, below := 0.0, uint64(0)
+	for _, b := range r.buckets {
+		if float64(b.count) >= rank {
+			if math.IsInf(b.upperBound, 1) {
+				// the latency exceeds every bucket, the last bound is the best estimate
+				return lower
+			}
+			if b.count == below {
+				return b.upperBound
+			}
+			return lower + (b.upperBound-lower)*(rank-float64(below))/float64(b.count-below)
+		}
+		lower, below = b.upperBound, b.count
+	}
+	return lower
+}
+
+// reconciles returns the metrics of the reconciles of the controller
+func (o Options) reconciles(ctx context.Context, controller string) (reconciles, error) {
+	families, err := o.gather(ctx)
+	if err != nil {
+		return reconciles{}, fmt.Errorf("unable to read the metrics: %v", err)
+	}
+
+	var r reconciles
+	for _, family := range families {
+		for _, m := range family.GetMetric() {
+			if !hasLabel(m, "controller", controller) {
+				continue
+			}
+			switch family.GetName() {
+			case reconcileTimeMetric:
+				h := m.GetHistogram()
+				r.count, r.sum = h.GetSampleCount(), h.GetSampleSum()
+				for _, b := range h.GetBucket() {
+					r.buckets = append(r.buckets, bucket{upperBound: b.GetUpperBound(), count: b.GetCumulativeCount()})
+				}
+			case reconcileErrorsMetric:
+				r.errors = uint64(m.GetCounter().GetValue())
+			}
+		}
+	}
+	// the count of the implicit +Inf bucket is the count of the histogram
+	if n := len(r.buckets); n == 0 || !math.IsInf(r.buckets[n-1].upperBound, 1) {
+		r.buckets = append(r.buckets, bucket{upperBound: math.Inf(1), count: r.count})
+	}
+	return r, nil
+}
+
+// gather returns the metrics of the manager at the metrics URL, or of the controllers running in the test
+func (o Options) gather(ctx context.Context) ([]*dto.MetricFamily, error) {
+	if o.MetricsURL == "" {
+		return metrics.Registry.Gather()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, o.MetricsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s from %s", resp.Status, o.MetricsURL)
+	}
+	parsed, err := (&expfmt.TextParser{}).TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	families := make([]*dto.MetricFamily, 0, len(parsed))
+	for _, family := range parsed {
+		families = append(families, family)
+	}
+	return families, nil
+}
+
+// hasLabel returns true if the metric has the label with the value
+func hasLabel(m *dto.Metric, name, value string) bool {
+	for _, l := range m.GetLabel() {
+		if l.GetName() == name {
+			return l.GetValue() == value
+		}
+	}
+	return false
+}
+
+// newReport returns the report of the reconciles during a scale test
+func newReport(controller string, objects int, creation, duration time.Duration, r reconciles) *Report {
+	report := &Report{
+		Controller:      controller,
+		Objects:         objects,
+		Duration:        duration.Seconds(),
+		Reconciles:      r.count,
+		ReconcileErrors: r.errors,
+		P50:             r.quantile(0.5),
+		P90:             r.quantile(0.9),
+		P99:             r.quantile(0.99),
+	}
+	if objects > 1 && creation > 0 {
+		report.Rate = float64(objects-1) / creation.Seconds()
+	}
+	if r.count > 0 {
+		report.Mean = r.sum / float64(r.count)
+	}
+	return report
+}
+
+// seconds formats a number of seconds as a duration, e.g. 12.5ms
+func seconds(s float64) string {
+	return time.Duration(s * float64(time.Second)).Round(time.Microsecond).String()
+}
+`
+
+const harnessTestTemplate = `{{ .Boilerplate }}
+
+package scaletest
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestQuantile(t *testing.T) {
+	// 100 reconciles: 50 within 10ms, 40 within 100ms and 10 within 1s
+	r := reconciles{count: 100, sum: 3, buckets: []bucket{
+		{upperBound: 0.01, count: 50},
+		{upperBound: 0.1, count: 90},
+		{upperBound: 1, count: 100},
+		{upperBound: math.Inf(1), count: 100},
+	}}
+	for q, expected := range map[float64]float64{0.5: 0.01, 0.7: 0.055, 0.95: 0.55, 0.99: 0.91} {
+		if actual := r.quantile(q); math.Abs(actual-expected) > 1e-9 {
+			t.Errorf("expected the quantile %v to be %v, got %v", q, expected, actual)
+		}
+	}
+
+	if actual := (reconciles{}).quantile(0.99); actual != 0 {
+		t.Errorf("expected no latency without reconciles, got %v", actual)
+	}
+}
+
+func TestReconcilesFromMetricsURL(t *testing.T) {
+	const controller = "scaletest"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, ` + "`" + `# TYPE controller_runtime_reconcile_time_seconds histogram
+controller_runtime_reconcile_time_seconds_bucket{controller="%[1]s",le="0.01"} 3
+controller_runtime_reconcile_time_seconds_bucket{controller="%[1]s",le="0.1"} 4
+controller_runtime_reconcile_time_seconds_bucket{controller="%[1]s",le="+Inf"} 4
+controller_runtime_reconcile_time_seconds_sum{controller="%[1]s"} 0.2
+controller_runtime_reconcile_time_seconds_count{controller="%[1]s"} 4
+controller_runtime_reconcile_time_seconds_bucket{controller="other",le="+Inf"} 7
+controller_runtime_reconcile_time_seconds_sum{controller="other"} 1
+controller_runtime_reconcile_time_seconds_count{controller="other"} 7
+# TYPE controller_runtime_reconcile_errors_total counter
+controller_runtime_reconcile_errors_total{controller="%[1]s"} 1
+` + "`" + `, controller)
+	}))
+	defer server.Close()
+
+	r, err := Options{MetricsURL: server.URL}.reconciles(context.Background(), controller)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.count != 4 || r.errors != 1 || len(r.buckets) != 3 {
+		t.Errorf("expected 4 reconciles, 1 error and 3 buckets, got %+v", r)
+	}
+
+	report := newReport(controller, 4, 0, 0, r.sub(reconciles{}))
+	if math.Abs(report.Mean-0.05) > 1e-9 || math.Abs(report.P50-0.01*2/3) > 1e-9 {
+		t.Errorf("expected a mean latency of 50ms and a p50 of 6.7ms, got %+v", report)
+	}
+}
+`
@@ -0,0 +1,296 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaletest
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+)
+
+var _ input.File = &KindTest{}
+
+// KindTest scaffolds the scale test of the controller of a Resource
+type KindTest struct {
+	input.Input
+
+	// Resource is the Resource whose objects are created
+	Resource *resource.Resource
+
+	// ResourcePackage is the package of the Resource
+	ResourcePackage string
+
+	// ControllersImport is the import of the package of the controller of the Resource
+	ControllersImport string
+
+	// ControllersPackage is the name of the package of the controller of the Resource in the test
+	ControllersPackage string
+
+	// ReconcilerName is the name of the reconciler type of the Resource
+	ReconcilerName string
+
+	// TestName is the name of the Resource in the names of the test functions, prefixed by its group in
+	// multigroup projects where kinds of different groups share the package of the test
+	TestName string
+
+	// CRDDirectoryPath are the quoted elements of the path of the CRDs relative to the test
+	CRDDirectoryPath string
+
+	// SamplePath is the slash separated path of the sample of the Resource relative to the test
+	SamplePath string
+
+	// HealthCheck and WatchConfig indicate that the constructor of the reconciler takes its heartbeat and the
+	// configuration watcher, which the test leaves nil
+	HealthCheck bool
+	WatchConfig bool
+}
+
+// GetInput implements input.File
+func (f *KindTest) GetInput() (input.Input, error) {
+	f.ResourcePackage, _ = util.GetResourceInfo(f.ProjectPath, f.Resource, f.Repo, f.Domain, f.MultiGroup)
+	f.ReconcilerName = f.Naming.ReconcilerName(f.Resource.Kind)
+
+	name := f.Naming.FileName(f.Resource.Kind)
+	f.TestName = f.Resource.Kind
+	f.ControllersPackage = "controllers"
+	f.ControllersImport = strconv.Quote(path.Join(f.Repo, "controllers"))
+	if f.MultiGroup {
+		name = f.Resource.Group + "_" + name
+		f.TestName = strings.Title(f.Resource.GroupImportSafe) + f.Resource.Kind
+		f.ControllersPackage = "controller" + f.Resource.GroupImportSafe
+		f.ControllersImport = f.ControllersPackage + " " +
+			strconv.Quote(path.Join(f.Repo, "controllers", f.Resource.Group))
+	}
+
+	if f.Path == "" {
+		f.Path = filepath.Join("scaletest", name+"_scale_test.go")
+	}
+
+	controllerPath := filepath.Join("controllers", f.Naming.FileName(f.Resource.Kind)+"_controller.go")
+	if f.MultiGroup {
+		controllerPath = filepath.Join("controllers", f.Resource.Group,
+			f.Naming.FileName(f.Resource.Kind)+"_controller.go")
+	}
+	f.HealthCheck, f.WatchConfig = constructorDependencies(filepath.Join(f.ProjectPath, controllerPath))
+
+	rel, err := filepath.Rel(filepath.Dir(f.Path), filepath.Join(f.CRDDir, "bases"))
+	if err != nil {
+		return input.Input{}, err
+	}
+	var elems []string
+	for _, elem := range strings.Split(filepath.ToSlash(rel), "/") {
+		elems = append(elems, strconv.Quote(elem))
+	}
+	f.CRDDirectoryPath = strings.Join(elems, ", ")
+
+	if f.SamplePath == "" {
+		sample := filepath.Join("config", "samples", fmt.Sprintf(
+			"%s_%s_%s.yaml", f.Resource.Group, f.Resource.Version, f.Naming.FileName(f.Resource.Kind)))
+		rel, err := filepath.Rel(filepath.Dir(f.Path), sample)
+		if err != nil {
+			return input.Input{}, err
+		}
+		f.SamplePath = filepath.ToSlash(rel)
+	}
+
+	f.TemplateBody = kindTestTemplate
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *KindTest) Validate() error {
+	return f.Resource.Validate()
+}
+
+// constructorDependencies returns whether the constructor of the reconciler in the controller file takes a
+// heartbeat and a configuration watcher, as scaffolded with --health-check and --watch-config
+func constructorDependencies(path string) (heartbeat, config bool) {
+	controller, err := filesystem.ReadFile(path)
+	if err != nil {
+		return false, false
+	}
+	return strings.Contains(string(controller), "hb *heartbeat.Heartbeat"),
+		strings.Contains(string(controller), "config *operatorconfig.Watcher")
+}
+
+// nolint:lll
+const kindTestTemplate = `{{ .Boilerplate }}
+
+package scaletest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+
+	{{ .Resource.GroupImportSafe }}{{ .Resource.Version }} "{{ .ResourcePackage }}/{{ .Resource.Version }}"
+	{{ .ControllersImport }}
+)
+
+// The scale test is skipped by default, run it with make scale-test or:
+//
+//   SCALE_TEST=true go test ./scaletest -run Test{{ .TestName }}Scale -v
+//
+// The objects are reconciled by the controller running in a test environment unless SCALE_TEST_METRICS_URL is
+// set to the metrics of a manager running in the cluster of the current kubeconfig, e.g.
+// http://localhost:8080/metrics when port-forwarding its pod. See OptionsFromEnv for the other options.
+const (
+	// {{ lower .TestName }}ScaleTestP99Budget is the p99 latency the reconciles must complete within.
+	// TODO(user): set the latency your users are promised.
+	{{ lower .TestName }}ScaleTestP99Budget = time.Second
+)
+
+func Test{{ .TestName }}Scale(t *testing.T) {
+	if os.Getenv("SCALE_TEST") == "" {
+		t.Skip("set SCALE_TEST to run the scale test")
+	}
+	o, err := OptionsFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatalf("unable to add the built-in types to the scheme: %v", err)
+	}
+	if err := {{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.AddToScheme(s); err != nil {
+		t.Fatalf("unable to add types to the scheme: %v", err)
+	}
+
+	var cfg *rest.Config
+	if o.MetricsURL != "" {
+		// the objects are reconciled by the manager running in the cluster
+		if cfg, err = ctrl.GetConfig(); err != nil {
+			t.Fatalf("unable to get the configuration of the cluster: %v", err)
+		}
+	} else {
+		testEnv := &envtest.Environment{
+			CRDDirectoryPaths: []string{filepath.Join({{ .CRDDirectoryPath }})},
+		}
+		if cfg, err = testEnv.Start(); err != nil {
+			t.Fatalf("unable to start the test environment: %v", err)
+		}
+		defer func() {
+			if err := testEnv.Stop(); err != nil {
+				t.Errorf("unable to stop the test environment: %v", err)
+			}
+		}()
+
+		mgr, err := ctrl.NewManager(cfg, ctrl.Options{Scheme: s, MetricsBindAddress: "0"})
+		if err != nil {
+			t.Fatalf("unable to create the manager: %v", err)
+		}
+		// TODO(user): pass the other dependencies of the reconciler as main.go does.
+		err = {{ .ControllersPackage }}.New{{ .ReconcilerName }}(
+			mgr.GetClient(),
+			ctrl.Log.WithName("controllers").WithName("{{ .Resource.Kind }}"),
+			mgr.GetScheme(),
+			mgr.GetEventRecorderFor("{{ lower .Resource.Kind }}-controller"),
+			clock.RealClock{},{{ if .HealthCheck }}
+			nil,{{ end }}{{ if .WatchConfig }}
+			nil,{{ end }}
+		).SetupWithManager(mgr)
+		if err != nil {
+			t.Fatalf("unable to create the controller: %v", err)
+		}
+		stop := make(chan struct{})
+		defer close(stop)
+		go func() {
+			if err := mgr.Start(stop); err != nil {
+				t.Errorf("unable to run the manager: %v", err)
+			}
+		}()
+	}
+
+	c, err := client.New(cfg, client.Options{Scheme: s})
+	if err != nil {
+		t.Fatalf("unable to create the client: %v", err)
+	}
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "scale-test-"}}
+	if err := c.Create(context.Background(), ns); err != nil {
+		t.Fatalf("unable to create the namespace of the test: %v", err)
+	}
+	defer func() {
+		if err := client.IgnoreNotFound(c.Delete(context.Background(), ns)); err != nil {
+			t.Errorf("unable to delete the namespace of the test: %v", err)
+		}
+	}()
+
+	sample := {{ lower .TestName }}ScaleTestSample(t)
+	report, err := Run(context.Background(), c, "{{ lower .Resource.Kind }}", func(i int) runtime.Object {
+		obj := sample.DeepCopy()
+		obj.Name = fmt.Sprintf("%s-%d", sample.Name, i)
+		obj.Namespace = ns.Name
+		return obj
+	}, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(report)
+	if path := os.Getenv("SCALE_TEST_REPORT"); path != "" {
+		if err := report.WriteFile(path); err != nil {
+			t.Errorf("unable to write the report: %v", err)
+		}
+	}
+
+	if report.ReconcileErrors > 0 {
+		t.Errorf("%d reconciles failed", report.ReconcileErrors)
+	}
+	if p99 := time.Duration(report.P99 * float64(time.Second)); p99 > {{ lower .TestName }}ScaleTestP99Budget {
+		t.Errorf("p99 latency %v exceeds the budget of %v", p99, {{ lower .TestName }}ScaleTestP99Budget)
+	}
+}
+
+// {{ lower .TestName }}ScaleTestSample returns the object the created ones are copies of, the sample by default.
+// TODO(user): return an object representative of the objects of your users, e.g. with the largest spec they are
+// expected to have.
+func {{ lower .TestName }}ScaleTestSample(t *testing.T) *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }} {
+	b, err := ioutil.ReadFile(filepath.FromSlash("{{ .SamplePath }}"))
+	if err != nil {
+		t.Fatalf("unable to read the sample: %v", err)
+	}
+	sample := &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{}
+	if err := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(b), len(b)).Decode(sample); err != nil {
+		t.Fatalf("unable to decode the sample: %v", err)
+	}
+	return sample
+}
+`
@@ -0,0 +1,91 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/internal"
+)
+
+var _ input.File = &Scheme{}
+
+// Scheme scaffolds the apis/scheme package of a multigroup project, adding the types of every group and version of
+// the project to a scheme for the consumers of the API to register all of them with one import
+type Scheme struct {
+	input.Input
+
+	// Resource is the resource whose version is added by Update
+	Resource *resource.Resource
+}
+
+// GetInput implements input.File
+func (f *Scheme) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("apis", "scheme", "scheme.go")
+	}
+	f.TemplateBody = schemeTemplate
+	f.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+// Update adds the types of the version of the resource to the scheme of a multigroup project, the versions are
+// added once
+func (f *Scheme) Update() error {
+	if !f.MultiGroup {
+		return nil
+	}
+	if f.Path == "" {
+		f.Path = filepath.Join("apis", "scheme", "scheme.go")
+	}
+
+	pkg, _ := util.GetResourceInfo(f.ProjectPath, f.Resource, f.Repo, f.Domain, true)
+	alias := f.Resource.GroupImportSafe + f.Resource.Version
+
+	return internal.InsertStringsInFile(filepath.Join(f.ProjectPath, f.Path),
+		map[string][]string{
+			APIPkgImportScaffoldMarker: {fmt.Sprintf("%s %q\n", alias, pkg+"/"+f.Resource.Version)},
+			APISchemeScaffoldMarker:    {fmt.Sprintf("builder.Register(%s.AddToScheme)\n", alias)},
+		})
+}
+
+const schemeTemplate = `{{ .Boilerplate }}
+
+// Package scheme adds the types of every group and version of the API to a scheme, for the consumers of the API,
+// e.g. the clients of other operators, to register all of them with one import:
+//
+//	_ = scheme.AddToScheme(s)
+//
+// The groups and versions are added by kubebuilder create api.
+package scheme
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	// +kubebuilder:scaffold:imports
+)
+
+// AddToScheme adds the types of every group and version of the API to the scheme
+func AddToScheme(s *runtime.Scheme) error {
+	var builder runtime.SchemeBuilder
+	// +kubebuilder:scaffold:scheme
+	return builder.AddToScheme(s)
+}
+`
@@ -0,0 +1,83 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+)
+
+func TestSchemeUpdate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scheme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.MkdirAll(filepath.Join(dir, "apis", "scheme"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := strings.Replace(schemeTemplate, "{{ .Boilerplate }}", "", 1)
+	if err := ioutil.WriteFile(filepath.Join(dir, "apis", "scheme", "scheme.go"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the versions are added once, whatever the number of their kinds
+	for _, r := range []*resource.Resource{
+		{Group: "crew", Version: "v1", Kind: "Captain"},
+		{Group: "crew", Version: "v1", Kind: "FirstMate"},
+		{Group: "ship", Version: "v1beta1", Kind: "Frigate"},
+	} {
+		if err := r.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		f := &Scheme{
+			Input: input.Input{ProjectPath: dir, Repo: "example.org/project", Domain: "example.org",
+				MultiGroup: true},
+			Resource: r,
+		}
+		if err := f.Update(); err != nil {
+			t.Fatalf("%s: unexpected error: %v", r.Kind, err)
+		}
+	}
+
+	// the scheme package is only scaffolded for the multigroup projects
+	single := &Scheme{Input: input.Input{ProjectPath: dir}, Resource: &resource.Resource{Group: "crew"}}
+	if err := single.Update(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "apis", "scheme", "scheme.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for expected, count := range map[string]int{
+		`crewv1 "example.org/project/apis/crew/v1"`:           1,
+		`shipv1beta1 "example.org/project/apis/ship/v1beta1"`: 1,
+		"builder.Register(crewv1.AddToScheme)":                1,
+		"builder.Register(shipv1beta1.AddToScheme)":           1,
+	} {
+		if n := strings.Count(string(b), expected); n != count {
+			t.Errorf("scheme.go contains %s %d times instead of %d:\n%s", expected, n, count, b)
+		}
+	}
+}
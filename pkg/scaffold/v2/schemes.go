@@ -0,0 +1,178 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/internal"
+)
+
+// RegisterSchemes registers the types of the API group versions defined outside of the project in the scheme of
+// main.go and in the ones of the suites of the controllers of the project in dir, and requires their modules in
+// go.mod, along with the replacements they need. The group versions already registered are skipped.
+func RegisterSchemes(dir string, c *config.Config, schemes []config.Scheme) error {
+	if len(schemes) == 0 {
+		return nil
+	}
+
+	mainPath := filepath.Join(dir, "main.go")
+	if _, err := filesystem.Stat(mainPath); err == nil {
+		if err := registerSchemesIn(mainPath, c, schemes, func(alias string) string {
+			return fmt.Sprintf("_ = %s.AddToScheme(scheme)\n", alias)
+		}); err != nil {
+			return fmt.Errorf("error registering the schemes in main.go: %v", err)
+		}
+	}
+
+	if err := RegisterSchemesInSuites(dir, c, schemes); err != nil {
+		return err
+	}
+
+	goModPath := filepath.Join(dir, "go.mod")
+	for _, s := range schemes {
+		if s.Module == "" {
+			continue
+		}
+		if err := requireModule(goModPath, s.Module, s.ModuleVersion); err != nil {
+			return fmt.Errorf("error requiring %s in go.mod: %v", s.Module, err)
+		}
+		modules := make([]string, 0, len(s.Replace))
+		for module := range s.Replace {
+			modules = append(modules, module)
+		}
+		sort.Strings(modules)
+		for _, module := range modules {
+			if err := replaceModule(goModPath, module, s.Replace[module]); err != nil {
+				return fmt.Errorf("error replacing %s in go.mod: %v", module, err)
+			}
+		}
+	}
+	return nil
+}
+
+// RegisterSchemesInSuites registers the types of the API group versions defined outside of the project in the
+// schemes of the suites of the controllers of the project in dir, the test environment of every suite reading
+// and writing their objects
+func RegisterSchemesInSuites(dir string, c *config.Config, schemes []config.Scheme) error {
+	pattern := filepath.Join(dir, "controllers", "suite_test.go")
+	if c.MultiGroup {
+		pattern = filepath.Join(dir, "controllers", "*", "suite_test.go")
+	}
+	suites, err := afero.Glob(filesystem.FS, pattern)
+	if err != nil {
+		return err
+	}
+	for _, suite := range suites {
+		if err := registerSchemesIn(suite, c, schemes, func(alias string) string {
+			return fmt.Sprintf(`err = %s.AddToScheme(scheme.Scheme)
+Expect(err).NotTo(HaveOccurred())
+
+`, alias)
+		}); err != nil {
+			return fmt.Errorf("error registering the schemes in %s: %v", suite, err)
+		}
+	}
+	return nil
+}
+
+// registerSchemesIn inserts the imports of the packages of the group versions and their registration at the
+// markers of the go file, unless the file already registers them. The packages are imported as named by the
+// project, see config.Config.SchemeImportAlias
+func registerSchemesIn(path string, c *config.Config, schemes []config.Scheme,
+	register func(alias string) string) error {
+	content, err := filesystem.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var imports, registrations []string
+	for _, s := range schemes {
+		alias := c.SchemeImportAlias(s)
+		if strings.Contains(string(content), alias+".AddToScheme(") {
+			continue
+		}
+		imports = append(imports, fmt.Sprintf("%s %q\n", alias, s.Package))
+		registrations = append(registrations, register(alias))
+	}
+	if len(imports) == 0 {
+		return nil
+	}
+
+	return internal.InsertStringsInFile(path,
+		map[string][]string{
+			APIPkgImportScaffoldMarker: imports,
+			APISchemeScaffoldMarker:    registrations,
+		})
+}
+
+// requireBlockRe matches the first require block of a go.mod
+var requireBlockRe = regexp.MustCompile(`(?m)^require \($`)
+
+// requireModule adds the module at the version to the requirements of the go.mod, unless it is already required
+func requireModule(path, module, version string) error {
+	content, err := filesystem.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	required := regexp.MustCompile(`(?m)^(require)?\s+` + regexp.QuoteMeta(module) + `\s`)
+	if required.Match(content) {
+		return nil
+	}
+
+	requirement := fmt.Sprintf("%s %s", module, version)
+	var updated string
+	if loc := requireBlockRe.FindIndex(content); loc != nil {
+		updated = string(content[:loc[1]]) + "\n\t" + requirement + string(content[loc[1]:])
+	} else {
+		updated = strings.TrimRight(string(content), "\n") + "\n\nrequire " + requirement + "\n"
+	}
+	return filesystem.WriteFile(path, []byte(updated), 0644)
+}
+
+// replaceModule replaces the module by its version in the go.mod, unless it is already replaced
+func replaceModule(path, module, version string) error {
+	content, err := filesystem.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	replaced := regexp.MustCompile(`(?m)^(replace)?\s+` + regexp.QuoteMeta(module) + `(\s+\S+)?\s+=>`)
+	if replaced.Match(content) {
+		return nil
+	}
+
+	updated := strings.TrimRight(string(content), "\n") +
+		fmt.Sprintf("\n\nreplace %s => %s %s\n", module, module, version)
+	return filesystem.WriteFile(path, []byte(updated), 0644)
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRequireModule(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "go.mod")
+
+	tests := []struct {
+		name     string
+		goMod    string
+		expected string
+	}{
+		{
+			name:  "require block",
+			goMod: "module example.org/project\n\ngo 1.13\n\nrequire (\n\tsigs.k8s.io/controller-runtime v0.4.0\n)\n",
+			expected: "module example.org/project\n\ngo 1.13\n\nrequire (\n\tgithub.com/jetstack/cert-manager v1.0.4\n" +
+				"\tsigs.k8s.io/controller-runtime v0.4.0\n)\n",
+		},
+		{
+			name:  "single requirement",
+			goMod: "module example.org/project\n\ngo 1.13\n\nrequire sigs.k8s.io/controller-runtime v0.4.0\n",
+			expected: "module example.org/project\n\ngo 1.13\n\nrequire sigs.k8s.io/controller-runtime v0.4.0\n\n" +
+				"require github.com/jetstack/cert-manager v1.0.4\n",
+		},
+		{
+			name:     "already required",
+			goMod:    "module example.org/project\n\ngo 1.13\n\nrequire (\n\tgithub.com/jetstack/cert-manager v1.1.0\n)\n",
+			expected: "module example.org/project\n\ngo 1.13\n\nrequire (\n\tgithub.com/jetstack/cert-manager v1.1.0\n)\n",
+		},
+	}
+	for _, test := range tests {
+		if err := ioutil.WriteFile(path, []byte(test.goMod), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := requireModule(path, "github.com/jetstack/cert-manager", "v1.0.4"); err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != test.expected {
+			t.Errorf("%s: unexpected go.mod:\n%s", test.name, b)
+		}
+	}
+}
+
+func TestReplaceModule(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gomod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "go.mod")
+
+	tests := []struct {
+		name     string
+		goMod    string
+		expected string
+	}{
+		{
+			name:  "not replaced",
+			goMod: "module example.org/project\n\ngo 1.13\n\nrequire k8s.io/client-go v12.0.0+incompatible\n",
+			expected: "module example.org/project\n\ngo 1.13\n\nrequire k8s.io/client-go v12.0.0+incompatible\n\n" +
+				"replace k8s.io/client-go => k8s.io/client-go v0.0.0-20190918160344-1fbdaa4c8d90\n",
+		},
+		{
+			name: "already replaced",
+			goMod: "module example.org/project\n\ngo 1.13\n\nreplace (\n\tk8s.io/client-go => " +
+				"k8s.io/client-go v0.17.0\n)\n",
+			expected: "module example.org/project\n\ngo 1.13\n\nreplace (\n\tk8s.io/client-go => " +
+				"k8s.io/client-go v0.17.0\n)\n",
+		},
+	}
+	for _, test := range tests {
+		if err := ioutil.WriteFile(path, []byte(test.goMod), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := replaceModule(path, "k8s.io/client-go", "v0.0.0-20190918160344-1fbdaa4c8d90"); err != nil {
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+			continue
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != test.expected {
+			t.Errorf("%s: unexpected go.mod:\n%s", test.name, b)
+		}
+	}
+}
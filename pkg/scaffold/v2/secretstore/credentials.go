@@ -0,0 +1,168 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretstore
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &Credentials{}
+
+// Credentials scaffolds the package of the project loading the credentials mounted from the secret store into a
+// typed struct
+type Credentials struct {
+	input.Input
+
+	// MountPath is the directory the credentials are mounted into
+	MountPath string
+}
+
+// GetInput implements input.File
+func (f *Credentials) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("credentials", "credentials.go")
+	}
+	if f.MountPath == "" {
+		f.MountPath = MountPath
+	}
+	f.TemplateBody = credentialsTemplate
+	return f.Input, nil
+}
+
+var _ input.File = &CredentialsTest{}
+
+// CredentialsTest scaffolds the tests of the credentials package of the project
+type CredentialsTest struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *CredentialsTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("credentials", "credentials_test.go")
+	}
+	f.TemplateBody = credentialsTestTemplate
+	return f.Input, nil
+}
+
+const credentialsTemplate = `{{ .Boilerplate }}
+
+// Package credentials loads the credentials of the manager, e.g. the keys of a cloud provider, from the files the
+// secret store mounts into the manager, see config/secretstore. Don't write the credentials into a Secret of
+// config/manager: add a field per credential to Credentials, tagged with the name of its file, and the object of
+// the secret store mounted as that file.
+package credentials
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// DefaultDir is the directory the credentials are mounted into, see config/default/manager_credentials_patch.yaml
+const DefaultDir = "{{ .MountPath }}"
+
+// Credentials are the credentials the operator requires, every field is read from the file of its file tag
+type Credentials struct {
+	// AccessKeyID and SecretAccessKey are the keys of the cloud provider
+	AccessKeyID     string ` + "`" + `file:"access-key-id"` + "`" + `
+	SecretAccessKey string ` + "`" + `file:"secret-access-key"` + "`" + `
+}
+
+// String implements fmt.Stringer, the credentials are never printed, e.g. in the logs
+func (c Credentials) String() string {
+	return "credentials(redacted)"
+}
+
+// Load reads the credentials from the files of dir, it fails if any of them is missing or empty
+func Load(dir string) (*Credentials, error) {
+	creds := &Credentials{}
+	v := reflect.ValueOf(creds).Elem()
+	var missing []string
+	for i := 0; i < v.NumField(); i++ {
+		name := v.Type().Field(i).Tag.Get("file")
+		if name == "" {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("unable to read the credential %s: %v", name, err)
+		}
+		value := strings.TrimSpace(string(data))
+		if value == "" {
+			missing = append(missing, name)
+			continue
+		}
+		v.Field(i).SetString(value)
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing credentials %s in %s, mounted from the secret store, see config/secretstore",
+			strings.Join(missing, ", "), dir)
+	}
+	return creds, nil
+}
+`
+
+const credentialsTestTemplate = `{{ .Boilerplate }}
+
+package credentials
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "credentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, value := range map[string]string{"access-key-id": "id\n", "secret-access-key": "secret"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(value), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	creds, err := Load(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if creds.AccessKeyID != "id" || creds.SecretAccessKey != "secret" {
+		t.Errorf("unexpected credentials %#v", *creds)
+	}
+	if printed := fmt.Sprint(creds); strings.Contains(printed, "secret") {
+		t.Errorf("expected the credentials to be redacted, got %s", printed)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "secret-access-key")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(dir); err == nil || !strings.Contains(err.Error(), "secret-access-key") {
+		t.Errorf("expected an error about the missing credential, got %v", err)
+	}
+}
+`
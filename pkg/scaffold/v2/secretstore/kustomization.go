@@ -0,0 +1,107 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package secretstore scaffolds the credentials of the manager read from an external secret store, e.g. the keys
+// of a cloud provider, rather than from a Secret written into config/manager: the manifest describing them in the
+// store, the patch mounting them into the manager and the package of the project loading them
+package secretstore
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+// MountPath is the directory the credentials are mounted into, one file per credential
+const MountPath = "/etc/credentials"
+
+// Files returns the files scaffolding the credentials read from the secret store
+func Files(store string) []input.File {
+	files := []input.File{
+		&Kustomization{Store: store},
+		&ManagerPatch{Store: store},
+		&Credentials{},
+		&CredentialsTest{},
+	}
+	switch store {
+	case config.CSISecretStore:
+		files = append(files, &SecretProviderClass{}, &KustomizeConfig{})
+	case config.ExternalSecretsStore:
+		files = append(files, &ExternalSecret{})
+	}
+	return files
+}
+
+var _ input.File = &Kustomization{}
+
+// Kustomization scaffolds the kustomization in the secretstore folder
+type Kustomization struct {
+	input.Input
+
+	// Store is the way the credentials are read from the secret store, see config.Config.SecretStore
+	Store string
+}
+
+// GetInput implements input.File
+func (f *Kustomization) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "secretstore", "kustomization.yaml")
+	}
+	f.TemplateBody = kustomizationTemplate
+	return f.Input, nil
+}
+
+const kustomizationTemplate = `# Describes the credentials of the manager in the external secret store, mounted
+# into the manager by manager_credentials_patch.yaml of config/default.
+# Don't write them into a Secret of config/manager.
+resources:
+{{- if eq .Store "csi" }}
+- secretproviderclass.yaml
+
+configurations:
+- kustomizeconfig.yaml
+{{- else }}
+- externalsecret.yaml
+{{- end }}
+`
+
+var _ input.File = &KustomizeConfig{}
+
+// KustomizeConfig scaffolds the kustomizeconfig in the secretstore folder
+type KustomizeConfig struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *KustomizeConfig) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "secretstore", "kustomizeconfig.yaml")
+	}
+	f.TemplateBody = kustomizeConfigTemplate
+	return f.Input, nil
+}
+
+const kustomizeConfigTemplate = `# This configuration is for teaching kustomize how to update the name of the
+# SecretProviderClass in the volume of the manager mounting the credentials
+nameReference:
+- kind: SecretProviderClass
+  group: secrets-store.csi.x-k8s.io
+  fieldSpecs:
+  - kind: Deployment
+    group: apps
+    path: spec/template/spec/volumes/csi/volumeAttributes/secretProviderClass
+`
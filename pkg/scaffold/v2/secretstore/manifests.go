@@ -0,0 +1,165 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretstore
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &SecretProviderClass{}
+
+// SecretProviderClass scaffolds the SecretProviderClass of the credentials mounted by the Secrets Store CSI driver
+type SecretProviderClass struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *SecretProviderClass) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "secretstore", "secretproviderclass.yaml")
+	}
+	f.TemplateBody = secretProviderClassTemplate
+	return f.Input, nil
+}
+
+const secretProviderClassTemplate = `# Mounts the credentials of the manager with the Secrets Store CSI driver, which
+# must be installed in the cluster along with the provider of the secret store.
+# The objects of the provider are mounted as files named after their object name,
+# which must match the file tags of the Credentials type of the credentials package.
+apiVersion: secrets-store.csi.x-k8s.io/v1
+kind: SecretProviderClass
+metadata:
+  name: credentials
+  namespace: system
+spec:
+  # One of aws, azure, gcp or vault, set the parameters of the provider accordingly.
+  provider: vault
+  parameters:
+    vaultAddress: https://vault.example.com
+    roleName: controller-manager
+    objects: |
+      - objectName: access-key-id
+        secretPath: secret/data/controller-manager
+        secretKey: access-key-id
+      - objectName: secret-access-key
+        secretPath: secret/data/controller-manager
+        secretKey: secret-access-key
+`
+
+var _ input.File = &ExternalSecret{}
+
+// ExternalSecret scaffolds the ExternalSecret of the credentials synced into a Secret by the external-secrets
+// operator
+type ExternalSecret struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *ExternalSecret) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "secretstore", "externalsecret.yaml")
+	}
+	f.TemplateBody = externalSecretTemplate
+	return f.Input, nil
+}
+
+const externalSecretTemplate = `# Syncs the credentials of the manager into the Secret credentials with the
+# external-secrets operator, which must be installed in the cluster along with a
+# ClusterSecretStore connecting to the secret store.
+# The keys of the Secret are mounted as files, they must match the file tags of
+# the Credentials type of the credentials package.
+apiVersion: external-secrets.io/v1beta1
+kind: ExternalSecret
+metadata:
+  name: credentials
+  namespace: system
+spec:
+  refreshInterval: 1h
+  secretStoreRef:
+    kind: ClusterSecretStore
+    name: secret-store
+  target:
+    # not prefixed by kustomize, see manager_credentials_patch.yaml
+    name: credentials
+    creationPolicy: Owner
+  data:
+  - secretKey: access-key-id
+    remoteRef:
+      key: controller-manager
+      property: access-key-id
+  - secretKey: secret-access-key
+    remoteRef:
+      key: controller-manager
+      property: secret-access-key
+`
+
+var _ input.File = &ManagerPatch{}
+
+// ManagerPatch scaffolds the patch mounting the credentials into the manager
+type ManagerPatch struct {
+	input.Input
+
+	// Store is the way the credentials are read from the secret store, see config.Config.SecretStore
+	Store string
+
+	// MountPath is the directory the credentials are mounted into
+	MountPath string
+}
+
+// GetInput implements input.File
+func (f *ManagerPatch) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "default", "manager_credentials_patch.yaml")
+	}
+	if f.MountPath == "" {
+		f.MountPath = MountPath
+	}
+	f.TemplateBody = managerPatchTemplate
+	return f.Input, nil
+}
+
+const managerPatchTemplate = `# Mounts the credentials of the manager from the secret store into
+# {{ .MountPath }}, the directory the credentials package loads them from.
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller-manager
+  namespace: system
+spec:
+  template:
+    spec:
+      containers:
+      - name: manager
+        volumeMounts:
+        - mountPath: {{ .MountPath }}
+          name: credentials
+          readOnly: true
+      volumes:
+      - name: credentials
+{{- if eq .Store "csi" }}
+        csi:
+          driver: secrets-store.csi.k8s.io
+          readOnly: true
+          volumeAttributes:
+            secretProviderClass: credentials
+{{- else }}
+        secret:
+          secretName: credentials
+{{- end }}
+`
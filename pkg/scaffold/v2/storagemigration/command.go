@@ -0,0 +1,106 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storagemigration
+
+import (
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+const makefileTarget = `# Migrate the objects of the kinds listed in storagemigration/resources.go to their storage version in the
+# configured Kubernetes cluster in ~/.kube/config, the manager migrates them as well when it starts
+migrate-storage: install
+	go run ./cmd/migrate-storage
+
+`
+
+var _ input.File = &Command{}
+
+// Command scaffolds the command migrating the storage version out of the manager
+type Command struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *Command) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("cmd", "migrate-storage", "main.go")
+	}
+	f.TemplateBody = commandTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+// Update adds the migrate-storage target to the Makefile, before the docker targets, unless it already has it
+func (f *Command) Update() error {
+	path := filepath.Join(f.ProjectPath, "Makefile")
+	in, err := filesystem.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	content := string(in)
+	if strings.Contains(content, "\nmigrate-storage:") {
+		return nil
+	}
+	if i := strings.Index(content, "# Build the docker image"); i >= 0 {
+		content = content[:i] + makefileTarget + content[i:]
+	} else {
+		content += "\n" + strings.TrimSuffix(makefileTarget, "\n")
+	}
+	return filesystem.WriteFile(path, []byte(content), 0644)
+}
+
+const commandTemplate = `{{ .Boilerplate }}
+
+// Command migrate-storage migrates the objects of the kinds listed in storagemigration.Resources to their storage
+// version in the cluster of the current kubeconfig, e.g. before deploying a manager whose CRDs no longer serve the
+// previous versions.
+package main
+
+import (
+	"context"
+	"os"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"{{ .Repo }}/storagemigration"
+)
+
+func main() {
+	ctrl.SetLogger(zap.New(func(o *zap.Options) {
+		o.Development = true
+	}))
+	log := ctrl.Log.WithName("storagemigration")
+
+	c, err := client.New(ctrl.GetConfigOrDie(), client.Options{})
+	if err != nil {
+		log.Error(err, "unable to create the client")
+		os.Exit(1)
+	}
+
+	m := &storagemigration.Migrator{Client: c, Log: log, Resources: storagemigration.Resources}
+	if err := m.MigrateAll(context.Background()); err != nil {
+		log.Error(err, "unable to migrate the storage version")
+		os.Exit(1)
+	}
+}
+`
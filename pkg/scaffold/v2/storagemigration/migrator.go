@@ -0,0 +1,253 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package storagemigration scaffolds the migration of the objects of the kinds whose storage version changed to
+// their new storage version.
+package storagemigration
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &Migrator{}
+
+// Migrator scaffolds the migrator rewriting the objects of a kind at its storage version
+type Migrator struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *Migrator) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("storagemigration", "migrator.go")
+	}
+	f.TemplateBody = migratorTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+var _ input.File = &MigratorTest{}
+
+// MigratorTest scaffolds the tests of the migrator
+type MigratorTest struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *MigratorTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("storagemigration", "migrator_test.go")
+	}
+	f.TemplateBody = migratorTestTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+const migratorTemplate = `{{ .Boilerplate }}
+
+// Package storagemigration migrates the objects of the kinds whose storage version changed to their new storage
+// version, as kube-storage-version-migrator does: every object is written back unchanged for the API server to
+// store it at the storage version, then the CRD records that the previous versions are no longer stored so that
+// they can be removed from it.
+package storagemigration
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// pageSize is the number of objects listed at once
+const pageSize = 500
+
+var crdGVK = schema.GroupVersionKind{
+	Group:   "apiextensions.k8s.io",
+	Version: "v1beta1",
+	Kind:    "CustomResourceDefinition",
+}
+
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions/status,verbs=get;update
+
+// Resource is a kind whose objects are migrated to its storage version
+type Resource struct {
+	// Group is the group of the kind, qualified by the domain of the project
+	Group string
+
+	// Version is the storage version of the kind
+	Version string
+
+	// Kind and Plural are the kind and the resource name of the objects
+	Kind, Plural string
+}
+
+// Migrator migrates the objects of the Resources whose CRD still stores other versions
+type Migrator struct {
+	// Client reads the objects from the API server, the migrated objects are not cached
+	Client client.Client
+
+	Log logr.Logger
+
+	Resources []Resource
+}
+
+// SetupWithManager migrates the objects once the manager is elected leader, whenever it starts
+func (m *Migrator) SetupWithManager(mgr ctrl.Manager) error {
+	if m.Client == nil {
+		c, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+		if err != nil {
+			return err
+		}
+		m.Client = c
+	}
+	if m.Log == nil {
+		m.Log = ctrl.Log.WithName("storagemigration")
+	}
+	return mgr.Add(m)
+}
+
+// Start implements manager.Runnable. A failed migration doesn't stop the manager, it is retried at its next start.
+func (m *Migrator) Start(stop <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if err := m.MigrateAll(ctx); err != nil {
+		m.Log.Error(err, "unable to migrate the storage version of every resource")
+	}
+	return nil
+}
+
+// MigrateAll migrates the objects of every resource, it returns an error if any migration failed
+func (m *Migrator) MigrateAll(ctx context.Context) error {
+	failed := 0
+	for _, r := range m.Resources {
+		if err := m.Migrate(ctx, r); err != nil {
+			m.Log.Error(err, "unable to migrate the storage version", "resource", r.Plural+"."+r.Group)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d resources failed to migrate", failed, len(m.Resources))
+	}
+	return nil
+}
+
+// Migrate rewrites the objects of the resource at its storage version, unless its CRD only stores this version
+func (m *Migrator) Migrate(ctx context.Context, r Resource) error {
+	log := m.Log.WithValues("resource", r.Plural+"."+r.Group, "version", r.Version)
+
+	crd := &unstructured.Unstructured{}
+	crd.SetGroupVersionKind(crdGVK)
+	if err := m.Client.Get(ctx, client.ObjectKey{Name: r.Plural + "." + r.Group}, crd); err != nil {
+		return err
+	}
+	stored, _, err := unstructured.NestedStringSlice(crd.Object, "status", "storedVersions")
+	if err != nil {
+		return err
+	}
+	if !NeedsMigration(stored, r.Version) {
+		log.V(1).Info("storage version already migrated")
+		return nil
+	}
+
+	migrated := 0
+	for token := ""; ; {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(schema.GroupVersionKind{Group: r.Group, Version: r.Version, Kind: r.Kind + "List"})
+		if err := m.Client.List(ctx, list, client.Limit(pageSize), client.Continue(token)); err != nil {
+			return err
+		}
+		for i := range list.Items {
+			if err := m.rewrite(ctx, &list.Items[i]); err != nil {
+				return err
+			}
+			migrated++
+		}
+		if token = list.GetContinue(); token == "" {
+			break
+		}
+	}
+
+	// the previous versions are no longer stored, they can be removed from the CRD
+	if err := unstructured.SetNestedStringSlice(crd.Object, []string{r.Version}, "status", "storedVersions"); err != nil {
+		return err
+	}
+	if err := m.Client.Status().Update(ctx, crd); err != nil {
+		return err
+	}
+	log.Info("migrated the storage version", "objects", migrated, "previous", stored)
+	return nil
+}
+
+// rewrite writes the object back unchanged for the API server to store it at the storage version
+func (m *Migrator) rewrite(ctx context.Context, obj *unstructured.Unstructured) error {
+	err := m.Client.Update(ctx, obj)
+	if apierrors.IsNotFound(err) || apierrors.IsConflict(err) {
+		// deleted or written since it was listed, the object is already stored at the storage version
+		return nil
+	}
+	return err
+}
+
+// NeedsMigration returns true if versions other than the storage version are stored
+func NeedsMigration(stored []string, version string) bool {
+	return len(stored) != 1 || stored[0] != version
+}
+`
+
+const migratorTestTemplate = `{{ .Boilerplate }}
+
+package storagemigration
+
+import (
+	"testing"
+)
+
+func TestNeedsMigration(t *testing.T) {
+	tests := []struct {
+		name     string
+		stored   []string
+		expected bool
+	}{
+		{name: "previous version stored", stored: []string{"v1", "v2"}, expected: true},
+		{name: "previous version only", stored: []string{"v1"}, expected: true},
+		{name: "storage version only", stored: []string{"v2"}},
+		{name: "nothing recorded", expected: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if needed := NeedsMigration(test.stored, "v2"); needed != test.expected {
+				t.Errorf("expected the migration to be needed: %t, got: %t", test.expected, needed)
+			}
+		})
+	}
+}
+`
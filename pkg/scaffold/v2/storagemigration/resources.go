@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storagemigration
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/internal"
+)
+
+const (
+	resourcesMarker     = "// +kubebuilder:scaffold:storagemigration"
+	resourcesRBACMarker = "// +kubebuilder:scaffold:storagemigration-rbac"
+)
+
+var _ input.File = &Resources{}
+
+// Resources scaffolds the list of the kinds migrated to their storage version
+type Resources struct {
+	input.Input
+
+	// Resource is the kind migrated to its storage version, the version of the resource
+	Resource *resource.Resource
+
+	// ResourcesMarker and ResourcesRBACMarker are the markers the resources and their rbac markers are added at
+	ResourcesMarker, ResourcesRBACMarker string
+}
+
+// GetInput implements input.File
+func (f *Resources) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("storagemigration", "resources.go")
+	}
+	f.ResourcesMarker, f.ResourcesRBACMarker = resourcesMarker, resourcesRBACMarker
+	f.TemplateBody = resourcesTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+// Update adds the resource to the list of the kinds migrated to their storage version
+func (f *Resources) Update() error {
+	_, groupDomain := util.GetResourceInfo(f.ProjectPath, f.Resource, f.Repo, f.Domain, f.MultiGroup)
+	return internal.InsertStringsInFile(filepath.Join(f.ProjectPath, f.Path),
+		map[string][]string{
+			resourcesMarker: {fmt.Sprintf("{Group: %q, Version: %q, Kind: %q, Plural: %q},\n",
+				groupDomain, f.Resource.Version, f.Resource.Kind, f.Resource.Resource)},
+			resourcesRBACMarker: {fmt.Sprintf("// +kubebuilder:rbac:groups=%s,resources=%s,verbs=get;list;update\n",
+				groupDomain, f.Resource.Resource)},
+		})
+}
+
+const resourcesTemplate = `{{ .Boilerplate }}
+
+package storagemigration
+
+{{ .ResourcesRBACMarker }}
+
+// Resources are the kinds whose objects are migrated to their storage version, a kind can be removed once its
+// previous versions are no longer stored in any cluster
+var Resources = []Resource{
+	{{ .ResourcesMarker }}
+}
+`
@@ -0,0 +1,273 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tenancy scaffolds the isolation of the tenants of the operator by namespace: the package of the
+// project scoping the clients of the reconcilers to the namespace of the reconciled object, and the RoleBinding
+// limiting the manager to the namespaces of the tenants
+package tenancy
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+var _ input.File = &Client{}
+
+// Client scaffolds the package of the project scoping the clients of the reconcilers to a tenant
+type Client struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *Client) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("tenancy", "tenancy.go")
+	}
+	f.TemplateBody = clientTemplate
+	return f.Input, nil
+}
+
+var _ input.File = &ClientTest{}
+
+// ClientTest scaffolds the tests of the tenancy package of the project
+type ClientTest struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *ClientTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("tenancy", "tenancy_test.go")
+	}
+	f.TemplateBody = clientTestTemplate
+	return f.Input, nil
+}
+
+const clientTemplate = `{{ .Boilerplate }}
+
+// Package tenancy isolates the tenants of the operator by namespace. The objects of a tenant are the ones of its
+// namespace: the objects reconciled in any namespace, their children are only created in the same namespace.
+//
+// The reconcilers run with a tenant in their context, see WithTenant, and their client refuses to access the
+// objects of another namespace, or cluster-scoped objects, for that tenant. The calls without a tenant in their
+// context, e.g. the ones of the handlers mapping events to requests, are not restricted.
+//
+// The manager may also be limited to the namespaces of the tenants, by caching their objects only, see the
+// --tenant-namespaces flag, and by binding its role in these namespaces only, see config/tenant.
+package tenancy
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+type tenantKey struct{}
+
+// WithTenant returns a context of the reconciles of the objects of the tenant namespace
+func WithTenant(ctx context.Context, namespace string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, namespace)
+}
+
+// TenantFrom returns the tenant namespace of ctx, if any
+func TenantFrom(ctx context.Context) (string, bool) {
+	namespace, ok := ctx.Value(tenantKey{}).(string)
+	return namespace, ok
+}
+
+// CrossTenantError is the error accessing an object out of the namespace of the tenant
+type CrossTenantError struct {
+	// Tenant is the namespace of the tenant
+	Tenant string
+
+	// Namespace is the namespace of the object, empty for a cluster-scoped object
+	Namespace string
+}
+
+// Error implements error
+func (e *CrossTenantError) Error() string {
+	if e.Namespace == "" {
+		return fmt.Sprintf("the reconciles of the tenant %s can't access cluster-scoped objects", e.Tenant)
+	}
+	return fmt.Sprintf("the reconciles of the tenant %s can't access the objects of the namespace %s",
+		e.Tenant, e.Namespace)
+}
+
+// IsCrossTenant returns true if err is, or wraps, a CrossTenantError
+func IsCrossTenant(err error) bool {
+	var crossTenant *CrossTenantError
+	return stderrors.As(err, &crossTenant)
+}
+
+// NewClient returns a client refusing to access the objects out of the namespace of the tenant of the context of
+// the calls, if any
+func NewClient(c client.Client) client.Client {
+	return &tenantClient{Client: c}
+}
+
+type tenantClient struct {
+	client.Client
+}
+
+// check returns a CrossTenantError if ctx has a tenant and namespace is not the one of the tenant
+func check(ctx context.Context, namespace string) error {
+	tenant, ok := TenantFrom(ctx)
+	if !ok || namespace == tenant {
+		return nil
+	}
+	return &CrossTenantError{Tenant: tenant, Namespace: namespace}
+}
+
+// checkObject checks the namespace of obj
+func checkObject(ctx context.Context, obj runtime.Object) error {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+	return check(ctx, accessor.GetNamespace())
+}
+
+func (c *tenantClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	if err := check(ctx, key.Namespace); err != nil {
+		return err
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func (c *tenantClient) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	if err := check(ctx, (&client.ListOptions{}).ApplyOptions(opts).Namespace); err != nil {
+		return err
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *tenantClient) Create(ctx context.Context, obj runtime.Object, opts ...client.CreateOption) error {
+	if err := checkObject(ctx, obj); err != nil {
+		return err
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *tenantClient) Delete(ctx context.Context, obj runtime.Object, opts ...client.DeleteOption) error {
+	if err := checkObject(ctx, obj); err != nil {
+		return err
+	}
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *tenantClient) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	if err := checkObject(ctx, obj); err != nil {
+		return err
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *tenantClient) Patch(ctx context.Context, obj runtime.Object, patch client.Patch,
+	opts ...client.PatchOption) error {
+	if err := checkObject(ctx, obj); err != nil {
+		return err
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *tenantClient) DeleteAllOf(ctx context.Context, obj runtime.Object, opts ...client.DeleteAllOfOption) error {
+	if err := check(ctx, (&client.DeleteAllOfOptions{}).ApplyOptions(opts).Namespace); err != nil {
+		return err
+	}
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+func (c *tenantClient) Status() client.StatusWriter {
+	return &tenantStatusWriter{StatusWriter: c.Client.Status()}
+}
+
+type tenantStatusWriter struct {
+	client.StatusWriter
+}
+
+func (w *tenantStatusWriter) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	if err := checkObject(ctx, obj); err != nil {
+		return err
+	}
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func (w *tenantStatusWriter) Patch(ctx context.Context, obj runtime.Object, patch client.Patch,
+	opts ...client.PatchOption) error {
+	if err := checkObject(ctx, obj); err != nil {
+		return err
+	}
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
+`
+
+const clientTestTemplate = `{{ .Boilerplate }}
+
+package tenancy
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestTenantIsolation(t *testing.T) {
+	c := NewClient(fake.NewFakeClientWithScheme(clientgoscheme.Scheme))
+	ctx := WithTenant(context.Background(), "tenant-a")
+
+	own := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "tenant-a"}}
+	if err := c.Create(ctx, own); err != nil {
+		t.Fatalf("expected the tenant to create its objects, got: %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKey{Name: "test", Namespace: "tenant-a"}, &corev1.ConfigMap{}); err != nil {
+		t.Errorf("expected the tenant to read its objects, got: %v", err)
+	}
+	if err := c.List(ctx, &corev1.ConfigMapList{}, client.InNamespace("tenant-a")); err != nil {
+		t.Errorf("expected the tenant to list its objects, got: %v", err)
+	}
+
+	other := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "tenant-b"}}
+	for name, err := range map[string]error{
+		"create":         c.Create(ctx, other),
+		"update":         c.Update(ctx, other),
+		"delete":         c.Delete(ctx, other),
+		"status update":  c.Status().Update(ctx, other),
+		"get":            c.Get(ctx, client.ObjectKey{Name: "test", Namespace: "tenant-b"}, &corev1.ConfigMap{}),
+		"list":           c.List(ctx, &corev1.ConfigMapList{}, client.InNamespace("tenant-b")),
+		"list all":       c.List(ctx, &corev1.ConfigMapList{}),
+		"cluster-scoped": c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-c"}}),
+	} {
+		if !IsCrossTenant(err) {
+			t.Errorf("%s: expected a cross-tenant error, got: %v", name, err)
+		}
+	}
+
+	// the calls without a tenant aren't restricted
+	if err := c.Create(context.Background(), other); err != nil {
+		t.Errorf("expected the objects to be created without tenant, got: %v", err)
+	}
+}
+`
@@ -0,0 +1,92 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tenancy
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+)
+
+var _ input.File = &Kustomization{}
+
+// Kustomization scaffolds the kustomization of the RoleBinding granting the manager access to a tenant namespace
+type Kustomization struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *Kustomization) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "tenant", "kustomization.yaml")
+	}
+	f.TemplateBody = kustomizationTemplate
+	return f.Input, nil
+}
+
+var _ input.File = &RoleBinding{}
+
+// RoleBinding scaffolds the RoleBinding granting the manager access to a tenant namespace
+type RoleBinding struct {
+	input.Input
+
+	// Prefix is the name prefix of the resources of config/default, defaults to the project name
+	Prefix string
+}
+
+// GetInput implements input.File
+func (f *RoleBinding) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "tenant", "role_binding.yaml")
+	}
+	if f.Prefix == "" {
+		// use directory name as prefix
+		prefix, err := util.ProjectName(f.ProjectPath)
+		if err != nil {
+			return input.Input{}, err
+		}
+		f.Prefix = prefix
+	}
+	f.TemplateBody = roleBindingTemplate
+	return f.Input, nil
+}
+
+const kustomizationTemplate = `# Grants the manager access to the objects of a tenant namespace. The manager is limited to the namespaces of
+# the tenants by replacing the ClusterRoleBinding of config/rbac by one RoleBinding per tenant namespace, and
+# by listing these namespaces in the --tenant-namespaces flag of the manager, e.g.
+#
+#   kustomize build config/tenant | kubectl apply -n tenant-a -f -
+#
+# The RoleBinding has no namespace, it is created in the namespace it is applied to.
+resources:
+- role_binding.yaml
+`
+
+const roleBindingTemplate = `apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: {{ .Prefix }}-manager-rolebinding
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: {{ .Prefix }}-manager-role
+subjects:
+- kind: ServiceAccount
+  name: default
+  namespace: {{ .Prefix }}-system
+`
@@ -19,6 +19,7 @@ package v2
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
@@ -33,30 +34,125 @@ type Types struct {
 
 	// Resource is the resource to scaffold the types_test.go file for
 	Resource *resource.Resource
+
+	// Imported contains the declarations lifted from an existing Go file, if any
+	Imported *ImportedTypes
+
+	// References are the fields of the spec referencing objects of other kinds
+	References []*Reference
+
+	// Unions are the fields of the spec of which exactly one member is set
+	Unions []*Union
+
+	// Embeds are the fields of the spec of Kubernetes types, e.g. a corev1.PodTemplateSpec
+	Embeds []*Embed
+
+	// Enums are the fields of the spec or of the status whose value is one of a set of strings
+	Enums []*Enum
+
+	// PrinterColumns are the columns of kubectl get besides the Ready and Age ones
+	PrinterColumns []*PrinterColumn
+
+	// AdoptExisting indicates that the spec has a field enabling the adoption of the existing children
+	AdoptExisting bool
+
+	// StatusConditions indicates that the status has conditions, shown in the Ready column of kubectl get
+	StatusConditions bool
+
+	// ApplyConditions indicates that the conditions are applied with server-side apply by several field managers,
+	// merged by type
+	ApplyConditions bool
+
+	// LongRunning indicates that the status tracks the long-running operation of the controller, shown in the Phase
+	// column of kubectl get
+	LongRunning bool
+
+	// GenerateClient marks the kind for the generation of its typed clientset, informers and listers
+	GenerateClient bool
+
+	// Imports are the import specs of the file besides metav1
+	Imports []string
 }
 
 // GetInput implements input.File
 func (f *Types) GetInput() (input.Input, error) {
 	if f.Path == "" {
-		f.Path = filepath.Join("pkg", "apis", f.Resource.Group, f.Resource.Version,
-			fmt.Sprintf("%s_types.go", strings.ToLower(f.Resource.Kind)))
+		name := fmt.Sprintf("%s_types.go", f.Naming.FileName(f.Resource.Kind))
+		if f.MultiGroup {
+			f.Path = filepath.Join("apis", f.Resource.Group, f.Resource.Version, name)
+		} else {
+			f.Path = filepath.Join("api", f.Resource.Version, name)
+		}
+	}
+	if f.Imported != nil {
+		f.Imports = append(f.Imports, f.Imported.Imports...)
+	}
+	if hasLocalReference(f.References) {
+		f.Imports = append(f.Imports, `corev1 "k8s.io/api/core/v1"`)
+	}
+	if len(f.Unions) > 0 {
+		f.Imports = append(f.Imports,
+			`apierrors "k8s.io/apimachinery/pkg/api/errors"`,
+			`"k8s.io/apimachinery/pkg/runtime/schema"`,
+			`"k8s.io/apimachinery/pkg/util/validation/field"`,
+		)
+	}
+	for _, embed := range f.Embeds {
+		f.Imports = append(f.Imports, embed.Import())
 	}
+	f.Imports = unique(f.Imports)
+	sort.Strings(f.Imports)
+
 	f.TemplateBody = typesTemplate
 	f.IfExistsAction = input.Error
 	return f.Input, nil
 }
 
+// ResourceMarker returns the arguments of the resource marker of the kind, empty if it doesn't need one. The
+// arguments are set by a single marker, controller-gen resets the ones missing from every other marker.
+func (f *Types) ResourceMarker() string {
+	var args []string
+	if !f.Resource.HasDefaultPlural() {
+		args = append(args, "path="+f.Resource.Resource)
+	}
+	if !f.Resource.Namespaced {
+		args = append(args, "scope=Cluster")
+	}
+	if len(f.Resource.ShortNames) > 0 {
+		args = append(args, "shortName="+strings.Join(f.Resource.ShortNames, ";"))
+	}
+	if len(f.Resource.Categories) > 0 {
+		args = append(args, "categories="+strings.Join(f.Resource.Categories, ";"))
+	}
+	return strings.Join(args, ",")
+}
+
 // Validate validates the values
 func (f *Types) Validate() error {
 	return f.Resource.Validate()
 }
 
+func unique(values []string) []string {
+	var result []string
+	seen := map[string]bool{}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
 const typesTemplate = `{{ .Boilerplate }}
 
 package {{ .Resource.Version }}
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+{{- range .Imports }}
+	{{ . }}
+{{- end }}
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -66,19 +162,112 @@ import (
 type {{.Resource.Kind}}Spec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+{{- if .Imported }}
+
+	// The fields below were imported from {{ .Imported.Source }}
+{{- range .Imported.Fields }}
+
+	{{ . }}
+{{- end }}
+{{- else }}
 
 	// Foo is an example field of {{.Resource.Kind}}. Edit {{.Resource.Kind}}_types.go to remove/update
+	// +optional
 	Foo string ` + "`" + `json:"foo,omitempty"` + "`" + `
+{{- end }}
+{{- range .References }}
+
+	// {{ .Field }} references the {{ .Resource.Kind }}{{ if .CrossNamespace }} by its namespace and name{{ else if not .ClusterScoped }} in the namespace of the {{ $.Resource.Kind }}{{ end }}
+	// +kubebuilder:validation:Required
+	{{ .Field }} {{ .Type }} ` + "`" + `json:"{{ .JSONName }}"` + "`" + `
+{{- end }}
+{{- range .Unions }}
+
+	// {{ .Field }} is a union of which exactly one member is set, the one named by its type
+	// +kubebuilder:validation:Required
+	{{ .Field }} {{ $.Resource.Kind }}{{ .Field }} ` + "`" + `json:"{{ .JSONName }}"` + "`" + `
+{{- end }}
+{{- range .Embeds }}
+
+	// {{ .Field }} is the {{ .Type }} of the {{ $.Resource.Kind }}.
+	// The descriptions of its schema are dropped from the CRD, see CRD_OPTIONS in the Makefile, for the CRD to
+	// be small enough to be applied.
+	// NOTE: the API server only validates it structurally, not like a built-in {{ .Type }}, validate it in
+	// the validating webhook or the objects created from it may be refused.
+{{- if .HasMetadata }}
+	// Its unknown fields are preserved, for the labels and annotations of its metadata not to be pruned.
+	// +kubebuilder:pruning:PreserveUnknownFields
+{{- end }}
+	// +kubebuilder:validation:Required
+	{{ .Field }} {{ .GoType }} ` + "`" + `json:"{{ .JSONName }}"` + "`" + `
+{{- end }}
+{{- range .Enums }}{{ if not .Status }}
+
+	// {{ .Field }} is one of {{ range $i, $v := .Values }}{{ if $i }}, {{ end }}{{ $v.Value }}{{ end }}
+	// +optional
+	{{ .Field }} {{ $.Resource.Kind }}{{ .Field }} ` + "`" + `json:"{{ .JSONName }},omitempty"` + "`" + `
+{{- end }}{{ end }}
+{{- if .AdoptExisting }}
+
+	// AdoptExisting indicates whether the existing objects the {{.Resource.Kind}} manages which no object controls,
+	// e.g. created by hand or by helm, are adopted, the {{.Resource.Kind}} becoming their controller. They are
+	// left alone otherwise.
+	// +optional
+	AdoptExisting bool ` + "`" + `json:"adoptExisting,omitempty"` + "`" + `
+{{- end }}
 }
 
 // {{.Resource.Kind}}Status defines the observed state of {{.Resource.Kind}}
 type {{.Resource.Kind}}Status struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+{{- if .StatusConditions }}
+
+	// Conditions are the latest observations of the state
+{{- if .ApplyConditions }}. Every controller applies the conditions it owns with
+	// server-side apply, the API server merges them by type.
+	// +listType=map
+	// +listMapKey=type
+{{- end }}
+	// +optional
+	Conditions []Condition ` + "`" + `json:"conditions,omitempty"` + "`" + `
+{{- end }}
+{{- if .LongRunning }}
+
+	// Operation is the progress of the operation of the controller, resumed across reconciles
+	// +optional
+	Operation OperationStatus ` + "`" + `json:"operation,omitempty"` + "`" + `
+{{- end }}
+{{- range .Enums }}{{ if .Status }}
+
+	// {{ .Field }} is one of {{ range $i, $v := .Values }}{{ if $i }}, {{ end }}{{ $v.Value }}{{ end }}
+	// +optional
+	{{ .Field }} {{ $.Resource.Kind }}{{ .Field }} ` + "`" + `json:"{{ .JSONName }},omitempty"` + "`" + `
+{{- end }}{{ end }}
 }
 
+{{ if .GenerateClient -}}
+// +genclient
+{{ if not .Resource.Namespaced -}}
+// +genclient:nonNamespaced
+{{ end -}}
+{{ end -}}
 // +kubebuilder:object:root=true
-{{ if not .Resource.Namespaced }} // +kubebuilder:resource:scope=Cluster {{ end }}
+// +kubebuilder:subresource:status
+{{- range .PrinterColumns }}
+{{ .Marker }}
+{{- end }}
+{{- if .LongRunning }}
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.operation.phase"
+{{- end }}
+{{- if .StatusConditions }}
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+{{- end }}
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+{{- if .ResourceMarker }}
+// +kubebuilder:resource:{{ .ResourceMarker }}
+{{- end }}
+
 
 // {{.Resource.Kind}} is the Schema for the {{ .Resource.Resource }} API
 type {{.Resource.Kind}} struct {
@@ -101,4 +290,109 @@ type {{.Resource.Kind}}List struct {
 func init() {
 	SchemeBuilder.Register(&{{.Resource.Kind}}{}, &{{.Resource.Kind}}List{})
 }
+{{- if .Unions }}
+
+// ValidateUnions verifies that exactly one member of each union of the spec is set, the one named by its type.
+// Unions can't be validated by the OpenAPI schema of the CRD, call it from the validating webhook.
+func (r *{{ .Resource.Kind }}) ValidateUnions() error {
+	var allErrs field.ErrorList
+{{- range .Unions }}
+	allErrs = append(allErrs, r.Spec.{{ .Field }}.validate(field.NewPath("spec", "{{ .JSONName }}"))...)
+{{- end }}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "{{ .Resource.Kind }}"}, r.Name, allErrs)
+}
+{{- end }}
+{{- range $union := .Unions }}
+
+// {{ $.Resource.Kind }}{{ .Field }}Type names the member of {{ $.Resource.Kind }}{{ .Field }} which is set
+// +kubebuilder:validation:Enum={{ range $i, $m := .Members }}{{ if $i }};{{ end }}{{ $m.Name }}{{ end }}
+type {{ $.Resource.Kind }}{{ .Field }}Type string
+
+const (
+{{- range .Members }}
+	// {{ $.Resource.Kind }}{{ $union.Field }}Type{{ .Name }} selects the {{ .JSONName }} member
+	{{ $.Resource.Kind }}{{ $union.Field }}Type{{ .Name }} {{ $.Resource.Kind }}{{ $union.Field }}Type = "{{ .Name }}"
+{{- end }}
+)
+
+// {{ $.Resource.Kind }}{{ .Field }} is a union of which exactly one member is set, the one named by Type
+// +union
+type {{ $.Resource.Kind }}{{ .Field }} struct {
+	// Type names the member which is set
+	// +unionDiscriminator
+	// +kubebuilder:validation:Required
+	Type {{ $.Resource.Kind }}{{ .Field }}Type ` + "`" + `json:"type"` + "`" + `
+{{- range .Members }}
+
+	// {{ .Name }} is set when Type is {{ .Name }}
+	// +optional
+	{{ .Name }} *{{ $.Resource.Kind }}{{ .Name }}{{ $union.Field }} ` + "`" + `json:"{{ .JSONName }},omitempty"` + "`" + `
+{{- end }}
+}
+{{- range .Members }}
+
+// {{ $.Resource.Kind }}{{ .Name }}{{ $union.Field }} is the {{ .JSONName }} member of {{ $.Resource.Kind }}{{ $union.Field }}
+type {{ $.Resource.Kind }}{{ .Name }}{{ $union.Field }} struct {
+	// TODO(user): add the fields of the {{ .JSONName }} {{ $union.JSONName }}
+}
+{{- end }}
+
+// validate verifies that the member named by Type is the only one set
+func (u *{{ $.Resource.Kind }}{{ .Field }}) validate(path *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch u.Type {
+	case {{ range $i, $m := .Members }}{{ if $i }}, {{ end }}{{ $.Resource.Kind }}{{ $union.Field }}Type{{ $m.Name }}{{ end }}:
+	default:
+		allErrs = append(allErrs, field.NotSupported(path.Child("type"), u.Type, []string{
+		{{- range .Members }}
+			string({{ $.Resource.Kind }}{{ $union.Field }}Type{{ .Name }}),
+		{{- end }}
+		}))
+	}
+{{- range .Members }}
+
+	if u.Type == {{ $.Resource.Kind }}{{ $union.Field }}Type{{ .Name }} && u.{{ .Name }} == nil {
+		allErrs = append(allErrs, field.Required(path.Child("{{ .JSONName }}"), "must be set when type is {{ .Name }}"))
+	}
+	if u.Type != {{ $.Resource.Kind }}{{ $union.Field }}Type{{ .Name }} && u.{{ .Name }} != nil {
+		allErrs = append(allErrs, field.Forbidden(path.Child("{{ .JSONName }}"), "must not be set when type is not {{ .Name }}"))
+	}
+{{- end }}
+	return allErrs
+}
+{{- end }}
+{{- range $enum := .Enums }}
+
+// {{ $.Resource.Kind }}{{ .Field }} is the {{ .JSONName }} of a {{ $.Resource.Kind }}
+// +kubebuilder:validation:Enum={{ range $i, $v := .Values }}{{ if $i }};{{ end }}{{ $v.Value }}{{ end }}
+type {{ $.Resource.Kind }}{{ .Field }} string
+
+const (
+{{- range .Values }}
+	// {{ $.Resource.Kind }}{{ $enum.Field }}{{ .Name }} is the {{ .Value }} {{ $enum.JSONName }}
+	{{ $.Resource.Kind }}{{ $enum.Field }}{{ .Name }} {{ $.Resource.Kind }}{{ $enum.Field }} = "{{ .Value }}"
+{{- end }}
+)
+
+// String implements fmt.Stringer
+func (v {{ $.Resource.Kind }}{{ .Field }}) String() string {
+	return string(v)
+}
+
+// IsValid returns whether the value is one of the values of {{ $.Resource.Kind }}{{ .Field }}
+func (v {{ $.Resource.Kind }}{{ .Field }}) IsValid() bool {
+	switch v {
+	case {{ range $i, $v := .Values }}{{ if $i }}, {{ end }}{{ $.Resource.Kind }}{{ $enum.Field }}{{ $v.Name }}{{ end }}:
+		return true
+	}
+	return false
+}
+{{- end }}
+{{- if .Imported }}{{ range .Imported.Declarations }}
+
+{{ . }}
+{{- end }}{{ end }}
 `
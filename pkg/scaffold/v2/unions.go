@@ -0,0 +1,96 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var unionMemberRe = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+
+// initialisms are the words spelled in upper case in Go names, as golint expects
+var initialisms = map[string]bool{
+	"acl": true, "api": true, "ascii": true, "cpu": true, "css": true, "dns": true, "eof": true, "guid": true,
+	"html": true, "http": true, "https": true, "id": true, "ip": true, "json": true, "oci": true, "qps": true,
+	"ram": true, "rpc": true, "sla": true, "smtp": true, "sql": true, "ssh": true, "tcp": true, "tls": true,
+	"ttl": true, "udp": true, "ui": true, "uid": true, "uuid": true, "uri": true, "url": true, "vm": true,
+	"xml": true, "xmpp": true, "xsrf": true, "xss": true,
+}
+
+// Union is a field of the spec of a resource of which exactly one member is set, as named by its discriminator
+type Union struct {
+	// Field is the name of the Go field, e.g. Source
+	Field string
+
+	// JSONName is the name of the field in the spec, e.g. source
+	JSONName string
+
+	// Members are the members of the union
+	Members []UnionMember
+}
+
+// UnionMember is a member of a Union
+type UnionMember struct {
+	// Name is the name of the Go field and the value of the discriminator selecting it, e.g. Git
+	Name string
+
+	// JSONName is the name of the field in the union, e.g. git
+	JSONName string
+}
+
+// ParseUnion parses a union of the form spec.<field>=<member>|<member>..., e.g. spec.source=git|oci|http
+func ParseUnion(value string) (*Union, error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid union %q, expected spec.<field>=<member>|<member>...", value)
+	}
+
+	field := specFieldRe.FindStringSubmatch(parts[0])
+	if field == nil {
+		return nil, fmt.Errorf("invalid union field %q, expected a lower camel case field of the spec, "+
+			"e.g. spec.source", parts[0])
+	}
+
+	u := &Union{Field: goName(field[1]), JSONName: field[1]}
+	seen := map[string]bool{}
+	for _, member := range strings.Split(parts[1], "|") {
+		if !unionMemberRe.MatchString(member) {
+			return nil, fmt.Errorf("invalid member %q of union %s, expected a lower camel case name, e.g. git",
+				member, parts[0])
+		}
+		if seen[member] {
+			return nil, fmt.Errorf("member %q of union %s is repeated", member, parts[0])
+		}
+		seen[member] = true
+		u.Members = append(u.Members, UnionMember{Name: goName(member), JSONName: member})
+	}
+	if len(u.Members) < 2 {
+		return nil, fmt.Errorf("union %s must have at least two members", parts[0])
+	}
+
+	return u, nil
+}
+
+// goName returns the exported Go name of a lower camel case json name, e.g. http is HTTP and gitRepo is GitRepo
+func goName(jsonName string) string {
+	if initialisms[jsonName] {
+		return strings.ToUpper(jsonName)
+	}
+	return strings.ToUpper(jsonName[:1]) + jsonName[1:]
+}
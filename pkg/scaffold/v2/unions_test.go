@@ -0,0 +1,50 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseUnion(t *testing.T) {
+	u, err := ParseUnion("spec.source=git|oci|gitRepo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := &Union{Field: "Source", JSONName: "source", Members: []UnionMember{
+		{Name: "Git", JSONName: "git"},
+		{Name: "OCI", JSONName: "oci"},
+		{Name: "GitRepo", JSONName: "gitRepo"},
+	}}
+	if !reflect.DeepEqual(u, expected) {
+		t.Errorf("unexpected union %+v", u)
+	}
+
+	for _, value := range []string{
+		"spec.source",
+		"spec.source=git",
+		"spec.source=git|git",
+		"spec.source=git|",
+		"spec.source=git|Oci",
+		"status.source=git|oci",
+	} {
+		if _, err := ParseUnion(value); err == nil {
+			t.Errorf("%s: expected an error", value)
+		}
+	}
+}
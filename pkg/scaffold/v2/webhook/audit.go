@@ -0,0 +1,306 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/internal"
+)
+
+const auditResourcesScaffoldMarker = "# +kubebuilder:scaffold:auditresources"
+
+var _ input.File = &Audit{}
+
+// Audit scaffolds the registration of the webhooks of a Resource adding audit annotations to their responses
+type Audit struct {
+	input.Input
+
+	// Resource is the Resource to make the Webhook for
+	Resource *resource.Resource
+
+	// Is the Group + "." + Domain for the Resource
+	GroupDomain string
+
+	// Is the Group + "." + Domain for the Resource
+	GroupDomainWithDash string
+
+	// If scaffold the defaulting webhook
+	Defaulting bool
+	// If scaffold the validating webhook
+	Validating bool
+}
+
+// GetInput implements input.File
+func (f *Audit) GetInput() (input.Input, error) {
+	_, f.GroupDomain = util.GetResourceInfo(f.ProjectPath, f.Resource, f.Repo, f.Domain, f.MultiGroup)
+	f.GroupDomainWithDash = strings.Replace(f.GroupDomain, ".", "-", -1)
+
+	if f.Path == "" {
+		f.Path = webhookFilePath(f.Resource, f.MultiGroup, f.Naming, "webhook_audit.go")
+	}
+	f.TemplateBody = auditTemplate
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *Audit) Validate() error {
+	if !f.Defaulting && !f.Validating {
+		return fmt.Errorf("audit annotations require a defaulting or validating webhook")
+	}
+	return f.Resource.Validate()
+}
+
+var _ input.File = &AuditTest{}
+
+// AuditTest scaffolds the tests asserting the audit annotations added by the webhooks of a Resource
+type AuditTest struct {
+	input.Input
+
+	// Resource is the Resource to make the Webhook for
+	Resource *resource.Resource
+
+	// If scaffold the defaulting webhook
+	Defaulting bool
+	// If scaffold the validating webhook
+	Validating bool
+	// If the validating webhook validates the unions of the spec, which the tested object doesn't set
+	ValidateUnions bool
+}
+
+// GetInput implements input.File
+func (f *AuditTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = webhookFilePath(f.Resource, f.MultiGroup, f.Naming, "webhook_audit_test.go")
+	}
+	if f.Validating && !f.ValidateUnions {
+		f.ValidateUnions = declaresUnions(f.ProjectPath, f.Resource, f.MultiGroup, f.Naming)
+	}
+	f.TemplateBody = auditTestTemplate
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *AuditTest) Validate() error {
+	return f.Resource.Validate()
+}
+
+var _ input.File = &AuditPolicy{}
+
+// AuditPolicy scaffolds an audit policy recording the audit annotations added by the webhooks
+type AuditPolicy struct {
+	input.Input
+
+	// Resource is a Resource whose webhooks add audit annotations
+	Resource *resource.Resource
+}
+
+// GetInput implements input.File
+func (f *AuditPolicy) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "audit", "policy.yaml")
+	}
+	f.TemplateBody = auditPolicyTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+// Update adds the Resource to the resources recorded by the audit policy
+func (f *AuditPolicy) Update() error {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "audit", "policy.yaml")
+	}
+
+	_, groupDomain := util.GetResourceInfo(f.ProjectPath, f.Resource, f.Repo, f.Domain, f.MultiGroup)
+	plural := f.Resource.Plural()
+
+	return internal.InsertStringsInFile(filepath.Join(f.ProjectPath, f.Path),
+		map[string][]string{
+			auditResourcesScaffoldMarker: {fmt.Sprintf("  - {group: %s, resources: [%s]}\n", groupDomain, plural)},
+		})
+}
+
+// webhookFilePath returns the path of a file next to the types of r named after its kind
+func webhookFilePath(r *resource.Resource, multiGroup bool, naming config.Naming, suffix string) string {
+	name := fmt.Sprintf("%s_%s", naming.FileName(r.Kind), suffix)
+	if multiGroup {
+		return filepath.Join("apis", r.Group, r.Version, name)
+	}
+	return filepath.Join("api", r.Version, name)
+}
+
+// nolint:lll
+const auditTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// {{ lower .Resource.Kind }}AuditAnnotations returns the audit annotations added to the responses of the {{ .Resource.Kind }} webhooks,
+// they are recorded in the audit events of the requests by the API server, prefixed by the name of the webhook.
+func {{ lower .Resource.Kind }}AuditAnnotations(req admission.Request, resp admission.Response) map[string]string {
+	annotations := map[string]string{
+		// who requested the change
+		"requested-by": req.UserInfo.Username,
+	}
+
+	// why the object was mutated or rejected
+	if len(resp.Patches) > 0 {
+		annotations["mutation"] = fmt.Sprintf("defaulted %d field(s)", len(resp.Patches))
+	}
+	if !resp.Allowed && resp.Result != nil {
+		annotations["denial-reason"] = resp.Result.Message
+	}
+
+	// TODO(user): add the annotations required by your compliance rules.
+	return annotations
+}
+
+// {{ lower .Resource.Kind }}AuditHandler adds the {{ lower .Resource.Kind }}AuditAnnotations to the responses of the wrapped handler
+type {{ lower .Resource.Kind }}AuditHandler struct {
+	admission.Handler
+}
+
+// Handle implements admission.Handler
+func (h *{{ lower .Resource.Kind }}AuditHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	resp := h.Handler.Handle(ctx, req)
+
+	if resp.AuditAnnotations == nil {
+		resp.AuditAnnotations = map[string]string{}
+	}
+	for key, value := range {{ lower .Resource.Kind }}AuditAnnotations(req, resp) {
+		resp.AuditAnnotations[key] = value
+	}
+	return resp
+}
+
+// InjectDecoder passes the decoder down to the wrapped handler
+func (h *{{ lower .Resource.Kind }}AuditHandler) InjectDecoder(d *admission.Decoder) error {
+	_, err := admission.InjectDecoderInto(d, h.Handler)
+	return err
+}
+
+// register{{ .Resource.Kind }}AuditedWebhooks registers the {{ .Resource.Kind }} webhooks adding audit annotations to their responses,
+// it must be called before the webhook builder which skips the paths that are already registered.
+func register{{ .Resource.Kind }}AuditedWebhooks(server *webhook.Server) {
+	{{- if .Defaulting }}
+	server.Register("/mutate-{{ .GroupDomainWithDash }}-{{ .Resource.Version }}-{{ lower .Resource.Kind }}", &webhook.Admission{
+		Handler: &{{ lower .Resource.Kind }}AuditHandler{Handler: admission.DefaultingWebhookFor(&{{ .Resource.Kind }}{}).Handler},
+	})
+	{{- end }}
+	{{- if .Validating }}
+	server.Register("/validate-{{ .GroupDomainWithDash }}-{{ .Resource.Version }}-{{ lower .Resource.Kind }}", &webhook.Admission{
+		Handler: &{{ lower .Resource.Kind }}AuditHandler{Handler: admission.ValidatingWebhookFor(&{{ .Resource.Kind }}{}).Handler},
+	})
+	{{- end }}
+}
+`
+
+const auditTestTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func Test{{ .Resource.Kind }}WebhookAuditAnnotations(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := AddToScheme(s); err != nil {
+		t.Fatalf("unable to add types to the scheme: %v", err)
+	}
+	decoder, err := admission.NewDecoder(s)
+	if err != nil {
+		t.Fatalf("unable to create the decoder: %v", err)
+	}
+
+	raw, err := json.Marshal(&{{ .Resource.Kind }}{
+		TypeMeta:   metav1.TypeMeta{APIVersion: GroupVersion.String(), Kind: "{{ .Resource.Kind }}"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+	})
+	if err != nil {
+		t.Fatalf("unable to encode the object: %v", err)
+	}
+	req := admission.Request{AdmissionRequest: admissionv1beta1.AdmissionRequest{
+		Operation: admissionv1beta1.Create,
+		Object:    runtime.RawExtension{Raw: raw},
+		UserInfo:  authenticationv1.UserInfo{Username: "jane"},
+	}}
+
+	handlers := map[string]admission.Handler{
+		{{- if .Defaulting }}
+		"defaulting": &{{ lower .Resource.Kind }}AuditHandler{Handler: admission.DefaultingWebhookFor(&{{ .Resource.Kind }}{}).Handler},
+		{{- end }}
+		{{- if .Validating }}
+		"validating": &{{ lower .Resource.Kind }}AuditHandler{Handler: admission.ValidatingWebhookFor(&{{ .Resource.Kind }}{}).Handler},
+		{{- end }}
+	}
+	for name, handler := range handlers {
+		if _, err := admission.InjectDecoderInto(decoder, handler); err != nil {
+			t.Fatalf("unable to inject the decoder into the %s webhook: %v", name, err)
+		}
+
+		resp := handler.Handle(context.Background(), req)
+		{{- if .ValidateUnions }}
+		// the unions of the spec are not set, the validating webhook denies the request
+		if !resp.Allowed && name != "validating" {
+		{{- else }}
+		if !resp.Allowed {
+		{{- end }}
+			t.Errorf("expected the %s webhook to allow the request, got: %v", name, resp.Result)
+		}
+		if got := resp.AuditAnnotations["requested-by"]; got != "jane" {
+			t.Errorf("expected the %s webhook to record who requested the change, got: %q", name, got)
+		}
+	}
+}
+`
+
+const auditPolicyTemplate = `# Audit policy recording the audit annotations added by the webhooks of the project.
+# Pass it to the API server with --audit-policy-file: audit annotations are recorded in the
+# audit events of the Metadata level and above, prefixed by the name of the webhook.
+apiVersion: audit.k8s.io/v1
+kind: Policy
+omitStages:
+- RequestReceived
+rules:
+- level: Metadata
+  resources:
+` + auditResourcesScaffoldMarker + `
+`
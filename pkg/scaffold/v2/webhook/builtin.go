@@ -0,0 +1,306 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+)
+
+var _ input.File = &BuiltinWebhook{}
+
+// BuiltinWebhook scaffolds the webhooks of a built-in kind, e.g. the Deployments. The methods of webhook.Defaulter
+// and webhook.Validator can't be declared on a type of another package, the webhooks are admission handlers
+// decoding the objects of the requests.
+type BuiltinWebhook struct {
+	input.Input
+
+	// Resource is the built-in Resource to make the webhooks for
+	Resource *resource.Resource
+
+	// ResourcePackage is the package of the versions of the group of the Resource, e.g. k8s.io/api/apps
+	ResourcePackage string
+
+	// Plural is the plural lowercase of kind
+	Plural string
+
+	// Is the Group + "." + Domain for the Resource
+	GroupDomain string
+
+	// Is the Group + "." + Domain for the Resource
+	GroupDomainWithDash string
+
+	// RBACGroup is the group of the webhook markers, the empty group for the kinds of the core group
+	RBACGroup string
+
+	// If scaffold the defaulting webhook
+	Defaulting bool
+	// If scaffold the validating webhook
+	Validating bool
+	// FailurePolicy is what the API server does when it can't call the webhooks, fail or ignore, defaults to fail
+	FailurePolicy string
+
+	// DefaultingPath and ValidatingPath are the paths the webhooks are served at by the webhook server, default to
+	// the paths of the webhooks of the kinds of the project, e.g. /mutate-apps-v1-deployment
+	DefaultingPath string
+	ValidatingPath string
+}
+
+// GetInput implements input.File
+func (f *BuiltinWebhook) GetInput() (input.Input, error) {
+	f.ResourcePackage, f.GroupDomain = util.BuiltinPackage(f.Resource), util.BuiltinGroup(f.Resource)
+	f.GroupDomainWithDash = strings.Replace(f.GroupDomain, ".", "-", -1)
+	f.RBACGroup = util.RBACGroup(f.GroupDomain)
+
+	if f.Plural == "" {
+		f.Plural = f.Resource.Plural()
+	}
+
+	if f.FailurePolicy == "" {
+		f.FailurePolicy = FailurePolicyFail
+	}
+
+	if f.DefaultingPath == "" {
+		f.DefaultingPath = fmt.Sprintf("/mutate-%s-%s-%s", f.GroupDomainWithDash, f.Resource.Version,
+			strings.ToLower(f.Resource.Kind))
+	}
+	if f.ValidatingPath == "" {
+		f.ValidatingPath = fmt.Sprintf("/validate-%s-%s-%s", f.GroupDomainWithDash, f.Resource.Version,
+			strings.ToLower(f.Resource.Kind))
+	}
+
+	if f.Path == "" {
+		f.Path = BuiltinWebhookPath(f.Resource, f.MultiGroup, f.Naming.FileName(f.Resource.Kind))
+	}
+
+	f.TemplateBody = builtinWebhookTemplate
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *BuiltinWebhook) Validate() error {
+	return f.Resource.Validate()
+}
+
+// BuiltinWebhookPath returns the path of the webhooks of a built-in kind, in the webhooks package or in the
+// package of its group in the multigroup layout, fileName is the name of the kind as named by the project
+func BuiltinWebhookPath(r *resource.Resource, multiGroup bool, fileName string) string {
+	if multiGroup {
+		return filepath.Join("webhooks", r.Group, fmt.Sprintf("%s_webhook.go", fileName))
+	}
+	return filepath.Join("webhooks", fmt.Sprintf("%s_webhook.go", fileName))
+}
+
+// webhookPathRe matches the paths of the webhooks which can be set in their markers, e.g. /mutate-pods
+var webhookPathRe = regexp.MustCompile(`^(/[a-zA-Z0-9._-]+)+$`)
+
+// ValidateWebhookPath validates the path a webhook of a built-in kind is served at, which must not be the path of
+// the conversion webhook nor the one of another webhook of the project in dir
+func ValidateWebhookPath(dir, path string) error {
+	if !webhookPathRe.MatchString(path) {
+		return fmt.Errorf("invalid webhook path %q, expected an absolute path of letters, digits, '.', '_' and '-', "+
+			"e.g. /mutate-pods", path)
+	}
+	if path == "/convert" {
+		return fmt.Errorf("the webhook path /convert is the path of the conversion webhook")
+	}
+
+	// the paths of the webhooks are the ones of their markers
+	marker := "path=" + path + ","
+	if dir == "" {
+		dir = "."
+	}
+	return afero.Walk(filesystem.FS, dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if p != dir && (info.Name() == "vendor" || info.Name() == "bin" || info.Name() == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(p) != ".go" {
+			return nil
+		}
+		content, err := filesystem.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if strings.Contains(string(content), marker) {
+			rel, _ := filepath.Rel(dir, p)
+			return fmt.Errorf("the webhook path %s is the path of a webhook of %s", path, rel)
+		}
+		return nil
+	})
+}
+
+// nolint:lll
+const builtinWebhookTemplate = `{{ .Boilerplate }}
+
+package webhooks
+
+import (
+	"context"
+	{{- if .Defaulting }}
+	"encoding/json"
+	{{- end }}
+	"net/http"
+
+	{{- if .Validating }}
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	{{- end }}
+	{{ .Resource.GroupImportSafe }}{{ .Resource.Version }} "{{ .ResourcePackage }}/{{ .Resource.Version }}"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var {{ lower .Resource.Kind }}log = logf.Log.WithName("{{ lower .Resource.Kind }}-resource")
+
+// Setup{{ .Resource.Kind }}WebhookWithManager registers the webhooks of the {{ .Plural }} in the webhook server of the manager.
+// {{ .Resource.Kind }} is a built-in kind, its webhooks decode the objects of the requests.
+func Setup{{ .Resource.Kind }}WebhookWithManager(mgr ctrl.Manager) {
+{{- if .Defaulting }}
+	mgr.GetWebhookServer().Register("{{ .DefaultingPath }}", &webhook.Admission{Handler: &{{ .Resource.Kind }}Defaulter{}})
+{{- end }}
+{{- if .Validating }}
+	mgr.GetWebhookServer().Register("{{ .ValidatingPath }}", &webhook.Admission{Handler: &{{ .Resource.Kind }}Validator{}})
+{{- end }}
+}
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+{{- if .Defaulting }}
+
+// +kubebuilder:webhook:path={{ .DefaultingPath }},mutating=true,failurePolicy={{ .FailurePolicy }},groups={{ .RBACGroup }},resources={{ .Plural }},verbs=create;update,versions={{ .Resource.Version }},name={{ .Resource.WebhookName "m" }}
+
+// {{ .Resource.Kind }}Defaulter is the defaulting webhook of the {{ .Plural }}
+type {{ .Resource.Kind }}Defaulter struct {
+	decoder *admission.Decoder
+}
+
+var _ admission.DecoderInjector = &{{ .Resource.Kind }}Defaulter{}
+
+// InjectDecoder implements admission.DecoderInjector
+func (d *{{ .Resource.Kind }}Defaulter) InjectDecoder(decoder *admission.Decoder) error {
+	d.decoder = decoder
+	return nil
+}
+
+// Handle implements admission.Handler, it patches the {{ .Resource.Kind }} of the request into the defaulted one
+func (d *{{ .Resource.Kind }}Defaulter) Handle(_ context.Context, req admission.Request) admission.Response {
+	obj := &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{}
+	if err := d.decoder.Decode(req, obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	{{ lower .Resource.Kind }}log.Info("default", "name", obj.Name)
+
+	// TODO(user): fill in your defaulting logic.
+
+	defaulted, err := json.Marshal(obj)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, defaulted)
+}
+{{- end }}
+{{- if .Validating }}
+
+// TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
+// +kubebuilder:webhook:verbs=create;update,path={{ .ValidatingPath }},mutating=false,failurePolicy={{ .FailurePolicy }},groups={{ .RBACGroup }},resources={{ .Plural }},versions={{ .Resource.Version }},name={{ .Resource.WebhookName "v" }}
+
+// {{ .Resource.Kind }}Validator is the validating webhook of the {{ .Plural }}
+type {{ .Resource.Kind }}Validator struct {
+	decoder *admission.Decoder
+}
+
+var _ admission.DecoderInjector = &{{ .Resource.Kind }}Validator{}
+
+// InjectDecoder implements admission.DecoderInjector
+func (v *{{ .Resource.Kind }}Validator) InjectDecoder(decoder *admission.Decoder) error {
+	v.decoder = decoder
+	return nil
+}
+
+// Handle implements admission.Handler, it denies the request if the {{ .Resource.Kind }} is invalid
+func (v *{{ .Resource.Kind }}Validator) Handle(_ context.Context, req admission.Request) admission.Response {
+	obj, old := &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{}, &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{}
+	// the object of a deletion is the old object
+	if req.Operation != admissionv1beta1.Delete {
+		if err := v.decoder.Decode(req, obj); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+	if req.Operation != admissionv1beta1.Create {
+		if err := v.decoder.DecodeRaw(req.OldObject, old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+
+	var err error
+	switch req.Operation {
+	case admissionv1beta1.Create:
+		err = v.validateCreate(obj)
+	case admissionv1beta1.Update:
+		err = v.validateUpdate(obj, old)
+	case admissionv1beta1.Delete:
+		err = v.validateDelete(old)
+	}
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+	return admission.Allowed("")
+}
+
+// validateCreate validates the {{ .Resource.Kind }} of a creation
+func (v *{{ .Resource.Kind }}Validator) validateCreate(obj *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) error {
+	{{ lower .Resource.Kind }}log.Info("validate create", "name", obj.Name)
+
+	// TODO(user): fill in your validation logic upon object creation.
+	return nil
+}
+
+// validateUpdate validates the {{ .Resource.Kind }} of an update
+func (v *{{ .Resource.Kind }}Validator) validateUpdate(obj, old *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) error {
+	{{ lower .Resource.Kind }}log.Info("validate update", "name", obj.Name)
+
+	// TODO(user): fill in your validation logic upon object update.
+	return nil
+}
+
+// validateDelete validates the {{ .Resource.Kind }} of a deletion
+func (v *{{ .Resource.Kind }}Validator) validateDelete(old *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) error {
+	{{ lower .Resource.Kind }}log.Info("validate delete", "name", old.Name)
+
+	// TODO(user): fill in your validation logic upon object deletion.
+	return nil
+}
+{{- end }}
+`
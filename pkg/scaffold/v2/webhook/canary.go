@@ -0,0 +1,175 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/internal"
+)
+
+// CanaryDir is the directory of the canary overlay of the webhooks, relative to the directory of the webhook
+// manifests. The overlay is a base of config/default rather than a kustomize component, which kustomize v3.5
+// doesn't support.
+const CanaryDir = "canary"
+
+// canaryLabel returns the label of the namespaces whose requests the canary webhooks are called for
+func canaryLabel(domain string) string {
+	return domain + "/webhook-canary"
+}
+
+var _ input.File = &CanaryKustomization{}
+
+// CanaryKustomization scaffolds the overlay of the webhook manifests deploying the canary webhooks, listing the
+// patches of the webhooks of every Resource rolled out
+type CanaryKustomization struct {
+	input.Input
+
+	// Label is the label of the namespaces the canary webhooks are called for
+	Label string
+}
+
+// GetInput implements input.File
+func (f *CanaryKustomization) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join(f.WebhookDir, CanaryDir, "kustomization.yaml")
+	}
+	f.Label = canaryLabel(f.Domain)
+	f.TemplateBody = canaryKustomizationTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+const canaryKustomizationTemplate = `# Deploys the webhooks in canary: the webhooks patched below admit the requests when they can't be called, and are
+# only called for the requests of the namespaces labelled {{ .Label }}=true, e.g.
+#   kubectl label namespace <namespace> {{ .Label }}=true
+# config/default deploys this overlay while the webhooks are rolled out. Once they are trusted in production,
+# make webhook-promote deploys them for every namespace and with their own failure policy, Fail by default, and
+# removes this overlay.
+bases:
+- ..
+
+patchesStrategicMerge:
+`
+
+var _ input.File = &CanaryPatch{}
+
+// CanaryPatch scaffolds the patch of the canary overlay rolling out the webhooks of a Resource: they admit the
+// requests when they can't be called and are only called for the namespaces labelled for the canary
+type CanaryPatch struct {
+	input.Input
+
+	// Resource is the Resource of the webhooks
+	Resource *resource.Resource
+
+	// Defaulting and Validating select the webhooks rolled out
+	Defaulting bool
+	Validating bool
+
+	// Label is the label of the namespaces the canary webhooks are called for
+	Label string
+
+	// DefaultKustomization is the path of the kustomization of config/default, which deploys the overlay
+	DefaultKustomization string
+}
+
+// GetInput implements input.File
+func (f *CanaryPatch) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join(f.WebhookDir, CanaryDir,
+			fmt.Sprintf("canary_in_%s.yaml", f.Resource.QualifiedName(f.Resource.Plural(), "_")))
+	}
+	if f.DefaultKustomization == "" {
+		f.DefaultKustomization = filepath.Join("config", "default", "kustomization.yaml")
+	}
+	f.Label = canaryLabel(f.Domain)
+	f.TemplateBody = canaryPatchTemplate
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *CanaryPatch) Validate() error {
+	return f.Resource.Validate()
+}
+
+// Update references the scaffolded patch from the canary overlay, and deploys the overlay from config/default
+// instead of the webhook manifests, whether they are enabled yet or not
+func (f *CanaryPatch) Update() error {
+	if err := internal.AddToKustomization(filepath.Join(f.ProjectPath, filepath.Dir(f.Path), "kustomization.yaml"),
+		"patchesStrategicMerge", filepath.Base(f.Path)); err != nil {
+		return err
+	}
+
+	path := filepath.Join(f.ProjectPath, f.DefaultKustomization)
+	content, err := filesystem.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	base, err := filepath.Rel(filepath.Dir(f.DefaultKustomization), f.WebhookDir)
+	if err != nil {
+		return err
+	}
+	base = filepath.ToSlash(base)
+	baseRe := regexp.MustCompile(`(?m)^(#?- )` + regexp.QuoteMeta(base) + `$`)
+	updated := baseRe.ReplaceAllString(string(content), "${1}"+base+"/"+CanaryDir)
+	if updated == string(content) {
+		return nil
+	}
+	return filesystem.WriteFile(path, []byte(updated), 0644)
+}
+
+const canaryPatchTemplate = `# Rolls out the webhooks of the {{ .Resource.Kind }}: they admit the requests when they can't be called, and are
+# only called for the requests of the namespaces labelled {{ .Label }}=true. The namespace selector doesn't apply
+# to the requests of the cluster-scoped kinds, only their failure policy is staged.
+{{- if .Defaulting }}
+apiVersion: admissionregistration.k8s.io/{{ .Kubernetes.WebhookVersion }}
+kind: MutatingWebhookConfiguration
+metadata:
+  name: mutating-webhook-configuration
+webhooks:
+- name: {{ .Resource.WebhookName "m" }}
+  failurePolicy: Ignore
+  namespaceSelector:
+    matchLabels:
+      {{ .Label }}: "true"
+{{- end }}
+{{- if and .Defaulting .Validating }}
+---
+{{- end }}
+{{- if .Validating }}
+apiVersion: admissionregistration.k8s.io/{{ .Kubernetes.WebhookVersion }}
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: validating-webhook-configuration
+webhooks:
+- name: {{ .Resource.WebhookName "v" }}
+  failurePolicy: Ignore
+  namespaceSelector:
+    matchLabels:
+      {{ .Label }}: "true"
+{{- end }}
+`
@@ -0,0 +1,141 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+)
+
+var _ input.File = &CertReadiness{}
+
+// CertReadiness scaffolds a readiness check verifying the webhook serving certificate
+type CertReadiness struct {
+	input.Input
+
+	// Prefix is the kustomize name prefix used to compute the webhook service DNS names
+	Prefix string
+}
+
+// GetInput implements input.File
+func (f *CertReadiness) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("webhookcert", "readiness.go")
+	}
+	if f.Prefix == "" {
+		// use directory name as prefix
+		prefix, err := util.ProjectName(f.ProjectPath)
+		if err != nil {
+			return input.Input{}, err
+		}
+		f.Prefix = prefix
+	}
+	f.TemplateBody = certReadinessTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+const certReadinessTemplate = `{{ .Boilerplate }}
+
+// Package webhookcert contains a readiness check for the webhook serving certificate
+package webhookcert
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// CertDir is the directory the webhook server loads the serving certificate from
+	CertDir = filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs")
+
+	// DNSNames are the names the serving certificate must be valid for.
+	// TODO(user): update them if you change the namePrefix or namespace in config/default/kustomization.yaml.
+	DNSNames = []string{
+		"{{ .Prefix }}-webhook-service.{{ .Prefix }}-system.svc",
+		"{{ .Prefix }}-webhook-service.{{ .Prefix }}-system.svc.cluster.local",
+	}
+
+	// MinValidity is the minimum remaining validity of the serving certificate
+	MinValidity = 7 * 24 * time.Hour
+
+	certExpiration = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "webhook_certificate_expiration_timestamp_seconds",
+		Help: "Expiration time of the webhook serving certificate in seconds since the epoch.",
+	})
+	certValid = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "webhook_certificate_valid",
+		Help: "Whether the webhook serving certificate is valid for the webhook service (1) or not (0).",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(certExpiration, certValid)
+}
+
+// ReadinessCheck fails if the serving certificate does not match the webhook service
+// DNS names or if it expires in less than MinValidity.
+func ReadinessCheck(_ *http.Request) error {
+	if err := checkCertificate(); err != nil {
+		certValid.Set(0)
+		return err
+	}
+	certValid.Set(1)
+	return nil
+}
+
+func checkCertificate() error {
+	certPEM, err := ioutil.ReadFile(filepath.Join(CertDir, "tls.crt"))
+	if err != nil {
+		return fmt.Errorf("unable to read the serving certificate: %v", err)
+	}
+
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return fmt.Errorf("unable to decode the serving certificate")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("unable to parse the serving certificate: %v", err)
+	}
+	certExpiration.Set(float64(cert.NotAfter.Unix()))
+
+	for _, name := range DNSNames {
+		if err := cert.VerifyHostname(name); err != nil {
+			return fmt.Errorf("serving certificate is not valid for %s: %v", name, err)
+		}
+	}
+
+	if remaining := time.Until(cert.NotAfter); remaining < MinValidity {
+		return fmt.Errorf("serving certificate expires in %s", remaining.Round(time.Minute))
+	}
+
+	return nil
+}
+`
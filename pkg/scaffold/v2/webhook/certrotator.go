@@ -0,0 +1,636 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+)
+
+// CertRotatorSecretName is the name of the secret of the serving certificate of the webhook server generated by the
+// certificate rotator, the one cert-manager issues the certificate to otherwise
+const CertRotatorSecretName = "webhook-server-cert"
+
+var _ input.File = &CertRotator{}
+
+// CertRotator scaffolds the package provisioning the serving certificate of the webhook server without
+// cert-manager: the manager generates a self-signed CA and the certificate, injects the CA into the webhooks and
+// renews the certificate before it expires
+type CertRotator struct {
+	input.Input
+
+	// Prefix is the kustomize name prefix used to compute the name and the namespace of the webhook service
+	Prefix string
+
+	// SecretName is the name of the secret of the CA and of the certificate
+	SecretName string
+}
+
+// GetInput implements input.File
+func (f *CertRotator) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "certrotator", "rotator.go")
+	}
+	if f.Prefix == "" {
+		// use directory name as prefix
+		prefix, err := util.ProjectName(f.ProjectPath)
+		if err != nil {
+			return input.Input{}, err
+		}
+		f.Prefix = prefix
+	}
+	f.SecretName = CertRotatorSecretName
+	f.TemplateBody = certRotatorTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+var _ input.File = &CertRotatorTest{}
+
+// CertRotatorTest scaffolds the tests of the certificate rotator, against a fake client and driven by a fake clock
+type CertRotatorTest struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *CertRotatorTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("internal", "certrotator", "rotator_test.go")
+	}
+	f.TemplateBody = certRotatorTestTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+const certRotatorTemplate = `{{ .Boilerplate }}
+
+// Package certrotator provisions the serving certificate of the webhook server without cert-manager. The manager
+// generates a self-signed CA and a serving certificate signed by it, stored in a secret shared by its replicas,
+// writes the certificate to the directory the webhook server reloads it from, and injects the CA into the webhook
+// configurations and the conversion webhooks of the CRDs calling the webhook service. The certificate is renewed
+// before it expires, and the CA injected again into the webhooks applied without it, e.g. by make deploy.
+package certrotator
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	admissionregistration "k8s.io/api/admissionregistration/{{ .Kubernetes.WebhookVersion }}"
+	corev1 "k8s.io/api/core/v1"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/{{ .Kubernetes.CRDVersion }}"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;create;update
+// +kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=mutatingwebhookconfigurations;validatingwebhookconfigurations,verbs=get;list;update
+// +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;update
+
+var (
+	// CertDir is the directory the webhook server loads the serving certificate from, an emptyDir volume of the
+	// manager, see config/default/manager_webhook_patch.yaml
+	CertDir = filepath.Join(os.TempDir(), "k8s-webhook-server", "serving-certs")
+
+	// ServiceName is the name of the webhook service, the certificate is valid for its DNS names.
+	// TODO(user): update it if you change the namePrefix in config/default/kustomization.yaml.
+	ServiceName = "{{ .Prefix }}-webhook-service"
+
+	// SecretName is the name of the secret of the CA and of the certificate, in the namespace of the manager
+	SecretName = "{{ .SecretName }}"
+
+	// CAValidity and CertValidity are the validity of the CA and of the serving certificates it signs
+	CAValidity   = 10 * 365 * 24 * time.Hour
+	CertValidity = 90 * 24 * time.Hour
+
+	// RenewBefore is the remaining validity under which the certificate is renewed
+	RenewBefore = 30 * 24 * time.Hour
+
+	// Interval is the interval at which the certificate and the injection of the CA are checked
+	Interval = 10 * time.Minute
+)
+
+// defaultNamespace is the namespace of the manager out of a cluster, in a cluster it is the one of its service
+// account
+const defaultNamespace = "{{ .Prefix }}-system"
+
+const (
+	// caCertKey and caKeyKey are the keys of the CA in the secret, along with the ones of the certificate
+	caCertKey = "ca.crt"
+	caKeyKey  = "ca.key"
+)
+
+// Rotator keeps the serving certificate of the webhook server valid and its CA injected into the webhooks calling
+// the webhook service
+type Rotator struct {
+	Client client.Client
+	Log    logr.Logger
+	Clock  clock.Clock
+
+	// Namespace is the namespace of the manager, of the webhook service and of the secret
+	Namespace string
+}
+
+// SetupWithManager provisions the certificate before the webhook server loads it, and adds the rotator to the
+// manager, which renews the certificate until the manager stops
+func SetupWithManager(mgr ctrl.Manager) error {
+	// the cache of the manager isn't started yet, and the CRDs aren't in its scheme
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return err
+	}
+	if err := apiextensions.AddToScheme(scheme); err != nil {
+		return err
+	}
+	c, err := client.New(mgr.GetConfig(), client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+
+	r := &Rotator{
+		Client:    c,
+		Log:       ctrl.Log.WithName("certrotator"),
+		Clock:     clock.RealClock{},
+		Namespace: namespace(),
+	}
+	if err := r.Rotate(context.Background()); err != nil {
+		return fmt.Errorf("unable to provision the serving certificate: %v", err)
+	}
+	return mgr.Add(r)
+}
+
+// Start implements manager.Runnable, it rotates the certificate every Interval until stop is closed
+func (r *Rotator) Start(stop <-chan struct{}) error {
+	ticker := r.Clock.NewTicker(Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return nil
+		case <-ticker.C():
+			if err := r.Rotate(context.Background()); err != nil {
+				r.Log.Error(err, "unable to rotate the serving certificate")
+			}
+		}
+	}
+}
+
+// Rotate renews the certificate of the secret if it is missing, invalid or about to expire, writes it to CertDir
+// and injects the CA into the webhooks calling the webhook service
+func (r *Rotator) Rotate(ctx context.Context) error {
+	secret, err := r.ensureCertificate(ctx)
+	if err != nil {
+		return err
+	}
+	if err := writeCertificate(CertDir, secret.Data); err != nil {
+		return fmt.Errorf("unable to write the serving certificate to %s: %v", CertDir, err)
+	}
+	return r.injectCA(ctx, secret.Data[caCertKey])
+}
+
+// DNSNames returns the names the certificate is valid for, the ones of the webhook service
+func (r *Rotator) DNSNames() []string {
+	return []string{
+		fmt.Sprintf("%s.%s.svc", ServiceName, r.Namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", ServiceName, r.Namespace),
+	}
+}
+
+// ensureCertificate returns the secret of the certificate, renewed if needed. The replicas of the manager share the
+// secret: the certificate of the replica which renews it first is kept.
+func (r *Rotator) ensureCertificate(ctx context.Context) (*corev1.Secret, error) {
+	key := types.NamespacedName{Namespace: r.Namespace, Name: SecretName}
+	secret := &corev1.Secret{}
+	err := r.Client.Get(ctx, key, secret)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, err
+	}
+	exists := err == nil
+
+	now := r.Clock.Now()
+	if exists && r.valid(secret.Data, now) {
+		return secret, nil
+	}
+
+	// the CA is kept while it outlives the certificate, the webhooks trust the renewed certificate right away
+	ca, caKey := parseCA(secret.Data, now.Add(CertValidity))
+	if ca == nil {
+		var err error
+		ca, caKey, err = issue(&x509.Certificate{
+			Subject:               pkix.Name{CommonName: ServiceName + "-ca"},
+			NotBefore:             now.Add(-time.Hour),
+			NotAfter:              now.Add(CAValidity),
+			KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+			BasicConstraintsValid: true,
+			IsCA:                  true,
+		}, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cert, certKey, err := issue(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: r.DNSNames()[0]},
+		DNSNames:    r.DNSNames(),
+		NotBefore:   now.Add(-time.Hour),
+		NotAfter:    now.Add(CertValidity),
+		KeyUsage:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, ca, caKey)
+	if err != nil {
+		return nil, err
+	}
+	secret.Data = map[string][]byte{
+		caCertKey:               encodeCert(ca),
+		caKeyKey:                encodeKey(caKey),
+		corev1.TLSCertKey:       encodeCert(cert),
+		corev1.TLSPrivateKeyKey: encodeKey(certKey),
+	}
+
+	if exists {
+		err = r.Client.Update(ctx, secret)
+	} else {
+		secret.Name, secret.Namespace, secret.Type = SecretName, r.Namespace, corev1.SecretTypeTLS
+		err = r.Client.Create(ctx, secret)
+	}
+	if apierrors.IsAlreadyExists(err) || apierrors.IsConflict(err) {
+		// another replica renewed the certificate first
+		secret = &corev1.Secret{}
+		if err := r.Client.Get(ctx, key, secret); err != nil {
+			return nil, err
+		}
+		return secret, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to store the serving certificate in the secret %s: %v", key, err)
+	}
+	r.Log.Info("renewed the serving certificate", "secret", key.String(), "notAfter", cert.NotAfter)
+	return secret, nil
+}
+
+// valid returns true if the certificate of the secret is signed by its CA, valid for the DNS names of the webhook
+// service and doesn't expire within RenewBefore
+func (r *Rotator) valid(data map[string][]byte, now time.Time) bool {
+	ca, _ := parseCA(data, now)
+	cert := parseCert(data[corev1.TLSCertKey])
+	if ca == nil || cert == nil || len(data[corev1.TLSPrivateKeyKey]) == 0 {
+		return false
+	}
+	if cert.NotAfter.Sub(now) < RenewBefore {
+		return false
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	for _, name := range r.DNSNames() {
+		_, err := cert.Verify(x509.VerifyOptions{DNSName: name, Roots: roots, CurrentTime: now})
+		if err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// injectCA sets the CA bundle of the webhook configurations and of the conversion webhooks of the CRDs calling the
+// webhook service to the CA
+func (r *Rotator) injectCA(ctx context.Context, ca []byte) error {
+	mutating := &admissionregistration.MutatingWebhookConfigurationList{}
+	if err := r.Client.List(ctx, mutating); err != nil {
+		return err
+	}
+	for i := range mutating.Items {
+		configuration, injected := &mutating.Items[i], false
+		for j := range configuration.Webhooks {
+			config := &configuration.Webhooks[j].ClientConfig
+			if s := config.Service; s != nil && r.inject(s.Namespace, s.Name, &config.CABundle, ca) {
+				injected = true
+			}
+		}
+		if err := r.update(ctx, configuration, injected); err != nil {
+			return err
+		}
+	}
+
+	validating := &admissionregistration.ValidatingWebhookConfigurationList{}
+	if err := r.Client.List(ctx, validating); err != nil {
+		return err
+	}
+	for i := range validating.Items {
+		configuration, injected := &validating.Items[i], false
+		for j := range configuration.Webhooks {
+			config := &configuration.Webhooks[j].ClientConfig
+			if s := config.Service; s != nil && r.inject(s.Namespace, s.Name, &config.CABundle, ca) {
+				injected = true
+			}
+		}
+		if err := r.update(ctx, configuration, injected); err != nil {
+			return err
+		}
+	}
+
+	crds := &apiextensions.CustomResourceDefinitionList{}
+	if err := r.Client.List(ctx, crds); err != nil {
+		return err
+	}
+	for i := range crds.Items {
+		crd, injected := &crds.Items[i], false
+{{- if eq .Kubernetes.CRDVersion "v1beta1" }}
+		if crd.Spec.Conversion != nil && crd.Spec.Conversion.WebhookClientConfig != nil {
+			config := crd.Spec.Conversion.WebhookClientConfig
+{{- else }}
+		if crd.Spec.Conversion != nil && crd.Spec.Conversion.Webhook != nil &&
+			crd.Spec.Conversion.Webhook.ClientConfig != nil {
+			config := crd.Spec.Conversion.Webhook.ClientConfig
+{{- end }}
+			if s := config.Service; s != nil && r.inject(s.Namespace, s.Name, &config.CABundle, ca) {
+				injected = true
+			}
+		}
+		if err := r.update(ctx, crd, injected); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inject sets the CA bundle of the client configuration of a webhook to the CA if the webhook calls the webhook
+// service, it returns true if the bundle changed
+func (r *Rotator) inject(namespace, name string, caBundle *[]byte, ca []byte) bool {
+	if namespace != r.Namespace || name != ServiceName || bytes.Equal(*caBundle, ca) {
+		return false
+	}
+	*caBundle = ca
+	return true
+}
+
+// update updates the object whose CA was injected
+func (r *Rotator) update(ctx context.Context, obj runtime.Object, injected bool) error {
+	if !injected {
+		return nil
+	}
+	if err := r.Client.Update(ctx, obj); err != nil {
+		return fmt.Errorf("unable to inject the CA: %v", err)
+	}
+	return nil
+}
+
+// issue generates a key and a certificate from the template, signed by the parent or self-signed if the parent is
+// nil
+func issue(template, parent *x509.Certificate, parentKey *rsa.PrivateKey) (*x509.Certificate, *rsa.PrivateKey, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	template.SerialNumber = serial
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+// parseCA returns the CA of the secret and its key, nil if they are missing or if the CA expires before notAfter
+func parseCA(data map[string][]byte, notAfter time.Time) (*x509.Certificate, *rsa.PrivateKey) {
+	ca := parseCert(data[caCertKey])
+	block, _ := pem.Decode(data[caKeyKey])
+	if ca == nil || block == nil || ca.NotAfter.Before(notAfter) {
+		return nil, nil
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil
+	}
+	return ca, key
+}
+
+// parseCert returns the certificate encoded in PEM, nil if it can't be parsed
+func parseCert(data []byte) *x509.Certificate {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil
+	}
+	return cert
+}
+
+func encodeCert(cert *x509.Certificate) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+}
+
+func encodeKey(key *rsa.PrivateKey) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+// writeCertificate writes the certificate and its key to the directory, unless they are written already: the
+// webhook server reloads them when they change
+func writeCertificate(dir string, data map[string][]byte) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	for _, name := range []string{corev1.TLSPrivateKeyKey, corev1.TLSCertKey} {
+		path := filepath.Join(dir, name)
+		if current, err := ioutil.ReadFile(path); err == nil && bytes.Equal(current, data[name]) {
+			continue
+		}
+		if err := ioutil.WriteFile(path, data[name], 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// namespace returns the namespace of the manager, the one of its service account in a cluster
+func namespace() string {
+	ns, err := ioutil.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace")
+	if err != nil {
+		return defaultNamespace
+	}
+	return strings.TrimSpace(string(ns))
+}
+`
+
+const certRotatorTestTemplate = `{{ .Boilerplate }}
+
+package certrotator
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	admissionregistration "k8s.io/api/admissionregistration/{{ .Kubernetes.WebhookVersion }}"
+	corev1 "k8s.io/api/core/v1"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/{{ .Kubernetes.CRDVersion }}"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testNamespace = "test-system"
+
+// newTestRotator returns a rotator writing the certificate to a temporary directory, against a fake client holding
+// a validating webhook configuration calling the webhook service and another service. The directory is removed by
+// the returned function.
+func newTestRotator(t *testing.T) (*Rotator, *clock.FakeClock, func()) {
+	dir, err := ioutil.TempDir("", "certrotator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	CertDir = dir
+
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = apiextensions.AddToScheme(scheme)
+	configuration := &admissionregistration.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "validating-webhook-configuration"},
+		Webhooks: []admissionregistration.ValidatingWebhook{
+			{
+				Name: "webhook.example.org",
+				ClientConfig: admissionregistration.WebhookClientConfig{
+					Service: &admissionregistration.ServiceReference{Namespace: testNamespace, Name: ServiceName},
+				},
+			},
+			{
+				Name: "other.example.org",
+				ClientConfig: admissionregistration.WebhookClientConfig{
+					Service: &admissionregistration.ServiceReference{Namespace: testNamespace, Name: "other"},
+				},
+			},
+		},
+	}
+
+	clk := clock.NewFakeClock(time.Now())
+	return &Rotator{
+		Client:    fake.NewFakeClientWithScheme(scheme, configuration),
+		Log:       ctrl.Log.WithName("certrotator"),
+		Clock:     clk,
+		Namespace: testNamespace,
+	}, clk, func() { _ = os.RemoveAll(dir) }
+}
+
+func getSecret(t *testing.T, c client.Client) *corev1.Secret {
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: testNamespace, Name: SecretName}
+	if err := c.Get(context.Background(), key, secret); err != nil {
+		t.Fatalf("expected the secret %s: %v", key, err)
+	}
+	return secret
+}
+
+func TestRotateProvisionsCertificate(t *testing.T) {
+	r, _, cleanup := newTestRotator(t)
+	defer cleanup()
+	if err := r.Rotate(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	secret := getSecret(t, r.Client)
+	if !r.valid(secret.Data, r.Clock.Now()) {
+		t.Error("expected the certificate to be valid for the webhook service")
+	}
+	if _, err := tls.LoadX509KeyPair(filepath.Join(CertDir, corev1.TLSCertKey),
+		filepath.Join(CertDir, corev1.TLSPrivateKeyKey)); err != nil {
+		t.Errorf("expected the certificate to be written to %s: %v", CertDir, err)
+	}
+
+	configuration := &admissionregistration.ValidatingWebhookConfiguration{}
+	key := types.NamespacedName{Name: "validating-webhook-configuration"}
+	if err := r.Client.Get(context.Background(), key, configuration); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(configuration.Webhooks[0].ClientConfig.CABundle, secret.Data[caCertKey]) {
+		t.Error("expected the CA to be injected into the webhook calling the webhook service")
+	}
+	if len(configuration.Webhooks[1].ClientConfig.CABundle) != 0 {
+		t.Error("expected the webhook calling another service to be left as is")
+	}
+}
+
+func TestRotateRenewsCertificate(t *testing.T) {
+	r, clk, cleanup := newTestRotator(t)
+	defer cleanup()
+	if err := r.Rotate(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first := getSecret(t, r.Client)
+
+	clk.Step(CertValidity - RenewBefore - time.Hour)
+	if err := r.Rotate(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(getSecret(t, r.Client).Data[corev1.TLSCertKey], first.Data[corev1.TLSCertKey]) {
+		t.Error("expected the certificate to be kept until RenewBefore")
+	}
+
+	clk.Step(2 * time.Hour)
+	if err := r.Rotate(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	renewed := getSecret(t, r.Client)
+	if bytes.Equal(renewed.Data[corev1.TLSCertKey], first.Data[corev1.TLSCertKey]) {
+		t.Error("expected the certificate to be renewed within RenewBefore")
+	}
+	if !bytes.Equal(renewed.Data[caCertKey], first.Data[caCertKey]) {
+		t.Error("expected the CA to be kept, the webhooks trust the renewed certificate")
+	}
+	written, err := ioutil.ReadFile(filepath.Join(CertDir, corev1.TLSCertKey))
+	if err != nil || !bytes.Equal(written, renewed.Data[corev1.TLSCertKey]) {
+		t.Errorf("expected the renewed certificate to be written to %s: %v", CertDir, err)
+	}
+}
+`
@@ -0,0 +1,326 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/internal"
+)
+
+const (
+	// ConversionServerPort is the port of the conversion webhook server, the admission webhooks are served on 9443
+	ConversionServerPort = 9444
+
+	// ConversionServiceName is the name of the service of the conversion webhook server, the conversion patches of
+	// the CRDs served by the server point to it
+	ConversionServiceName = "conversion-service"
+
+	// ConversionCertificateName is the name of the certificate of the conversion webhook server, injected by
+	// cert-manager into the CRDs served by the server
+	ConversionCertificateName = "conversion-serving-cert"
+
+	// conversionSecretName is the name of the secret of the certificate of the conversion webhook server
+	conversionSecretName = "conversion-server-cert"
+
+	// conversionManagerPatchFile is the patch of the manager mounting the certificate of the server
+	conversionManagerPatchFile = "manager_conversion_patch.yaml"
+)
+
+// conversionDir is the directory of the manifests of the conversion webhook server
+var conversionDir = filepath.Join("config", "conversion")
+
+var _ input.File = &ConversionServer{}
+
+// ConversionServer scaffolds the package serving the conversion webhooks from a webhook server of their own
+type ConversionServer struct {
+	input.Input
+
+	// Port is the port of the server
+	Port int
+}
+
+// GetInput implements input.File
+func (f *ConversionServer) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("conversionserver", "server.go")
+	}
+	f.Port = ConversionServerPort
+	f.TemplateBody = conversionServerTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+var _ input.File = &ConversionService{}
+
+// ConversionService scaffolds the service of the conversion webhook server
+type ConversionService struct {
+	input.Input
+
+	// Name and Port are the name of the service and the port of the server
+	Name string
+	Port int
+}
+
+// GetInput implements input.File
+func (f *ConversionService) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join(conversionDir, "service.yaml")
+	}
+	f.Name, f.Port = ConversionServiceName, ConversionServerPort
+	f.TemplateBody = conversionServiceTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+var _ input.File = &ConversionCertificate{}
+
+// ConversionCertificate scaffolds the certificate of the conversion webhook server, issued by cert-manager
+// independently of the one of the admission webhooks
+type ConversionCertificate struct {
+	input.Input
+
+	// Name and SecretName are the names of the certificate and of its secret
+	Name, SecretName string
+}
+
+// GetInput implements input.File
+func (f *ConversionCertificate) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join(conversionDir, "certificate.yaml")
+	}
+	f.Name, f.SecretName = ConversionCertificateName, conversionSecretName
+	f.TemplateBody = conversionCertificateTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+var _ input.File = &ConversionKustomization{}
+
+// ConversionKustomization scaffolds the kustomization of the manifests of the conversion webhook server
+type ConversionKustomization struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *ConversionKustomization) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join(conversionDir, "kustomization.yaml")
+	}
+	f.TemplateBody = conversionKustomizationTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+var _ input.File = &ConversionKustomizeConfig{}
+
+// ConversionKustomizeConfig scaffolds the configuration of kustomize for the certificate of the conversion webhook
+// server
+type ConversionKustomizeConfig struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *ConversionKustomizeConfig) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join(conversionDir, "kustomizeconfig.yaml")
+	}
+	f.TemplateBody = conversionKustomizeConfigTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+var _ input.File = &ConversionManagerPatch{}
+
+// ConversionManagerPatch scaffolds the patch of the manager exposing the port of the conversion webhook server and
+// mounting its certificate
+type ConversionManagerPatch struct {
+	input.Input
+
+	// Port and SecretName are the port of the server and the name of the secret of its certificate
+	Port       int
+	SecretName string
+}
+
+// GetInput implements input.File
+func (f *ConversionManagerPatch) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("config", "default", conversionManagerPatchFile)
+	}
+	f.Port, f.SecretName = ConversionServerPort, conversionSecretName
+	f.TemplateBody = conversionManagerPatchTemplate
+	f.Input.IfExistsAction = input.Skip
+	return f.Input, nil
+}
+
+// Update adds the manifests of the conversion webhook server, the patch of the manager and the variables of the
+// names of the service and of the certificate to the kustomization next to the patch
+func (f *ConversionManagerPatch) Update() error {
+	path := filepath.Join(f.ProjectPath, filepath.Dir(f.Path), "kustomization.yaml")
+	entries := []struct{ field, entry string }{
+		{"bases", "../conversion"},
+		{"patchesStrategicMerge", conversionManagerPatchFile},
+		{"vars", conversionVar("CONVERSION_CERTIFICATE_NAMESPACE", "Certificate", ConversionCertificateName, true)},
+		{"vars", conversionVar("CONVERSION_CERTIFICATE_NAME", "Certificate", ConversionCertificateName, false)},
+		{"vars", conversionVar("CONVERSION_SERVICE_NAMESPACE", "Service", ConversionServiceName, true)},
+		{"vars", conversionVar("CONVERSION_SERVICE_NAME", "Service", ConversionServiceName, false)},
+	}
+	for _, e := range entries {
+		if err := internal.AddToKustomization(path, e.field, e.entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// conversionVar returns the variable of kustomize set to the name, or the namespace, of an object
+func conversionVar(name, kind, object string, namespace bool) string {
+	v := "name: " + name + "\n  objref:\n    kind: " + kind + "\n"
+	if kind == "Certificate" {
+		v += "    group: cert-manager.io\n    version: v1alpha2\n"
+	} else {
+		v += "    version: v1\n"
+	}
+	v += "    name: " + object
+	if namespace {
+		v += "\n  fieldref:\n    fieldpath: metadata.namespace"
+	}
+	return v
+}
+
+const conversionServerTemplate = `{{ .Boilerplate }}
+
+// Package conversionserver serves the conversion webhooks of the CRDs from a webhook server of their own, separate
+// from the server of the defaulting and validating webhooks. The server has its own service and certificate, see
+// config/conversion: the certificate can be issued and rotated on its own schedule, e.g. by another issuer, without
+// interrupting the admission webhooks, and the other way around.
+package conversionserver
+
+import (
+	"os"
+	"path/filepath"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+)
+
+const (
+	// Port is the port of the server, exposed by the manager patch config/default/manager_conversion_patch.yaml
+	Port = {{ .Port }}
+
+	// Path is the path the API server sends the conversion reviews to, see the conversion patches of the CRDs
+	Path = "/convert"
+)
+
+// CertDir is the directory of the serving certificate of the server, mounted from the secret of its certificate
+var CertDir = filepath.Join(os.TempDir(), "k8s-conversion-server", "serving-certs")
+
+// SetupWithManager adds the server to the manager, which starts it along with the controllers. The objects are
+// converted by the conversion.Hub and conversion.Convertible implementations of the versions of their kinds
+// registered in the scheme of the manager.
+func SetupWithManager(mgr ctrl.Manager) error {
+	server := &webhook.Server{Port: Port, CertDir: CertDir}
+	server.Register(Path, &conversion.Webhook{})
+	return mgr.Add(server)
+}
+`
+
+const conversionServiceTemplate = `apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Name }}
+  namespace: system
+spec:
+  ports:
+    - port: 443
+      targetPort: {{ .Port }}
+  selector:
+    control-plane: controller-manager
+`
+
+const conversionCertificateTemplate = `# The certificate of the conversion webhook server, issued by the issuer of config/certmanager. It is renewed
+# independently of the certificate of the admission webhooks.
+# TODO(user): set the issuer, the duration and the renewal of the certificate.
+apiVersion: cert-manager.io/v1alpha2
+kind: Certificate
+metadata:
+  name: {{ .Name }}
+  namespace: system
+spec:
+  # $(CONVERSION_SERVICE_NAME) and $(CONVERSION_SERVICE_NAMESPACE) will be substituted by kustomize
+  dnsNames:
+  - $(CONVERSION_SERVICE_NAME).$(CONVERSION_SERVICE_NAMESPACE).svc
+  - $(CONVERSION_SERVICE_NAME).$(CONVERSION_SERVICE_NAMESPACE).svc.cluster.local
+  issuerRef:
+    kind: Issuer
+    name: selfsigned-issuer
+  duration: 2160h
+  renewBefore: 360h
+  secretName: {{ .SecretName }} # this secret will not be prefixed, since it's not managed by kustomize
+`
+
+const conversionKustomizationTemplate = `# The service and the certificate of the conversion webhook server, see the conversionserver package. It requires
+# the 'WEBHOOK' and 'CERTMANAGER' sections of config/default/kustomization.yaml to be enabled.
+resources:
+- service.yaml
+- certificate.yaml
+
+configurations:
+- kustomizeconfig.yaml
+`
+
+const conversionKustomizeConfigTemplate = `# This configuration is for teaching kustomize how to update name ref and var substitution
+nameReference:
+- kind: Issuer
+  group: cert-manager.io
+  fieldSpecs:
+  - kind: Certificate
+    group: cert-manager.io
+    path: spec/issuerRef/name
+
+varReference:
+- kind: Certificate
+  group: cert-manager.io
+  path: spec/dnsNames
+`
+
+const conversionManagerPatchTemplate = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller-manager
+  namespace: system
+spec:
+  template:
+    spec:
+      containers:
+      - name: manager
+        ports:
+        - containerPort: {{ .Port }}
+          name: conversion
+          protocol: TCP
+        volumeMounts:
+        - mountPath: /tmp/k8s-conversion-server/serving-certs
+          name: conversion-cert
+          readOnly: true
+      volumes:
+      - name: conversion-cert
+        secret:
+          defaultMode: 420
+          secretName: {{ .SecretName }}
+`
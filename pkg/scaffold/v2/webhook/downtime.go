@@ -0,0 +1,378 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/internal"
+)
+
+const (
+	// FailurePolicyFail rejects the requests when the webhook can't be called, the default
+	FailurePolicyFail = "fail"
+	// FailurePolicyIgnore admits the requests when the webhook can't be called
+	FailurePolicyIgnore = "ignore"
+
+	// DefaultTimeoutSeconds is the time the API server waits for the webhooks when their timeout isn't set
+	DefaultTimeoutSeconds = 30
+	// MaxTimeoutSeconds is the longest timeout of a webhook accepted by the API server
+	MaxTimeoutSeconds = 30
+)
+
+// ValidateTuning validates the failure policy and the timeout of the webhooks of a resource, a timeout of 0
+// leaves the default of the API server
+func ValidateTuning(failurePolicy string, timeoutSeconds int) error {
+	if failurePolicy != FailurePolicyFail && failurePolicy != FailurePolicyIgnore {
+		return fmt.Errorf("invalid failure policy %q, expected %s or %s",
+			failurePolicy, FailurePolicyFail, FailurePolicyIgnore)
+	}
+	if timeoutSeconds < 0 || timeoutSeconds > MaxTimeoutSeconds {
+		return fmt.Errorf("invalid timeout of %d seconds, expected between 1 and %d", timeoutSeconds, MaxTimeoutSeconds)
+	}
+	return nil
+}
+
+var _ input.File = &TimeoutPatch{}
+
+// TimeoutPatch scaffolds the patch of the webhook manifests setting the timeout of the webhooks of a Resource
+type TimeoutPatch struct {
+	input.Input
+
+	// Resource is the Resource of the webhooks
+	Resource *resource.Resource
+
+	// Defaulting and Validating select the webhooks whose timeout is set
+	Defaulting bool
+	Validating bool
+
+	// TimeoutSeconds is the time the API server waits for the webhooks
+	TimeoutSeconds int
+}
+
+// GetInput implements input.File
+func (f *TimeoutPatch) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join(f.WebhookDir, timeoutPatchFile(f.Resource))
+	}
+	f.TemplateBody = timeoutPatchTemplate
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *TimeoutPatch) Validate() error {
+	return f.Resource.Validate()
+}
+
+// Update references the scaffolded patch from the kustomization next to it
+func (f *TimeoutPatch) Update() error {
+	return internal.AddToKustomization(filepath.Join(f.ProjectPath, filepath.Dir(f.Path), "kustomization.yaml"),
+		"patchesStrategicMerge", filepath.Base(f.Path))
+}
+
+// timeoutPatchFile returns the name of the timeout patch of the webhooks of r
+func timeoutPatchFile(r *resource.Resource) string {
+	return fmt.Sprintf("timeout_in_%s.yaml", r.QualifiedName(r.Plural(), "_"))
+}
+
+const timeoutPatchTemplate = `# Sets the time the API server waits for the webhooks of the {{ .Resource.Kind }}. The requests of users wait
+# for the webhooks, when they time out the request is rejected or admitted according to their failure policy.
+{{- if .Defaulting }}
+apiVersion: admissionregistration.k8s.io/{{ .Kubernetes.WebhookVersion }}
+kind: MutatingWebhookConfiguration
+metadata:
+  name: mutating-webhook-configuration
+webhooks:
+- name: {{ .Resource.WebhookName "m" }}
+  timeoutSeconds: {{ .TimeoutSeconds }}
+{{- end }}
+{{- if and .Defaulting .Validating }}
+---
+{{- end }}
+{{- if .Validating }}
+apiVersion: admissionregistration.k8s.io/{{ .Kubernetes.WebhookVersion }}
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: validating-webhook-configuration
+webhooks:
+- name: {{ .Resource.WebhookName "v" }}
+  timeoutSeconds: {{ .TimeoutSeconds }}
+{{- end }}
+`
+
+var _ input.File = &DowntimeTest{}
+
+// DowntimeTest scaffolds a test of the effect of the failure policy and the timeout of a webhook of a Resource
+// on the requests of the users while the webhook is down, against the API server of envtest
+type DowntimeTest struct {
+	input.Input
+
+	// Resource is the Resource of the webhook
+	Resource *resource.Resource
+
+	// Validating indicates that the validating webhook is tested, the defaulting one otherwise
+	Validating bool
+
+	// FailurePolicy and TimeoutSeconds are the tuning of the webhook
+	FailurePolicy  string
+	TimeoutSeconds int
+
+	// CRDDirectoryPath and SamplePath are the slash separated paths of the CRDs and of the sample of the
+	// Resource relative to the test
+	CRDDirectoryPath string
+	SamplePath       string
+
+	// Package is the path of the package of the test relative to the project root
+	Package string
+}
+
+// GetInput implements input.File
+func (f *DowntimeTest) GetInput() (input.Input, error) {
+	if f.FailurePolicy == "" {
+		f.FailurePolicy = FailurePolicyFail
+	}
+	if f.TimeoutSeconds == 0 {
+		f.TimeoutSeconds = DefaultTimeoutSeconds
+	}
+	if f.Path == "" {
+		f.Path = webhookFilePath(f.Resource, f.MultiGroup, f.Naming, "webhook_downtime_test.go")
+	}
+	f.Package = "./" + filepath.ToSlash(filepath.Dir(f.Path))
+
+	crds, err := filepath.Rel(filepath.Dir(f.Path), f.CRDDir)
+	if err != nil {
+		return input.Input{}, err
+	}
+	f.CRDDirectoryPath = filepath.ToSlash(filepath.Join(crds, "bases"))
+	sample := filepath.Join("config", "samples", fmt.Sprintf(
+		"%s_%s_%s.yaml", f.Resource.Group, f.Resource.Version, f.Naming.FileName(f.Resource.Kind)))
+	rel, err := filepath.Rel(filepath.Dir(f.Path), sample)
+	if err != nil {
+		return input.Input{}, err
+	}
+	f.SamplePath = filepath.ToSlash(rel)
+
+	f.TemplateBody = downtimeTestTemplate
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *DowntimeTest) Validate() error {
+	return f.Resource.Validate()
+}
+
+// nolint:lll
+const downtimeTestTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+)
+
+// The downtime test is skipped by default as it waits for the webhook to time out, run it with:
+//
+//   WEBHOOK_DOWNTIME_TEST=true go test {{ .Package }} -run Test{{ .Resource.Kind }}WebhookDowntime -v
+//
+// It registers the {{ if .Validating }}validating{{ else }}defaulting{{ end }} webhook of the {{ .Resource.Kind }} in the API server of envtest with the failure
+// policy and the timeout of the webhook manifests, and points it to an address which accepts connections but
+// never answers, as a webhook which is down or overloaded. Creating a {{ .Resource.Kind }} then shows the effect of
+// the tuning on the requests of the users: they wait for the timeout, and are rejected if the failure policy is
+// Fail or admitted without being {{ if .Validating }}validated{{ else }}defaulted{{ end }} if it is Ignore.
+//
+// Fail keeps the objects consistent with the webhook but makes the API unavailable while the webhook is down,
+// Ignore keeps the API available but admits objects the webhook would have {{ if .Validating }}rejected{{ else }}mutated{{ end }}. Keep the timeout short,
+// every request of a user waits for it while the webhook is down.
+const (
+	// {{ lower .Resource.Kind }}DowntimeTestFailurePolicy is the failure policy of the webhook, keep it in sync with its marker
+	{{ lower .Resource.Kind }}DowntimeTestFailurePolicy = admissionregistrationv1beta1.{{ title .FailurePolicy }}
+	// {{ lower .Resource.Kind }}DowntimeTestTimeoutSeconds is the timeout of the webhook, keep it in sync with the webhook manifests
+	{{ lower .Resource.Kind }}DowntimeTestTimeoutSeconds = {{ .TimeoutSeconds }}
+)
+
+func Test{{ .Resource.Kind }}WebhookDowntime(t *testing.T) {
+	if os.Getenv("WEBHOOK_DOWNTIME_TEST") == "" {
+		t.Skip("set WEBHOOK_DOWNTIME_TEST to run the webhook downtime test")
+	}
+
+	testEnv := &envtest.Environment{
+		CRDDirectoryPaths: []string{filepath.FromSlash("{{ .CRDDirectoryPath }}")},
+	}
+	cfg, err := testEnv.Start()
+	if err != nil {
+		t.Fatalf("unable to start the test environment: %v", err)
+	}
+	defer func() {
+		if err := testEnv.Stop(); err != nil {
+			t.Errorf("unable to stop the test environment: %v", err)
+		}
+	}()
+
+	s := runtime.NewScheme()
+	if err := AddToScheme(s); err != nil {
+		t.Fatalf("unable to add types to the scheme: %v", err)
+	}
+	if err := admissionregistrationv1beta1.AddToScheme(s); err != nil {
+		t.Fatalf("unable to add the admission registration types to the scheme: %v", err)
+	}
+	c, err := client.New(cfg, client.Options{Scheme: s})
+	if err != nil {
+		t.Fatalf("unable to create the client: %v", err)
+	}
+
+	url, stop := {{ lower .Resource.Kind }}UnresponsiveWebhook(t)
+	defer stop()
+	policy := admissionregistrationv1beta1.FailurePolicyType({{ lower .Resource.Kind }}DowntimeTestFailurePolicy)
+	timeout := int32({{ lower .Resource.Kind }}DowntimeTestTimeoutSeconds)
+	rule := admissionregistrationv1beta1.RuleWithOperations{
+		Operations: []admissionregistrationv1beta1.OperationType{admissionregistrationv1beta1.Create},
+		Rule: admissionregistrationv1beta1.Rule{
+			APIGroups:   []string{GroupVersion.Group},
+			APIVersions: []string{GroupVersion.Version},
+			Resources:   []string{"{{ .Resource.Resource }}"},
+		},
+	}
+{{- if .Validating }}
+	wh := admissionregistrationv1beta1.ValidatingWebhook{
+		Name:           "{{ .Resource.WebhookName "v" }}",
+		ClientConfig:   admissionregistrationv1beta1.WebhookClientConfig{URL: &url},
+		Rules:          []admissionregistrationv1beta1.RuleWithOperations{rule},
+		FailurePolicy:  &policy,
+		TimeoutSeconds: &timeout,
+	}
+	configuration := &admissionregistrationv1beta1.ValidatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "{{ lower .Resource.Kind }}-downtime-test"},
+		Webhooks:   []admissionregistrationv1beta1.ValidatingWebhook{wh},
+	}
+{{- else }}
+	wh := admissionregistrationv1beta1.MutatingWebhook{
+		Name:           "{{ .Resource.WebhookName "m" }}",
+		ClientConfig:   admissionregistrationv1beta1.WebhookClientConfig{URL: &url},
+		Rules:          []admissionregistrationv1beta1.RuleWithOperations{rule},
+		FailurePolicy:  &policy,
+		TimeoutSeconds: &timeout,
+	}
+	configuration := &admissionregistrationv1beta1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: "{{ lower .Resource.Kind }}-downtime-test"},
+		Webhooks:   []admissionregistrationv1beta1.MutatingWebhook{wh},
+	}
+{{- end }}
+	if err := c.Create(context.Background(), configuration); err != nil {
+		t.Fatalf("unable to register the webhook: %v", err)
+	}
+
+	// the API server calls the webhook once it has observed its configuration, which takes a moment: wait until
+	// a creation is rejected or waits for the timeout
+	var elapsed time.Duration
+	for i := 0; i < 50; i++ {
+		start := time.Now()
+		err = c.Create(context.Background(), {{ lower .Resource.Kind }}DowntimeTestObject(t))
+		elapsed = time.Since(start)
+		if err != nil || elapsed >= time.Duration(timeout)*time.Second/2 {
+			break
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	t.Logf("with the failure policy %s and a timeout of %ds, creating a {{ .Resource.Kind }} while the webhook is down "+
+		"took %v and returned: %v", policy, timeout, elapsed, err)
+
+	if elapsed < time.Duration(timeout)*time.Second/2 {
+		t.Fatalf("the webhook was never called by the API server")
+	}
+	if policy == admissionregistrationv1beta1.Fail && err == nil {
+		t.Errorf("expected the creation to be rejected with the failure policy Fail")
+	}
+	if policy == admissionregistrationv1beta1.Ignore && err != nil {
+		t.Errorf("expected the creation to be admitted with the failure policy Ignore, got: %v", err)
+	}
+}
+
+// {{ lower .Resource.Kind }}UnresponsiveWebhook returns the URL of a webhook accepting connections without ever
+// answering them, and the function stopping it
+func {{ lower .Resource.Kind }}UnresponsiveWebhook(t *testing.T) (string, func()) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to listen: %v", err)
+	}
+
+	var mu sync.Mutex
+	var conns []net.Conn
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+		}
+	}()
+	stop := func() {
+		_ = l.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+	}
+
+	return "https://" + l.Addr().String() + "/{{ if .Validating }}validate{{ else }}mutate{{ end }}", stop
+}
+
+// {{ lower .Resource.Kind }}DowntimeTestObject returns the sample with a generated name
+func {{ lower .Resource.Kind }}DowntimeTestObject(t *testing.T) *{{ .Resource.Kind }} {
+	sample, err := ioutil.ReadFile(filepath.FromSlash("{{ .SamplePath }}"))
+	if err != nil {
+		t.Fatalf("unable to read the sample: %v", err)
+	}
+	data, err := yaml.ToJSON(sample)
+	if err != nil {
+		t.Fatalf("unable to convert the sample to JSON: %v", err)
+	}
+	obj := &{{ .Resource.Kind }}{}
+	if err := json.Unmarshal(data, obj); err != nil {
+		t.Fatalf("unable to decode the sample: %v", err)
+	}
+	obj.Name = ""
+	obj.GenerateName = "{{ lower .Resource.Kind }}-downtime-"
+{{- if .Resource.Namespaced }}
+	obj.Namespace = "default"
+{{- end }}
+	return obj
+}
+`
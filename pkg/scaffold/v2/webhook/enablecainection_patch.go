@@ -41,14 +41,14 @@ func (f *InjectCAPatch) GetInput() (input.Input, error) {
 
 const injectCAPatchTemplate = `# This patch add annotation to admission webhook config and
 # the variables $(CERTIFICATE_NAMESPACE) and $(CERTIFICATE_NAME) will be substituted by kustomize.
-apiVersion: admissionregistration.k8s.io/v1beta1
+apiVersion: admissionregistration.k8s.io/{{ .Kubernetes.WebhookVersion }}
 kind: MutatingWebhookConfiguration
 metadata:
   name: mutating-webhook-configuration
   annotations:
     cert-manager.io/inject-ca-from: $(CERTIFICATE_NAMESPACE)/$(CERTIFICATE_NAME)
 ---
-apiVersion: admissionregistration.k8s.io/v1beta1
+apiVersion: admissionregistration.k8s.io/{{ .Kubernetes.WebhookVersion }}
 kind: ValidatingWebhookConfiguration
 metadata:
   name: validating-webhook-configuration
@@ -32,7 +32,7 @@ type Kustomization struct {
 // GetInput implements input.File
 func (f *Kustomization) GetInput() (input.Input, error) {
 	if f.Path == "" {
-		f.Path = filepath.Join("config", "webhook", "kustomization.yaml")
+		f.Path = filepath.Join(f.WebhookDir, "kustomization.yaml")
 	}
 	f.TemplateBody = KustomizeWebhookTemplate
 	f.Input.IfExistsAction = input.Error
@@ -32,7 +32,7 @@ type KustomizeConfigWebhook struct {
 // GetInput implements input.File
 func (f *KustomizeConfigWebhook) GetInput() (input.Input, error) {
 	if f.Path == "" {
-		f.Path = filepath.Join("config", "webhook", "kustomizeconfig.yaml")
+		f.Path = filepath.Join(f.WebhookDir, "kustomizeconfig.yaml")
 	}
 	f.TemplateBody = KustomizeConfigWebhookTemplate
 	f.Input.IfExistsAction = input.Error
@@ -0,0 +1,237 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+)
+
+var _ input.File = &LoadTest{}
+
+// LoadTest scaffolds a load test of the validating webhook of a Resource reporting its latency
+type LoadTest struct {
+	input.Input
+
+	// Resource is the Resource to make the Webhook for
+	Resource *resource.Resource
+
+	// Is the Group + "." + Domain for the Resource
+	GroupDomainWithDash string
+
+	// SamplePath is the slash separated path of the sample of the Resource relative to the test
+	SamplePath string
+
+	// Package is the path of the package of the test relative to the project root
+	Package string
+}
+
+// GetInput implements input.File
+func (f *LoadTest) GetInput() (input.Input, error) {
+	_, groupDomain := util.GetResourceInfo(f.ProjectPath, f.Resource, f.Repo, f.Domain, f.MultiGroup)
+	f.GroupDomainWithDash = strings.Replace(groupDomain, ".", "-", -1)
+
+	if f.Path == "" {
+		f.Path = webhookFilePath(f.Resource, f.MultiGroup, f.Naming, "webhook_load_test.go")
+	}
+	f.Package = "./" + filepath.ToSlash(filepath.Dir(f.Path))
+	if f.SamplePath == "" {
+		sample := filepath.Join("config", "samples", fmt.Sprintf(
+			"%s_%s_%s.yaml", f.Resource.Group, f.Resource.Version, f.Naming.FileName(f.Resource.Kind)))
+		rel, err := filepath.Rel(filepath.Dir(f.Path), sample)
+		if err != nil {
+			return input.Input{}, err
+		}
+		f.SamplePath = filepath.ToSlash(rel)
+	}
+	f.TemplateBody = loadTestTemplate
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *LoadTest) Validate() error {
+	return f.Resource.Validate()
+}
+
+// nolint:lll
+const loadTestTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// The load test is skipped by default, run it with:
+//
+//   WEBHOOK_LOAD_TEST=true go test {{ .Package }} -run Test{{ .Resource.Kind }}ValidatingWebhookLoad -v
+//
+// The requests are sent to an in-process webhook server unless WEBHOOK_URL is set to the base URL of a
+// running one, e.g. https://localhost:9443 when port-forwarding the webhook service of the manager.
+const (
+	// {{ lower .Resource.Kind }}LoadTestRequests is the number of requests sent to the webhook
+	{{ lower .Resource.Kind }}LoadTestRequests = 1000
+	// {{ lower .Resource.Kind }}LoadTestConcurrency is the number of requests sent concurrently
+	{{ lower .Resource.Kind }}LoadTestConcurrency = 10
+	// {{ lower .Resource.Kind }}LoadTestP99Budget is the p99 latency the webhook must answer within. Every request to
+	// the API server for a {{ .Resource.Kind }} waits for the webhook, keep it well below the webhook timeout.
+	{{ lower .Resource.Kind }}LoadTestP99Budget = 100 * time.Millisecond
+)
+
+func Test{{ .Resource.Kind }}ValidatingWebhookLoad(t *testing.T) {
+	if os.Getenv("WEBHOOK_LOAD_TEST") == "" {
+		t.Skip("set WEBHOOK_LOAD_TEST to run the webhook load test")
+	}
+
+	const path = "/validate-{{ .GroupDomainWithDash }}-{{ .Resource.Version }}-{{ lower .Resource.Kind }}"
+	url := os.Getenv("WEBHOOK_URL")
+	client := http.DefaultClient
+	if url == "" {
+		s := runtime.NewScheme()
+		if err := AddToScheme(s); err != nil {
+			t.Fatalf("unable to add types to the scheme: %v", err)
+		}
+		wh := admission.ValidatingWebhookFor(&{{ .Resource.Kind }}{})
+		if err := wh.InjectScheme(s); err != nil {
+			t.Fatalf("unable to inject the scheme into the webhook: %v", err)
+		}
+		if err := wh.InjectLogger(ctrl.Log.WithName("webhook")); err != nil {
+			t.Fatalf("unable to inject the logger into the webhook: %v", err)
+		}
+		mux := http.NewServeMux()
+		mux.Handle(path, wh)
+		server := httptest.NewServer(mux)
+		defer server.Close()
+		url = server.URL
+	} else {
+		// the serving certificate of the webhook is signed for the service name, not for the forwarded address
+		client = &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, // nolint: gosec
+		}}
+	}
+
+	payloads := {{ lower .Resource.Kind }}LoadTestPayloads(t)
+
+	var failures int32
+	latencies := make([]time.Duration, {{ lower .Resource.Kind }}LoadTestRequests)
+	requests := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < {{ lower .Resource.Kind }}LoadTestConcurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range requests {
+				start := time.Now()
+				resp, err := client.Post(url+path, "application/json", bytes.NewReader(payloads[i%len(payloads)]))
+				if err != nil {
+					atomic.AddInt32(&failures, 1)
+					latencies[i] = time.Since(start)
+					continue
+				}
+				_, _ = io.Copy(ioutil.Discard, resp.Body)
+				_ = resp.Body.Close()
+				latencies[i] = time.Since(start)
+				if resp.StatusCode != http.StatusOK {
+					atomic.AddInt32(&failures, 1)
+				}
+			}
+		}()
+	}
+	for i := range latencies {
+		requests <- i
+	}
+	close(requests)
+	wg.Wait()
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	p50 := latencies[len(latencies)/2]
+	p99 := latencies[len(latencies)*99/100]
+	t.Logf("%d requests, %d concurrent: p50 %v, p99 %v, max %v",
+		len(latencies), {{ lower .Resource.Kind }}LoadTestConcurrency, p50, p99, latencies[len(latencies)-1])
+
+	if failures > 0 {
+		t.Errorf("%d requests failed", failures)
+	}
+	if p99 > {{ lower .Resource.Kind }}LoadTestP99Budget {
+		t.Errorf("p99 latency %v exceeds the budget of %v", p99, {{ lower .Resource.Kind }}LoadTestP99Budget)
+	}
+}
+
+// {{ lower .Resource.Kind }}LoadTestPayloads returns the admission reviews sent to the webhook, creating and updating the sample
+func {{ lower .Resource.Kind }}LoadTestPayloads(t *testing.T) [][]byte {
+	sample, err := ioutil.ReadFile(filepath.FromSlash("{{ .SamplePath }}"))
+	if err != nil {
+		t.Fatalf("unable to read the sample: %v", err)
+	}
+	object, err := yaml.ToJSON(sample)
+	if err != nil {
+		t.Fatalf("unable to convert the sample to JSON: %v", err)
+	}
+
+	// TODO(user): add payloads representative of the objects of your users, e.g. with the largest specs
+	// they are expected to have, as the decoding and validation time grows with the size of the object.
+	var payloads [][]byte
+	for _, operation := range []admissionv1beta1.Operation{admissionv1beta1.Create, admissionv1beta1.Update} {
+		request := &admissionv1beta1.AdmissionRequest{
+			UID:       "{{ lower .Resource.Kind }}-load-test",
+			Kind:      metav1.GroupVersionKind{Group: GroupVersion.Group, Version: GroupVersion.Version, Kind: "{{ .Resource.Kind }}"},
+			Resource:  metav1.GroupVersionResource{Group: GroupVersion.Group, Version: GroupVersion.Version, Resource: "{{ .Resource.Resource }}"},
+			Operation: operation,
+			Object:    runtime.RawExtension{Raw: object},
+		}
+		if operation == admissionv1beta1.Update {
+			request.OldObject = runtime.RawExtension{Raw: object}
+		}
+
+		review, err := json.Marshal(&admissionv1beta1.AdmissionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: admissionv1beta1.SchemeGroupVersion.String(), Kind: "AdmissionReview"},
+			Request:  request,
+		})
+		if err != nil {
+			t.Fatalf("unable to encode the admission review: %v", err)
+		}
+		payloads = append(payloads, review)
+	}
+	return payloads
+}
+`
@@ -32,7 +32,7 @@ type Service struct {
 // GetInput implements input.File
 func (f *Service) GetInput() (input.Input, error) {
 	if f.Path == "" {
-		f.Path = filepath.Join("config", "webhook", "service.yaml")
+		f.Path = filepath.Join(f.WebhookDir, "service.yaml")
 	}
 	f.TemplateBody = ServiceTemplate
 	f.Input.IfExistsAction = input.Error
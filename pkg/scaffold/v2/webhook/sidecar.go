@@ -0,0 +1,330 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/internal"
+)
+
+const (
+	// SidecarInjectorPath is the path the sidecar injector is served at
+	SidecarInjectorPath = "/mutate-v1-pod-sidecar"
+
+	// SidecarInjectorName is the name of the sidecar injector in the MutatingWebhookConfiguration
+	SidecarInjectorName = "msidecar.kb.io"
+
+	// sidecarSelectorPatchFile is the patch of the webhook manifests restricting the injector to the pods
+	// managed by the operator
+	sidecarSelectorPatchFile = "sidecar_selector_patch.yaml"
+)
+
+var _ input.File = &SidecarInjector{}
+
+// SidecarInjector scaffolds a mutating webhook injecting a sidecar container into the pods created by the operator
+type SidecarInjector struct {
+	input.Input
+
+	// Prefix is the value of the label of the pods managed by the operator, defaults to the project name
+	Prefix string
+
+	// WebhookPath and WebhookName are the path and the name of the webhook
+	WebhookPath, WebhookName string
+}
+
+// GetInput implements input.File
+func (f *SidecarInjector) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("sidecar", "injector.go")
+	}
+	if f.Prefix == "" {
+		// use directory name as prefix
+		prefix, err := util.ProjectName(f.ProjectPath)
+		if err != nil {
+			return input.Input{}, err
+		}
+		f.Prefix = prefix
+	}
+	f.WebhookPath, f.WebhookName = SidecarInjectorPath, SidecarInjectorName
+	f.TemplateBody = sidecarInjectorTemplate
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+var _ input.File = &SidecarInjectorTest{}
+
+// SidecarInjectorTest scaffolds the tests of the sidecar injector
+type SidecarInjectorTest struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *SidecarInjectorTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join("sidecar", "injector_test.go")
+	}
+	f.TemplateBody = sidecarInjectorTestTemplate
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+var _ input.File = &SidecarSelectorPatch{}
+
+// SidecarSelectorPatch scaffolds the patch of the webhook manifests restricting the sidecar injector to the
+// pods managed by the operator
+type SidecarSelectorPatch struct {
+	input.Input
+
+	// Prefix is the value of the label of the pods managed by the operator, defaults to the project name
+	Prefix string
+
+	// WebhookName is the name of the webhook
+	WebhookName string
+}
+
+// GetInput implements input.File
+func (f *SidecarSelectorPatch) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join(f.WebhookDir, sidecarSelectorPatchFile)
+	}
+	if f.Prefix == "" {
+		// use directory name as prefix
+		prefix, err := util.ProjectName(f.ProjectPath)
+		if err != nil {
+			return input.Input{}, err
+		}
+		f.Prefix = prefix
+	}
+	f.WebhookName = SidecarInjectorName
+	f.TemplateBody = sidecarSelectorPatchTemplate
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Update references the scaffolded patch from the kustomization next to it
+func (f *SidecarSelectorPatch) Update() error {
+	return internal.AddToKustomization(filepath.Join(f.ProjectPath, filepath.Dir(f.Path), "kustomization.yaml"),
+		"patchesStrategicMerge", sidecarSelectorPatchFile)
+}
+
+const sidecarInjectorTemplate = `{{ .Boilerplate }}
+
+// Package sidecar contains a mutating webhook injecting a sidecar container into the pods created by the operator
+package sidecar
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ManagedByLabel and ManagedBy label the pods created by the operator, only these pods get the sidecar. The
+// MutatingWebhookConfiguration selects the pods by the same label, see the sidecar selector patch of the webhook
+// manifests.
+// TODO(user): set them on the pods, or the pod templates, your controllers create.
+const (
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	ManagedBy      = "{{ .Prefix }}"
+)
+
+// Labels returns the labels of the pods managed by the operator
+func Labels() map[string]string {
+	return map[string]string{ManagedByLabel: ManagedBy}
+}
+
+// Container is the injected sidecar container.
+// TODO(user): replace the image and configure the container.
+var Container = corev1.Container{
+	Name:  "sidecar",
+	Image: "busybox:1.31",
+	Args:  []string{"sleep", "3600"},
+}
+
+// +kubebuilder:webhook:path={{ .WebhookPath }},mutating=true,failurePolicy=ignore,groups="",resources=pods,verbs=create,versions=v1,name={{ .WebhookName }}
+
+// Injector is a mutating webhook injecting a sidecar container into the pods managed by the operator
+type Injector struct {
+	// Sidecar is the injected container
+	Sidecar corev1.Container
+
+	decoder *admission.Decoder
+}
+
+// SetupWebhookWithManager registers the injector of Container in the webhook server of the manager
+func SetupWebhookWithManager(mgr ctrl.Manager) {
+	mgr.GetWebhookServer().Register("{{ .WebhookPath }}", &webhook.Admission{Handler: &Injector{Sidecar: Container}})
+}
+
+var _ admission.DecoderInjector = &Injector{}
+
+// InjectDecoder implements admission.DecoderInjector
+func (i *Injector) InjectDecoder(d *admission.Decoder) error {
+	i.decoder = d
+	return nil
+}
+
+// Handle implements admission.Handler
+func (i *Injector) Handle(_ context.Context, req admission.Request) admission.Response {
+	pod := &corev1.Pod{}
+	if err := i.decoder.Decode(req, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	// API servers older than 1.15 ignore the object selector and send every pod
+	if pod.Labels[ManagedByLabel] != ManagedBy {
+		return admission.Allowed("pod not managed by the operator")
+	}
+	if !Inject(pod, i.Sidecar) {
+		return admission.Allowed("sidecar already injected")
+	}
+	return PatchResponse(req.Object.Raw, pod)
+}
+
+// Inject adds the sidecar to the containers of the pod, unless it already has a container of the same name.
+// It returns whether the pod changed.
+func Inject(pod *corev1.Pod, sidecar corev1.Container) bool {
+	for _, c := range pod.Spec.Containers {
+		if c.Name == sidecar.Name {
+			return false
+		}
+	}
+	pod.Spec.Containers = append(pod.Spec.Containers, sidecar)
+	return true
+}
+
+// PatchResponse returns the response allowing the raw pod of the request, patched into the mutated pod
+func PatchResponse(raw []byte, pod *corev1.Pod) admission.Response {
+	mutated, err := json.Marshal(pod)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(raw, mutated)
+}
+`
+
+const sidecarInjectorTestTemplate = `{{ .Boilerplate }}
+
+package sidecar
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func newPod(labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Labels: labels},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "app", Image: "app"},
+			},
+		},
+	}
+}
+
+func TestInject(t *testing.T) {
+	pod := newPod(Labels())
+	if !Inject(pod, Container) {
+		t.Fatalf("expected the sidecar to be injected")
+	}
+	if n := len(pod.Spec.Containers); n != 2 || pod.Spec.Containers[1].Name != Container.Name {
+		t.Fatalf("expected the sidecar after the containers of the pod, got: %v", pod.Spec.Containers)
+	}
+	if Inject(pod, Container) {
+		t.Errorf("expected the sidecar to be injected only once, got: %v", pod.Spec.Containers)
+	}
+}
+
+func TestInjectorHandle(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatalf("unable to add types to the scheme: %v", err)
+	}
+	decoder, err := admission.NewDecoder(s)
+	if err != nil {
+		t.Fatalf("unable to create the decoder: %v", err)
+	}
+	injector := &Injector{Sidecar: Container}
+	if _, err := admission.InjectDecoderInto(decoder, injector); err != nil {
+		t.Fatalf("unable to inject the decoder: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		pod     *corev1.Pod
+		patched bool
+	}{
+		{name: "managed pod", pod: newPod(Labels()), patched: true},
+		{name: "unmanaged pod", pod: newPod(map[string]string{"app": "other"})},
+		{name: "injected pod", pod: func() *corev1.Pod {
+			pod := newPod(Labels())
+			Inject(pod, Container)
+			return pod
+		}()},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			raw, err := json.Marshal(test.pod)
+			if err != nil {
+				t.Fatalf("unable to encode the pod: %v", err)
+			}
+			resp := injector.Handle(context.Background(), admission.Request{
+				AdmissionRequest: admissionv1beta1.AdmissionRequest{
+					Operation: admissionv1beta1.Create,
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			})
+			if !resp.Allowed {
+				t.Fatalf("expected the pod to be allowed, got: %v", resp.Result)
+			}
+			if patched := len(resp.Patches) > 0; patched != test.patched {
+				t.Errorf("expected the pod to be patched: %t, got the patches: %v", test.patched, resp.Patches)
+			}
+		})
+	}
+}
+`
+
+const sidecarSelectorPatchTemplate = `# Restricts the sidecar injector to the pods managed by the operator, other pods are not sent to it.
+# The object selector is supported by API servers 1.15 and above, older ones send every pod to the injector.
+apiVersion: admissionregistration.k8s.io/{{ .Kubernetes.WebhookVersion }}
+kind: MutatingWebhookConfiguration
+metadata:
+  name: mutating-webhook-configuration
+webhooks:
+- name: {{ .WebhookName }}
+  objectSelector:
+    matchLabels:
+      app.kubernetes.io/managed-by: {{ .Prefix }}
+`
@@ -0,0 +1,339 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/internal"
+)
+
+var _ input.File = &MatchPolicyPatch{}
+
+// MatchPolicyPatch scaffolds the patch of the webhook manifests calling the webhooks of a Resource of several
+// versions for the requests to all of them: the webhooks are registered for the storage version, and the match
+// policy Equivalent has the API server convert the requests to the other versions to it. The markers of
+// controller-gen v0.2 can't set the match policy, whose default is Exact in admissionregistration/v1beta1.
+type MatchPolicyPatch struct {
+	input.Input
+
+	// Resource is the Resource of the webhooks, of the storage version
+	Resource *resource.Resource
+
+	// Defaulting and Validating select the webhooks whose match policy is set
+	Defaulting bool
+	Validating bool
+}
+
+// GetInput implements input.File
+func (f *MatchPolicyPatch) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = filepath.Join(f.WebhookDir, matchPolicyPatchFile(f.Resource))
+	}
+	f.TemplateBody = matchPolicyPatchTemplate
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *MatchPolicyPatch) Validate() error {
+	return f.Resource.Validate()
+}
+
+// Update references the scaffolded patch from the kustomization next to it
+func (f *MatchPolicyPatch) Update() error {
+	return internal.AddToKustomization(filepath.Join(f.ProjectPath, filepath.Dir(f.Path), "kustomization.yaml"),
+		"patchesStrategicMerge", filepath.Base(f.Path))
+}
+
+// matchPolicyPatchFile returns the name of the match policy patch of the webhooks of r
+func matchPolicyPatchFile(r *resource.Resource) string {
+	return fmt.Sprintf("matchpolicy_in_%s.yaml", r.QualifiedName(r.Plural(), "_"))
+}
+
+const matchPolicyPatchTemplate = `# Calls the webhooks of the {{ .Resource.Kind }}, registered for {{ .Resource.Version }}, its storage version, for the requests to all of
+# its versions: the API server converts the objects of the requests to the other versions to {{ .Resource.Version }} with the
+# conversion webhook before calling them. With the match policy Exact, the requests to the other versions would
+# skip the webhooks.
+{{- if .Defaulting }}
+apiVersion: admissionregistration.k8s.io/{{ .Kubernetes.WebhookVersion }}
+kind: MutatingWebhookConfiguration
+metadata:
+  name: mutating-webhook-configuration
+webhooks:
+- name: {{ .Resource.WebhookName "m" }}
+  matchPolicy: Equivalent
+{{- end }}
+{{- if and .Defaulting .Validating }}
+---
+{{- end }}
+{{- if .Validating }}
+apiVersion: admissionregistration.k8s.io/{{ .Kubernetes.WebhookVersion }}
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: validating-webhook-configuration
+webhooks:
+- name: {{ .Resource.WebhookName "v" }}
+  matchPolicy: Equivalent
+{{- end }}
+`
+
+var _ input.File = &VersionsTest{}
+
+// VersionsTest scaffolds the test of the webhooks of a Resource of several versions admitting the requests to
+// every version, as the API server calls them: converted to the storage version the webhooks are registered for
+type VersionsTest struct {
+	input.Input
+
+	// Resource is the Resource of the webhooks, of the storage version
+	Resource *resource.Resource
+
+	// Versions are the other served versions of the Resource
+	Versions []string
+
+	// Defaulting and Validating select the webhooks tested
+	Defaulting bool
+	Validating bool
+
+	// Package is the package of the storage version, and Spokes the other versions
+	Package string
+	Spokes  []VersionPackage
+
+	// SamplePath, ManifestsPath and MatchPolicyPatchPath are the slash separated paths of the sample of the
+	// storage version, of the webhook manifests generated by make manifests and of the match policy patch
+	// relative to the test
+	SamplePath           string
+	ManifestsPath        string
+	MatchPolicyPatchPath string
+}
+
+// VersionPackage is a version of a Resource converted to the storage version
+type VersionPackage struct {
+	Version string
+
+	// Package is the package of the version and SamplePath the slash separated path of its sample relative to
+	// the test
+	Package    string
+	SamplePath string
+}
+
+// GetInput implements input.File
+func (f *VersionsTest) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = webhookFilePath(f.Resource, f.MultiGroup, f.Naming, "webhook_versions_test.go")
+	}
+	pkg, _ := util.GetResourceInfo(f.ProjectPath, f.Resource, f.Repo, f.Domain, f.MultiGroup)
+	f.Package = pkg + "/" + f.Resource.Version
+
+	var err error
+	if f.SamplePath, err = f.relative(f.samplePath(f.Resource.Version)); err != nil {
+		return input.Input{}, err
+	}
+	if f.ManifestsPath, err = f.relative(filepath.Join(f.WebhookDir, "manifests.yaml")); err != nil {
+		return input.Input{}, err
+	}
+	if f.MatchPolicyPatchPath, err = f.relative(filepath.Join(f.WebhookDir, matchPolicyPatchFile(f.Resource))); err != nil {
+		return input.Input{}, err
+	}
+	f.Spokes = nil
+	for _, version := range f.Versions {
+		sample, err := f.relative(f.samplePath(version))
+		if err != nil {
+			return input.Input{}, err
+		}
+		f.Spokes = append(f.Spokes, VersionPackage{Version: version, Package: pkg + "/" + version, SamplePath: sample})
+	}
+
+	f.TemplateBody = versionsTestTemplate
+	f.Input.IfExistsAction = input.Error
+	return f.Input, nil
+}
+
+// Validate validates the values
+func (f *VersionsTest) Validate() error {
+	return f.Resource.Validate()
+}
+
+// samplePath returns the path of the sample of a version of the Resource relative to the project root
+func (f *VersionsTest) samplePath(version string) string {
+	return filepath.Join("config", "samples",
+		fmt.Sprintf("%s_%s_%s.yaml", f.Resource.Group, version, f.Naming.FileName(f.Resource.Kind)))
+}
+
+// relative returns the slash separated path relative to the test of a path relative to the project root
+func (f *VersionsTest) relative(path string) (string, error) {
+	rel, err := filepath.Rel(filepath.Dir(f.Path), path)
+	return filepath.ToSlash(rel), err
+}
+
+// nolint:lll
+const versionsTestTemplate = `{{ .Boilerplate }}
+
+package {{ .Resource.Version }}_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	{{ .Resource.GroupImportSafe }}{{ .Resource.Version }} "{{ .Package }}"
+{{- range .Spokes }}
+	{{ $.Resource.GroupImportSafe }}{{ .Version }} "{{ .Package }}"
+{{- end }}
+)
+
+// The webhooks of the {{ .Resource.Kind }} are registered for {{ .Resource.Version }}, its storage version, and called for the requests
+// to all of its versions: the API server converts the objects of the requests to the other versions to {{ .Resource.Version }}
+// with the conversion webhook, as set by the match policy Equivalent of the webhooks. These tests run that path
+// for the sample of every version, and verify the registration of the webhooks once make manifests generated
+// their manifests. Add the new versions of the {{ .Resource.Kind }} to Test{{ .Resource.Kind }}WebhookVersions.
+
+func Test{{ .Resource.Kind }}WebhookVersions(t *testing.T) {
+	t.Run("{{ .Resource.Version }}", func(t *testing.T) {
+		hub := &{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}{}
+		decode{{ .Resource.Kind }}Sample(t, "{{ .SamplePath }}", hub)
+		admit{{ .Resource.Kind }}(t, hub)
+	})
+{{- range .Spokes }}
+	t.Run("{{ .Version }}", func(t *testing.T) {
+		obj := &{{ $.Resource.GroupImportSafe }}{{ .Version }}.{{ $.Resource.Kind }}{}
+		decode{{ $.Resource.Kind }}Sample(t, "{{ .SamplePath }}", obj)
+		spoke, ok := interface{}(obj).(conversion.Convertible)
+		if !ok {
+			t.Fatalf("{{ .Version }} doesn't implement conversion.Convertible, the API server can't convert it to {{ $.Resource.Version }} for the webhooks")
+		}
+		hub := &{{ $.Resource.GroupImportSafe }}{{ $.Resource.Version }}.{{ $.Resource.Kind }}{}
+		if err := spoke.ConvertTo(hub); err != nil {
+			t.Fatalf("unable to convert the {{ $.Resource.Kind }} to {{ $.Resource.Version }}: %v", err)
+		}
+		admit{{ $.Resource.Kind }}(t, hub)
+	})
+{{- end }}
+}
+
+func Test{{ .Resource.Kind }}WebhookRegistration(t *testing.T) {
+	manifests, err := ioutil.ReadFile(filepath.FromSlash("{{ .ManifestsPath }}"))
+	if os.IsNotExist(err) {
+		t.Skip("run make manifests to generate the webhook manifests")
+	}
+	if err != nil {
+		t.Fatalf("unable to read the webhook manifests: %v", err)
+	}
+	webhooks := decode{{ .Resource.Kind }}Webhooks(t, manifests)
+	patch, err := ioutil.ReadFile(filepath.FromSlash("{{ .MatchPolicyPatchPath }}"))
+	if err != nil {
+		t.Fatalf("unable to read the match policy patch: %v", err)
+	}
+	policies := decode{{ .Resource.Kind }}Webhooks(t, patch)
+
+	for _, name := range []string{
+{{- if .Defaulting }}
+		"{{ .Resource.WebhookName "m" }}",
+{{- end }}
+{{- if .Validating }}
+		"{{ .Resource.WebhookName "v" }}",
+{{- end }}
+	} {
+		wh, found := webhooks[name]
+		if !found {
+			t.Errorf("the webhook %s isn't in the webhook manifests", name)
+			continue
+		}
+		for _, rule := range wh.Rules {
+			if !reflect.DeepEqual(rule.APIVersions, []string{"{{ .Resource.Version }}"}) {
+				t.Errorf("expected the webhook %s to be registered for {{ .Resource.Version }}, the storage version, got %v", name, rule.APIVersions)
+			}
+		}
+		if policies[name].MatchPolicy != "Equivalent" {
+			t.Errorf("expected the match policy of the webhook %s to be Equivalent, got %q: the requests to the other versions would skip it",
+				name, policies[name].MatchPolicy)
+		}
+	}
+}
+
+// admit{{ .Resource.Kind }} runs the webhooks on the {{ .Resource.Kind }} as the API server calls them
+func admit{{ .Resource.Kind }}(t *testing.T, obj *{{ .Resource.GroupImportSafe }}{{ .Resource.Version }}.{{ .Resource.Kind }}) {
+	t.Helper()
+{{- if .Defaulting }}
+	obj.Default()
+{{- end }}
+{{- if .Validating }}
+	if err := obj.ValidateCreate(); err != nil {
+		t.Errorf("expected the {{ .Resource.Kind }} to be admitted, got: %v", err)
+	}
+{{- end }}
+}
+
+// decode{{ .Resource.Kind }}Sample decodes the sample of the path into obj
+func decode{{ .Resource.Kind }}Sample(t *testing.T, path string, obj interface{}) {
+	t.Helper()
+	sample, err := ioutil.ReadFile(filepath.FromSlash(path))
+	if err != nil {
+		t.Fatalf("unable to read the sample: %v", err)
+	}
+	data, err := yaml.ToJSON(sample)
+	if err != nil {
+		t.Fatalf("unable to convert the sample to JSON: %v", err)
+	}
+	if err := json.Unmarshal(data, obj); err != nil {
+		t.Fatalf("unable to decode the sample: %v", err)
+	}
+}
+
+// {{ lower .Resource.Kind }}Webhook is the part of a webhook of the webhook configurations the tests verify
+type {{ lower .Resource.Kind }}Webhook struct {
+	Name        string ` + "`json:\"name\"`" + `
+	MatchPolicy string ` + "`json:\"matchPolicy\"`" + `
+	Rules       []struct {
+		APIVersions []string ` + "`json:\"apiVersions\"`" + `
+	} ` + "`json:\"rules\"`" + `
+}
+
+// decode{{ .Resource.Kind }}Webhooks returns the webhooks of the webhook configurations of the manifests by name
+func decode{{ .Resource.Kind }}Webhooks(t *testing.T, manifests []byte) map[string]{{ lower .Resource.Kind }}Webhook {
+	t.Helper()
+	webhooks := map[string]{{ lower .Resource.Kind }}Webhook{}
+	decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifests), 4096)
+	for {
+		var configuration struct {
+			Webhooks []{{ lower .Resource.Kind }}Webhook ` + "`json:\"webhooks\"`" + `
+		}
+		err := decoder.Decode(&configuration)
+		if err == io.EOF {
+			return webhooks
+		}
+		if err != nil {
+			t.Fatalf("unable to decode the webhook configurations: %v", err)
+		}
+		for _, wh := range configuration.Webhooks {
+			webhooks[wh.Name] = wh
+		}
+	}
+}
+`
@@ -21,8 +21,8 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/gobuffalo/flect"
-
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/util"
@@ -50,27 +50,35 @@ type Webhook struct {
 	Defaulting bool
 	// If scaffold the validating webhook
 	Validating bool
+	// If register the webhooks adding audit annotations to their responses
+	AuditAnnotations bool
+	// If the validating webhook validates the unions of the spec, set if the types of the Resource declare them
+	ValidateUnions bool
+	// FailurePolicy is what the API server does when it can't call the webhooks, fail or ignore, defaults to fail
+	FailurePolicy string
 }
 
 // GetInput implements input.File
 func (f *Webhook) GetInput() (input.Input, error) {
 
-	_, f.GroupDomain = util.GetResourceInfo(f.Resource, f.Repo, f.Domain, f.MultiGroup)
+	_, f.GroupDomain = util.GetResourceInfo(f.ProjectPath, f.Resource, f.Repo, f.Domain, f.MultiGroup)
 
 	f.GroupDomainWithDash = strings.Replace(f.GroupDomain, ".", "-", -1)
 
 	if f.Plural == "" {
-		f.Plural = flect.Pluralize(strings.ToLower(f.Resource.Kind))
+		f.Plural = f.Resource.Plural()
+	}
+
+	if f.FailurePolicy == "" {
+		f.FailurePolicy = FailurePolicyFail
 	}
 
 	if f.Path == "" {
-		if f.MultiGroup {
-			f.Path = filepath.Join("apis", f.Resource.Group, f.Resource.Version,
-				fmt.Sprintf("%s_webhook.go", strings.ToLower(f.Resource.Kind)))
-		} else {
-			f.Path = filepath.Join("api", f.Resource.Version,
-				fmt.Sprintf("%s_webhook.go", strings.ToLower(f.Resource.Kind)))
-		}
+		f.Path = WebhookPath(f.Resource, f.MultiGroup, f.Naming)
+	}
+
+	if f.Validating && !f.ValidateUnions {
+		f.ValidateUnions = declaresUnions(f.ProjectPath, f.Resource, f.MultiGroup, f.Naming)
 	}
 
 	webhookTemplate := WebhookTemplate
@@ -83,6 +91,11 @@ func (f *Webhook) GetInput() (input.Input, error) {
 
 	f.TemplateBody = webhookTemplate
 	f.Input.IfExistsAction = input.Error
+	// the webhook file scaffolded along with the versions of a kind only registers its conversions, it is
+	// replaced by the one of its defaulting and validating webhooks
+	if (f.Defaulting || f.Validating) && registersConversionsOnly(filepath.Join(f.ProjectPath, f.Path)) {
+		f.Input.IfExistsAction = input.Overwrite
+	}
 	return f.Input, nil
 }
 
@@ -91,6 +104,32 @@ func (f *Webhook) Validate() error {
 	return f.Resource.Validate()
 }
 
+// WebhookPath returns the path of the webhook of a kind relative to the project root
+func WebhookPath(r *resource.Resource, multiGroup bool, naming config.Naming) string {
+	return webhookFilePath(r, multiGroup, naming, "webhook.go")
+}
+
+// registersConversionsOnly returns true if the webhook file of the path only declares SetupWebhookWithManager,
+// without any defaulting nor validating webhook
+func registersConversionsOnly(path string) bool {
+	content, err := filesystem.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return !strings.Contains(string(content), "+kubebuilder:webhook:") &&
+		strings.Count(string(content), "\nfunc ") == 1 &&
+		strings.Contains(string(content), ") SetupWebhookWithManager(mgr ctrl.Manager) error {")
+}
+
+// declaresUnions returns true if the types of r declare the ValidateUnions method scaffolded along with unions
+func declaresUnions(projectPath string, r *resource.Resource, multiGroup bool, naming config.Naming) bool {
+	types, err := filesystem.ReadFile(filepath.Join(projectPath, webhookFilePath(r, multiGroup, naming, "types.go")))
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(types), fmt.Sprintf("func (r *%s) ValidateUnions() error", r.Kind))
+}
+
 const (
 	WebhookTemplate = `{{ .Boilerplate }}
 
@@ -109,6 +148,9 @@ import (
 var {{ lower .Resource.Kind }}log = logf.Log.WithName("{{ lower .Resource.Kind }}-resource")
 
 func (r *{{.Resource.Kind}}) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	{{- if .AuditAnnotations }}
+	register{{ .Resource.Kind }}AuditedWebhooks(mgr.GetWebhookServer())
+{{ end }}
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
@@ -119,7 +161,7 @@ func (r *{{.Resource.Kind}}) SetupWebhookWithManager(mgr ctrl.Manager) error {
 
 	// nolint:lll
 	DefaultingWebhookTemplate = `
-// +kubebuilder:webhook:path=/mutate-{{ .GroupDomainWithDash }}-{{ .Resource.Version }}-{{ lower .Resource.Kind }},mutating=true,failurePolicy=fail,groups={{ .GroupDomain }},resources={{ .Plural }},verbs=create;update,versions={{ .Resource.Version }},name=m{{ lower .Resource.Kind }}.kb.io
+// +kubebuilder:webhook:path=/mutate-{{ .GroupDomainWithDash }}-{{ .Resource.Version }}-{{ lower .Resource.Kind }},mutating=true,failurePolicy={{ .FailurePolicy }},groups={{ .GroupDomain }},resources={{ .Plural }},verbs=create;update,versions={{ .Resource.Version }},name={{ .Resource.WebhookName "m" }}
 
 var _ webhook.Defaulter = &{{ .Resource.Kind }}{}
 
@@ -133,7 +175,7 @@ func (r *{{ .Resource.Kind }}) Default() {
 	// nolint:lll
 	ValidatingWebhookTemplate = `
 // TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
-// +kubebuilder:webhook:verbs=create;update,path=/validate-{{ .GroupDomainWithDash }}-{{ .Resource.Version }}-{{ lower .Resource.Kind }},mutating=false,failurePolicy=fail,groups={{ .GroupDomain }},resources={{ .Plural }},versions={{ .Resource.Version }},name=v{{ lower .Resource.Kind }}.kb.io
+// +kubebuilder:webhook:verbs=create;update,path=/validate-{{ .GroupDomainWithDash }}-{{ .Resource.Version }}-{{ lower .Resource.Kind }},mutating=false,failurePolicy={{ .FailurePolicy }},groups={{ .GroupDomain }},resources={{ .Plural }},versions={{ .Resource.Version }},name={{ .Resource.WebhookName "v" }}
 
 var _ webhook.Validator = &{{ .Resource.Kind }}{}
 
@@ -142,7 +184,7 @@ func (r *{{ .Resource.Kind }}) ValidateCreate() error {
 	{{ lower .Resource.Kind }}log.Info("validate create", "name", r.Name)
 
 	// TODO(user): fill in your validation logic upon object creation.
-	return nil
+	return {{ if .ValidateUnions }}r.ValidateUnions(){{ else }}nil{{ end }}
 }
 
 // ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
@@ -150,7 +192,7 @@ func (r *{{ .Resource.Kind }}) ValidateUpdate(old runtime.Object) error {
 	{{ lower .Resource.Kind }}log.Info("validate update", "name", r.Name)
 
 	// TODO(user): fill in your validation logic upon object update.
-	return nil
+	return {{ if .ValidateUnions }}r.ValidateUnions(){{ else }}nil{{ end }}
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -17,8 +17,11 @@ limitations under the License.
 package v2
 
 import (
+	"fmt"
 	"path/filepath"
+	"strings"
 
+	"sigs.k8s.io/kubebuilder/internal/filesystem"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
 )
 
@@ -27,6 +30,10 @@ var _ input.File = &ManagerWebhookPatch{}
 // CRDWebhookPatch scaffolds a CRDWebhookPatch for a Resource
 type ManagerWebhookPatch struct {
 	input.Input
+
+	// SelfSigned indicates that the serving certificate is generated by the manager itself, see the certrotator
+	// package, in an emptyDir volume rather than mounted from the secret of the certificate of cert-manager
+	SelfSigned bool
 }
 
 // GetInput implements input.File
@@ -38,6 +45,38 @@ func (f *ManagerWebhookPatch) GetInput() (input.Input, error) {
 	return f.Input, nil
 }
 
+// UseSelfSigned replaces the secret of the certificate of cert-manager mounted by the patch with the emptyDir
+// volume the manager writes its self-signed certificate to
+func (f *ManagerWebhookPatch) UseSelfSigned() error {
+	path := filepath.Join(f.ProjectPath, f.Path)
+	in, err := filesystem.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	content := string(in)
+	if strings.Contains(content, selfSignedVolume) {
+		return nil
+	}
+	if !strings.Contains(content, secretVolume) {
+		return fmt.Errorf("the volume cert of %s isn't the secret webhook-server-cert, replace it with an emptyDir "+
+			"volume the manager writes the serving certificate to", f.Path)
+	}
+	content = strings.Replace(content, secretVolume, selfSignedVolume, 1)
+	content = strings.Replace(content, "          name: cert\n          readOnly: true\n", "          name: cert\n", 1)
+	return filesystem.WriteFile(path, []byte(content), 0644)
+}
+
+const secretVolume = `      - name: cert
+        secret:
+          defaultMode: 420
+          secretName: webhook-server-cert
+`
+
+const selfSignedVolume = `      - name: cert
+        # the serving certificate is generated by the manager, see the certrotator package
+        emptyDir: {}
+`
+
 const ManagerWebhookPatchTemplate = `apiVersion: apps/v1
 kind: Deployment
 metadata:
@@ -55,10 +94,8 @@ spec:
         volumeMounts:
         - mountPath: /tmp/k8s-webhook-server/serving-certs
           name: cert
+{{- if not .SelfSigned }}
           readOnly: true
+{{- end }}
       volumes:
-      - name: cert
-        secret:
-          defaultMode: 420
-          secretName: webhook-server-cert
-`
+` + "{{ if .SelfSigned }}" + selfSignedVolume + "{{ else }}" + secretVolume + "{{ end }}"
@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+)
+
+func renderManagerWebhookPatch(t *testing.T, selfSigned bool) string {
+	var out bytes.Buffer
+	tmpl := template.Must(template.New("patch").Parse(ManagerWebhookPatchTemplate))
+	if err := tmpl.Execute(&out, &ManagerWebhookPatch{SelfSigned: selfSigned}); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestManagerWebhookPatchUseSelfSigned(t *testing.T) {
+	dir, err := ioutil.TempDir("", "patch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "manager_webhook_patch.yaml")
+	if err := ioutil.WriteFile(path, []byte(renderManagerWebhookPatch(t, false)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the secret is replaced once, the patch is then the one scaffolded for a self-signed certificate
+	patch := &ManagerWebhookPatch{Input: input.Input{ProjectPath: dir, Path: "manager_webhook_patch.yaml"}}
+	for i := 0; i < 2; i++ {
+		if err := patch.UseSelfSigned(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := renderManagerWebhookPatch(t, true); string(b) != expected {
+		t.Errorf("expected the patch:\n%s\ngot:\n%s", expected, b)
+	}
+
+	// a patch whose volume was changed is left to update by hand
+	changed := strings.Replace(renderManagerWebhookPatch(t, false), "secretName: webhook-server-cert",
+		"secretName: serving-cert", 1)
+	if err := ioutil.WriteFile(path, []byte(changed), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := patch.UseSelfSigned(); err == nil || !strings.Contains(err.Error(), "replace it with an emptyDir") {
+		t.Errorf("expected an error for the changed volume, got %v", err)
+	}
+}
@@ -0,0 +1,270 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/v2/internal"
+)
+
+const (
+	ReconcilerProvidersScaffoldMarker = "// +kubebuilder:scaffold:reconcilers"
+	WebhookProvidersScaffoldMarker    = "// +kubebuilder:scaffold:webhooks"
+	OperatorFieldsScaffoldMarker      = "// +kubebuilder:scaffold:operator"
+	OperatorSetupScaffoldMarker       = "// +kubebuilder:scaffold:setup"
+	ProviderFuncsScaffoldMarker       = "// +kubebuilder:scaffold:providers"
+)
+
+var _ input.File = &Providers{}
+
+// Providers scaffolds the providers.go declaring the provider sets injecting the reconcilers and the webhooks set
+// up by main.go with google/wire
+type Providers struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *Providers) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = "providers.go"
+	}
+	f.TemplateBody = providersTemplate
+	return f.Input, nil
+}
+
+var _ input.File = &WireInjector{}
+
+// WireInjector scaffolds the wire.go declaring the injector of the reconcilers and the webhooks, only built by wire
+type WireInjector struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *WireInjector) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = "wire.go"
+	}
+	f.TemplateBody = wireInjectorTemplate
+	return f.Input, nil
+}
+
+var _ input.File = &WireGen{}
+
+// WireGen scaffolds the wire_gen.go generated by wire from the injector of an operator without reconciler, for the
+// project to build before make generate runs wire
+type WireGen struct {
+	input.Input
+}
+
+// GetInput implements input.File
+func (f *WireGen) GetInput() (input.Input, error) {
+	if f.Path == "" {
+		f.Path = "wire_gen.go"
+	}
+	f.TemplateBody = wireGenTemplate
+	return f.Input, nil
+}
+
+// updateProviders adds the providers of the reconciler or of the webhooks of the resource to providers.go, main.go
+// sets up the ones injected by wire
+func updateProviders(opts *MainUpdateOptions, resPkg string) error {
+	path := filepath.Join(opts.OutputDir, "providers.go")
+
+	// the names follow the ones of main.go, qualified by the group in the multigroup layout
+	name := strings.ToLower(opts.Resource.Kind[:1]) + opts.Resource.Kind[1:]
+	if opts.Config.MultiGroup {
+		name = opts.Resource.GroupImportSafe + opts.Resource.Kind
+	}
+	api := opts.Resource.GroupImportSafe + opts.Resource.Version
+
+	if opts.WireController {
+		reconciler := opts.Config.Names().ReconcilerName(opts.Resource.Kind)
+		ctrlPkg, ctrlImport := "controllers", fmt.Sprintf(`"%s/controllers"
+`, opts.Config.Repo)
+		if opts.Config.MultiGroup {
+			ctrlPkg = "controller" + opts.Resource.GroupImportSafe
+			ctrlImport = fmt.Sprintf(`%s "%s/controllers/%s"
+`, ctrlPkg, opts.Config.Repo, opts.Resource.Group)
+		}
+		field, provider := name+"Reconciler", "provide"+strings.Title(name)+"Reconciler"
+
+		// gofmt unindents the marker of an empty provider set, the fragments of the sets indent it again
+
+		return internal.InsertStringsInFile(path,
+			map[string][]string{
+				APIPkgImportScaffoldMarker:        {ctrlImport, "\"fmt\"\n"},
+				ReconcilerProvidersScaffoldMarker: {provider + ",\n\t"},
+				OperatorFieldsScaffoldMarker:      {fmt.Sprintf("%s *%s.%s\n", field, ctrlPkg, reconciler)},
+				OperatorSetupScaffoldMarker: {fmt.Sprintf(`if err := o.%s.SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create controller %s: %%w", err)
+	}
+`, field, opts.Resource.Kind)},
+				ProviderFuncsScaffoldMarker: {fmt.Sprintf(`// %s provides the reconciler of %s
+func %s(mgr ctrl.Manager, c client.Client, scheme *runtime.Scheme,
+	clk clock.Clock) *%s.%s {
+	return %s.New%s(
+		c,
+		ctrl.Log.WithName("controllers").WithName("%s"),
+		scheme,
+		mgr.GetEventRecorderFor("%s-controller"),
+		clk,
+	)
+}
+
+`, provider, opts.Resource.Kind, provider, ctrlPkg, reconciler, ctrlPkg, reconciler, opts.Resource.Kind,
+					strings.ToLower(opts.Resource.Kind))},
+			})
+	}
+
+	if opts.WireWebhook {
+		field, provider := name+"Webhook", "provide"+strings.Title(name)+"Webhook"
+
+		return internal.InsertStringsInFile(path,
+			map[string][]string{
+				APIPkgImportScaffoldMarker: {fmt.Sprintf(`%s "%s/%s"
+`, api, resPkg, opts.Resource.Version), "\"fmt\"\n"},
+				WebhookProvidersScaffoldMarker: {provider + ",\n\t"},
+				OperatorFieldsScaffoldMarker:   {fmt.Sprintf("%s *%s.%s\n", field, api, opts.Resource.Kind)},
+				OperatorSetupScaffoldMarker: {fmt.Sprintf(`if err := o.%s.SetupWebhookWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create webhook %s: %%w", err)
+	}
+`, field, opts.Resource.Kind)},
+				ProviderFuncsScaffoldMarker: {fmt.Sprintf(`// %s provides the %s whose webhooks are served
+func %s() *%s.%s {
+	return &%s.%s{}
+}
+
+`, provider, opts.Resource.Kind, provider, api, opts.Resource.Kind, api, opts.Resource.Kind)},
+			})
+	}
+
+	return nil
+}
+
+var providersTemplate = fmt.Sprintf(`{{ .Boilerplate }}
+
+package main
+
+import (
+	"github.com/google/wire"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	%s
+)
+
+// providers inject the reconcilers and the webhooks set up by main.go from the manager, see newOperator.
+// Run make generate after changing them for wire to generate wire_gen.go again.
+var providers = wire.NewSet(
+	clientProviders,
+	reconcilerProviders,
+	webhookProviders,
+	wire.Struct(new(operator), "*"),
+)
+
+// clientProviders provide the clients shared by the reconcilers.
+// TODO(user): add the providers of the clients of the external systems the reconcilers depend on.
+var clientProviders = wire.NewSet(
+	provideClient,
+	provideScheme,
+	provideClock,
+)
+
+// reconcilerProviders provide the reconcilers of the controllers
+var reconcilerProviders = wire.NewSet(
+	%s
+)
+
+// webhookProviders provide the types whose webhooks are served
+var webhookProviders = wire.NewSet(
+	%s
+)
+
+// operator is the reconcilers and the webhooks set up with the manager
+type operator struct {
+	%s
+}
+
+// SetupWithManager sets up the reconcilers and the webhooks of the operator with the manager
+func (o *operator) SetupWithManager(mgr ctrl.Manager) error {
+	%s
+	return nil
+}
+
+// provideClient provides the client of the manager, reading the objects from its cache
+func provideClient(mgr ctrl.Manager) client.Client {
+	return mgr.GetClient()
+}
+
+// provideScheme provides the scheme of the manager
+func provideScheme(mgr ctrl.Manager) *runtime.Scheme {
+	return mgr.GetScheme()
+}
+
+// provideClock provides the source of the current time of the reconcilers
+func provideClock() clock.Clock {
+	return clock.RealClock{}
+}
+
+%s
+`, APIPkgImportScaffoldMarker, ReconcilerProvidersScaffoldMarker, WebhookProvidersScaffoldMarker,
+	OperatorFieldsScaffoldMarker, OperatorSetupScaffoldMarker, ProviderFuncsScaffoldMarker)
+
+const wireInjectorTemplate = `{{ .Boilerplate }}
+
+// +build wireinject
+
+package main
+
+import (
+	"github.com/google/wire"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// newOperator injects the reconcilers and the webhooks of the operator from the manager with the providers of
+// providers.go, wire generates its implementation in wire_gen.go
+func newOperator(mgr ctrl.Manager) (*operator, error) {
+	wire.Build(providers)
+	return nil, nil
+}
+`
+
+const wireGenTemplate = `// Code generated by Wire. DO NOT EDIT.
+
+//go:generate wire
+//+build !wireinject
+
+package main
+
+import (
+	"sigs.k8s.io/controller-runtime"
+)
+
+// Injectors from wire.go:
+
+// newOperator injects the reconcilers and the webhooks of the operator from the manager with the providers of
+// providers.go, wire generates its implementation in wire_gen.go
+func newOperator(mgr controllerruntime.Manager) (*operator, error) {
+	mainOperator := &operator{}
+	return mainOperator, nil
+}
+`
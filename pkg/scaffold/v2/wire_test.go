@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/resource"
+)
+
+func TestMainUpdateWire(t *testing.T) {
+	dir, err := ioutil.TempDir("", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "main.go"), []byte(mainWithMarkers), 0644); err != nil {
+		t.Fatal(err)
+	}
+	providers := strings.Replace(providersTemplate, "{{ .Boilerplate }}", "", 1)
+	if err := ioutil.WriteFile(filepath.Join(dir, "providers.go"), []byte(providers), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &config.Config{Repo: "example.org/project", Domain: "example.org",
+		DependencyInjection: config.WireInjection}
+	for _, kind := range []string{"Captain", "FirstMate"} {
+		r := &resource.Resource{Group: "crew", Version: "v1", Kind: kind}
+		if err := r.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		for _, opts := range []*MainUpdateOptions{
+			{Config: c, Resource: r, OutputDir: dir, WireController: true},
+			{Config: c, Resource: r, OutputDir: dir, WireWebhook: true},
+		} {
+			if err := (&Main{}).Update(opts); err != nil {
+				t.Fatalf("%s: unexpected error: %v", kind, err)
+			}
+		}
+	}
+
+	main, err := ioutil.ReadFile(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(main), "NewCaptainReconciler") ||
+		strings.Contains(string(main), "SetupWebhookWithManager") {
+		t.Errorf("expected main.go not to construct the reconcilers and the webhooks, got:\n%s", main)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "providers.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"\tprovideCaptainReconciler,\n\tprovideFirstMateReconciler,\n\t" + ReconcilerProvidersScaffoldMarker,
+		"\tprovideCaptainWebhook,\n\tprovideFirstMateWebhook,\n\t" + WebhookProvidersScaffoldMarker,
+		"firstMateReconciler *controllers.FirstMateReconciler",
+		"firstMateWebhook    *crewv1.FirstMate",
+		"o.firstMateReconciler.SetupWithManager(mgr)",
+		"o.firstMateWebhook.SetupWebhookWithManager(mgr)",
+		"return controllers.NewFirstMateReconciler(",
+		`mgr.GetEventRecorderFor("firstmate-controller")`,
+		"return &crewv1.FirstMate{}",
+	} {
+		if !strings.Contains(string(b), expected) {
+			t.Errorf("expected %q in providers.go, got:\n%s", expected, b)
+		}
+	}
+}
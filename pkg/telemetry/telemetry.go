@@ -0,0 +1,93 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package telemetry lets the tools embedding kubebuilder receive an event for every run of its commands
+// scaffolding a project, e.g. to audit them. kubebuilder itself sends nothing anywhere: its hook discards the
+// events until an embedding tool sets its own with SetHook, e.g. from an init function of a file added to the
+// build of the kubebuilder command.
+package telemetry
+
+import (
+	"sync"
+	"time"
+)
+
+// Event is a run of a command scaffolding a project
+type Event struct {
+	// Command is the command without the name of the binary, e.g. create api
+	Command string `json:"command"`
+
+	// Args are the positional arguments of the command
+	Args []string `json:"args,omitempty"`
+
+	// Flags are the flags set on the command line or defaulted from the environment or the user configuration,
+	// e.g. --kind=Captain
+	Flags []string `json:"flags,omitempty"`
+
+	// KubebuilderVersion is the version of kubebuilder running the command
+	KubebuilderVersion string `json:"kubebuilderVersion"`
+
+	// Start is the time the command started at, and Duration the time it ran for
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+
+	// Success is true if the command succeeded, Error is the error it failed with otherwise
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Hook receives the events of the scaffolding commands. It is called synchronously once the command finishes,
+// before kubebuilder exits, and must return quickly.
+type Hook interface {
+	Scaffolded(Event)
+}
+
+// HookFunc is a function implementing Hook
+type HookFunc func(Event)
+
+// Scaffolded implements Hook
+func (f HookFunc) Scaffolded(e Event) {
+	f(e)
+}
+
+// NoOp is the hook of kubebuilder, discarding the events
+type NoOp struct{}
+
+// Scaffolded implements Hook
+func (NoOp) Scaffolded(Event) {}
+
+var (
+	mu   sync.Mutex
+	hook Hook = NoOp{}
+)
+
+// SetHook sets the hook receiving the events, nil restoring NoOp
+func SetHook(h Hook) {
+	mu.Lock()
+	defer mu.Unlock()
+	if h == nil {
+		h = NoOp{}
+	}
+	hook = h
+}
+
+// Emit sends the event to the hook
+func Emit(e Event) {
+	mu.Lock()
+	h := hook
+	mu.Unlock()
+	h.Scaffolded(e)
+}
@@ -14,6 +14,7 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
+// Package version is the version of the build of kubebuilder, set by the release with -ldflags
 package version
 
 import (
@@ -54,6 +55,11 @@ func getVersion() Version {
 	}
 }
 
+// Get returns the version of this build of kubebuilder
+func Get() Version {
+	return getVersion()
+}
+
 func (v Version) Print() {
 	fmt.Printf("Version: %#v\n", v)
 }
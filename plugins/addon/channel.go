@@ -30,7 +30,7 @@ manifests:
 
 func ExampleChannel(u *model.Universe) error {
 	m := &model.File{
-		Path:           filepath.Join("channels", "stable"),
+		Path:           filepath.Join(DefaultChannelsDir, "stable"),
 		Contents:       exampleChannel,
 		IfExistsAction: input.Skip,
 	}
@@ -2,7 +2,6 @@ package addon
 
 import (
 	"path/filepath"
-	"strings"
 
 	"sigs.k8s.io/kubebuilder/pkg/model"
 	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
@@ -12,19 +11,25 @@ func ReplaceController(u *model.Universe) error {
 	templateBody := controllerTemplate
 
 	funcs := DefaultTemplateFunctions()
+	funcs["reconciler"] = ReconcilerTemplateFunction(u)
 	contents, err := RunTemplate("controller", templateBody, u, funcs)
 	if err != nil {
 		return err
 	}
 
+	dir := controllersDir(u)
 	m := &model.File{
-		Path:           filepath.Join("controllers", strings.ToLower(u.Resource.Kind)+"_controller.go"),
+		Path:           filepath.Join(dir, Names(u).FileName(u.Resource.Kind)+"_controller.go"),
 		Contents:       contents,
 		IfExistsAction: input.Error,
 	}
 
 	ReplaceFileIfExists(u, m)
 
+	// The scaffolded controller tests exercise the default reconciler, which was replaced
+	RemoveFileIfExists(u, filepath.Join(dir, Names(u).FileName(u.Resource.Kind)+"_controller_test.go"))
+	RemoveFileIfExists(u, filepath.Join(dir, Names(u).FileName(u.Resource.Kind)+"_controller_unit_test.go"))
+
 	return nil
 }
 
@@ -44,27 +49,37 @@ import (
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	api "{{ .Resource.GoPackage }}/{{ .Resource.Version }}"
 )
 
-var _ reconcile.Reconciler = &{{ .Resource.Kind }}Reconciler{}
+var _ reconcile.Reconciler = &{{ reconciler }}{}
 
-// {{ .Resource.Kind }}Reconciler reconciles a {{ .Resource.Kind }} object
-type {{ .Resource.Kind }}Reconciler struct {
+// {{ reconciler }} reconciles a {{ .Resource.Kind }} object
+type {{ reconciler }} struct {
 	client.Client
-	Log logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Clock    clock.Clock
 
 	declarative.Reconciler
 }
 
+// New{{ reconciler }} returns a reconciler of the {{ .Resource.Plural }} with its dependencies
+func New{{ reconciler }}(c client.Client, log logr.Logger, scheme *runtime.Scheme, recorder record.EventRecorder,
+	clk clock.Clock) *{{ reconciler }} {
+	return &{{ reconciler }}{Client: c, Log: log, Scheme: scheme, Recorder: recorder, Clock: clk}
+}
+
 // +kubebuilder:rbac:groups={{.Resource.GroupDomain}},resources={{ .Resource.Plural }},verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups={{.Resource.GroupDomain}},resources={{ .Resource.Plural }}/status,verbs=get;update;patch
 
-func (r *{{ .Resource.Kind }}Reconciler) SetupWithManager(mgr ctrl.Manager) error {
+func (r *{{ reconciler }}) SetupWithManager(mgr ctrl.Manager) error {
 	addon.Init()
 
 	labels := map[string]string{
@@ -9,6 +9,7 @@ import (
 	"github.com/gobuffalo/flect"
 
 	"sigs.k8s.io/kubebuilder/pkg/model"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
 )
 
 // This file gathers functions that are likely to be useful to other
@@ -65,6 +66,35 @@ func ReplaceFile(u *model.Universe, add *model.File) error {
 	return nil
 }
 
+// RemoveFileIfExists removes the file with the specified path from the model
+// Returns true iff the file was removed.
+func RemoveFileIfExists(u *model.Universe, path string) bool {
+	for i, f := range u.Files {
+		if f.Path == path {
+			u.Files = append(u.Files[:i], u.Files[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+// Names returns the naming policies of the project, the default ones if the universe has no configuration
+func Names(u *model.Universe) config.Naming {
+	if u.Config == nil {
+		return config.Naming{}
+	}
+	return u.Config.Names()
+}
+
+// ReconcilerTemplateFunction returns a template function returning the name of the reconciler type of the
+// resource of the universe, following the naming policies of the project
+func ReconcilerTemplateFunction(u *model.Universe) func() string {
+	return func() string {
+		return Names(u).ReconcilerName(u.Resource.Kind)
+	}
+}
+
 func DefaultTemplateFunctions() template.FuncMap {
 	return template.FuncMap{
 		"title":  strings.Title,
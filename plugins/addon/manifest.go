@@ -33,7 +33,7 @@ func ExampleManifest(u *model.Universe) error {
 	packageName := getPackageName(u)
 
 	m := &model.File{
-		Path:           filepath.Join("channels", "packages", packageName, exampleManifestVersion, "manifest.yaml"),
+		Path:           filepath.Join(DefaultChannelsDir, "packages", packageName, exampleManifestVersion, "manifest.yaml"),
 		Contents:       exampleManifestContents,
 		IfExistsAction: input.Skip,
 	}
@@ -1,9 +1,18 @@
 package addon
 
 import (
+	"path/filepath"
+
 	"sigs.k8s.io/kubebuilder/pkg/model"
 )
 
+// DefaultChannelsDir is the directory of the channels and the packages of manifests of the declarative pattern,
+// read by the manager relative to its working directory
+const DefaultChannelsDir = "channels"
+
+// Plugin scaffolds resources whose controllers are declarative reconcilers of the kubebuilder-declarative-pattern
+// library, following the addon pattern: the controller applies the manifest of the version of the package of the
+// resource, listed by a channel, instead of the imperative reconciler.
 type Plugin struct {
 }
 
@@ -13,6 +22,7 @@ func (p *Plugin) Pipe(u *model.Universe) error {
 		ExampleChannel,
 		ReplaceController,
 		ReplaceTypes,
+		UpdateSample,
 	}
 
 	for _, fn := range functions {
@@ -24,3 +34,20 @@ func (p *Plugin) Pipe(u *model.Universe) error {
 
 	return nil
 }
+
+// isMultiGroup returns whether the project has multiple API groups
+func isMultiGroup(u *model.Universe) bool {
+	if u.Config == nil {
+		return false
+	}
+	q, err := u.Config.Query()
+	return err == nil && q.IsMultiGroup()
+}
+
+// controllersDir returns the directory where the controllers of the resource are scaffolded
+func controllersDir(u *model.Universe) string {
+	if isMultiGroup(u) {
+		return filepath.Join("controllers", u.Resource.Group)
+	}
+	return "controllers"
+}
@@ -19,10 +19,10 @@ func ReplaceTypes(u *model.Universe) error {
 	}
 
 	var path string
-	if u.Config.MultiGroup {
-		path = filepath.Join("apis", u.Resource.Version, strings.ToLower(u.Resource.Kind)+"_types.go")
+	if isMultiGroup(u) {
+		path = filepath.Join("apis", u.Resource.Group, u.Resource.Version, Names(u).FileName(u.Resource.Kind)+"_types.go")
 	} else {
-		path = filepath.Join("api", u.Resource.Version, strings.ToLower(u.Resource.Kind)+"_types.go")
+		path = filepath.Join("api", u.Resource.Version, Names(u).FileName(u.Resource.Kind)+"_types.go")
 	}
 
 	m := &model.File{
@@ -36,6 +36,19 @@ func ReplaceTypes(u *model.Universe) error {
 	return nil
 }
 
+// UpdateSample replaces the placeholder fields of the sample with the channel of the manifests to apply
+func UpdateSample(u *model.Universe) error {
+	p := filepath.Join("config", "samples", fmt.Sprintf("%s_%s_%s.yaml",
+		u.Resource.Group, u.Resource.Version, Names(u).FileName(u.Resource.Kind)))
+	for _, f := range u.Files {
+		if f.Path == p {
+			f.Contents = strings.Replace(f.Contents, "  # Add fields here\n  foo: bar\n",
+				"  # the channel listing the version of the manifest to apply, stable if empty\n  channel: stable\n", 1)
+		}
+	}
+	return nil
+}
+
 // JSONTag is a helper to build the json tag for a struct
 // It works around escaping problems for the json tag syntax
 func JSONTag(tag string) string {
@@ -74,6 +87,8 @@ type {{.Resource.Kind}}Status struct {
 }
 
 // +kubebuilder:object:root=true
+// +kubebuilder:printcolumn:name="Healthy",type="boolean",JSONPath=".status.healthy"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // {{.Resource.Kind}} is the Schema for the {{ .Resource.Resource }} API
 type {{.Resource.Kind}} struct {
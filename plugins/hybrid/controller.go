@@ -0,0 +1,268 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hybrid
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/model"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/plugins/addon"
+)
+
+// ManifestApplier adds the generic manifest applier shared by the controllers of the hybrid pattern
+func ManifestApplier(u *model.Universe) error {
+	contents, err := addon.RunTemplate("applier", applierTemplate, u, addon.DefaultTemplateFunctions())
+	if err != nil {
+		return err
+	}
+
+	m := &model.File{
+		Path:           filepath.Join(controllersDir(u), "manifest_applier.go"),
+		Contents:       contents,
+		IfExistsAction: input.Skip,
+	}
+
+	_, err = addon.AddFile(u, m)
+	return err
+}
+
+// ReplaceController replaces the default controller with one applying the manifest of the resource
+func ReplaceController(u *model.Universe) error {
+	s, err := settings(u)
+	if err != nil {
+		return err
+	}
+
+	data := struct {
+		*model.Universe
+		ManifestPath string
+	}{
+		Universe:     u,
+		ManifestPath: filepath.ToSlash(manifestPath(u, s)),
+	}
+
+	funcs := addon.DefaultTemplateFunctions()
+	funcs["reconciler"] = addon.ReconcilerTemplateFunction(u)
+	contents, err := addon.RunTemplate("controller", controllerTemplate, data, funcs)
+	if err != nil {
+		return err
+	}
+
+	dir := controllersDir(u)
+	m := &model.File{
+		Path:           filepath.Join(dir, addon.Names(u).FileName(u.Resource.Kind)+"_controller.go"),
+		Contents:       contents,
+		IfExistsAction: input.Error,
+	}
+
+	addon.ReplaceFileIfExists(u, m)
+
+	// The scaffolded controller tests exercise the default reconciler, which was replaced
+	addon.RemoveFileIfExists(u, filepath.Join(dir, addon.Names(u).FileName(u.Resource.Kind)+"_controller_test.go"))
+	addon.RemoveFileIfExists(u, filepath.Join(dir, addon.Names(u).FileName(u.Resource.Kind)+"_controller_unit_test.go"))
+
+	return nil
+}
+
+const applierTemplate = `{{ .Boilerplate }}
+
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"text/template"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ManifestOwner is an object the manifest is applied for
+type ManifestOwner interface {
+	metav1.Object
+	runtime.Object
+}
+
+// ManifestApplier applies the objects of a manifest following the declarative pattern.
+// The manifest is rendered as a Go template with the owner as data and every object
+// it contains is created or updated with the owner as its controller.
+type ManifestApplier struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// ManifestPath is the path of the manifest to apply
+	ManifestPath string
+}
+
+// Apply renders the manifest for the provided owner and applies every object it contains
+func (a *ManifestApplier) Apply(ctx context.Context, owner ManifestOwner) error {
+	objs, err := a.render(owner)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range objs {
+		if err := controllerutil.SetControllerReference(owner, obj, a.Scheme); err != nil {
+			return err
+		}
+
+		if err := a.apply(ctx, obj); err != nil {
+			return fmt.Errorf("unable to apply %s %s: %v", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+// render renders the manifest for the provided owner and decodes the objects it contains
+func (a *ManifestApplier) render(owner ManifestOwner) ([]*unstructured.Unstructured, error) {
+	content, err := ioutil.ReadFile(a.ManifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := template.New(filepath.Base(a.ManifestPath)).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse manifest %s: %v", a.ManifestPath, err)
+	}
+
+	var b bytes.Buffer
+	if err := t.Execute(&b, owner); err != nil {
+		return nil, fmt.Errorf("unable to render manifest %s: %v", a.ManifestPath, err)
+	}
+
+	var objs []*unstructured.Unstructured
+	decoder := utilyaml.NewYAMLOrJSONDecoder(&b, 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("unable to decode manifest %s: %v", a.ManifestPath, err)
+		}
+
+		// skip empty documents
+		if len(obj.Object) == 0 {
+			continue
+		}
+
+		objs = append(objs, obj)
+	}
+
+	return objs, nil
+}
+
+// apply creates the object or updates it if it already exists
+func (a *ManifestApplier) apply(ctx context.Context, obj *unstructured.Unstructured) error {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+
+	err := a.Get(ctx, client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()}, existing)
+	if apierrors.IsNotFound(err) {
+		return a.Create(ctx, obj)
+	}
+	if err != nil {
+		return err
+	}
+
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	return a.Update(ctx, obj)
+}
+`
+
+// nolint:lll
+const controllerTemplate = `{{ .Boilerplate }}
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "{{ .Resource.GoPackage }}/{{ .Resource.Version }}"
+)
+
+// {{ .Resource.Kind }}ManifestPath is the path of the manifest applied for every {{ .Resource.Kind }}
+const {{ .Resource.Kind }}ManifestPath = "{{ .ManifestPath }}"
+
+// {{ reconciler }} reconciles a {{ .Resource.Kind }} object by applying its manifest
+type {{ reconciler }} struct {
+	client.Client
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Clock    clock.Clock
+}
+
+// New{{ reconciler }} returns a reconciler of the {{ .Resource.Plural }} with its dependencies
+func New{{ reconciler }}(c client.Client, log logr.Logger, scheme *runtime.Scheme, recorder record.EventRecorder,
+	clk clock.Clock) *{{ reconciler }} {
+	return &{{ reconciler }}{Client: c, Log: log, Scheme: scheme, Recorder: recorder, Clock: clk}
+}
+
+// +kubebuilder:rbac:groups={{.Resource.GroupDomain}},resources={{ .Resource.Plural }},verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups={{.Resource.GroupDomain}},resources={{ .Resource.Plural }}/status,verbs=get;update;patch
+// TODO: grant access to the kinds of the objects in the manifest
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+
+func (r *{{ reconciler }}) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("{{ .Resource.Kind | lower }}", req.NamespacedName)
+
+	var instance api.{{ .Resource.Kind }}
+	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	applier := &ManifestApplier{
+		Client:       r.Client,
+		Scheme:       r.Scheme,
+		ManifestPath: {{ .Resource.Kind }}ManifestPath,
+	}
+	if err := applier.Apply(ctx, &instance); err != nil {
+		log.Error(err, "unable to apply manifest")
+		return ctrl.Result{}, err
+	}
+
+	// your logic here, move the parts of the manifest into Go code incrementally
+
+	return ctrl.Result{}, nil
+}
+
+func (r *{{ reconciler }}) SetupWithManager(mgr ctrl.Manager) error {
+	// TODO: watch the kinds of the objects in the manifest with Owns
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&api.{{ .Resource.Kind }}{}).
+		Complete(r)
+}
+`
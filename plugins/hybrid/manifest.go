@@ -0,0 +1,161 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hybrid
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/model"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/plugins/addon"
+)
+
+const exampleManifestContents = `# Placeholder manifest - replace with the manifest for your resource
+# It is rendered as a Go template with the reconciled object as data before being applied.
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Name }}-example
+  namespace: {{ .Namespace }}
+data:
+  owner: {{ .Name }}
+`
+
+const renderedManifestContents = `# Placeholder manifest - run hack/render-charts.sh to render it from the Helm chart
+`
+
+// ExampleManifest adds the manifest applied by the controller of the resource
+func ExampleManifest(u *model.Universe) error {
+	s, err := settings(u)
+	if err != nil {
+		return err
+	}
+
+	contents := exampleManifestContents
+	if s.Source == config.HybridSourceHelm {
+		contents = renderedManifestContents
+	}
+
+	m := &model.File{
+		Path:           manifestPath(u, s),
+		Contents:       contents,
+		IfExistsAction: input.Skip,
+	}
+
+	_, err = addon.AddFile(u, m)
+	return err
+}
+
+const exampleChartContents = `apiVersion: v2
+name: %[1]s
+description: A Helm chart rendered into the manifest applied for every %[2]s
+type: application
+version: 0.1.0
+`
+
+const exampleValuesContents = `# Default values for %[1]s.
+`
+
+const exampleChartTemplateContents = `# Placeholder template - replace with the templates of your chart
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}-example
+data:
+  chart: {{ .Chart.Name }}
+`
+
+// ExampleChart adds a Helm chart the manifest of the resource is rendered from, only for the helm source
+func ExampleChart(u *model.Universe) error {
+	s, err := settings(u)
+	if err != nil {
+		return err
+	}
+
+	if s.Source != config.HybridSourceHelm {
+		return nil
+	}
+
+	chartDir := filepath.Join(s.ChartsDir, getPackageName(u))
+	files := []*model.File{
+		{
+			Path:           filepath.Join(chartDir, "Chart.yaml"),
+			Contents:       fmt.Sprintf(exampleChartContents, getPackageName(u), u.Resource.Kind),
+			IfExistsAction: input.Skip,
+		},
+		{
+			Path:           filepath.Join(chartDir, "values.yaml"),
+			Contents:       fmt.Sprintf(exampleValuesContents, getPackageName(u)),
+			IfExistsAction: input.Skip,
+		},
+		{
+			Path:           filepath.Join(chartDir, "templates", "configmap.yaml"),
+			Contents:       exampleChartTemplateContents,
+			IfExistsAction: input.Skip,
+		},
+	}
+
+	for _, f := range files {
+		if _, err := addon.AddFile(u, f); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+const renderChartsScript = `#!/usr/bin/env bash
+
+# Renders every Helm chart into the manifest applied by the controller of its resource.
+# Run it whenever a chart is modified.
+
+set -o errexit
+set -o nounset
+set -o pipefail
+
+CHARTS_DIR=${CHARTS_DIR:-%[1]s}
+MANIFESTS_DIR=${MANIFESTS_DIR:-%[2]s}
+
+for chart in "${CHARTS_DIR}"/*/; do
+  name=$(basename "${chart}")
+  mkdir -p "${MANIFESTS_DIR}/${name}"
+  helm template "${name}" "${chart}" > "${MANIFESTS_DIR}/${name}/manifest.yaml"
+done
+`
+
+// RenderChartsScript adds the script rendering the Helm charts into manifests, only for the helm source
+func RenderChartsScript(u *model.Universe) error {
+	s, err := settings(u)
+	if err != nil {
+		return err
+	}
+
+	if s.Source != config.HybridSourceHelm {
+		return nil
+	}
+
+	m := &model.File{
+		Path:           filepath.Join("hack", "render-charts.sh"),
+		Contents:       fmt.Sprintf(renderChartsScript, s.ChartsDir, s.ManifestsDir),
+		IfExistsAction: input.Skip,
+	}
+
+	_, err = addon.AddFile(u, m)
+	return err
+}
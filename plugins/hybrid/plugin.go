@@ -0,0 +1,109 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hybrid
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/pkg/model"
+	"sigs.k8s.io/kubebuilder/pkg/model/config"
+	"sigs.k8s.io/kubebuilder/plugins/addon"
+)
+
+// Default directories of the hybrid pattern
+const (
+	DefaultManifestsDir = "manifests"
+	DefaultChartsDir    = "charts"
+)
+
+// Plugin scaffolds resources whose controllers apply manifests rendered from a set of
+// manifests or from a Helm chart, configured through the hybrid section of the PROJECT file.
+// It allows projects coming from Helm-based operators to move their logic into Go incrementally.
+type Plugin struct {
+}
+
+func (p *Plugin) Pipe(u *model.Universe) error {
+	if _, err := settings(u); err != nil {
+		return err
+	}
+
+	functions := []addon.PluginFunc{
+		ManifestApplier,
+		ExampleManifest,
+		ExampleChart,
+		RenderChartsScript,
+		ReplaceController,
+	}
+
+	for _, fn := range functions {
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// settings returns the hybrid configuration of the project with the defaults applied
+func settings(u *model.Universe) (config.Hybrid, error) {
+	s := config.Hybrid{}
+	if u.Config != nil && u.Config.Hybrid != nil {
+		s = *u.Config.Hybrid
+	}
+
+	if s.Source == "" {
+		s.Source = config.HybridSourceManifests
+	}
+	if s.ManifestsDir == "" {
+		s.ManifestsDir = DefaultManifestsDir
+	}
+	if s.ChartsDir == "" {
+		s.ChartsDir = DefaultChartsDir
+	}
+
+	switch s.Source {
+	case config.HybridSourceManifests, config.HybridSourceHelm:
+	default:
+		return s, fmt.Errorf("unknown hybrid source %q, must be one of %q or %q",
+			s.Source, config.HybridSourceManifests, config.HybridSourceHelm)
+	}
+
+	return s, nil
+}
+
+// controllersDir returns the directory where the controllers of the resource are scaffolded
+func controllersDir(u *model.Universe) string {
+	if u.Config == nil {
+		return "controllers"
+	}
+	if q, err := u.Config.Query(); err == nil && q.IsMultiGroup() {
+		return filepath.Join("controllers", u.Resource.Group)
+	}
+	return "controllers"
+}
+
+// getPackageName returns the name of the manifest package of the resource
+func getPackageName(u *model.Universe) string {
+	return strings.ToLower(u.Resource.Kind)
+}
+
+// manifestPath returns the path of the manifest applied for the resource
+func manifestPath(u *model.Universe, s config.Hybrid) string {
+	return filepath.Join(s.ManifestsDir, getPackageName(u), "manifest.yaml")
+}
@@ -0,0 +1,520 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metaoperator
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/model"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/plugins/addon"
+)
+
+// SharedBundles adds the package decoding, applying and upgrading the bundles, shared by the controllers of the
+// meta-operator pattern, and its tests
+func SharedBundles(u *model.Universe) error {
+	for name, t := range map[string]string{
+		"bundles.go":      sharedBundlesTemplate,
+		"bundles_test.go": sharedBundlesTestTemplate,
+	} {
+		contents, err := addon.RunTemplate(name, t, u, addon.DefaultTemplateFunctions())
+		if err != nil {
+			return err
+		}
+
+		m := &model.File{
+			Path:           filepath.Join(DefaultBundlesDir, name),
+			Contents:       contents,
+			IfExistsAction: input.Skip,
+		}
+		if _, err := addon.AddFile(u, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExampleBundle adds the package embedding the bundles of the operator installed by the objects of the resource,
+// with a placeholder bundle of its first version, and the tests of its upgrade path
+func ExampleBundle(u *model.Universe) error {
+	data := struct {
+		*model.Universe
+		PackageName   string
+		BundlesImport string
+	}{
+		Universe:      u,
+		PackageName:   getPackageName(u),
+		BundlesImport: importPath(u, DefaultBundlesDir),
+	}
+
+	for name, t := range map[string]string{
+		"bundles.go":      bundlesTemplate,
+		"bundles_test.go": bundlesTestTemplate,
+		"v0_1_0.go":       exampleBundleTemplate,
+	} {
+		contents, err := addon.RunTemplate(name, t, data, addon.DefaultTemplateFunctions())
+		if err != nil {
+			return err
+		}
+
+		m := &model.File{
+			Path:           filepath.Join(bundlesDir(u), name),
+			Contents:       contents,
+			IfExistsAction: input.Skip,
+		}
+		if _, err := addon.AddFile(u, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const sharedBundlesTemplate = `{{ .Boilerplate }}
+
+// Package bundles applies the bundles of the operators installed by the controllers: the manifests of a version of
+// an operator, e.g. its CRDs, its RBAC and its deployment, embedded in the manager. An installation is upgraded one
+// version at a time along the versions of the bundles of the operator, the upgrade to a version proceeding once the
+// operator is ready in the previous one, which is the upgrade path the operators usually support.
+package bundles
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// Bundle is the manifest of a version of an operator
+type Bundle struct {
+	// Version is the version of the operator, e.g. 0.1.0
+	Version string
+
+	// Manifest is the YAML documents of the objects installing the operator
+	Manifest string
+}
+
+// Catalog is the bundles of an operator, ordered from the oldest version to the latest one
+type Catalog []Bundle
+
+// Latest returns the latest version of the catalog
+func (c Catalog) Latest() string {
+	if len(c) == 0 {
+		return ""
+	}
+	return c[len(c)-1].Version
+}
+
+// Get returns the bundle of a version
+func (c Catalog) Get(version string) (Bundle, bool) {
+	if i := c.index(version); i >= 0 {
+		return c[i], true
+	}
+	return Bundle{}, false
+}
+
+// Next returns the version to install next to upgrade the installed version to the target one, the latest
+// version if the target is empty: the target itself for a first installation, the version following the
+// installed one otherwise. It returns an error if a version isn't in the catalog or for a downgrade.
+func (c Catalog) Next(installed, target string) (string, error) {
+	if target == "" {
+		target = c.Latest()
+	}
+	t := c.index(target)
+	if t < 0 {
+		return "", fmt.Errorf("unknown version %q, the versions are %s", target, strings.Join(c.versions(), ", "))
+	}
+	if installed == "" {
+		return target, nil
+	}
+	i := c.index(installed)
+	switch {
+	case i < 0:
+		return "", fmt.Errorf("the installed version %q isn't in the catalog, it can't be upgraded", installed)
+	case t < i:
+		return "", fmt.Errorf("version %q is older than the installed version %q, downgrades aren't supported",
+			target, installed)
+	case t == i:
+		return target, nil
+	}
+	return c[i+1].Version, nil
+}
+
+// Validate checks that the versions of the catalog are unique and that their manifests decode
+func (c Catalog) Validate() error {
+	if len(c) == 0 {
+		return fmt.Errorf("the catalog has no bundle")
+	}
+	seen := map[string]bool{}
+	for _, b := range c {
+		if b.Version == "" {
+			return fmt.Errorf("a bundle has no version")
+		}
+		if seen[b.Version] {
+			return fmt.Errorf("version %q has several bundles", b.Version)
+		}
+		seen[b.Version] = true
+
+		objs, err := Decode(b.Manifest)
+		if err != nil {
+			return fmt.Errorf("invalid bundle of version %q: %v", b.Version, err)
+		}
+		if len(objs) == 0 {
+			return fmt.Errorf("the bundle of version %q has no object", b.Version)
+		}
+	}
+	return nil
+}
+
+// index returns the index of a version in the catalog, -1 if it isn't in the catalog
+func (c Catalog) index(version string) int {
+	for i, b := range c {
+		if b.Version == version {
+			return i
+		}
+	}
+	return -1
+}
+
+// versions returns the versions of the catalog
+func (c Catalog) versions() []string {
+	var versions []string
+	for _, b := range c {
+		versions = append(versions, b.Version)
+	}
+	return versions
+}
+
+// Decode decodes the objects of a manifest, skipping its empty documents
+func Decode(manifest string) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	decoder := utilyaml.NewYAMLOrJSONDecoder(strings.NewReader(manifest), 4096)
+	for {
+		obj := &unstructured.Unstructured{}
+		if err := decoder.Decode(&obj.Object); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if len(obj.Object) == 0 {
+			continue
+		}
+		if obj.GetKind() == "" || obj.GetName() == "" {
+			return nil, fmt.Errorf("an object of the manifest has no kind or no name")
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// Owner is the object an operator is installed for
+type Owner interface {
+	metav1.Object
+	runtime.Object
+}
+
+// Applier applies the objects of the bundles
+type Applier struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// Apply creates the objects of a bundle or updates the existing ones. The owner becomes the controller of the
+// objects it can own, the ones in its namespace or every one if it is cluster-scoped, which are deleted along with
+// it, but the CRDs: deleting them would delete the objects of the installed operator.
+func (a *Applier) Apply(ctx context.Context, owner Owner, objs []*unstructured.Unstructured) error {
+	for _, obj := range objs {
+		obj = obj.DeepCopy()
+		if owns(owner, obj) {
+			if err := controllerutil.SetControllerReference(owner, obj, a.Scheme); err != nil {
+				return err
+			}
+		}
+		if err := a.apply(ctx, obj); err != nil {
+			return fmt.Errorf("unable to apply %s %s: %v", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// owns returns whether the owner is the controller of the object
+func owns(owner Owner, obj *unstructured.Unstructured) bool {
+	if obj.GetKind() == "CustomResourceDefinition" {
+		return false
+	}
+	return owner.GetNamespace() == "" || owner.GetNamespace() == obj.GetNamespace()
+}
+
+// apply creates the object or updates it if it already exists
+func (a *Applier) apply(ctx context.Context, obj *unstructured.Unstructured) error {
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(obj.GroupVersionKind())
+
+	err := a.Get(ctx, client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()}, existing)
+	if apierrors.IsNotFound(err) {
+		return a.Create(ctx, obj)
+	}
+	if err != nil {
+		return err
+	}
+
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	return a.Update(ctx, obj)
+}
+
+// Ready returns whether the deployments of a bundle are available with all their replicas updated, the operator
+// being ready in the version of the bundle
+func Ready(ctx context.Context, c client.Reader, objs []*unstructured.Unstructured) (bool, error) {
+	for _, obj := range objs {
+		if obj.GetKind() != "Deployment" {
+			continue
+		}
+		deployment := &unstructured.Unstructured{}
+		deployment.SetGroupVersionKind(obj.GroupVersionKind())
+		if err := c.Get(ctx, client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()},
+			deployment); err != nil {
+			return false, client.IgnoreNotFound(err)
+		}
+		if !DeploymentReady(deployment) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// DeploymentReady returns whether the deployment controller observed the latest spec of a deployment and all its
+// replicas are updated and available
+func DeploymentReady(deployment *unstructured.Unstructured) bool {
+	replicas, found, _ := unstructured.NestedInt64(deployment.Object, "spec", "replicas")
+	if !found {
+		replicas = 1
+	}
+	observed, _, _ := unstructured.NestedInt64(deployment.Object, "status", "observedGeneration")
+	updated, _, _ := unstructured.NestedInt64(deployment.Object, "status", "updatedReplicas")
+	available, _, _ := unstructured.NestedInt64(deployment.Object, "status", "availableReplicas")
+	return observed >= deployment.GetGeneration() && updated >= replicas && available >= replicas
+}
+`
+
+const sharedBundlesTestTemplate = `{{ .Boilerplate }}
+
+package bundles
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var catalog = Catalog{
+	{Version: "0.1.0", Manifest: manifest},
+	{Version: "0.2.0", Manifest: manifest},
+	{Version: "1.0.0", Manifest: manifest},
+}
+
+const manifest = ` + "`" + `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: example-system
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: example-operator
+  namespace: example-system
+` + "`" + `
+
+func TestNext(t *testing.T) {
+	for _, c := range []struct {
+		installed, target, next string
+		err                     bool
+	}{
+		{installed: "", target: "", next: "1.0.0"},
+		{installed: "", target: "0.2.0", next: "0.2.0"},
+		{installed: "0.1.0", target: "", next: "0.2.0"},
+		{installed: "0.1.0", target: "0.2.0", next: "0.2.0"},
+		{installed: "0.2.0", target: "1.0.0", next: "1.0.0"},
+		{installed: "1.0.0", target: "1.0.0", next: "1.0.0"},
+		{installed: "1.0.0", target: "0.1.0", err: true},
+		{installed: "", target: "2.0.0", err: true},
+		{installed: "0.0.1", target: "1.0.0", err: true},
+	} {
+		next, err := catalog.Next(c.installed, c.target)
+		if c.err {
+			if err == nil {
+				t.Errorf("expected an error upgrading %q to %q, got %q", c.installed, c.target, next)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("unexpected error upgrading %q to %q: %v", c.installed, c.target, err)
+		} else if next != c.next {
+			t.Errorf("expected %q after %q to reach %q, got %q", c.next, c.installed, c.target, next)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := catalog.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, invalid := range []Catalog{
+		{},
+		{Bundle{Version: "", Manifest: manifest}},
+		{Bundle{Version: "0.1.0", Manifest: manifest}, Bundle{Version: "0.1.0", Manifest: manifest}},
+		{Bundle{Version: "0.1.0", Manifest: "---\n"}},
+		{Bundle{Version: "0.1.0", Manifest: "kind: Namespace\n"}},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("expected an error validating %v", invalid)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	objs, err := Decode(manifest)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(objs) != 2 || objs[0].GetKind() != "Namespace" || objs[1].GetName() != "example-operator" {
+		t.Errorf("unexpected objects %v", objs)
+	}
+}
+
+func TestDeploymentReady(t *testing.T) {
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"generation": int64(2)},
+		"spec":     map[string]interface{}{"replicas": int64(2)},
+		"status": map[string]interface{}{
+			"observedGeneration": int64(1),
+			"updatedReplicas":    int64(2),
+			"availableReplicas":  int64(2),
+		},
+	}}
+	if DeploymentReady(deployment) {
+		t.Errorf("expected a deployment whose latest spec isn't observed not to be ready")
+	}
+	_ = unstructured.SetNestedField(deployment.Object, int64(2), "status", "observedGeneration")
+	if !DeploymentReady(deployment) {
+		t.Errorf("expected the deployment to be ready")
+	}
+	_ = unstructured.SetNestedField(deployment.Object, int64(1), "status", "availableReplicas")
+	if DeploymentReady(deployment) {
+		t.Errorf("expected a deployment with unavailable replicas not to be ready")
+	}
+}
+`
+
+const bundlesTemplate = `{{ .Boilerplate }}
+
+// Package {{ .PackageName }} embeds the bundles of the operator installed by the {{ .Resource.Kind }} objects
+package {{ .PackageName }}
+
+import (
+	"{{ .BundlesImport }}"
+)
+
+// Catalog is the bundles of the operator, from the oldest version to the latest one. Add the bundle of a new
+// version at the end of the catalog, in a file of its own, e.g. v0_2_0.go: the installations are upgraded one
+// version at a time in this order.
+var Catalog = bundles.Catalog{
+	{Version: "0.1.0", Manifest: v0_1_0},
+}
+`
+
+const bundlesTestTemplate = `{{ .Boilerplate }}
+
+package {{ .PackageName }}
+
+import (
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	if err := Catalog.Validate(); err != nil {
+		t.Fatalf("invalid catalog: %v", err)
+	}
+}
+
+// TestUpgradePath checks that every version of the catalog is upgraded to the latest one one version at a time
+func TestUpgradePath(t *testing.T) {
+	latest := Catalog.Latest()
+	for i, b := range Catalog {
+		installed := b.Version
+		for steps := 0; installed != latest; steps++ {
+			next, err := Catalog.Next(installed, latest)
+			if err != nil {
+				t.Fatalf("unable to upgrade %s to %s: %v", installed, latest, err)
+			}
+			if next != Catalog[i+steps+1].Version {
+				t.Fatalf("expected %s to be upgraded to %s, got %s", installed, Catalog[i+steps+1].Version, next)
+			}
+			installed = next
+		}
+	}
+}
+`
+
+// nolint:lll
+const exampleBundleTemplate = `{{ .Boilerplate }}
+
+package {{ .PackageName }}
+
+// v0_1_0 is the bundle of the version 0.1.0 of the operator.
+// TODO(user): replace the placeholder with the manifests of the operator, e.g. its CRDs, its RBAC and its deployment
+// nolint:golint,stylecheck
+const v0_1_0 = ` + "`" + `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: {{ .PackageName }}-system
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: {{ .PackageName }}-operator
+  namespace: {{ .PackageName }}-system
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ .PackageName }}-operator
+  namespace: {{ .PackageName }}-system
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: {{ .PackageName }}-operator
+  template:
+    metadata:
+      labels:
+        app: {{ .PackageName }}-operator
+    spec:
+      serviceAccountName: {{ .PackageName }}-operator
+      containers:
+      - name: operator
+        image: example.com/{{ .PackageName }}-operator:v0.1.0
+` + "`" + `
+`
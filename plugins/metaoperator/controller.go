@@ -0,0 +1,189 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metaoperator
+
+import (
+	"path/filepath"
+
+	"sigs.k8s.io/kubebuilder/pkg/model"
+	"sigs.k8s.io/kubebuilder/pkg/scaffold/input"
+	"sigs.k8s.io/kubebuilder/plugins/addon"
+)
+
+// ReplaceController replaces the default controller with one installing and upgrading the operator of the resource
+func ReplaceController(u *model.Universe) error {
+	data := struct {
+		*model.Universe
+		PackageName   string
+		BundlesImport string
+		CatalogImport string
+	}{
+		Universe:      u,
+		PackageName:   getPackageName(u),
+		BundlesImport: importPath(u, DefaultBundlesDir),
+		CatalogImport: importPath(u, bundlesDir(u)),
+	}
+
+	funcs := addon.DefaultTemplateFunctions()
+	funcs["reconciler"] = addon.ReconcilerTemplateFunction(u)
+	contents, err := addon.RunTemplate("controller", controllerTemplate, data, funcs)
+	if err != nil {
+		return err
+	}
+
+	dir := controllersDir(u)
+	m := &model.File{
+		Path:           filepath.Join(dir, addon.Names(u).FileName(u.Resource.Kind)+"_controller.go"),
+		Contents:       contents,
+		IfExistsAction: input.Error,
+	}
+
+	addon.ReplaceFileIfExists(u, m)
+
+	// The scaffolded controller tests exercise the default reconciler, which was replaced, the upgrade path is
+	// tested along with the bundles
+	addon.RemoveFileIfExists(u, filepath.Join(dir, addon.Names(u).FileName(u.Resource.Kind)+"_controller_test.go"))
+	addon.RemoveFileIfExists(u, filepath.Join(dir, addon.Names(u).FileName(u.Resource.Kind)+"_controller_unit_test.go"))
+
+	return nil
+}
+
+// nolint:lll
+const controllerTemplate = `{{ .Boilerplate }}
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "{{ .Resource.GoPackage }}/{{ .Resource.Version }}"
+	"{{ .BundlesImport }}"
+	"{{ .CatalogImport }}"
+)
+
+// {{ .Resource.Kind }}ReadyPollInterval is the interval the readiness of the operator is checked at while it is
+// installed or upgraded
+const {{ .Resource.Kind }}ReadyPollInterval = 10 * time.Second
+
+// {{ reconciler }} reconciles a {{ .Resource.Kind }} object by installing the version of the operator it requests,
+// upgrading the installation one version at a time along the versions of the catalog of the operator
+type {{ reconciler }} struct {
+	client.Client
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Clock    clock.Clock
+}
+
+// New{{ reconciler }} returns a reconciler of the {{ .Resource.Plural }} with its dependencies
+func New{{ reconciler }}(c client.Client, log logr.Logger, scheme *runtime.Scheme, recorder record.EventRecorder,
+	clk clock.Clock) *{{ reconciler }} {
+	return &{{ reconciler }}{Client: c, Log: log, Scheme: scheme, Recorder: recorder, Clock: clk}
+}
+
+// +kubebuilder:rbac:groups={{.Resource.GroupDomain}},resources={{ .Resource.Plural }},verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups={{.Resource.GroupDomain}},resources={{ .Resource.Plural }}/status,verbs=get;update;patch
+// TODO: grant access to the kinds of the objects in the bundles, the RBAC of the installed operator can only be
+// created with the permissions it grants, or with the escalate and bind verbs
+// +kubebuilder:rbac:groups="",resources=namespaces;serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+
+func (r *{{ reconciler }}) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("{{ .Resource.Kind | lower }}", req.NamespacedName)
+
+	var instance api.{{ .Resource.Kind }}
+	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	installed := instance.Status.InstalledVersion
+	next, err := {{ .PackageName }}.Catalog.Next(installed, instance.Spec.Version)
+	if err != nil {
+		// the spec must change for the installation to proceed
+		r.Recorder.Event(&instance, "Warning", "InvalidVersion", err.Error())
+		return ctrl.Result{}, r.setPhase(ctx, &instance, api.{{ .Resource.Kind }}Failed, err.Error())
+	}
+
+	bundle, _ := {{ .PackageName }}.Catalog.Get(next)
+	objs, err := bundles.Decode(bundle.Manifest)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("invalid bundle of version %s: %v", next, err)
+	}
+	applier := &bundles.Applier{Client: r.Client, Scheme: r.Scheme}
+	if err := applier.Apply(ctx, &instance, objs); err != nil {
+		log.Error(err, "unable to apply bundle", "version", next)
+		return ctrl.Result{}, err
+	}
+
+	ready, err := bundles.Ready(ctx, r, objs)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !ready {
+		phase, message := api.{{ .Resource.Kind }}Upgrading, fmt.Sprintf("upgrading from %s to %s", installed, next)
+		if installed == "" {
+			phase, message = api.{{ .Resource.Kind }}Installing, fmt.Sprintf("installing %s", next)
+		} else if installed == next {
+			// the objects of the installed version were changed or deleted, they are restored
+			message = fmt.Sprintf("waiting for %s to be ready", next)
+		}
+		return ctrl.Result{RequeueAfter: {{ .Resource.Kind }}ReadyPollInterval}, r.setPhase(ctx, &instance, phase, message)
+	}
+
+	if installed != next {
+		log.Info("operator ready", "version", next)
+		r.Recorder.Eventf(&instance, "Normal", "Installed", "Version %s is ready", next)
+		instance.Status.InstalledVersion = next
+	}
+	target := instance.Spec.Version
+	if target == "" {
+		target = {{ .PackageName }}.Catalog.Latest()
+	}
+	if next != target {
+		// upgrade to the following version right away
+		return ctrl.Result{Requeue: true}, r.setPhase(ctx, &instance, api.{{ .Resource.Kind }}Upgrading,
+			fmt.Sprintf("upgrading from %s to %s", next, target))
+	}
+	return ctrl.Result{}, r.setPhase(ctx, &instance, api.{{ .Resource.Kind }}Installed, "")
+}
+
+// setPhase updates the phase of the installation in the status of the {{ .Resource.Kind }}
+func (r *{{ reconciler }}) setPhase(ctx context.Context, instance *api.{{ .Resource.Kind }}, phase api.{{ .Resource.Kind }}Phase,
+	message string) error {
+	instance.Status.Phase = phase
+	instance.Status.Message = message
+	return r.Status().Update(ctx, instance)
+}
+
+func (r *{{ reconciler }}) SetupWithManager(mgr ctrl.Manager) error {
+	// TODO: watch the kinds of the objects in the bundles with Owns, e.g. the deployments, for the readiness of
+	// the operator to be checked as soon as it changes
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&api.{{ .Resource.Kind }}{}).
+		Complete(r)
+}
+`
@@ -0,0 +1,103 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metaoperator
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/pkg/model"
+	"sigs.k8s.io/kubebuilder/plugins/addon"
+)
+
+// DefaultBundlesDir is the directory of the packages embedding the bundles of the installed operators
+const DefaultBundlesDir = "bundles"
+
+// Plugin scaffolds resources whose objects are installations of another operator, following the operator of
+// operators pattern: the controller applies the bundle of manifests of the version of the operator requested by
+// the object, embedded in the manager, and upgrades the installation one version at a time along the versions of
+// the bundles, tracking the installed version in its status.
+type Plugin struct {
+}
+
+func (p *Plugin) Pipe(u *model.Universe) error {
+	functions := []addon.PluginFunc{
+		UpdateTypes,
+		UpdateSample,
+		SharedBundles,
+		ExampleBundle,
+		ReplaceController,
+	}
+
+	for _, fn := range functions {
+		if err := fn(u); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isMultiGroup returns whether the project has multiple API groups
+func isMultiGroup(u *model.Universe) bool {
+	if u.Config == nil {
+		return false
+	}
+	q, err := u.Config.Query()
+	return err == nil && q.IsMultiGroup()
+}
+
+// controllersDir returns the directory where the controllers of the resource are scaffolded
+func controllersDir(u *model.Universe) string {
+	if isMultiGroup(u) {
+		return filepath.Join("controllers", u.Resource.Group)
+	}
+	return "controllers"
+}
+
+// typesPath returns the path of the types of the resource
+func typesPath(u *model.Universe) string {
+	file := addon.Names(u).FileName(u.Resource.Kind) + "_types.go"
+	if isMultiGroup(u) {
+		return filepath.Join("apis", u.Resource.Group, u.Resource.Version, file)
+	}
+	return filepath.Join("api", u.Resource.Version, file)
+}
+
+// bundlesDir returns the directory of the package embedding the bundles of the operator installed by the
+// objects of the resource
+func bundlesDir(u *model.Universe) string {
+	if isMultiGroup(u) {
+		return filepath.Join(DefaultBundlesDir, u.Resource.Group, getPackageName(u))
+	}
+	return filepath.Join(DefaultBundlesDir, getPackageName(u))
+}
+
+// getPackageName returns the name of the package embedding the bundles of the resource
+func getPackageName(u *model.Universe) string {
+	return strings.ToLower(u.Resource.Kind)
+}
+
+// importPath returns the import path of a directory of the project
+func importPath(u *model.Universe, dir string) string {
+	repo := ""
+	if u.Config != nil {
+		repo = u.Config.Repo
+	}
+	return path.Join(repo, filepath.ToSlash(dir))
+}
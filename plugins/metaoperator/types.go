@@ -0,0 +1,150 @@
+/*
+Copyright 2020 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metaoperator
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"sigs.k8s.io/kubebuilder/pkg/model"
+	"sigs.k8s.io/kubebuilder/plugins/addon"
+)
+
+// The types are edited rather than replaced, for the fields and markers added by the flags of create api to be kept
+const (
+	specFieldsMarker = `// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+`
+	statusFieldsMarker = `// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+`
+	subresourceMarker = "// +kubebuilder:subresource:status\n"
+	rootMarker        = "\n// +kubebuilder:object:root=true\n"
+)
+
+const specFieldsFragment = `
+	// Version is the version of the operator to install, one of the versions of its bundles, the latest one if
+	// empty. The installation is upgraded one version at a time up to it, downgrades are refused.
+	// +optional
+	Version string ` + "`" + `json:"version,omitempty"` + "`" + `
+`
+
+const statusFieldsFragment = `
+	// InstalledVersion is the version of the operator whose bundle is applied and ready
+	// +optional
+	InstalledVersion string ` + "`" + `json:"installedVersion,omitempty"` + "`" + `
+
+	// Phase is the phase of the installation
+	// +optional
+	Phase {{ .Resource.Kind }}Phase ` + "`" + `json:"phase,omitempty"` + "`" + `
+
+	// Message describes the phase, e.g. why the installation failed
+	// +optional
+	Message string ` + "`" + `json:"message,omitempty"` + "`" + `
+`
+
+const printColumnsFragment = `// +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".spec.version"
+// +kubebuilder:printcolumn:name="Installed",type="string",JSONPath=".status.installedVersion"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase"
+`
+
+const phaseFragment = `
+// {{ .Resource.Kind }}Phase is the phase of the installation of the operator
+// +kubebuilder:validation:Enum=Installing;Upgrading;Installed;Failed
+type {{ .Resource.Kind }}Phase string
+
+const (
+	// {{ .Resource.Kind }}Installing is the phase of a first installation, until the operator is ready
+	{{ .Resource.Kind }}Installing {{ .Resource.Kind }}Phase = "Installing"
+
+	// {{ .Resource.Kind }}Upgrading is the phase of an upgrade, until the operator is ready in the version
+	// requested
+	{{ .Resource.Kind }}Upgrading {{ .Resource.Kind }}Phase = "Upgrading"
+
+	// {{ .Resource.Kind }}Installed is the phase of an installation ready in the version requested
+	{{ .Resource.Kind }}Installed {{ .Resource.Kind }}Phase = "Installed"
+
+	// {{ .Resource.Kind }}Failed is the phase of an installation which can't proceed, e.g. the version requested
+	// isn't one of the versions of the bundles
+	{{ .Resource.Kind }}Failed {{ .Resource.Kind }}Phase = "Failed"
+)
+`
+
+// UpdateTypes adds the version of the operator to the spec of the resource and the state of its installation to
+// the status
+func UpdateTypes(u *model.Universe) error {
+	p := typesPath(u)
+	var f *model.File
+	for _, file := range u.Files {
+		if file.Path == p {
+			f = file
+		}
+	}
+	if f == nil {
+		return nil
+	}
+
+	fragments := map[string]string{}
+	for name, fragment := range map[string]string{
+		"spec fields":   specFieldsFragment,
+		"status fields": statusFieldsFragment,
+		"phase":         phaseFragment,
+	} {
+		contents, err := addon.RunTemplate(name, fragment, u, addon.DefaultTemplateFunctions())
+		if err != nil {
+			return err
+		}
+		fragments[name] = contents
+	}
+
+	contents := f.Contents
+	for _, insert := range []struct {
+		marker, fragment string
+		before           bool
+	}{
+		{marker: specFieldsMarker, fragment: fragments["spec fields"]},
+		{marker: statusFieldsMarker, fragment: fragments["status fields"]},
+		{marker: subresourceMarker, fragment: printColumnsFragment},
+		{marker: rootMarker, fragment: fragments["phase"], before: true},
+	} {
+		i := strings.Index(contents, insert.marker)
+		if i < 0 {
+			return fmt.Errorf("unable to find %q in %s", strings.TrimSpace(insert.marker), p)
+		}
+		if !insert.before {
+			i += len(insert.marker)
+		}
+		contents = contents[:i] + insert.fragment + contents[i:]
+	}
+	f.Contents = contents
+
+	return nil
+}
+
+// UpdateSample sets the version of the operator installed by the sample of the resource
+func UpdateSample(u *model.Universe) error {
+	p := filepath.Join("config", "samples", fmt.Sprintf("%s_%s_%s.yaml",
+		u.Resource.Group, u.Resource.Version, addon.Names(u).FileName(u.Resource.Kind)))
+	for _, f := range u.Files {
+		if f.Path == p {
+			f.Contents = strings.Replace(f.Contents, "\nspec:\n",
+				"\nspec:\n  # the version of the operator to install, the latest one if empty\n  version: 0.1.0\n", 1)
+		}
+	}
+	return nil
+}
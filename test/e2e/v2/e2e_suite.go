@@ -20,6 +20,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"io/ioutil"
+	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -28,6 +29,7 @@ import (
 	. "github.com/onsi/ginkgo" //nolint:golint
 	. "github.com/onsi/gomega" //nolint:golint
 
+	"sigs.k8s.io/kubebuilder/pkg/scaffold"
 	"sigs.k8s.io/kubebuilder/test/e2e/utils"
 )
 
@@ -105,49 +107,10 @@ var _ = Describe("kubebuilder", func() {
 				fmt.Sprintf("%s_webhook.go", strings.ToLower(kbc.Kind))))
 			Expect(err).Should(Succeed())
 
-			By("uncomment kustomization.yaml to enable webhook and ca injection")
-			Expect(utils.UncommentCode(
-				filepath.Join(kbc.Dir, "config", "default", "kustomization.yaml"),
-				"#- ../webhook", "#")).To(Succeed())
-			Expect(utils.UncommentCode(
-				filepath.Join(kbc.Dir, "config", "default", "kustomization.yaml"),
-				"#- ../certmanager", "#")).To(Succeed())
+			By("uncomment kustomization.yaml to enable the prometheus monitor")
 			Expect(utils.UncommentCode(
 				filepath.Join(kbc.Dir, "config", "default", "kustomization.yaml"),
 				"#- ../prometheus", "#")).To(Succeed())
-			Expect(utils.UncommentCode(
-				filepath.Join(kbc.Dir, "config", "default", "kustomization.yaml"),
-				"#- manager_webhook_patch.yaml", "#")).To(Succeed())
-			Expect(utils.UncommentCode(
-				filepath.Join(kbc.Dir, "config", "default", "kustomization.yaml"),
-				"#- webhookcainjection_patch.yaml", "#")).To(Succeed())
-			Expect(utils.UncommentCode(filepath.Join(kbc.Dir, "config", "default", "kustomization.yaml"),
-				`#- name: CERTIFICATE_NAMESPACE # namespace of the certificate CR
-#  objref:
-#    kind: Certificate
-#    group: cert-manager.io
-#    version: v1alpha2
-#    name: serving-cert # this name should match the one in certificate.yaml
-#  fieldref:
-#    fieldpath: metadata.namespace
-#- name: CERTIFICATE_NAME
-#  objref:
-#    kind: Certificate
-#    group: cert-manager.io
-#    version: v1alpha2
-#    name: serving-cert # this name should match the one in certificate.yaml
-#- name: SERVICE_NAMESPACE # namespace of the service
-#  objref:
-#    kind: Service
-#    version: v1
-#    name: webhook-service
-#  fieldref:
-#    fieldpath: metadata.namespace
-#- name: SERVICE_NAME
-#  objref:
-#    kind: Service
-#    version: v1
-#    name: webhook-service`, "#")).To(Succeed())
 
 			By("building image")
 			err = kbc.Make("docker-build", "IMG="+kbc.ImageName)
@@ -329,6 +292,48 @@ var _ = Describe("kubebuilder", func() {
 			Expect(count).To(BeNumerically("==", 5))
 		})
 	})
+
+	Context("with registered schemes", func() {
+		var kbc *utils.KBTestContext
+		BeforeEach(func() {
+			var err error
+			kbc, err = utils.TestContext("GO111MODULE=on")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(kbc.Prepare()).To(Succeed())
+		})
+
+		AfterEach(func() {
+			By("remove work dir")
+			kbc.Destroy()
+		})
+
+		It("should build a project registering the known group versions", func() {
+			By("init v2 project registering every known group version")
+			err := kbc.Init(
+				"--project-version", "2",
+				"--domain", kbc.Domain,
+				"--register-scheme", strings.Join(scaffold.KnownSchemes(), ","),
+				"--dep=false")
+			Expect(err).Should(Succeed())
+
+			By("creating api definition")
+			err = kbc.CreateAPI(
+				"--group", kbc.Group,
+				"--version", kbc.Version,
+				"--kind", kbc.Kind,
+				"--namespaced",
+				"--resource",
+				"--controller",
+				"--make=false")
+			Expect(err).Should(Succeed())
+
+			By("building the manager and the suite of the controllers along with the registered group versions")
+			Expect(kbc.Make("manager")).To(Succeed())
+			cmd := exec.Command("go", "test", "-run", "^$", "./...")
+			_, err = kbc.Run(cmd)
+			Expect(err).Should(Succeed())
+		})
+	})
 })
 
 func implementWebhooks(filename string) error {
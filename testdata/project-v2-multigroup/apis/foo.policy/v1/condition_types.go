@@ -0,0 +1,85 @@
+/*
+Copyright 2020 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConditionReady is the type of the condition shown in the Ready column of kubectl get
+const ConditionReady = "Ready"
+
+// Condition describes the state of a resource at a certain point
+type Condition struct {
+	// Type of the condition, e.g. Ready
+	Type string `json:"type"`
+
+	// Status of the condition, one of True, False or Unknown
+	Status metav1.ConditionStatus `json:"status"`
+
+	// LastTransitionTime is the last time the condition changed its status
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// Reason is a machine readable explanation of the last transition
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message is a human readable explanation of the last transition
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// FindCondition returns the condition of the type, nil if there is none
+func FindCondition(conditions []Condition, conditionType string) *Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// IsConditionTrue returns whether the condition of the type has the True status
+func IsConditionTrue(conditions []Condition, conditionType string) bool {
+	c := FindCondition(conditions, conditionType)
+	return c != nil && c.Status == metav1.ConditionTrue
+}
+
+// SetCondition adds the condition or replaces the condition of its type, whose last transition time is kept
+// when its status doesn't change
+func SetCondition(conditions *[]Condition, condition Condition) {
+	if existing := FindCondition(*conditions, condition.Type); existing != nil {
+		if existing.Status == condition.Status {
+			condition.LastTransitionTime = existing.LastTransitionTime
+		}
+		*existing = condition
+		return
+	}
+	*conditions = append(*conditions, condition)
+}
+
+// RemoveCondition removes the condition of the type
+func RemoveCondition(conditions *[]Condition, conditionType string) {
+	kept := (*conditions)[:0]
+	for _, c := range *conditions {
+		if c.Type != conditionType {
+			kept = append(kept, c)
+		}
+	}
+	*conditions = kept
+}
@@ -29,6 +29,7 @@ type HealthCheckPolicySpec struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	// Foo is an example field of HealthCheckPolicy. Edit HealthCheckPolicy_types.go to remove/update
+	// +optional
 	Foo string `json:"foo,omitempty"`
 }
 
@@ -36,9 +37,16 @@ type HealthCheckPolicySpec struct {
 type HealthCheckPolicyStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// Conditions are the latest observations of the state
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // HealthCheckPolicy is the Schema for the healthcheckpolicies API
 type HealthCheckPolicy struct {
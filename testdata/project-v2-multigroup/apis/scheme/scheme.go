@@ -0,0 +1,50 @@
+/*
+Copyright 2020 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package scheme adds the types of every group and version of the API to a scheme, for the consumers of the API,
+// e.g. the clients of other operators, to register all of them with one import:
+//
+//	_ = scheme.AddToScheme(s)
+//
+// The groups and versions are added by kubebuilder create api.
+package scheme
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+
+	crewv1 "sigs.k8s.io/kubebuilder/testdata/project-v2-multigroup/apis/crew/v1"
+	foopolicyv1 "sigs.k8s.io/kubebuilder/testdata/project-v2-multigroup/apis/foo.policy/v1"
+	seacreaturesv1beta1 "sigs.k8s.io/kubebuilder/testdata/project-v2-multigroup/apis/sea-creatures/v1beta1"
+	seacreaturesv1beta2 "sigs.k8s.io/kubebuilder/testdata/project-v2-multigroup/apis/sea-creatures/v1beta2"
+	shipv1 "sigs.k8s.io/kubebuilder/testdata/project-v2-multigroup/apis/ship/v1"
+	shipv1beta1 "sigs.k8s.io/kubebuilder/testdata/project-v2-multigroup/apis/ship/v1beta1"
+	shipv2alpha1 "sigs.k8s.io/kubebuilder/testdata/project-v2-multigroup/apis/ship/v2alpha1"
+	// +kubebuilder:scaffold:imports
+)
+
+// AddToScheme adds the types of every group and version of the API to the scheme
+func AddToScheme(s *runtime.Scheme) error {
+	var builder runtime.SchemeBuilder
+	builder.Register(crewv1.AddToScheme)
+	builder.Register(shipv1beta1.AddToScheme)
+	builder.Register(shipv1.AddToScheme)
+	builder.Register(shipv2alpha1.AddToScheme)
+	builder.Register(seacreaturesv1beta1.AddToScheme)
+	builder.Register(seacreaturesv1beta2.AddToScheme)
+	builder.Register(foopolicyv1.AddToScheme)
+	// +kubebuilder:scaffold:scheme
+	return builder.AddToScheme(s)
+}
@@ -29,6 +29,7 @@ type KrakenSpec struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	// Foo is an example field of Kraken. Edit Kraken_types.go to remove/update
+	// +optional
 	Foo string `json:"foo,omitempty"`
 }
 
@@ -36,9 +37,16 @@ type KrakenSpec struct {
 type KrakenStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// Conditions are the latest observations of the state
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // Kraken is the Schema for the krakens API
 type Kraken struct {
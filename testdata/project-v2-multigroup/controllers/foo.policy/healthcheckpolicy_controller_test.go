@@ -0,0 +1,123 @@
+/*
+Copyright 2020 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	foopolicyv1 "sigs.k8s.io/kubebuilder/testdata/project-v2-multigroup/apis/foo.policy/v1"
+)
+
+// conflictingHealthCheckPolicyClient fails the first status updates with a conflict error
+// to simulate concurrent modifications of the HealthCheckPolicy
+type conflictingHealthCheckPolicyClient struct {
+	client.Client
+
+	// conflicts is the number of status updates that still have to fail
+	conflicts int
+}
+
+func (c *conflictingHealthCheckPolicyClient) Status() client.StatusWriter {
+	return &conflictingHealthCheckPolicyStatusWriter{StatusWriter: c.Client.Status(), client: c}
+}
+
+type conflictingHealthCheckPolicyStatusWriter struct {
+	client.StatusWriter
+	client *conflictingHealthCheckPolicyClient
+}
+
+func (w *conflictingHealthCheckPolicyStatusWriter) Update(ctx context.Context, obj runtime.Object, opts ...client.UpdateOption) error {
+	if w.client.conflicts > 0 {
+		w.client.conflicts--
+		return apierrors.NewConflict(schema.GroupResource{Group: "foo.policy.testproject.org", Resource: "healthcheckpolicies"},
+			"test", errors.New("the object has been modified"))
+	}
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func newHealthCheckPolicyReconcilerWithConflicts(t *testing.T, conflicts int) (*HealthCheckPolicyReconciler, *conflictingHealthCheckPolicyClient) {
+	s := runtime.NewScheme()
+	if err := foopolicyv1.AddToScheme(s); err != nil {
+		t.Fatalf("unable to add types to the scheme: %v", err)
+	}
+
+	instance := &foopolicyv1.HealthCheckPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+	}
+	c := &conflictingHealthCheckPolicyClient{Client: fake.NewFakeClientWithScheme(s, instance), conflicts: conflicts}
+
+	r := NewHealthCheckPolicyReconciler(c, ctrl.Log.WithName("controllers").WithName("HealthCheckPolicy"), s,
+		&record.FakeRecorder{}, clock.NewFakeClock(time.Now()))
+	return r, c
+}
+
+func TestHealthCheckPolicyReconcilerRetriesStatusUpdateOnConflict(t *testing.T) {
+	t.Parallel()
+
+	r, c := newHealthCheckPolicyReconcilerWithConflicts(t, retry.DefaultBackoff.Steps-1)
+
+	result, err := r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: "test", Namespace: "default"}})
+	if err != nil {
+		t.Fatalf("expected the conflicts to be retried, got: %v", err)
+	}
+	if result.Requeue {
+		t.Errorf("expected no requeue once the status update succeeded")
+	}
+	if c.conflicts != 0 {
+		t.Errorf("expected every simulated conflict to be hit, %d left", c.conflicts)
+	}
+}
+
+func TestHealthCheckPolicyReconcilerRequeuesOnPersistentConflict(t *testing.T) {
+	t.Parallel()
+
+	r, _ := newHealthCheckPolicyReconcilerWithConflicts(t, retry.DefaultBackoff.Steps)
+
+	result, err := r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: "test", Namespace: "default"}})
+	if err != nil {
+		t.Fatalf("expected the conflict to be handled with a requeue, got: %v", err)
+	}
+	if !result.Requeue {
+		t.Errorf("expected a requeue when the conflict persists")
+	}
+}
+
+func TestHealthCheckPolicyReconcilerIgnoresNotFound(t *testing.T) {
+	t.Parallel()
+
+	r, _ := newHealthCheckPolicyReconcilerWithConflicts(t, 0)
+
+	_, err := r.Reconcile(ctrl.Request{NamespacedName: types.NamespacedName{Name: "missing", Namespace: "default"}})
+	if err != nil {
+		t.Fatalf("expected deleted objects to be ignored, got: %v", err)
+	}
+}
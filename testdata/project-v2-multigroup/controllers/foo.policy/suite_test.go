@@ -17,11 +17,14 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
 	"path/filepath"
 	"testing"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -35,11 +38,17 @@ import (
 
 // These tests use Ginkgo (BDD-style Go testing framework). Refer to
 // http://onsi.github.io/ginkgo/ to learn more about Ginkgo.
+// The specs can run in parallel with 'ginkgo -p' (make test-parallel), every ginkgo process runs
+// BeforeSuite and starts its own test environment.
 
 var cfg *rest.Config
 var k8sClient client.Client
 var testEnv *envtest.Environment
 
+// testNamespace is the namespace of the running spec. Every spec creates its objects in its own namespace,
+// it doesn't depend on the objects of the previous specs, e.g. left over by a failure.
+var testNamespace string
+
 func TestAPIs(t *testing.T) {
 	RegisterFailHandler(Fail)
 
@@ -53,7 +62,7 @@ var _ = BeforeSuite(func(done Done) {
 
 	By("bootstrapping test environment")
 	testEnv = &envtest.Environment{
-		CRDDirectoryPaths: []string{filepath.Join("..", "config", "crd", "bases")},
+		CRDDirectoryPaths: []string{filepath.Join("..", "..", "config", "crd", "bases")},
 	}
 
 	var err error
@@ -73,6 +82,18 @@ var _ = BeforeSuite(func(done Done) {
 	close(done)
 }, 60)
 
+var _ = BeforeEach(func() {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{GenerateName: "test-"}}
+	Expect(k8sClient.Create(context.Background(), ns)).To(Succeed())
+	testNamespace = ns.Name
+})
+
+var _ = AfterEach(func() {
+	// the test environment runs no namespace controller, the namespace is only marked for deletion
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testNamespace}}
+	Expect(client.IgnoreNotFound(k8sClient.Delete(context.Background(), ns))).To(Succeed())
+})
+
 var _ = AfterSuite(func() {
 	By("tearing down the test environment")
 	err := testEnv.Stop()
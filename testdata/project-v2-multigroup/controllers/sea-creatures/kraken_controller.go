@@ -20,11 +20,16 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	seacreaturesv1beta1 "sigs.k8s.io/kubebuilder/testdata/project-v2-multigroup/apis/sea-creatures/v1beta1"
+	"sigs.k8s.io/kubebuilder/testdata/project-v2-multigroup/internal/errors"
 )
 
 // KrakenReconciler reconciles a Kraken object
@@ -32,20 +37,89 @@ type KrakenReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+
+	// Recorder records the events of the krakens
+	Recorder record.EventRecorder
+
+	// Clock is the source of the current time, faked by the tests
+	Clock clock.Clock
+}
+
+// NewKrakenReconciler returns a reconciler of the krakens with its dependencies.
+// TODO(user): add the clients of the external systems the reconciler depends on, main.go passes them.
+func NewKrakenReconciler(c client.Client, log logr.Logger, scheme *runtime.Scheme, recorder record.EventRecorder,
+	clk clock.Clock) *KrakenReconciler {
+	return &KrakenReconciler{
+		Client:   c,
+		Log:      log,
+		Scheme:   scheme,
+		Recorder: recorder,
+		Clock:    clk,
+	}
 }
 
 // +kubebuilder:rbac:groups=sea-creatures.testproject.org,resources=krakens,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=sea-creatures.testproject.org,resources=krakens/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *KrakenReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
-	_ = context.Background()
-	_ = r.Log.WithValues("kraken", req.NamespacedName)
+	ctx := context.Background()
+	log := r.Log.WithValues("kraken", req.NamespacedName)
+
+	var instance seacreaturesv1beta1.Kraken
+	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
+		// not found errors can't be fixed by a requeue, the object will be reconciled again if it is recreated
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// your logic here, return errors.Result(errors.Terminal(err)) for the errors a retry can't fix, e.g. an invalid
+	// spec, and errors.Result(errors.RequeueAfter(err, delay)) to retry after a delay rather than with backoff.
+	// Stamp the objects created for the Kraken with the standard labels of labels.Stamp. Only write
+	// its status, with r.updateStatus or r.patchStatus, its spec is owned by its users.
 
-	// your logic here
+	err := r.updateStatus(ctx, req.NamespacedName, func(instance *seacreaturesv1beta1.Kraken) {
+		// set the observed state of the Kraken here
+	})
+	if err != nil {
+		if apierrors.IsConflict(err) {
+			// the object is still being modified concurrently, it is requeued to try again with its next version
+			log.V(1).Info("conflict updating status, requeuing")
+		} else {
+			log.Error(err, "unable to update status")
+		}
+		return errors.Result(err)
+	}
 
 	return ctrl.Result{}, nil
 }
 
+// updateStatus applies mutate to the latest version of the Kraken and updates its status,
+// retrying with backoff when the update conflicts with a concurrent modification of the object
+func (r *KrakenReconciler) updateStatus(ctx context.Context, key client.ObjectKey,
+	mutate func(*seacreaturesv1beta1.Kraken)) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		var instance seacreaturesv1beta1.Kraken
+		if err := r.Get(ctx, key, &instance); err != nil {
+			return err
+		}
+
+		mutate(&instance)
+
+		return r.Status().Update(ctx, &instance)
+	})
+}
+
+// patchStatus patches the status of the Kraken with the changes mutate makes to it. Unlike updateStatus,
+// it doesn't conflict with the concurrent modifications of the Kraken, the fields it sets overwrite theirs.
+// Only the status subresource is patched: a reconciler never writes the spec of its own objects, which
+// kubebuilder lint controllers checks.
+func (r *KrakenReconciler) patchStatus(ctx context.Context, instance *seacreaturesv1beta1.Kraken,
+	mutate func(*seacreaturesv1beta1.Kraken)) error {
+	base := instance.DeepCopy()
+	mutate(instance)
+	return r.Status().Patch(ctx, instance, client.MergeFrom(base))
+}
+
 func (r *KrakenReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&seacreaturesv1beta1.Kraken{}).
@@ -20,11 +20,16 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/clock"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	shipv2alpha1 "sigs.k8s.io/kubebuilder/testdata/project-v2-multigroup/apis/ship/v2alpha1"
+	"sigs.k8s.io/kubebuilder/testdata/project-v2-multigroup/internal/errors"
 )
 
 // CruiserReconciler reconciles a Cruiser object
@@ -32,20 +37,89 @@ type CruiserReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+
+	// Recorder records the events of the cruisers
+	Recorder record.EventRecorder
+
+	// Clock is the source of the current time, faked by the tests
+	Clock clock.Clock
+}
+
+// NewCruiserReconciler returns a reconciler of the cruisers with its dependencies.
+// TODO(user): add the clients of the external systems the reconciler depends on, main.go passes them.
+func NewCruiserReconciler(c client.Client, log logr.Logger, scheme *runtime.Scheme, recorder record.EventRecorder,
+	clk clock.Clock) *CruiserReconciler {
+	return &CruiserReconciler{
+		Client:   c,
+		Log:      log,
+		Scheme:   scheme,
+		Recorder: recorder,
+		Clock:    clk,
+	}
 }
 
 // +kubebuilder:rbac:groups=ship.testproject.org,resources=cruisers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=ship.testproject.org,resources=cruisers/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *CruiserReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
-	_ = context.Background()
-	_ = r.Log.WithValues("cruiser", req.NamespacedName)
+	ctx := context.Background()
+	log := r.Log.WithValues("cruiser", req.NamespacedName)
+
+	var instance shipv2alpha1.Cruiser
+	if err := r.Get(ctx, req.NamespacedName, &instance); err != nil {
+		// not found errors can't be fixed by a requeue, the object will be reconciled again if it is recreated
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// your logic here, return errors.Result(errors.Terminal(err)) for the errors a retry can't fix, e.g. an invalid
+	// spec, and errors.Result(errors.RequeueAfter(err, delay)) to retry after a delay rather than with backoff.
+	// Stamp the objects created for the Cruiser with the standard labels of labels.Stamp. Only write
+	// its status, with r.updateStatus or r.patchStatus, its spec is owned by its users.
 
-	// your logic here
+	err := r.updateStatus(ctx, req.NamespacedName, func(instance *shipv2alpha1.Cruiser) {
+		// set the observed state of the Cruiser here
+	})
+	if err != nil {
+		if apierrors.IsConflict(err) {
+			// the object is still being modified concurrently, it is requeued to try again with its next version
+			log.V(1).Info("conflict updating status, requeuing")
+		} else {
+			log.Error(err, "unable to update status")
+		}
+		return errors.Result(err)
+	}
 
 	return ctrl.Result{}, nil
 }
 
+// updateStatus applies mutate to the latest version of the Cruiser and updates its status,
+// retrying with backoff when the update conflicts with a concurrent modification of the object
+func (r *CruiserReconciler) updateStatus(ctx context.Context, key client.ObjectKey,
+	mutate func(*shipv2alpha1.Cruiser)) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		var instance shipv2alpha1.Cruiser
+		if err := r.Get(ctx, key, &instance); err != nil {
+			return err
+		}
+
+		mutate(&instance)
+
+		return r.Status().Update(ctx, &instance)
+	})
+}
+
+// patchStatus patches the status of the Cruiser with the changes mutate makes to it. Unlike updateStatus,
+// it doesn't conflict with the concurrent modifications of the Cruiser, the fields it sets overwrite theirs.
+// Only the status subresource is patched: a reconciler never writes the spec of its own objects, which
+// kubebuilder lint controllers checks.
+func (r *CruiserReconciler) patchStatus(ctx context.Context, instance *shipv2alpha1.Cruiser,
+	mutate func(*shipv2alpha1.Cruiser)) error {
+	base := instance.DeepCopy()
+	mutate(instance)
+	return r.Status().Patch(ctx, instance, client.MergeFrom(base))
+}
+
 func (r *CruiserReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&shipv2alpha1.Cruiser{}).
@@ -0,0 +1,81 @@
+/*
+Copyright 2020 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package errors
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestResult(t *testing.T) {
+	failure := fmt.Errorf("failure")
+	conflict := apierrors.NewConflict(schema.GroupResource{Resource: "tests"}, "test", failure)
+
+	tests := []struct {
+		name   string
+		err    error
+		result ctrl.Result
+		failed bool
+	}{
+		{name: "no error"},
+		{name: "error", err: failure, failed: true},
+		{name: "terminal error", err: Terminal(failure)},
+		{name: "wrapped terminal error", err: fmt.Errorf("reconciling: %w", Terminal(failure))},
+		{
+			name:   "requeue after",
+			err:    RequeueAfter(failure, time.Minute),
+			result: ctrl.Result{RequeueAfter: time.Minute},
+		},
+		{
+			name:   "requeue after without error",
+			err:    RequeueAfter(nil, time.Second),
+			result: ctrl.Result{RequeueAfter: time.Second},
+		},
+		{name: "conflict", err: conflict, result: ctrl.Result{Requeue: true}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := Result(test.err)
+			if result != test.result {
+				t.Errorf("expected the result %+v, got %+v", test.result, result)
+			}
+			if failed := err != nil; failed != test.failed {
+				t.Errorf("expected the reconcile to fail: %t, got the error: %v", test.failed, err)
+			}
+		})
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	if Terminal(nil) != nil {
+		t.Errorf("expected no terminal error without error")
+	}
+
+	failure := fmt.Errorf("failure")
+	err := fmt.Errorf("reconciling: %w", Terminal(failure))
+	if !IsTerminal(err) {
+		t.Errorf("expected %v to be terminal", err)
+	}
+	if IsTerminal(failure) {
+		t.Errorf("expected %v not to be terminal", failure)
+	}
+}
@@ -0,0 +1,5 @@
+module sigs.k8s.io/kubebuilder/testdata/project-v2-multigroup/internal/manifests
+
+go 1.13
+
+require sigs.k8s.io/kustomize/api v0.3.2
@@ -0,0 +1,105 @@
+/*
+Copyright 2020 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package manifests tests the manifests rendered from the config directory, as kustomize build does, against the
+// golden manifests of testdata, so that its drifts, e.g. a renamed patch or a broken var, are caught by the tests
+// rather than when deploying. The golden manifests are recorded when missing, update them with
+// make update-manifests-golden after changing the config directory on purpose.
+//
+// It is a module of its own, for the dependencies of the kustomize library not to constrain the ones of the
+// manager, tested by make test after the other packages.
+package manifests
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/kustomize/api/filesys"
+	"sigs.k8s.io/kustomize/api/krusty"
+)
+
+var update = flag.Bool("update", false, "update the golden manifests with the rendered ones")
+
+// kustomizations are the kustomizations rendered, relative to the project root, and their golden manifests.
+// TODO(user): add the other overlays deployed, e.g. the ones of your environments.
+var kustomizations = map[string]string{
+	"config/default": "default.yaml",
+}
+
+func TestManifests(t *testing.T) {
+	for dir, golden := range kustomizations {
+		rendered, err := render(filepath.Join("..", "..", filepath.FromSlash(dir)))
+		if err != nil {
+			t.Errorf("unable to render %s, run make manifests if the CRDs or the roles are missing: %v", dir, err)
+			continue
+		}
+
+		path := filepath.Join("testdata", golden)
+		expected, err := ioutil.ReadFile(path)
+		if *update || os.IsNotExist(err) {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(path, rendered, 0644); err != nil {
+				t.Fatal(err)
+			}
+			t.Logf("recorded the manifests of %s in %s", dir, path)
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(rendered, expected) {
+			t.Errorf("the manifests of %s differ from %s, run make update-manifests-golden if it is on purpose:\n%s",
+				dir, path, diff(expected, rendered))
+		}
+	}
+}
+
+// render returns the manifests of the kustomization of dir
+func render(dir string) ([]byte, error) {
+	resources, err := krusty.MakeKustomizer(filesys.MakeFsOnDisk(), krusty.MakeDefaultOptions()).Run(dir)
+	if err != nil {
+		return nil, err
+	}
+	return resources.AsYaml()
+}
+
+// diff returns the first lines differing between expected and rendered
+func diff(expected, rendered []byte) string {
+	expectedLines := strings.Split(string(expected), "\n")
+	renderedLines := strings.Split(string(rendered), "\n")
+	for i := 0; i < len(expectedLines) || i < len(renderedLines); i++ {
+		var e, r string
+		if i < len(expectedLines) {
+			e = expectedLines[i]
+		}
+		if i < len(renderedLines) {
+			r = renderedLines[i]
+		}
+		if e != r {
+			return fmt.Sprintf("line %d:\n- %s\n+ %s", i+1, e, r)
+		}
+	}
+	return ""
+}
@@ -24,8 +24,11 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
+	"k8s.io/apimachinery/pkg/util/clock"
+
 	crewv1 "sigs.k8s.io/kubebuilder/testdata/project-v2-multigroup/apis/crew/v1"
 	foopolicyv1 "sigs.k8s.io/kubebuilder/testdata/project-v2-multigroup/apis/foo.policy/v1"
 	seacreaturesv1beta1 "sigs.k8s.io/kubebuilder/testdata/project-v2-multigroup/apis/sea-creatures/v1beta1"
@@ -37,6 +40,7 @@ import (
 	controllerfoopolicy "sigs.k8s.io/kubebuilder/testdata/project-v2-multigroup/controllers/foo.policy"
 	controllerseacreatures "sigs.k8s.io/kubebuilder/testdata/project-v2-multigroup/controllers/sea-creatures"
 	controllership "sigs.k8s.io/kubebuilder/testdata/project-v2-multigroup/controllers/ship"
+	"sigs.k8s.io/kubebuilder/testdata/project-v2-multigroup/internal/events"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -59,9 +63,16 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == events.Command {
+		// list the recent Events of an object rather than running the manager, see make events
+		os.Exit(events.Run(os.Args[2:], os.Stdout))
+	}
+
 	var metricsAddr string
+	var probeAddr string
 	var enableLeaderElection bool
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	flag.StringVar(&probeAddr, "health-probe-addr", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -72,21 +83,24 @@ func main() {
 	}))
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
-		Scheme:             scheme,
-		MetricsBindAddress: metricsAddr,
-		LeaderElection:     enableLeaderElection,
-		Port:               9443,
+		Scheme:                 scheme,
+		MetricsBindAddress:     metricsAddr,
+		HealthProbeBindAddress: probeAddr,
+		LeaderElection:         enableLeaderElection,
+		Port:                   9443,
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	if err = (&controllercrew.CaptainReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Captain"),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	if err = controllercrew.NewCaptainReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName("Captain"),
+		mgr.GetScheme(),
+		mgr.GetEventRecorderFor("captain-controller"),
+		clock.RealClock{},
+	).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Captain")
 		os.Exit(1)
 	}
@@ -94,11 +108,13 @@ func main() {
 		setupLog.Error(err, "unable to create webhook", "webhook", "Captain")
 		os.Exit(1)
 	}
-	if err = (&controllership.FrigateReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Frigate"),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	if err = controllership.NewFrigateReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName("Frigate"),
+		mgr.GetScheme(),
+		mgr.GetEventRecorderFor("frigate-controller"),
+		clock.RealClock{},
+	).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Frigate")
 		os.Exit(1)
 	}
@@ -106,48 +122,67 @@ func main() {
 		setupLog.Error(err, "unable to create webhook", "webhook", "Frigate")
 		os.Exit(1)
 	}
-	if err = (&controllership.DestroyerReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Destroyer"),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	if err = controllership.NewDestroyerReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName("Destroyer"),
+		mgr.GetScheme(),
+		mgr.GetEventRecorderFor("destroyer-controller"),
+		clock.RealClock{},
+	).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Destroyer")
 		os.Exit(1)
 	}
-	if err = (&controllership.CruiserReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Cruiser"),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	if err = controllership.NewCruiserReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName("Cruiser"),
+		mgr.GetScheme(),
+		mgr.GetEventRecorderFor("cruiser-controller"),
+		clock.RealClock{},
+	).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Cruiser")
 		os.Exit(1)
 	}
-	if err = (&controllerseacreatures.KrakenReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Kraken"),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	if err = controllerseacreatures.NewKrakenReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName("Kraken"),
+		mgr.GetScheme(),
+		mgr.GetEventRecorderFor("kraken-controller"),
+		clock.RealClock{},
+	).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Kraken")
 		os.Exit(1)
 	}
-	if err = (&controllerseacreatures.LeviathanReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Leviathan"),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	if err = controllerseacreatures.NewLeviathanReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName("Leviathan"),
+		mgr.GetScheme(),
+		mgr.GetEventRecorderFor("leviathan-controller"),
+		clock.RealClock{},
+	).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Leviathan")
 		os.Exit(1)
 	}
-	if err = (&controllerfoopolicy.HealthCheckPolicyReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("HealthCheckPolicy"),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	if err = controllerfoopolicy.NewHealthCheckPolicyReconciler(
+		mgr.GetClient(),
+		ctrl.Log.WithName("controllers").WithName("HealthCheckPolicy"),
+		mgr.GetScheme(),
+		mgr.GetEventRecorderFor("healthcheckpolicy-controller"),
+		clock.RealClock{},
+	).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "HealthCheckPolicy")
 		os.Exit(1)
 	}
 	// +kubebuilder:scaffold:builder
 
+	if err := mgr.AddHealthzCheck("ping", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to set up health check")
+		os.Exit(1)
+	}
+	if err := mgr.AddReadyzCheck("ping", healthz.Ping); err != nil {
+		setupLog.Error(err, "unable to set up ready check")
+		os.Exit(1)
+	}
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
@@ -0,0 +1,111 @@
+/*
+Copyright 2020 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package errors classifies the errors of the reconcilers by the way they are retried:
+//
+//   - terminal errors, that a retry can't fix, e.g. an invalid spec, are not retried, the object is reconciled
+//     again when it changes
+//   - errors requeuing after a delay, e.g. while waiting for an external system, are retried after that delay
+//   - conflicts are retried right away with the next version of the object
+//   - any other error is retried with exponential backoff
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// TerminalError is an error that a retry can't fix
+type TerminalError struct {
+	Err error
+}
+
+// Terminal wraps err into a TerminalError, nil if err is nil
+func Terminal(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &TerminalError{Err: err}
+}
+
+// Error implements error
+func (e *TerminalError) Error() string {
+	return fmt.Sprintf("terminal error: %v", e.Err)
+}
+
+// Unwrap returns the wrapped error
+func (e *TerminalError) Unwrap() error {
+	return e.Err
+}
+
+// IsTerminal returns true if err is, or wraps, a TerminalError
+func IsTerminal(err error) bool {
+	var terminal *TerminalError
+	return stderrors.As(err, &terminal)
+}
+
+// RequeueAfterError is an error retried after a delay rather than with backoff
+type RequeueAfterError struct {
+	Err   error
+	After time.Duration
+}
+
+// RequeueAfter wraps err into a RequeueAfterError retried after the delay, err may be nil to requeue without error
+func RequeueAfter(err error, after time.Duration) error {
+	return &RequeueAfterError{Err: err, After: after}
+}
+
+// Error implements error
+func (e *RequeueAfterError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("requeue after %s", e.After)
+	}
+	return fmt.Sprintf("%v, requeue after %s", e.Err, e.After)
+}
+
+// Unwrap returns the wrapped error
+func (e *RequeueAfterError) Unwrap() error {
+	return e.Err
+}
+
+// IsRequeueAfter returns the delay of err if it is, or wraps, a RequeueAfterError
+func IsRequeueAfter(err error) (time.Duration, bool) {
+	var requeue *RequeueAfterError
+	if !stderrors.As(err, &requeue) {
+		return 0, false
+	}
+	return requeue.After, true
+}
+
+// Result returns the result and the error a reconciler returns to retry err as classified by the package.
+// Terminal errors are not returned since the controller would retry them, record them beforehand, e.g. in the
+// status of the object or in an event.
+func Result(err error) (ctrl.Result, error) {
+	if err == nil || IsTerminal(err) {
+		return ctrl.Result{}, nil
+	}
+	if after, ok := IsRequeueAfter(err); ok {
+		return ctrl.Result{RequeueAfter: after}, nil
+	}
+	if apierrors.IsConflict(err) {
+		return ctrl.Result{Requeue: true}, nil
+	}
+	return ctrl.Result{}, err
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package events
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+)
+
+func TestSelector(t *testing.T) {
+	event := fields.Set{
+		"involvedObject.kind":      "Captain",
+		"involvedObject.namespace": "default",
+		"involvedObject.name":      "test",
+	}
+	if !Selector("Captain", "default", "test").Matches(event) {
+		t.Errorf("expected the selector to match the Events of the object")
+	}
+	if Selector("Captain", "other", "test").Matches(event) {
+		t.Errorf("expected the selector not to match the Events of an object of another namespace")
+	}
+	if Selector("FirstMate", "default", "test").Matches(event) {
+		t.Errorf("expected the selector not to match the Events of an object of another kind")
+	}
+	if !Selector("Captain", "", "test").Matches(event) {
+		t.Errorf("expected the selector without namespace to match the Events of the objects of every namespace")
+	}
+}
+
+func TestPrint(t *testing.T) {
+	seen := metav1.NewTime(time.Date(2020, 3, 1, 10, 0, 0, 0, time.UTC))
+	event := corev1.Event{
+		Type:          corev1.EventTypeWarning,
+		Reason:        "ReconcileError",
+		Message:       "unable to create the child",
+		Source:        corev1.EventSource{Component: "captain-controller"},
+		Count:         3,
+		LastTimestamp: seen,
+	}
+	var out bytes.Buffer
+	if err := Print(&out, []corev1.Event{event}); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a header and an Event, got:\n%s", out.String())
+	}
+	for _, field := range []string{"2020-03-01T10:00:00Z", "Warning", "ReconcileError", "captain-controller", "3",
+		"unable to create the child"} {
+		if !strings.Contains(lines[1], field) {
+			t.Errorf("expected %q in the Event line %q", field, lines[1])
+		}
+	}
+}
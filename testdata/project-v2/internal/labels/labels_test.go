@@ -0,0 +1,63 @@
+/*
+Copyright 2020 The Kubernetes authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package labels
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestStamp(t *testing.T) {
+	defer func(version string) { BuildVersion = version }(BuildVersion)
+	BuildVersion = "v1.2.3"
+
+	owner := &metav1.ObjectMeta{Name: "owner"}
+	obj := &metav1.ObjectMeta{Name: "child", Labels: map[string]string{"app": "test", InstanceLabel: "other"}}
+	if !Stamp(obj, owner) {
+		t.Fatalf("expected the labels to change")
+	}
+
+	expected := map[string]string{
+		"app":          "test",
+		ManagedByLabel: ManagedBy,
+		PartOfLabel:    PartOf,
+		InstanceLabel:  "owner",
+		VersionLabel:   "v1.2.3",
+	}
+	for key, value := range expected {
+		if obj.Labels[key] != value {
+			t.Errorf("expected the label %s to be %q, got %q", key, value, obj.Labels[key])
+		}
+	}
+	if len(obj.Labels) != len(expected) {
+		t.Errorf("expected the labels %v, got %v", expected, obj.Labels)
+	}
+
+	if Stamp(obj, owner) {
+		t.Errorf("expected the labels not to change when stamped again")
+	}
+}
+
+func TestForInvalidVersion(t *testing.T) {
+	defer func(version string) { BuildVersion = version }(BuildVersion)
+	BuildVersion = "v1.2.3+not a label value"
+
+	if version, ok := For(&metav1.ObjectMeta{Name: "owner"})[VersionLabel]; ok {
+		t.Errorf("expected no version label for an invalid label value, got %q", version)
+	}
+}